@@ -2,29 +2,45 @@ package main
 
 import (
 	"fmt"
+	"log/slog"
 
 	"github.com/kyleboon/gochess/internal/config"
 	"github.com/kyleboon/gochess/internal/db"
 	"github.com/kyleboon/gochess/internal/engine"
 	"github.com/kyleboon/gochess/internal/logging"
+	"github.com/kyleboon/gochess/internal/output"
+	"github.com/kyleboon/gochess/internal/pgn"
 	"github.com/urfave/cli/v2"
 )
 
+// analyzedLine is an engine line in the --json output of "analyze position".
+// Scores are from the side to move's view.
+type analyzedLine struct {
+	Rank       int      `json:"rank"`
+	Score      string   `json:"score"`
+	Centipawns int      `json:"centipawns"`
+	Mate       int      `json:"mate,omitempty"`
+	Moves      []string `json:"moves"`
+}
+
+// analyzedPosition is the --json output of "analyze position"
+type analyzedPosition struct {
+	FEN       string         `json:"fen"`
+	Depth     int            `json:"depth"`
+	Lines     []analyzedLine `json:"lines"`
+	SavedEval *float64       `json:"saved_eval,omitempty"`
+}
+
 func analyzePositionAction(c *cli.Context) error {
 	fen := c.String("fen")
 	gameID := c.Int("game-id")
 	moveNumber := c.Int("move")
-	enginePath := c.String("engine")
 	depth := c.Int("depth")
 	lines := c.Int("lines")
 	save := c.Bool("save")
 
-	// Determine log level
-	logLevel := logging.LevelError
-	if c.IsSet("log-level") {
-		logLevel = logging.Level(c.String("log-level"))
-	}
-	logger := logging.NewWithLevel(logLevel)
+	logger := logging.Default()
+	out := output.Messages(c)
 
 	// Load config for defaults
 	cfg, err := config.LoadOrDefault()
@@ -32,21 +48,6 @@ func analyzePositionAction(c *cli.Context) error {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
-	// Resolve engine path: flag > config > error
-	if enginePath == "" {
-		enginePath = cfg.GetEnginePath()
-	}
-	if enginePath == "" {
-		return fmt.Errorf("engine path required: use --engine flag or configure with 'gochess config init'")
-	}
-
-	// Resolve engine options from config
-	var engineOpts engine.Options
-	if cfg.Engine != nil {
-		engineOpts.Threads = cfg.Engine.Threads
-		engineOpts.Hash = cfg.Engine.Hash
-	}
-
 	// Resolve FEN: --fen flag or --game-id + --move from DB
 	var gamePos *db.GamePosition
 	if fen == "" {
@@ -70,17 +71,17 @@ func analyzePositionAction(c *cli.Context) error {
 
 	// Print game info if loaded from DB
 	if gamePos != nil {
-		fmt.Printf("Game: %s vs %s (%s, %s)\n", gamePos.White, gamePos.Black, gamePos.Event, gamePos.Date)
-		fmt.Printf("Position at ply %d\n", gamePos.MoveNumber)
+		fmt.Fprintf(out, "Game: %s vs %s (%s, %s)\n", gamePos.White, gamePos.Black, gamePos.Event, gamePos.Date)
+		fmt.Fprintf(out, "Position at ply %d\n", gamePos.MoveNumber)
 	}
-	fmt.Printf("FEN: %s\n", fen)
+	fmt.Fprintf(out, "FEN: %s\n", fen)
 
 	// Start engine
-	fmt.Printf("\nAnalyzing at depth %d with %d line(s)...\n", depth, lines)
+	fmt.Fprintf(out, "\nAnalyzing at depth %d with %d line(s)...\n", depth, lines)
 
-	eng, err := engine.NewWithOptions(c.Context, enginePath, logger, engineOpts)
+	eng, err := openEngine(c, cfg, logger)
 	if err != nil {
-		return fmt.Errorf("failed to start engine: %w", err)
+		return err
 	}
 	defer func() { _ = eng.Close() }()
 
@@ -94,15 +95,30 @@ func analyzePositionAction(c *cli.Context) error {
 	}
 
 	// Display results
-	fmt.Printf("\nAnalysis (depth %d):\n\n", result.Depth)
-	for _, line := range result.Lines {
-		moves := ""
-		if len(line.Moves) > 5 {
-			moves = joinMoves(line.Moves[:5])
+	analyzed := analyzedPosition{FEN: fen, Depth: result.Depth, Lines: make([]analyzedLine, len(result.Lines))}
+	for i, line := range result.Lines {
+		analyzed.Lines[i] = analyzedLine{
+			Rank:  line.Rank,
+			Score: line.Score.String(),
+			Moves: line.Moves,
+		}
+		if line.Score.IsMate {
+			analyzed.Lines[i].Mate = line.Score.Mate
 		} else {
-			moves = joinMoves(line.Moves)
+			analyzed.Lines[i].Centipawns = line.Score.Centipawns
+		}
+	}
+	if !output.JSON(c) {
+		fmt.Printf("\nAnalysis (depth %d):\n\n", result.Depth)
+		for _, line := range result.Lines {
+			moves := ""
+			if len(line.Moves) > 5 {
+				moves = joinMoves(line.Moves[:5])
+			} else {
+				moves = joinMoves(line.Moves)
+			}
+			fmt.Printf("  %d. %-8s %s\n", line.Rank, line.Score.String(), moves)
 		}
-		fmt.Printf("  %d. %-8s %s\n", line.Rank, line.Score.String(), moves)
 	}
 
 	// Optionally save evaluation to DB
@@ -118,9 +134,9 @@ func analyzePositionAction(c *cli.Context) error {
 		var eval float64
 		if score.IsMate {
 			if score.Mate > 0 {
-				eval = 999.0
+				eval = pgn.MateEval
 			} else {
-				eval = -999.0
+				eval = -pgn.MateEval
 			}
 		} else {
 			eval = float64(score.Centipawns) / 100.0
@@ -129,12 +145,58 @@ func analyzePositionAction(c *cli.Context) error {
 		if err := database.UpdatePositionEvaluation(c.Context, gamePos.PositionID, eval); err != nil {
 			return fmt.Errorf("failed to save evaluation: %w", err)
 		}
-		fmt.Printf("\nEvaluation %.2f saved to database.\n", eval)
+		fmt.Fprintf(out, "\nEvaluation %.2f saved to database.\n", eval)
+		analyzed.SavedEval = &eval
 	}
 
+	if output.JSON(c) {
+		return output.WriteJSON(analyzed)
+	}
 	return nil
 }
 
+// openEngine starts the engine selected by the --engine and --protocol flags,
+// falling back to the configured engine. --engine may name an engine profile
+// from the config file instead of giving a path.
+func openEngine(c *cli.Context, cfg *config.Config, logger *slog.Logger) (engine.Analyzer, error) {
+	// Resolve engine: profile named by the flag > path from the flag >
+	// configured engine > error
+	engineCfg := cfg.Engine
+	enginePath := c.String("engine")
+	if profile := cfg.GetEngineProfile(enginePath); profile != nil {
+		engineCfg = profile
+		enginePath = profile.Path
+	} else if enginePath == "" {
+		enginePath = cfg.GetEnginePath()
+	}
+	if enginePath == "" {
+		return nil, fmt.Errorf("engine path required: use --engine flag or configure with 'gochess config init'")
+	}
+
+	// Resolve engine protocol: flag > config > uci
+	protocolName := c.String("protocol")
+	if protocolName == "" && engineCfg != nil {
+		protocolName = engineCfg.Protocol
+	}
+	protocol, err := engine.ParseProtocol(protocolName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Resolve engine options from config
+	var engineOpts engine.Options
+	if engineCfg != nil {
+		engineOpts.Threads = engineCfg.Threads
+		engineOpts.Hash = engineCfg.Hash
+	}
+
+	eng, err := engine.Open(c.Context, enginePath, protocol, logger, engineOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start engine: %w", err)
+	}
+	return eng, nil
+}
+
 func joinMoves(moves []string) string {
 	result := ""
 	for i, m := range moves {
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/kyleboon/gochess/internal"
+	"github.com/kyleboon/gochess/internal/analysis"
+	"github.com/kyleboon/gochess/internal/config"
+	"github.com/kyleboon/gochess/internal/db"
+	"github.com/kyleboon/gochess/internal/logging"
+	"github.com/kyleboon/gochess/internal/output"
+	"github.com/kyleboon/gochess/internal/pgn"
+	"github.com/kyleboon/gochess/internal/progress"
+	"github.com/urfave/cli/v2"
+)
+
+func analyzeGameAction(c *cli.Context) error {
+	gameID := c.Int("game-id")
+	pgnPath := c.String("pgn")
+
+	logger := logging.Default()
+	out := output.Messages(c)
+
+	// Load config for defaults
+	cfg, err := config.LoadOrDefault()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// Load the game text: --pgn file or --game-id from DB
+	var pgnText string
+	switch {
+	case pgnPath != "":
+		data, err := os.ReadFile(expandPath(pgnPath))
+		if err != nil {
+			return fmt.Errorf("failed to read PGN file: %w", err)
+		}
+		pgnText = string(data)
+	case gameID > 0:
+		database, err := db.NewWithLogger(expandPath(cfg.DatabasePath), logger)
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		defer func() { _ = database.Close() }()
+
+		game, err := database.GetGame(c.Context, gameID)
+		if err != nil {
+			return fmt.Errorf("failed to get game: %w", err)
+		}
+		pgnText = game.PGNText
+	default:
+		return fmt.Errorf("either --pgn or --game-id is required")
+	}
+
+	game, err := parseSingleGame(pgnText)
+	if err != nil {
+		return err
+	}
+
+	eng, err := openEngine(c, cfg, logger)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = eng.Close() }()
+
+	fmt.Fprintf(out, "Game: %s vs %s (%s)\n", game.Tags["White"], game.Tags["Black"], game.Tags["Result"])
+	opts := reviewOptions(c, cfg)
+	fmt.Fprintf(out, "Reviewing %d plies at depth %d...\n\n", game.Plies(), opts.Depth)
+
+	bar := progress.ForOutput(out, game.Plies()+1, "positions")
+	annotator := analysis.New(eng, opts, logger).WithProgress(bar.Set)
+	annotations, err := annotator.AnnotateGame(c.Context, game)
+	bar.Finish()
+	if err != nil {
+		return fmt.Errorf("analysis failed: %w", err)
+	}
+
+	if output.JSON(c) {
+		return writeReviewJSON(game, opts, annotations)
+	}
+
+	for _, a := range annotations {
+		if a.Classification == analysis.Normal {
+			continue
+		}
+		moveNr := (a.Ply + 1) / 2
+		dots := "."
+		if a.Color == internal.Black {
+			dots = "..."
+		}
+		line := fmt.Sprintf("  %d%s %s%s", moveNr, dots, a.San, a.Classification.Nag())
+		fmt.Printf("%-16s %-11s %+.2f -> %+.2f  (%s win%% %.0f -> %.0f)", line, a.Classification,
+			float64(a.EvalBefore)/100, float64(a.EvalAfter)/100,
+			[2]string{"White", "Black"}[a.Color], 100*moverWin(a.WinBefore, a.Color), 100*moverWin(a.WinAfter, a.Color))
+		if a.Best != "" && a.Best != a.San {
+			fmt.Printf("  (best: %s)", a.Best)
+		}
+		if len(a.Motifs) > 0 {
+			fmt.Printf("  [%s]", strings.Join(a.Motifs, ", "))
+		}
+		if a.TimeTrouble {
+			fmt.Printf("  [time trouble: %s left]", formatClock(a.Clock))
+		}
+		fmt.Println()
+	}
+
+	summary := analysis.Summarize(annotations)
+	fmt.Println()
+	for color, name := range []string{"White", "Black"} {
+		s := summary[color]
+		fmt.Printf("%s: %d brilliant, %d great, %d inaccuracies, %d mistakes, %d blunders\n",
+			name, s.Brilliant, s.Great, s.Inaccuracies, s.Mistakes, s.Blunders)
+	}
+
+	// Time usage, when the game has clock comments
+	timeSummary := analysis.SummarizeTime(game)
+	if timeSummary[internal.White].Moves > 0 || timeSummary[internal.Black].Moves > 0 {
+		fmt.Printf("\nTime Usage:\n")
+		for color, name := range []string{"White", "Black"} {
+			ts := timeSummary[color]
+			if ts.Moves == 0 {
+				continue
+			}
+			fmt.Printf("  %s: %s total, %s per move, longest think %s on move %d",
+				name, formatClock(ts.Total), formatClock(ts.Average()), formatClock(ts.Longest), (ts.LongestPly+1)/2)
+			if ts.TimeTroubleMoves > 0 {
+				fmt.Printf(", %d moves in time trouble (%d mistakes/blunders)",
+					ts.TimeTroubleMoves, summary[color].TimeTroubleErrors)
+			}
+			fmt.Println()
+		}
+	}
+
+	return nil
+}
+
+// reviewedMove is a move in the --json output of "analyze game". Evaluations
+// are in centipawns from White's view.
+type reviewedMove struct {
+	Ply            int      `json:"ply"`
+	Color          string   `json:"color"`
+	SAN            string   `json:"san"`
+	Best           string   `json:"best,omitempty"`
+	EvalBefore     int      `json:"eval_before"`
+	EvalAfter      int      `json:"eval_after"`
+	Loss           int      `json:"loss"`
+	WinBefore      float64  `json:"win_before"` // White's win probability (0-1)
+	WinAfter       float64  `json:"win_after"`
+	Classification string   `json:"classification"`
+	Motifs         []string `json:"motifs,omitempty"` // tactics of the move, or of the reply punishing it
+	Clock          float64  `json:"clock,omitempty"`  // seconds left after the move
+	TimeTrouble    bool     `json:"time_trouble,omitempty"`
+}
+
+// writeReviewJSON writes the review of a game as JSON: the verdict on every
+// move and the counts per side
+func writeReviewJSON(game *pgn.Game, opts analysis.Options, annotations []analysis.MoveAnnotation) error {
+	colors := [2]string{"white", "black"}
+	moves := make([]reviewedMove, len(annotations))
+	for i, a := range annotations {
+		moves[i] = reviewedMove{
+			Ply:            a.Ply,
+			Color:          colors[a.Color],
+			SAN:            a.San,
+			Best:           a.Best,
+			EvalBefore:     a.EvalBefore,
+			EvalAfter:      a.EvalAfter,
+			Loss:           a.Loss,
+			WinBefore:      a.WinBefore,
+			WinAfter:       a.WinAfter,
+			Classification: a.Classification.String(),
+			Motifs:         a.Motifs,
+			TimeTrouble:    a.TimeTrouble,
+		}
+		if a.HasClock {
+			moves[i].Clock = a.Clock.Seconds()
+		}
+	}
+	summary := analysis.Summarize(annotations)
+	return output.WriteJSON(map[string]any{
+		"white":   game.Tags["White"],
+		"black":   game.Tags["Black"],
+		"result":  game.Tags["Result"],
+		"depth":   opts.Depth,
+		"moves":   moves,
+		"summary": map[string]analysis.Summary{colors[0]: summary[0], colors[1]: summary[1]},
+	})
+}
+
+// moverWin converts White's win probability into color's
+func moverWin(white float64, color int) float64 {
+	if color == internal.Black {
+		return 1 - white
+	}
+	return white
+}
+
+// reviewOptions returns the annotator options of a game review: the --depth,
+// --lines and --rating flags, with the analysis defaults of the config file used for
+// the flags that are not given.
+func reviewOptions(c *cli.Context, cfg *config.Config) analysis.Options {
+	opts := analysis.Options{
+		Depth:   c.Int("depth"),
+		MultiPV: c.Int("lines"),
+		Rating:  c.Int("rating"),
+	}
+	if a := cfg.Analysis; a != nil {
+		if !c.IsSet("depth") && a.Depth > 0 {
+			opts.Depth = a.Depth
+		}
+		if !c.IsSet("lines") && a.Lines > 0 {
+			opts.MultiPV = a.Lines
+		}
+		opts.InaccuracyThreshold = a.InaccuracyThreshold
+		opts.MistakeThreshold = a.MistakeThreshold
+		opts.BlunderThreshold = a.BlunderThreshold
+	}
+	return opts
+}
+
+// formatClock formats a duration as M:SS, or H:MM:SS for an hour or more.
+func formatClock(d time.Duration) string {
+	d = d.Round(time.Second)
+	h := int(d / time.Hour)
+	m := int(d % time.Hour / time.Minute)
+	sec := int(d % time.Minute / time.Second)
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, m, sec)
+	}
+	return fmt.Sprintf("%d:%02d", m, sec)
+}
+
+// parseSingleGame parses the first game of a PGN text, including its moves.
+func parseSingleGame(text string) (*pgn.Game, error) {
+	pgnDB := &pgn.DB{}
+	if errs := pgnDB.Parse(text); len(errs) > 0 && len(pgnDB.Games) == 0 {
+		return nil, fmt.Errorf("failed to parse PGN: %w", errs[0])
+	}
+	if len(pgnDB.Games) == 0 {
+		return nil, fmt.Errorf("no game found in PGN")
+	}
+	game := pgnDB.Games[0]
+	if err := pgnDB.ParseMoves(game); err != nil {
+		return nil, fmt.Errorf("failed to parse moves: %w", err)
+	}
+	return game, nil
+}
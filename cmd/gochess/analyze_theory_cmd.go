@@ -0,0 +1,234 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/kyleboon/gochess/internal"
+	"github.com/kyleboon/gochess/internal/analysis"
+	"github.com/kyleboon/gochess/internal/config"
+	"github.com/kyleboon/gochess/internal/db"
+	"github.com/kyleboon/gochess/internal/lichess"
+	"github.com/kyleboon/gochess/internal/logging"
+	"github.com/kyleboon/gochess/internal/output"
+	"github.com/kyleboon/gochess/internal/pgn"
+	"github.com/urfave/cli/v2"
+)
+
+// analyzeTheoryAction finds where a game leaves opening theory: the first
+// move played in fewer than --min-games reference games, taken from a
+// database of master games (--reference) or the Lichess masters explorer
+// (--lichess). It shows how popular the move is and what theory plays
+// instead
+func analyzeTheoryAction(c *cli.Context) error {
+	logger := logging.Default()
+	out := output.Messages(c)
+
+	cfg, err := config.LoadOrDefault()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	var source analysis.TheorySource
+	sourceName := ""
+	switch ref := c.String("reference"); {
+	case ref != "" && c.Bool("lichess"):
+		return fmt.Errorf("use either --reference or --lichess, not both")
+	case ref != "":
+		reference, err := db.NewWithLogger(expandPath(ref), logger)
+		if err != nil {
+			return fmt.Errorf("failed to open reference database: %w", err)
+		}
+		defer func() { _ = reference.Close() }()
+		source, sourceName = dbTheory{reference}, ref
+	case c.Bool("lichess"):
+		client := lichess.NewClientWithLogger(logger)
+		if cfg.Lichess.APIToken != "" {
+			client.SetAPIToken(cfg.Lichess.APIToken)
+		}
+		source, sourceName = lichessTheory{client}, "Lichess masters"
+	default:
+		return fmt.Errorf("either --reference or --lichess is required")
+	}
+
+	// Load the game: --pgn file or --game-id from the database
+	var pgnText string
+	switch gameID := c.Int("game-id"); {
+	case c.String("pgn") != "":
+		data, err := os.ReadFile(expandPath(c.String("pgn")))
+		if err != nil {
+			return fmt.Errorf("failed to read PGN file: %w", err)
+		}
+		pgnText = string(data)
+	case gameID > 0:
+		database, err := openDatabase(c)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = database.Close() }()
+
+		game, err := database.GetGame(c.Context, gameID)
+		if err != nil {
+			return fmt.Errorf("failed to get game: %w", err)
+		}
+		pgnText = game.PGNText
+	default:
+		return fmt.Errorf("either --pgn or --game-id is required")
+	}
+
+	game, err := parseSingleGame(pgnText)
+	if err != nil {
+		return err
+	}
+
+	minGames := c.Int("min-games")
+	if minGames < 1 {
+		return fmt.Errorf("--min-games must be at least 1")
+	}
+	deviation, err := analysis.FindDeviation(c.Context, source, game, minGames)
+	if err != nil {
+		return fmt.Errorf("failed to look up theory: %w", err)
+	}
+	if deviation != nil && len(deviation.Replies) > c.Int("replies") {
+		deviation.Replies = deviation.Replies[:c.Int("replies")]
+	}
+
+	if output.JSON(c) {
+		return writeTheoryJSON(game, deviation)
+	}
+
+	fmt.Fprintf(out, "Game: %s vs %s (%s)\n", game.Tags["White"], game.Tags["Black"], game.Tags["Result"])
+	fmt.Fprintf(out, "Reference: %s\n\n", sourceName)
+	if deviation == nil {
+		fmt.Println("The game never left theory")
+		return nil
+	}
+
+	fmt.Printf("Out of theory at %s\n", moveNumber(deviation.Ply, deviation.Color)+deviation.SAN)
+	if deviation.Total == 0 {
+		fmt.Println("  The position does not occur in the reference games")
+		return nil
+	}
+	fmt.Printf("  %s was played in %d of %d reference games (%.1f%%)\n",
+		deviation.SAN, deviation.Games, deviation.Total, 100*deviation.Popularity())
+	fmt.Println("\nMain moves in the position:")
+	fmt.Printf("  %-8s %8s %7s %7s %7s %7s\n", "MOVE", "GAMES", "SHARE", "WHITE", "DRAW", "BLACK")
+	for _, m := range deviation.Replies {
+		fmt.Printf("  %-8s %8d %6.1f%% %6.1f%% %6.1f%% %6.1f%%\n", m.SAN, m.Games,
+			percent(m.Games, deviation.Total), percent(m.WhiteWins, m.Games),
+			percent(m.Draws, m.Games), percent(m.BlackWins, m.Games))
+	}
+	return nil
+}
+
+// moveNumber returns the move number prefix of a ply, such as "12." for
+// White or "12..." for Black
+func moveNumber(ply, color int) string {
+	if color == internal.Black {
+		return fmt.Sprintf("%d...", (ply+1)/2)
+	}
+	return fmt.Sprintf("%d.", (ply+1)/2)
+}
+
+// percent returns n as a percentage of total, or 0 if total is 0
+func percent(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return 100 * float64(n) / float64(total)
+}
+
+// theoryMoveJSON is a move of theory in the --json output of
+// "analyze theory"
+type theoryMoveJSON struct {
+	SAN       string `json:"san"`
+	UCI       string `json:"uci"`
+	Games     int    `json:"games"`
+	WhiteWins int    `json:"white_wins"`
+	Draws     int    `json:"draws"`
+	BlackWins int    `json:"black_wins"`
+}
+
+// writeTheoryJSON writes where the game left theory as JSON. The deviation
+// is null if the game never left theory
+func writeTheoryJSON(game *pgn.Game, d *analysis.Deviation) error {
+	var deviation map[string]any
+	if d != nil {
+		replies := make([]theoryMoveJSON, len(d.Replies))
+		for i, m := range d.Replies {
+			replies[i] = theoryMoveJSON{
+				SAN:       m.SAN,
+				UCI:       m.UCI,
+				Games:     m.Games,
+				WhiteWins: m.WhiteWins,
+				Draws:     m.Draws,
+				BlackWins: m.BlackWins,
+			}
+		}
+		deviation = map[string]any{
+			"ply":        d.Ply,
+			"color":      [2]string{"white", "black"}[d.Color],
+			"san":        d.SAN,
+			"uci":        d.UCI,
+			"games":      d.Games,
+			"total":      d.Total,
+			"popularity": d.Popularity(),
+			"replies":    replies,
+		}
+	}
+	return output.WriteJSON(map[string]any{
+		"white":     game.Tags["White"],
+		"black":     game.Tags["Black"],
+		"result":    game.Tags["Result"],
+		"deviation": deviation,
+	})
+}
+
+// dbTheory looks up theory in a gochess database of reference games, such
+// as master games imported with "gochess import"
+type dbTheory struct {
+	db *db.DB
+}
+
+func (t dbTheory) PositionMoves(ctx context.Context, fen string) ([]analysis.TheoryMove, error) {
+	stats, err := t.db.GetPositionMoves(ctx, fen)
+	if err != nil {
+		return nil, err
+	}
+	moves := make([]analysis.TheoryMove, len(stats))
+	for i, st := range stats {
+		moves[i] = analysis.TheoryMove{
+			UCI:       st.Move,
+			Games:     st.Games,
+			WhiteWins: st.WhiteWins,
+			BlackWins: st.BlackWins,
+			Draws:     st.Draws,
+		}
+	}
+	return moves, nil
+}
+
+// lichessTheory looks up theory in the Lichess masters database
+type lichessTheory struct {
+	client *lichess.Client
+}
+
+func (t lichessTheory) PositionMoves(ctx context.Context, fen string) ([]analysis.TheoryMove, error) {
+	result, err := t.client.GetMastersExplorer(ctx, fen, 0)
+	if err != nil {
+		return nil, err
+	}
+	moves := make([]analysis.TheoryMove, len(result.Moves))
+	for i, m := range result.Moves {
+		moves[i] = analysis.TheoryMove{
+			UCI:       m.UCI,
+			SAN:       m.SAN,
+			Games:     m.Games(),
+			WhiteWins: m.White,
+			BlackWins: m.Black,
+			Draws:     m.Draws,
+		}
+	}
+	return moves, nil
+}
@@ -0,0 +1,478 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/kyleboon/gochess/internal"
+	"github.com/kyleboon/gochess/internal/config"
+	"github.com/kyleboon/gochess/internal/engine"
+	"github.com/kyleboon/gochess/internal/epd"
+	"github.com/kyleboon/gochess/internal/logging"
+	"github.com/kyleboon/gochess/internal/output"
+	"github.com/kyleboon/gochess/internal/progress"
+	"github.com/urfave/cli/v2"
+)
+
+func benchSuiteAction(c *cli.Context) error {
+	verbose := c.Bool("verbose")
+
+	logger := logging.Default()
+
+	// Load config for defaults
+	cfg, err := config.LoadOrDefault()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	f, err := os.Open(expandPath(c.String("epd")))
+	if err != nil {
+		return fmt.Errorf("failed to open EPD file: %w", err)
+	}
+	positions, err := epd.Parse(f)
+	_ = f.Close()
+	if err != nil {
+		return fmt.Errorf("failed to parse EPD file: %w", err)
+	}
+	if len(positions) == 0 {
+		return fmt.Errorf("no positions found in EPD file")
+	}
+
+	// A depth limit takes precedence over the time limit
+	opts := engine.AnalysisOptions{Depth: c.Int("depth")}
+	limit := fmt.Sprintf("depth %d", opts.Depth)
+	if opts.Depth <= 0 {
+		opts.MoveTime = time.Duration(c.Float64("time") * float64(time.Second))
+		if opts.MoveTime <= 0 {
+			return fmt.Errorf("--time must be positive")
+		}
+		limit = fmt.Sprintf("%s per position", opts.MoveTime)
+	}
+
+	eng, err := openEngine(c, cfg, logger)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = eng.Close() }()
+
+	fmt.Printf("Running %d positions at %s...\n", len(positions), limit)
+
+	// Verbose results are printed above the progress bar
+	bar := progress.ForOutput(os.Stdout, len(positions), "positions")
+	out := bar.Writer(os.Stdout)
+	done := 0
+	onResult := func(r epd.Result) {
+		done++
+		bar.Set(done, len(positions))
+		if !verbose {
+			return
+		}
+		status := "FAIL"
+		switch {
+		case r.Err != nil:
+			status = "ERR "
+		case r.Passed:
+			status = "ok  "
+		}
+		name := r.Position.ID()
+		if name == "" {
+			name = fmt.Sprintf("line %d", r.Position.Line)
+		}
+		line := fmt.Sprintf("  %s %-32s %-8s", status, name, r.Move)
+		if r.Err != nil {
+			line += fmt.Sprintf(" %v", r.Err)
+		} else if len(r.Position.BestMoves()) > 0 {
+			line += " bm " + joinMoves(r.Position.BestMoves())
+		} else {
+			line += " am " + joinMoves(r.Position.AvoidMoves())
+		}
+		fmt.Fprintln(out, line)
+	}
+
+	report, runErr := epd.Run(c.Context, eng, positions, opts, onResult)
+	bar.Finish()
+
+	fmt.Println()
+	if len(report.Themes) > 0 {
+		fmt.Printf("  %-32s %-10s %-10s\n", "THEME", "SOLVED", "POINTS")
+		fmt.Println("  " + repeatString("-", 54))
+		for _, s := range report.Themes {
+			printSuiteScore(s)
+		}
+		fmt.Println("  " + repeatString("-", 54))
+	}
+	printSuiteScore(report.Total)
+
+	errored := 0
+	for _, r := range report.Results {
+		if r.Err != nil {
+			errored++
+		}
+	}
+	if errored > 0 {
+		fmt.Printf("\n%d position(s) could not be scored", errored)
+		if !verbose {
+			fmt.Print(" (use --verbose for details)")
+		}
+		fmt.Println()
+	}
+
+	if runErr != nil {
+		return fmt.Errorf("suite aborted: %w", runErr)
+	}
+	return nil
+}
+
+// printSuiteScore prints one row of the suite summary.
+func printSuiteScore(s epd.Score) {
+	solved := fmt.Sprintf("%d/%d", s.Passed, s.Positions)
+	points := fmt.Sprintf("%d/%d", s.Points, s.MaxPoints)
+	pct := 0.0
+	if s.MaxPoints > 0 {
+		pct = float64(s.Points) / float64(s.MaxPoints) * 100
+	}
+	fmt.Printf("  %-32s %-10s %-10s %.1f%%\n", s.Theme, solved, points, pct)
+}
+
+// perftPosition is a position with published perft node counts, used by the
+// movegen benchmark to check the move generator as well as time it
+type perftPosition struct {
+	name  string
+	fen   string
+	nodes []uint64 // by depth, from depth 1
+}
+
+// perftPositions are the standard perft test positions
+var perftPositions = []perftPosition{
+	{"start", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+		[]uint64{20, 400, 8902, 197281, 4865609, 119060324, 3195901860}},
+	{"kiwipete", "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+		[]uint64{48, 2039, 97862, 4085603, 193690690}},
+	{"endgame", "8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1",
+		[]uint64{14, 191, 2812, 43238, 674624, 11030083}},
+	{"promotions", "r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1",
+		[]uint64{6, 264, 9467, 422333, 15833292}},
+	{"middlegame", "rnbq1k1r/pp1Pbppp/2p5/8/2B5/8/PPP1NnPP/RNBQK2R w KQ - 1 8",
+		[]uint64{44, 1486, 62379, 2103487, 89941194}},
+}
+
+// movegenReport is the result of the movegen benchmark, as written by --json
+type movegenReport struct {
+	GoVersion          string             `json:"go_version"`
+	Platform           string             `json:"platform"`
+	CPUs               int                `json:"cpus"`
+	Depth              int                `json:"depth"`
+	Positions          []perftResult      `json:"positions"`
+	TotalNodes         uint64             `json:"total_nodes"`
+	TotalSeconds       float64            `json:"total_seconds"`
+	NodesPerSecond     float64            `json:"nodes_per_second"`
+	PositionsPerSecond float64            `json:"positions_per_second"` // legal move generation
+	MovesPerSecond     float64            `json:"moves_per_second"`
+	Operations         map[string]float64 `json:"operations,omitempty"` // calls per second, by operation
+}
+
+// perftResult is the perft count and time of one position
+type perftResult struct {
+	Name           string               `json:"name"`
+	FEN            string               `json:"fen"`
+	Nodes          uint64               `json:"nodes"`
+	Expected       uint64               `json:"expected,omitempty"` // the published count, if known
+	Seconds        float64              `json:"seconds"`
+	NodesPerSecond float64              `json:"nodes_per_second"`
+	Stats          *internal.PerftStats `json:"stats,omitempty"`
+}
+
+// benchMovegenAction times perft and legal move generation on the standard
+// perft positions, or on a single --fen position, and reports nodes per
+// second. Node counts of the standard positions are checked against their
+// published values. With --stats perft also counts captures, checks, mates
+// and so on, which is slower but helps track down move generator bugs.
+// --baseline saves the results, or with --compare checks them against the
+// saved ones, so that performance can be tracked over time
+func benchMovegenAction(c *cli.Context) error {
+	depth := c.Int("depth")
+	if depth < 1 {
+		return fmt.Errorf("--depth must be at least 1")
+	}
+	duration := time.Duration(c.Float64("time") * float64(time.Second))
+	if duration <= 0 {
+		return fmt.Errorf("--time must be positive")
+	}
+
+	positions := perftPositions
+	if fen := c.String("fen"); fen != "" {
+		board, err := internal.ParseFen(fen)
+		if err != nil {
+			return fmt.Errorf("invalid FEN: %w", err)
+		}
+		if err := board.Validate(); err != nil {
+			return fmt.Errorf("illegal position: %w", err)
+		}
+		positions = []perftPosition{{name: "fen", fen: fen}}
+	}
+
+	boards := make([]*internal.Board, len(positions))
+	for i, pos := range positions {
+		board, err := internal.ParseFen(pos.fen)
+		if err != nil {
+			return fmt.Errorf("invalid FEN for %s: %w", pos.name, err)
+		}
+		boards[i] = board
+	}
+
+	out := output.Messages(c)
+	report := movegenReport{
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+		CPUs:      runtime.NumCPU(),
+		Depth:     depth,
+	}
+	fmt.Fprintf(out, "gochess movegen benchmark (%s, %s, %d CPUs)\n\n",
+		report.GoVersion, report.Platform, report.CPUs)
+
+	fmt.Fprintf(out, "Perft to depth %d:\n", depth)
+	fmt.Fprintf(out, "  %-12s %14s %10s %14s  %s\n", "POSITION", "NODES", "TIME", "NODES/S", "CHECK")
+	fmt.Fprintln(out, "  "+repeatString("-", 60))
+	var totalTime time.Duration
+	mismatches := 0
+	for i, pos := range positions {
+		result := perftResult{Name: pos.name, FEN: pos.fen}
+		start := time.Now()
+		if c.Bool("stats") {
+			stats := boards[i].PerftWithStats(depth)
+			result.Nodes, result.Stats = stats.Nodes, &stats
+		} else {
+			result.Nodes = boards[i].Perft(depth)
+		}
+		elapsed := time.Since(start)
+		result.Seconds = elapsed.Seconds()
+		result.NodesPerSecond = perSecond(result.Nodes, elapsed)
+		report.TotalNodes += result.Nodes
+		totalTime += elapsed
+
+		check := ""
+		if depth <= len(pos.nodes) {
+			result.Expected = pos.nodes[depth-1]
+			check = "ok"
+			if result.Nodes != result.Expected {
+				check = fmt.Sprintf("MISMATCH (expected %d)", result.Expected)
+				mismatches++
+			}
+		}
+		report.Positions = append(report.Positions, result)
+		fmt.Fprintf(out, "  %-12s %14d %10s %14.0f  %s\n", pos.name, result.Nodes,
+			elapsed.Round(time.Millisecond), result.NodesPerSecond, check)
+	}
+	report.TotalSeconds = totalTime.Seconds()
+	report.NodesPerSecond = perSecond(report.TotalNodes, totalTime)
+	fmt.Fprintln(out, "  "+repeatString("-", 60))
+	fmt.Fprintf(out, "  %-12s %14d %10s %14.0f\n\n", "total", report.TotalNodes,
+		totalTime.Round(time.Millisecond), report.NodesPerSecond)
+
+	if c.Bool("stats") {
+		printPerftStats(out, report.Positions)
+	}
+
+	// Move generation alone: generate the legal moves of each position in
+	// turn until the time is up
+	var moves, calls uint64
+	start := time.Now()
+	for time.Since(start) < duration {
+		for _, board := range boards {
+			moves += uint64(len(board.LegalMoves()))
+			calls++
+		}
+	}
+	elapsed := time.Since(start)
+	report.PositionsPerSecond = perSecond(calls, elapsed)
+	report.MovesPerSecond = perSecond(moves, elapsed)
+	fmt.Fprintf(out, "Legal move generation (%s):\n", duration)
+	fmt.Fprintf(out, "  %.0f positions/s, %.0f moves/s\n\n", report.PositionsPerSecond, report.MovesPerSecond)
+
+	report.Operations = timeOperations(boards, duration)
+	fmt.Fprintf(out, "Board operations (%s each):\n", duration)
+	for _, name := range benchOperations {
+		fmt.Fprintf(out, "  %-12s %14.0f/s\n", name, report.Operations[name])
+	}
+
+	if output.JSON(c) {
+		if err := output.WriteJSON(report); err != nil {
+			return err
+		}
+	}
+	if mismatches > 0 {
+		return fmt.Errorf("%d perft node count(s) did not match", mismatches)
+	}
+	return compareBaseline(c, out, report)
+}
+
+// benchOperations are the board operations timed by the movegen benchmark
+var benchOperations = []string{"make_move", "san", "fen_parse", "fen_write"}
+
+// timeOperations times making moves, writing them in SAN, and parsing and
+// writing FENs, in calls per second. Each operation is repeated over the
+// positions, or over all their legal moves, until d has passed
+func timeOperations(boards []*internal.Board, d time.Duration) map[string]float64 {
+	type boardMove struct {
+		board *internal.Board
+		move  internal.Move
+	}
+	var moves []boardMove
+	var fens []string
+	for _, board := range boards {
+		for _, move := range board.LegalMoves() {
+			moves = append(moves, boardMove{board, move})
+		}
+		fens = append(fens, board.Fen())
+	}
+
+	ops := map[string]func(i int){
+		"make_move": func(i int) {
+			m := moves[i%len(moves)]
+			m.board.MakeMove(m.move)
+		},
+		"san": func(i int) {
+			m := moves[i%len(moves)]
+			m.move.San(m.board)
+		},
+		"fen_parse": func(i int) {
+			_, _ = internal.ParseFen(fens[i%len(fens)])
+		},
+		"fen_write": func(i int) {
+			boards[i%len(boards)].Fen()
+		},
+	}
+	rates := make(map[string]float64, len(ops))
+	for _, name := range benchOperations {
+		if len(moves) == 0 && (name == "make_move" || name == "san") {
+			continue
+		}
+		op := ops[name]
+		calls := 0
+		start := time.Now()
+		for time.Since(start) < d {
+			// Only look at the clock every 100 calls
+			for range 100 {
+				op(calls)
+				calls++
+			}
+		}
+		rates[name] = perSecond(uint64(calls), time.Since(start))
+	}
+	return rates
+}
+
+// benchMetric is a rate measured by the movegen benchmark; higher is better
+type benchMetric struct {
+	name  string
+	value float64
+}
+
+// movegenMetrics returns the rates of a movegen report that are compared
+// with a baseline
+func movegenMetrics(r movegenReport) []benchMetric {
+	metrics := []benchMetric{{"perft total", r.NodesPerSecond}}
+	for _, p := range r.Positions {
+		metrics = append(metrics, benchMetric{"perft " + p.Name, p.NodesPerSecond})
+	}
+	metrics = append(metrics, benchMetric{"legal moves", r.MovesPerSecond})
+	for _, name := range benchOperations {
+		if rate, ok := r.Operations[name]; ok {
+			metrics = append(metrics, benchMetric{name, rate})
+		}
+	}
+	return metrics
+}
+
+// compareBaseline saves the report to the --baseline file, or with --compare
+// compares it with the one saved there earlier. Rates more than --threshold
+// percent below the baseline are flagged as regressions and make the
+// command fail
+func compareBaseline(c *cli.Context, out io.Writer, report movegenReport) error {
+	path := c.String("baseline")
+	if path == "" {
+		if c.Bool("compare") {
+			return fmt.Errorf("--compare needs a --baseline file")
+		}
+		return nil
+	}
+	path = expandPath(path)
+
+	if !c.Bool("compare") {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode baseline: %w", err)
+		}
+		if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+			return fmt.Errorf("failed to save baseline: %w", err)
+		}
+		fmt.Fprintf(out, "\nSaved baseline to %s\n", path)
+		return nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read baseline: %w", err)
+	}
+	var baseline movegenReport
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		return fmt.Errorf("failed to parse baseline %s: %w", path, err)
+	}
+
+	threshold := c.Float64("threshold")
+	fmt.Fprintf(out, "\nCompared with %s:\n", path)
+	if baseline.Depth != report.Depth {
+		fmt.Fprintf(out, "  (the baseline ran perft to depth %d, not %d)\n", baseline.Depth, report.Depth)
+	}
+	fmt.Fprintf(out, "  %-22s %14s %14s %8s\n", "METRIC", "BASELINE", "CURRENT", "CHANGE")
+	fmt.Fprintln(out, "  "+repeatString("-", 61))
+	before := make(map[string]float64)
+	for _, m := range movegenMetrics(baseline) {
+		before[m.name] = m.value
+	}
+	regressions := 0
+	for _, m := range movegenMetrics(report) {
+		old, ok := before[m.name]
+		if !ok || old <= 0 {
+			continue
+		}
+		change := (m.value - old) / old * 100
+		flag := ""
+		if change < -threshold {
+			flag = "  REGRESSION"
+			regressions++
+		}
+		fmt.Fprintf(out, "  %-22s %14.0f %14.0f %+7.1f%%%s\n", m.name, old, m.value, change, flag)
+	}
+	if regressions > 0 {
+		return fmt.Errorf("%d rate(s) more than %g%% below the baseline", regressions, threshold)
+	}
+	fmt.Fprintf(out, "\nNo regressions of more than %g%%\n", threshold)
+	return nil
+}
+
+// printPerftStats prints the perft counts of each position by kind of move
+func printPerftStats(out io.Writer, results []perftResult) {
+	fmt.Fprintln(out, "Perft statistics:")
+	fmt.Fprintf(out, "  %-12s %12s %10s %10s %10s %10s %10s %8s %8s\n", "POSITION",
+		"CAPTURES", "E.P.", "CASTLES", "PROMOTIONS", "CHECKS", "DISC.CHK", "DBL.CHK", "MATES")
+	for _, r := range results {
+		s := r.Stats
+		fmt.Fprintf(out, "  %-12s %12d %10d %10d %10d %10d %10d %8d %8d\n", r.Name,
+			s.Captures, s.EnPassant, s.Castles, s.Promotions, s.Checks,
+			s.DiscoveryChecks, s.DoubleChecks, s.Checkmates)
+	}
+	fmt.Fprintln(out)
+}
+
+// perSecond returns the rate of n events over d
+func perSecond(n uint64, d time.Duration) float64 {
+	if d <= 0 {
+		return 0
+	}
+	return float64(n) / d.Seconds()
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/kyleboon/gochess/internal/config"
+	"github.com/kyleboon/gochess/internal/engine"
+	"github.com/kyleboon/gochess/internal/lichess"
+	"github.com/kyleboon/gochess/internal/logging"
+	"github.com/urfave/cli/v2"
+)
+
+// botCommand plays online as a bot account: it accepts challenges and plays
+// them with an engine until interrupted, storing the finished games
+func botCommand(c *cli.Context) error {
+	if provider := c.String("provider"); provider != "lichess" {
+		return fmt.Errorf("unsupported provider %q: only lichess has a bot API", provider)
+	}
+	cfg, err := config.LoadOrDefault()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	token := c.String("token")
+	if token == "" && cfg.Lichess != nil {
+		token = cfg.Lichess.APIToken
+	}
+	if token == "" {
+		return fmt.Errorf("give the API token of a Lichess bot account with the bot:play scope with --token, or set lichess.api_token in the config")
+	}
+
+	logger := logging.Default()
+	eng, err := openPlayingEngine(c, cfg, logger)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = eng.Close() }()
+
+	database, err := openDatabase(c)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = database.Close() }()
+
+	ctx, stop := signal.NotifyContext(c.Context, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	client := lichess.NewClientWithLogger(logger)
+	client.SetAPIToken(token)
+	bot := lichess.NewBot(client, eng, lichess.BotOptions{
+		MaxGames:    c.Int("max-games"),
+		MaxMoveTime: c.Duration("move-time"),
+		Rated:       c.Bool("rated"),
+	})
+	addGame := gameAdder(context.Background(), database)
+	bot.OnGameFinished(func(gameID, pgnText string) {
+		if err := addGame(pgnText); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to store game %s: %v\n", gameID, err)
+			return
+		}
+		fmt.Printf("Stored game https://lichess.org/%s\n", gameID)
+	})
+
+	fmt.Println("Waiting for challenges, press Ctrl+C to stop")
+	return bot.Run(ctx)
+}
+
+// openPlayingEngine starts the engine to play against: the one given with
+// --engine or configured, or the built-in engine when there is none
+func openPlayingEngine(c *cli.Context, cfg *config.Config, logger *slog.Logger) (engine.Analyzer, error) {
+	if c.String("engine") == "" && cfg.GetEnginePath() == "" {
+		return engine.NewBuiltin(), nil
+	}
+	return openEngine(c, cfg, logger)
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/kyleboon/gochess/internal/config"
+	"github.com/kyleboon/gochess/internal/lichess"
+	"github.com/kyleboon/gochess/internal/logging"
+	"github.com/kyleboon/gochess/internal/tui"
+	"github.com/urfave/cli/v2"
+)
+
+// defaultBroadcastInterval is how often the game is pushed to a broadcast
+// round when it changed
+const defaultBroadcastInterval = 5 * time.Second
+
+// broadcastCommand relays a game live to a Lichess broadcast round: a game
+// played at the terminal, or the PGN file at --pgn as it is written
+func broadcastCommand(c *cli.Context) error {
+	roundID, err := lichess.BroadcastRoundID(c.String("round-url"))
+	if err != nil {
+		return err
+	}
+	interval := c.Duration("interval")
+	if interval <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+	cfg, err := config.LoadOrDefault()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	token, err := broadcastToken(c, cfg)
+	if err != nil {
+		return err
+	}
+
+	if path := c.String("pgn"); path != "" {
+		if len(broadcastTags(c)) > 0 {
+			return fmt.Errorf("--white, --black, --event and --round set the tags of games played with gochess; write them in %s instead", path)
+		}
+		client := lichess.NewClientWithLogger(logging.Default())
+		client.SetAPIToken(token)
+		return broadcastFile(c.Context, lichess.NewBroadcaster(client, roundID), path, interval)
+	}
+
+	// The game is played in the TUI, so what happens to the relay is logged
+	if err := logToFileForTUI(c); err != nil {
+		return err
+	}
+	client := lichess.NewClientWithLogger(logging.Default())
+	client.SetAPIToken(token)
+	model, err := newPlayModel(c, cfg)
+	if err != nil {
+		return err
+	}
+	_, err = broadcastPlay(c, model.WithTags(broadcastTags(c)), lichess.NewBroadcaster(client, roundID), interval)
+	return err
+}
+
+// broadcastToken returns the Lichess API token to push games with: the
+// token flag, or the configured one
+func broadcastToken(c *cli.Context, cfg *config.Config) (string, error) {
+	token := c.String("token")
+	if token == "" && cfg.Lichess != nil {
+		token = cfg.Lichess.APIToken
+	}
+	if token == "" {
+		return "", fmt.Errorf("give a Lichess API token with the study:write scope with --token, or set lichess.api_token in the config")
+	}
+	return token, nil
+}
+
+// broadcastTags returns the tags given to the game played for the broadcast
+func broadcastTags(c *cli.Context) map[string]string {
+	tags := make(map[string]string)
+	for flag, tag := range map[string]string{"white": "White", "black": "Black", "event": "Event", "round": "Round"} {
+		if value := c.String(flag); value != "" {
+			tags[tag] = value
+		}
+	}
+	return tags
+}
+
+// broadcastFile pushes the PGN file at path every interval it changed, until
+// interrupted
+func broadcastFile(ctx context.Context, broadcaster *lichess.Broadcaster, path string, interval time.Duration) error {
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("error accessing PGN file: %w", err)
+	}
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Printf("Relaying %s, checking for changes every %s (Ctrl-C to stop)...\n", path, interval)
+	source := func() (string, error) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		return string(data), nil
+	}
+	onError := func(err error) {
+		fmt.Fprintf(os.Stderr, "Error relaying the game: %v\n", err)
+	}
+	return broadcaster.Run(ctx, interval, source, printBroadcastPush, onError)
+}
+
+// printBroadcastPush reports the games Lichess read from the PGN pushed
+func printBroadcastPush(result *lichess.BroadcastPushResult) {
+	fmt.Printf("%s Pushed %d games\n", time.Now().Format("15:04:05"), len(result.Games))
+	for _, g := range result.Games {
+		players := fmt.Sprintf("%s - %s", g.Tags["White"], g.Tags["Black"])
+		if g.Error != "" {
+			fmt.Printf("  %s: rejected: %s\n", players, g.Error)
+			continue
+		}
+		fmt.Printf("  %s: %d moves\n", players, g.Moves)
+	}
+}
+
+// broadcastPlay plays the game of model at the terminal, pushing it every
+// interval it changed, and the final position with the result once the
+// players quit. It returns the game as PGN
+func broadcastPlay(c *cli.Context, model tui.PlayModel, broadcaster *lichess.Broadcaster, interval time.Duration) (string, error) {
+	// The game is handed over by the TUI after each move
+	var mu sync.Mutex
+	var current string
+	model = model.WithGameListener(func(pgnText string) {
+		mu.Lock()
+		current = pgnText
+		mu.Unlock()
+	})
+	source := func() (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return current, nil
+	}
+
+	logger := logging.Default()
+	onPush := func(result *lichess.BroadcastPushResult) {
+		for _, g := range result.Games {
+			if g.Error != "" {
+				logger.Warn("broadcast rejected the game", "error", g.Error)
+			}
+		}
+	}
+	onError := func(err error) {
+		logger.Error("failed to relay the game", "error", err)
+	}
+
+	ctx, cancel := context.WithCancel(c.Context)
+	done := make(chan error, 1)
+	go func() { done <- broadcaster.Run(ctx, interval, source, onPush, onError) }()
+
+	playErr := runPlayModel(model)
+	cancel()
+	<-done
+	if playErr != nil {
+		return "", playErr
+	}
+
+	pgnText, _ := source()
+	result, err := broadcaster.Push(c.Context, pgnText)
+	if err != nil {
+		return pgnText, fmt.Errorf("failed to push the final position: %w", err)
+	}
+	if result != nil {
+		printBroadcastPush(result)
+	}
+	fmt.Println("The game was relayed; the broadcast keeps its last position")
+	return pgnText, nil
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+)
+
+// The bash and zsh scripts ask gochess itself for the completions of the
+// command line typed so far, through the hidden --generate-bash-completion
+// flag of urfave/cli.
+const bashCompletion = `# bash completion for gochess
+_gochess_completion() {
+  local cur words cword
+  COMPREPLY=()
+  if declare -F _init_completion >/dev/null 2>&1; then
+    _init_completion -n "=:" || return
+  else
+    cur="${COMP_WORDS[COMP_CWORD]}"
+    words=("${COMP_WORDS[@]}")
+    cword=$COMP_CWORD
+  fi
+  words=("${words[@]:0:$cword}")
+  local request="${words[*]} --generate-bash-completion"
+  if [[ "$cur" == "-"* ]]; then
+    request="${words[*]} ${cur} --generate-bash-completion"
+  fi
+  local opts
+  opts=$(eval "${request}" 2>/dev/null)
+  COMPREPLY=($(compgen -W "${opts}" -- "${cur}"))
+}
+
+complete -o bashdefault -o default -o nospace -F _gochess_completion gochess
+`
+
+const zshCompletion = `#compdef gochess
+
+_gochess() {
+  local -a opts
+  local cur
+  cur=${words[-1]}
+  if [[ "$cur" == "-"* ]]; then
+    opts=("${(@f)$(${words[@]:0:#words[@]-1} ${cur} --generate-bash-completion)}")
+  else
+    opts=("${(@f)$(${words[@]:0:#words[@]-1} --generate-bash-completion)}")
+  fi
+
+  if [[ "${opts[1]}" != "" ]]; then
+    _describe 'values' opts
+  else
+    _files
+  fi
+}
+
+compdef _gochess gochess
+`
+
+// completionCommand prints the completion script of a shell
+func completionCommand(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return fmt.Errorf("expected one shell: bash, zsh or fish")
+	}
+
+	var script string
+	switch shell := c.Args().First(); shell {
+	case "bash":
+		script = bashCompletion
+	case "zsh":
+		script = zshCompletion
+	case "fish":
+		var err error
+		script, err = c.App.ToFishCompletion()
+		if err != nil {
+			return fmt.Errorf("failed to generate fish completion: %w", err)
+		}
+	default:
+		return fmt.Errorf("unsupported shell %q (use bash, zsh or fish)", shell)
+	}
+	fmt.Print(script)
+	return nil
+}
+
+// docsManCommand writes the man page of gochess, generated from the command
+// tree
+func docsManCommand(c *cli.Context) error {
+	page, err := c.App.ToManWithSection(1)
+	if err != nil {
+		return fmt.Errorf("failed to generate man page: %w", err)
+	}
+	return writeDocs(c.String("output"), page)
+}
+
+// docsMarkdownCommand writes the command reference of gochess as Markdown
+func docsMarkdownCommand(c *cli.Context) error {
+	doc, err := c.App.ToMarkdown()
+	if err != nil {
+		return fmt.Errorf("failed to generate Markdown: %w", err)
+	}
+	return writeDocs(c.String("output"), doc)
+}
+
+// writeDocs writes generated documentation to path, or to stdout if path is
+// empty
+func writeDocs(path, doc string) error {
+	if path == "" {
+		fmt.Print(doc)
+		return nil
+	}
+	if !strings.HasSuffix(doc, "\n") {
+		doc += "\n"
+	}
+	if err := os.WriteFile(expandPath(path), []byte(doc), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	fmt.Printf("Written to %s\n", path)
+	return nil
+}
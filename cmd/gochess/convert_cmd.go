@@ -0,0 +1,221 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kyleboon/gochess/internal"
+	"github.com/kyleboon/gochess/internal/pgn"
+	"github.com/urfave/cli/v2"
+)
+
+// convertCommand converts games between PGN and JSON, or from either to EPD
+// positions or UCI move lists. Games are converted one at a time, so files of
+// any size can be converted
+func convertCommand(c *cli.Context) error {
+	inPath, outPath := c.String("in"), c.String("out")
+	from, err := convertFormat(c.String("from"), inPath, []string{"pgn", "json"})
+	if err != nil {
+		return fmt.Errorf("input: %w", err)
+	}
+	to, err := convertFormat(c.String("to"), outPath, []string{"pgn", "json", "epd", "uci"})
+	if err != nil {
+		return fmt.Errorf("output: %w", err)
+	}
+
+	var in io.Reader = os.Stdin
+	if inPath != "-" {
+		f, err := os.Open(expandPath(inPath))
+		if err != nil {
+			return fmt.Errorf("failed to open input file: %w", err)
+		}
+		defer func() { _ = f.Close() }()
+		in = f
+	}
+	var out io.Writer = os.Stdout
+	if outPath != "" && outPath != "-" {
+		f, err := os.Create(expandPath(outPath))
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer func() { _ = f.Close() }()
+		out = f
+	}
+
+	next := pgn.NewReader(in).Read
+	if from == "json" {
+		if next, err = jsonGames(in); err != nil {
+			return err
+		}
+	}
+
+	w := bufio.NewWriter(out)
+	write := gameWriter(w, to, c.Bool("every-ply"))
+	converted, skipped := 0, 0
+	for {
+		game, err := next()
+		if err == io.EOF {
+			break
+		}
+		var perr *pgn.ParseError
+		if err != nil && !errors.As(err, &perr) {
+			return err
+		}
+		if err != nil {
+			// A game that does not parse is skipped
+			skipped++
+			fmt.Fprintf(os.Stderr, "Skipping game %d: %v\n", converted+skipped, err)
+			continue
+		}
+		if err := write(game, converted); err != nil {
+			return fmt.Errorf("failed to write output: %w", err)
+		}
+		converted++
+	}
+	if to == "json" {
+		if converted == 0 {
+			_, err = w.WriteString("[")
+		} else {
+			_, err = w.WriteString("\n")
+		}
+		if err == nil {
+			_, err = w.WriteString("]\n")
+		}
+		if err != nil {
+			return fmt.Errorf("failed to write output: %w", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "Converted %d games", converted)
+	if skipped > 0 {
+		fmt.Fprintf(os.Stderr, " (%d skipped)", skipped)
+	}
+	fmt.Fprintln(os.Stderr)
+	return nil
+}
+
+// convertFormat returns the format given by flag, or else the one implied by
+// the extension of path. The format must be one of allowed.
+func convertFormat(flag, path string, allowed []string) (string, error) {
+	format := strings.ToLower(flag)
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	}
+	for _, f := range allowed {
+		if f == format {
+			return format, nil
+		}
+	}
+	if format == "" {
+		return "", fmt.Errorf("cannot tell the format of %q, use one of: %s", path, strings.Join(allowed, ", "))
+	}
+	return "", fmt.Errorf("unsupported format %q, use one of: %s", format, strings.Join(allowed, ", "))
+}
+
+// jsonGames returns a function that reads the games of a JSON array, as
+// written by the convert command, one at a time. Games with illegal moves are
+// reported as parse errors, so they can be skipped.
+func jsonGames(r io.Reader) (func() (*pgn.Game, error), error) {
+	dec := json.NewDecoder(r)
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('[') {
+		return nil, fmt.Errorf("invalid JSON input: expected an array of games")
+	}
+	return func() (*pgn.Game, error) {
+		if !dec.More() {
+			return nil, io.EOF
+		}
+		var j pgn.GameJSON
+		if err := dec.Decode(&j); err != nil {
+			return nil, fmt.Errorf("invalid JSON input: %w", err)
+		}
+		game, err := pgn.GameFromJSON(j)
+		if err != nil {
+			return nil, &pgn.ParseError{Message: err.Error()}
+		}
+		return game, nil
+	}, nil
+}
+
+// gameWriter returns a function that writes the i-th game converted to w in
+// format. EPD output holds the final position of each game, or every
+// position if everyPly is set.
+func gameWriter(w io.Writer, format string, everyPly bool) func(game *pgn.Game, i int) error {
+	switch format {
+	case "json":
+		return func(game *pgn.Game, i int) error {
+			data, err := json.Marshal(game.JSON())
+			if err != nil {
+				return err
+			}
+			sep := ",\n"
+			if i == 0 {
+				sep = "[\n"
+			}
+			_, err = fmt.Fprintf(w, "%s%s", sep, data)
+			return err
+		}
+	case "epd":
+		return func(game *pgn.Game, i int) error {
+			n := game.Root
+			for ply := 0; ; ply++ {
+				if everyPly || n.Next == nil {
+					if _, err := fmt.Fprintln(w, epdLine(n.Board, fmt.Sprintf("game %d ply %d", i+1, ply))); err != nil {
+						return err
+					}
+				}
+				if n.Next == nil {
+					return nil
+				}
+				n = n.Next
+			}
+		}
+	case "uci":
+		return func(game *pgn.Game, i int) error {
+			var moves []string
+			for n := game.Root.Next; n != nil; n = n.Next {
+				moves = append(moves, uciMove(n.Parent.Board, n.Move))
+			}
+			_, err := fmt.Fprintln(w, strings.Join(moves, " "))
+			return err
+		}
+	}
+	return func(game *pgn.Game, i int) error {
+		sep := "\n"
+		if i == 0 {
+			sep = ""
+		}
+		_, err := fmt.Fprintf(w, "%s%s", sep, game.String())
+		return err
+	}
+}
+
+// epdLine returns the EPD record of board, with the move counters as hmvc
+// and fmvn operations and id as its id.
+func epdLine(board *internal.Board, id string) string {
+	fields := strings.Fields(board.Fen())
+	return fmt.Sprintf("%s hmvc %d; fmvn %d; id %q;", strings.Join(fields[:4], " "), board.Rule50, board.MoveNr, id)
+}
+
+// uciMove returns mv in UCI notation, with castling written as the king
+// moving two squares as engines expect, rather than as the king taking its
+// own rook.
+func uciMove(board *internal.Board, mv internal.Move) string {
+	p, q := board.Piece[mv.From], board.Piece[mv.To]
+	if p.Type() == internal.King && q != internal.NoPiece && q.Color() == p.Color() {
+		file := internal.FileG
+		if mv.To.File() < mv.From.File() {
+			file = internal.FileC
+		}
+		mv.To = internal.Square(file, mv.From.Rank())
+	}
+	return mv.Uci(board)
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kyleboon/gochess/internal/db"
+	"github.com/kyleboon/gochess/internal/output"
+	"github.com/urfave/cli/v2"
+)
+
+// dbCrosstableCommand prints the crosstable of a tournament, or lists the
+// tournaments in the database when no event is given
+func dbCrosstableCommand(c *cli.Context) error {
+	database, err := openDatabase(c)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = database.Close() }()
+
+	if !c.IsSet("event") {
+		return listTournaments(c, database)
+	}
+
+	ct, err := database.GetCrosstable(c.Context, c.String("event"), c.String("site"), c.String("date"))
+	if err != nil {
+		return err
+	}
+	if output.JSON(c) {
+		return output.WriteJSON(ct)
+	}
+
+	title := ct.Event
+	if ct.Site != "" && ct.Site != "?" {
+		title += ", " + ct.Site
+	}
+	fmt.Println(title)
+	fmt.Println(repeatString("=", len([]rune(title))))
+	dates := ct.StartDate
+	if ct.EndDate != ct.StartDate {
+		dates += " to " + ct.EndDate
+	}
+	format := "Round robin"
+	if ct.Format == db.Swiss {
+		format = "Swiss"
+	}
+	fmt.Printf("%s, %d players, %d rounds", format, len(ct.Players), ct.Rounds)
+	if dates != "" {
+		fmt.Printf(", %s", dates)
+	}
+	fmt.Print("\n\n")
+
+	nameWidth := len("PLAYER")
+	for _, p := range ct.Players {
+		nameWidth = max(nameWidth, len([]rune(p.Player)))
+	}
+	nameWidth = min(nameWidth, 24)
+
+	if ct.Format == db.RoundRobin {
+		printRoundRobin(ct, nameWidth)
+	} else {
+		printSwiss(ct, nameWidth)
+	}
+	return nil
+}
+
+// printRoundRobin prints a round-robin crosstable, with a column of results
+// against each opponent
+func printRoundRobin(ct *db.Crosstable, nameWidth int) {
+	// Each pair of players met the same number of times
+	cellWidth := max(2, ct.Players[0].Games/max(1, len(ct.Players)-1)+1)
+	fmt.Printf("%3s  %-*s  %6s ", "#", nameWidth, "PLAYER", "RATING")
+	for i := range ct.Players {
+		fmt.Printf(" %-*d", cellWidth, i+1)
+	}
+	fmt.Printf("  %5s %6s\n", "SCORE", "SB")
+	for _, p := range ct.Players {
+		cells := make([]string, len(ct.Players))
+		cells[p.Rank-1] = "×"
+		for _, r := range p.Results {
+			cells[r.Opponent-1] += formatScore(r.Score)
+		}
+		fmt.Printf("%3d  %-*s  %6s ", p.Rank, nameWidth, truncateName(p.Player, nameWidth), playerRating(p.Rating))
+		for _, cell := range cells {
+			fmt.Printf(" %-*s", cellWidth, cell)
+		}
+		fmt.Printf("  %5s %6.2f\n", formatScore(p.Score), p.SonnebornBerger)
+	}
+}
+
+// printSwiss prints a Swiss crosstable, with a column per round giving the
+// result, the opponent's rank and the color played, as in "+3w"
+func printSwiss(ct *db.Crosstable, nameWidth int) {
+	fmt.Printf("%3s  %-*s  %6s ", "#", nameWidth, "PLAYER", "RATING")
+	for round := 1; round <= ct.Rounds; round++ {
+		fmt.Printf(" %-5s", fmt.Sprintf("R%d", round))
+	}
+	fmt.Printf("  %5s %8s %6s\n", "SCORE", "BUCHHOLZ", "SB")
+	for _, p := range ct.Players {
+		cells := make([]string, ct.Rounds)
+		for i, r := range p.Results {
+			// Games of an unknown round are shown in the order played
+			round := r.Round
+			if round == 0 {
+				round = i + 1
+			}
+			if round > len(cells) || cells[round-1] != "" {
+				continue
+			}
+			sign := "="
+			switch r.Score {
+			case 1:
+				sign = "+"
+			case 0:
+				sign = "-"
+			}
+			cells[round-1] = fmt.Sprintf("%s%d%s", sign, r.Opponent, r.Color[:1])
+		}
+		fmt.Printf("%3d  %-*s  %6s ", p.Rank, nameWidth, truncateName(p.Player, nameWidth), playerRating(p.Rating))
+		for _, cell := range cells {
+			if cell == "" {
+				cell = "-"
+			}
+			fmt.Printf(" %-5s", cell)
+		}
+		fmt.Printf("  %5s %8s %6.2f\n", formatScore(p.Score), formatScore(p.Buchholz), p.SonnebornBerger)
+	}
+}
+
+// listTournaments prints the tournaments found in the database, grouped by
+// Event, Site and dates
+func listTournaments(c *cli.Context, database *db.DB) error {
+	tournaments, err := database.GetTournaments(c.Context, "")
+	if err != nil {
+		return err
+	}
+	if output.JSON(c) {
+		if tournaments == nil {
+			tournaments = []db.Tournament{}
+		}
+		return output.WriteJSON(map[string]interface{}{"tournaments": tournaments})
+	}
+	if len(tournaments) == 0 {
+		fmt.Println("No games with an Event tag found")
+		return nil
+	}
+
+	fmt.Printf("%-32s %-20s %-10s %-10s %6s %7s\n", "EVENT", "SITE", "START", "END", "GAMES", "PLAYERS")
+	for _, t := range tournaments {
+		fmt.Printf("%-32s %-20s %-10s %-10s %6d %7d\n",
+			truncateName(t.Event, 32), truncateName(t.Site, 20), t.StartDate, t.EndDate, t.Games, t.Players)
+	}
+	fmt.Println("\nShow a crosstable with --event \"<event>\"")
+	return nil
+}
+
+// truncateName shortens name to width characters, ending it with "..."
+func truncateName(name string, width int) string {
+	if r := []rune(name); len(r) > width {
+		return strings.TrimSpace(string(r[:width-3])) + "..."
+	}
+	return name
+}
+
+// playerRating formats a rating, or "-" if it is unknown
+func playerRating(rating int) string {
+	if rating == 0 {
+		return "-"
+	}
+	return fmt.Sprint(rating)
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/kyleboon/gochess/internal"
+	"github.com/kyleboon/gochess/internal/chesscom"
+	"github.com/kyleboon/gochess/internal/config"
+	"github.com/kyleboon/gochess/internal/eco"
+	"github.com/kyleboon/gochess/internal/logging"
+	"github.com/kyleboon/gochess/internal/output"
+	"github.com/kyleboon/gochess/internal/tui"
+	"github.com/urfave/cli/v2"
+)
+
+// dailyGame is a Chess.com daily game in progress, as listed by chesscom
+// daily
+type dailyGame struct {
+	URL         string    `json:"url"`
+	White       string    `json:"white"`
+	Black       string    `json:"black"`
+	TimeControl string    `json:"time_control"`
+	FEN         string    `json:"fen"`
+	Turn        string    `json:"turn"`
+	ToMove      bool      `json:"to_move"`
+	DrawOffer   bool      `json:"draw_offer"`
+	MoveBy      time.Time `json:"move_by,omitzero"`
+	PGN         string    `json:"pgn"`
+}
+
+// chesscomDailyCommand lists a player's daily (correspondence) games in
+// progress with their positions, those waiting on the player first, and
+// optionally browses them in the TUI
+func chesscomDailyCommand(c *cli.Context) error {
+	username, err := config.Username(c, "chesscom")
+	if err != nil {
+		return err
+	}
+
+	client := chesscom.NewClientWithLogger(logging.Default())
+	current, err := client.GetCurrentGames(c.Context, username)
+	if err != nil {
+		return err
+	}
+	toMove, err := client.GetGamesToMove(c.Context, username)
+	if err != nil {
+		return err
+	}
+	waiting := make(map[string]chesscom.ToMoveGame, len(toMove.Games))
+	for _, g := range toMove.Games {
+		waiting[g.URL] = g
+	}
+
+	var games, others []dailyGame
+	for _, g := range current.Games {
+		game := dailyGame{
+			URL:         g.URL,
+			White:       g.WhiteUsername(),
+			Black:       g.BlackUsername(),
+			TimeControl: g.TimeControl,
+			FEN:         g.FEN,
+			Turn:        g.Turn,
+			PGN:         g.PGN,
+		}
+		if g.MoveBy > 0 {
+			game.MoveBy = time.Unix(g.MoveBy, 0)
+		}
+		w, ok := waiting[g.URL]
+		if !ok {
+			others = append(others, game)
+			continue
+		}
+		game.ToMove = true
+		game.DrawOffer = w.DrawOffer
+		if w.MoveBy > 0 {
+			game.MoveBy = time.Unix(w.MoveBy, 0)
+		}
+		games = append(games, game)
+	}
+	games = append(games, others...)
+
+	if output.JSON(c) {
+		if games == nil {
+			games = []dailyGame{}
+		}
+		return output.WriteJSON(map[string]interface{}{"username": username, "games": games})
+	}
+	if len(games) == 0 {
+		fmt.Printf("%s has no daily games in progress on Chess.com\n", username)
+		return nil
+	}
+	if c.Bool("tui") {
+		return browseDailyGames(c, username, games)
+	}
+
+	fmt.Printf("Daily games of %s in progress (%d, %d to move):\n\n", username, len(games), len(toMove.Games))
+	for i, g := range games {
+		status := "waiting on the opponent"
+		if g.ToMove {
+			status = "your move"
+			if g.DrawOffer {
+				status += ", draw offered"
+			}
+		}
+		fmt.Printf("%d. %s - %s (%s), %s", i+1, g.White, g.Black, g.TimeControl, status)
+		if !g.MoveBy.IsZero() {
+			fmt.Printf(", move by %s", g.MoveBy.Local().Format("Mon Jan 2 15:04"))
+		}
+		fmt.Println()
+		fmt.Printf("   FEN: %s\n", g.FEN)
+		fmt.Printf("   %s\n", g.URL)
+		if c.Bool("board") {
+			board, err := internal.ParseFen(g.FEN)
+			if err != nil {
+				return fmt.Errorf("failed to parse the position of %s: %w", g.URL, err)
+			}
+			fmt.Println()
+			fmt.Print(diagram(board, c.Bool("unicode"), strings.EqualFold(g.Black, username)))
+		}
+		fmt.Println()
+	}
+	return nil
+}
+
+// browseDailyGames opens the daily games in the game list browser, where
+// enter loads one onto the board
+func browseDailyGames(c *cli.Context, username string, games []dailyGame) error {
+	if err := logToFileForTUI(c); err != nil {
+		return err
+	}
+	cfg, err := config.LoadOrDefault()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	keys, err := keyMap(cfg)
+	if err != nil {
+		return err
+	}
+	openings, err := eco.NewDatabase()
+	if err != nil {
+		return fmt.Errorf("failed to load ECO database: %w", err)
+	}
+
+	list := make([]tui.Game, len(games))
+	for i, g := range games {
+		event := "Daily game"
+		if g.ToMove {
+			event += ", your move"
+		}
+		// The games are not in the database: their IDs only tell them apart
+		list[i] = tui.Game{
+			ID:          i + 1,
+			Event:       event,
+			Site:        g.URL,
+			White:       g.White,
+			Black:       g.Black,
+			Result:      "*",
+			TimeControl: g.TimeControl,
+			PGNText:     g.PGN,
+		}
+	}
+
+	model := tui.NewGameListModel(list).
+		WithKeys(keys).
+		WithPlayers([]string{username}).
+		WithBoardOptions(boardOptions(cfg), settingsSaver(cfg)).
+		WithOpenings(openings)
+	p := tea.NewProgram(model, tea.WithAltScreen(), tea.WithMouseCellMotion())
+	if _, err := p.Run(); err != nil {
+		return fmt.Errorf("failed to run TUI: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/kyleboon/gochess/internal"
+	"github.com/kyleboon/gochess/internal/config"
+	"github.com/kyleboon/gochess/internal/dgt"
+	"github.com/kyleboon/gochess/internal/lichess"
+	"github.com/kyleboon/gochess/internal/logging"
+	"github.com/urfave/cli/v2"
+)
+
+// dgtCommand follows a game played on a DGT board in the TUI, relaying it to
+// a Lichess broadcast round with --round-url, and stores it in the database
+// once the players quit
+func dgtCommand(c *cli.Context) error {
+	if err := logToFileForTUI(c); err != nil {
+		return err
+	}
+	cfg, err := config.LoadOrDefault()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	var broadcaster *lichess.Broadcaster
+	if roundURL := c.String("round-url"); roundURL != "" {
+		roundID, err := lichess.BroadcastRoundID(roundURL)
+		if err != nil {
+			return err
+		}
+		if c.Duration("interval") <= 0 {
+			return fmt.Errorf("--interval must be positive")
+		}
+		token, err := broadcastToken(c, cfg)
+		if err != nil {
+			return err
+		}
+		client := lichess.NewClientWithLogger(logging.Default())
+		client.SetAPIToken(token)
+		broadcaster = lichess.NewBroadcaster(client, roundID)
+	}
+
+	model, err := newPlayModel(c, cfg)
+	if err != nil {
+		return err
+	}
+	board, err := dgt.Open(c.String("port"))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = board.Close() }()
+
+	ctx, cancel := context.WithCancel(c.Context)
+	defer cancel()
+	moves := make(chan string, 16)
+	var played atomic.Int32
+	go followBoard(ctx, board, moves, &played)
+	model = model.WithTags(broadcastTags(c)).WithMoveFeed(moves)
+
+	var pgnText string
+	if broadcaster != nil {
+		pgnText, err = broadcastPlay(c, model, broadcaster, c.Duration("interval"))
+	} else {
+		// The TUI hands the game over after each move, and is done with it
+		// once it returns
+		model = model.WithGameListener(func(text string) { pgnText = text })
+		err = runPlayModel(model)
+	}
+	if err != nil {
+		return err
+	}
+	if played.Load() == 0 || c.Bool("no-save") {
+		return nil
+	}
+	if err := lazyGameAdder(c.Context, cfg)(pgnText); err != nil {
+		return fmt.Errorf("failed to store the game: %w", err)
+	}
+	fmt.Println("The game was stored in the database")
+	return nil
+}
+
+// followBoard passes the moves played on the DGT board to moves, in SAN,
+// counting them in played, until ctx is done or the board is disconnected
+func followBoard(ctx context.Context, board *dgt.Board, moves chan<- string, played *atomic.Int32) {
+	defer close(moves)
+	logger := logging.Default()
+	start, err := internal.ParseFen(ecoStartFEN)
+	if err != nil {
+		logger.Error("failed to set up the game", "error", err)
+		return
+	}
+	tracker := dgt.NewTracker(start)
+	err = board.Watch(ctx, func(pos dgt.Position) error {
+		b := tracker.Board()
+		for _, m := range tracker.Update(pos) {
+			san := m.San(b)
+			b = b.MakeMove(m)
+			logger.Debug("move played on the DGT board", "move", san, "position", pos.String())
+			select {
+			case moves <- san:
+				played.Add(1)
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		return nil
+	})
+	if err != nil && ctx.Err() == nil {
+		logger.Error("failed to read the DGT board", "error", err)
+	}
+}
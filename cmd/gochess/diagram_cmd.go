@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kyleboon/gochess/internal"
+	"github.com/kyleboon/gochess/internal/render"
+	"github.com/urfave/cli/v2"
+)
+
+// diagramCommand draws a position as an SVG or PNG image
+func diagramCommand(c *cli.Context) error {
+	board, err := internal.ParseFen(c.String("fen"))
+	if err != nil {
+		return err
+	}
+
+	opts := render.Options{
+		Flipped:         c.Bool("flip"),
+		SquareSize:      c.Int("size"),
+		HideCoordinates: c.Bool("no-coordinates"),
+	}
+	if s := c.String("last-move"); s != "" {
+		if opts.LastMove, err = render.ParseSquares(s); err != nil {
+			return err
+		}
+	}
+
+	output := c.String("output")
+	f, err := os.Create(expandPath(output))
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	switch ext := strings.ToLower(filepath.Ext(output)); ext {
+	case ".svg":
+		_, err = f.WriteString(render.SVG(board, opts))
+	case ".png":
+		err = render.PNG(f, board, opts)
+	default:
+		return fmt.Errorf("unsupported image format %q (use .svg or .png)", ext)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write diagram: %w", err)
+	}
+	fmt.Printf("Diagram written to %s\n", output)
+	return nil
+}
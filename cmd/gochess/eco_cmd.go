@@ -0,0 +1,168 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/kyleboon/gochess/internal/eco"
+	"github.com/kyleboon/gochess/internal/output"
+	"github.com/kyleboon/gochess/internal/pgn"
+	"github.com/urfave/cli/v2"
+)
+
+// ecoStartFEN is the standard starting position; only games starting from it
+// are classified by their moves
+const ecoStartFEN = "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
+
+// classifiedGame is the opening of one game, in the --json output of the eco
+// command
+type classifiedGame struct {
+	Game    int    `json:"game"`
+	White   string `json:"white"`
+	Black   string `json:"black"`
+	ECO     string `json:"eco,omitempty"`
+	Opening string `json:"opening,omitempty"`
+}
+
+// ecoLine is an opening line in the --json output of the eco commands
+type ecoLine struct {
+	ECO   string   `json:"eco"`
+	Name  string   `json:"name"`
+	PGN   string   `json:"pgn"`
+	Moves []string `json:"moves"`
+}
+
+// ecoCommand classifies the games of a PGN file, or a single position given
+// as a FEN, using the ECO database
+func ecoCommand(c *cli.Context) error {
+	pgnPath, fen := c.String("pgn"), c.String("fen")
+	if (pgnPath == "") == (fen == "") {
+		return fmt.Errorf("either --pgn or --fen is required")
+	}
+
+	openings, err := eco.NewDatabase()
+	if err != nil {
+		return fmt.Errorf("failed to load ECO database: %w", err)
+	}
+	if fen != "" {
+		return ecoClassifyFEN(c, openings, fen)
+	}
+
+	var in io.Reader = os.Stdin
+	if pgnPath != "-" {
+		f, err := os.Open(expandPath(pgnPath))
+		if err != nil {
+			return fmt.Errorf("failed to open PGN file: %w", err)
+		}
+		defer func() { _ = f.Close() }()
+		in = f
+	}
+
+	results := make([]classifiedGame, 0)
+	reader := pgn.NewReader(in)
+	for n := 1; ; n++ {
+		game, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		var perr *pgn.ParseError
+		if errors.As(err, &perr) {
+			// A game that does not parse is skipped
+			fmt.Fprintf(os.Stderr, "Skipping game %d: %v\n", n, err)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read PGN: %w", err)
+		}
+
+		result := classifiedGame{Game: n, White: game.Tags["White"], Black: game.Tags["Black"]}
+		if game.Root.Board.Fen() == ecoStartFEN {
+			result.ECO, result.Opening, _ = openings.Classify(mainLineSAN(game))
+		}
+		results = append(results, result)
+
+		if !output.JSON(c) {
+			opening := "(unknown opening)"
+			if result.ECO != "" {
+				opening = result.ECO + " " + result.Opening
+			}
+			fmt.Printf("%d. %s - %s: %s\n", n, result.White, result.Black, opening)
+		}
+	}
+
+	if output.JSON(c) {
+		return output.WriteJSON(results)
+	}
+	return nil
+}
+
+// ecoClassifyFEN prints the opening that reaches a position, by any move
+// order
+func ecoClassifyFEN(c *cli.Context, openings *eco.Database, fen string) error {
+	opening, ok := openings.ClassifyFEN(fen)
+	if output.JSON(c) {
+		if !ok {
+			return output.WriteJSON(nil)
+		}
+		return output.WriteJSON(toECOLine(opening))
+	}
+	if !ok {
+		fmt.Println("No ECO opening reaches this position.")
+		return nil
+	}
+	fmt.Printf("%s %s\n  %s\n", opening.ECOCode, opening.Name, opening.PGN)
+	return nil
+}
+
+// ecoShowCommand prints the moves that define an ECO code, followed by the
+// named variations filed under it
+func ecoShowCommand(c *cli.Context) error {
+	code := strings.ToUpper(c.Args().First())
+	if code == "" {
+		return fmt.Errorf("an ECO code is required, e.g. gochess eco show B90")
+	}
+
+	openings, err := eco.NewDatabase()
+	if err != nil {
+		return fmt.Errorf("failed to load ECO database: %w", err)
+	}
+	lines := openings.GetByCode(code)
+	if len(lines) == 0 {
+		return fmt.Errorf("unknown ECO code %q", code)
+	}
+
+	if output.JSON(c) {
+		result := make([]ecoLine, len(lines))
+		for i, line := range lines {
+			result[i] = toECOLine(line)
+		}
+		return output.WriteJSON(result)
+	}
+
+	fmt.Printf("%s %s\n  %s\n", lines[0].ECOCode, lines[0].Name, lines[0].PGN)
+	if len(lines) > 1 {
+		fmt.Printf("\nVariations (%d):\n", len(lines)-1)
+		for _, line := range lines[1:] {
+			fmt.Printf("  %s\n    %s\n", line.Name, line.PGN)
+		}
+	}
+	return nil
+}
+
+// mainLineSAN returns the main line moves of a game in SAN
+func mainLineSAN(game *pgn.Game) []string {
+	var moves []string
+	board := game.Root.Board
+	for node := game.Root.Next; node != nil; node = node.Next {
+		moves = append(moves, node.Move.San(board))
+		board = node.Board
+	}
+	return moves
+}
+
+func toECOLine(opening eco.Opening) ecoLine {
+	return ecoLine{ECO: opening.ECOCode, Name: opening.Name, PGN: opening.PGN, Moves: opening.Moves}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/kyleboon/gochess/internal/db"
+	"github.com/kyleboon/gochess/internal/output"
+	"github.com/urfave/cli/v2"
+)
+
+// endgameMinGames is the minimum number of games an endgame type needs before
+// it is suggested for study.
+const endgameMinGames = 3
+
+// endgameStats prints the results of the given players (all players if
+// empty) by the type of endgame their games reached, to show which endgames
+// are worth studying.
+func endgameStats(c *cli.Context, database *db.DB, players []string) error {
+	asJSON := output.JSON(c) || c.String("format") == "json"
+	out := os.Stdout
+	if asJSON {
+		out = os.Stderr
+	}
+	fmt.Fprintln(out, "Calculating endgame statistics...")
+	stats, err := database.GetEndgameStatsFiltered(c.Context, players)
+	if err != nil {
+		return fmt.Errorf("failed to get endgame statistics: %w", err)
+	}
+
+	if asJSON {
+		if stats == nil {
+			stats = []db.EndgameStats{}
+		}
+		return output.WriteJSON(map[string]interface{}{
+			"endgames": stats,
+		})
+	}
+
+	if len(stats) == 0 {
+		fmt.Println("No games reached an endgame")
+		return nil
+	}
+
+	if c.String("format") == "csv" {
+		fmt.Println("Endgame,Games,Wins,Losses,Draws,WinRate")
+		for _, s := range stats {
+			fmt.Printf("%s,%d,%d,%d,%d,%.1f%%\n", s.Endgame, s.Games, s.Wins, s.Losses, s.Draws, s.WinRate)
+		}
+		return nil
+	}
+
+	perspective := "White's results"
+	if len(players) > 0 {
+		perspective = "your results"
+	}
+	fmt.Printf("\nEndgames (%s):\n", perspective)
+	fmt.Printf("  %-18s %-6s %-6s %-6s %-6s %-8s\n", "ENDGAME", "GAMES", "WINS", "LOSSES", "DRAWS", "WIN RATE")
+	fmt.Println("  " + repeatString("-", 55))
+	for _, s := range stats {
+		fmt.Printf("  %-18s %-6d %-6d %-6d %-6d %.1f%%\n", s.Endgame, s.Games, s.Wins, s.Losses, s.Draws, s.WinRate)
+	}
+
+	// Call out the endgame with the worst results, if played often enough
+	var worst *db.EndgameStats
+	for i := range stats {
+		if stats[i].Games >= endgameMinGames && (worst == nil || stats[i].WinRate < worst.WinRate) {
+			worst = &stats[i]
+		}
+	}
+	if worst != nil && len(players) > 0 {
+		fmt.Printf("\n  Worth studying: %s endgames, won %.1f%% of %d games.\n", worst.Endgame, worst.WinRate, worst.Games)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/kyleboon/gochess/internal/db"
+	"github.com/kyleboon/gochess/internal/parquet"
+	"github.com/urfave/cli/v2"
+)
+
+// trainingColumns are the columns of the training data export
+var trainingColumns = []parquet.Column{
+	{Name: "game_id", Type: parquet.Int64},
+	{Name: "ply", Type: parquet.Int64},
+	{Name: "fen", Type: parquet.String},
+	{Name: "side", Type: parquet.String},
+	{Name: "eval", Type: parquet.Double},
+	{Name: "best_move", Type: parquet.String},
+	{Name: "white", Type: parquet.String},
+	{Name: "black", Type: parquet.String},
+	{Name: "white_elo", Type: parquet.Int64},
+	{Name: "black_elo", Type: parquet.Int64},
+	{Name: "result", Type: parquet.String},
+	{Name: "date", Type: parquet.String},
+}
+
+// trainingRow returns the values of a position for the trainingColumns
+func trainingRow(p db.TrainingPosition) []any {
+	side := "w"
+	if fields := strings.Fields(p.FEN); len(fields) > 1 {
+		side = fields[1]
+	}
+	return []any{
+		p.GameID, p.Ply, p.FEN, side, p.Eval, p.BestMove,
+		p.White, p.Black, p.WhiteElo, p.BlackElo, p.Result, p.Date,
+	}
+}
+
+// exportTrainingAction writes the evaluated positions of the database as
+// training data for evaluation and policy models: CSV, or Parquet when
+// --format is parquet or the output file ends in .parquet. Evaluations are
+// in pawns from White's view. With --from-analysis only the games analyzed
+// by the engine are exported, with its best move in each position
+func exportTrainingAction(c *cli.Context) error {
+	outPath := expandPath(c.String("output"))
+
+	format := strings.ToLower(c.String("format"))
+	if format == "" {
+		format = "csv"
+		if strings.EqualFold(filepath.Ext(outPath), ".parquet") {
+			format = "parquet"
+		}
+	}
+	if format != "csv" && format != "parquet" {
+		return fmt.Errorf("unknown format %q; use csv or parquet", format)
+	}
+
+	database, err := openDatabase(c)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = database.Close() }()
+
+	var w io.Writer = os.Stdout
+	if outPath != "" {
+		file, err := os.Create(outPath)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer func() { _ = file.Close() }()
+		w = file
+	}
+
+	var count int
+	if format == "parquet" {
+		pw := parquet.NewWriter(w, trainingColumns)
+		count, err = database.TrainingPositions(c.Context, c.Bool("from-analysis"), func(p db.TrainingPosition) error {
+			return pw.Write(trainingRow(p)...)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to export positions: %w", err)
+		}
+		if err := pw.Close(); err != nil {
+			return err
+		}
+	} else {
+		cw := csv.NewWriter(w)
+		header := make([]string, len(trainingColumns))
+		for i, col := range trainingColumns {
+			header[i] = col.Name
+		}
+		if err := cw.Write(header); err != nil {
+			return fmt.Errorf("failed to write CSV: %w", err)
+		}
+		count, err = database.TrainingPositions(c.Context, c.Bool("from-analysis"), func(p db.TrainingPosition) error {
+			row := trainingRow(p)
+			record := make([]string, len(row))
+			for i, v := range row {
+				switch v := v.(type) {
+				case float64:
+					record[i] = strconv.FormatFloat(v, 'f', -1, 64)
+				default:
+					record[i] = fmt.Sprint(v)
+				}
+			}
+			return cw.Write(record)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to export positions: %w", err)
+		}
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return fmt.Errorf("failed to write CSV: %w", err)
+		}
+	}
+
+	if outPath != "" {
+		fmt.Printf("Exported %d positions to %s\n", count, outPath)
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kyleboon/gochess/internal"
+	"github.com/urfave/cli/v2"
+)
+
+// fenCommand validates a FEN, optionally plays moves from it, and describes
+// the resulting position: a diagram, its legal moves and whether the game is
+// over
+func fenCommand(c *cli.Context) error {
+	fen := strings.Join(c.Args().Slice(), " ")
+	if fen == "" {
+		return fmt.Errorf("a FEN string is required, e.g. gochess fen \"<fen>\"")
+	}
+
+	board, err := internal.ParseFen(fen)
+	if err != nil {
+		// Flags are only parsed before the FEN
+		for _, arg := range c.Args().Slice() {
+			if len(arg) > 1 && strings.HasPrefix(arg, "-") {
+				return fmt.Errorf("%w (options must come before the FEN)", err)
+			}
+		}
+		return err
+	}
+	if err := board.Validate(); err != nil {
+		return fmt.Errorf("illegal position: %w", err)
+	}
+
+	board, err = applyMoves(board, c.String("apply-moves"))
+	if err != nil {
+		return err
+	}
+
+	if c.Bool("quiet") {
+		fmt.Println(board.Fen())
+		return nil
+	}
+
+	fmt.Printf("FEN: %s\n\n", board.Fen())
+	fmt.Print(diagram(board, c.Bool("unicode"), c.Bool("flip")))
+	fmt.Println()
+
+	side := [2]string{"White", "Black"}[board.SideToMove]
+	fmt.Printf("Side to move: %s\n", side)
+	fmt.Printf("Status:       %s\n", positionStatus(board))
+
+	moves := board.LegalMoves()
+	sans := make([]string, len(moves))
+	for i, mv := range moves {
+		sans[i] = mv.San(board)
+	}
+	sort.Strings(sans)
+	fmt.Printf("Legal moves:  %d\n", len(moves))
+	if len(sans) > 0 {
+		fmt.Printf("  %s\n", strings.Join(sans, " "))
+	}
+	if c.Bool("eval") {
+		fmt.Println()
+		printEvaluation(internal.Eval(board))
+	}
+	return nil
+}
+
+// printEvaluation prints a static evaluation term by term, in pawns
+func printEvaluation(e internal.Evaluation) {
+	pawns := func(cp int) string { return fmt.Sprintf("%+.2f", float64(cp)/100) }
+	fmt.Printf("Evaluation:   %s (static: material, piece squares and mobility)\n", pawns(e.Score()))
+	fmt.Printf("  %-14s %7s %7s %7s\n", "", "White", "Black", "Diff")
+	diff := e.Diff()
+	rows := []struct {
+		name         string
+		white, black int
+		diff         int
+	}{
+		{"Material", e.White.Material, e.Black.Material, diff.Material},
+		{"Piece squares", e.White.PieceSquares, e.Black.PieceSquares, diff.PieceSquares},
+		{"Mobility", e.White.Mobility, e.Black.Mobility, diff.Mobility},
+	}
+	for _, r := range rows {
+		fmt.Printf("  %-14s %7.2f %7.2f %7s\n", r.name, float64(r.white)/100, float64(r.black)/100, pawns(r.diff))
+	}
+}
+
+// applyMoves plays moves, given in SAN or UCI notation and separated by
+// spaces, from board. Move numbers such as "1." or "12..." are skipped.
+func applyMoves(board *internal.Board, moves string) (*internal.Board, error) {
+	for _, s := range strings.Fields(moves) {
+		if strings.HasSuffix(s, ".") {
+			continue
+		}
+		mv, err := board.ParseMove(s)
+		if err != nil || mv == internal.NullMove {
+			side := [2]string{"White", "Black"}[board.SideToMove]
+			if err != nil {
+				return nil, fmt.Errorf("illegal move %q for %s at move %d: %w", s, side, board.MoveNr, err)
+			}
+			return nil, fmt.Errorf("illegal move %q for %s at move %d", s, side, board.MoveNr)
+		}
+		board = board.MakeMove(mv)
+	}
+	return board, nil
+}
+
+// positionStatus describes whether the side to move is in check, and whether
+// the game is over or can be claimed drawn.
+func positionStatus(board *internal.Board) string {
+	check, mate := board.IsCheckOrMate()
+	switch {
+	case check && mate:
+		return "checkmate"
+	case mate:
+		return "stalemate"
+	case board.HasInsufficientMaterial():
+		return "draw by insufficient material"
+	case board.Rule50 >= 100 && check:
+		return "check, draw claimable by the fifty-move rule"
+	case board.Rule50 >= 100:
+		return "draw claimable by the fifty-move rule"
+	case check:
+		return "check"
+	}
+	return "in play"
+}
+
+// diagram draws board as text, with White at the bottom unless flipped.
+// Pieces are letters, or chess symbols if unicode is set.
+func diagram(board *internal.Board, unicode, flip bool) string {
+	files := "a b c d e f g h"
+	var b strings.Builder
+	for i := 7; i >= 0; i-- {
+		rank := i
+		if flip {
+			rank = 7 - i
+		}
+		fmt.Fprintf(&b, "%d ", rank+1)
+		for j := 0; j < 8; j++ {
+			file := j
+			if flip {
+				file = 7 - j
+			}
+			b.WriteString(" ")
+			p := board.Piece[internal.Square(file, rank)]
+			switch {
+			case p == internal.NoPiece:
+				b.WriteString(".")
+			case unicode:
+				b.WriteRune(internal.Glyphs[p])
+			default:
+				b.WriteRune(internal.PieceRunes[p])
+			}
+		}
+		b.WriteString("\n")
+	}
+	if flip {
+		files = "h g f e d c b a"
+	}
+	fmt.Fprintf(&b, "   %s\n", files)
+	return b.String()
+}
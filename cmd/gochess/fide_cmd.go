@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/kyleboon/gochess/internal/db"
+	"github.com/kyleboon/gochess/internal/fide"
+	"github.com/kyleboon/gochess/internal/logging"
+	"github.com/kyleboon/gochess/internal/output"
+	"github.com/urfave/cli/v2"
+)
+
+// fideRatingsCommand looks up the players named by FIDE ID in the games and
+// lists their current ratings
+func fideRatingsCommand(c *cli.Context) error {
+	database, err := openDatabase(c)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = database.Close() }()
+
+	if !c.Bool("offline") {
+		if err := fetchFideRatings(c, database, c.Bool("refresh"), output.Messages(c)); err != nil {
+			return err
+		}
+	}
+
+	players, err := database.FidePlayers(c.Context)
+	if err != nil {
+		return err
+	}
+	if output.JSON(c) {
+		if players == nil {
+			players = []db.FidePlayer{}
+		}
+		return output.WriteJSON(map[string]interface{}{"players": players})
+	}
+	if len(players) == 0 {
+		fmt.Println("No FIDE ratings stored: games name their players by FIDE ID in WhiteFideId and BlackFideId tags")
+		return nil
+	}
+
+	fmt.Printf("%-10s %-30s %-5s %-16s %-8s %-8s %-8s\n", "FIDE ID", "NAME", "TITLE", "FEDERATION", "STD", "RAPID", "BLITZ")
+	fmt.Println(repeatString("-", 91))
+	for _, p := range players {
+		name := p.Name
+		if name == "" {
+			name = "(not in the rating list)"
+		}
+		fmt.Printf("%-10d %-30s %-5s %-16s %-8s %-8s %-8s\n", p.ID, name, p.Title, p.Federation,
+			formatFideRating(p.Standard), formatFideRating(p.Rapid), formatFideRating(p.Blitz))
+	}
+	return nil
+}
+
+// formatFideRating shows a rating, "-" if unrated
+func formatFideRating(rating int) string {
+	if rating == 0 {
+		return "-"
+	}
+	return fmt.Sprint(rating)
+}
+
+// fetchFideRatings fetches the FIDE ratings of the players named by FIDE ID
+// in the games, only those never fetched unless refresh is set
+func fetchFideRatings(c *cli.Context, database *db.DB, refresh bool, out io.Writer) error {
+	client := fide.NewClientWithLogger(logging.Default())
+	count, err := fide.FetchRatings(c.Context, client, database, refresh, out)
+	if count > 0 {
+		fmt.Fprintf(out, "Stored the FIDE ratings of %d players\n", count)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to fetch FIDE ratings: %w", err)
+	}
+	return nil
+}
+
+// dbImportCommand imports PGN games into the database and, with
+// --fide-ratings, then fetches the FIDE ratings of their players. Failing to
+// fetch the ratings does not fail the import.
+func dbImportCommand(c *cli.Context) error {
+	if err := db.ImportCommand(c); err != nil {
+		return err
+	}
+	if !c.Bool("fide-ratings") || c.Bool("dry-run") {
+		return nil
+	}
+	database, err := openDatabase(c)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = database.Close() }()
+	out := output.Messages(c)
+	if err := fetchFideRatings(c, database, false, out); err != nil {
+		fmt.Fprintf(out, "Warning: %v\n", err)
+	}
+	return nil
+}
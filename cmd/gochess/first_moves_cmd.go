@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+
+	"github.com/kyleboon/gochess/internal/db"
+	"github.com/kyleboon/gochess/internal/output"
+	"github.com/urfave/cli/v2"
+)
+
+// firstMoveStats prints the first moves of the given players as White and
+// their responses as Black, overall, by time class and by year, as a
+// snapshot of how their repertoire drifts.
+func firstMoveStats(c *cli.Context, database *db.DB, players []string) error {
+	if len(players) == 0 {
+		return fmt.Errorf("--first-moves needs the games of a player; use --player")
+	}
+	asJSON := output.JSON(c) || c.String("format") == "json"
+	out := os.Stdout
+	if asJSON {
+		out = os.Stderr
+	}
+	fmt.Fprintln(out, "Calculating first moves...")
+	report, err := database.GetFirstMoveStats(c.Context, players)
+	if err != nil {
+		return fmt.Errorf("failed to get first moves: %w", err)
+	}
+
+	if asJSON {
+		return output.WriteJSON(report)
+	}
+
+	if len(report.Overall) == 0 {
+		fmt.Println("No finished games found")
+		return nil
+	}
+
+	if c.String("format") == "csv" {
+		fmt.Println("Group,Color,Move,Games,Wins,Losses,Draws,Score")
+		printCSV := func(group string, moves []db.FirstMoveStats) {
+			for _, m := range moves {
+				fmt.Printf("%s,%s,%s,%d,%d,%d,%d,%.1f%%\n", group, m.Color, m.Move, m.Games, m.Wins, m.Losses, m.Draws, m.Score)
+			}
+		}
+		printCSV("all", report.Overall)
+		for _, class := range db.TimeClasses {
+			printCSV(class, report.ByTimeClass[class])
+		}
+		for _, year := range slices.Sorted(maps.Keys(report.ByYear)) {
+			printCSV(year, report.ByYear[year])
+		}
+		return nil
+	}
+
+	printFirstMoves("All", report.Overall)
+	for _, class := range db.TimeClasses {
+		if moves := report.ByTimeClass[class]; len(moves) > 0 {
+			printFirstMoves(class, moves)
+		}
+	}
+	for _, year := range slices.Sorted(maps.Keys(report.ByYear)) {
+		printFirstMoves(year, report.ByYear[year])
+	}
+	return nil
+}
+
+// printFirstMoves prints the first moves of one group of games, White's then
+// Black's, titled by the group
+func printFirstMoves(title string, moves []db.FirstMoveStats) {
+	fmt.Printf("\n%s games:\n", title)
+	fmt.Printf("  %-6s %-12s %-6s %-12s %-6s\n", "COLOR", "MOVE", "GAMES", "W-L-D", "SCORE")
+	for _, m := range moves {
+		fmt.Printf("  %-6s %-12s %-6d %-12s %.1f%%\n", m.Color, m.Move, m.Games,
+			fmt.Sprintf("%d-%d-%d", m.Wins, m.Losses, m.Draws), m.Score)
+	}
+}
@@ -1,17 +1,29 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/kyleboon/gochess/internal/config"
 	"github.com/kyleboon/gochess/internal/db"
+	"github.com/kyleboon/gochess/internal/eco"
 	"github.com/kyleboon/gochess/internal/tui"
 	"github.com/urfave/cli/v2"
 )
 
 // gameListTUICommand shows an interactive game list browser
 func gameListTUICommand(c *cli.Context) error {
-	dbPath := expandPath(c.String("database"))
+	if err := logToFileForTUI(c); err != nil {
+		return err
+	}
+	dbPath, err := config.DatabasePath(c)
+	if err != nil {
+		return err
+	}
+	dbPath = expandPath(dbPath)
 	limit := c.Int("limit")
 	offset := c.Int("offset")
 
@@ -29,9 +41,34 @@ func gameListTUICommand(c *cli.Context) error {
 	if date := c.String("date"); date != "" {
 		criteria["date"] = date
 	}
+	if endgame := c.String("endgame"); endgame != "" {
+		if err := db.ValidateEndgame(endgame); err != nil {
+			return err
+		}
+		criteria["endgame"] = endgame
+	}
+	if motif := c.String("motif"); motif != "" {
+		if err := db.ValidateMotif(motif); err != nil {
+			return err
+		}
+		criteria["motif"] = motif
+	}
+	if timeClass := strings.ToLower(c.String("time-class")); timeClass != "" {
+		if err := db.ValidateTimeClass(timeClass); err != nil {
+			return err
+		}
+		criteria["time_class"] = timeClass
+	}
+	for _, filter := range c.StringSlice("tag") {
+		name, value, err := db.ParseTagFilter(filter)
+		if err != nil {
+			return err
+		}
+		criteria[db.TagCriterion+name] = value
+	}
 
 	// Open database connection
-	database, err := db.New(dbPath)
+	database, err := db.Open(dbPath, db.FlagOpenMode(c))
 	if err != nil {
 		return fmt.Errorf("failed to open database: %w", err)
 	}
@@ -54,13 +91,175 @@ func gameListTUICommand(c *cli.Context) error {
 		games[i] = tui.MapToGame(gameMap)
 	}
 
+	cfg, err := config.LoadOrDefault()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// Games of the configured users are shown from their side of the board
+	var players []string
+	players = append(players, cfg.GetUsernames("chesscom")...)
+	players = append(players, cfg.GetUsernames("lichess")...)
+
+	// Stored evaluations are loaded when a game is opened
+	loadEvals := func(gameID int) (map[int]float64, error) {
+		positions, err := database.GetPositionsForGame(c.Context, gameID)
+		if err != nil {
+			return nil, err
+		}
+		evals := make(map[int]float64)
+		for _, pos := range positions {
+			if pos.Evaluation != nil {
+				evals[pos.MoveNumber] = *pos.Evaluation
+			}
+		}
+		return evals, nil
+	}
+
+	// The user's own notes are shown above the board
+	loadNote := func(gameID int) (string, error) {
+		return database.GetNote(c.Context, gameID)
+	}
+
+	// Games are listed without their moves, which are read when opened
+	loadPGN := func(gameID int) (string, error) {
+		game, err := database.GetGame(c.Context, gameID)
+		if err != nil {
+			return "", err
+		}
+		return game.PGNText, nil
+	}
+
+	// Comments, NAGs and variations edited in the TUI are saved as PGN
+	saveGame := func(gameID int, pgnText string) error {
+		return database.UpdateGamePGN(c.Context, gameID, pgnText)
+	}
+
+	keys, err := keyMap(cfg)
+	if err != nil {
+		return err
+	}
+
+	openings, err := eco.NewDatabase()
+	if err != nil {
+		return fmt.Errorf("failed to load ECO database: %w", err)
+	}
+
+	model := tui.NewGameListModel(games).
+		WithKeys(keys).
+		WithPlayers(players).
+		WithBoardOptions(boardOptions(cfg), settingsSaver(cfg)).
+		WithPGN(loadPGN).
+		WithEvaluations(loadEvals).
+		WithNotes(loadNote).
+		WithGameSaver(saveGame).
+		WithGameAdder(gameAdder(c.Context, database)).
+		WithOpenings(openings)
+
+	// The last session is offered for restoring, and saved again on quitting
+	sessionPath, err := config.DefaultSessionPath()
+	if err != nil {
+		return err
+	}
+	session, err := sessionToRestore(c, sessionPath)
+	if err != nil {
+		return err
+	}
+	if session != nil {
+		var listed bool
+		if model, listed = model.WithSession(*session); !listed {
+			fmt.Printf("%s is not among the games listed; starting from the list\n", session.Title)
+		}
+	}
+
 	// Start the TUI
-	model := tui.NewGameListModel(games)
-	p := tea.NewProgram(model, tea.WithAltScreen())
+	p := tea.NewProgram(model, tea.WithAltScreen(), tea.WithMouseCellMotion())
 
-	if _, err := p.Run(); err != nil {
+	final, err := p.Run()
+	if err != nil {
 		return fmt.Errorf("failed to run TUI: %w", err)
 	}
+	if final, ok := final.(tui.GameListModel); ok {
+		if err := saveSession(sessionPath, final.Session()); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
+
+// settingsSaver returns a function that writes the board settings chosen in
+// the TUI back to the config file.
+func settingsSaver(cfg *config.Config) tui.SaveSettingsFunc {
+	return func(opts tui.BoardOptions) error {
+		if cfg.TUI == nil {
+			cfg.TUI = &config.TUIConfig{}
+		}
+		cfg.TUI.Theme = opts.Theme.Name
+		cfg.TUI.Pieces = opts.Pieces.String()
+		cfg.TUI.Highlight = opts.Highlight.String()
+		cfg.TUI.HideCoordinates = opts.HideCoordinates
+		return cfg.SaveDefault()
+	}
+}
+
+// gameAdder returns a function that imports a game exported from the TUI
+// into database.
+func gameAdder(ctx context.Context, database *db.DB) tui.AddGameFunc {
+	return func(pgnText string) error {
+		tmpfile, err := os.CreateTemp("", "gochess-*.pgn")
+		if err != nil {
+			return fmt.Errorf("failed to create temporary file: %w", err)
+		}
+		tmpPath := tmpfile.Name()
+		defer func() { _ = os.Remove(tmpPath) }()
+
+		if _, err := tmpfile.WriteString(pgnText); err != nil {
+			_ = tmpfile.Close()
+			return fmt.Errorf("failed to write to temporary file: %w", err)
+		}
+		_ = tmpfile.Close()
+
+		count, errs := database.ImportPGN(ctx, tmpPath)
+		if len(errs) > 0 {
+			return errs[0]
+		}
+		if count == 0 {
+			return fmt.Errorf("the game is already in the database")
+		}
+		return nil
+	}
+}
+
+// keyMap returns the TUI key bindings, with the keys remapped in the config
+// file.
+func keyMap(cfg *config.Config) (tui.KeyMap, error) {
+	keys := tui.DefaultKeyMap()
+	if cfg.TUI == nil {
+		return keys, nil
+	}
+	if err := keys.Remap(cfg.TUI.Keys); err != nil {
+		return keys, fmt.Errorf("invalid tui.keys in config: %w", err)
+	}
+	return keys, nil
+}
+
+// boardOptions returns the board settings from the config file. Unknown
+// values fall back to the defaults.
+func boardOptions(cfg *config.Config) tui.BoardOptions {
+	opts := tui.BoardOptions{Theme: tui.DefaultTheme()}
+	if cfg.TUI == nil {
+		return opts
+	}
+	if theme, ok := tui.ThemeByName(cfg.TUI.Theme); ok {
+		opts.Theme = theme
+	}
+	if pieces, err := tui.ParsePieceSet(cfg.TUI.Pieces); err == nil {
+		opts.Pieces = pieces
+	}
+	if highlight, err := tui.ParseHighlightStyle(cfg.TUI.Highlight); err == nil {
+		opts.Highlight = highlight
+	}
+	opts.HideCoordinates = cfg.TUI.HideCoordinates
+	return opts
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kyleboon/gochess/internal/config"
+	"github.com/kyleboon/gochess/internal/db"
+	"github.com/kyleboon/gochess/internal/render"
+	"github.com/urfave/cli/v2"
+)
+
+// gifCommand renders the positions of a game as an animated GIF or PNG
+func gifCommand(c *cli.Context) error {
+	gameID := c.Int("id")
+	pgnPath := c.String("pgn")
+
+	var pgnText string
+	switch {
+	case pgnPath != "":
+		data, err := os.ReadFile(expandPath(pgnPath))
+		if err != nil {
+			return fmt.Errorf("failed to read PGN file: %w", err)
+		}
+		pgnText = string(data)
+	case gameID > 0:
+		dbPath, err := config.DatabasePath(c)
+		if err != nil {
+			return err
+		}
+		database, err := db.New(expandPath(dbPath))
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		defer func() { _ = database.Close() }()
+
+		game, err := database.GetGame(c.Context, gameID)
+		if err != nil {
+			return fmt.Errorf("failed to get game: %w", err)
+		}
+		pgnText = game.PGNText
+	default:
+		return fmt.Errorf("either --id or --pgn is required")
+	}
+
+	game, err := parseSingleGame(pgnText)
+	if err != nil {
+		return err
+	}
+
+	delay := c.Duration("delay")
+	if delay <= 0 {
+		return fmt.Errorf("--delay must be positive")
+	}
+	opts := render.Options{
+		Flipped:    c.Bool("flip"),
+		SquareSize: c.Int("size"),
+	}
+	frames := render.GameFrames(game)
+
+	output := c.String("output")
+	ext := strings.ToLower(filepath.Ext(output))
+	if ext != ".gif" && ext != ".png" && ext != ".apng" {
+		return fmt.Errorf("unsupported image format %q (use .gif, .png or .apng)", ext)
+	}
+	f, err := os.Create(expandPath(output))
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	if ext == ".gif" {
+		err = render.GIF(f, frames, opts, delay)
+	} else {
+		err = render.APNG(f, frames, opts, delay)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write animation: %w", err)
+	}
+	fmt.Printf("Animation of %d positions written to %s\n", len(frames), output)
+	return nil
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/kyleboon/gochess/internal/config"
+	"github.com/kyleboon/gochess/internal/db"
+	"github.com/kyleboon/gochess/internal/fics"
+	"github.com/kyleboon/gochess/internal/logging"
+	"github.com/kyleboon/gochess/internal/output"
+	"github.com/urfave/cli/v2"
+)
+
+// historyImport is the result of importing one file of a player's history
+// from an older server, in the --json output of fics import and icc import
+type historyImport struct {
+	File       string `json:"file"`
+	Imported   int    `json:"imported"`
+	Duplicates int    `json:"duplicates"`
+	Variants   int    `json:"variants_skipped,omitempty"`
+	Errors     int    `json:"errors"`
+}
+
+// ficsImportCommand imports FICS history listings and PGN exports of FICS
+// games (journals, ficsgames.org downloads), tagging them with the account
+// they came from
+func ficsImportCommand(c *cli.Context) error {
+	if len(c.StringSlice("history")) == 0 && len(c.StringSlice("pgn")) == 0 {
+		return fmt.Errorf("give --history or --pgn files to import")
+	}
+	database, err := openHistoryDatabase(c)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = database.Close() }()
+
+	var results []historyImport
+	for _, path := range c.StringSlice("history") {
+		result, err := importFICSHistory(c, database, path)
+		if err != nil {
+			return err
+		}
+		results = append(results, *result)
+	}
+	for _, path := range c.StringSlice("pgn") {
+		results = append(results, importHistoryPGN(c, database, "fics", path))
+	}
+	return printHistoryImports(c, results)
+}
+
+// iccImportCommand imports PGN exports of Internet Chess Club games, tagging
+// them with the account they came from
+func iccImportCommand(c *cli.Context) error {
+	if len(c.StringSlice("pgn")) == 0 {
+		return fmt.Errorf("give --pgn files to import")
+	}
+	database, err := openHistoryDatabase(c)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = database.Close() }()
+
+	var results []historyImport
+	for _, path := range c.StringSlice("pgn") {
+		results = append(results, importHistoryPGN(c, database, "icc", path))
+	}
+	return printHistoryImports(c, results)
+}
+
+// openHistoryDatabase opens the database games are imported into
+func openHistoryDatabase(c *cli.Context) (*db.DB, error) {
+	dbPath, err := config.DatabasePath(c)
+	if err != nil {
+		return nil, err
+	}
+	database, err := db.NewWithLogger(expandPath(dbPath), logging.Default())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	return database, nil
+}
+
+// importFICSHistory imports the games of a saved FICS history listing. They
+// have no moves, only their players, ratings, opening code and result.
+// Games of chess variants are skipped.
+func importFICSHistory(c *cli.Context, database *db.DB, path string) (*historyImport, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	listed, entries, err := fics.ParseHistory(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history %s: %w", path, err)
+	}
+	username := c.String("username")
+	if username == "" {
+		username = listed
+	}
+	if username == "" {
+		return nil, fmt.Errorf("%s does not name its player: give --username", path)
+	}
+
+	result := &historyImport{File: path}
+	var games strings.Builder
+	for _, entry := range entries {
+		if entry.Variant() {
+			result.Variants++
+			continue
+		}
+		games.WriteString(entry.PGN(username))
+		games.WriteString("\n")
+	}
+	imported := database.ImportPGNReader(c.Context, path, strings.NewReader(games.String()),
+		db.ImportOptions{Tags: db.SourceAccountTags("fics", username)})
+	result.Imported, result.Duplicates, result.Errors = imported.Imported, imported.Duplicates, len(imported.Errors)
+	return result, nil
+}
+
+// importHistoryPGN imports a PGN export of games played on platform, tagged
+// with --username's account when given
+func importHistoryPGN(c *cli.Context, database *db.DB, platform, path string) historyImport {
+	var opts db.ImportOptions
+	if username := c.String("username"); username != "" {
+		opts.Tags = db.SourceAccountTags(platform, username)
+	}
+	imported := database.ImportPGNWithOptions(c.Context, path, opts)
+	return historyImport{File: path, Imported: imported.Imported, Duplicates: imported.Duplicates, Errors: len(imported.Errors)}
+}
+
+// printHistoryImports reports what was imported from each file
+func printHistoryImports(c *cli.Context, results []historyImport) error {
+	if output.JSON(c) {
+		return output.WriteJSON(map[string]interface{}{"files": results})
+	}
+	total := 0
+	for _, r := range results {
+		fmt.Printf("%s: %d games imported, %d duplicates", r.File, r.Imported, r.Duplicates)
+		if r.Variants > 0 {
+			fmt.Printf(", %d variant games skipped", r.Variants)
+		}
+		if r.Errors > 0 {
+			fmt.Printf(", %d errors", r.Errors)
+		}
+		fmt.Println()
+		total += r.Imported
+	}
+	fmt.Printf("Imported %d games\n", total)
+	return nil
+}
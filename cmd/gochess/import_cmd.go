@@ -1,7 +1,9 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"log/slog"
 	"time"
 
@@ -10,9 +12,32 @@ import (
 	"github.com/kyleboon/gochess/internal/db"
 	"github.com/kyleboon/gochess/internal/lichess"
 	"github.com/kyleboon/gochess/internal/logging"
+	"github.com/kyleboon/gochess/internal/notify"
+	"github.com/kyleboon/gochess/internal/output"
 	"github.com/urfave/cli/v2"
 )
 
+// importedSource is the result of importing from one source, in the --json
+// output of the import command
+type importedSource struct {
+	Source   string `json:"source"`
+	Username string `json:"username"`
+	Imported int    `json:"imported"`
+	Error    string `json:"error,omitempty"`
+}
+
+// name names the account in sync summaries, as in "chesscom:alice"
+func (s importedSource) name() string {
+	return s.Source + ":" + s.Username
+}
+
+// importResult is the --json output of the import command
+type importResult struct {
+	Sources    []importedSource `json:"sources"`
+	Imported   int              `json:"imported"`
+	TotalGames int              `json:"total_games"`
+}
+
 // ImportCommand is the unified import command that imports from all configured sources
 func ImportCommand(c *cli.Context) error {
 	verbose := c.Bool("verbose")
@@ -24,96 +49,142 @@ func ImportCommand(c *cli.Context) error {
 		return fmt.Errorf("failed to load configuration: %w", err)
 	}
 
-	// Determine log level: CLI flag takes precedence over config file
-	// If the flag was set explicitly, use it; otherwise use config
-	logLevel := cfg.GetLogLevel()
-	if c.IsSet("log-level") {
-		logLevel = c.String("log-level")
-	}
-
-	// Create logger with the determined log level
-	logger := createLogger(logLevel)
+	logger := logging.Default()
+	out := output.Messages(c)
 
 	// Check if any sources are configured
 	if !cfg.HasAnySource() {
-		fmt.Println("No game sources configured.")
-		fmt.Println("Run 'gochess config init' to set up your configuration.")
+		fmt.Fprintln(out, "No game sources configured.")
+		fmt.Fprintln(out, "Run 'gochess config init' to set up your configuration.")
+		if output.JSON(c) {
+			return output.WriteJSON(importResult{Sources: []importedSource{}})
+		}
 		return nil
 	}
 
 	// If --full is specified, clear last import times
 	if full {
-		fmt.Println("Full import requested - fetching all available games...")
+		fmt.Fprintln(out, "Full import requested - fetching all available games...")
 		cfg.LastImport = make(map[string]time.Time)
 	}
 
 	// Open database
-	fmt.Printf("Opening database at %s...\n", cfg.DatabasePath)
+	fmt.Fprintf(out, "Opening database at %s...\n", cfg.DatabasePath)
 	database, err := db.NewWithLogger(cfg.DatabasePath, logger)
 	if err != nil {
 		return fmt.Errorf("failed to open database: %w", err)
 	}
 	defer func() { _ = database.Close() }()
 
-	totalGames := 0
-	hasErrors := false
-
-	// Import from Chess.com if configured
-	if cfg.ChessCom != nil && cfg.ChessCom.Username != "" {
-		fmt.Println("\n=== Importing from Chess.com ===")
-		count, err := chesscom.ImportFromConfig(c.Context, cfg, database, logger, verbose)
-		if err != nil {
-			fmt.Printf("Error importing from Chess.com: %v\n", err)
-			hasErrors = true
-		} else {
-			totalGames += count
-		}
-	}
-
-	// Import from Lichess if configured
-	if cfg.Lichess != nil && cfg.Lichess.Username != "" {
-		fmt.Println("\n=== Importing from Lichess ===")
-		count, err := lichess.ImportFromConfig(c.Context, cfg, database, logger, verbose)
-		if err != nil {
-			fmt.Printf("Error importing from Lichess: %v\n", err)
-			hasErrors = true
-		} else {
-			totalGames += count
-		}
+	sources, totalGames, hasErrors := importSources(c.Context, cfg, database, logger, out, verbose)
+	if c.Bool("player-profiles") {
+		fetchOpponentProfiles(c.Context, cfg, database, logger, out)
 	}
 
 	// Get current game count in database
 	currentCount, err := database.GetGameCount(c.Context)
 	if err == nil {
-		fmt.Printf("\n=== Import Summary ===\n")
-		fmt.Printf("Games imported this session: %d\n", totalGames)
-		fmt.Printf("Total games in database: %d\n", currentCount)
+		fmt.Fprintf(out, "\n=== Import Summary ===\n")
+		fmt.Fprintf(out, "Games imported this session: %d\n", totalGames)
+		fmt.Fprintf(out, "Total games in database: %d\n", currentCount)
+	}
+
+	if c.Bool("notify") {
+		notifyImport(c, cfg, logger, sources, totalGames, currentCount)
+	}
+
+	if output.JSON(c) {
+		if err := output.WriteJSON(importResult{Sources: sources, Imported: totalGames, TotalGames: currentCount}); err != nil {
+			return err
+		}
 	}
 
 	if hasErrors {
-		fmt.Println("\nSome imports failed. Use --verbose to see more details.")
+		fmt.Fprintln(out, "\nSome imports failed. Use --verbose to see more details.")
 		return fmt.Errorf("some imports failed")
 	}
 
 	if totalGames == 0 {
-		fmt.Println("\nNo new games to import.")
+		fmt.Fprintln(out, "\nNo new games to import.")
 	}
 
 	return nil
 }
 
-// createLogger creates a logger with the specified log level
-func createLogger(logLevelStr string) *slog.Logger {
-	var level logging.Level
-	switch logLevelStr {
-	case "debug":
-		level = logging.LevelDebug
-	case "warn":
-		level = logging.LevelWarn
-	case "error":
-		level = logging.LevelError
-	default:
-		level = logging.LevelInfo
-	}
-	return logging.NewWithLevel(level)
+// fetchOpponentProfiles stores the profiles of the Chess.com opponents of
+// the configured players that were not fetched before. Failures are
+// reported but do not fail the import.
+func fetchOpponentProfiles(ctx context.Context, cfg *config.Config, database *db.DB, logger *slog.Logger, out io.Writer) {
+	players := cfg.GetUsernames("chesscom")
+	if len(players) == 0 {
+		return
+	}
+	client := chesscom.NewClientWithLogger(logger)
+	count, err := chesscom.FetchOpponentProfiles(ctx, client, database, players, out)
+	if err != nil {
+		fmt.Fprintf(out, "Warning: failed to fetch opponent profiles: %v\n", err)
+	}
+	if count > 0 {
+		fmt.Fprintf(out, "Stored the profiles of %d opponents\n", count)
+	}
+}
+
+// notifyImport sends the summary of an import to the notification
+// destinations of cfg. Failures are reported but do not fail the import.
+func notifyImport(c *cli.Context, cfg *config.Config, logger *slog.Logger, sources []importedSource, imported, total int) {
+	notifier := notify.FromConfig(cfg.Notify, logger)
+	if !notifier.Enabled() {
+		fmt.Fprintln(output.Messages(c), "No notification destinations configured.")
+		return
+	}
+	summary := notify.SyncSummary{GamesAdded: imported, TotalGames: total}
+	for _, source := range sources {
+		if source.Error != "" {
+			if summary.Errors == nil {
+				summary.Errors = make(map[string]string)
+			}
+			summary.Errors[source.name()] = source.Error
+		}
+	}
+	if err := notifier.Send(c.Context, notify.NewSyncEvent(summary)); err != nil {
+		fmt.Fprintf(output.Messages(c), "Failed to send notification: %v\n", err)
+	}
+}
+
+// importSources imports the new games of every configured account,
+// returning the result per account, the number of games imported and
+// whether any account failed
+func importSources(ctx context.Context, cfg *config.Config, database *db.DB, logger *slog.Logger, out io.Writer, verbose bool) ([]importedSource, int, bool) {
+	totalGames := 0
+	hasErrors := false
+	var sources []importedSource
+
+	platforms := []struct {
+		source, name string
+		importGames  func(ctx context.Context, cfg *config.Config, username string, database *db.DB, logger *slog.Logger, out io.Writer, verbose bool) (int, error)
+	}{
+		{"chesscom", "Chess.com", chesscom.ImportFromConfig},
+		{"lichess", "Lichess", lichess.ImportFromConfig},
+	}
+	for _, platform := range platforms {
+		usernames := cfg.GetUsernames(platform.source)
+		for _, username := range usernames {
+			if len(usernames) > 1 {
+				fmt.Fprintf(out, "\n=== Importing from %s (%s) ===\n", platform.name, username)
+			} else {
+				fmt.Fprintf(out, "\n=== Importing from %s ===\n", platform.name)
+			}
+			count, err := platform.importGames(ctx, cfg, username, database, logger, out, verbose)
+			source := importedSource{Source: platform.source, Username: username, Imported: count}
+			if err != nil {
+				fmt.Fprintf(out, "Error importing from %s: %v\n", platform.name, err)
+				hasErrors = true
+				source.Error = err.Error()
+			} else {
+				totalGames += count
+			}
+			sources = append(sources, source)
+		}
+	}
+	return sources, totalGames, hasErrors
 }
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/kyleboon/gochess/internal/config"
+	"github.com/kyleboon/gochess/internal/logging"
+	"github.com/urfave/cli/v2"
+)
+
+// logFile is the file logs are written to, if any, closed when the app exits
+var logFile *os.File
+
+// setupLogging configures the default logger from the global log flags and
+// the log_level of the config file. Logs go to stderr unless --log-file is
+// given.
+func setupLogging(c *cli.Context) error {
+	var out io.Writer = os.Stderr
+	if path := c.String("log-file"); path != "" {
+		f, err := openLogFile(path)
+		if err != nil {
+			return err
+		}
+		out = f
+	}
+	return configureLogger(c, out)
+}
+
+// logToFileForTUI moves logging off the terminal while a TUI is running:
+// unless --log-file is already set, logs go to ~/.gochess/gochess.log
+func logToFileForTUI(c *cli.Context) error {
+	if logFile != nil {
+		return nil
+	}
+	path, err := config.DefaultLogPath()
+	if err != nil {
+		return err
+	}
+	f, err := openLogFile(path)
+	if err != nil {
+		return err
+	}
+	return configureLogger(c, f)
+}
+
+// closeLogging closes the log file, if logs were written to one
+func closeLogging(*cli.Context) error {
+	if logFile == nil {
+		return nil
+	}
+	logging.SetDefault(nil)
+	err := logFile.Close()
+	logFile = nil
+	return err
+}
+
+func openLogFile(path string) (*os.File, error) {
+	path = expandPath(path)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log file: %w", err)
+	}
+	if logFile != nil {
+		_ = logFile.Close()
+	}
+	logFile = f
+	return f, nil
+}
+
+func configureLogger(c *cli.Context, out io.Writer) error {
+	if c.Bool("quiet") {
+		out = io.Discard
+	}
+
+	levelName := c.String("log-level")
+	if !c.IsSet("log-level") {
+		cfg, err := config.LoadOrDefault()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		levelName = cfg.GetLogLevel()
+	}
+	level, err := logging.ParseLevel(levelName)
+	if err != nil {
+		return err
+	}
+	format, err := logging.ParseFormat(c.String("log-format"))
+	if err != nil {
+		return err
+	}
+
+	logging.SetDefault(logging.New(logging.Config{Level: level, Format: format, Output: out}))
+	return nil
+}
@@ -5,33 +5,68 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/kyleboon/gochess/internal/chesscom"
 	"github.com/kyleboon/gochess/internal/config"
 	"github.com/kyleboon/gochess/internal/db"
 	"github.com/kyleboon/gochess/internal/lichess"
+	"github.com/kyleboon/gochess/internal/output"
+	"github.com/kyleboon/gochess/internal/render"
+	"github.com/kyleboon/gochess/internal/server"
 	"github.com/urfave/cli/v2"
 )
 
 const (
-	defaultDepth    = 18
-	defaultLines    = 1
-	defaultLogLevel = "info"
+	defaultDepth           = 18
+	defaultLines           = 1
+	defaultReviewDepth     = 14
+	defaultReviewLines     = 2
+	defaultSuiteTime       = 1.0
+	defaultPerftDepth      = 4
+	defaultMovegenTime     = 1.0
+	defaultBenchThreshold  = 5.0
+	defaultTheoryMinGames  = 1
+	defaultTheoryReplies   = 5
+	defaultRepertoireGames = 20
+	defaultRatingK         = 20
+	defaultGamesPerFile    = 1000
+	defaultTreeDepth       = 8
+	defaultStatsTreeDepth  = 6
+	defaultTimeControl     = "300+3"
+	defaultLogFormat       = "text"
+	defaultListenAddr      = "localhost:8080"
+	defaultSyncInterval    = 6 * time.Hour
 )
 
 func main() {
 	app := &cli.App{
-		Name:  "gochess",
-		Usage: "Chess utilities and analysis tools",
+		Name:                 "gochess",
+		Usage:                "Chess utilities and analysis tools",
+		EnableBashCompletion: true,
 		Flags: []cli.Flag{
 			&cli.StringFlag{
 				Name:    "log-level",
 				Aliases: []string{"l"},
-				Usage:   "Set log level (debug, info, warn, error)",
-				Value:   defaultLogLevel,
+				Usage:   "Set log level: debug, info, warn or error (default: log_level from config, or error)",
+			},
+			&cli.StringFlag{
+				Name:  "log-format",
+				Usage: "Log format: text or json",
+				Value: defaultLogFormat,
+			},
+			&cli.StringFlag{
+				Name:  "log-file",
+				Usage: "Append logs to this file instead of stderr (TUIs log to ~/.gochess/gochess.log by default)",
+			},
+			&cli.BoolFlag{
+				Name:  "quiet",
+				Usage: "Disable logging",
 			},
 		},
+		Before: setupLogging,
+		After:  closeLogging,
 		Commands: []*cli.Command{
 			{
 				Name:  "import",
@@ -42,46 +77,765 @@ func main() {
 						Aliases: []string{"v"},
 						Usage:   "Show detailed error messages",
 					},
-					&cli.BoolFlag{
-						Name:    "full",
-						Aliases: []string{"f"},
-						Usage:   "Import full history (ignore last import time)",
+					&cli.BoolFlag{
+						Name:    "full",
+						Aliases: []string{"f"},
+						Usage:   "Import full history (ignore last import time)",
+					},
+					&cli.BoolFlag{
+						Name:  "notify",
+						Usage: "Send a summary to the notification destinations in the config when done",
+					},
+					&cli.BoolFlag{
+						Name:  "player-profiles",
+						Usage: "Fetch the title, country and membership status of new Chess.com opponents",
+					},
+					output.JSONFlag(),
+				},
+				Action: ImportCommand,
+			},
+			{
+				Name:  "sync",
+				Usage: "Import new games from the configured sources on a schedule",
+				Subcommands: []*cli.Command{
+					{
+						Name:  "daemon",
+						Usage: "Sync every --interval until interrupted, optionally analyzing the new games",
+						Flags: append(syncFlags(), &cli.DurationFlag{
+							Name:  "interval",
+							Usage: "Time between syncs",
+							Value: defaultSyncInterval,
+						}),
+						Action: syncDaemonCommand,
+					},
+					{
+						Name:   "run",
+						Usage:  "Sync once, as the daemon does (for cron)",
+						Flags:  syncFlags(),
+						Action: syncRunCommand,
+					},
+					{
+						Name:   "status",
+						Usage:  "Show whether the daemon is running, the last sync and the next one",
+						Flags:  []cli.Flag{output.JSONFlag()},
+						Action: syncStatusCommand,
+					},
+				},
+			},
+			{
+				Name:    "stats",
+				Aliases: []string{"st"},
+				Usage:   "Show statistics for configured players",
+				Flags:   statsFlags(),
+				Action:  statsCommand,
+			},
+			{
+				Name:  "play",
+				Usage: "Play a game against another player at the terminal, with a chess clock",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "time-control",
+						Aliases: []string{"t"},
+						Usage:   "Clock as base+increment in seconds (default: tui.time_control from config, or " + defaultTimeControl + ")",
+					},
+				},
+				Action: playCommand,
+			},
+			{
+				Name:  "broadcast",
+				Usage: "Relay a game live to a Lichess broadcast round: one played at the terminal, or a PGN file as it is written",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "round-url",
+						Usage:    "URL of the broadcast round, as in https://lichess.org/broadcast/<event>/<round>/<id>, or its ID",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:  "token",
+						Usage: "Lichess API token with the study:write scope (default: lichess.api_token from config)",
+					},
+					&cli.StringFlag{
+						Name:  "pgn",
+						Usage: "PGN file to relay, pushed again whenever it changes (default: play a game at the terminal)",
+					},
+					&cli.DurationFlag{
+						Name:  "interval",
+						Usage: "How often to push the game when it changed",
+						Value: defaultBroadcastInterval,
+					},
+					&cli.StringFlag{
+						Name:    "time-control",
+						Aliases: []string{"t"},
+						Usage:   "Clock of the game played at the terminal as base+increment in seconds (default: tui.time_control from config, or " + defaultTimeControl + ")",
+					},
+					&cli.StringFlag{
+						Name:  "white",
+						Usage: "Name of the White player of the game played at the terminal",
+					},
+					&cli.StringFlag{
+						Name:  "black",
+						Usage: "Name of the Black player of the game played at the terminal",
+					},
+					&cli.StringFlag{
+						Name:  "event",
+						Usage: "Event of the game played at the terminal",
+					},
+					&cli.StringFlag{
+						Name:  "round",
+						Usage: "Round of the game played at the terminal, as in 3.1",
+					},
+				},
+				Action: broadcastCommand,
+			},
+			{
+				Name:  "bot",
+				Usage: "Play online as a bot account: accept challenges and play them with an engine, storing the finished games",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "provider",
+						Usage: "Site to play on (lichess)",
+						Value: "lichess",
+					},
+					&cli.StringFlag{
+						Name:  "token",
+						Usage: "API token of a Lichess bot account with the bot:play scope (default: lichess.api_token from config)",
+					},
+					&cli.StringFlag{
+						Name:    "engine",
+						Aliases: []string{"e"},
+						Usage:   "Path to chess engine executable, or the name of an engine profile from config (default: the configured engine, or the built-in one)",
+					},
+					&cli.StringFlag{
+						Name:  "protocol",
+						Usage: "Engine protocol: uci or cecp (xboard/winboard)",
+					},
+					&cli.DurationFlag{
+						Name:  "move-time",
+						Usage: "Longest time to think on a move; less is used when the clock runs low",
+						Value: 10 * time.Second,
+					},
+					&cli.IntFlag{
+						Name:  "max-games",
+						Usage: "Games played at once; later challenges are declined",
+						Value: 1,
+					},
+					&cli.BoolFlag{
+						Name:  "rated",
+						Usage: "Accept rated challenges, not only casual ones",
+					},
+					databaseFlag(),
+				},
+				Action: botCommand,
+			},
+			{
+				Name:  "dgt",
+				Usage: "Follow a game played on a DGT electronic board at the terminal, optionally relaying it to a Lichess broadcast round, and store it in the database",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "port",
+						Usage:    "Serial port of the board, as in /dev/ttyUSB0 or /dev/ttyACM0",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:    "time-control",
+						Aliases: []string{"t"},
+						Usage:   "Clock of the game as base+increment in seconds (default: tui.time_control from config, or " + defaultTimeControl + ")",
+					},
+					&cli.StringFlag{
+						Name:  "white",
+						Usage: "Name of the White player",
+					},
+					&cli.StringFlag{
+						Name:  "black",
+						Usage: "Name of the Black player",
+					},
+					&cli.StringFlag{
+						Name:  "event",
+						Usage: "Event of the game",
+					},
+					&cli.StringFlag{
+						Name:  "round",
+						Usage: "Round of the game, as in 3.1",
+					},
+					&cli.StringFlag{
+						Name:  "round-url",
+						Usage: "URL or ID of a Lichess broadcast round to relay the game to",
+					},
+					&cli.StringFlag{
+						Name:  "token",
+						Usage: "Lichess API token with the study:write scope, for --round-url (default: lichess.api_token from config)",
+					},
+					&cli.DurationFlag{
+						Name:  "interval",
+						Usage: "How often to push the game to the broadcast when it changed",
+						Value: defaultBroadcastInterval,
+					},
+					&cli.BoolFlag{
+						Name:  "no-save",
+						Usage: "Do not store the game in the database when quitting",
+					},
+				},
+				Action: dgtCommand,
+			},
+			{
+				Name:  "train",
+				Usage: "Train: play blindfold, name square colors, find knight paths and guess the moves of games, with scores stored in the database",
+				Subcommands: []*cli.Command{
+					{
+						Name:  "blindfold",
+						Usage: "Play a game against the engine without seeing the pieces, entering moves as SAN",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:  "color",
+								Usage: "Side to play: white or black",
+								Value: "white",
+							},
+							&cli.StringFlag{
+								Name:    "engine",
+								Aliases: []string{"e"},
+								Usage:   "Path to chess engine executable, or the name of an engine profile from config (default: the configured engine, or the built-in one)",
+							},
+							&cli.StringFlag{
+								Name:  "protocol",
+								Usage: "Engine protocol: uci or cecp (xboard/winboard)",
+							},
+							&cli.DurationFlag{
+								Name:  "move-time",
+								Usage: "Time the engine thinks on each move",
+								Value: 500 * time.Millisecond,
+							},
+							databaseFlag(),
+						},
+						Action: trainBlindfoldCommand,
+					},
+					{
+						Name:  "square-colors",
+						Usage: "Say whether named squares are light or dark",
+						Flags: []cli.Flag{
+							&cli.IntFlag{
+								Name:  "rounds",
+								Usage: "Squares asked per session",
+								Value: defaultSquareColorRounds,
+							},
+							databaseFlag(),
+						},
+						Action: trainSquareColorsCommand,
+					},
+					{
+						Name:  "knight-paths",
+						Usage: "Find the shortest path of a knight between two squares",
+						Flags: []cli.Flag{
+							&cli.IntFlag{
+								Name:  "rounds",
+								Usage: "Paths asked per session",
+								Value: defaultKnightPathRounds,
+							},
+							databaseFlag(),
+						},
+						Action: trainKnightPathsCommand,
+					},
+					{
+						Name:  "guess",
+						Usage: "Guess the moves of one side in a game of the database, scored against the game moves and the engine",
+						Flags: []cli.Flag{
+							&cli.IntFlag{
+								Name:  "id",
+								Usage: "ID of the game (default: a game picked at random)",
+							},
+							&cli.StringFlag{
+								Name:    "player",
+								Aliases: []string{"p"},
+								Usage:   "Pick the game among this player's games, and guess their moves",
+							},
+							&cli.StringFlag{
+								Name:  "color",
+								Usage: "Side whose moves to guess: white or black (default: the player's, or white)",
+							},
+							&cli.IntFlag{
+								Name:  "from-move",
+								Usage: "First move number to guess, to skip the opening",
+								Value: 1,
+							},
+							&cli.StringFlag{
+								Name:    "engine",
+								Aliases: []string{"e"},
+								Usage:   "Path to chess engine executable, or the name of an engine profile from config (default: the configured engine, or the built-in one)",
+							},
+							&cli.StringFlag{
+								Name:  "protocol",
+								Usage: "Engine protocol: uci or cecp (xboard/winboard)",
+							},
+							&cli.DurationFlag{
+								Name:  "move-time",
+								Usage: "Time the engine spends on each position it evaluates",
+								Value: time.Second,
+							},
+							&cli.BoolFlag{
+								Name:  "no-engine",
+								Usage: "Only score guesses of the game move",
+							},
+							databaseFlag(),
+						},
+						Action: trainGuessCommand,
+					},
+					{
+						Name:  "repertoire",
+						Usage: "Drill a repertoire with spaced repetition: find its moves in the positions due",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:     "name",
+								Usage:    "Name of the repertoire",
+								Required: true,
+							},
+							&cli.IntFlag{
+								Name:  "limit",
+								Usage: "Most positions reviewed; 0 reviews all that are due",
+							},
+							databaseFlag(),
+						},
+						Action: trainRepertoireCommand,
+					},
+					{
+						Name:  "review",
+						Usage: "Review the positions the trainers scheduled for spaced repetition that are due",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:  "kind",
+								Usage: "Only review positions of this trainer: guess-the-move or repertoire",
+							},
+							&cli.IntFlag{
+								Name:  "limit",
+								Usage: "Most positions reviewed; 0 reviews all that are due",
+							},
+							databaseFlag(),
+						},
+						Action: trainReviewCommand,
+					},
+					{
+						Name:  "due",
+						Usage: "Show what is due for review today, by trainer",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:  "kind",
+								Usage: "Only list positions of this trainer: guess-the-move or repertoire",
+							},
+							&cli.IntFlag{
+								Name:  "limit",
+								Usage: "Most positions listed; 0 lists all",
+								Value: 20,
+							},
+							databaseFlag(),
+							output.JSONFlag(),
+						},
+						Action: trainDueCommand,
+					},
+					{
+						Name:  "scores",
+						Usage: "Show the training scores: a summary per mode, or the latest sessions of one mode",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:  "mode",
+								Usage: "Only show sessions of this mode: blindfold, square-colors, knight-paths or guess-the-move",
+							},
+							&cli.IntFlag{
+								Name:  "limit",
+								Usage: "Sessions shown with --mode; 0 shows all",
+								Value: 20,
+							},
+							databaseFlag(),
+							output.JSONFlag(),
+						},
+						Action: trainScoresCommand,
+					},
+				},
+			},
+			{
+				Name:  "setup",
+				Usage: "Set up a position on the board editor, then analyse it, play it with a clock or copy its FEN",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "fen",
+						Usage: "Position to start from (default: the starting position)",
+					},
+					&cli.StringFlag{
+						Name:    "time-control",
+						Aliases: []string{"t"},
+						Usage:   "Clock for games played from the position, as base+increment in seconds (default: tui.time_control from config, or " + defaultTimeControl + ")",
+					},
+				},
+				Action: setupCommand,
+			},
+			{
+				Name:      "fen",
+				Usage:     "Validate a FEN, show the position with its legal moves and status, and play moves from it",
+				ArgsUsage: "\"<fen>\"",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "apply-moves",
+						Aliases: []string{"m"},
+						Usage:   "Moves to play from the position, in SAN or UCI, e.g. \"e4 e5 Nf3\"",
+					},
+					&cli.BoolFlag{
+						Name:    "quiet",
+						Aliases: []string{"q"},
+						Usage:   "Print only the FEN of the resulting position",
+					},
+					&cli.BoolFlag{
+						Name:    "unicode",
+						Aliases: []string{"u"},
+						Usage:   "Draw the pieces as chess symbols instead of letters",
+					},
+					&cli.BoolFlag{
+						Name:  "flip",
+						Usage: "Draw the board from Black's side",
+					},
+					&cli.BoolFlag{
+						Name:    "eval",
+						Aliases: []string{"e"},
+						Usage:   "Show a static evaluation of the position: material, piece squares and mobility",
+					},
+				},
+				Action: fenCommand,
+			},
+			{
+				Name:  "convert",
+				Usage: "Convert games between PGN and JSON, or to EPD positions or UCI move lists",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "in",
+						Aliases:  []string{"i"},
+						Usage:    "Input file (.pgn or .json), or - for stdin",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:    "out",
+						Aliases: []string{"o"},
+						Usage:   "Output file (.pgn, .json, .epd or .uci) (default: stdout)",
+					},
+					&cli.StringFlag{
+						Name:  "from",
+						Usage: "Input format: pgn or json (default: from the input file extension)",
+					},
+					&cli.StringFlag{
+						Name:  "to",
+						Usage: "Output format: pgn, json, epd or uci (default: from the output file extension)",
+					},
+					&cli.BoolFlag{
+						Name:  "every-ply",
+						Usage: "Write every position of each game to EPD, not just the final one",
+					},
+				},
+				Action: convertCommand,
+			},
+			{
+				Name:  "pgn",
+				Usage: "Split and merge PGN files before importing them",
+				Subcommands: []*cli.Command{
+					{
+						Name:  "split",
+						Usage: "Split a PGN file into numbered files of a fixed number of games",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:     "input",
+								Aliases:  []string{"i"},
+								Usage:    "PGN file to split",
+								Required: true,
+							},
+							&cli.IntFlag{
+								Name:    "games-per-file",
+								Aliases: []string{"n"},
+								Usage:   "Number of games in each file",
+								Value:   defaultGamesPerFile,
+							},
+							&cli.StringFlag{
+								Name:    "output-dir",
+								Aliases: []string{"o"},
+								Usage:   "Directory to write the files to (default: the input file's directory)",
+							},
+						},
+						Action: pgnSplitCommand,
+					},
+					{
+						Name:      "cat",
+						Usage:     "Concatenate PGN files, optionally dropping duplicate games",
+						ArgsUsage: "<file.pgn>...",
+						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:  "dedupe",
+								Usage: "Write each game only once, by the rule the database import uses for duplicates",
+							},
+							&cli.StringFlag{
+								Name:    "output",
+								Aliases: []string{"o"},
+								Usage:   "PGN file to write (default: stdout)",
+							},
+						},
+						Action: pgnCatCommand,
+					},
+				},
+			},
+			{
+				Name:  "diagram",
+				Usage: "Draw a position as an SVG or PNG image",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "fen",
+						Usage:    "FEN of the position to draw",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:     "output",
+						Aliases:  []string{"o"},
+						Usage:    "Image file to write (.svg or .png)",
+						Required: true,
+					},
+					&cli.BoolFlag{
+						Name:  "flip",
+						Usage: "Draw the board from Black's side",
+					},
+					&cli.StringFlag{
+						Name:  "last-move",
+						Usage: "Highlight the squares of a move, e.g. e2e4",
+					},
+					&cli.IntFlag{
+						Name:  "size",
+						Usage: "Size of a square in pixels",
+						Value: render.DefaultSquareSize,
+					},
+					&cli.BoolFlag{
+						Name:  "no-coordinates",
+						Usage: "Leave out the file and rank labels (SVG only)",
+					},
+				},
+				Action: diagramCommand,
+			},
+			{
+				Name:  "gif",
+				Usage: "Animate the moves of a game as a GIF or animated PNG",
+				Flags: []cli.Flag{
+					&cli.IntFlag{
+						Name:  "id",
+						Usage: "ID of the game in the database",
+					},
+					&cli.StringFlag{
+						Name:  "pgn",
+						Usage: "PGN file holding the game (its first game is used)",
+					},
+					databaseFlag(),
+					&cli.StringFlag{
+						Name:     "output",
+						Aliases:  []string{"o"},
+						Usage:    "Image file to write (.gif, or .png/.apng for an animated PNG)",
+						Required: true,
+					},
+					&cli.DurationFlag{
+						Name:  "delay",
+						Usage: "How long each position is shown",
+						Value: 800 * time.Millisecond,
+					},
+					&cli.BoolFlag{
+						Name:  "flip",
+						Usage: "Draw the board from Black's side",
+					},
+					&cli.IntFlag{
+						Name:  "size",
+						Usage: "Size of a square in pixels",
+						Value: render.DefaultSquareSize,
+					},
+				},
+				Action: gifCommand,
+			},
+			{
+				Name:  "eco",
+				Usage: "Classify games or a position by ECO code, and explore ECO lines",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "pgn",
+						Usage: "PGN file whose games to classify, or - for stdin",
+					},
+					&cli.StringFlag{
+						Name:  "fen",
+						Usage: "FEN of a position to classify, by any move order",
+					},
+					output.JSONFlag(),
+				},
+				Action: ecoCommand,
+				Subcommands: []*cli.Command{
+					{
+						Name:      "show",
+						Usage:     "Show the moves that define an ECO code and its variations",
+						ArgsUsage: "<code>",
+						Flags:     []cli.Flag{output.JSONFlag()},
+						Action:    ecoShowCommand,
+					},
+				},
+			},
+			{
+				Name:  "repertoire",
+				Usage: "Keep opening repertoires and check your games against them",
+				Subcommands: []*cli.Command{
+					{
+						Name:  "import",
+						Usage: "Import a repertoire from a PGN file, variations included",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:     "pgn",
+								Usage:    "PGN file holding the repertoire",
+								Required: true,
+							},
+							&cli.StringFlag{
+								Name:     "name",
+								Usage:    "Name of the repertoire; an existing one is replaced",
+								Required: true,
+							},
+							&cli.StringFlag{
+								Name:  "color",
+								Usage: "Side the repertoire is for: white or black (default: black if the name mentions it, else white)",
+							},
+							databaseFlag(),
+						},
+						Action: repertoireImportCommand,
+					},
+					{
+						Name:   "list",
+						Usage:  "List the repertoires",
+						Flags:  []cli.Flag{databaseFlag(), output.JSONFlag()},
+						Action: repertoireListCommand,
+					},
+					{
+						Name:  "check",
+						Usage: "Compare a player's recent games with their repertoires and show where they deviated first",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:  "player",
+								Usage: "Player whose games to check (default: the configured username)",
+							},
+							&cli.StringFlag{
+								Name:  "name",
+								Usage: "Only compare with this repertoire (default: every repertoire for the player's color)",
+							},
+							&cli.IntFlag{
+								Name:    "games",
+								Aliases: []string{"n"},
+								Usage:   "Number of recent games to check",
+								Value:   defaultRepertoireGames,
+							},
+							databaseFlag(),
+							output.JSONFlag(),
+						},
+						Action: repertoireCheckCommand,
+					},
+					{
+						Name:  "export",
+						Usage: "Write a repertoire as PGN",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:     "name",
+								Usage:    "Name of the repertoire",
+								Required: true,
+							},
+							&cli.StringFlag{
+								Name:    "output",
+								Aliases: []string{"o"},
+								Usage:   "PGN file to write (default: stdout)",
+							},
+							databaseFlag(),
+						},
+						Action: repertoireExportCommand,
+					},
+					{
+						Name:  "delete",
+						Usage: "Delete a repertoire",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:     "name",
+								Usage:    "Name of the repertoire",
+								Required: true,
+							},
+							databaseFlag(),
+						},
+						Action: repertoireDeleteCommand,
+					},
+				},
+			},
+			{
+				Name:  "rating",
+				Usage: "Elo calculations: performance ratings and expected scores",
+				Subcommands: []*cli.Command{
+					{
+						Name:  "perf",
+						Usage: "Show a player's performance rating and expected against actual score",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:     "player",
+								Usage:    "Player whose games to rate",
+								Required: true,
+							},
+							&cli.StringFlag{
+								Name:  "event",
+								Usage: "Only count the games of this event (default: every game)",
+							},
+							&cli.IntFlag{
+								Name:  "rating",
+								Usage: "Player's rating for the expected score (default: the rating in each game)",
+							},
+							databaseFlag(),
+							output.JSONFlag(),
+						},
+						Action: ratingPerfCommand,
+					},
+					{
+						Name:  "expected",
+						Usage: "Show the expected score between two ratings and the rating change of each result",
+						Flags: []cli.Flag{
+							&cli.Float64Flag{
+								Name:     "a",
+								Usage:    "Rating of the first player",
+								Required: true,
+							},
+							&cli.Float64Flag{
+								Name:     "b",
+								Usage:    "Rating of the second player",
+								Required: true,
+							},
+							&cli.Float64Flag{
+								Name:  "k",
+								Usage: "Development coefficient for the rating change",
+								Value: defaultRatingK,
+							},
+							output.JSONFlag(),
+						},
+						Action: ratingExpectedCommand,
 					},
 				},
-				Action: ImportCommand,
 			},
 			{
-				Name:    "stats",
-				Aliases: []string{"st"},
-				Usage:   "Show statistics for configured players",
+				Name:  "serve",
+				Usage: "Serve the game database as a JSON API over HTTP",
 				Flags: []cli.Flag{
+					databaseFlag(),
+					readOnlyFlag(),
 					&cli.StringFlag{
-						Name:    "database",
-						Aliases: []string{"db"},
-						Usage:   "Path to database file",
-						Value:   "~/.gochess/games.db",
+						Name:  "listen",
+						Usage: "Address to listen on",
+						Value: defaultListenAddr,
 					},
-					&cli.StringSliceFlag{
-						Name:    "player",
-						Aliases: []string{"p"},
-						Usage:   "Filter statistics for specific player(s) (can be used multiple times)",
-					},
-					&cli.BoolFlag{
-						Name:  "all",
-						Usage: "Show statistics for all players in database (not just configured users)",
+					&cli.StringFlag{
+						Name:    "engine",
+						Aliases: []string{"e"},
+						Usage:   "Path to chess engine executable, or the name of an engine profile from config, for game analysis",
 					},
 					&cli.StringFlag{
-						Name:    "format",
-						Aliases: []string{"f"},
-						Usage:   "Output format (table, csv, or tui)",
-						Value:   "table",
+						Name:  "protocol",
+						Usage: "Engine protocol: uci or cecp (xboard/winboard)",
 					},
-					&cli.BoolFlag{
-						Name:  "tui",
-						Usage: "Use pretty TUI output (same as --format=tui)",
+					&cli.IntFlag{
+						Name:  "workers",
+						Usage: "Number of games analyzed at once in the background",
+						Value: server.DefaultAnalysisWorkers,
 					},
 				},
-				Action: statsCommand,
+				Action: serveCommand,
 			},
 			{
 				Name:  "chesscom",
@@ -92,23 +846,35 @@ func main() {
 						Usage: "List available archives for a user",
 						Flags: []cli.Flag{
 							&cli.StringFlag{
-								Name:     "username",
-								Aliases:  []string{"u"},
-								Usage:    "Chess.com username",
-								Required: true,
+								Name:    "username",
+								Aliases: []string{"u"},
+								Usage:   "Chess.com username (default: the configured one)",
+							},
+							&cli.BoolFlag{
+								Name:    "details",
+								Aliases: []string{"d"},
+								Usage:   "Fetch each month to show its game count, dates and time classes",
+							},
+							&cli.BoolFlag{
+								Name:  "no-cache",
+								Usage: "With --details, fetch every month again instead of using the cached counts of past months",
 							},
+							output.JSONFlag(),
 						},
 						Action: chesscom.ListArchives,
 					},
 					{
 						Name:  "download",
-						Usage: "Download games for a user",
+						Usage: "Download games for one or more users",
 						Flags: []cli.Flag{
+							&cli.StringSliceFlag{
+								Name:    "username",
+								Aliases: []string{"u"},
+								Usage:   "Chess.com username, repeatable to download several accounts one after the other (default: the configured ones)",
+							},
 							&cli.StringFlag{
-								Name:     "username",
-								Aliases:  []string{"u"},
-								Usage:    "Chess.com username",
-								Required: true,
+								Name:  "usernames-file",
+								Usage: "File of usernames to download, one per line, with # starting a comment",
 							},
 							&cli.IntFlag{
 								Name:    "year",
@@ -131,18 +897,13 @@ func main() {
 							&cli.StringFlag{
 								Name:    "output",
 								Aliases: []string{"o"},
-								Usage:   "Output file path (default: stdout)",
+								Usage:   "Output file path (default: stdout); * is replaced with the month and {username} with the account, needed for several",
 							},
 							&cli.BoolFlag{
 								Name:  "import-db",
 								Usage: "Import games directly into the database",
 							},
-							&cli.StringFlag{
-								Name:    "database",
-								Aliases: []string{"db"},
-								Usage:   "Path to database file (for import-db option)",
-								Value:   "~/.gochess/games.db",
-							},
+							databaseFlag(),
 							&cli.BoolFlag{
 								Name:    "verbose",
 								Aliases: []string{"v"},
@@ -153,9 +914,65 @@ func main() {
 								Aliases: []string{"a"},
 								Usage:   "Download all available game history (ignores year/month options)",
 							},
+							output.JSONFlag(),
 						},
 						Action: chesscom.DownloadGames,
 					},
+					{
+						Name:  "watch",
+						Usage: "Follow a player's games: print the moves of daily games as they are played and import finished games",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:    "username",
+								Aliases: []string{"u"},
+								Usage:   "Chess.com username (default: the configured one)",
+							},
+							&cli.DurationFlag{
+								Name:  "interval",
+								Usage: "How often to poll Chess.com",
+								Value: chesscom.DefaultWatchInterval,
+							},
+							&cli.BoolFlag{
+								Name:  "board",
+								Usage: "Draw the board after each move",
+							},
+							&cli.BoolFlag{
+								Name:  "unicode",
+								Usage: "Draw the pieces as chess symbols instead of letters (with --board)",
+							},
+							&cli.BoolFlag{
+								Name:  "no-import",
+								Usage: "Do not import finished games into the database",
+							},
+							databaseFlag(),
+						},
+						Action: chesscomWatchCommand,
+					},
+					{
+						Name:  "daily",
+						Usage: "List a player's daily (correspondence) games in progress with their positions, those waiting on the player first",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:    "username",
+								Aliases: []string{"u"},
+								Usage:   "Chess.com username (default: the configured one)",
+							},
+							&cli.BoolFlag{
+								Name:  "board",
+								Usage: "Draw the board of each game",
+							},
+							&cli.BoolFlag{
+								Name:  "unicode",
+								Usage: "Draw the pieces as chess symbols instead of letters (with --board)",
+							},
+							&cli.BoolFlag{
+								Name:  "tui",
+								Usage: "Browse the games in the TUI, loading one onto the board with enter",
+							},
+							output.JSONFlag(),
+						},
+						Action: chesscomDailyCommand,
+					},
 				},
 			},
 			{
@@ -164,13 +981,16 @@ func main() {
 				Subcommands: []*cli.Command{
 					{
 						Name:  "download",
-						Usage: "Download games for a user",
+						Usage: "Download games for one or more users",
 						Flags: []cli.Flag{
+							&cli.StringSliceFlag{
+								Name:    "username",
+								Aliases: []string{"u"},
+								Usage:   "Lichess username, repeatable to download several accounts one after the other (default: the configured ones)",
+							},
 							&cli.StringFlag{
-								Name:     "username",
-								Aliases:  []string{"u"},
-								Usage:    "Lichess username",
-								Required: true,
+								Name:  "usernames-file",
+								Usage: "File of usernames to download, one per line, with # starting a comment",
 							},
 							&cli.StringFlag{
 								Name:    "since",
@@ -178,8 +998,8 @@ func main() {
 								Usage:   "Download games since this date (YYYY-MM-DD, YYYY-MM, or YYYY)",
 							},
 							&cli.StringFlag{
-								Name:    "until",
-								Usage:   "Download games until this date (YYYY-MM-DD, YYYY-MM, or YYYY)",
+								Name:  "until",
+								Usage: "Download games until this date (YYYY-MM-DD, YYYY-MM, or YYYY)",
 							},
 							&cli.IntFlag{
 								Name:    "max",
@@ -205,44 +1025,115 @@ func main() {
 							&cli.StringFlag{
 								Name:    "output",
 								Aliases: []string{"o"},
-								Usage:   "Output file path (default: stdout)",
+								Usage:   "Output file path (default: stdout); {username} is replaced with the account, needed for several",
 							},
 							&cli.BoolFlag{
 								Name:  "import-db",
 								Usage: "Import games directly into the database",
 							},
-							&cli.StringFlag{
-								Name:    "database",
-								Aliases: []string{"db"},
-								Usage:   "Path to database file (for import-db option)",
-								Value:   "~/.gochess/games.db",
-							},
+							databaseFlag(),
 							&cli.StringFlag{
 								Name:  "api-token",
-								Usage: "Lichess API token for private games (optional)",
+								Usage: "Lichess API token for private games (default: the configured token of the configured user)",
 							},
 							&cli.BoolFlag{
 								Name:    "verbose",
 								Aliases: []string{"v"},
 								Usage:   "Show detailed error messages",
 							},
+							output.JSONFlag(),
 						},
 						Action: lichess.DownloadGames,
 					},
 				},
 			},
+			{
+				Name:  "fics",
+				Usage: "Import game history from the Free Internet Chess Server",
+				Subcommands: []*cli.Command{
+					{
+						Name:  "import",
+						Usage: "Import saved history listings (results only) and PGN exports of FICS games",
+						Flags: []cli.Flag{
+							&cli.StringSliceFlag{
+								Name:  "history",
+								Usage: "Output of the FICS history command saved to a file (repeatable)",
+							},
+							&cli.StringSliceFlag{
+								Name:  "pgn",
+								Usage: "PGN export of FICS games, such as a journal or a ficsgames.org download (repeatable)",
+							},
+							&cli.StringFlag{
+								Name:    "username",
+								Aliases: []string{"u"},
+								Usage:   "FICS username the games are tagged with (default: the one named by the history)",
+							},
+							databaseFlag(),
+							output.JSONFlag(),
+						},
+						Action: ficsImportCommand,
+					},
+				},
+			},
+			{
+				Name:  "icc",
+				Usage: "Import game history from the Internet Chess Club",
+				Subcommands: []*cli.Command{
+					{
+						Name:  "import",
+						Usage: "Import PGN exports of ICC games",
+						Flags: []cli.Flag{
+							&cli.StringSliceFlag{
+								Name:  "pgn",
+								Usage: "PGN export of ICC games (repeatable)",
+							},
+							&cli.StringFlag{
+								Name:    "username",
+								Aliases: []string{"u"},
+								Usage:   "ICC username the games are tagged with",
+							},
+							databaseFlag(),
+							output.JSONFlag(),
+						},
+						Action: iccImportCommand,
+					},
+				},
+			},
+			{
+				Name:  "fide",
+				Usage: "Look up over-the-board players in the FIDE rating list",
+				Subcommands: []*cli.Command{
+					{
+						Name:  "ratings",
+						Usage: "Fetch and list the current standard, rapid and blitz ratings of the players named by FIDE ID in the games",
+						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:  "refresh",
+								Usage: "Fetch the ratings of every player again, not only of those never looked up",
+							},
+							&cli.BoolFlag{
+								Name:  "offline",
+								Usage: "List the stored ratings without fetching any",
+							},
+							databaseFlag(),
+							output.JSONFlag(),
+						},
+						Action: fideRatingsCommand,
+					},
+				},
+			},
 			{
 				Name:  "config",
 				Usage: "Manage gochess configuration",
 				Subcommands: []*cli.Command{
 					{
-						Name:  "init",
-						Usage: "Initialize configuration interactively",
+						Name:   "init",
+						Usage:  "Initialize configuration interactively",
 						Action: config.InitCommand,
 					},
 					{
-						Name:  "show",
-						Usage: "Show current configuration",
+						Name:   "show",
+						Usage:  "Show current configuration",
 						Action: config.ShowCommand,
 					},
 					{
@@ -266,6 +1157,10 @@ func main() {
 								Aliases: []string{"t"},
 								Usage:   "API token (Lichess only, optional)",
 							},
+							&cli.BoolFlag{
+								Name:  "additional",
+								Usage: "Add another account on the platform, such as a separate daily account, instead of replacing the configured user",
+							},
 						},
 						Action: config.AddUserCommand,
 					},
@@ -274,59 +1169,251 @@ func main() {
 						Usage: "Remove a tracked user",
 						Flags: []cli.Flag{
 							&cli.StringFlag{
-								Name:     "platform",
-								Aliases: []string{"p"},
-								Usage:    "Platform (chesscom or lichess)",
-								Required: true,
+								Name:     "platform",
+								Aliases:  []string{"p"},
+								Usage:    "Platform (chesscom or lichess)",
+								Required: true,
+							},
+						},
+						Action: config.RemoveUserCommand,
+					},
+				},
+			},
+			{
+				Name:  "analyze",
+				Usage: "Analyze chess positions with a UCI or CECP (xboard) engine",
+				Subcommands: []*cli.Command{
+					{
+						Name:  "position",
+						Usage: "Analyze a single position",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:  "fen",
+								Usage: "FEN string of the position to analyze",
+							},
+							&cli.IntFlag{
+								Name:  "game-id",
+								Usage: "Game ID to load position from database",
+							},
+							&cli.IntFlag{
+								Name:  "move",
+								Usage: "Ply (half-move) number within the game (used with --game-id)",
+								Value: 0,
+							},
+							&cli.StringFlag{
+								Name:    "engine",
+								Aliases: []string{"e"},
+								Usage:   "Path to chess engine executable, or the name of an engine profile from config",
+							},
+							&cli.StringFlag{
+								Name:  "protocol",
+								Usage: "Engine protocol: uci or cecp (xboard/winboard)",
+							},
+							&cli.IntFlag{
+								Name:    "depth",
+								Aliases: []string{"d"},
+								Usage:   "Analysis depth",
+								Value:   defaultDepth,
+							},
+							&cli.IntFlag{
+								Name:  "lines",
+								Usage: "Number of principal variations (MultiPV)",
+								Value: defaultLines,
+							},
+							&cli.BoolFlag{
+								Name:  "save",
+								Usage: "Save the evaluation to the database (requires --game-id)",
+							},
+							output.JSONFlag(),
+						},
+						Action: analyzePositionAction,
+					},
+					{
+						Name:  "game",
+						Usage: "Review a game, classifying blunders and brilliant moves",
+						Flags: []cli.Flag{
+							&cli.IntFlag{
+								Name:  "game-id",
+								Usage: "Game ID to load from database",
+							},
+							&cli.StringFlag{
+								Name:  "pgn",
+								Usage: "PGN file to load the game from (first game is used)",
+							},
+							&cli.StringFlag{
+								Name:    "engine",
+								Aliases: []string{"e"},
+								Usage:   "Path to chess engine executable, or the name of an engine profile from config",
+							},
+							&cli.StringFlag{
+								Name:  "protocol",
+								Usage: "Engine protocol: uci or cecp (xboard/winboard)",
+							},
+							&cli.IntFlag{
+								Name:    "depth",
+								Aliases: []string{"d"},
+								Usage:   "Analysis depth per position",
+								Value:   defaultReviewDepth,
+							},
+							&cli.IntFlag{
+								Name:  "lines",
+								Usage: "Lines per position (2 or more enables brilliancy detection)",
+								Value: defaultReviewLines,
+							},
+							&cli.IntFlag{
+								Name:  "rating",
+								Usage: "Rating to compute win probabilities for (default: the players' average Elo)",
+							},
+							output.JSONFlag(),
+						},
+						Action: analyzeGameAction,
+					},
+					{
+						Name:  "theory",
+						Usage: "Find where a game leaves opening theory, compared with master games",
+						Flags: []cli.Flag{
+							&cli.IntFlag{
+								Name:    "game-id",
+								Aliases: []string{"id"},
+								Usage:   "Game ID to load from database",
+							},
+							&cli.StringFlag{
+								Name:  "pgn",
+								Usage: "PGN file to load the game from (first game is used)",
+							},
+							databaseFlag(),
+							&cli.StringFlag{
+								Name:  "reference",
+								Usage: "Path to a gochess database of reference games, such as imported master games",
+							},
+							&cli.BoolFlag{
+								Name:  "lichess",
+								Usage: "Use the Lichess masters database as reference (needs network access)",
+							},
+							&cli.IntFlag{
+								Name:  "min-games",
+								Usage: "Reference games a move needs to count as theory",
+								Value: defaultTheoryMinGames,
+							},
+							&cli.IntFlag{
+								Name:  "replies",
+								Usage: "Number of theory moves to show at the deviation",
+								Value: defaultTheoryReplies,
+							},
+							output.JSONFlag(),
+						},
+						Action: analyzeTheoryAction,
+					},
+				},
+			},
+			{
+				Name:  "bench",
+				Usage: "Benchmark chess engines and the move generator",
+				Subcommands: []*cli.Command{
+					{
+						Name:  "suite",
+						Usage: "Run an EPD test suite (WAC, STS, ...) and score the engine",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:     "epd",
+								Usage:    "Path to EPD file with bm/am operations",
+								Required: true,
+							},
+							&cli.StringFlag{
+								Name:    "engine",
+								Aliases: []string{"e"},
+								Usage:   "Path to chess engine executable, or the name of an engine profile from config",
+							},
+							&cli.StringFlag{
+								Name:  "protocol",
+								Usage: "Engine protocol: uci or cecp (xboard/winboard)",
+							},
+							&cli.Float64Flag{
+								Name:    "time",
+								Aliases: []string{"t"},
+								Usage:   "Search time per position in seconds",
+								Value:   defaultSuiteTime,
+							},
+							&cli.IntFlag{
+								Name:    "depth",
+								Aliases: []string{"d"},
+								Usage:   "Search depth per position (overrides --time)",
+							},
+							&cli.BoolFlag{
+								Name:    "verbose",
+								Aliases: []string{"v"},
+								Usage:   "Show the result of every position",
+							},
+						},
+						Action: benchSuiteAction,
+					},
+					{
+						Name:  "movegen",
+						Usage: "Time perft and legal move generation and report nodes per second",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:  "fen",
+								Usage: "FEN of the position to benchmark (default: the standard perft positions)",
+							},
+							&cli.IntFlag{
+								Name:    "depth",
+								Aliases: []string{"d"},
+								Usage:   "Perft depth",
+								Value:   defaultPerftDepth,
+							},
+							&cli.Float64Flag{
+								Name:    "time",
+								Aliases: []string{"t"},
+								Usage:   "Seconds to spend timing legal move generation and each board operation",
+								Value:   defaultMovegenTime,
+							},
+							&cli.BoolFlag{
+								Name:  "stats",
+								Usage: "Also count captures, castles, promotions, checks and mates (slower)",
+							},
+							&cli.StringFlag{
+								Name:  "baseline",
+								Usage: "Save the results to this JSON file, or compare with it if --compare is set",
 							},
+							&cli.BoolFlag{
+								Name:  "compare",
+								Usage: "Compare the results with the --baseline file and fail on regressions",
+							},
+							&cli.Float64Flag{
+								Name:  "threshold",
+								Usage: "Percentage a rate may fall below the baseline before it counts as a regression",
+								Value: defaultBenchThreshold,
+							},
+							output.JSONFlag(),
 						},
-						Action: config.RemoveUserCommand,
+						Action: benchMovegenAction,
 					},
 				},
 			},
 			{
-				Name:  "analyze",
-				Usage: "Analyze chess positions with a UCI engine",
+				Name:  "export",
+				Usage: "Export data from the database",
 				Subcommands: []*cli.Command{
 					{
-						Name:  "position",
-						Usage: "Analyze a single position",
+						Name:  "training",
+						Usage: "Export evaluated positions (FEN, side, eval, best move, game metadata) as CSV or Parquet to train models",
 						Flags: []cli.Flag{
 							&cli.StringFlag{
-								Name:  "fen",
-								Usage: "FEN string of the position to analyze",
-							},
-							&cli.IntFlag{
-								Name:  "game-id",
-								Usage: "Game ID to load position from database",
-							},
-							&cli.IntFlag{
-								Name:  "move",
-								Usage: "Ply (half-move) number within the game (used with --game-id)",
-								Value: 0,
+								Name:    "output",
+								Aliases: []string{"o"},
+								Usage:   "File to write (default: stdout)",
 							},
 							&cli.StringFlag{
-								Name:    "engine",
-								Aliases: []string{"e"},
-								Usage:   "Path to UCI chess engine executable",
-							},
-							&cli.IntFlag{
-								Name:    "depth",
-								Aliases: []string{"d"},
-								Usage:   "Analysis depth",
-								Value:   defaultDepth,
-							},
-							&cli.IntFlag{
-								Name:  "lines",
-								Usage: "Number of principal variations (MultiPV)",
-								Value: defaultLines,
+								Name:  "format",
+								Usage: "csv or parquet (default: parquet for a .parquet output file, csv otherwise)",
 							},
 							&cli.BoolFlag{
-								Name:  "save",
-								Usage: "Save the evaluation to the database (requires --game-id)",
+								Name:  "from-analysis",
+								Usage: "Only export games analyzed by the engine, with its best move in each position",
 							},
+							databaseFlag(),
 						},
-						Action: analyzePositionAction,
+						Action: exportTrainingAction,
 					},
 				},
 			},
@@ -341,33 +1428,59 @@ func main() {
 							&cli.StringFlag{
 								Name:     "pgn",
 								Aliases:  []string{"p"},
-								Usage:    "Path to PGN file or directory of PGN files",
+								Usage:    "Path to PGN file or directory of PGN files, - for stdin, or an http(s) URL to download",
 								Required: true,
 							},
+							&cli.BoolFlag{
+								Name:  "dry-run",
+								Usage: "Parse, validate and deduplicate the games and report what would be imported, without changing the database",
+							},
 							&cli.StringFlag{
-								Name:    "database",
-								Aliases: []string{"db"},
-								Usage:   "Path to database file",
-								Value:   "~/.gochess/games.db",
+								Name:  "require-tags",
+								Usage: "Comma-separated tags a game must have to be imported, e.g. White,Black (default: import.require_tags from the config, else none; missing standard tags get PGN placeholders such as \"?\")",
+							},
+							&cli.BoolFlag{
+								Name:  "force",
+								Usage: "Import files again even if unchanged since they were imported",
+							},
+							&cli.BoolFlag{
+								Name:  "recursive",
+								Usage: "Import the files of subdirectories when --pgn is a directory; --recursive=false for its top level only",
+								Value: true,
+							},
+							&cli.StringSliceFlag{
+								Name:  "include",
+								Usage: "Import only files of a directory whose names match these patterns, ignoring case",
+								Value: cli.NewStringSlice("*.pgn"),
+							},
+							&cli.StringSliceFlag{
+								Name:  "exclude",
+								Usage: "Skip files and subdirectories of a directory whose names match these patterns, e.g. \"*blitz*\"",
 							},
+							&cli.IntFlag{
+								Name:  "workers",
+								Usage: "Number of games whose moves are parsed at once, 0 for one per CPU",
+							},
+							databaseFlag(),
 							&cli.BoolFlag{
 								Name:    "verbose",
 								Aliases: []string{"v"},
 								Usage:   "Show detailed error messages",
 							},
+							&cli.BoolFlag{
+								Name:  "fide-ratings",
+								Usage: "Then look up the current FIDE ratings of players named by WhiteFideId/BlackFideId tags",
+							},
+							output.JSONFlag(),
 						},
-						Action: db.ImportCommand,
+						Action: dbImportCommand,
 					},
 					{
 						Name:  "list",
 						Usage: "List games in the database",
 						Flags: []cli.Flag{
-							&cli.StringFlag{
-								Name:    "database",
-								Aliases: []string{"db"},
-								Usage:   "Path to database file",
-								Value:   "~/.gochess/games.db",
-							},
+							databaseFlag(),
+							readOnlyFlag(),
 							&cli.StringFlag{
 								Name:    "white",
 								Aliases: []string{"w"},
@@ -388,6 +1501,22 @@ func main() {
 								Aliases: []string{"d"},
 								Usage:   "Filter by date",
 							},
+							&cli.StringFlag{
+								Name:  "endgame",
+								Usage: "Filter by the endgame reached: pawn, rook, queen, opposite-bishops, minor, rook-minor, mixed or none",
+							},
+							&cli.StringFlag{
+								Name:  "motif",
+								Usage: "Filter by a tactical motif found by the engine analysis: fork, pin, skewer, back-rank, discovered-attack or smothered-mate",
+							},
+							&cli.StringFlag{
+								Name:  "time-class",
+								Usage: "Filter by time class: bullet, blitz, rapid, classical or daily",
+							},
+							&cli.StringSliceFlag{
+								Name:  "tag",
+								Usage: "Filter by the exact value of a PGN tag, as Name=Value, e.g. --tag ECO=B90 --tag Termination=\"won on time\" (repeat for several)",
+							},
 							&cli.IntFlag{
 								Name:    "limit",
 								Aliases: []string{"n"},
@@ -403,6 +1532,11 @@ func main() {
 								Name:  "tui",
 								Usage: "Use interactive TUI browser",
 							},
+							&cli.BoolFlag{
+								Name:  "restore",
+								Usage: "With --tui, resume the last session without asking; --restore=false starts afresh",
+							},
+							output.JSONFlag(),
 						},
 						Action: listCommandRouter,
 					},
@@ -415,30 +1549,198 @@ func main() {
 								Usage:    "Game ID",
 								Required: true,
 							},
+							databaseFlag(),
+							&cli.BoolFlag{
+								Name:  "pgn",
+								Usage: "Show PGN text",
+								Value: true,
+							},
+							output.JSONFlag(),
+						},
+						Action: db.ShowCommand,
+					},
+					{
+						Name:  "note",
+						Usage: "Print or edit your own note on a game, kept apart from its PGN comments",
+						Flags: []cli.Flag{
+							&cli.IntFlag{
+								Name:     "id",
+								Usage:    "Game ID",
+								Required: true,
+							},
+							&cli.BoolFlag{
+								Name:  "edit",
+								Usage: "Edit the note in $VISUAL or $EDITOR (default: vi)",
+							},
 							&cli.StringFlag{
-								Name:    "database",
-								Aliases: []string{"db"},
-								Usage:   "Path to database file",
-								Value:   "~/.gochess/games.db",
+								Name:  "text",
+								Usage: "Replace the note with this text (empty to delete it)",
 							},
 							&cli.BoolFlag{
-								Name:    "pgn",
-								Usage:   "Show PGN text",
-								Value:   true,
+								Name:  "delete",
+								Usage: "Delete the note",
 							},
+							databaseFlag(),
+							output.JSONFlag(),
 						},
-						Action: db.ShowCommand,
+						Action: dbNoteCommand,
 					},
 					{
-						Name:  "export",
-						Usage: "Export games to PGN format",
+						Name:  "delete",
+						Usage: "Move games to the trash, leaving them out of searches and statistics until restored",
+						Flags: []cli.Flag{
+							&cli.IntSliceFlag{
+								Name:     "id",
+								Usage:    "Game ID, repeatable",
+								Required: true,
+							},
+							databaseFlag(),
+						},
+						Action: dbDeleteCommand,
+					},
+					{
+						Name:  "restore",
+						Usage: "Take games out of the trash",
+						Flags: []cli.Flag{
+							&cli.IntSliceFlag{
+								Name:     "id",
+								Usage:    "Game ID, repeatable",
+								Required: true,
+							},
+							databaseFlag(),
+						},
+						Action: dbRestoreCommand,
+					},
+					{
+						Name:  "trash",
+						Usage: "List or purge the games moved to the trash by db delete",
+						Subcommands: []*cli.Command{
+							{
+								Name:   "list",
+								Usage:  "List the games in the trash, the latest deleted first",
+								Flags:  []cli.Flag{databaseFlag(), output.JSONFlag()},
+								Action: dbTrashListCommand,
+							},
+							{
+								Name:  "purge",
+								Usage: "Permanently delete games in the trash, with their analyses and notes",
+								Flags: []cli.Flag{
+									&cli.IntSliceFlag{
+										Name:  "id",
+										Usage: "Game ID to purge, repeatable (default: every game in the trash)",
+									},
+									&cli.BoolFlag{
+										Name:    "force",
+										Aliases: []string{"f"},
+										Usage:   "Purge without confirmation prompt",
+									},
+									databaseFlag(),
+								},
+								Action: dbTrashPurgeCommand,
+							},
+						},
+					},
+					{
+						Name:  "crosstable",
+						Usage: "Print the crosstable of a tournament, or list the tournaments when no event is given",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:  "event",
+								Usage: "Event of the tournament, as in its Event tag",
+							},
+							&cli.StringFlag{
+								Name:  "site",
+								Usage: "Site of the tournament, for an event held at several sites",
+							},
+							&cli.StringFlag{
+								Name:  "date",
+								Usage: "A date the tournament was played (YYYY.MM.DD), for an event held several times",
+							},
+							databaseFlag(),
+							output.JSONFlag(),
+						},
+						Action: dbCrosstableCommand,
+					},
+					{
+						Name:  "view",
+						Usage: "Step through a game move by move, printing the board after each move",
+						Flags: []cli.Flag{
+							&cli.IntFlag{
+								Name:     "id",
+								Usage:    "Game ID",
+								Required: true,
+							},
+							databaseFlag(),
+							&cli.BoolFlag{
+								Name:  "flip",
+								Usage: "Show the board from Black's side",
+							},
+							&cli.BoolFlag{
+								Name:  "unicode",
+								Usage: "Draw the pieces with Unicode chess symbols",
+							},
+						},
+						Action: dbViewCommand,
+					},
+					{
+						Name:  "tree",
+						Usage: "Draw a player's opening tree as a Graphviz or Mermaid graph",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:     "player",
+								Usage:    "Player whose games to draw",
+								Required: true,
+							},
+							&cli.IntFlag{
+								Name:  "depth",
+								Usage: "Number of plies to draw",
+								Value: defaultTreeDepth,
+							},
+							&cli.StringFlag{
+								Name:  "color",
+								Usage: "Only the games played with this color: white or black (default: both)",
+							},
+							&cli.IntFlag{
+								Name:  "min-games",
+								Usage: "Leave out moves played in fewer games",
+								Value: 1,
+							},
+							&cli.StringFlag{
+								Name:  "format",
+								Usage: "Graph format: dot (Graphviz) or mermaid",
+								Value: "dot",
+							},
+							&cli.StringFlag{
+								Name:    "output",
+								Aliases: []string{"o"},
+								Usage:   "File to write (default: stdout)",
+							},
+							databaseFlag(),
+							output.JSONFlag(),
+						},
+						Action: dbTreeCommand,
+					},
+					{
+						Name:      "query",
+						Usage:     "Run a read-only SQL query against the game database",
+						ArgsUsage: "<sql>",
 						Flags: []cli.Flag{
+							databaseFlag(),
 							&cli.StringFlag{
-								Name:    "database",
-								Aliases: []string{"db"},
-								Usage:   "Path to database file",
-								Value:   "~/.gochess/games.db",
+								Name:    "format",
+								Aliases: []string{"f"},
+								Usage:   "Output format (table, csv, or json)",
+								Value:   "table",
 							},
+							output.JSONFlag(),
+						},
+						Action: dbQueryCommand,
+					},
+					{
+						Name:  "export",
+						Usage: "Export games to PGN format",
+						Flags: []cli.Flag{
+							databaseFlag(),
 							&cli.IntFlag{
 								Name:  "id",
 								Usage: "Export specific game by ID (if not specified, export all games)",
@@ -448,6 +1750,10 @@ func main() {
 								Aliases: []string{"o"},
 								Usage:   "Output file path (default: stdout)",
 							},
+							&cli.BoolFlag{
+								Name:  "with-analysis",
+								Usage: "Merge the latest analysis of each game into its PGN as [%eval] comments, NAGs and best-move variations",
+							},
 						},
 						Action: db.ExportCommand,
 					},
@@ -456,12 +1762,7 @@ func main() {
 						Aliases: []string{"c"},
 						Usage:   "Clear all games from the database",
 						Flags: []cli.Flag{
-							&cli.StringFlag{
-								Name:    "database",
-								Aliases: []string{"db"},
-								Usage:   "Path to database file",
-								Value:   "~/.gochess/games.db",
-							},
+							databaseFlag(),
 							&cli.BoolFlag{
 								Name:    "force",
 								Aliases: []string{"f"},
@@ -470,6 +1771,52 @@ func main() {
 						},
 						Action: db.ClearCommand,
 					},
+					{
+						Name:   "stats",
+						Usage:  "Show statistics for configured players (same as 'gochess stats')",
+						Flags:  statsFlags(),
+						Action: statsCommand,
+					},
+				},
+			},
+			{
+				Name:      "completion",
+				Usage:     "Print the shell completion script for bash, zsh or fish",
+				ArgsUsage: "bash|zsh|fish",
+				Description: "Load the completions in the current shell with, for example,\n" +
+					"  source <(gochess completion bash)\n" +
+					"or install them with\n" +
+					"  gochess completion fish > ~/.config/fish/completions/gochess.fish",
+				Action: completionCommand,
+			},
+			{
+				Name:  "docs",
+				Usage: "Generate documentation from the command tree",
+				Subcommands: []*cli.Command{
+					{
+						Name:  "man",
+						Usage: "Generate the gochess(1) man page",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:    "output",
+								Aliases: []string{"o"},
+								Usage:   "Output file (default: stdout)",
+							},
+						},
+						Action: docsManCommand,
+					},
+					{
+						Name:  "markdown",
+						Usage: "Generate the command reference as Markdown",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:    "output",
+								Aliases: []string{"o"},
+								Usage:   "Output file (default: stdout)",
+							},
+						},
+						Action: docsMarkdownCommand,
+					},
 				},
 			},
 		},
@@ -481,20 +1828,154 @@ func main() {
 	}
 }
 
+// syncFlags returns the flags shared by the "sync daemon" and "sync run"
+// commands.
+func syncFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.BoolFlag{
+			Name:    "verbose",
+			Aliases: []string{"v"},
+			Usage:   "Show detailed error messages",
+		},
+		&cli.BoolFlag{
+			Name:  "analyze",
+			Usage: "Review the new games with the engine and save the analyses",
+		},
+		&cli.BoolFlag{
+			Name:  "player-profiles",
+			Usage: "Fetch the title, country and membership status of new Chess.com opponents",
+		},
+		&cli.StringFlag{
+			Name:    "engine",
+			Aliases: []string{"e"},
+			Usage:   "Path to chess engine executable, or the name of an engine profile from config (with --analyze)",
+		},
+		&cli.StringFlag{
+			Name:  "protocol",
+			Usage: "Engine protocol: uci or cecp (xboard/winboard)",
+		},
+		&cli.IntFlag{
+			Name:    "depth",
+			Aliases: []string{"d"},
+			Usage:   "Analysis depth per position",
+			Value:   defaultReviewDepth,
+		},
+		&cli.IntFlag{
+			Name:  "lines",
+			Usage: "Lines per position (2 or more enables brilliancy detection)",
+			Value: defaultReviewLines,
+		},
+	}
+}
+
+// statsFlags returns the flags shared by the "stats" and "db stats" commands.
+func statsFlags() []cli.Flag {
+	return []cli.Flag{
+		databaseFlag(),
+		readOnlyFlag(),
+		&cli.StringSliceFlag{
+			Name:    "player",
+			Aliases: []string{"p"},
+			Usage:   "Filter statistics for specific player(s) (can be used multiple times)",
+		},
+		&cli.BoolFlag{
+			Name:  "all",
+			Usage: "Show statistics for all players in database (not just configured users)",
+		},
+		&cli.StringFlag{
+			Name:    "format",
+			Aliases: []string{"f"},
+			Usage:   "Output format (table, csv, json, or tui)",
+			Value:   "table",
+		},
+		output.JSONFlag(),
+		&cli.BoolFlag{
+			Name:  "tui",
+			Usage: "Use pretty TUI output (same as --format=tui)",
+		},
+		&cli.BoolFlag{
+			Name:  "time-usage",
+			Usage: "Show clock usage statistics from %clk comments instead",
+		},
+		&cli.BoolFlag{
+			Name:  "endgames",
+			Usage: "Show results by the type of endgame reached instead",
+		},
+		&cli.BoolFlag{
+			Name:  "phases",
+			Usage: "Show the average length of the opening, middlegame and endgame instead",
+		},
+		&cli.BoolFlag{
+			Name:  "first-moves",
+			Usage: "Show first moves as White and responses as Black, by time class and year, instead",
+		},
+		&cli.BoolFlag{
+			Name:  "streaks",
+			Usage: "Show winning and losing streaks, results after a loss and games per session instead",
+		},
+		&cli.BoolFlag{
+			Name:  "by-termination",
+			Usage: "Show how games ended (checkmate, resignation, timeout, ...) instead",
+		},
+		&cli.StringFlag{
+			Name:  "by-opponent",
+			Usage: "Show results against Chess.com opponents grouped by title, country or status instead (profiles fetched with import --player-profiles)",
+		},
+		&cli.StringFlag{
+			Name:  "time-class",
+			Usage: "Only count games of this time class: bullet, blitz, rapid, classical or daily",
+		},
+		&cli.BoolFlag{
+			Name:  "by-opening",
+			Usage: "Show results as an opening tree to drill down instead (indented text, json or tui)",
+		},
+		&cli.IntFlag{
+			Name:  "depth",
+			Usage: "With --by-opening, number of plies of the tree",
+			Value: defaultStatsTreeDepth,
+		},
+		&cli.IntFlag{
+			Name:  "min-games",
+			Usage: "With --by-opening, leave out moves played in fewer games",
+			Value: 1,
+		},
+	}
+}
+
 func statsCommand(c *cli.Context) error {
-	dbPath := expandPath(c.String("database"))
+	dbPath, err := config.DatabasePath(c)
+	if err != nil {
+		return err
+	}
+	dbPath = expandPath(dbPath)
 	playerFilter := c.StringSlice("player")
 	showAll := c.Bool("all")
 	format := c.String("format")
 	useTUI := c.Bool("tui")
+	timeClass := strings.ToLower(c.String("time-class"))
+	if timeClass != "" {
+		if err := db.ValidateTimeClass(timeClass); err != nil {
+			return err
+		}
+		if c.Bool("time-usage") || c.Bool("endgames") || c.Bool("streaks") || c.Bool("first-moves") || c.Bool("phases") {
+			return fmt.Errorf("--time-class cannot be combined with --time-usage, --endgames, --streaks, --first-moves or --phases")
+		}
+	}
 
 	// If --tui flag is set, use TUI format
 	if useTUI {
 		format = "tui"
 	}
+	if output.JSON(c) {
+		format = "json"
+	}
+	out := os.Stdout
+	if format == "json" {
+		out = os.Stderr
+	}
 
 	// Route to TUI if requested
-	if format == "tui" {
+	if format == "tui" && !c.Bool("by-opening") {
 		return statsTUICommand(c)
 	}
 
@@ -505,8 +1986,8 @@ func statsCommand(c *cli.Context) error {
 	}
 
 	// Open database connection
-	fmt.Printf("Opening database at %s...\n", dbPath)
-	database, err := db.New(dbPath)
+	fmt.Fprintf(out, "Opening database at %s...\n", dbPath)
+	database, err := db.Open(dbPath, db.FlagOpenMode(c))
 	if err != nil {
 		return fmt.Errorf("failed to open database: %w", err)
 	}
@@ -518,7 +1999,7 @@ func statsCommand(c *cli.Context) error {
 		return fmt.Errorf("failed to get game count: %w", err)
 	}
 
-	if count == 0 {
+	if count == 0 && format != "json" {
 		fmt.Println("Database is empty")
 		return nil
 	}
@@ -530,28 +2011,51 @@ func statsCommand(c *cli.Context) error {
 		players = playerFilter
 	} else if !showAll {
 		// Use configured users by default
-		if cfg.ChessCom != nil && cfg.ChessCom.Username != "" {
-			players = append(players, cfg.ChessCom.Username)
-		}
-		if cfg.Lichess != nil && cfg.Lichess.Username != "" {
-			players = append(players, cfg.Lichess.Username)
-		}
+		players = append(players, cfg.GetUsernames("chesscom")...)
+		players = append(players, cfg.GetUsernames("lichess")...)
 
 		if len(players) == 0 {
-			fmt.Println("No configured users found. Use --all to show all players or configure users with 'gochess config add-user'")
+			fmt.Fprintln(out, "No configured users found. Use --all to show all players or configure users with 'gochess config add-user'")
 			return nil
 		}
 	}
 	// else showAll is true, so players remains nil/empty and we get all players
 
+	if c.Bool("time-usage") {
+		return timeUsageStats(c, database, players)
+	}
+	if c.Bool("endgames") {
+		return endgameStats(c, database, players)
+	}
+	if c.Bool("by-termination") {
+		return terminationStats(c, database, players, timeClass)
+	}
+	if by := c.String("by-opponent"); by != "" {
+		if len(players) == 0 {
+			return fmt.Errorf("--by-opponent needs players: use --player or configure users instead of --all")
+		}
+		return opponentStats(c, database, players, strings.ToLower(by), timeClass)
+	}
+	if c.Bool("streaks") {
+		return streakStats(c, database, players)
+	}
+	if c.Bool("first-moves") {
+		return firstMoveStats(c, database, players)
+	}
+	if c.Bool("phases") {
+		return phaseStats(c, database, players)
+	}
+	if c.Bool("by-opening") {
+		return openingTreeStats(c, database, players, timeClass, format)
+	}
+	if format == "json" {
+		return statsJSON(c, database, count, players, timeClass)
+	}
+
 	// Get player statistics
 	fmt.Println("Calculating player statistics...")
 	var stats []db.PlayerStats
-	if len(players) > 0 {
-		stats, err = database.GetPlayerStatsFiltered(c.Context, players)
-	} else {
-		stats, err = database.GetPlayerStats(c.Context)
-	}
+	stats, err = database.GetPlayerStatsForTimeClass(c.Context, players, timeClass)
 	if err != nil {
 		return fmt.Errorf("failed to get player statistics: %w", err)
 	}
@@ -614,6 +2118,18 @@ func statsCommand(c *cli.Context) error {
 			fmt.Printf("    As Black: %d games, %d-%d-%d (W-L-D), %.1f%% win rate\n",
 				s.BlackGames, s.BlackWins, s.BlackLosses, s.BlackDraws, s.BlackWinRate)
 
+			// Win rates by opponent rating difference
+			if len(s.RatingBands) > 0 {
+				fmt.Printf("\n  Performance by Opponent Rating (opponent - you):\n")
+				fmt.Printf("    %-6s %-6s %-12s %-8s %-8s %-8s\n", "BAND", "GAMES", "W-L-D", "WIN RATE", "AS WHITE", "AS BLACK")
+				for _, b := range s.RatingBands {
+					fmt.Printf("    %-6s %-6d %-12s %-8s %-8s %-8s\n",
+						b.Band, b.Games, fmt.Sprintf("%d-%d-%d", b.Wins, b.Losses, b.Draws),
+						fmt.Sprintf("%.1f%%", b.WinRate),
+						bandRate(b.WhiteGames, b.WhiteWinRate), bandRate(b.BlackGames, b.BlackWinRate))
+				}
+			}
+
 			// Time control breakdown
 			if s.BulletGames > 0 || s.BlitzGames > 0 || s.RapidGames > 0 || s.ClassicalGames > 0 {
 				fmt.Printf("\n  Games by Time Control:\n")
@@ -640,11 +2156,7 @@ func statsCommand(c *cli.Context) error {
 	// Get and display opening statistics (only in table format)
 	if format == "table" {
 		var openingStats []db.OpeningStats
-		if len(players) > 0 {
-			openingStats, err = database.GetOpeningStatsFiltered(c.Context, players)
-		} else {
-			openingStats, err = database.GetOpeningStats(c.Context)
-		}
+		openingStats, err = database.GetOpeningStatsForTimeClass(c.Context, players, timeClass)
 
 		if err != nil {
 			fmt.Printf("\nWarning: Failed to get opening statistics: %v\n", err)
@@ -752,6 +2264,73 @@ func statsCommand(c *cli.Context) error {
 	return nil
 }
 
+// statsJSON writes the player, opening and position statistics of the "stats"
+// command as JSON
+func statsJSON(c *cli.Context, database *db.DB, count int, players []string, timeClass string) error {
+	var (
+		stats    []db.PlayerStats
+		openings []db.OpeningStats
+		err      error
+	)
+	stats, err = database.GetPlayerStatsForTimeClass(c.Context, players, timeClass)
+	if err != nil {
+		return fmt.Errorf("failed to get player statistics: %w", err)
+	}
+	openings, err = database.GetOpeningStatsForTimeClass(c.Context, players, timeClass)
+	if err != nil {
+		return fmt.Errorf("failed to get opening statistics: %w", err)
+	}
+	uniqueCount, topPositions, err := database.GetPositionStats(c.Context)
+	if err != nil {
+		return fmt.Errorf("failed to get position statistics: %w", err)
+	}
+
+	if stats == nil {
+		stats = []db.PlayerStats{}
+	}
+	if openings == nil {
+		openings = []db.OpeningStats{}
+	}
+	if topPositions == nil {
+		topPositions = []db.PositionFrequency{}
+	}
+	return output.WriteJSON(map[string]interface{}{
+		"total_games":      count,
+		"players":          stats,
+		"openings":         openings,
+		"unique_positions": uniqueCount,
+		"top_positions":    topPositions,
+	})
+}
+
+// bandRate formats the win rate of a rating band by color, "-" if no games
+// were played with the color
+func bandRate(games int, winRate float64) string {
+	if games == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", winRate)
+}
+
+// databaseFlag returns the --database flag shared by the commands that use
+// the game database. When it is not given, the configured database is used.
+func databaseFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:    "database",
+		Aliases: []string{"db"},
+		Usage:   "Path to database file, or :memory: for one discarded when the command ends (default: database_path from config, or ~/.gochess/games.db)",
+	}
+}
+
+// readOnlyFlag returns the flag opening the database read-only, for commands
+// that only read it
+func readOnlyFlag() cli.Flag {
+	return &cli.BoolFlag{
+		Name:  "read-only",
+		Usage: "Open the database read-only, to read it safely while another process such as the sync daemon writes to it",
+	}
+}
+
 // expandPath expands the tilde in file paths to the user's home directory
 func expandPath(path string) string {
 	if path == "" {
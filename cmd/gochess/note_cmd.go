@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/kyleboon/gochess/internal/output"
+	"github.com/urfave/cli/v2"
+)
+
+// defaultEditor is the editor used for notes when neither $VISUAL nor
+// $EDITOR is set
+const defaultEditor = "vi"
+
+// dbNoteCommand prints, sets, edits or deletes the user's own note on a game,
+// which is kept apart from the comments in its PGN
+func dbNoteCommand(c *cli.Context) error {
+	id := c.Int("id")
+	set := 0
+	for _, name := range []string{"edit", "text", "delete"} {
+		if c.IsSet(name) {
+			set++
+		}
+	}
+	if set > 1 {
+		return fmt.Errorf("--edit, --text and --delete cannot be combined")
+	}
+
+	database, err := openDatabase(c)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = database.Close() }()
+
+	// Fail on a game that does not exist, even just to print its note
+	if _, err := database.GetGame(c.Context, id); err != nil {
+		return err
+	}
+	note, err := database.GetNote(c.Context, id)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case c.Bool("delete"):
+		if err := database.SetNote(c.Context, id, ""); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Deleted the note on game #%d\n", id)
+		return nil
+	case c.IsSet("text"), c.Bool("edit"):
+		text := c.String("text")
+		if c.Bool("edit") {
+			if text, err = editText(note, fmt.Sprintf("gochess-note-%d-*.txt", id)); err != nil {
+				return err
+			}
+		}
+		if err := database.SetNote(c.Context, id, text); err != nil {
+			return err
+		}
+		if strings.TrimSpace(text) == "" {
+			fmt.Fprintf(os.Stderr, "Game #%d has no note\n", id)
+		} else {
+			fmt.Fprintf(os.Stderr, "Saved the note on game #%d\n", id)
+		}
+		return nil
+	}
+
+	if output.JSON(c) {
+		return output.WriteJSON(map[string]interface{}{"id": id, "notes": note})
+	}
+	if note == "" {
+		fmt.Fprintf(os.Stderr, "Game #%d has no note; add one with --edit or --text\n", id)
+		return nil
+	}
+	fmt.Println(note)
+	return nil
+}
+
+// editText opens text in the user's editor, from $VISUAL or $EDITOR, in a
+// temporary file named by pattern, and returns it as saved
+func editText(text, pattern string) (string, error) {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = defaultEditor
+	}
+
+	f, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer func() { _ = os.Remove(f.Name()) }()
+	if text != "" {
+		text += "\n"
+	}
+	if _, err := f.WriteString(text); err != nil {
+		_ = f.Close()
+		return "", fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("failed to write temporary file: %w", err)
+	}
+
+	// The editor may come with arguments, as in "code --wait"
+	args := strings.Fields(editor)
+	cmd := exec.Command(args[0], append(args[1:], f.Name())...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("failed to run editor %q: %w", editor, err)
+	}
+
+	edited, err := os.ReadFile(f.Name())
+	if err != nil {
+		return "", fmt.Errorf("failed to read temporary file: %w", err)
+	}
+	return string(edited), nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/kyleboon/gochess/internal/db"
+	"github.com/kyleboon/gochess/internal/output"
+	"github.com/urfave/cli/v2"
+)
+
+// opponentStats prints the results of the given players against their
+// Chess.com opponents grouped by title, country or membership status, as
+// stored by import --player-profiles
+func opponentStats(c *cli.Context, database *db.DB, players []string, by, timeClass string) error {
+	asJSON := output.JSON(c) || c.String("format") == "json"
+	out := os.Stdout
+	if asJSON {
+		out = os.Stderr
+	}
+	fmt.Fprintf(out, "Calculating results by opponent %s...\n", by)
+	stats, err := database.GetOpponentStats(c.Context, players, by, timeClass)
+	if err != nil {
+		return fmt.Errorf("failed to get opponent statistics: %w", err)
+	}
+
+	if asJSON {
+		if stats == nil {
+			stats = []db.OpponentStats{}
+		}
+		return output.WriteJSON(map[string]interface{}{
+			"by":        by,
+			"opponents": stats,
+		})
+	}
+
+	if len(stats) == 0 {
+		fmt.Println("No finished games found")
+		return nil
+	}
+
+	if c.String("format") == "csv" {
+		fmt.Println("Group,Games,Wins,Losses,Draws,Score")
+		for _, s := range stats {
+			fmt.Printf("%s,%d,%d,%d,%d,%.1f\n", s.Group, s.Games, s.Wins, s.Losses, s.Draws, s.Score)
+		}
+		return nil
+	}
+
+	fmt.Printf("\nResults by opponent %s:\n", by)
+	fmt.Printf("  %-12s %-6s %-6s %-6s %-6s %-8s\n", "GROUP", "GAMES", "WINS", "LOSSES", "DRAWS", "SCORE")
+	fmt.Println("  " + repeatString("-", 50))
+	for _, s := range stats {
+		fmt.Printf("  %-12s %-6d %-6d %-6d %-6d %.1f%%\n", s.Group, s.Games, s.Wins, s.Losses, s.Draws, s.Score)
+	}
+	for _, s := range stats {
+		if s.Group == db.UnknownProfile {
+			fmt.Println("\nOpponents whose profile was not fetched are shown as unknown: run 'gochess import --player-profiles' to fetch them")
+			break
+		}
+	}
+	return nil
+}
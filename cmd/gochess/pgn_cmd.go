@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kyleboon/gochess/internal/db"
+	"github.com/kyleboon/gochess/internal/pgn"
+	"github.com/urfave/cli/v2"
+)
+
+// pgnSplitCommand splits a PGN file into files of at most --games-per-file
+// games each, named after the input file: big.pgn becomes big-0001.pgn,
+// big-0002.pgn and so on. Games are copied as written, without parsing them
+func pgnSplitCommand(c *cli.Context) error {
+	inPath := expandPath(c.String("input"))
+	perFile := c.Int("games-per-file")
+	if perFile <= 0 {
+		return fmt.Errorf("--games-per-file must be positive")
+	}
+	outDir := c.String("output-dir")
+	if outDir == "" {
+		outDir = filepath.Dir(inPath)
+	}
+	outDir = expandPath(outDir)
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	base := strings.TrimSuffix(filepath.Base(inPath), filepath.Ext(inPath))
+
+	in, err := os.Open(inPath)
+	if err != nil {
+		return fmt.Errorf("failed to open input file: %w", err)
+	}
+	defer func() { _ = in.Close() }()
+
+	var (
+		out   *os.File
+		w     *bufio.Writer
+		files int
+		games int
+	)
+	closeFile := func() error {
+		if out == nil {
+			return nil
+		}
+		if err := w.Flush(); err != nil {
+			_ = out.Close()
+			return fmt.Errorf("failed to write output file: %w", err)
+		}
+		return out.Close()
+	}
+
+	reader := pgn.NewReader(in)
+	for {
+		text, err := reader.ReadText()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			_ = closeFile()
+			return fmt.Errorf("failed to read PGN: %w", err)
+		}
+		if games%perFile == 0 {
+			if err := closeFile(); err != nil {
+				return err
+			}
+			files++
+			name := filepath.Join(outDir, fmt.Sprintf("%s-%04d.pgn", base, files))
+			if out, err = os.Create(name); err != nil {
+				return fmt.Errorf("failed to create output file: %w", err)
+			}
+			w = bufio.NewWriter(out)
+		}
+		if err := writeGameText(w, text); err != nil {
+			_ = closeFile()
+			return fmt.Errorf("failed to write output file: %w", err)
+		}
+		games++
+	}
+	if err := closeFile(); err != nil {
+		return err
+	}
+
+	fmt.Printf("Split %d games into %d files in %s\n", games, files, outDir)
+	return nil
+}
+
+// pgnCatCommand concatenates PGN files. With --dedupe, games that are
+// duplicates by the same rule the database import uses (players, date,
+// result and moves, ignoring comments) are written only once
+func pgnCatCommand(c *cli.Context) error {
+	if c.NArg() == 0 {
+		return fmt.Errorf("at least one PGN file is required")
+	}
+	dedupe := c.Bool("dedupe")
+
+	var out io.Writer = os.Stdout
+	if outPath := c.String("output"); outPath != "" && outPath != "-" {
+		f, err := os.Create(expandPath(outPath))
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer func() { _ = f.Close() }()
+		out = f
+	}
+	w := bufio.NewWriter(out)
+
+	seen := make(map[string]bool)
+	written, duplicates := 0, 0
+	for _, path := range c.Args().Slice() {
+		f, err := os.Open(expandPath(path))
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %w", path, err)
+		}
+		reader := pgn.NewReader(f)
+		for {
+			text, err := reader.ReadText()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				_ = f.Close()
+				return fmt.Errorf("failed to read %s: %w", path, err)
+			}
+			if dedupe {
+				if hash, ok := gameTextHash(text); ok {
+					if seen[hash] {
+						duplicates++
+						continue
+					}
+					seen[hash] = true
+				}
+			}
+			if err := writeGameText(w, text); err != nil {
+				_ = f.Close()
+				return fmt.Errorf("failed to write output: %w", err)
+			}
+			written++
+		}
+		_ = f.Close()
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+
+	if dedupe {
+		fmt.Fprintf(os.Stderr, "Wrote %d games, skipped %d duplicates\n", written, duplicates)
+	} else {
+		fmt.Fprintf(os.Stderr, "Wrote %d games\n", written)
+	}
+	return nil
+}
+
+// writeGameText writes the text of a game followed by one blank line
+func writeGameText(w io.Writer, text string) error {
+	_, err := io.WriteString(w, strings.TrimRight(text, "\r\n\t ")+"\n\n")
+	return err
+}
+
+// gameTextHash returns the hash the database identifies the game of text
+// by, and false if its tags cannot be parsed, in which case the game is
+// never treated as a duplicate
+func gameTextHash(text string) (string, bool) {
+	var pgnDB pgn.DB
+	if errs := pgnDB.Parse(text); len(errs) > 0 || len(pgnDB.Games) == 0 {
+		return "", false
+	}
+	return db.CalculateGameHash(pgnDB.Games[0], db.ExtractMoveText(text)), true
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/kyleboon/gochess/internal/db"
+	"github.com/kyleboon/gochess/internal/output"
+	"github.com/urfave/cli/v2"
+)
+
+// phaseStats prints the average length of the opening, middlegame and
+// endgame of the games of the given players (all players if empty), in moves
+func phaseStats(c *cli.Context, database *db.DB, players []string) error {
+	asJSON := output.JSON(c) || c.String("format") == "json"
+	out := os.Stdout
+	if asJSON {
+		out = os.Stderr
+	}
+	fmt.Fprintln(out, "Calculating game phases...")
+	stats, err := database.GetPhaseStats(c.Context, players)
+	if err != nil {
+		return fmt.Errorf("failed to get game phases: %w", err)
+	}
+
+	if asJSON {
+		return output.WriteJSON(map[string]interface{}{
+			"phases": stats,
+		})
+	}
+
+	if len(stats) == 0 {
+		fmt.Println("No games with moves found")
+		return nil
+	}
+
+	if c.String("format") == "csv" {
+		fmt.Println("Player,Games,Opening,Middlegame,Endgame,EndgameGames")
+		for _, s := range stats {
+			fmt.Printf("%s,%d,%.1f,%.1f,%.1f,%d\n", s.Player, s.Games, s.Opening/2, s.Middlegame/2, s.Endgame/2, s.EndgameGames)
+		}
+		return nil
+	}
+
+	fmt.Printf("\nAverage moves per game phase:\n")
+	fmt.Printf("  %-20s %-6s %-8s %-11s %-8s %s\n", "PLAYER", "GAMES", "OPENING", "MIDDLEGAME", "ENDGAME", "REACHED ENDGAME")
+	fmt.Println("  " + repeatString("-", 75))
+	for _, s := range stats {
+		name := s.Player
+		if len(name) > 20 {
+			name = name[:17] + "..."
+		}
+		fmt.Printf("  %-20s %-6d %-8.1f %-11.1f %-8.1f %.1f%%\n", name, s.Games, s.Opening/2, s.Middlegame/2, s.Endgame/2,
+			float64(s.EndgameGames)/float64(s.Games)*100)
+	}
+	return nil
+}
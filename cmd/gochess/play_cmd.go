@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/kyleboon/gochess/internal/config"
+	"github.com/kyleboon/gochess/internal/db"
+	"github.com/kyleboon/gochess/internal/eco"
+	"github.com/kyleboon/gochess/internal/pgn"
+	"github.com/kyleboon/gochess/internal/tui"
+	"github.com/urfave/cli/v2"
+)
+
+// playCommand starts a game between two players at the terminal, with a
+// chess clock
+func playCommand(c *cli.Context) error {
+	if err := logToFileForTUI(c); err != nil {
+		return err
+	}
+	cfg, err := config.LoadOrDefault()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	model, err := newPlayModel(c, cfg)
+	if err != nil {
+		return err
+	}
+	return runPlayModel(model)
+}
+
+// newPlayModel sets up a game between two players at the terminal with the
+// configured clock, board and keys
+func newPlayModel(c *cli.Context, cfg *config.Config) (tui.PlayModel, error) {
+	base, increment, err := timeControl(c, cfg)
+	if err != nil {
+		return tui.PlayModel{}, err
+	}
+
+	keys, err := keyMap(cfg)
+	if err != nil {
+		return tui.PlayModel{}, err
+	}
+
+	openings, err := eco.NewDatabase()
+	if err != nil {
+		return tui.PlayModel{}, fmt.Errorf("failed to load ECO database: %w", err)
+	}
+
+	return tui.NewPlayModel(base, increment).
+		WithBoardOptions(boardOptions(cfg), settingsSaver(cfg)).
+		WithGameAdder(lazyGameAdder(c.Context, cfg)).
+		WithOpenings(openings).
+		WithKeys(keys), nil
+}
+
+// runPlayModel plays a game at the terminal until the players quit
+func runPlayModel(model tui.PlayModel) error {
+	p := tea.NewProgram(model, tea.WithAltScreen(), tea.WithMouseCellMotion())
+	if _, err := p.Run(); err != nil {
+		return fmt.Errorf("failed to run TUI: %w", err)
+	}
+	return nil
+}
+
+// timeControl returns the clock for new games. The time-control flag takes
+// precedence over the configured time control.
+func timeControl(c *cli.Context, cfg *config.Config) (base, increment time.Duration, err error) {
+	tc := defaultTimeControl
+	if cfg.TUI != nil && cfg.TUI.TimeControl != "" {
+		tc = cfg.TUI.TimeControl
+	}
+	if c.IsSet("time-control") {
+		tc = c.String("time-control")
+	}
+	base, increment, ok := pgn.ParseTimeControl(tc)
+	if !ok || base <= 0 {
+		return 0, 0, fmt.Errorf("invalid time control %q (expected base+increment in seconds, e.g. 300+3)", tc)
+	}
+	return base, increment, nil
+}
+
+// lazyGameAdder returns a function that adds games to the configured
+// database, which is only opened when a game is saved to it.
+func lazyGameAdder(ctx context.Context, cfg *config.Config) tui.AddGameFunc {
+	return func(pgnText string) error {
+		database, err := db.New(expandPath(cfg.DatabasePath))
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		defer func() { _ = database.Close() }()
+		return gameAdder(ctx, database)(pgnText)
+	}
+}
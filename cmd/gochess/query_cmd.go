@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/kyleboon/gochess/internal/config"
+	"github.com/kyleboon/gochess/internal/db"
+	"github.com/kyleboon/gochess/internal/logging"
+	"github.com/kyleboon/gochess/internal/output"
+	"github.com/urfave/cli/v2"
+)
+
+// dbQueryCommand runs an SQL query read-only against the game database and
+// prints the rows as a table, CSV or JSON
+func dbQueryCommand(c *cli.Context) error {
+	switch {
+	case c.NArg() > 1 && strings.HasPrefix(c.Args().Get(1), "-"):
+		return fmt.Errorf("flags go before the query, as in: gochess db query --format csv \"SELECT ...\"")
+	case c.NArg() != 1:
+		return fmt.Errorf("expected one SQL query, e.g. \"SELECT white, count(*) FROM games GROUP BY 1\"")
+	}
+	format := strings.ToLower(c.String("format"))
+	if output.JSON(c) {
+		format = "json"
+	}
+	if format != "table" && format != "csv" && format != "json" {
+		return fmt.Errorf("unknown format %q; use table, csv or json", format)
+	}
+
+	// A read-only connection, so that SQLite refuses writes whatever the
+	// query
+	dbPath, err := config.DatabasePath(c)
+	if err != nil {
+		return err
+	}
+	database, err := db.OpenWithLogger(expandPath(dbPath), db.ReadOnly, logging.Default())
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer func() { _ = database.Close() }()
+
+	result, err := database.Query(c.Context, c.Args().First())
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case "json":
+		rows := make([]map[string]interface{}, 0, len(result.Rows))
+		for _, values := range result.Rows {
+			row := make(map[string]interface{}, len(values))
+			for i, v := range values {
+				row[result.Columns[i]] = v
+			}
+			rows = append(rows, row)
+		}
+		return output.WriteJSON(rows)
+	case "csv":
+		w := csv.NewWriter(os.Stdout)
+		_ = w.Write(result.Columns)
+		for _, values := range result.Rows {
+			record := make([]string, len(values))
+			for i, v := range values {
+				record[i] = queryValue(v)
+			}
+			_ = w.Write(record)
+		}
+		w.Flush()
+		return w.Error()
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, strings.ToUpper(strings.Join(result.Columns, "\t")))
+	for _, values := range result.Rows {
+		record := make([]string, len(values))
+		for i, v := range values {
+			record[i] = queryValue(v)
+		}
+		fmt.Fprintln(w, strings.Join(record, "\t"))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "(%d rows)\n", len(result.Rows))
+	return nil
+}
+
+// queryValue formats a value of a query result, NULL as an empty string
+func queryValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case time.Time:
+		return v.Format(time.RFC3339)
+	default:
+		return fmt.Sprint(v)
+	}
+}
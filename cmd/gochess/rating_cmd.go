@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/kyleboon/gochess/internal/output"
+	"github.com/kyleboon/gochess/internal/rating"
+	"github.com/urfave/cli/v2"
+)
+
+// ratingPerfCommand prints a player's performance rating and expected
+// against actual score over their games in the database, as for a
+// tournament imported from PGN
+func ratingPerfCommand(c *cli.Context) error {
+	database, err := openDatabase(c)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = database.Close() }()
+
+	perf, err := database.GetPerformance(c.Context, c.String("player"), c.String("event"), c.Int("rating"))
+	if err != nil {
+		return err
+	}
+	if output.JSON(c) {
+		return output.WriteJSON(perf)
+	}
+	if len(perf.Games) == 0 {
+		fmt.Printf("No finished games of %s found.\n", perf.Player)
+		return nil
+	}
+
+	title := perf.Player
+	if perf.Event != "" {
+		title += " at " + perf.Event
+	}
+	fmt.Println(title)
+	fmt.Println(repeatString("=", len(title)))
+	fmt.Println()
+
+	fmt.Printf("%-10s %-5s %-5s %-24s %6s %6s %8s\n", "DATE", "ROUND", "COLOR", "OPPONENT", "RATING", "SCORE", "EXPECTED")
+	for _, g := range perf.Games {
+		opponentRating, expected := "-", "-"
+		if g.OpponentRating > 0 {
+			opponentRating = fmt.Sprint(g.OpponentRating)
+		}
+		if g.Expected > 0 {
+			expected = fmt.Sprintf("%.2f", g.Expected)
+		}
+		fmt.Printf("%-10s %-5s %-5s %-24s %6s %6s %8s\n",
+			g.Date, g.Round, g.Color, g.Opponent, opponentRating, formatScore(g.Score), expected)
+	}
+	fmt.Println()
+
+	fmt.Printf("Score:              %s / %d\n", formatScore(perf.Score), len(perf.Games))
+	if perf.Rated == 0 {
+		fmt.Println("No games against rated opponents, so no performance rating.")
+		return nil
+	}
+	if unrated := len(perf.Games) - perf.Rated; unrated > 0 {
+		fmt.Printf("Rated games:        %s / %d (%d against unrated opponents left out)\n",
+			formatScore(perf.RatedScore), perf.Rated, unrated)
+	}
+	fmt.Printf("Average opponent:   %.0f\n", perf.AverageOpponent)
+	fmt.Printf("Performance rating: %.0f\n", perf.Performance)
+	if perf.Expected > 0 {
+		fmt.Printf("Expected score:     %.2f (%+.2f)\n", perf.Expected, perf.RatedScore-perf.Expected)
+	}
+	return nil
+}
+
+// ratingExpectedCommand prints the expected score between two ratings and
+// the rating changes each result would bring
+func ratingExpectedCommand(c *cli.Context) error {
+	a, b, k := c.Float64("a"), c.Float64("b"), c.Float64("k")
+	expected := rating.ExpectedScore(a, b)
+
+	if output.JSON(c) {
+		return output.WriteJSON(map[string]interface{}{
+			"a":        a,
+			"b":        b,
+			"expected": expected,
+			"k":        k,
+			"win":      rating.Change(a, b, 1, k),
+			"draw":     rating.Change(a, b, 0.5, k),
+			"loss":     rating.Change(a, b, 0, k),
+		})
+	}
+
+	fmt.Printf("Expected score of %.0f against %.0f: %.3f (%.1f%%)\n", a, b, expected, expected*100)
+	fmt.Printf("Expected score of %.0f against %.0f: %.3f (%.1f%%)\n", b, a, 1-expected, (1-expected)*100)
+	fmt.Printf("\nRating change for %.0f with K=%g:\n", a, k)
+	fmt.Printf("  Win:  %+.1f\n", rating.Change(a, b, 1, k))
+	fmt.Printf("  Draw: %+.1f\n", rating.Change(a, b, 0.5, k))
+	fmt.Printf("  Loss: %+.1f\n", rating.Change(a, b, 0, k))
+	return nil
+}
+
+// formatScore formats a score with ½ for half points, as in "3½"
+func formatScore(score float64) string {
+	whole, frac := math.Modf(score)
+	switch {
+	case frac == 0:
+		return fmt.Sprintf("%.0f", whole)
+	case whole == 0:
+		return "½"
+	default:
+		return fmt.Sprintf("%.0f½", whole)
+	}
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/kyleboon/gochess/internal/config"
+	"github.com/kyleboon/gochess/internal/db"
+	"github.com/kyleboon/gochess/internal/logging"
+	"github.com/kyleboon/gochess/internal/output"
+	"github.com/kyleboon/gochess/internal/pgn"
+	"github.com/urfave/cli/v2"
+)
+
+// openDatabase opens the database given by the --database flag or the config
+func openDatabase(c *cli.Context) (*db.DB, error) {
+	dbPath, err := config.DatabasePath(c)
+	if err != nil {
+		return nil, err
+	}
+	database, err := db.NewWithLogger(expandPath(dbPath), logging.Default())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	return database, nil
+}
+
+// repertoireImportCommand stores the moves of a PGN file, variations
+// included, as a named repertoire
+func repertoireImportCommand(c *cli.Context) error {
+	name := c.String("name")
+	color := strings.ToLower(c.String("color"))
+	if color == "" {
+		// "Black: Sicilian" is a repertoire for Black
+		color = "white"
+		if strings.Contains(strings.ToLower(name), "black") {
+			color = "black"
+		}
+	}
+
+	f, err := os.Open(expandPath(c.String("pgn")))
+	if err != nil {
+		return fmt.Errorf("failed to open PGN file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var games []*pgn.Game
+	reader := pgn.NewReader(f)
+	for {
+		game, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read PGN: %w", err)
+		}
+		games = append(games, game)
+	}
+	if len(games) == 0 {
+		return fmt.Errorf("no games found in PGN file")
+	}
+
+	database, err := openDatabase(c)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = database.Close() }()
+
+	count, err := database.ImportRepertoire(c.Context, name, color, games)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Imported repertoire %q for %s: %d moves from %d games\n", name, color, count, len(games))
+	return nil
+}
+
+// repertoireListCommand lists the repertoires in the database
+func repertoireListCommand(c *cli.Context) error {
+	database, err := openDatabase(c)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = database.Close() }()
+
+	repertoires, err := database.ListRepertoires(c.Context)
+	if err != nil {
+		return err
+	}
+	if output.JSON(c) {
+		if repertoires == nil {
+			repertoires = []db.Repertoire{}
+		}
+		return output.WriteJSON(repertoires)
+	}
+	if len(repertoires) == 0 {
+		fmt.Println("No repertoires. Add one with 'gochess repertoire import'.")
+		return nil
+	}
+
+	fmt.Printf("%-32s %-6s %s\n", "NAME", "COLOR", "MOVES")
+	for _, r := range repertoires {
+		fmt.Printf("%-32s %-6s %d\n", r.Name, r.Color, r.Moves)
+	}
+	return nil
+}
+
+// repertoireExportCommand writes a repertoire as a PGN game with variations
+func repertoireExportCommand(c *cli.Context) error {
+	database, err := openDatabase(c)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = database.Close() }()
+
+	game, err := database.ExportRepertoire(c.Context, c.String("name"))
+	if err != nil {
+		return err
+	}
+
+	outPath := c.String("output")
+	if outPath == "" {
+		fmt.Print(game.String())
+		return nil
+	}
+	if err := os.WriteFile(expandPath(outPath), []byte(game.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+	fmt.Printf("Exported repertoire %q to %s\n", c.String("name"), outPath)
+	return nil
+}
+
+// repertoireDeleteCommand deletes a repertoire
+func repertoireDeleteCommand(c *cli.Context) error {
+	database, err := openDatabase(c)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = database.Close() }()
+
+	if err := database.DeleteRepertoire(c.Context, c.String("name")); err != nil {
+		return err
+	}
+	fmt.Printf("Deleted repertoire %q\n", c.String("name"))
+	return nil
+}
+
+// repertoireCheckCommand compares a player's recent games with their
+// repertoires and reports where each game left it, and who deviated
+func repertoireCheckCommand(c *cli.Context) error {
+	player := c.String("player")
+	if player == "" {
+		cfg, err := config.LoadOrDefault()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		if player = cfg.GetUsername("chesscom"); player == "" {
+			player = cfg.GetUsername("lichess")
+		}
+		if player == "" {
+			return fmt.Errorf("a --player is required (or configure a user with 'gochess config add-user')")
+		}
+	}
+
+	database, err := openDatabase(c)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = database.Close() }()
+
+	checks, err := checkRepertoire(c.Context, database, player, c.String("name"), c.Int("games"))
+	if err != nil {
+		return err
+	}
+	if output.JSON(c) {
+		return output.WriteJSON(checks)
+	}
+	if len(checks) == 0 {
+		fmt.Printf("No games of %s to compare with a repertoire.\n", player)
+		return nil
+	}
+
+	deviated := 0
+	for _, check := range checks {
+		move := check.Plies/2 + 1
+		dots := "."
+		if check.Plies%2 == 1 {
+			dots = "..."
+		}
+		var verdict string
+		switch check.Deviation {
+		case db.DeviationPlayer:
+			deviated++
+			verdict = fmt.Sprintf("you deviated with %d%s %s (repertoire: %s)",
+				move, dots, check.Played, strings.Join(check.Expected, ", "))
+		case db.DeviationOpponent:
+			verdict = fmt.Sprintf("opponent left the repertoire with %d%s %s (prepared: %s)",
+				move, dots, check.Played, strings.Join(check.Expected, ", "))
+		default:
+			verdict = fmt.Sprintf("followed the repertoire for %d plies", check.Plies)
+		}
+		fmt.Printf("#%-5d %s  %s - %s  %s\n", check.GameID, check.Date, check.White, check.Black, check.Result)
+		fmt.Printf("       %s: %s\n", check.Repertoire, verdict)
+	}
+	fmt.Printf("\n%s deviated first in %d of %d games\n", player, deviated, len(checks))
+	return nil
+}
+
+// checkRepertoire compares the recent games of player with the repertoires,
+// returning an empty slice rather than nil when there are none
+func checkRepertoire(ctx context.Context, database *db.DB, player, name string, limit int) ([]db.RepertoireCheck, error) {
+	if limit <= 0 {
+		return nil, errors.New("--games must be positive")
+	}
+	checks, err := database.CheckRepertoire(ctx, player, name, limit)
+	if err != nil {
+		return nil, err
+	}
+	if checks == nil {
+		checks = []db.RepertoireCheck{}
+	}
+	return checks, nil
+}
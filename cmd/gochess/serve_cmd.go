@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/kyleboon/gochess/internal/config"
+	"github.com/kyleboon/gochess/internal/db"
+	"github.com/kyleboon/gochess/internal/engine"
+	"github.com/kyleboon/gochess/internal/logging"
+	"github.com/kyleboon/gochess/internal/notify"
+	"github.com/kyleboon/gochess/internal/server"
+	"github.com/urfave/cli/v2"
+)
+
+// serveCommand serves the game database as a JSON API until interrupted
+func serveCommand(c *cli.Context) error {
+	logger := logging.Default()
+
+	cfg, err := config.LoadOrDefault()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	dbPath, err := config.DatabasePath(c)
+	if err != nil {
+		return err
+	}
+	database, err := db.OpenWithLogger(expandPath(dbPath), db.FlagOpenMode(c), logger)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer func() { _ = database.Close() }()
+
+	if c.Int("workers") < 1 {
+		return fmt.Errorf("--workers must be positive")
+	}
+	srv := server.New(database, logger).
+		WithAnalysisWorkers(c.Int("workers")).
+		WithNotifier(notify.FromConfig(cfg.Notify, logger))
+	// Analysis is stored in the database, so it needs one open for writing
+	if c.Bool("read-only") {
+		fmt.Println("Read-only database: game analysis is disabled")
+	} else if c.String("engine") != "" || cfg.GetEnginePath() != "" {
+		srv.WithEngine(func(ctx context.Context) (engine.Analyzer, error) {
+			return openEngine(c, cfg, logger)
+		})
+	} else {
+		fmt.Println("No engine configured: game analysis is disabled")
+	}
+
+	ctx, stop := signal.NotifyContext(c.Context, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	addr := c.String("listen")
+	fmt.Printf("Serving the API on %s (press Ctrl+C to stop)\n", addr)
+	return srv.ListenAndServe(ctx, addr)
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kyleboon/gochess/internal/progress"
+	"github.com/kyleboon/gochess/internal/tui"
+	"github.com/urfave/cli/v2"
+)
+
+// loadSession reads the session saved at path, or returns nil when there is
+// none
+func loadSession(path string) (*tui.Session, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session: %w", err)
+	}
+	session := &tui.Session{}
+	if err := json.Unmarshal(data, session); err != nil {
+		return nil, fmt.Errorf("failed to parse session: %w", err)
+	}
+	return session, nil
+}
+
+// saveSession writes the session file at path
+func saveSession(path string, session tui.Session) error {
+	data, err := json.MarshalIndent(session, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal session: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create session directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write session: %w", err)
+	}
+	return nil
+}
+
+// sessionToRestore returns the saved session of the game browser if it is
+// to be restored: with --restore, or when the user agrees at the prompt.
+// Without a terminal to ask at, nothing is restored
+func sessionToRestore(c *cli.Context, path string) (*tui.Session, error) {
+	if c.IsSet("restore") && !c.Bool("restore") {
+		return nil, nil
+	}
+	session, err := loadSession(path)
+	if err != nil || session == nil || session.GameID == 0 {
+		return nil, err
+	}
+	if c.Bool("restore") {
+		return session, nil
+	}
+	if !progress.IsTerminal(os.Stdin) {
+		return nil, nil
+	}
+
+	where := session.Title
+	if session.Screen == tui.ScreenGame && session.Ply > 0 {
+		where += fmt.Sprintf(" at move %d", (session.Ply+1)/2)
+	}
+	fmt.Printf("Resume your last session (%s, %s)? [Y/n]: ", where, session.SavedAt.Local().Format("2006-01-02 15:04"))
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "", "y", "yes":
+		return session, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/kyleboon/gochess/internal/config"
+	"github.com/kyleboon/gochess/internal/eco"
+	"github.com/kyleboon/gochess/internal/tui"
+	"github.com/urfave/cli/v2"
+)
+
+// setupCommand opens the board editor, from which a position can be
+// analysed, played with a clock or copied as FEN
+func setupCommand(c *cli.Context) error {
+	if err := logToFileForTUI(c); err != nil {
+		return err
+	}
+	cfg, err := config.LoadOrDefault()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	base, increment, err := timeControl(c, cfg)
+	if err != nil {
+		return err
+	}
+
+	keys, err := keyMap(cfg)
+	if err != nil {
+		return err
+	}
+
+	openings, err := eco.NewDatabase()
+	if err != nil {
+		return fmt.Errorf("failed to load ECO database: %w", err)
+	}
+
+	model, err := tui.NewSetupModel(c.String("fen"))
+	if err != nil {
+		return fmt.Errorf("invalid FEN: %w", err)
+	}
+	model = model.
+		WithBoardOptions(boardOptions(cfg), settingsSaver(cfg)).
+		WithGameAdder(lazyGameAdder(c.Context, cfg)).
+		WithTimeControl(base, increment).
+		WithOpenings(openings).
+		WithKeys(keys)
+
+	p := tea.NewProgram(model, tea.WithAltScreen(), tea.WithMouseCellMotion())
+	if _, err := p.Run(); err != nil {
+		return fmt.Errorf("failed to run TUI: %w", err)
+	}
+	return nil
+}
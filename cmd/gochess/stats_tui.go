@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/kyleboon/gochess/internal/config"
 	"github.com/kyleboon/gochess/internal/db"
@@ -11,9 +12,17 @@ import (
 
 // statsTUICommand renders stats with the TUI interface
 func statsTUICommand(c *cli.Context) error {
-	dbPath := expandPath(c.String("database"))
+	if err := logToFileForTUI(c); err != nil {
+		return err
+	}
+	dbPath, err := config.DatabasePath(c)
+	if err != nil {
+		return err
+	}
+	dbPath = expandPath(dbPath)
 	playerFilter := c.StringSlice("player")
 	showAll := c.Bool("all")
+	timeClass := strings.ToLower(c.String("time-class"))
 
 	// Load config to get configured users
 	cfg, err := config.LoadOrDefault()
@@ -22,7 +31,7 @@ func statsTUICommand(c *cli.Context) error {
 	}
 
 	// Open database connection
-	database, err := db.New(dbPath)
+	database, err := db.Open(dbPath, db.FlagOpenMode(c))
 	if err != nil {
 		return fmt.Errorf("failed to open database: %w", err)
 	}
@@ -44,12 +53,8 @@ func statsTUICommand(c *cli.Context) error {
 	if len(playerFilter) > 0 {
 		players = playerFilter
 	} else if !showAll {
-		if cfg.ChessCom != nil && cfg.ChessCom.Username != "" {
-			players = append(players, cfg.ChessCom.Username)
-		}
-		if cfg.Lichess != nil && cfg.Lichess.Username != "" {
-			players = append(players, cfg.Lichess.Username)
-		}
+		players = append(players, cfg.GetUsernames("chesscom")...)
+		players = append(players, cfg.GetUsernames("lichess")...)
 
 		if len(players) == 0 {
 			fmt.Println("No configured users found. Use --all to show all players or configure users with 'gochess config add-user'")
@@ -59,11 +64,7 @@ func statsTUICommand(c *cli.Context) error {
 
 	// Get player statistics
 	var stats []db.PlayerStats
-	if len(players) > 0 {
-		stats, err = database.GetPlayerStatsFiltered(c.Context, players)
-	} else {
-		stats, err = database.GetPlayerStats(c.Context)
-	}
+	stats, err = database.GetPlayerStatsForTimeClass(c.Context, players, timeClass)
 	if err != nil {
 		return fmt.Errorf("failed to get player statistics: %w", err)
 	}
@@ -82,11 +83,7 @@ func statsTUICommand(c *cli.Context) error {
 
 	// Get and display opening statistics
 	var openingStats []db.OpeningStats
-	if len(players) > 0 {
-		openingStats, err = database.GetOpeningStatsFiltered(c.Context, players)
-	} else {
-		openingStats, err = database.GetOpeningStats(c.Context)
-	}
+	openingStats, err = database.GetOpeningStatsForTimeClass(c.Context, players, timeClass)
 
 	if err != nil {
 		fmt.Printf("\nWarning: Failed to get opening statistics: %v\n", err)
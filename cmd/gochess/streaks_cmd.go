@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/kyleboon/gochess/internal/db"
+	"github.com/kyleboon/gochess/internal/output"
+	"github.com/urfave/cli/v2"
+)
+
+// streakMinGames is the minimum number of games after a loss before tilting
+// is called out.
+const streakMinGames = 5
+
+// streakStats prints the winning and losing streaks of the given players,
+// taken as one player, how they did after a win or a loss and how long they
+// play in one session, to show whether they tilt.
+func streakStats(c *cli.Context, database *db.DB, players []string) error {
+	if len(players) == 0 {
+		return fmt.Errorf("--streaks needs the games of a player; use --player")
+	}
+	asJSON := output.JSON(c) || c.String("format") == "json"
+	out := os.Stdout
+	if asJSON {
+		out = os.Stderr
+	}
+	fmt.Fprintln(out, "Calculating streaks...")
+	streaks, err := database.GetStreaks(c.Context, players)
+	if err != nil {
+		return fmt.Errorf("failed to get streaks: %w", err)
+	}
+
+	if asJSON {
+		return output.WriteJSON(streaks)
+	}
+
+	if streaks.Games == 0 {
+		fmt.Println("No finished games found")
+		return nil
+	}
+
+	current := "none"
+	switch {
+	case streaks.CurrentStreak > 0:
+		current = fmt.Sprintf("won %d", streaks.CurrentStreak)
+	case streaks.CurrentStreak < 0:
+		current = fmt.Sprintf("lost %d", -streaks.CurrentStreak)
+	}
+	fmt.Printf("\nStreaks over %d games:\n", streaks.Games)
+	fmt.Printf("  Longest winning streak: %d\n", streaks.LongestWinStreak)
+	fmt.Printf("  Longest losing streak:  %d\n", streaks.LongestLossStreak)
+	fmt.Printf("  Current streak:         %s\n", current)
+
+	fmt.Printf("\nResults, and of the next game in a session after a win or a loss:\n")
+	fmt.Printf("  %-12s %-6s %-12s %-8s\n", "", "GAMES", "W-L-D", "WIN RATE")
+	for _, r := range []struct {
+		label  string
+		counts db.ResultCounts
+	}{
+		{"Overall", streaks.Overall},
+		{"After a win", streaks.AfterWin},
+		{"After a loss", streaks.AfterLoss},
+	} {
+		fmt.Printf("  %-12s %-6d %-12s %.1f%%\n", r.label, r.counts.Games,
+			fmt.Sprintf("%d-%d-%d", r.counts.Wins, r.counts.Losses, r.counts.Draws), r.counts.WinRate)
+	}
+
+	fmt.Printf("\nSessions (games at most an hour apart, or on the same day if untimed):\n")
+	fmt.Printf("  Sessions:          %d\n", streaks.Sessions)
+	fmt.Printf("  Games per session: %.1f\n", streaks.GamesPerSession)
+	fmt.Printf("  Longest session:   %d games\n", streaks.LongestSession)
+
+	if streaks.AfterLoss.Games >= streakMinGames && streaks.AfterLoss.WinRate < streaks.Overall.WinRate-10 {
+		fmt.Printf("\n  You win %.1f%% of games right after a loss against %.1f%% overall: consider a break after losing.\n",
+			streaks.AfterLoss.WinRate, streaks.Overall.WinRate)
+	}
+	return nil
+}
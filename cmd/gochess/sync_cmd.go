@@ -0,0 +1,386 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/kyleboon/gochess/internal/analysis"
+	"github.com/kyleboon/gochess/internal/config"
+	"github.com/kyleboon/gochess/internal/db"
+	"github.com/kyleboon/gochess/internal/logging"
+	"github.com/kyleboon/gochess/internal/notify"
+	"github.com/kyleboon/gochess/internal/output"
+	"github.com/urfave/cli/v2"
+)
+
+// syncStatus is the state of the sync daemon and the outcome of the last
+// sync, kept in the sync status file between runs
+type syncStatus struct {
+	PID        int                 `json:"pid,omitempty"`      // of the running daemon
+	Interval   string              `json:"interval,omitempty"` // between the daemon's syncs
+	LastStart  *time.Time          `json:"last_start,omitempty"`
+	LastFinish *time.Time          `json:"last_finish,omitempty"`
+	Last       *notify.SyncSummary `json:"last,omitempty"`
+	NextRun    *time.Time          `json:"next_run,omitempty"`
+
+	// Set by "sync status" from the running processes
+	DaemonRunning bool `json:"daemon_running"`
+	SyncRunning   bool `json:"sync_running"`
+}
+
+// syncDaemonCommand syncs the configured sources every --interval until
+// interrupted, analyzing the new games with --analyze
+func syncDaemonCommand(c *cli.Context) error {
+	interval := c.Duration("interval")
+	if interval <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+	statusPath, err := config.DefaultSyncStatusPath()
+	if err != nil {
+		return err
+	}
+	status, err := loadSyncStatus(statusPath)
+	if err != nil {
+		return err
+	}
+	if processRunning(status.PID) && status.PID != os.Getpid() {
+		return fmt.Errorf("the sync daemon is already running (pid %d)", status.PID)
+	}
+
+	ctx, stop := signal.NotifyContext(c.Context, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	status.PID, status.Interval = os.Getpid(), interval.String()
+	if err := saveSyncStatus(statusPath, status); err != nil {
+		return err
+	}
+	defer func() {
+		if status, err := loadSyncStatus(statusPath); err == nil {
+			status.PID, status.Interval, status.NextRun = 0, "", nil
+			_ = saveSyncStatus(statusPath, status)
+		}
+	}()
+
+	out := output.Messages(c)
+	fmt.Fprintf(out, "Syncing every %s (Ctrl-C to stop)...\n", interval)
+	for {
+		if err := runSync(ctx, c, out); err != nil {
+			fmt.Fprintf(out, "%s: %v\n", time.Now().Format("2006-01-02 15:04:05"), err)
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		next := time.Now().Add(interval)
+		if status, err := loadSyncStatus(statusPath); err == nil {
+			status.NextRun = &next
+			_ = saveSyncStatus(statusPath, status)
+		}
+		fmt.Fprintf(out, "Next sync at %s\n", next.Format("2006-01-02 15:04:05"))
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+// syncRunCommand runs a single sync, as the daemon does on each interval,
+// for running from cron or by hand
+func syncRunCommand(c *cli.Context) error {
+	ctx, stop := signal.NotifyContext(c.Context, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return runSync(ctx, c, output.Messages(c))
+}
+
+// runSync imports the new games of the configured sources, analyzes them
+// when --analyze is set, records the outcome in the status file and sends
+// it to the configured notification destinations. It fails if another
+// sync holds the lock.
+func runSync(ctx context.Context, c *cli.Context, out io.Writer) error {
+	lockPath, err := config.DefaultSyncLockPath()
+	if err != nil {
+		return err
+	}
+	unlock, err := acquireSyncLock(lockPath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	// Reload the config on every run: imports update the last import times
+	cfg, err := config.LoadOrDefault()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if !cfg.HasAnySource() {
+		return fmt.Errorf("no game sources configured (run 'gochess config init')")
+	}
+	logger := logging.Default()
+	database, err := db.NewWithLogger(expandPath(cfg.DatabasePath), logger)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer func() { _ = database.Close() }()
+
+	start := time.Now()
+	fmt.Fprintf(out, "%s: syncing...\n", start.Format("2006-01-02 15:04:05"))
+	lastID, err := database.GetLastGameID(ctx)
+	if err != nil {
+		return err
+	}
+
+	sources, added, _ := importSources(ctx, cfg, database, logger, out, c.Bool("verbose"))
+	if c.Bool("player-profiles") && added > 0 {
+		fetchOpponentProfiles(ctx, cfg, database, logger, out)
+	}
+	summary := notify.SyncSummary{GamesAdded: added}
+	for _, source := range sources {
+		if source.Error != "" {
+			if summary.Errors == nil {
+				summary.Errors = make(map[string]string)
+			}
+			summary.Errors[source.name()] = source.Error
+		}
+	}
+	if c.Bool("analyze") && added > 0 && ctx.Err() == nil {
+		analyzed, blunders, err := analyzeNewGames(ctx, c, cfg, database, logger, out, lastID)
+		summary.GamesAnalyzed, summary.Blunders = analyzed, blunders
+		if err != nil {
+			if summary.Errors == nil {
+				summary.Errors = make(map[string]string)
+			}
+			summary.Errors["analysis"] = err.Error()
+		}
+	}
+	if summary.TotalGames, err = database.GetGameCount(ctx); err != nil {
+		return err
+	}
+
+	event := notify.NewSyncEvent(summary)
+	fmt.Fprintf(out, "%s: %s\n", time.Now().Format("2006-01-02 15:04:05"), event.Text())
+	if err := recordSync(start, summary); err != nil {
+		fmt.Fprintf(out, "Failed to record the sync status: %v\n", err)
+	}
+	if err := notify.FromConfig(cfg.Notify, logger).Send(ctx, event); err != nil {
+		fmt.Fprintf(out, "Failed to send notification: %v\n", err)
+	}
+	if len(summary.Errors) > 0 {
+		return fmt.Errorf("sync finished with errors")
+	}
+	return nil
+}
+
+// analyzeNewGames reviews the games added after the game with lastID with
+// the engine, saving the analyses, and returns how many were analyzed and
+// the blunders found in them
+func analyzeNewGames(ctx context.Context, c *cli.Context, cfg *config.Config, database *db.DB, logger *slog.Logger, out io.Writer, lastID int) (int, int, error) {
+	ids, err := database.GetGameIDsAfter(ctx, lastID)
+	if err != nil || len(ids) == 0 {
+		return 0, 0, err
+	}
+	eng, err := openEngine(c, cfg, logger)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer func() { _ = eng.Close() }()
+
+	opts := reviewOptions(c, cfg)
+	fmt.Fprintf(out, "Analyzing %d new games at depth %d...\n", len(ids), opts.Depth)
+	analyzed, blunders := 0, 0
+	for _, id := range ids {
+		record, err := database.GetGame(ctx, id)
+		if err != nil {
+			return analyzed, blunders, err
+		}
+		game, err := parseSingleGame(record.PGNText)
+		if err != nil {
+			fmt.Fprintf(out, "Skipping game %d: %v\n", id, err)
+			continue
+		}
+		annotations, err := analysis.New(eng, opts, logger).AnnotateGame(ctx, game)
+		if err != nil {
+			return analyzed, blunders, fmt.Errorf("failed to analyze game %d: %w", id, err)
+		}
+		result, evals := analysis.Record(id, opts, annotations)
+		if err := database.SaveAnalysis(ctx, result, evals); err != nil {
+			return analyzed, blunders, err
+		}
+		analyzed++
+		blunders += result.WhiteBlunders + result.BlackBlunders
+	}
+	return analyzed, blunders, nil
+}
+
+// syncStatusCommand shows whether the sync daemon and a sync are running,
+// the outcome of the last sync and when the next one is due
+func syncStatusCommand(c *cli.Context) error {
+	statusPath, err := config.DefaultSyncStatusPath()
+	if err != nil {
+		return err
+	}
+	lockPath, err := config.DefaultSyncLockPath()
+	if err != nil {
+		return err
+	}
+	status, err := loadSyncStatus(statusPath)
+	if err != nil {
+		return err
+	}
+	status.DaemonRunning = processRunning(status.PID)
+	syncPID := syncLockHolder(lockPath)
+	status.SyncRunning = syncPID != 0
+	if !status.DaemonRunning {
+		status.PID, status.Interval, status.NextRun = 0, "", nil
+	}
+	if output.JSON(c) {
+		return output.WriteJSON(status)
+	}
+
+	if status.DaemonRunning {
+		fmt.Printf("Daemon: running (pid %d, every %s)\n", status.PID, status.Interval)
+	} else {
+		fmt.Println("Daemon: not running")
+	}
+	if status.SyncRunning {
+		fmt.Printf("Sync in progress (pid %d)\n", syncPID)
+	}
+	if status.LastStart == nil || status.LastFinish == nil || status.Last == nil {
+		fmt.Println("Last sync: never")
+	} else {
+		last := status.Last
+		fmt.Printf("Last sync: %s (took %s)\n", status.LastFinish.Format("2006-01-02 15:04:05"),
+			status.LastFinish.Sub(*status.LastStart).Round(time.Second))
+		fmt.Printf("  Games added: %d (%d in the database)\n", last.GamesAdded, last.TotalGames)
+		if last.GamesAnalyzed > 0 {
+			fmt.Printf("  Games analyzed: %d (%d blunders)\n", last.GamesAnalyzed, last.Blunders)
+		}
+		sources := make([]string, 0, len(last.Errors))
+		for source := range last.Errors {
+			sources = append(sources, source)
+		}
+		sort.Strings(sources)
+		for _, source := range sources {
+			fmt.Printf("  %s failed: %s\n", source, last.Errors[source])
+		}
+	}
+	if status.NextRun != nil {
+		fmt.Printf("Next sync: %s\n", status.NextRun.Format("2006-01-02 15:04:05"))
+	}
+	return nil
+}
+
+// recordSync saves the outcome of a sync that started at start to the
+// status file
+func recordSync(start time.Time, summary notify.SyncSummary) error {
+	statusPath, err := config.DefaultSyncStatusPath()
+	if err != nil {
+		return err
+	}
+	status, err := loadSyncStatus(statusPath)
+	if err != nil {
+		return err
+	}
+	finish := time.Now()
+	status.LastStart, status.LastFinish, status.Last = &start, &finish, &summary
+	return saveSyncStatus(statusPath, status)
+}
+
+// loadSyncStatus reads the status file at path, which may not exist yet
+func loadSyncStatus(path string) (*syncStatus, error) {
+	status := &syncStatus{}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return status, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sync status: %w", err)
+	}
+	if err := json.Unmarshal(data, status); err != nil {
+		return nil, fmt.Errorf("failed to parse sync status: %w", err)
+	}
+	return status, nil
+}
+
+// saveSyncStatus writes the status file at path
+func saveSyncStatus(path string, status *syncStatus) error {
+	data, err := json.MarshalIndent(status, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal sync status: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create sync status directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write sync status: %w", err)
+	}
+	return nil
+}
+
+// acquireSyncLock creates the lock file at path, holding the PID of this
+// process, and returns a function removing it. A lock left behind by a
+// process that is no longer running is taken over.
+func acquireSyncLock(path string) (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create lock directory: %w", err)
+	}
+	for attempt := 0; attempt < 2; attempt++ {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			_, err = fmt.Fprintf(f, "%d\n", os.Getpid())
+			if err = errors.Join(err, f.Close()); err != nil {
+				_ = os.Remove(path)
+				return nil, fmt.Errorf("failed to write lock file: %w", err)
+			}
+			return func() { _ = os.Remove(path) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("failed to create lock file: %w", err)
+		}
+		if pid := syncLockHolder(path); pid != 0 {
+			return nil, fmt.Errorf("a sync is already running (pid %d, lock file %s)", pid, path)
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("failed to remove stale lock file: %w", err)
+		}
+	}
+	return nil, fmt.Errorf("failed to acquire lock file %s", path)
+}
+
+// syncLockHolder returns the PID of the running process holding the lock
+// file at path, or 0 if there is none
+func syncLockHolder(path string) int {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || !processRunning(pid) {
+		return 0
+	}
+	return pid
+}
+
+// processRunning reports whether a process with pid is running
+func processRunning(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	return p.Signal(syscall.Signal(0)) == nil
+}
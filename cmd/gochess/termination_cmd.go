@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/kyleboon/gochess/internal/db"
+	"github.com/kyleboon/gochess/internal/output"
+	"github.com/urfave/cli/v2"
+)
+
+// terminationStats prints how the games of the given players (all players if
+// empty) ended, to show how often they lose on time or get mated.
+func terminationStats(c *cli.Context, database *db.DB, players []string, timeClass string) error {
+	asJSON := output.JSON(c) || c.String("format") == "json"
+	out := os.Stdout
+	if asJSON {
+		out = os.Stderr
+	}
+	fmt.Fprintln(out, "Calculating termination statistics...")
+	stats, err := database.GetTerminationStats(c.Context, players, timeClass)
+	if err != nil {
+		return fmt.Errorf("failed to get termination statistics: %w", err)
+	}
+
+	if asJSON {
+		if stats == nil {
+			stats = []db.TerminationStats{}
+		}
+		return output.WriteJSON(map[string]interface{}{
+			"terminations": stats,
+		})
+	}
+
+	if len(stats) == 0 {
+		fmt.Println("No finished games found")
+		return nil
+	}
+
+	if c.String("format") == "csv" {
+		fmt.Println("Termination,Games,Wins,Losses,Draws")
+		for _, s := range stats {
+			fmt.Printf("%s,%d,%d,%d,%d\n", s.Termination, s.Games, s.Wins, s.Losses, s.Draws)
+		}
+		return nil
+	}
+
+	total := 0
+	for _, s := range stats {
+		total += s.Games
+	}
+	perspective := "White's results"
+	if len(players) > 0 {
+		perspective = "your results"
+	}
+	fmt.Printf("\nGames by termination (%s):\n", perspective)
+	fmt.Printf("  %-22s %-6s %-6s %-6s %-6s %-8s\n", "TERMINATION", "GAMES", "WINS", "LOSSES", "DRAWS", "SHARE")
+	fmt.Println("  " + repeatString("-", 60))
+	for _, s := range stats {
+		fmt.Printf("  %-22s %-6d %-6d %-6d %-6d %.1f%%\n", s.Termination, s.Games, s.Wins, s.Losses, s.Draws,
+			float64(s.Games)/float64(total)*100)
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/kyleboon/gochess/internal/db"
+	"github.com/kyleboon/gochess/internal/output"
+	"github.com/urfave/cli/v2"
+)
+
+// timeUsageMinGames is the minimum number of games an opening needs before
+// its clock usage is called out.
+const timeUsageMinGames = 3
+
+// timeUsageStats prints clock usage statistics for the given players (all
+// players if empty).
+func timeUsageStats(c *cli.Context, database *db.DB, players []string) error {
+	asJSON := output.JSON(c) || c.String("format") == "json"
+	out := os.Stdout
+	if asJSON {
+		out = os.Stderr
+	}
+	fmt.Fprintln(out, "Calculating time usage statistics...")
+	overall, byOpening, err := database.GetTimeUsageStatsFiltered(c.Context, players)
+	if err != nil {
+		return fmt.Errorf("failed to get time usage statistics: %w", err)
+	}
+
+	if asJSON {
+		if byOpening == nil {
+			byOpening = []db.TimeUsageStats{}
+		}
+		return output.WriteJSON(map[string]interface{}{
+			"overall":  overall,
+			"openings": byOpening,
+		})
+	}
+
+	if overall.Games == 0 {
+		fmt.Println("No games with clock data found")
+		return nil
+	}
+
+	if c.String("format") == "csv" {
+		fmt.Println("ECO,Opening,Games,AvgMoveSeconds,PeakStart,PeakEnd,PeakShare,TimeTroubleGames")
+		fmt.Printf(",All games,%d,%.1f,%d,%d,%.1f%%,%d\n",
+			overall.Games, overall.AvgMoveSeconds, overall.PeakStart, overall.PeakEnd,
+			overall.PeakShare, overall.TimeTroubleGames)
+		for _, s := range byOpening {
+			fmt.Printf("%s,%q,%d,%.1f,%d,%d,%.1f%%,%d\n",
+				s.ECOCode, s.OpeningName, s.Games, s.AvgMoveSeconds, s.PeakStart, s.PeakEnd,
+				s.PeakShare, s.TimeTroubleGames)
+		}
+		return nil
+	}
+
+	fmt.Printf("\nTime Usage (%d games with clock data):\n", overall.Games)
+	fmt.Printf("  Average think:    %.1fs per move\n", overall.AvgMoveSeconds)
+	fmt.Printf("  Heaviest stretch: moves %d-%d (%.1f%% of the starting clock)\n",
+		overall.PeakStart, overall.PeakEnd, overall.PeakShare)
+	fmt.Printf("  Time trouble:     %d of %d games (%.1f%%)\n",
+		overall.TimeTroubleGames, overall.Games,
+		float64(overall.TimeTroubleGames)/float64(overall.Games)*100)
+
+	if len(byOpening) == 0 {
+		return nil
+	}
+
+	displayCount := 10
+	if len(byOpening) < displayCount {
+		displayCount = len(byOpening)
+	}
+
+	fmt.Printf("\n  By Opening:\n")
+	fmt.Printf("  %-6s %-40s %-6s %-9s %-22s %-6s\n", "ECO", "OPENING", "GAMES", "AVG/MOVE", "HEAVIEST STRETCH", "TROUBLE")
+	fmt.Println("  " + repeatString("-", 95))
+	for _, s := range byOpening[:displayCount] {
+		name := s.OpeningName
+		if len(name) > 40 {
+			name = name[:37] + "..."
+		}
+		stretch := fmt.Sprintf("moves %d-%d (%.0f%%)", s.PeakStart, s.PeakEnd, s.PeakShare)
+		fmt.Printf("  %-6s %-40s %-6d %-9s %-22s %d\n",
+			s.ECOCode, name, s.Games, fmt.Sprintf("%.1fs", s.AvgMoveSeconds), stretch, s.TimeTroubleGames)
+	}
+
+	// Call out the openings that eat the most clock
+	subject := "Players spend %.0f%% of their clock"
+	if len(players) > 0 {
+		subject = "You spend %.0f%% of your clock"
+	}
+	var lines []string
+	for _, s := range byOpening {
+		if s.Games >= timeUsageMinGames && s.PeakShare >= overall.PeakShare {
+			lines = append(lines, fmt.Sprintf("  "+subject+" on moves %d-%d in the %s.",
+				s.PeakShare, s.PeakStart, s.PeakEnd, s.OpeningName))
+		}
+		if len(lines) == 3 {
+			break
+		}
+	}
+	if len(lines) > 0 {
+		fmt.Printf("\n  Insights:\n")
+		for _, line := range lines {
+			fmt.Println(line)
+		}
+	}
+
+	return nil
+}
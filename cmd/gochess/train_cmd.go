@@ -0,0 +1,420 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/kyleboon/gochess/internal"
+	"github.com/kyleboon/gochess/internal/analysis"
+	"github.com/kyleboon/gochess/internal/config"
+	"github.com/kyleboon/gochess/internal/db"
+	"github.com/kyleboon/gochess/internal/engine"
+	"github.com/kyleboon/gochess/internal/logging"
+	"github.com/kyleboon/gochess/internal/output"
+	"github.com/kyleboon/gochess/internal/pgn"
+	"github.com/kyleboon/gochess/internal/srs"
+	"github.com/kyleboon/gochess/internal/tui"
+	"github.com/urfave/cli/v2"
+)
+
+// Questions asked per session of the training games, unless --rounds says
+// otherwise
+const (
+	defaultSquareColorRounds = 20
+	defaultKnightPathRounds  = 10
+)
+
+// trainBlindfoldCommand plays a game against the engine without showing the
+// pieces
+func trainBlindfoldCommand(c *cli.Context) error {
+	color := internal.White
+	switch strings.ToLower(c.String("color")) {
+	case "white":
+	case "black":
+		color = internal.Black
+	default:
+		return fmt.Errorf("invalid color %q: use white or black", c.String("color"))
+	}
+	if err := logToFileForTUI(c); err != nil {
+		return err
+	}
+	cfg, err := config.LoadOrDefault()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	eng, err := openPlayingEngine(c, cfg, logging.Default())
+	if err != nil {
+		return err
+	}
+	defer func() { _ = eng.Close() }()
+
+	moveTime := c.Duration("move-time")
+	opponent := func(fen string) (string, error) {
+		result, err := eng.Analyze(context.Background(), fen, engine.AnalysisOptions{MoveTime: moveTime})
+		if err != nil {
+			return "", err
+		}
+		if len(result.Lines) == 0 || len(result.Lines[0].Moves) == 0 {
+			return "", fmt.Errorf("no move found")
+		}
+		return result.Lines[0].Moves[0], nil
+	}
+	return runTraining(c, cfg, tui.TrainingBlindfold, 1, func(m tui.TrainModel) tui.TrainModel {
+		return m.WithOpponent(opponent, color)
+	})
+}
+
+// trainSquareColorsCommand asks for the color of squares named at random
+func trainSquareColorsCommand(c *cli.Context) error {
+	if err := logToFileForTUI(c); err != nil {
+		return err
+	}
+	cfg, err := config.LoadOrDefault()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	return runTraining(c, cfg, tui.TrainingSquareColors, c.Int("rounds"), nil)
+}
+
+// trainKnightPathsCommand asks for the shortest paths of a knight between
+// two squares
+func trainKnightPathsCommand(c *cli.Context) error {
+	if err := logToFileForTUI(c); err != nil {
+		return err
+	}
+	cfg, err := config.LoadOrDefault()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	return runTraining(c, cfg, tui.TrainingKnightPaths, c.Int("rounds"), nil)
+}
+
+// trainGuessCommand replays a game of the database, asking for each move of
+// one side to be guessed and scoring the guesses against the game moves and
+// the engine's evaluation
+func trainGuessCommand(c *cli.Context) error {
+	if err := logToFileForTUI(c); err != nil {
+		return err
+	}
+	cfg, err := config.LoadOrDefault()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	database, err := openDatabase(c)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = database.Close() }()
+
+	player := c.String("player")
+	gameID := c.Int("id")
+	if gameID == 0 {
+		if gameID, err = database.RandomGameID(c.Context, player); err != nil {
+			return err
+		}
+	}
+	record, err := database.GetGame(c.Context, gameID)
+	if err != nil {
+		return fmt.Errorf("failed to get game: %w", err)
+	}
+	game, err := parseSingleGame(record.PGNText)
+	if err != nil {
+		return err
+	}
+
+	// The side guessed defaults to the player's, then to White
+	color := internal.White
+	switch strings.ToLower(c.String("color")) {
+	case "white":
+	case "black":
+		color = internal.Black
+	case "":
+		name := strings.ToLower(player)
+		if name != "" && !strings.Contains(strings.ToLower(record.White), name) &&
+			strings.Contains(strings.ToLower(record.Black), name) {
+			color = internal.Black
+		}
+	default:
+		return fmt.Errorf("invalid color %q: use white or black", c.String("color"))
+	}
+
+	model, err := tui.NewGuessModel(game, color, c.Int("from-move"))
+	if err != nil {
+		return err
+	}
+	model = model.WithBoardOptions(boardOptions(cfg)).WithScoreSaver(func(r tui.TrainingResult) error {
+		return database.SaveTrainingScore(context.Background(), db.TrainingScore{
+			Mode: string(r.Mode), Score: r.Score, Total: r.Total, Duration: r.Duration,
+		})
+	})
+	model = model.WithReviewScheduler(func(fen, answer string) error {
+		item := db.ReviewItem{Kind: db.ReviewGuessMove, Source: fmt.Sprintf("game %d", gameID), FEN: fen, Answers: []string{answer}}
+		_, err := database.AddReviewItem(context.Background(), item, time.Now())
+		return err
+	})
+	if !c.Bool("no-engine") {
+		eng, err := openPlayingEngine(c, cfg, logging.Default())
+		if err != nil {
+			return err
+		}
+		defer func() { _ = eng.Close() }()
+		model = model.WithEvaluator(positionEvaluator(eng, c.Duration("move-time")))
+	}
+
+	p := tea.NewProgram(model, tea.WithAltScreen())
+	if _, err := p.Run(); err != nil {
+		return fmt.Errorf("failed to run TUI: %w", err)
+	}
+	return nil
+}
+
+// positionEvaluator evaluates positions with the engine, in pawns from
+// White's point of view. Mates and stalemates are scored without it
+func positionEvaluator(eng engine.Analyzer, moveTime time.Duration) tui.EvalFunc {
+	return func(fen string) (float64, error) {
+		board, err := internal.ParseFen(fen)
+		if err != nil {
+			return 0, err
+		}
+		if len(board.LegalMoves()) == 0 {
+			if check, _ := board.IsCheckOrMate(); !check {
+				return 0, nil
+			}
+			if board.SideToMove == internal.White {
+				return -pgn.MateEval, nil
+			}
+			return pgn.MateEval, nil
+		}
+		result, err := eng.Analyze(context.Background(), fen, engine.AnalysisOptions{MoveTime: moveTime})
+		if err != nil {
+			return 0, err
+		}
+		if len(result.Lines) == 0 {
+			return 0, fmt.Errorf("no evaluation found")
+		}
+		return analysis.Pawns(analysis.Centipawns(result.Lines[0].Score)), nil
+	}
+}
+
+// runTraining plays sessions of a training game in the TUI, storing the
+// score of each finished one in the database
+func runTraining(c *cli.Context, cfg *config.Config, mode tui.TrainingMode, rounds int, setup func(tui.TrainModel) tui.TrainModel) error {
+	database, err := openDatabase(c)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = database.Close() }()
+
+	model, err := tui.NewTrainModel(mode, rounds)
+	if err != nil {
+		return err
+	}
+	model = model.WithBoardOptions(boardOptions(cfg)).WithScoreSaver(func(r tui.TrainingResult) error {
+		return database.SaveTrainingScore(context.Background(), db.TrainingScore{
+			Mode: string(r.Mode), Score: r.Score, Total: r.Total, Duration: r.Duration,
+		})
+	})
+	if setup != nil {
+		model = setup(model)
+	}
+
+	p := tea.NewProgram(model, tea.WithAltScreen())
+	if _, err := p.Run(); err != nil {
+		return fmt.Errorf("failed to run TUI: %w", err)
+	}
+	return nil
+}
+
+// trainScoresCommand lists the scores of the training games: a summary of
+// each, or the latest sessions of one with --mode
+func trainScoresCommand(c *cli.Context) error {
+	mode := c.String("mode")
+	if mode != "" {
+		known := false
+		for _, m := range tui.TrainingModes {
+			known = known || string(m) == mode
+		}
+		if !known {
+			return fmt.Errorf("unknown training mode %q", mode)
+		}
+	}
+	database, err := openDatabase(c)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = database.Close() }()
+
+	if mode == "" {
+		summaries, err := database.TrainingSummaries(c.Context)
+		if err != nil {
+			return err
+		}
+		if output.JSON(c) {
+			if summaries == nil {
+				summaries = []db.TrainingSummary{}
+			}
+			return output.WriteJSON(summaries)
+		}
+		if len(summaries) == 0 {
+			fmt.Println("No training sessions yet. Start one with 'gochess train blindfold', 'square-colors', 'knight-paths' or 'guess'.")
+			return nil
+		}
+		fmt.Printf("%-14s %-9s %-6s %-9s %s\n", "MODE", "SESSIONS", "BEST", "ACCURACY", "LAST PLAYED")
+		for _, s := range summaries {
+			fmt.Printf("%-14s %-9d %-6d %-9s %s\n", s.Mode, s.Sessions, s.Best, fmt.Sprintf("%.1f%%", s.Accuracy), s.Last)
+		}
+		return nil
+	}
+
+	scores, err := database.TrainingScores(c.Context, mode, c.Int("limit"))
+	if err != nil {
+		return err
+	}
+	if output.JSON(c) {
+		if scores == nil {
+			scores = []db.TrainingScore{}
+		}
+		return output.WriteJSON(scores)
+	}
+	if len(scores) == 0 {
+		fmt.Printf("No %s sessions yet\n", mode)
+		return nil
+	}
+	fmt.Printf("%-20s %-8s %s\n", "PLAYED", "SCORE", "TIME")
+	for _, s := range scores {
+		fmt.Printf("%-20s %-8s %s\n", s.PlayedAt, fmt.Sprintf("%d/%d", s.Score, s.Total), s.Duration.Round(time.Second))
+	}
+	return nil
+}
+
+// trainReviewCommand drills the positions due for spaced repetition that
+// the trainers scheduled
+func trainReviewCommand(c *cli.Context) error {
+	if err := logToFileForTUI(c); err != nil {
+		return err
+	}
+	database, err := openDatabase(c)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = database.Close() }()
+	return runReview(c, database, c.String("kind"), "")
+}
+
+// trainRepertoireCommand drills the moves of a repertoire with spaced
+// repetition: its positions are scheduled the first time, and those due are
+// reviewed
+func trainRepertoireCommand(c *cli.Context) error {
+	if err := logToFileForTUI(c); err != nil {
+		return err
+	}
+	database, err := openDatabase(c)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = database.Close() }()
+
+	name := c.String("name")
+	if _, err := database.ScheduleRepertoire(c.Context, name, time.Now()); err != nil {
+		return err
+	}
+	return runReview(c, database, db.ReviewRepertoire, name)
+}
+
+// runReview reviews the items of kind, and of source when given, due now,
+// recording the grade of each
+func runReview(c *cli.Context, database *db.DB, kind, source string) error {
+	cfg, err := config.LoadOrDefault()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	items, err := database.DueReviewItems(c.Context, kind, time.Now(), 0)
+	if err != nil {
+		return err
+	}
+	var cards []tui.ReviewCard
+	for _, item := range items {
+		if source != "" && item.Source != source {
+			continue
+		}
+		cards = append(cards, tui.ReviewCard{ID: item.ID, Kind: item.Kind, Source: item.Source, FEN: item.FEN, Answers: item.Answers})
+		if limit := c.Int("limit"); limit > 0 && len(cards) == limit {
+			break
+		}
+	}
+	if len(cards) == 0 {
+		fmt.Println("Nothing is due for review. See what comes next with 'gochess train due'.")
+		return nil
+	}
+
+	model, err := tui.NewReviewModel(cards)
+	if err != nil {
+		return err
+	}
+	model = model.WithBoardOptions(boardOptions(cfg)).WithGrader(func(id int, q srs.Quality) error {
+		_, err := database.RecordReview(context.Background(), id, q, time.Now())
+		return err
+	})
+	p := tea.NewProgram(model, tea.WithAltScreen())
+	if _, err := p.Run(); err != nil {
+		return fmt.Errorf("failed to run TUI: %w", err)
+	}
+	return nil
+}
+
+// trainDueCommand shows what is due for review today: the number of items
+// of each trainer, and the items
+func trainDueCommand(c *cli.Context) error {
+	database, err := openDatabase(c)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = database.Close() }()
+
+	now := time.Now()
+	endOfDay := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, now.Location()).Add(-time.Second)
+	summaries, err := database.ReviewSummaries(c.Context, endOfDay)
+	if err != nil {
+		return err
+	}
+	items, err := database.DueReviewItems(c.Context, c.String("kind"), endOfDay, c.Int("limit"))
+	if err != nil {
+		return err
+	}
+
+	if output.JSON(c) {
+		if summaries == nil {
+			summaries = []db.ReviewSummary{}
+		}
+		if items == nil {
+			items = []db.ReviewItem{}
+		}
+		return output.WriteJSON(map[string]interface{}{"summaries": summaries, "due": items})
+	}
+	if len(summaries) == 0 {
+		fmt.Println("Nothing is scheduled for review yet: 'gochess train guess' schedules the moves you miss, and 'gochess train repertoire' the positions of a repertoire.")
+		return nil
+	}
+	fmt.Printf("%-16s %-6s %-10s %s\n", "TRAINER", "ITEMS", "DUE TODAY", "NEXT DUE")
+	for _, s := range summaries {
+		fmt.Printf("%-16s %-6d %-10d %s\n", s.Kind, s.Items, s.Due, s.Next.Local().Format("2006-01-02 15:04"))
+	}
+	if len(items) == 0 {
+		fmt.Println("\nNothing is due today")
+		return nil
+	}
+	fmt.Println()
+	fmt.Printf("%-16s %-20s %-8s %s\n", "TRAINER", "SOURCE", "TO MOVE", "DUE")
+	for _, item := range items {
+		side := "White"
+		if fields := strings.Fields(item.FEN); len(fields) > 1 && fields[1] == "b" {
+			side = "Black"
+		}
+		fmt.Printf("%-16s %-20s %-8s %s\n", item.Kind, item.Source, side, item.Due.Local().Format("2006-01-02 15:04"))
+	}
+	fmt.Println("\nReview them with 'gochess train review'")
+	return nil
+}
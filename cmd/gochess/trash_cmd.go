@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/kyleboon/gochess/internal/db"
+	"github.com/kyleboon/gochess/internal/output"
+	"github.com/urfave/cli/v2"
+)
+
+// dbDeleteCommand moves games to the trash, from where db restore brings
+// them back
+func dbDeleteCommand(c *cli.Context) error {
+	database, err := openDatabase(c)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = database.Close() }()
+
+	for _, id := range c.IntSlice("id") {
+		if err := database.DeleteGame(c.Context, id); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Moved game #%d to the trash; bring it back with 'gochess db restore --id %d'\n", id, id)
+	}
+	return nil
+}
+
+// dbRestoreCommand takes games out of the trash
+func dbRestoreCommand(c *cli.Context) error {
+	database, err := openDatabase(c)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = database.Close() }()
+
+	for _, id := range c.IntSlice("id") {
+		if err := database.RestoreGame(c.Context, id); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Restored game #%d\n", id)
+	}
+	return nil
+}
+
+// dbTrashListCommand lists the games in the trash
+func dbTrashListCommand(c *cli.Context) error {
+	database, err := openDatabase(c)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = database.Close() }()
+
+	games, err := database.TrashedGames(c.Context)
+	if err != nil {
+		return err
+	}
+	if output.JSON(c) {
+		if games == nil {
+			games = []db.TrashedGame{}
+		}
+		return output.WriteJSON(map[string]interface{}{"games": games})
+	}
+	if len(games) == 0 {
+		fmt.Println("The trash is empty")
+		return nil
+	}
+
+	fmt.Printf("%-6s %-16s %-10s %-20s %-20s %-7s %s\n", "ID", "DELETED", "DATE", "WHITE", "BLACK", "RESULT", "EVENT")
+	for _, g := range games {
+		fmt.Printf("%-6d %-16s %-10s %-20s %-20s %-7s %s\n",
+			g.ID, g.DeletedAt.Local().Format("2006-01-02 15:04"), g.Date,
+			truncateName(g.White, 20), truncateName(g.Black, 20), g.Result, truncateName(g.Event, 32))
+	}
+	fmt.Println("\nRestore a game with 'gochess db restore --id <id>', or remove them for good with 'gochess db trash purge'")
+	return nil
+}
+
+// dbTrashPurgeCommand removes games in the trash for good, asking first
+// unless --force is given
+func dbTrashPurgeCommand(c *cli.Context) error {
+	database, err := openDatabase(c)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = database.Close() }()
+
+	ids := c.IntSlice("id")
+	if !c.Bool("force") {
+		count := len(ids)
+		if count == 0 {
+			games, err := database.TrashedGames(c.Context)
+			if err != nil {
+				return err
+			}
+			if count = len(games); count == 0 {
+				fmt.Println("The trash is empty")
+				return nil
+			}
+		}
+		fmt.Printf("WARNING: This will permanently delete %d games from the trash, with their analyses and notes\n", count)
+		fmt.Print("Are you sure you want to continue? [y/N]: ")
+
+		var response string
+		_, _ = fmt.Scanln(&response)
+		if response != "y" && response != "Y" {
+			fmt.Println("Operation cancelled")
+			return nil
+		}
+	}
+
+	purged, err := database.PurgeTrash(c.Context, ids...)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Permanently deleted %d games\n", purged)
+	return nil
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/kyleboon/gochess/internal/config"
+	"github.com/kyleboon/gochess/internal/db"
+	"github.com/kyleboon/gochess/internal/output"
+	"github.com/kyleboon/gochess/internal/tui"
+	"github.com/urfave/cli/v2"
+)
+
+// dbTreeCommand writes a player's opening tree, with the number of games
+// and the player's score in each branch, as a Graphviz or Mermaid graph
+func dbTreeCommand(c *cli.Context) error {
+	format := strings.ToLower(c.String("format"))
+	if format != "dot" && format != "mermaid" && !output.JSON(c) {
+		return fmt.Errorf("unsupported format %q (use dot or mermaid)", format)
+	}
+	depth := c.Int("depth")
+	if depth <= 0 {
+		return fmt.Errorf("--depth must be positive")
+	}
+
+	database, err := openDatabase(c)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = database.Close() }()
+
+	player := c.String("player")
+	root, err := database.GetOpeningTree(c.Context, player, strings.ToLower(c.String("color")), depth, c.Int("min-games"))
+	if err != nil {
+		return err
+	}
+	if output.JSON(c) {
+		return output.WriteJSON(root)
+	}
+	if root.Games == 0 {
+		return fmt.Errorf("no finished games of %s found", player)
+	}
+
+	var out io.Writer = os.Stdout
+	if outPath := c.String("output"); outPath != "" && outPath != "-" {
+		f, err := os.Create(expandPath(outPath))
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer func() { _ = f.Close() }()
+		out = f
+	}
+
+	var text string
+	if format == "dot" {
+		text = treeDot(player, root)
+	} else {
+		text = treeMermaid(root)
+	}
+	if _, err := io.WriteString(out, text); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	return nil
+}
+
+// treeDot returns the tree of root as a Graphviz digraph. Nodes are shaded
+// from red to green by the player's score and edges are drawn thicker the
+// more often a move was played
+func treeDot(player string, root *db.TreeNode) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph %q {\n", player+" openings")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box, style=\"rounded,filled\", fontname=\"Helvetica\"];\n")
+	fmt.Fprintf(&b, "  n0 [label=%q, fillcolor=\"white\"];\n", fmt.Sprintf("%s\n%s", player, treeStats(root)))
+
+	id := 0
+	var walk func(parent int, n *db.TreeNode)
+	walk = func(parent int, n *db.TreeNode) {
+		for _, child := range n.Children {
+			id++
+			fmt.Fprintf(&b, "  n%d [label=%q, fillcolor=\"%.3f 0.35 1.0\"];\n",
+				id, fmt.Sprintf("%s\n%s", tui.TreeMove(child), treeStats(child)), child.Score()/3)
+			fmt.Fprintf(&b, "  n%d -> n%d [penwidth=%.1f];\n",
+				parent, id, 1+4*float64(child.Games)/float64(root.Games))
+			walk(id, child)
+		}
+	}
+	walk(0, root)
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// treeMermaid returns the tree of root as a Mermaid flowchart, with nodes
+// classed as good, even or bad by the player's score
+func treeMermaid(root *db.TreeNode) string {
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+	fmt.Fprintf(&b, "  n0[\"Start<br/>%s\"]\n", treeStats(root))
+
+	id := 0
+	var walk func(parent int, n *db.TreeNode)
+	walk = func(parent int, n *db.TreeNode) {
+		for _, child := range n.Children {
+			id++
+			class := "even"
+			switch score := child.Score(); {
+			case score >= 0.6:
+				class = "good"
+			case score <= 0.4:
+				class = "bad"
+			}
+			fmt.Fprintf(&b, "  n%d --> n%d[\"%s<br/>%s\"]:::%s\n",
+				parent, id, tui.TreeMove(child), treeStats(child), class)
+			walk(id, child)
+		}
+	}
+	walk(0, root)
+	b.WriteString("  classDef good fill:#c8f7c5,stroke:#2e7d32\n")
+	b.WriteString("  classDef even fill:#fff3c4,stroke:#b38f00\n")
+	b.WriteString("  classDef bad fill:#f7c5c5,stroke:#c62828\n")
+	return b.String()
+}
+
+// treeStats returns the number of games of n and the player's score in
+// them, as in "12 games, 58%"
+func treeStats(n *db.TreeNode) string {
+	games := "games"
+	if n.Games == 1 {
+		games = "game"
+	}
+	return fmt.Sprintf("%d %s, %.0f%%", n.Games, games, n.Score()*100)
+}
+
+// openingTreeStats shows the results of the given players (all players, from
+// White's view, if empty) as an opening tree: indented text, JSON, or a TUI
+// to expand and collapse its moves
+func openingTreeStats(c *cli.Context, database *db.DB, players []string, timeClass, format string) error {
+	depth := c.Int("depth")
+	if depth <= 0 {
+		return fmt.Errorf("--depth must be positive")
+	}
+	root, err := database.GetOpeningTreeFiltered(c.Context, players, "", timeClass, depth, c.Int("min-games"))
+	if err != nil {
+		return fmt.Errorf("failed to get opening tree: %w", err)
+	}
+
+	switch format {
+	case "json":
+		return output.WriteJSON(root)
+	case "tui":
+		if err := logToFileForTUI(c); err != nil {
+			return err
+		}
+		cfg, err := config.LoadOrDefault()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		keys, err := keyMap(cfg)
+		if err != nil {
+			return err
+		}
+		title := "Opening Tree"
+		if len(players) > 0 {
+			title += " for " + strings.Join(players, ", ")
+		}
+		p := tea.NewProgram(tui.NewOpeningTreeModel(title, root, keys.Tree), tea.WithAltScreen())
+		if _, err := p.Run(); err != nil {
+			return fmt.Errorf("failed to run TUI: %w", err)
+		}
+		return nil
+	}
+
+	if root.Games == 0 {
+		fmt.Println("No finished games found")
+		return nil
+	}
+	perspective := "White's results"
+	if len(players) > 0 {
+		perspective = "your results"
+	}
+	fmt.Printf("\nOpening tree (%s, %d plies):\n", perspective, depth)
+	fmt.Printf("  Start: %s\n", treeStats(root))
+	var walk func(n *db.TreeNode, indent string)
+	walk = func(n *db.TreeNode, indent string) {
+		for _, child := range n.Children {
+			fmt.Printf("  %-24s %s\n", indent+tui.TreeMove(child), treeStats(child))
+			walk(child, indent+"  ")
+		}
+	}
+	walk(root, "")
+	return nil
+}
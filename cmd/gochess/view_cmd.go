@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/kyleboon/gochess/internal"
+	"github.com/kyleboon/gochess/internal/pgn"
+	"github.com/kyleboon/gochess/internal/tui"
+	"github.com/urfave/cli/v2"
+)
+
+// commentCommand matches embedded commands such as "[%clk 0:05:00]", which
+// are left out of the comments shown
+var commentCommand = regexp.MustCompile(`\[%[^\]]*\]`)
+
+// dbViewCommand steps through a game of the database in the terminal without
+// the TUI: the board is printed after each move, with its comment and
+// evaluation, and enter shows the next move. When stdin is not interactive
+// the whole game is printed
+func dbViewCommand(c *cli.Context) error {
+	database, err := openDatabase(c)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = database.Close() }()
+
+	gameID := c.Int("id")
+	record, err := database.GetGame(c.Context, gameID)
+	if err != nil {
+		return fmt.Errorf("failed to get game: %w", err)
+	}
+	game, err := parseSingleGame(record.PGNText)
+	if err != nil {
+		return err
+	}
+
+	// Evaluations saved by "gochess analyze" take precedence over the
+	// [%eval] comments of Lichess games
+	var mainline []*pgn.Node
+	evals := make(map[int]float64)
+	for n := game.Root; n != nil; n = n.Next {
+		if v, ok := n.Eval(); ok {
+			evals[len(mainline)] = v
+		}
+		mainline = append(mainline, n)
+	}
+	positions, err := database.GetPositionsForGame(c.Context, gameID)
+	if err != nil {
+		return err
+	}
+	for _, pos := range positions {
+		if pos.Evaluation != nil {
+			evals[pos.MoveNumber] = *pos.Evaluation
+		}
+	}
+
+	flip, unicode := c.Bool("flip"), c.Bool("unicode")
+	fmt.Printf("%s - %s  %s\n", game.Tags["White"], game.Tags["Black"], game.Tags["Result"])
+	if event := game.Tags["Event"]; event != "" {
+		fmt.Printf("%s, %s\n", event, game.Tags["Date"])
+	}
+	fmt.Println()
+
+	input := bufio.NewReader(os.Stdin)
+	interactive := true
+	for ply := 0; ply < len(mainline); {
+		n := mainline[ply]
+		if ply == 0 {
+			fmt.Println("Starting position")
+		} else {
+			fmt.Println(numberedMove(n))
+		}
+		if v, ok := evals[ply]; ok {
+			fmt.Printf("Eval: %s\n", tui.FormatEval(v))
+		}
+		if comment := viewComment(n); comment != "" {
+			fmt.Printf("{%s}\n", comment)
+		}
+		fmt.Println()
+		fmt.Print(diagram(n.Board, unicode, flip))
+		fmt.Println()
+
+		if ply == len(mainline)-1 {
+			break
+		}
+		if !interactive {
+			ply++
+			continue
+		}
+		fmt.Printf("[%d/%d] enter: next, b: back, q: quit > ", ply, len(mainline)-1)
+		line, err := input.ReadString('\n')
+		if err == io.EOF {
+			// Nothing more to read, so show the rest of the game
+			fmt.Println()
+			interactive = false
+			ply++
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read input: %w", err)
+		}
+		switch strings.TrimSpace(strings.ToLower(line)) {
+		case "q":
+			return nil
+		case "b":
+			if ply > 0 {
+				ply--
+			}
+		default:
+			ply++
+		}
+		fmt.Println()
+	}
+
+	fmt.Printf("%s - %s  %s\n", game.Tags["White"], game.Tags["Black"], game.Tags["Result"])
+	return nil
+}
+
+// numberedMove returns the move of n with its move number and annotations,
+// as in "12. Nf3!" or "12... Nc6"
+func numberedMove(n *pgn.Node) string {
+	before := n.Parent.Board
+	dots := "."
+	if before.SideToMove == internal.Black {
+		dots = "..."
+	}
+	san := n.Move.San(before)
+	for _, nag := range n.Nags {
+		if s := nag.String(); !strings.HasPrefix(s, "$") {
+			san += s
+		}
+	}
+	return fmt.Sprintf("%d%s %s", before.MoveNr, dots, san)
+}
+
+// viewComment returns the comments of n without embedded commands
+func viewComment(n *pgn.Node) string {
+	var parts []string
+	for _, c := range n.Comment {
+		if c = strings.TrimSpace(commentCommand.ReplaceAllString(c, "")); c != "" {
+			parts = append(parts, c)
+		}
+	}
+	return strings.Join(parts, " ")
+}
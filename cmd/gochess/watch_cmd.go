@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/kyleboon/gochess/internal/chesscom"
+	"github.com/kyleboon/gochess/internal/config"
+	"github.com/kyleboon/gochess/internal/db"
+	"github.com/kyleboon/gochess/internal/logging"
+	"github.com/urfave/cli/v2"
+)
+
+// chesscomWatchCommand follows a Chess.com player's games: the moves of
+// daily games in progress are printed as they are played, and games that
+// finish are imported into the database
+func chesscomWatchCommand(c *cli.Context) error {
+	username, err := config.Username(c, "chesscom")
+	if err != nil {
+		return err
+	}
+	interval := c.Duration("interval")
+	if interval <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+	showBoard, unicode := c.Bool("board"), c.Bool("unicode")
+
+	ctx, stop := signal.NotifyContext(c.Context, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var addGame func(pgnText string) error
+	if !c.Bool("no-import") {
+		dbPath, err := config.DatabasePath(c)
+		if err != nil {
+			return err
+		}
+		database, err := db.NewWithLogger(expandPath(dbPath), logging.Default())
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		defer func() { _ = database.Close() }()
+		addGame = gameAdder(ctx, database)
+	}
+
+	client := chesscom.NewClientWithLogger(logging.Default())
+	watcher := chesscom.NewWatcher(client, username)
+
+	fmt.Printf("Watching the Chess.com games of %s, polling every %s (Ctrl-C to stop)...\n", username, interval)
+
+	onUpdate := func(update *chesscom.WatchUpdate) {
+		for _, g := range update.Games {
+			players := fmt.Sprintf("%s - %s", g.Game.WhiteUsername(), g.Game.BlackUsername())
+			if g.New {
+				side := [2]string{"White", "Black"}[g.Board.SideToMove]
+				fmt.Printf("Following %s (%s, %s): %d moves played, %s to move\n",
+					players, g.Game.TimeClass, g.Game.URL, g.FirstPly-1, side)
+			} else {
+				fmt.Printf("%s: %s\n", players, numberedMoves(g.FirstPly, g.Moves))
+			}
+			if showBoard {
+				flip := strings.EqualFold(g.Game.BlackUsername(), username)
+				fmt.Print(diagram(g.Board, unicode, flip))
+				fmt.Println()
+			}
+		}
+
+		for _, g := range update.Finished {
+			fmt.Printf("Game over: %s - %s %s (%s, %s)\n",
+				g.White.Username, g.Black.Username, g.Result(), g.TimeClass, g.URL)
+			if addGame == nil {
+				continue
+			}
+			if err := addGame(g.PGN); err != nil {
+				fmt.Printf("  Not imported: %v\n", err)
+			} else {
+				fmt.Println("  Imported into the database")
+			}
+		}
+	}
+	onError := func(err error) {
+		fmt.Fprintf(os.Stderr, "Error polling Chess.com: %v\n", err)
+	}
+
+	return watcher.Run(ctx, interval, onUpdate, onError)
+}
+
+// numberedMoves formats moves starting at ply firstPly with move numbers, as
+// in "12. Nf3 Nc6" or "12... Nc6 13. d4"
+func numberedMoves(firstPly int, moves []string) string {
+	var b strings.Builder
+	for i, san := range moves {
+		ply := firstPly + i
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		switch {
+		case ply%2 == 1:
+			fmt.Fprintf(&b, "%d. ", (ply+1)/2)
+		case i == 0:
+			fmt.Fprintf(&b, "%d... ", ply/2)
+		}
+		b.WriteString(san)
+	}
+	return b.String()
+}
@@ -0,0 +1,388 @@
+// Package analysis reviews chess games with an engine, classifying each move
+// and annotating the game tree with NAGs.
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/kyleboon/gochess/internal"
+	"github.com/kyleboon/gochess/internal/engine"
+	"github.com/kyleboon/gochess/internal/pgn"
+)
+
+// mateScore is the centipawn value used for a forced mate. Shorter mates are
+// worth more: mate in N scores mateScore-N.
+const mateScore = 10000
+
+// maxMateDistance bounds the mates told apart from ordinary scores by Pawns.
+const maxMateDistance = 1000
+
+// sacrificeTolerance is the largest eval drop, in centipawns, for which a
+// material sacrifice still counts as "maintaining" the evaluation.
+const sacrificeTolerance = 20
+
+// Classification describes the quality of a move.
+type Classification int
+
+const (
+	Normal Classification = iota
+	Inaccuracy
+	Mistake
+	Blunder
+	Great     // a sound sacrifice
+	Brilliant // a sound sacrifice that is also the only good move
+)
+
+// String returns the lowercase name of the classification.
+func (c Classification) String() string {
+	switch c {
+	case Inaccuracy:
+		return "inaccuracy"
+	case Mistake:
+		return "mistake"
+	case Blunder:
+		return "blunder"
+	case Great:
+		return "great"
+	case Brilliant:
+		return "brilliant"
+	}
+	return "normal"
+}
+
+// Nag returns the NAG used to annotate a move of this classification, or 0
+// for normal moves.
+func (c Classification) Nag() pgn.Nag {
+	switch c {
+	case Inaccuracy:
+		return 6 // ?!
+	case Mistake:
+		return 2 // ?
+	case Blunder:
+		return 4 // ??
+	case Great:
+		return 1 // !
+	case Brilliant:
+		return 3 // !!
+	}
+	return 0
+}
+
+// Options configures the annotator.
+type Options struct {
+	Depth   int // engine search depth per position
+	MultiPV int // lines per position; at least 2 is needed to detect brilliant moves
+	Rating  int // players' rating for win probabilities; 0 takes it from the Elo tags
+
+	// Eval-loss thresholds in centipawns for the punitive classifications.
+	InaccuracyThreshold int
+	MistakeThreshold    int
+	BlunderThreshold    int
+}
+
+// DefaultOptions returns the default annotator options.
+func DefaultOptions() Options {
+	return Options{
+		Depth:               16,
+		MultiPV:             2,
+		InaccuracyThreshold: 50,
+		MistakeThreshold:    100,
+		BlunderThreshold:    200,
+	}
+}
+
+// MoveAnnotation is the annotator's verdict on a single move.
+type MoveAnnotation struct {
+	Ply            int     // 1-based half-move number
+	Color          int     // internal.White or internal.Black
+	San            string  // the move played
+	Best           string  // the engine's preferred move (SAN), empty if unknown
+	EvalBefore     int     // centipawns from White's perspective before the move
+	EvalAfter      int     // centipawns from White's perspective after the move
+	Loss           int     // centipawns lost by the mover (never negative)
+	WinBefore      float64 // White's win probability (0-1) before the move
+	WinAfter       float64 // White's win probability (0-1) after the move
+	SEE            int     // static exchange evaluation of the move
+	Classification Classification
+	Motifs         []string // tactical motifs of the move, or of the reply punishing a mistake
+
+	// Clock data, available when the game has %clk comments.
+	HasClock    bool
+	Clock       time.Duration // clock remaining after the move
+	TimeSpent   time.Duration // thinking time spent on the move
+	TimeTrouble bool          // the move was made in time trouble
+}
+
+// Summary counts the classified moves of one side.
+type Summary struct {
+	Inaccuracies int
+	Mistakes     int
+	Blunders     int
+	Great        int
+	Brilliant    int
+
+	TimeTroubleErrors int // mistakes and blunders made in time trouble
+}
+
+// Summarize counts the classifications per side. The result is indexed by
+// color (internal.White, internal.Black).
+func Summarize(annotations []MoveAnnotation) [2]Summary {
+	var s [2]Summary
+	for _, a := range annotations {
+		sum := &s[a.Color]
+		switch a.Classification {
+		case Inaccuracy:
+			sum.Inaccuracies++
+		case Mistake:
+			sum.Mistakes++
+		case Blunder:
+			sum.Blunders++
+		case Great:
+			sum.Great++
+		case Brilliant:
+			sum.Brilliant++
+		}
+		if a.TimeTrouble && (a.Classification == Mistake || a.Classification == Blunder) {
+			sum.TimeTroubleErrors++
+		}
+	}
+	return s
+}
+
+// Annotator classifies the moves of a game using an engine.
+type Annotator struct {
+	engine   engine.Analyzer
+	opts     Options
+	logger   *slog.Logger
+	progress func(done, total int)
+}
+
+// New creates an Annotator that analyzes positions with eng. Zero-valued
+// options are replaced by their defaults.
+func New(eng engine.Analyzer, opts Options, logger *slog.Logger) *Annotator {
+	def := DefaultOptions()
+	if opts.Depth <= 0 {
+		opts.Depth = def.Depth
+	}
+	if opts.MultiPV <= 0 {
+		opts.MultiPV = def.MultiPV
+	}
+	if opts.InaccuracyThreshold <= 0 {
+		opts.InaccuracyThreshold = def.InaccuracyThreshold
+	}
+	if opts.MistakeThreshold <= 0 {
+		opts.MistakeThreshold = def.MistakeThreshold
+	}
+	if opts.BlunderThreshold <= 0 {
+		opts.BlunderThreshold = def.BlunderThreshold
+	}
+	return &Annotator{engine: eng, opts: opts, logger: logger.With("component", "analysis")}
+}
+
+// WithProgress makes AnnotateGame call fn after analyzing each position, with
+// the number of positions analyzed and the number in the game.
+func (a *Annotator) WithProgress(fn func(done, total int)) *Annotator {
+	a.progress = fn
+	return a
+}
+
+// position is an analyzed position of the main line.
+type position struct {
+	board *internal.Board
+	eval  int      // centipawns from White's perspective
+	lines []int    // eval of each engine line, from White's perspective
+	best  []string // first move of each engine line (UCI)
+}
+
+// AnnotateGame analyzes every position of the game's main line, adds NAGs to
+// the moves it classifies and returns an annotation for each move. Clock data
+// from %clk comments is attached to the annotations when present. The moves
+// of the game must already have been parsed.
+func (a *Annotator) AnnotateGame(ctx context.Context, game *pgn.Game) ([]MoveAnnotation, error) {
+	nodes := []*pgn.Node{game.Root}
+	for n := game.Root.Next; n != nil; n = n.Next {
+		nodes = append(nodes, n)
+	}
+
+	positions := make([]position, len(nodes))
+	for i, n := range nodes {
+		pos, err := a.analyze(ctx, n.Board)
+		if err != nil {
+			return nil, fmt.Errorf("ply %d: %w", i, err)
+		}
+		positions[i] = pos
+		if a.progress != nil {
+			a.progress(i+1, len(nodes))
+		}
+	}
+
+	annotations := make([]MoveAnnotation, 0, len(nodes)-1)
+	for i := 1; i < len(nodes); i++ {
+		ann := a.classify(positions[i-1], positions[i], nodes[i].Move)
+		ann.Ply = i
+		if nag := ann.Classification.Nag(); nag != 0 {
+			nodes[i].AddNag(nag)
+		}
+		annotations = append(annotations, ann)
+	}
+
+	// Win probabilities, for the strength of the players
+	rating := a.opts.Rating
+	if rating <= 0 {
+		rating = gameRating(game)
+	}
+	for i := range annotations {
+		annotations[i].WinBefore = WinProbability(annotations[i].EvalBefore, rating)
+		annotations[i].WinAfter = WinProbability(annotations[i].EvalAfter, rating)
+	}
+
+	// Tag the tactics of the critical moves: the sacrifices played and the
+	// engine's replies punishing mistakes, which make the best puzzles.
+	for i := range annotations {
+		ann := &annotations[i]
+		switch ann.Classification {
+		case Great, Brilliant:
+			ann.Motifs = nodes[i].Board.Motifs(nodes[i+1].Move)
+		case Mistake, Blunder:
+			if best := positions[i+1].best; len(best) > 0 {
+				ann.Motifs = moveMotifs(nodes[i+1].Board, best[0])
+			}
+		}
+	}
+
+	base, _, _ := pgn.ParseTimeControl(game.Tags["TimeControl"])
+	threshold := pgn.TimeTroubleThreshold(base)
+	for _, mt := range game.MoveTimes() {
+		ann := &annotations[mt.Ply-1]
+		ann.HasClock = true
+		ann.Clock = mt.Clock
+		ann.TimeSpent = mt.Spent
+		ann.TimeTrouble = mt.Clock < threshold
+	}
+	return annotations, nil
+}
+
+// analyze evaluates a single position. Positions without legal moves are
+// scored directly instead of being sent to the engine.
+func (a *Annotator) analyze(ctx context.Context, board *internal.Board) (position, error) {
+	pos := position{board: board}
+
+	if len(board.LegalMoves()) == 0 {
+		if check, _ := board.IsCheckOrMate(); check {
+			pos.eval = whiteView(-mateScore, board.SideToMove)
+		}
+		return pos, nil
+	}
+
+	result, err := a.engine.Analyze(ctx, board.Fen(), engine.AnalysisOptions{
+		Depth:   a.opts.Depth,
+		MultiPV: a.opts.MultiPV,
+	})
+	if err != nil {
+		return pos, err
+	}
+	for _, line := range result.Lines {
+		pos.lines = append(pos.lines, Centipawns(line.Score))
+		if len(line.Moves) > 0 {
+			pos.best = append(pos.best, line.Moves[0])
+		} else {
+			pos.best = append(pos.best, "")
+		}
+	}
+	if len(pos.lines) > 0 {
+		pos.eval = pos.lines[0]
+	}
+	a.logger.Debug("position analyzed", "fen", result.FEN, "eval", pos.eval, "lines", len(pos.lines))
+	return pos, nil
+}
+
+// classify compares the positions before and after move m.
+func (a *Annotator) classify(before, after position, m internal.Move) MoveAnnotation {
+	board := before.board
+	color := board.SideToMove
+	ann := MoveAnnotation{
+		Color:      color,
+		San:        m.San(board),
+		EvalBefore: before.eval,
+		EvalAfter:  after.eval,
+		SEE:        board.SEE(m),
+	}
+
+	// evals from the mover's perspective
+	mover := func(cp int) int { return whiteView(cp, color) }
+	ann.Loss = max(0, mover(before.eval)-mover(after.eval))
+
+	isBest := false
+	if len(before.best) > 0 && before.best[0] != "" {
+		if best, err := board.ParseMove(before.best[0]); err == nil {
+			ann.Best = best.San(board)
+		}
+		isBest = before.best[0] == m.Uci(board)
+	}
+
+	switch {
+	case ann.Loss >= a.opts.BlunderThreshold:
+		ann.Classification = Blunder
+	case ann.Loss >= a.opts.MistakeThreshold:
+		ann.Classification = Mistake
+	case ann.Loss >= a.opts.InaccuracyThreshold:
+		ann.Classification = Inaccuracy
+	case ann.SEE < 0 && (isBest || ann.Loss <= sacrificeTolerance):
+		ann.Classification = Great
+		// With a second line we can tell whether the sacrifice was the
+		// only way to keep the evaluation.
+		if len(before.lines) > 1 && mover(before.lines[0])-mover(before.lines[1]) >= a.opts.MistakeThreshold {
+			ann.Classification = Brilliant
+		}
+	}
+	return ann
+}
+
+// moveMotifs returns the tactical motifs of a move given in UCI notation, or
+// nil if it cannot be parsed.
+func moveMotifs(board *internal.Board, uci string) []string {
+	m, err := board.ParseMove(uci)
+	if err != nil {
+		return nil
+	}
+	return board.Motifs(m)
+}
+
+// Centipawns converts an engine score to centipawns. Mates are mapped to
+// ±(10000-N) so that shorter mates compare as better.
+func Centipawns(s engine.Score) int {
+	if !s.IsMate {
+		return s.Centipawns
+	}
+	switch {
+	case s.Mate > 0:
+		return mateScore - s.Mate
+	case s.Mate < 0:
+		return -mateScore - s.Mate
+	}
+	return 0
+}
+
+// Pawns converts centipawns, as returned by Centipawns, to pawns as the
+// positions table stores evaluations, with forced mates as ±pgn.MateEval.
+func Pawns(cp int) float64 {
+	switch {
+	case cp > mateScore-maxMateDistance:
+		return pgn.MateEval
+	case cp < -mateScore+maxMateDistance:
+		return -pgn.MateEval
+	}
+	return float64(cp) / 100
+}
+
+// whiteView flips a score given from color's perspective to White's
+// perspective (and vice versa).
+func whiteView(cp, color int) int {
+	if color == internal.Black {
+		return -cp
+	}
+	return cp
+}
@@ -0,0 +1,238 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/kyleboon/gochess/internal"
+	"github.com/kyleboon/gochess/internal/engine"
+	"github.com/kyleboon/gochess/internal/logging"
+	"github.com/kyleboon/gochess/internal/pgn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAnalyzer returns canned analysis results keyed by FEN.
+type fakeAnalyzer struct {
+	results map[string]*engine.AnalysisResult
+}
+
+func (f *fakeAnalyzer) Analyze(_ context.Context, fen string, _ engine.AnalysisOptions) (*engine.AnalysisResult, error) {
+	r, ok := f.results[fen]
+	if !ok {
+		return nil, fmt.Errorf("unexpected position %s", fen)
+	}
+	return r, nil
+}
+
+func (f *fakeAnalyzer) Close() error { return nil }
+
+func parseGame(t *testing.T, text string) *pgn.Game {
+	t.Helper()
+	db := &pgn.DB{}
+	require.Empty(t, db.Parse(text))
+	require.Len(t, db.Games, 1)
+	require.NoError(t, db.ParseMoves(db.Games[0]))
+	return db.Games[0]
+}
+
+func mainLine(game *pgn.Game) []*pgn.Node {
+	nodes := []*pgn.Node{game.Root}
+	for n := game.Root.Next; n != nil; n = n.Next {
+		nodes = append(nodes, n)
+	}
+	return nodes
+}
+
+func cp(v int) engine.Score { return engine.Score{Centipawns: v} }
+
+func TestAnnotateGame_LegalsMate(t *testing.T) {
+	game := parseGame(t, `[Event "Legal's mate"]
+[White "A"]
+[Black "B"]
+[Result "1-0"]
+
+1. e4 e5 2. Nf3 d6 3. Bc4 Bg4 4. Nc3 g6 5. Nxe5 Bxd1 6. Bxf7+ Ke7 7. Nd5# 1-0
+`)
+	nodes := mainLine(game)
+	require.Len(t, nodes, 14)
+
+	evals := []engine.Score{
+		cp(30), cp(30), cp(30), cp(30), cp(50), cp(50), cp(60), cp(60),
+		cp(150),                 // after 4...g6
+		cp(160),                 // after 5.Nxe5
+		{Mate: 2, IsMate: true}, // after 5...Bxd1
+		{Mate: 1, IsMate: true}, // after 6.Bxf7+
+		{Mate: 1, IsMate: true}, // after 6...Ke7
+	}
+	results := make(map[string]*engine.AnalysisResult)
+	for i, score := range evals {
+		fen := nodes[i].Board.Fen()
+		results[fen] = &engine.AnalysisResult{
+			FEN:   fen,
+			Lines: []engine.AnalysisLine{{Rank: 1, Score: score}},
+		}
+	}
+	// Before 5.Nxe5: the knight sacrifice is the only good move.
+	results[nodes[8].Board.Fen()].Lines = []engine.AnalysisLine{
+		{Rank: 1, Score: cp(150), Moves: []string{"f3e5"}},
+		{Rank: 2, Score: cp(20), Moves: []string{"e1h1"}},
+	}
+
+	a := New(&fakeAnalyzer{results: results}, Options{}, logging.Discard())
+	annotations, err := a.AnnotateGame(context.Background(), game)
+	require.NoError(t, err)
+	require.Len(t, annotations, 13)
+
+	byPly := func(ply int) MoveAnnotation { return annotations[ply-1] }
+
+	assert.Equal(t, "g6", byPly(8).San)
+	assert.Equal(t, Inaccuracy, byPly(8).Classification)
+
+	nxe5 := byPly(9)
+	assert.Equal(t, "Nxe5", nxe5.San)
+	assert.Equal(t, "Nxe5", nxe5.Best)
+	assert.Equal(t, -200, nxe5.SEE)
+	assert.Equal(t, 0, nxe5.Loss)
+	assert.Equal(t, Brilliant, nxe5.Classification)
+	assert.Equal(t, []pgn.Nag{3}, nodes[9].Nags)
+	assert.Equal(t, []string{internal.DiscoveredAttackMotif}, nxe5.Motifs, "the queen attacks the bishop")
+
+	bxd1 := byPly(10)
+	assert.Equal(t, internal.Black, bxd1.Color)
+	assert.Equal(t, Blunder, bxd1.Classification)
+	assert.Equal(t, []pgn.Nag{4}, nodes[10].Nags)
+	assert.Empty(t, bxd1.Motifs, "no engine reply to tag")
+
+	assert.Equal(t, Normal, byPly(11).Classification)
+	assert.Empty(t, nodes[11].Nags)
+
+	// The final position is mate and is scored without the engine.
+	assert.Equal(t, mateScore, byPly(13).EvalAfter)
+	assert.Greater(t, byPly(13).WinAfter, 0.97)
+	assert.Equal(t, WinProbability(150, 0), byPly(9).WinBefore)
+
+	summary := Summarize(annotations)
+	assert.Equal(t, Summary{Brilliant: 1}, summary[internal.White])
+	assert.Equal(t, Summary{Inaccuracies: 1, Blunders: 1}, summary[internal.Black])
+}
+
+func TestClassify_GreatWithoutSecondLine(t *testing.T) {
+	board, err := internal.ParseFen("4k3/1p6/8/8/3N4/8/8/4K3 w - - 0 1")
+	require.NoError(t, err)
+	m, err := board.ParseMove("Nc6")
+	require.NoError(t, err)
+
+	a := New(&fakeAnalyzer{}, Options{MultiPV: 1}, logging.Discard())
+	before := position{board: board, eval: 100, lines: []int{100}, best: []string{"d4c6"}}
+	after := position{board: board.MakeMove(m), eval: 110}
+
+	ann := a.classify(before, after, m)
+	assert.Equal(t, -300, ann.SEE)
+	assert.Equal(t, Great, ann.Classification)
+	assert.Equal(t, pgn.Nag(1), ann.Classification.Nag())
+}
+
+func TestCentipawns(t *testing.T) {
+	assert.Equal(t, 35, Centipawns(cp(35)))
+	assert.Equal(t, 9997, Centipawns(engine.Score{Mate: 3, IsMate: true}))
+	assert.Equal(t, -9998, Centipawns(engine.Score{Mate: -2, IsMate: true}))
+	assert.Equal(t, 0, Centipawns(engine.Score{IsMate: true}))
+}
+
+func TestPawns(t *testing.T) {
+	assert.Equal(t, 0.35, Pawns(35))
+	assert.Equal(t, -12.5, Pawns(-1250))
+	assert.Equal(t, pgn.MateEval, Pawns(Centipawns(engine.Score{Mate: 3, IsMate: true})))
+	assert.Equal(t, -pgn.MateEval, Pawns(Centipawns(engine.Score{Mate: -2, IsMate: true})))
+}
+
+func TestAnnotateGame_Motifs(t *testing.T) {
+	game := parseGame(t, `[Event "Blackburne Shilling"]
+[White "A"]
+[Black "B"]
+[Result "0-1"]
+
+1. e4 e5 2. Nf3 Nc6 3. Bc4 Nd4 4. Nxe5 Qg5 5. Nxf7 Qxg2 6. Rf1 Qxe4+ 7. Be2 Nf3# 0-1
+`)
+	nodes := mainLine(game)
+	results := make(map[string]*engine.AnalysisResult)
+	for i, n := range nodes[:len(nodes)-1] {
+		fen := n.Board.Fen()
+		results[fen] = &engine.AnalysisResult{FEN: fen, Lines: []engine.AnalysisLine{{Rank: 1, Score: cp(-300)}}}
+		if i == 13 {
+			// After 7.Be2 Black mates: the blunder allows a smothered mate.
+			results[fen].Lines[0] = engine.AnalysisLine{Rank: 1, Score: engine.Score{Mate: -1, IsMate: true}, Moves: []string{"d4f3"}}
+		}
+	}
+
+	a := New(&fakeAnalyzer{results: results}, Options{}, logging.Discard())
+	annotations, err := a.AnnotateGame(context.Background(), game)
+	require.NoError(t, err)
+
+	be2 := annotations[12]
+	assert.Equal(t, "Be2", be2.San)
+	assert.Equal(t, Blunder, be2.Classification)
+	assert.Equal(t, []string{internal.SmotheredMateMotif}, be2.Motifs)
+	for _, ann := range annotations[:12] {
+		assert.Empty(t, ann.Motifs, "only critical moves are tagged")
+	}
+}
+
+func TestAnnotateGame_TimeTrouble(t *testing.T) {
+	game := parseGame(t, `[Event "Bullet"]
+[White "A"]
+[Black "B"]
+[Result "1-0"]
+[TimeControl "60"]
+
+1. e4 {[%clk 0:00:59]} 1... e5 {[%clk 0:00:30]} 2. Qh5 {[%clk 0:00:58]} 2... Ke7 {[%clk 0:00:03]} 3. Qxe5# {[%clk 0:00:57]} 1-0
+`)
+	nodes := mainLine(game)
+	evals := []engine.Score{cp(30), cp(30), cp(30), cp(20), {Mate: 1, IsMate: true}}
+	results := make(map[string]*engine.AnalysisResult)
+	for i, score := range evals {
+		fen := nodes[i].Board.Fen()
+		results[fen] = &engine.AnalysisResult{FEN: fen, Lines: []engine.AnalysisLine{{Rank: 1, Score: score}}}
+	}
+
+	a := New(&fakeAnalyzer{results: results}, Options{}, logging.Discard())
+	annotations, err := a.AnnotateGame(context.Background(), game)
+	require.NoError(t, err)
+	require.Len(t, annotations, 5)
+
+	ke7 := annotations[3]
+	assert.Equal(t, Blunder, ke7.Classification)
+	assert.True(t, ke7.HasClock)
+	assert.Equal(t, 27*time.Second, ke7.TimeSpent)
+	assert.True(t, ke7.TimeTrouble)
+	assert.False(t, annotations[1].TimeTrouble)
+
+	summary := Summarize(annotations)
+	assert.Equal(t, 1, summary[internal.Black].TimeTroubleErrors)
+	assert.Equal(t, 0, summary[internal.White].TimeTroubleErrors)
+}
+
+func TestAnnotateGame_Progress(t *testing.T) {
+	game := parseGame(t, `[Event "Progress"]
+[White "A"]
+[Black "B"]
+[Result "*"]
+
+1. e4 e5 *
+`)
+	results := make(map[string]*engine.AnalysisResult)
+	for _, n := range mainLine(game) {
+		fen := n.Board.Fen()
+		results[fen] = &engine.AnalysisResult{FEN: fen, Lines: []engine.AnalysisLine{{Rank: 1, Score: cp(20)}}}
+	}
+
+	var calls [][2]int
+	a := New(&fakeAnalyzer{results: results}, Options{}, logging.Discard()).
+		WithProgress(func(done, total int) { calls = append(calls, [2]int{done, total}) })
+	_, err := a.AnnotateGame(context.Background(), game)
+	require.NoError(t, err)
+	assert.Equal(t, [][2]int{{1, 3}, {2, 3}, {3, 3}}, calls)
+}
@@ -0,0 +1,36 @@
+package analysis
+
+import (
+	"github.com/kyleboon/gochess/internal"
+	"github.com/kyleboon/gochess/internal/db"
+)
+
+// Record converts the annotations of a game's review into the analysis
+// saved to the database, with the evaluations of its positions in pawns
+// keyed by ply, as db.SaveAnalysis takes them.
+func Record(gameID int, opts Options, annotations []MoveAnnotation) (*db.GameAnalysis, map[int]float64) {
+	result := &db.GameAnalysis{GameID: gameID, Depth: opts.Depth, Lines: opts.MultiPV}
+	evals := make(map[int]float64)
+	for i, a := range annotations {
+		if i == 0 {
+			evals[a.Ply-1] = Pawns(a.EvalBefore)
+		}
+		evals[a.Ply] = Pawns(a.EvalAfter)
+		result.Moves = append(result.Moves, db.AnalysisMove{
+			Ply:            a.Ply,
+			SAN:            a.San,
+			Best:           a.Best,
+			EvalBefore:     a.EvalBefore,
+			EvalAfter:      a.EvalAfter,
+			Loss:           a.Loss,
+			Classification: a.Classification.String(),
+			Motifs:         a.Motifs,
+		})
+	}
+
+	summary := Summarize(annotations)
+	white, black := summary[internal.White], summary[internal.Black]
+	result.WhiteInaccuracies, result.WhiteMistakes, result.WhiteBlunders = white.Inaccuracies, white.Mistakes, white.Blunders
+	result.BlackInaccuracies, result.BlackMistakes, result.BlackBlunders = black.Inaccuracies, black.Mistakes, black.Blunders
+	return result, evals
+}
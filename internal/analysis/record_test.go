@@ -0,0 +1,29 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/kyleboon/gochess/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecord(t *testing.T) {
+	annotations := []MoveAnnotation{
+		{Ply: 1, Color: internal.White, San: "e4", Best: "e4", EvalBefore: 20, EvalAfter: 30, Classification: Normal},
+		{Ply: 2, Color: internal.Black, San: "f6", Best: "e5", EvalBefore: 30, EvalAfter: 120, Loss: 90, Classification: Mistake},
+		{Ply: 3, Color: internal.White, San: "d4", Best: "Qh5+", EvalBefore: 120, EvalAfter: -400, Loss: 520, Classification: Blunder, Motifs: []string{internal.ForkMotif}},
+	}
+	opts := DefaultOptions()
+
+	result, evals := Record(7, opts, annotations)
+	assert.Equal(t, 7, result.GameID)
+	assert.Equal(t, opts.Depth, result.Depth)
+	assert.Equal(t, 1, result.WhiteBlunders)
+	assert.Equal(t, 1, result.BlackMistakes)
+	require.Len(t, result.Moves, 3)
+	assert.Equal(t, "blunder", result.Moves[2].Classification)
+	assert.Equal(t, []string{internal.ForkMotif}, result.Moves[2].Motifs)
+	assert.Equal(t, "Qh5+", result.Moves[2].Best)
+	assert.Equal(t, map[int]float64{0: 0.2, 1: 0.3, 2: 1.2, 3: -4}, evals)
+}
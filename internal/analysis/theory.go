@@ -0,0 +1,86 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kyleboon/gochess/internal/pgn"
+)
+
+// TheoryMove is a move played from a position in a set of reference games,
+// such as a database of master games, with the results of those games.
+type TheoryMove struct {
+	UCI       string
+	SAN       string
+	Games     int
+	WhiteWins int
+	BlackWins int
+	Draws     int
+}
+
+// TheorySource looks up positions in a set of reference games.
+type TheorySource interface {
+	// PositionMoves returns the moves played from the position of fen, most
+	// played first.
+	PositionMoves(ctx context.Context, fen string) ([]TheoryMove, error)
+}
+
+// Deviation is the first move of a game out of known theory.
+type Deviation struct {
+	Ply     int // of the move, from 1
+	Color   int // the side that played the move
+	SAN     string
+	UCI     string
+	Games   int          // the reference games in which the move was played
+	Total   int          // the reference games that reached the position
+	Replies []TheoryMove // the moves theory plays in the position, most played first
+}
+
+// Popularity returns the share of the reference games reaching the position
+// in which the move was played, from 0 to 1.
+func (d Deviation) Popularity() float64 {
+	if d.Total == 0 {
+		return 0
+	}
+	return float64(d.Games) / float64(d.Total)
+}
+
+// FindDeviation follows the main line of game through the reference games
+// of source and returns the first move played in fewer than minGames of
+// them, or nil if the game never leaves theory. The moves of the game must
+// already have been parsed.
+func FindDeviation(ctx context.Context, source TheorySource, game *pgn.Game, minGames int) (*Deviation, error) {
+	ply := 0
+	for n := game.Root; n.Next != nil; n = n.Next {
+		ply++
+		board := n.Board
+		moves, err := source.PositionMoves(ctx, board.Fen())
+		if err != nil {
+			return nil, fmt.Errorf("ply %d: %w", ply, err)
+		}
+
+		played := n.Next.Move
+		d := &Deviation{
+			Ply:   ply,
+			Color: board.SideToMove,
+			SAN:   played.San(board),
+			UCI:   played.Uci(board),
+		}
+		for i, m := range moves {
+			if m.SAN == "" {
+				if mv, err := board.ParseMove(m.UCI); err == nil {
+					moves[i].SAN = mv.San(board)
+				}
+			}
+			if moves[i].SAN == d.SAN {
+				d.Games = m.Games
+			}
+			d.Total += m.Games
+		}
+		if d.Games < minGames {
+			d.Replies = moves
+			return d, nil
+		}
+	}
+	return nil, nil
+}
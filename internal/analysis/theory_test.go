@@ -0,0 +1,89 @@
+package analysis
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kyleboon/gochess/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTheory returns canned reference moves keyed by FEN; other positions
+// have none.
+type fakeTheory map[string][]TheoryMove
+
+func (f fakeTheory) PositionMoves(_ context.Context, fen string) ([]TheoryMove, error) {
+	return f[fen], nil
+}
+
+const theoryGame = `[Event "Theory"]
+[White "A"]
+[Black "B"]
+[Result "*"]
+
+1. e4 c5 2. Nf3 a6 *
+`
+
+func theorySource(t *testing.T) fakeTheory {
+	t.Helper()
+	nodes := mainLine(parseGame(t, theoryGame))
+	return fakeTheory{
+		nodes[0].Board.Fen(): {
+			{UCI: "e2e4", Games: 500},
+			{UCI: "d2d4", Games: 400},
+		},
+		nodes[1].Board.Fen(): {
+			{UCI: "c7c5", SAN: "c5", Games: 200},
+			{UCI: "e7e5", SAN: "e5", Games: 150},
+		},
+		nodes[2].Board.Fen(): {
+			{UCI: "g1f3", Games: 150},
+			{UCI: "b1c3", Games: 40},
+		},
+		nodes[3].Board.Fen(): {
+			{UCI: "d7d6", Games: 60},
+			{UCI: "b8c6", Games: 50},
+			{UCI: "e7e6", Games: 38},
+			{UCI: "a7a6", Games: 2},
+		},
+	}
+}
+
+func TestFindDeviation(t *testing.T) {
+	game := parseGame(t, theoryGame)
+
+	d, err := FindDeviation(context.Background(), theorySource(t), game, 5)
+	require.NoError(t, err)
+	require.NotNil(t, d)
+	assert.Equal(t, 4, d.Ply)
+	assert.Equal(t, internal.Black, d.Color)
+	assert.Equal(t, "a6", d.SAN)
+	assert.Equal(t, "a7a6", d.UCI)
+	assert.Equal(t, 2, d.Games)
+	assert.Equal(t, 150, d.Total)
+	assert.InDelta(t, 2.0/150, d.Popularity(), 1e-9)
+	require.Len(t, d.Replies, 4)
+	assert.Equal(t, "d6", d.Replies[0].SAN, "SAN is filled in from the UCI move")
+	assert.Equal(t, "Nc6", d.Replies[1].SAN)
+}
+
+func TestFindDeviation_UnknownMove(t *testing.T) {
+	game := parseGame(t, theoryGame)
+
+	// a6 is still theory with a threshold of 2 games, but then the game ends
+	d, err := FindDeviation(context.Background(), theorySource(t), game, 2)
+	require.NoError(t, err)
+	assert.Nil(t, d, "the game never leaves theory")
+
+	source := theorySource(t)
+	delete(source, mainLine(game)[1].Board.Fen())
+	d, err = FindDeviation(context.Background(), source, game, 1)
+	require.NoError(t, err)
+	require.NotNil(t, d)
+	assert.Equal(t, 2, d.Ply, "c5 is out of theory when Black's replies are unknown")
+	assert.Equal(t, 0, d.Games)
+	assert.Equal(t, 0, d.Total)
+	assert.Zero(t, d.Popularity())
+	assert.Empty(t, d.Replies)
+}
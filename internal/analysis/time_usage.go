@@ -0,0 +1,47 @@
+package analysis
+
+import (
+	"time"
+
+	"github.com/kyleboon/gochess/internal/pgn"
+)
+
+// TimeSummary describes how one side used the clock in a game.
+type TimeSummary struct {
+	Moves            int           // moves with clock data
+	Total            time.Duration // total thinking time
+	Longest          time.Duration // longest think
+	LongestPly       int           // ply of the longest think
+	TimeTroubleMoves int           // moves played in time trouble
+}
+
+// Average returns the average thinking time per move.
+func (s TimeSummary) Average() time.Duration {
+	if s.Moves == 0 {
+		return 0
+	}
+	return s.Total / time.Duration(s.Moves)
+}
+
+// SummarizeTime summarizes the clock usage of both sides of a game. The
+// result is indexed by color (internal.White, internal.Black). The moves of
+// the game must already have been parsed.
+func SummarizeTime(game *pgn.Game) [2]TimeSummary {
+	base, _, _ := pgn.ParseTimeControl(game.Tags["TimeControl"])
+	threshold := pgn.TimeTroubleThreshold(base)
+
+	var s [2]TimeSummary
+	for _, mt := range game.MoveTimes() {
+		sum := &s[mt.Color]
+		sum.Moves++
+		sum.Total += mt.Spent
+		if mt.Spent > sum.Longest {
+			sum.Longest = mt.Spent
+			sum.LongestPly = mt.Ply
+		}
+		if mt.Clock < threshold {
+			sum.TimeTroubleMoves++
+		}
+	}
+	return s
+}
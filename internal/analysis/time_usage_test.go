@@ -0,0 +1,36 @@
+package analysis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kyleboon/gochess/internal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummarizeTime(t *testing.T) {
+	game := parseGame(t, `[Event "Blitz"]
+[White "A"]
+[Black "B"]
+[Result "*"]
+[TimeControl "180+2"]
+
+1. e4 {[%clk 0:03:01]} 1... c6 {[%clk 0:02:50]} 2. d4 {[%clk 0:02:33]} 2... d5 {[%clk 0:00:12]} *
+`)
+
+	s := SummarizeTime(game)
+
+	white := s[internal.White]
+	assert.Equal(t, 2, white.Moves)
+	assert.Equal(t, 31*time.Second, white.Total)
+	assert.Equal(t, 30*time.Second, white.Longest)
+	assert.Equal(t, 3, white.LongestPly)
+	assert.Equal(t, 15500*time.Millisecond, white.Average())
+	assert.Equal(t, 0, white.TimeTroubleMoves)
+
+	black := s[internal.Black]
+	assert.Equal(t, 2, black.Moves)
+	assert.Equal(t, 12*time.Second+160*time.Second, black.Total)
+	assert.Equal(t, 4, black.LongestPly)
+	assert.Equal(t, 1, black.TimeTroubleMoves)
+}
@@ -0,0 +1,57 @@
+package analysis
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/kyleboon/gochess/internal/pgn"
+)
+
+// winCoefficient is the slope of the logistic curve mapping centipawns to
+// win probability, as fitted by Lichess on games between strong players.
+const winCoefficient = 0.00368208
+
+// winModelRating is the rating the curve's slope is fitted for. Weaker
+// players convert advantages less reliably, so for lower ratings the curve
+// is flattened in proportion.
+const winModelRating = 2300
+
+// Ratings outside these bounds are clamped by the win-probability model.
+const (
+	minWinRating = 600
+	maxWinRating = 3000
+)
+
+// maxWinEval bounds the evaluations, in centipawns, told apart by the model:
+// a larger advantage, including a forced mate, is as good as won.
+const maxWinEval = 1000
+
+// WinProbability returns the chances, from 0 to 1, of the side with an
+// advantage of cp centipawns, counting a draw as half a win: the "win%"
+// chess sites show instead of raw evaluations. rating adjusts the curve to
+// the strength of the players; 0 uses the curve as fitted.
+func WinProbability(cp, rating int) float64 {
+	k := winCoefficient
+	if rating > 0 {
+		k *= float64(max(minWinRating, min(maxWinRating, rating))) / winModelRating
+	}
+	cp = max(-maxWinEval, min(maxWinEval, cp))
+	return 1 / (1 + math.Exp(-k*float64(cp)))
+}
+
+// gameRating returns the average rating of the players of a game from its
+// WhiteElo and BlackElo tags, the one rating given if the other is missing,
+// or 0 if neither is.
+func gameRating(game *pgn.Game) int {
+	sum, n := 0, 0
+	for _, tag := range []string{"WhiteElo", "BlackElo"} {
+		if elo, err := strconv.Atoi(game.Tags[tag]); err == nil && elo > 0 {
+			sum += elo
+			n++
+		}
+	}
+	if n == 0 {
+		return 0
+	}
+	return sum / n
+}
@@ -0,0 +1,41 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/kyleboon/gochess/internal/engine"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWinProbability(t *testing.T) {
+	assert.Equal(t, 0.5, WinProbability(0, 0))
+	assert.InDelta(t, 0.59, WinProbability(100, 0), 0.01)
+	assert.InDelta(t, 1-WinProbability(100, 0), WinProbability(-100, 0), 1e-9, "the curve is symmetric")
+
+	// A forced mate is as good as won, and no better than a large advantage
+	mate := Centipawns(engine.Score{Mate: 3, IsMate: true})
+	assert.Equal(t, WinProbability(maxWinEval, 0), WinProbability(mate, 0))
+	assert.Greater(t, WinProbability(mate, 0), 0.97)
+
+	// Weaker players convert the same advantage less often
+	assert.Less(t, WinProbability(200, 1200), WinProbability(200, 2000))
+	assert.Equal(t, WinProbability(200, winModelRating), WinProbability(200, 0))
+	assert.Equal(t, WinProbability(200, minWinRating), WinProbability(200, 100), "ratings are clamped")
+}
+
+func TestGameRating(t *testing.T) {
+	game := parseGame(t, `[Event "Rated"]
+[White "A"]
+[Black "B"]
+[WhiteElo "1500"]
+[BlackElo "1700"]
+[Result "*"]
+
+1. e4 *
+`)
+	assert.Equal(t, 1600, gameRating(game))
+	game.Tags["BlackElo"] = "?"
+	assert.Equal(t, 1500, gameRating(game))
+	delete(game.Tags, "WhiteElo")
+	assert.Equal(t, 0, gameRating(game))
+}
@@ -69,35 +69,140 @@ func BenchmarkPerft1(b *testing.B) {
 		"r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1",           // Position 4
 	}
 	
-	var totalNodes int
+	var totalNodes uint64
 	
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		pos := positions[i%len(positions)]
 		board, _ := ParseFen(pos)
-		nodes := perft(board, 1)
+		nodes := board.Perft(1)
 		totalNodes += nodes
 	}
 	
 	b.ReportMetric(float64(totalNodes)/b.Elapsed().Seconds(), "nodes/s")
 }
 
-// Perft (performance test) - counts the number of leaf nodes at a given depth
-func perft(board *Board, depth int) int {
-	if depth == 0 {
-		return 1
+// BenchmarkIsLegal measures checking single moves, as when validating a move
+// entered by a player, against generating all legal moves to find it
+func BenchmarkIsLegal(b *testing.B) {
+	board, err := ParseFen("r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1")
+	if err != nil {
+		b.Fatalf("Failed to parse FEN: %v", err)
 	}
-	
 	moves := board.LegalMoves()
-	if depth == 1 {
-		return len(moves)
+
+	b.Run("IsLegal", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			board.IsLegal(moves[i%len(moves)])
+		}
+	})
+	b.Run("LegalMoves", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			m := moves[i%len(moves)]
+			for _, n := range board.LegalMoves() {
+				if n == m {
+					break
+				}
+			}
+		}
+	})
+}
+
+// benchmarkPositions are the standard perft positions, shared by the
+// benchmarks of single board operations
+var benchmarkPositions = []string{
+	"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+	"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+	"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1",
+	"r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1",
+	"rnbq1k1r/pp1Pbppp/2p5/8/2B5/8/PPP1NnPP/RNBQK2R w KQ - 1 8",
+}
+
+// benchmarkMoves returns the legal moves of the benchmark positions, each
+// with the board it is played on
+func benchmarkMoves(b *testing.B) ([]*Board, []Move) {
+	var boards []*Board
+	var moves []Move
+	for _, fen := range benchmarkPositions {
+		board, err := ParseFen(fen)
+		if err != nil {
+			b.Fatalf("Failed to parse FEN %s: %v", fen, err)
+		}
+		for _, m := range board.LegalMoves() {
+			boards = append(boards, board)
+			moves = append(moves, m)
+		}
 	}
-	
-	var nodes int
-	for _, move := range moves {
-		newBoard := board.MakeMove(move)
-		nodes += perft(newBoard, depth-1)
+	return boards, moves
+}
+
+// BenchmarkMakeMove measures playing a move, which copies the board
+func BenchmarkMakeMove(b *testing.B) {
+	boards, moves := benchmarkMoves(b)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		j := i % len(moves)
+		boards[j].MakeMove(moves[j])
 	}
-	
-	return nodes
+}
+
+// BenchmarkSan measures writing moves in SAN, which generates the legal moves
+// to disambiguate and plays the move to add check and mate marks
+func BenchmarkSan(b *testing.B) {
+	boards, moves := benchmarkMoves(b)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		j := i % len(moves)
+		moves[j].San(boards[j])
+	}
+}
+
+// BenchmarkParseFen measures parsing positions from FEN
+func BenchmarkParseFen(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		fen := benchmarkPositions[i%len(benchmarkPositions)]
+		if _, err := ParseFen(fen); err != nil {
+			b.Fatalf("Failed to parse FEN %s: %v", fen, err)
+		}
+	}
+}
+
+// BenchmarkFen measures writing positions as FEN
+func BenchmarkFen(b *testing.B) {
+	boards := make([]*Board, len(benchmarkPositions))
+	for i, fen := range benchmarkPositions {
+		board, err := ParseFen(fen)
+		if err != nil {
+			b.Fatalf("Failed to parse FEN %s: %v", fen, err)
+		}
+		boards[i] = board
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		boards[i%len(boards)].Fen()
+	}
+}
+
+// BenchmarkPerft5 benchmarks perft(5) from the starting position, the usual
+// yardstick of move generator speed
+func BenchmarkPerft5(b *testing.B) {
+	board, err := ParseFen(benchmarkPositions[0])
+	if err != nil {
+		b.Fatalf("Failed to parse FEN: %v", err)
+	}
+
+	var totalNodes uint64
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		nodes := board.Perft(5)
+		if nodes != 4865609 {
+			b.Fatalf("Perft(5) = %d, want 4865609", nodes)
+		}
+		totalNodes += nodes
+	}
+
+	b.ReportMetric(float64(totalNodes)/b.Elapsed().Seconds(), "nodes/s")
 }
@@ -0,0 +1,141 @@
+package internal
+
+import (
+	"math/bits"
+	"strings"
+)
+
+// Bitboard is a set of squares, one bit per square: bit 0 is a1, bit 7 is
+// h1 and bit 63 is h8.
+type Bitboard uint64
+
+const (
+	// EmptySquares is the empty set, AllSquares the whole board.
+	EmptySquares Bitboard = 0
+	AllSquares   Bitboard = ^Bitboard(0)
+
+	// LightSquares and DarkSquares are the squares of either color.
+	LightSquares Bitboard = 0x55aa55aa55aa55aa
+	DarkSquares  Bitboard = ^LightSquares
+)
+
+// SquareMask returns the set holding only sq, or the empty set for
+// NoSquare.
+func SquareMask(sq Sq) Bitboard {
+	if sq < A1 || sq > H8 {
+		return EmptySquares
+	}
+	return 1 << uint(sq)
+}
+
+// FileMask returns the squares of file (FileA to FileH).
+func FileMask(file int) Bitboard {
+	if file < FileA || file > FileH {
+		return EmptySquares
+	}
+	return 0x0101010101010101 << uint(file)
+}
+
+// RankMask returns the squares of rank (Rank1 to Rank8).
+func RankMask(rank int) Bitboard {
+	if rank < Rank1 || rank > Rank8 {
+		return EmptySquares
+	}
+	return 0xff << (8 * uint(rank))
+}
+
+// BitboardOf returns the set of the given squares. NoSquare is ignored.
+func BitboardOf(squares ...Sq) Bitboard {
+	var bb Bitboard
+	for _, sq := range squares {
+		bb.Set(sq)
+	}
+	return bb
+}
+
+// Has reports whether sq is in the set.
+func (bb Bitboard) Has(sq Sq) bool {
+	return bb&SquareMask(sq) != 0
+}
+
+// Set adds sq to the set.
+func (bb *Bitboard) Set(sq Sq) {
+	*bb |= SquareMask(sq)
+}
+
+// Clear removes sq from the set.
+func (bb *Bitboard) Clear(sq Sq) {
+	*bb &^= SquareMask(sq)
+}
+
+// LSB returns the lowest square in the set, or NoSquare if it is empty.
+func (bb Bitboard) LSB() Sq {
+	if bb == 0 {
+		return NoSquare
+	}
+	return Sq(bits.TrailingZeros64(uint64(bb)))
+}
+
+// Pop removes the lowest square from the set and returns it, or returns
+// NoSquare if the set is empty.
+func (bb *Bitboard) Pop() Sq {
+	sq := bb.LSB()
+	*bb &= *bb - 1
+	return sq
+}
+
+// Count returns the number of squares in the set.
+func (bb Bitboard) Count() int {
+	return bits.OnesCount64(uint64(bb))
+}
+
+// Squares returns the squares in the set in ascending order.
+func (bb Bitboard) Squares() []Sq {
+	squares := make([]Sq, 0, bb.Count())
+	for bb != 0 {
+		squares = append(squares, bb.Pop())
+	}
+	return squares
+}
+
+// String draws the set as a board seen from White's side, with 'x' for the
+// squares in the set and '.' for the others.
+func (bb Bitboard) String() string {
+	var sb strings.Builder
+	for rank := Rank8; rank >= Rank1; rank-- {
+		for file := FileA; file <= FileH; file++ {
+			if file > FileA {
+				sb.WriteByte(' ')
+			}
+			if bb.Has(Square(file, rank)) {
+				sb.WriteByte('x')
+			} else {
+				sb.WriteByte('.')
+			}
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// Pieces returns the squares of piece p.
+func (b *Board) Pieces(p Piece) Bitboard {
+	var bb Bitboard
+	for sq, q := range b.Piece {
+		if q == p && p != NoPiece {
+			bb.Set(Sq(sq))
+		}
+	}
+	return bb
+}
+
+// Occupied returns the squares of the pieces of color.
+func (b *Board) Occupied(color int) Bitboard {
+	var bb Bitboard
+	for sq, p := range b.Piece {
+		if p != NoPiece && p.Color() == color {
+			bb.Set(Sq(sq))
+		}
+	}
+	return bb
+}
@@ -0,0 +1,68 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBitboard(t *testing.T) {
+	var bb Bitboard
+	assert.Equal(t, NoSquare, bb.LSB())
+	assert.Equal(t, NoSquare, bb.Pop())
+
+	bb.Set(E4)
+	bb.Set(A1)
+	bb.Set(H8)
+	bb.Set(E4)
+	bb.Set(NoSquare)
+	assert.Equal(t, 3, bb.Count())
+	assert.True(t, bb.Has(E4))
+	assert.False(t, bb.Has(E5))
+	assert.False(t, bb.Has(NoSquare))
+	assert.Equal(t, []Sq{A1, E4, H8}, bb.Squares())
+	assert.Equal(t, BitboardOf(H8, A1, E4), bb)
+
+	bb.Clear(E4)
+	assert.Equal(t, A1, bb.LSB())
+	assert.Equal(t, A1, bb.Pop())
+	assert.Equal(t, H8, bb.Pop())
+	assert.Equal(t, EmptySquares, bb)
+}
+
+func TestBitboardMasks(t *testing.T) {
+	assert.Equal(t, BitboardOf(E1, E2, E3, E4, E5, E6, E7, E8), FileMask(FileE))
+	assert.Equal(t, BitboardOf(A2, B2, C2, D2, E2, F2, G2, H2), RankMask(Rank2))
+	assert.Equal(t, EmptySquares, FileMask(8))
+	assert.Equal(t, EmptySquares, RankMask(-1))
+	assert.Equal(t, BitboardOf(D5), FileMask(FileD)&RankMask(Rank5))
+	assert.Equal(t, 64, AllSquares.Count())
+	assert.Equal(t, AllSquares, LightSquares|DarkSquares)
+
+	for sq := A1; sq <= H8; sq++ {
+		assert.Equal(t, sq.Color() == White, LightSquares.Has(sq), "square %s", sq)
+	}
+}
+
+func TestBoardBitboards(t *testing.T) {
+	b, err := ParseFen("rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1")
+	require.NoError(t, err)
+	assert.Equal(t, BitboardOf(B1, G1), b.Pieces(WN))
+	assert.Equal(t, EmptySquares, b.Pieces(NoPiece))
+	assert.Equal(t, RankMask(Rank1)|RankMask(Rank2)&^SquareMask(E2)|SquareMask(E4), b.Occupied(White))
+	assert.Equal(t, 16, b.Occupied(Black).Count())
+	assert.Equal(t, BitboardOf(E4), b.Pieces(WP)&FileMask(FileE))
+}
+
+func TestBitboardString(t *testing.T) {
+	want := ". . . . . . . x\n" +
+		". . . . . . . .\n" +
+		". . . . . . . .\n" +
+		". . . . . . . .\n" +
+		". . . . . . . .\n" +
+		". . . . . . . .\n" +
+		". . . . . . . .\n" +
+		"x . . . . . . .\n"
+	assert.Equal(t, want, BitboardOf(A1, H8).String())
+}
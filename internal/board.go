@@ -161,14 +161,15 @@ const (
 )
 
 type Board struct {
-	Piece      [64]Piece // piece placement (NoPiece, WP, BP, WN, BN, ...)
-	SideToMove int       // White or Black
-	MoveNr     int       // fullmove counter (1-based)
-	Rule50     int       // halfmove counter for the 50-move rule (counts from 0-100)
-	EpSquare   Sq        // en-passant square (behind capturable pawn)
-	CastleSq   [4]Sq     // rooks that can castle; e.g. CastleSq[WhiteOO]
-	checkFrom  Sq        // squares the opponent's castling king moved through;
-	checkTo    Sq        //      [A1,A1] if opp did not castle last turn.
+	Piece      [64]Piece    // piece placement (NoPiece, WP, BP, WN, BN, ...)
+	SideToMove int          // White or Black
+	MoveNr     int          // fullmove counter (1-based)
+	Rule50     int          // halfmove counter for the 50-move rule (counts from 0-100)
+	EpSquare   Sq           // en-passant square (behind capturable pawn)
+	CastleSq   [4]Sq        // rooks that can castle; e.g. CastleSq[WhiteOO]
+	checkFrom  Sq           // squares the opponent's castling king moved through;
+	checkTo    Sq           //      [A1,A1] if opp did not castle last turn.
+	count      [BK + 1]int8 // number of pieces on the board, by piece
 }
 
 type GamePiece struct {
@@ -223,6 +224,8 @@ func (b Board) MakeMove(m Move) *Board {
 				b.Piece[epSquare] = b.opp(Pawn)
 			case m.To.RelativeRank(b.SideToMove) == Rank8:
 				b.Piece[m.From] = m.Promotion
+				b.count[piece]--
+				b.count[m.Promotion]++
 			}
 		}
 		// update castling rights
@@ -241,7 +244,11 @@ func (b Board) MakeMove(m Move) *Board {
 		} else {
 			b.Rule50++
 		}
-		// move the piece
+		// move the piece, capturing whatever is on the destination,
+		// including a pawn taken en passant
+		if captured := b.Piece[m.To]; captured != NoPiece {
+			b.count[captured]--
+		}
 		b.Piece[m.To] = b.Piece[m.From]
 		b.Piece[m.From] = NoPiece
 	}
@@ -252,6 +259,21 @@ func (b Board) MakeMove(m Move) *Board {
 	return &b
 }
 
+// Count returns the number of pieces p on the board.
+func (b *Board) Count(p Piece) int {
+	return int(b.count[p])
+}
+
+// Material returns the material of color in centipawns, not counting the
+// king.
+func (b *Board) Material(color int) int {
+	total := 0
+	for piece := Pawn; piece < King; piece += 2 {
+		total += b.Count(Piece(color|piece)) * PieceValue(piece)
+	}
+	return total
+}
+
 // find locates a piece in the given range of squares.
 func (b *Board) find(piece Piece, sq0, sq1 Sq) Sq {
 	dir := Sq(1)
@@ -282,6 +304,8 @@ func (b *Board) Copy() *Board {
 	
 	// Copy castling rights
 	copy(newBoard.CastleSq[:], b.CastleSq[:])
+
+	newBoard.count = b.count
 	
 	return newBoard
 }
@@ -355,3 +355,59 @@ func TestHasInsufficientMaterial(t *testing.T) {
 		})
 	}
 }
+
+func TestMaterialTracking(t *testing.T) {
+	recount := func(b *Board) [BK + 1]int8 {
+		var counts [BK + 1]int8
+		for _, p := range b.Piece {
+			if p != NoPiece {
+				counts[p]++
+			}
+		}
+		return counts
+	}
+
+	b, err := ParseFen("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1")
+	assert.NoError(t, err)
+	assert.Equal(t, 8, b.Count(WP))
+	assert.Equal(t, 2, b.Count(BN))
+	assert.Equal(t, 1, b.Count(BK))
+	assert.Equal(t, 3900, b.Material(White))
+	assert.Equal(t, 3900, b.Material(Black))
+
+	// Play a long deterministic sequence of legal moves, checking the
+	// counts against the board after every move
+	for i := 0; i < 300; i++ {
+		moves := b.LegalMoves()
+		if len(moves) == 0 {
+			break
+		}
+		b = b.MakeMove(moves[(i*7)%len(moves)])
+		assert.Equal(t, recount(b), b.count, "after move %d", i+1)
+	}
+
+	t.Run("en passant", func(t *testing.T) {
+		b, err := ParseFen("4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 1")
+		assert.NoError(t, err)
+		b = b.MakeMove(Move{From: E5, To: D6})
+		assert.Equal(t, 0, b.Count(BP))
+		assert.Equal(t, 1, b.Count(WP))
+		assert.Equal(t, recount(b), b.count)
+	})
+
+	t.Run("promotion", func(t *testing.T) {
+		b, err := ParseFen("1r2k3/P7/8/8/8/8/8/4K3 w - - 0 1")
+		assert.NoError(t, err)
+		b = b.MakeMove(Move{From: A7, To: B8, Promotion: WQ})
+		assert.Equal(t, 0, b.Count(WP))
+		assert.Equal(t, 1, b.Count(WQ))
+		assert.Equal(t, 0, b.Count(BR))
+		assert.Equal(t, 900, b.Material(White))
+		assert.Equal(t, 0, b.Material(Black))
+		assert.Equal(t, recount(b), b.count)
+	})
+
+	t.Run("copy", func(t *testing.T) {
+		assert.Equal(t, b.count, b.Copy().count)
+	})
+}
@@ -0,0 +1,97 @@
+package internal
+
+var (
+	knightOffsets   = []int{-17, -15, -10, -6, 6, 10, 15, 17}
+	kingOffsets     = []int{-9, -8, -7, -1, 1, 7, 8, 9}
+	diagonalOffsets = []int{-9, -7, 7, 9}
+	straightOffsets = []int{-8, -1, 1, 8}
+)
+
+// Checkers returns the squares of the opponent's pieces giving check to the
+// king of the side to move, in ascending order: none when the king is not in
+// check, two in a double check, when only king moves are legal.
+func (b *Board) Checkers() []Sq {
+	king := b.find(b.my(King), A1, H8)
+	if king == NoSquare {
+		return nil
+	}
+	return attackers(&b.Piece, king, b.SideToMove^1)
+}
+
+// attackers returns the squares of the pieces of color attacking sq, in
+// ascending order.
+func attackers(pieces *[64]Piece, sq Sq, color int) []Sq {
+	var found []Sq
+	add := func(from Sq, types ...int) {
+		if from == NoSquare || pieces[from] == NoPiece || pieces[from].Color() != color {
+			return
+		}
+		for _, t := range types {
+			if pieces[from].Type() == t {
+				found = append(found, from)
+				return
+			}
+		}
+	}
+
+	// pawns attack from the rank behind them
+	for _, offset := range [2][2]int{{-7, -9}, {7, 9}}[color] {
+		add(sq.step(offset), Pawn)
+	}
+	for _, offset := range knightOffsets {
+		add(sq.step(offset), Knight)
+	}
+	for _, offset := range kingOffsets {
+		add(sq.step(offset), King)
+	}
+	for _, offset := range diagonalOffsets {
+		add(firstPiece(pieces, sq, offset), Bishop, Queen)
+	}
+	for _, offset := range straightOffsets {
+		add(firstPiece(pieces, sq, offset), Rook, Queen)
+	}
+
+	// insertion sort: there are few attackers
+	for i := 1; i < len(found); i++ {
+		for j := i; j > 0 && found[j] < found[j-1]; j-- {
+			found[j], found[j-1] = found[j-1], found[j]
+		}
+	}
+	return found
+}
+
+// firstPiece returns the square of the first piece along the ray from sq in
+// the direction of offset, or NoSquare if the ray reaches the edge.
+func firstPiece(pieces *[64]Piece, sq Sq, offset int) Sq {
+	for to := sq.step(offset); to != NoSquare; to = to.step(offset) {
+		if pieces[to] != NoPiece {
+			return to
+		}
+	}
+	return NoSquare
+}
+
+// pinned returns which pieces of the side to move are pinned to their king:
+// moving them off the line between the king and an opponent's slider would
+// expose the king.
+func (b *Board) pinned() (pins [64]bool) {
+	king := b.find(b.my(King), A1, H8)
+	if king == NoSquare {
+		return
+	}
+	for _, offset := range kingOffsets {
+		slider := b.opp(Rook)
+		if offset == -9 || offset == -7 || offset == 7 || offset == 9 {
+			slider = b.opp(Bishop)
+		}
+		first := firstPiece(&b.Piece, king, offset)
+		if first == NoSquare || b.Piece[first].Color() != b.SideToMove {
+			continue
+		}
+		second := firstPiece(&b.Piece, first, offset)
+		if second != NoSquare && (b.Piece[second] == slider || b.Piece[second] == b.opp(Queen)) {
+			pins[first] = true
+		}
+	}
+	return
+}
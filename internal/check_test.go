@@ -0,0 +1,90 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckers(t *testing.T) {
+	tests := []struct {
+		name string
+		fen  string
+		want []Sq
+	}{
+		{"no check", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", nil},
+		{"pawn", "4k3/8/8/8/8/8/3p4/4K3 w - - 0 1", []Sq{D2}},
+		{"knight", "4k3/8/8/8/8/5n2/8/4K3 w - - 0 1", []Sq{F3}},
+		{"blocked rook", "4k3/4r3/8/8/8/8/4P3/4K3 w - - 0 1", nil},
+		{"queen on a diagonal", "4k3/8/8/8/Q7/8/8/4K3 b - - 0 1", []Sq{A4}},
+		{"knight and rook", "4kr2/8/5N2/8/1b6/8/8/4R1K1 b - - 0 1", []Sq{E1, F6}},
+		{"bishop and rook", "3qk3/8/8/8/1b6/8/2N5/r3K2R w K - 0 1", []Sq{A1, B4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := ParseFen(tt.fen)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, b.Checkers())
+		})
+	}
+}
+
+func TestLegalMovesDoubleCheck(t *testing.T) {
+	// The rook and the bishop could each capture a checker, but in a double
+	// check only the king can move
+	b, err := ParseFen("4kr2/8/5N2/8/1b6/8/8/4R1K1 b - - 0 1")
+	require.NoError(t, err)
+	require.Len(t, b.Checkers(), 2)
+
+	var moves []string
+	for _, m := range b.LegalMoves() {
+		assert.Equal(t, Piece(BK), b.Piece[m.From], "non-king move %s", m.San(b))
+		moves = append(moves, m.San(b))
+	}
+	assert.ElementsMatch(t, []string{"Kd8", "Kf7"}, moves)
+}
+
+func TestLegalMovesPins(t *testing.T) {
+	tests := []struct {
+		name string
+		fen  string
+		want []string
+	}{
+		{
+			name: "pinned knight cannot move, pinned rook moves along the pin",
+			fen:  "4r2k/8/8/8/1b6/8/3NR3/4K3 w - - 0 1",
+			want: []string{"Kd1", "Kf1", "Kf2", "Re3", "Re4", "Re5", "Re6", "Re7", "Rxe8+"},
+		},
+		{
+			name: "pinned bishop captures the pinning queen",
+			fen:  "7k/8/8/3q4/8/8/6B1/7K w - - 0 1",
+			want: []string{"Kg1", "Kh2", "Bf3", "Be4", "Bxd5"},
+		},
+		{
+			name: "en passant capture exposing the king",
+			fen:  "8/8/8/8/k2Pp2Q/8/8/3K4 b - d3 0 1",
+			want: []string{"Ka3", "Kb3", "Kb4", "Ka5", "Kb5", "e3"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := ParseFen(tt.fen)
+			require.NoError(t, err)
+			var moves []string
+			for _, m := range b.LegalMoves() {
+				moves = append(moves, m.San(b))
+			}
+			assert.ElementsMatch(t, tt.want, moves)
+		})
+	}
+}
+
+func TestPinned(t *testing.T) {
+	b, err := ParseFen("4r2k/8/8/8/1b6/8/3NR3/4K3 w - - 0 1")
+	require.NoError(t, err)
+	pins := b.pinned()
+	for sq := A1; sq <= H8; sq++ {
+		assert.Equal(t, sq == D2 || sq == E2, pins[sq], "square %s", sq)
+	}
+}
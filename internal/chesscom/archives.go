@@ -0,0 +1,114 @@
+package chesscom
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ArchiveSummary describes the games of one monthly archive.
+type ArchiveSummary struct {
+	URL         string         `json:"url"`
+	Year        int            `json:"year"`
+	Month       int            `json:"month"`
+	Games       int            `json:"games"`
+	First       time.Time      `json:"first,omitzero"` // End time of the first game
+	Last        time.Time      `json:"last,omitzero"`  // End time of the last game
+	TimeClasses map[string]int `json:"time_classes"`   // Games per Chess.com time class
+}
+
+// SummarizeArchive counts the games of a monthly archive per time class and
+// finds the dates of the first and last of them.
+func SummarizeArchive(url string, year, month int, games []Game) ArchiveSummary {
+	summary := ArchiveSummary{URL: url, Year: year, Month: month, Games: len(games), TimeClasses: make(map[string]int)}
+	for _, g := range games {
+		if g.TimeClass != "" {
+			summary.TimeClasses[g.TimeClass]++
+		}
+		if g.EndTime == 0 {
+			continue
+		}
+		end := g.GetEndTime()
+		if summary.First.IsZero() || end.Before(summary.First) {
+			summary.First = end
+		}
+		if end.After(summary.Last) {
+			summary.Last = end
+		}
+	}
+	return summary
+}
+
+// Complete reports whether the month of the archive is over as of now, so
+// that no game will be added to it and its summary can be kept.
+func (s ArchiveSummary) Complete(now time.Time) bool {
+	next := time.Date(s.Year, time.Month(s.Month)+1, 1, 0, 0, 0, 0, time.UTC)
+	return !now.Before(next)
+}
+
+// ArchiveCache keeps the summaries of the archives of months that are over,
+// which never change, in a JSON file.
+type ArchiveCache struct {
+	path      string
+	summaries map[string]ArchiveSummary // by lowercase username and month, as in alice/2024-01
+	changed   bool
+}
+
+// LoadArchiveCache reads the cache file at path, which may not exist yet.
+func LoadArchiveCache(path string) (*ArchiveCache, error) {
+	cache := &ArchiveCache{path: path, summaries: make(map[string]ArchiveSummary)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cache, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive cache: %w", err)
+	}
+	if err := json.Unmarshal(data, &cache.summaries); err != nil {
+		return nil, fmt.Errorf("failed to parse archive cache: %w", err)
+	}
+	return cache, nil
+}
+
+// archiveKey returns the key of a player's month in the cache
+func archiveKey(username string, year, month int) string {
+	return fmt.Sprintf("%s/%d-%02d", strings.ToLower(username), year, month)
+}
+
+// Get returns the cached summary of a player's month, if there is one.
+func (c *ArchiveCache) Get(username string, year, month int) (ArchiveSummary, bool) {
+	summary, ok := c.summaries[archiveKey(username, year, month)]
+	return summary, ok
+}
+
+// Put caches the summary of a player's month if the month is over.
+func (c *ArchiveCache) Put(username string, summary ArchiveSummary) {
+	if !summary.Complete(time.Now()) {
+		return
+	}
+	c.summaries[archiveKey(username, summary.Year, summary.Month)] = summary
+	c.changed = true
+}
+
+// Save writes the cache file if summaries were added.
+func (c *ArchiveCache) Save() error {
+	if !c.changed {
+		return nil
+	}
+	data, err := json.MarshalIndent(c.summaries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal archive cache: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return fmt.Errorf("failed to create archive cache directory: %w", err)
+	}
+	if err := os.WriteFile(c.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write archive cache: %w", err)
+	}
+	c.changed = false
+	return nil
+}
@@ -0,0 +1,69 @@
+package chesscom
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kyleboon/gochess/internal/logging"
+)
+
+func TestSummarizeArchive_Fixtures(t *testing.T) {
+	useFixtures(t)
+	client := NewClientWithLogger(logging.Discard())
+
+	games, err := client.GetPlayerGames(context.Background(), fixtureUser, 2024, 2)
+	if err != nil {
+		t.Fatalf("GetPlayerGames failed: %v", err)
+	}
+	summary := SummarizeArchive("https://api.chess.com/pub/player/erik/games/2024/02", 2024, 2, games.Games)
+	if summary.Games != len(games.Games) || summary.Games == 0 {
+		t.Fatalf("expected %d games, got %d", len(games.Games), summary.Games)
+	}
+	total := 0
+	for _, n := range summary.TimeClasses {
+		total += n
+	}
+	if total != summary.Games {
+		t.Errorf("expected the time classes to count %d games, got %v", summary.Games, summary.TimeClasses)
+	}
+	if summary.First.IsZero() || summary.Last.Before(summary.First) {
+		t.Errorf("unexpected dates %v - %v", summary.First, summary.Last)
+	}
+	if summary.First.Year() != 2024 || summary.First.Month() != time.February {
+		t.Errorf("expected games of February 2024, got %v", summary.First)
+	}
+}
+
+func TestArchiveCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache", "archives.json")
+	cache, err := LoadArchiveCache(path)
+	if err != nil {
+		t.Fatalf("LoadArchiveCache failed: %v", err)
+	}
+
+	past := ArchiveSummary{Year: 2024, Month: 2, Games: 3, TimeClasses: map[string]int{"blitz": 3}}
+	now := time.Now()
+	current := ArchiveSummary{Year: now.Year(), Month: int(now.Month()), Games: 1}
+	cache.Put("Alice", past)
+	cache.Put("Alice", current)
+	if err := cache.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	cache, err = LoadArchiveCache(path)
+	if err != nil {
+		t.Fatalf("LoadArchiveCache failed: %v", err)
+	}
+	got, ok := cache.Get("alice", 2024, 2)
+	if !ok || got.Games != 3 || got.TimeClasses["blitz"] != 3 {
+		t.Errorf("expected the summary of 2024-02, got %+v (%v)", got, ok)
+	}
+	if _, ok := cache.Get("alice", current.Year, current.Month); ok {
+		t.Error("expected the current month not to be cached")
+	}
+	if _, ok := cache.Get("bob", 2024, 2); ok {
+		t.Error("expected no summary for another player")
+	}
+}
@@ -3,14 +3,19 @@ package chesscom
 import (
 	"context"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/kyleboon/gochess/internal/config"
 	"github.com/kyleboon/gochess/internal/db"
+	"github.com/kyleboon/gochess/internal/output"
+	"github.com/kyleboon/gochess/internal/progress"
 	"github.com/urfave/cli/v2"
 )
 
@@ -29,43 +34,164 @@ func expandPath(path string) string {
 	return path
 }
 
+// downloadSummary is the --json output of the download command
+type downloadSummary struct {
+	Username   string `json:"username"`
+	Months     int    `json:"months"`
+	Skipped    int    `json:"skipped_months,omitempty"`
+	Imported   int    `json:"imported"`
+	TotalGames int    `json:"total_games,omitempty"`
+	Output     string `json:"output,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
 // ListArchives lists available archives for a Chess.com user
 func ListArchives(c *cli.Context) error {
-	username := c.String("username")
+	username, err := config.Username(c, "chesscom")
+	if err != nil {
+		return err
+	}
 	client := NewClient()
+	out := output.Messages(c)
 
-	fmt.Printf("Fetching available archives for %s...\n", username)
+	fmt.Fprintf(out, "Fetching available archives for %s...\n", username)
 
 	archives, err := client.GetArchivedMonths(c.Context, username)
 	if err != nil {
 		return fmt.Errorf("failed to fetch archives: %w", err)
 	}
+	if c.Bool("details") {
+		return listArchiveDetails(c, client, username, archives.Archives)
+	}
+	if output.JSON(c) {
+		return output.WriteJSON(archives)
+	}
 
-	fmt.Printf("Available archives for %s:\n", username)
+	fmt.Fprintf(out, "Available archives for %s:\n", username)
 	for _, archive := range archives.Archives {
-		fmt.Println(archive)
+		fmt.Fprintln(out, archive)
 	}
 
 	return nil
 }
 
+// listArchiveDetails prints a table of the archives with their game counts,
+// dates and time classes, fetching the games of each month not cached
+func listArchiveDetails(c *cli.Context, client *Client, username string, archiveURLs []string) error {
+	out := output.Messages(c)
+	var cache *ArchiveCache
+	if !c.Bool("no-cache") {
+		cachePath, err := config.DefaultArchiveCachePath()
+		if err != nil {
+			return err
+		}
+		if cache, err = LoadArchiveCache(cachePath); err != nil {
+			return err
+		}
+	}
+
+	summaries := make([]ArchiveSummary, 0, len(archiveURLs))
+	bar := progress.ForOutput(out, len(archiveURLs), "months")
+	out = bar.Writer(out)
+	for i, archiveURL := range archiveURLs {
+		bar.Set(i, len(archiveURLs))
+		parts := strings.Split(archiveURL, "/")
+		if len(parts) < 2 {
+			continue
+		}
+		year, err := parseArchiveYear(parts[len(parts)-2])
+		if err != nil {
+			fmt.Fprintf(out, "Warning: Could not parse year from archive URL %s: %v\n", archiveURL, err)
+			continue
+		}
+		month, err := parseArchiveMonth(parts[len(parts)-1])
+		if err != nil {
+			fmt.Fprintf(out, "Warning: Could not parse month from archive URL %s: %v\n", archiveURL, err)
+			continue
+		}
+
+		if cache != nil {
+			if summary, ok := cache.Get(username, year, month); ok {
+				summaries = append(summaries, summary)
+				continue
+			}
+		}
+		games, err := client.GetPlayerGames(c.Context, username, year, month)
+		if err != nil {
+			bar.Finish()
+			return fmt.Errorf("failed to fetch games for %d/%02d: %w", year, month, err)
+		}
+		summary := SummarizeArchive(archiveURL, year, month, games.Games)
+		if cache != nil {
+			cache.Put(username, summary)
+		}
+		summaries = append(summaries, summary)
+	}
+	bar.Set(len(archiveURLs), len(archiveURLs))
+	bar.Finish()
+	if cache != nil {
+		if err := cache.Save(); err != nil {
+			return err
+		}
+	}
+
+	if output.JSON(c) {
+		return output.WriteJSON(map[string]interface{}{"username": username, "archives": summaries})
+	}
+
+	total := 0
+	fmt.Printf("Archives of %s:\n", username)
+	fmt.Printf("  %-8s %-6s %-12s %-12s %s\n", "MONTH", "GAMES", "FIRST", "LAST", "TIME CLASSES")
+	for _, s := range summaries {
+		first, last := "-", "-"
+		if !s.First.IsZero() {
+			first, last = s.First.Format("2006-01-02"), s.Last.Format("2006-01-02")
+		}
+		fmt.Printf("  %d-%02d  %-6d %-12s %-12s %s\n", s.Year, s.Month, s.Games, first, last, formatTimeClasses(s.TimeClasses))
+		total += s.Games
+	}
+	fmt.Printf("\n%d games in %d months\n", total, len(summaries))
+	return nil
+}
+
+// formatTimeClasses lists the games per time class from fastest to
+// slowest, as in "blitz 12, rapid 3"
+func formatTimeClasses(counts map[string]int) string {
+	var parts []string
+	for _, class := range db.TimeClasses {
+		if counts[class] > 0 {
+			parts = append(parts, fmt.Sprintf("%s %d", class, counts[class]))
+		}
+	}
+	var others []string
+	for class, n := range counts {
+		if !slices.Contains(db.TimeClasses, class) {
+			others = append(others, fmt.Sprintf("%s %d", class, n))
+		}
+	}
+	sort.Strings(others)
+	return strings.Join(append(parts, others...), ", ")
+}
+
 // downloadAndImportMonthlyGames downloads and optionally imports games for a specific month
 // If externalDB is provided, it will be used instead of opening a new connection
-// If logger is provided, it will be used for logging
-func downloadAndImportMonthlyGames(ctx context.Context, username string, year, month int, format, output, dbPath string, importDB, verbose bool, externalDB *db.DB, logger *slog.Logger) (int, error) {
+// If logger is provided, it will be used for logging. Progress messages are
+// written to out
+func downloadAndImportMonthlyGames(ctx context.Context, out io.Writer, username string, year, month int, format, outputPath, dbPath string, importDB, verbose bool, externalDB *db.DB, logger *slog.Logger) (int, error) {
 	var client *Client
 	if logger != nil {
 		client = NewClientWithLogger(logger)
 	} else {
 		client = NewClient()
 	}
-	fmt.Printf("Fetching games for %s (%d/%02d)...\n", username, year, month)
+	fmt.Fprintf(out, "Fetching games for %s (%d/%02d)...\n", username, year, month)
 
-	// Get the PGN data
-	pgn, err := client.GetPlayerGamesPGN(ctx, username, year, month)
+	// Stream the PGN rather than holding the whole month in memory
+	pgnData, err := client.OpenPlayerGamesPGN(ctx, username, year, month)
 	if err != nil {
 		return 0, fmt.Errorf("failed to fetch PGN for %d/%02d: %w", year, month, err)
 	}
+	defer func() { _ = pgnData.Close() }()
 	
 	// If we're importing to DB
 	if importDB {
@@ -78,7 +204,7 @@ func downloadAndImportMonthlyGames(ctx context.Context, username string, year, m
 		defer func() { _ = os.Remove(tmpPath) }() // Clean up
 
 		// Write PGN to temporary file
-		if _, err := tmpfile.WriteString(pgn); err != nil {
+		if _, err := io.Copy(tmpfile, pgnData); err != nil {
 			_ = tmpfile.Close()
 			return 0, fmt.Errorf("failed to write to temporary file: %w", err)
 		}
@@ -107,20 +233,21 @@ func downloadAndImportMonthlyGames(ctx context.Context, username string, year, m
 			return 0, fmt.Errorf("no database connection available")
 		}
 
-		// Import the PGN file
-		count, errors := database.ImportPGN(ctx, tmpPath)
+		// Import the PGN file, recording the account the games are from
+		result := database.ImportPGNWithOptions(ctx, tmpPath, db.ImportOptions{Tags: db.SourceAccountTags("chesscom", username)})
+		count, errors := result.Imported, result.Errors
 
 		// Print import results
 		if len(errors) > 0 && verbose {
-			fmt.Printf("Encountered %d errors during import of %d/%02d:\n", len(errors), year, month)
+			fmt.Fprintf(out, "Encountered %d errors during import of %d/%02d:\n", len(errors), year, month)
 			for i, err := range errors {
-				fmt.Printf("  Error %d: %s\n", i+1, err)
+				fmt.Fprintf(out, "  Error %d: %s\n", i+1, err)
 				if pgnErr, ok := err.(*db.PGNImportError); ok && pgnErr.PGNText != "" {
-					fmt.Printf("    PGN: %s\n", pgnErr.PGNText)
+					fmt.Fprintf(out, "    PGN: %s\n", pgnErr.PGNText)
 				}
 			}
 		} else if len(errors) > 0 {
-			fmt.Printf("Encountered %d errors during import of %d/%02d. Use --verbose to see details.\n", len(errors), year, month)
+			fmt.Fprintf(out, "Encountered %d errors during import of %d/%02d. Use --verbose to see details.\n", len(errors), year, month)
 		}
 
 		// Return the count of imported games
@@ -128,12 +255,12 @@ func downloadAndImportMonthlyGames(ctx context.Context, username string, year, m
 	}
 
 	// Handle non-import output
-	if output != "" {
+	if outputPath != "" {
 		// If we're writing to a file and not importing, create a month-specific file
-		monthlyOutput := output
-		if strings.Contains(output, "*") {
+		monthlyOutput := outputPath
+		if strings.Contains(outputPath, "*") {
 			// Replace * with year-month
-			monthlyOutput = strings.ReplaceAll(output, "*", fmt.Sprintf("%d-%02d", year, month))
+			monthlyOutput = strings.ReplaceAll(outputPath, "*", fmt.Sprintf("%d-%02d", year, month))
 		}
 		
 		outputFile, err := os.Create(monthlyOutput)
@@ -143,29 +270,84 @@ func downloadAndImportMonthlyGames(ctx context.Context, username string, year, m
 		defer func() { _ = outputFile.Close() }()
 		
 		// Write the PGN data
-		_, err = outputFile.WriteString(pgn)
+		_, err = io.Copy(outputFile, pgnData)
 		if err != nil {
 			return 0, fmt.Errorf("failed to write to output file: %w", err)
 		}
 		
-		fmt.Printf("Downloaded PGN games for %s (%d/%02d) to %s\n", username, year, month, monthlyOutput)
+		fmt.Fprintf(out, "Downloaded PGN games for %s (%d/%02d) to %s\n", username, year, month, monthlyOutput)
 	}
 	
 	return 0, nil
 }
 
-// DownloadGames downloads games for a Chess.com user
+// DownloadGames downloads games for one or more Chess.com users, one
+// after the other
 func DownloadGames(c *cli.Context) error {
-	username := c.String("username")
+	usernames, err := config.Usernames(c, "chesscom")
+	if err != nil {
+		return err
+	}
+	if output.JSON(c) && c.String("output") == "" && !c.Bool("import-db") {
+		return fmt.Errorf("--json needs --output or --import-db, as the games would be written to stdout")
+	}
+	out := output.Messages(c)
+
+	var summaries []downloadSummary
+	var failed []string
+	for _, username := range usernames {
+		outputPath, err := config.OutputForUsername(c.String("output"), username, len(usernames))
+		if err != nil {
+			return err
+		}
+		if len(usernames) > 1 {
+			fmt.Fprintf(out, "\n=== %s ===\n", username)
+		}
+		summary, err := downloadUserGames(c, username, outputPath)
+		if err != nil && len(usernames) == 1 {
+			return err
+		}
+		if err != nil {
+			// The other accounts are still downloaded
+			fmt.Fprintf(out, "Error downloading the games of %s: %v\n", username, err)
+			failed = append(failed, username)
+			summary = &downloadSummary{Username: username, Error: err.Error()}
+		}
+		summaries = append(summaries, *summary)
+	}
+
+	if output.JSON(c) {
+		var err error
+		if len(summaries) == 1 {
+			err = output.WriteJSON(summaries[0])
+		} else {
+			err = output.WriteJSON(map[string]interface{}{"accounts": summaries})
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to download the games of %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// downloadUserGames downloads the games of one Chess.com user, writing them
+// to outputPath or stdout and importing them with --import-db
+func downloadUserGames(c *cli.Context, username, outputPath string) (*downloadSummary, error) {
 	year := c.Int("year")
 	month := c.Int("month")
 	format := c.String("format")
-	output := c.String("output")
 	importDB := c.Bool("import-db")
-	dbPath := c.String("database")
+	dbPath, err := config.DatabasePath(c)
+	if err != nil {
+		return nil, err
+	}
 	verbose := c.Bool("verbose")
 	allHistory := c.Bool("all-history")
-
+	asJSON := output.JSON(c)
+	out := output.Messages(c)
 	client := NewClient()
 
 	// Expand database path
@@ -174,30 +356,34 @@ func DownloadGames(c *cli.Context) error {
 	// Handle downloading all historical games
 	if allHistory {
 		// Fetch all available archives
-		fmt.Printf("Fetching available archives for %s...\n", username)
+		fmt.Fprintf(out, "Fetching available archives for %s...\n", username)
 		archives, err := client.GetArchivedMonths(c.Context, username)
 		if err != nil {
-			return fmt.Errorf("failed to fetch archives: %w", err)
+			return nil, fmt.Errorf("failed to fetch archives: %w", err)
 		}
 
-		fmt.Printf("Found %d months of archives for %s\n", len(archives.Archives), username)
+		fmt.Fprintf(out, "Found %d months of archives for %s\n", len(archives.Archives), username)
 		
 		// Open database once if importing
 		var database *db.DB
 		if importDB {
-			fmt.Printf("Opening database at %s...\n", dbPath)
+			fmt.Fprintf(out, "Opening database at %s...\n", dbPath)
 			database, err = db.New(dbPath)
 			if err != nil {
-				return fmt.Errorf("failed to open database: %w", err)
+				return nil, fmt.Errorf("failed to open database: %w", err)
 			}
 			defer func() { _ = database.Close() }()
 		}
 		
 		totalGames := 0
 		skippedMonths := 0
+		bar := progress.ForOutput(out, len(archives.Archives), "months")
+		out = bar.Writer(out)
 		
 		// Process each archive
 		for i, archiveURL := range archives.Archives {
+			bar.Set(i, len(archives.Archives))
+
 			// Extract year and month from the URL
 			// Format is https://api.chess.com/pub/player/{username}/games/{year}/{month}
 			parts := strings.Split(archiveURL, "/")
@@ -207,28 +393,29 @@ func DownloadGames(c *cli.Context) error {
 			
 			archiveYear, err := parseArchiveYear(parts[len(parts)-2])
 			if err != nil {
-				fmt.Printf("Warning: Could not parse year from archive URL %s: %v\n", archiveURL, err)
+				fmt.Fprintf(out, "Warning: Could not parse year from archive URL %s: %v\n", archiveURL, err)
 				skippedMonths++
 				continue
 			}
 			
 			archiveMonth, err := parseArchiveMonth(parts[len(parts)-1])
 			if err != nil {
-				fmt.Printf("Warning: Could not parse month from archive URL %s: %v\n", archiveURL, err)
+				fmt.Fprintf(out, "Warning: Could not parse month from archive URL %s: %v\n", archiveURL, err)
 				skippedMonths++
 				continue
 			}
 			
-			fmt.Printf("\nProcessing archive %d/%d: %d/%02d\n", i+1, len(archives.Archives), archiveYear, archiveMonth)
+			fmt.Fprintf(out, "\nProcessing archive %d/%d: %d/%02d\n", i+1, len(archives.Archives), archiveYear, archiveMonth)
 			
 			// Download and process games for this month
 			monthlyGames, err := downloadAndImportMonthlyGames(
 				c.Context,
+				out,
 				username,
 				archiveYear,
 				archiveMonth,
 				format,
-				output,
+				outputPath,
 				"", // Empty dbPath because we already opened the database
 				importDB,
 				verbose,
@@ -236,8 +423,13 @@ func DownloadGames(c *cli.Context) error {
 				nil,      // No logger available in this context
 			)
 			
+			if IsAccountError(err) {
+				// The other months would fail the same way
+				bar.Finish()
+				return nil, err
+			}
 			if err != nil {
-				fmt.Printf("Error processing %d/%02d: %v\n", archiveYear, archiveMonth, err)
+				fmt.Fprintf(out, "Error processing %d/%02d: %v\n", archiveYear, archiveMonth, err)
 				skippedMonths++
 				continue
 			}
@@ -245,36 +437,49 @@ func DownloadGames(c *cli.Context) error {
 			totalGames += monthlyGames
 		}
 		
+		bar.Set(len(archives.Archives), len(archives.Archives))
+		bar.Finish()
+
 		// Summary
-		fmt.Printf("\n====== DOWNLOAD SUMMARY ======\n")
-		fmt.Printf("Total archives processed: %d\n", len(archives.Archives) - skippedMonths)
+		fmt.Fprintf(out, "\n====== DOWNLOAD SUMMARY ======\n")
+		fmt.Fprintf(out, "Total archives processed: %d\n", len(archives.Archives) - skippedMonths)
 		if skippedMonths > 0 {
-			fmt.Printf("Skipped archives: %d\n", skippedMonths)
+			fmt.Fprintf(out, "Skipped archives: %d\n", skippedMonths)
 		}
 		
+		summary := downloadSummary{
+			Username: username,
+			Months:   len(archives.Archives) - skippedMonths,
+			Skipped:  skippedMonths,
+			Output:   outputPath,
+		}
 		if importDB {
-			fmt.Printf("Total games imported: %d\n", totalGames)
+			fmt.Fprintf(out, "Total games imported: %d\n", totalGames)
+			summary.Imported = totalGames
 			
 			// Get current game count in database
 			currentCount, err := database.GetGameCount(c.Context)
 			if err == nil {
-				fmt.Printf("Total games in database: %d\n", currentCount)
+				fmt.Fprintf(out, "Total games in database: %d\n", currentCount)
+				summary.TotalGames = currentCount
 			}
 		}
 		
-		return nil
+		return &summary, nil
 	}
 	
 	// Handle regular single-month download
+	summary := downloadSummary{Username: username, Months: 1, Output: outputPath}
 	if importDB {
 		// Use our reusable function to handle the download and import
 		count, err := downloadAndImportMonthlyGames(
 			c.Context,
+			out,
 			username,
 			year,
 			month,
 			format,
-			output,
+			outputPath,
 			dbPath,
 			importDB,
 			verbose,
@@ -283,53 +488,63 @@ func DownloadGames(c *cli.Context) error {
 		)
 		
 		if err != nil {
-			return fmt.Errorf("failed to download and import games: %w", err)
+			return nil, fmt.Errorf("failed to download and import games: %w", err)
 		}
 		
 		// Print success message
-		fmt.Printf("Successfully imported %d games from Chess.com\n", count)
+		fmt.Fprintf(out, "Successfully imported %d games from Chess.com\n", count)
+		summary.Imported = count
 
 		// If the user still wants to output to a file or stdout, we'll do that too
-		if output != "" || format != "pgn" {
+		if outputPath != "" || format != "pgn" {
 			// Continue with the normal download operation
-			fmt.Println("\nAdditionally processing requested output format...")
+			fmt.Fprintln(out, "\nAdditionally processing requested output format...")
+		} else if asJSON {
+			return &summary, nil
 		} else {
 			// Otherwise we're done
-			return nil
+			return &summary, nil
 		}
 	}
 
 	// Handle output to file or stdout as before
 	var outputWriter *os.File
-	if output == "" {
+	if outputPath == "" {
+		if asJSON {
+			return nil, fmt.Errorf("--json needs --output, as the games would be written to stdout")
+		}
 		outputWriter = os.Stdout
 	} else {
 		var err error
-		outputWriter, err = os.Create(output)
+		outputWriter, err = os.Create(outputPath)
 		if err != nil {
-			return fmt.Errorf("failed to create output file: %w", err)
+			return nil, fmt.Errorf("failed to create output file: %w", err)
 		}
 		defer func() { _ = outputWriter.Close() }()
 	}
 
 	switch format {
 	case "pgn":
-		pgn, err := client.GetPlayerGamesPGN(c.Context, username, year, month)
+		// Write the games as they arrive rather than holding the month
+		count, err := client.StreamPlayerGamesPGN(c.Context, username, year, month, func(game string) error {
+			_, err := io.WriteString(outputWriter, game)
+			return err
+		})
 		if err != nil {
-			return fmt.Errorf("failed to fetch PGN: %w", err)
+			return nil, fmt.Errorf("failed to fetch PGN: %w", err)
 		}
 
-		_, _ = fmt.Fprintln(outputWriter, pgn)
+		_, _ = fmt.Fprintln(outputWriter)
 
-		if output != "" {
-			fmt.Printf("Downloaded PGN games for %s (%d/%02d) to %s\n",
-				username, year, month, output)
+		if outputPath != "" {
+			fmt.Fprintf(out, "Downloaded %d PGN games for %s (%d/%02d) to %s\n",
+				count, username, year, month, outputPath)
 		}
 
 	case "json":
 		games, err := client.GetPlayerGames(c.Context, username, year, month)
 		if err != nil {
-			return fmt.Errorf("failed to fetch games: %w", err)
+			return nil, fmt.Errorf("failed to fetch games: %w", err)
 		}
 
 		// Just output the raw JSON for now
@@ -345,15 +560,15 @@ func DownloadGames(c *cli.Context) error {
 			_, _ = fmt.Fprintf(outputWriter, "  PGN: %s\n\n", game.PGN)
 		}
 
-		if output != "" {
-			fmt.Printf("Downloaded %d games for %s (%d/%02d) to %s\n",
-				len(games.Games), username, year, month, output)
+		if outputPath != "" {
+			fmt.Fprintf(out, "Downloaded %d games for %s (%d/%02d) to %s\n",
+				len(games.Games), username, year, month, outputPath)
 		}
 
 	case "summary":
 		games, err := client.GetPlayerGames(c.Context, username, year, month)
 		if err != nil {
-			return fmt.Errorf("failed to fetch games: %w", err)
+			return nil, fmt.Errorf("failed to fetch games: %w", err)
 		}
 
 		_, _ = fmt.Fprintf(outputWriter, "Games for %s (%d/%02d):\n", username, year, month)
@@ -367,16 +582,16 @@ func DownloadGames(c *cli.Context) error {
 			_, _ = fmt.Fprintf(outputWriter, "  Date: %s\n\n", game.GetEndTime().Format("2006-01-02"))
 		}
 
-		if output != "" {
-			fmt.Printf("Downloaded summary of %d games for %s (%d/%02d) to %s\n",
-				len(games.Games), username, year, month, output)
+		if outputPath != "" {
+			fmt.Fprintf(out, "Downloaded summary of %d games for %s (%d/%02d) to %s\n",
+				len(games.Games), username, year, month, outputPath)
 		}
 
 	default:
-		return fmt.Errorf("unknown format %q, supported formats: pgn, json, summary", format)
+		return nil, fmt.Errorf("unknown format %q, supported formats: pgn, json, summary", format)
 	}
 
-	return nil
+	return &summary, nil
 }
 
 // Helper functions for parsing archive URLs
@@ -401,18 +616,14 @@ func ConvertToDatabase(ctx context.Context, username string, year, month int) (*
 	return client.GetPlayerGames(ctx, username, year, month)
 }
 
-// ImportFromConfig imports games using configuration settings
-// If lastImport is non-zero, only games from months since that time are imported
-func ImportFromConfig(ctx context.Context, cfg *config.Config, database *db.DB, logger *slog.Logger, verbose bool) (int, error) {
-	if cfg.ChessCom == nil || cfg.ChessCom.Username == "" {
-		return 0, fmt.Errorf("no Chess.com user configured")
-	}
-
-	username := cfg.ChessCom.Username
+// ImportFromConfig imports the games of username, one of the accounts
+// configured in cfg. If it was imported before, only games from months since
+// the last import are imported
+func ImportFromConfig(ctx context.Context, cfg *config.Config, username string, database *db.DB, logger *slog.Logger, out io.Writer, verbose bool) (int, error) {
 	client := NewClientWithLogger(logger)
 
 	// Fetch all available archives
-	fmt.Printf("Fetching available archives for %s on Chess.com...\n", username)
+	fmt.Fprintf(out, "Fetching available archives for %s on Chess.com...\n", username)
 	archives, err := client.GetArchivedMonths(ctx, username)
 	if err != nil {
 		return 0, fmt.Errorf("failed to fetch archives: %w", err)
@@ -423,9 +634,13 @@ func ImportFromConfig(ctx context.Context, cfg *config.Config, database *db.DB,
 
 	totalGames := 0
 	processedMonths := 0
+	bar := progress.ForOutput(out, len(archives.Archives), "months")
+	out = bar.Writer(out)
 
 	// Process each archive
-	for _, archiveURL := range archives.Archives {
+	for i, archiveURL := range archives.Archives {
+		bar.Set(i, len(archives.Archives))
+
 		// Extract year and month from the URL
 		parts := strings.Split(archiveURL, "/")
 		if len(parts) < 2 {
@@ -434,13 +649,13 @@ func ImportFromConfig(ctx context.Context, cfg *config.Config, database *db.DB,
 
 		archiveYear, err := parseArchiveYear(parts[len(parts)-2])
 		if err != nil {
-			fmt.Printf("Warning: Could not parse year from archive URL %s: %v\n", archiveURL, err)
+			fmt.Fprintf(out, "Warning: Could not parse year from archive URL %s: %v\n", archiveURL, err)
 			continue
 		}
 
 		archiveMonth, err := parseArchiveMonth(parts[len(parts)-1])
 		if err != nil {
-			fmt.Printf("Warning: Could not parse month from archive URL %s: %v\n", archiveURL, err)
+			fmt.Fprintf(out, "Warning: Could not parse month from archive URL %s: %v\n", archiveURL, err)
 			continue
 		}
 
@@ -454,14 +669,15 @@ func ImportFromConfig(ctx context.Context, cfg *config.Config, database *db.DB,
 		}
 
 		if hasLastImport && processedMonths == 0 {
-			fmt.Printf("Fetching Chess.com games for %s since %s...\n", username, lastImport.Format("2006-01-02 15:04:05"))
+			fmt.Fprintf(out, "Fetching Chess.com games for %s since %s...\n", username, lastImport.Format("2006-01-02 15:04:05"))
 		} else if !hasLastImport && processedMonths == 0 {
-			fmt.Printf("Fetching all Chess.com games for %s...\n", username)
+			fmt.Fprintf(out, "Fetching all Chess.com games for %s...\n", username)
 		}
 
 		// Download and process games for this month
 		monthlyGames, err := downloadAndImportMonthlyGames(
 			ctx,
+			out,
 			username,
 			archiveYear,
 			archiveMonth,
@@ -474,8 +690,13 @@ func ImportFromConfig(ctx context.Context, cfg *config.Config, database *db.DB,
 			logger,
 		)
 
+		if IsAccountError(err) {
+			// The other months would fail the same way
+			bar.Finish()
+			return totalGames, err
+		}
 		if err != nil {
-			fmt.Printf("Error processing %d/%02d: %v\n", archiveYear, archiveMonth, err)
+			fmt.Fprintf(out, "Error processing %d/%02d: %v\n", archiveYear, archiveMonth, err)
 			continue
 		}
 
@@ -483,13 +704,16 @@ func ImportFromConfig(ctx context.Context, cfg *config.Config, database *db.DB,
 		processedMonths++
 	}
 
+	bar.Set(len(archives.Archives), len(archives.Archives))
+	bar.Finish()
+
 	if processedMonths == 0 {
-		fmt.Printf("No new games found for %s on Chess.com\n", username)
+		fmt.Fprintf(out, "No new games found for %s on Chess.com\n", username)
 		return 0, nil
 	}
 
 	if totalGames > 0 {
-		fmt.Printf("Successfully imported %d games from Chess.com\n", totalGames)
+		fmt.Fprintf(out, "Successfully imported %d games from Chess.com\n", totalGames)
 		// Update last import time
 		cfg.SetLastImport("chesscom", username, time.Now())
 		if err := cfg.SaveDefault(); err != nil {
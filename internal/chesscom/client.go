@@ -19,9 +19,12 @@ import (
 	"io"
 	"log/slog"
 	"net/http"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/kyleboon/gochess/internal/logging"
+	"github.com/kyleboon/gochess/internal/pgn"
 )
 
 const (
@@ -72,15 +75,31 @@ func NewClient() *Client {
 }
 
 // NewClientWithLogger creates a new Chess.com API client with a custom logger.
+//
+// The base URL is taken from BaseURLEnv when set, and with RecordEnv set to
+// 1 every successful response is recorded into RecordDirEnv's directory.
 func NewClientWithLogger(logger *slog.Logger) *Client {
-	return &Client{
+	c := &Client{
 		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout:       30 * time.Second,
+			CheckRedirect: checkRedirect,
 		},
-		logger:      logger,
+		logger:      logger.With("component", "chesscom"),
 		retryConfig: DefaultRetryConfig(),
 		baseURL:     baseURL,
 	}
+	if url := os.Getenv(BaseURLEnv); url != "" {
+		c.baseURL = strings.TrimSuffix(url, "/")
+	}
+	if os.Getenv(RecordEnv) == "1" {
+		dir := os.Getenv(RecordDirEnv)
+		if dir == "" {
+			dir = DefaultFixtureDir
+		}
+		c.logger.Info("recording responses", "dir", dir)
+		c.httpClient.Transport = NewRecorder(dir, nil)
+	}
+	return c
 }
 
 // SetRetryConfig sets custom retry configuration for the client.
@@ -88,6 +107,42 @@ func (c *Client) SetRetryConfig(config RetryConfig) {
 	c.retryConfig = config
 }
 
+// SetBaseURL sets the base URL of the API, https://api.chess.com/pub by
+// default, as for an httptest server serving a FixtureHandler.
+func (c *Client) SetBaseURL(url string) {
+	c.baseURL = strings.TrimSuffix(url, "/")
+}
+
+// SetHTTPClient sets the HTTP client requests are made with, as for one
+// with a custom transport. Requests are made with a copy of it that follows
+// redirects as the default client does, whatever its CheckRedirect, so that
+// renamed accounts are still reported as a RedirectedError.
+func (c *Client) SetHTTPClient(httpClient *http.Client) {
+	client := *httpClient
+	client.CheckRedirect = checkRedirect
+	c.httpClient = &client
+}
+
+// maxRedirects is the number of redirects followed for a request, as by
+// the net/http default.
+const maxRedirects = 10
+
+// checkRedirect is the CheckRedirect of the client's HTTP client. Chess.com
+// redirects the requests for renamed accounts, which are reported as a
+// RedirectedError rather than followed. Redirects to the same username in
+// other letter case, as for a username not written as registered, are
+// followed.
+func checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxRedirects {
+		return http.ErrUseLastResponse
+	}
+	username := playerUsername(via[0].URL)
+	if username == "" || !strings.EqualFold(playerUsername(req.URL), username) {
+		return http.ErrUseLastResponse
+	}
+	return nil
+}
+
 // doRequestWithRetry executes an HTTP request with automatic retry on 429 responses.
 // It implements exponential backoff according to the client's retry configuration.
 func (c *Client) doRequestWithRetry(ctx context.Context, req *http.Request) (*http.Response, error) {
@@ -170,7 +225,7 @@ func (c *Client) GetPlayerGames(ctx context.Context, username string, year, mont
 
 	if resp.StatusCode != http.StatusOK {
 		c.logger.Warn("unexpected status code from Chess.com API", "statusCode", resp.StatusCode, "url", url)
-		return nil, fmt.Errorf("chess.com API returned status code %d", resp.StatusCode)
+		return nil, monthStatusError(resp, username, year, month)
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -189,39 +244,84 @@ func (c *Client) GetPlayerGames(ctx context.Context, username string, year, mont
 	return &games, nil
 }
 
-// GetPlayerGamesPGN downloads the PGN file containing all games for a specific user in a given month and year.
+// GetPlayerGamesPGN fetches the games of a user in a given month as one PGN
+// string. For months of thousands of games, StreamPlayerGamesPGN or
+// OpenPlayerGamesPGN keep them out of memory.
 func (c *Client) GetPlayerGamesPGN(ctx context.Context, username string, year, month int) (string, error) {
+	body, err := c.OpenPlayerGamesPGN(ctx, username, year, month)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = body.Close() }()
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		c.logger.Error("failed to read response body", "error", err, "username", username)
+		return "", fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	c.logger.Info("successfully fetched PGN", "username", username, "year", year, "month", month, "pgnSize", len(data))
+	return string(data), nil
+}
+
+// OpenPlayerGamesPGN starts downloading the games of a user in a given
+// month and returns the PGN as it arrives. The caller must close it.
+func (c *Client) OpenPlayerGamesPGN(ctx context.Context, username string, year, month int) (io.ReadCloser, error) {
 	url := fmt.Sprintf("%s/player/%s/games/%d/%02d/pgn", c.baseURL, username, year, month)
 	c.logger.Info("fetching player games PGN", "username", username, "year", year, "month", month, "url", url)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		c.logger.Error("failed to create HTTP request", "error", err, "url", url)
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	resp, err := c.doRequestWithRetry(ctx, req)
 	if err != nil {
 		c.logger.Error("HTTP request failed", "error", err, "url", url)
-		return "", fmt.Errorf("failed to fetch PGN: %w", err)
+		return nil, fmt.Errorf("failed to fetch PGN: %w", err)
 	}
-	defer func() { _ = resp.Body.Close() }()
 
 	c.logger.Debug("received HTTP response", "statusCode", resp.StatusCode, "url", url)
 
 	if resp.StatusCode != http.StatusOK {
+		_ = resp.Body.Close()
 		c.logger.Warn("unexpected status code from Chess.com API", "statusCode", resp.StatusCode, "url", url)
-		return "", fmt.Errorf("chess.com API returned status code %d", resp.StatusCode)
+		return nil, monthStatusError(resp, username, year, month)
 	}
+	return resp.Body, nil
+}
 
-	body, err := io.ReadAll(resp.Body)
+// StreamPlayerGamesPGN downloads the games of a user in a given month and
+// hands them to fn one at a time, as the PGN text of each game, so that
+// only one game is held in memory. It stops at the first error of fn, and
+// returns the number of games handed over.
+func (c *Client) StreamPlayerGamesPGN(ctx context.Context, username string, year, month int, fn func(game string) error) (int, error) {
+	body, err := c.OpenPlayerGamesPGN(ctx, username, year, month)
 	if err != nil {
-		c.logger.Error("failed to read response body", "error", err, "url", url)
-		return "", fmt.Errorf("failed to read response body: %w", err)
+		return 0, err
+	}
+	defer func() { _ = body.Close() }()
+
+	reader := pgn.NewReader(body)
+	count := 0
+	for {
+		game, err := reader.ReadText()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			c.logger.Error("failed to read response body", "error", err, "username", username)
+			return count, fmt.Errorf("failed to read PGN: %w", err)
+		}
+		if err := fn(game); err != nil {
+			return count, err
+		}
+		count++
 	}
 
-	c.logger.Info("successfully fetched PGN", "username", username, "year", year, "month", month, "pgnSize", len(body))
-	return string(body), nil
+	c.logger.Info("successfully streamed PGN", "username", username, "year", year, "month", month, "games", count)
+	return count, nil
 }
 
 // GetArchivedMonths returns a list of monthly archives available for a player.
@@ -246,7 +346,7 @@ func (c *Client) GetArchivedMonths(ctx context.Context, username string) (*Archi
 
 	if resp.StatusCode != http.StatusOK {
 		c.logger.Warn("unexpected status code from Chess.com API", "statusCode", resp.StatusCode, "url", url)
-		return nil, fmt.Errorf("chess.com API returned status code %d", resp.StatusCode)
+		return nil, statusError(resp, username)
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -264,3 +364,129 @@ func (c *Client) GetArchivedMonths(ctx context.Context, username string) (*Archi
 	c.logger.Info("successfully fetched archived months", "username", username, "archiveCount", len(archives.Archives))
 	return &archives, nil
 }
+
+// GetCurrentGames returns the daily games a player has in progress. Live
+// games are not listed by the API until they finish.
+func (c *Client) GetCurrentGames(ctx context.Context, username string) (*CurrentGamesResponse, error) {
+	url := fmt.Sprintf("%s/player/%s/games", c.baseURL, username)
+	c.logger.Info("fetching current games", "username", username, "url", url)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		c.logger.Error("failed to create HTTP request", "error", err, "url", url)
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.doRequestWithRetry(ctx, req)
+	if err != nil {
+		c.logger.Error("HTTP request failed", "error", err, "url", url)
+		return nil, fmt.Errorf("failed to fetch current games: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	c.logger.Debug("received HTTP response", "statusCode", resp.StatusCode, "url", url)
+
+	if resp.StatusCode != http.StatusOK {
+		c.logger.Warn("unexpected status code from Chess.com API", "statusCode", resp.StatusCode, "url", url)
+		return nil, statusError(resp, username)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		c.logger.Error("failed to read response body", "error", err, "url", url)
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	var games CurrentGamesResponse
+	if err := json.Unmarshal(body, &games); err != nil {
+		c.logger.Error("failed to unmarshal JSON response", "error", err, "url", url)
+		return nil, fmt.Errorf("failed to unmarshal current games response: %w", err)
+	}
+
+	c.logger.Debug("successfully fetched current games", "username", username, "gamesCount", len(games.Games))
+	return &games, nil
+}
+
+// GetGamesToMove returns the daily games in which it is the player's turn,
+// or in which they have a draw offer to answer.
+func (c *Client) GetGamesToMove(ctx context.Context, username string) (*ToMoveResponse, error) {
+	url := fmt.Sprintf("%s/player/%s/games/to-move", c.baseURL, username)
+	c.logger.Info("fetching games to move", "username", username, "url", url)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		c.logger.Error("failed to create HTTP request", "error", err, "url", url)
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.doRequestWithRetry(ctx, req)
+	if err != nil {
+		c.logger.Error("HTTP request failed", "error", err, "url", url)
+		return nil, fmt.Errorf("failed to fetch games to move: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	c.logger.Debug("received HTTP response", "statusCode", resp.StatusCode, "url", url)
+
+	if resp.StatusCode != http.StatusOK {
+		c.logger.Warn("unexpected status code from Chess.com API", "statusCode", resp.StatusCode, "url", url)
+		return nil, statusError(resp, username)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		c.logger.Error("failed to read response body", "error", err, "url", url)
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	var games ToMoveResponse
+	if err := json.Unmarshal(body, &games); err != nil {
+		c.logger.Error("failed to unmarshal JSON response", "error", err, "url", url)
+		return nil, fmt.Errorf("failed to unmarshal games to move response: %w", err)
+	}
+
+	c.logger.Debug("successfully fetched games to move", "username", username, "gamesCount", len(games.Games))
+	return &games, nil
+}
+
+// GetPlayerProfile returns a player's profile, with their title, country and
+// membership status.
+func (c *Client) GetPlayerProfile(ctx context.Context, username string) (*PlayerProfile, error) {
+	url := fmt.Sprintf("%s/player/%s", c.baseURL, username)
+	c.logger.Info("fetching player profile", "username", username, "url", url)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		c.logger.Error("failed to create HTTP request", "error", err, "url", url)
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.doRequestWithRetry(ctx, req)
+	if err != nil {
+		c.logger.Error("HTTP request failed", "error", err, "url", url)
+		return nil, fmt.Errorf("failed to fetch player profile: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	c.logger.Debug("received HTTP response", "statusCode", resp.StatusCode, "url", url)
+
+	if resp.StatusCode != http.StatusOK {
+		c.logger.Warn("unexpected status code from Chess.com API", "statusCode", resp.StatusCode, "url", url)
+		return nil, statusError(resp, username)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		c.logger.Error("failed to read response body", "error", err, "url", url)
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	var profile PlayerProfile
+	if err := json.Unmarshal(body, &profile); err != nil {
+		c.logger.Error("failed to unmarshal JSON response", "error", err, "url", url)
+		return nil, fmt.Errorf("failed to unmarshal player profile response: %w", err)
+	}
+
+	c.logger.Debug("successfully fetched player profile", "username", username, "title", profile.Title)
+	return &profile, nil
+}
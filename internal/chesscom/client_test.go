@@ -2,6 +2,8 @@ package chesscom
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"sync/atomic"
@@ -364,3 +366,152 @@ func TestClient_GetArchivedMonths(t *testing.T) {
 		}
 	})
 }
+
+func TestClient_SetHTTPClientKeepsRedirects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/pub/player/renamed/games/archives":
+			http.Redirect(w, r, "/pub/player/newname/games/archives", http.StatusMovedPermanently)
+		case "/pub/player/newname/games/archives":
+			_, _ = w.Write([]byte(`{"archives": []}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	// A client that follows redirects, and one recording the responses
+	for _, httpClient := range []*http.Client{
+		{Timeout: 5 * time.Second},
+		{Transport: NewRecorder(t.TempDir(), nil)},
+	} {
+		client := NewClientWithLogger(logging.Discard())
+		client.SetBaseURL(server.URL + "/pub")
+		client.SetHTTPClient(httpClient)
+
+		_, err := client.GetArchivedMonths(context.Background(), "renamed")
+		var redirected *RedirectedError
+		if !errors.As(err, &redirected) || redirected.NewUsername != "newname" {
+			t.Errorf("expected a RedirectedError to newname, got %v", err)
+		}
+		if httpClient.CheckRedirect != nil {
+			t.Errorf("expected the client passed in to be left as it was")
+		}
+	}
+}
+
+func TestClient_AccountStatusErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/pub/player/renamed/games/archives":
+			http.Redirect(w, r, "/pub/player/newname/games/archives", http.StatusMovedPermanently)
+		case "/pub/player/NewName/games/archives":
+			http.Redirect(w, r, "/pub/player/newname/games/archives", http.StatusMovedPermanently)
+		case "/pub/player/newname/games/archives":
+			_, _ = w.Write([]byte(`{"archives": []}`))
+		case "/pub/player/loop/games/archives":
+			http.Redirect(w, r, "/pub/player/LOOP/games/archives", http.StatusFound)
+		case "/pub/player/LOOP/games/archives":
+			http.Redirect(w, r, "/pub/player/loop/games/archives", http.StatusFound)
+		case "/pub/player/closed/games/archives":
+			w.WriteHeader(http.StatusGone)
+		case "/pub/player/broken/games/archives":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClientWithLogger(logging.Discard())
+	client.baseURL = server.URL + "/pub"
+	ctx := context.Background()
+
+	_, err := client.GetArchivedMonths(ctx, "renamed")
+	var redirected *RedirectedError
+	if !errors.As(err, &redirected) {
+		t.Fatalf("expected a RedirectedError, got %v", err)
+	}
+	if redirected.NewUsername != "newname" {
+		t.Errorf("expected the new username newname, got %q", redirected.NewUsername)
+	}
+	if err.Error() != "chess.com user renamed was renamed to newname" {
+		t.Errorf("unexpected message %q", err.Error())
+	}
+
+	// A redirect to the same username in other letter case is followed
+	archives, err := client.GetArchivedMonths(ctx, "NewName")
+	if err != nil || archives == nil {
+		t.Errorf("expected the redirect to newname to be followed, got %v", err)
+	}
+	_, err = client.GetArchivedMonths(ctx, "loop")
+	if !errors.As(err, &redirected) || redirected.NewUsername == "" {
+		t.Errorf("expected a RedirectedError after too many redirects, got %v", err)
+	}
+
+	// A month that is not found fails alone, while a player that is not
+	// found fails every request
+	_, err = client.GetPlayerGamesPGN(ctx, "nonexistent", 2024, 1)
+	if !errors.Is(err, ErrMonthNotFound) || IsAccountError(err) {
+		t.Errorf("expected ErrMonthNotFound, got %v", err)
+	}
+	_, err = client.GetPlayerGames(ctx, "nonexistent", 2024, 1)
+	if !errors.Is(err, ErrMonthNotFound) || IsAccountError(err) {
+		t.Errorf("expected ErrMonthNotFound, got %v", err)
+	}
+	_, err = client.GetArchivedMonths(ctx, "nonexistent")
+	if !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("expected ErrUserNotFound, got %v", err)
+	}
+	_, err = client.GetArchivedMonths(ctx, "closed")
+	if !errors.Is(err, ErrAccountClosed) {
+		t.Errorf("expected ErrAccountClosed, got %v", err)
+	}
+	_, err = client.GetArchivedMonths(ctx, "broken")
+	if err == nil || IsAccountError(err) {
+		t.Errorf("expected a plain status error, got %v", err)
+	}
+	for _, err := range []error{redirected, ErrUserNotFound, ErrAccountClosed} {
+		if !IsAccountError(fmt.Errorf("failed to fetch archives: %w", err)) {
+			t.Errorf("expected %v to be an account error", err)
+		}
+	}
+}
+
+func TestClient_GetPlayerProfile(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/pub/player/hikaru" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{
+			"username": "hikaru",
+			"name": "Hikaru Nakamura",
+			"title": "GM",
+			"country": "https://api.chess.com/pub/country/US",
+			"status": "premium",
+			"joined": 1389043258
+		}`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithLogger(logging.Discard())
+	client.baseURL = server.URL + "/pub"
+
+	profile, err := client.GetPlayerProfile(context.Background(), "hikaru")
+	if err != nil {
+		t.Fatalf("GetPlayerProfile failed: %v", err)
+	}
+	if profile.Title != "GM" || profile.Status != "premium" {
+		t.Errorf("unexpected profile %+v", profile)
+	}
+	if code := profile.CountryCode(); code != "US" {
+		t.Errorf("expected country code US, got %q", code)
+	}
+
+	_, err = client.GetPlayerProfile(context.Background(), "nonexistent")
+	if !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("expected ErrUserNotFound, got %v", err)
+	}
+}
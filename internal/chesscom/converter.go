@@ -13,10 +13,21 @@ func GamesToDatabase(games *GamesResponse) (*pgn.DB, []error) {
 	var errs []error
 
 	for _, game := range games.Games {
+		parsed := len(db.Games)
 		pgnData := game.PGN
 		if err := db.Parse(pgnData); len(err) > 0 {
 			errs = append(errs, fmt.Errorf("failed to parse game PGN: %v", err))
 		}
+		// Keep Chess.com's time class, which the PGN does not carry, and
+		// its reason for the result if the PGN has no Termination tag
+		for _, g := range db.Games[parsed:] {
+			if game.TimeClass != "" {
+				g.Tags["TimeClass"] = game.TimeClass
+			}
+			if t := game.Termination(); t != pgn.TerminationUnknown && g.Tags["Termination"] == "" {
+				g.Tags["Termination"] = t.String()
+			}
+		}
 	}
 
 	// Parse moves for all games
@@ -92,7 +103,7 @@ func shouldIncludeGame(game *pgn.Game, options FilterOptions) bool {
 
 	// Check result
 	if options.Result != "" {
-		if result, ok := game.Tags["Result"]; !ok || result != options.Result {
+		if result, ok := game.Tags["Result"]; !ok || pgn.ParseResult(result) != pgn.ParseResult(options.Result) {
 			return false
 		}
 	}
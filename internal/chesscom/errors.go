@@ -0,0 +1,101 @@
+package chesscom
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ErrUserNotFound is returned when Chess.com has no player of the requested
+// username (HTTP 404).
+var ErrUserNotFound = errors.New("chess.com user not found")
+
+// ErrMonthNotFound is returned when Chess.com has no archive of a player's
+// games for a month (HTTP 404 on a monthly archive). Unlike ErrUserNotFound
+// it is a failure of that month only.
+var ErrMonthNotFound = errors.New("chess.com monthly archive not found")
+
+// ErrAccountClosed is returned when the player's account was closed, and
+// its games will never be available again (HTTP 410).
+var ErrAccountClosed = errors.New("chess.com account closed")
+
+// RedirectedError is returned when Chess.com redirects the requests for a
+// player elsewhere (HTTP 301 and other redirects), which it does for
+// accounts that were renamed.
+type RedirectedError struct {
+	Username    string // username requested
+	NewUsername string // username redirected to, or "" if not known
+	Location    string // URL redirected to
+}
+
+// Error tells the username the account was renamed to, when known.
+func (e *RedirectedError) Error() string {
+	if e.NewUsername != "" && !strings.EqualFold(e.NewUsername, e.Username) {
+		return fmt.Sprintf("chess.com user %s was renamed to %s", e.Username, e.NewUsername)
+	}
+	return fmt.Sprintf("chess.com redirected the requests for user %s to %s", e.Username, e.Location)
+}
+
+// statusError returns the error for a response other than 200 OK to a
+// request for username's data.
+func statusError(resp *http.Response, username string) error {
+	switch resp.StatusCode {
+	case http.StatusNotFound:
+		return fmt.Errorf("%w: %s", ErrUserNotFound, username)
+	case http.StatusGone:
+		return fmt.Errorf("%w: %s", ErrAccountClosed, username)
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		location := resp.Header.Get("Location")
+		return &RedirectedError{
+			Username:    username,
+			NewUsername: redirectedUsername(resp.Request, location),
+			Location:    location,
+		}
+	}
+	return fmt.Errorf("chess.com API returned status code %d", resp.StatusCode)
+}
+
+// monthStatusError returns the error for a response other than 200 OK to a
+// request for username's games of a month. A month that is not found is
+// not an account error: the other months may still be there.
+func monthStatusError(resp *http.Response, username string, year, month int) error {
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%w: %s %d/%02d", ErrMonthNotFound, username, year, month)
+	}
+	return statusError(resp, username)
+}
+
+// redirectedUsername returns the username in the player URL a request was
+// redirected to, as in https://api.chess.com/pub/player/{username}/games,
+// or "" if location is not such a URL.
+func redirectedUsername(req *http.Request, location string) string {
+	u, err := url.Parse(location)
+	if err != nil {
+		return ""
+	}
+	if req != nil && req.URL != nil {
+		u = req.URL.ResolveReference(u)
+	}
+	return playerUsername(u)
+}
+
+// playerUsername returns the username of a player URL, as in
+// https://api.chess.com/pub/player/{username}/games, or "" if u is not one.
+func playerUsername(u *url.URL) string {
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	for i := 0; i+1 < len(parts); i++ {
+		if parts[i] == "player" {
+			return parts[i+1]
+		}
+	}
+	return ""
+}
+
+// IsAccountError reports whether err tells that a player's games cannot be
+// fetched under that username at all, as opposed to a failure of one request.
+func IsAccountError(err error) bool {
+	var redirected *RedirectedError
+	return errors.Is(err, ErrUserNotFound) || errors.Is(err, ErrAccountClosed) || errors.As(err, &redirected)
+}
@@ -1,6 +1,11 @@
 package chesscom
 
-import "time"
+import (
+	"path"
+	"time"
+
+	"github.com/kyleboon/gochess/internal/pgn"
+)
 
 // ArchivesResponse represents the response from the archives endpoint.
 type ArchivesResponse struct {
@@ -49,3 +54,81 @@ type Accuracies struct {
 func (g *Game) GetEndTime() time.Time {
 	return time.Unix(g.EndTime, 0)
 }
+
+// CurrentGamesResponse represents the response from the current games
+// endpoint.
+type CurrentGamesResponse struct {
+	Games []CurrentGame `json:"games"`
+}
+
+// CurrentGame represents a daily game in progress on Chess.com. Unlike
+// finished games, the players are given as the API URLs of their profiles.
+type CurrentGame struct {
+	URL          string `json:"url"`
+	PGN          string `json:"pgn"`
+	FEN          string `json:"fen"`
+	TimeControl  string `json:"time_control"`
+	TimeClass    string `json:"time_class"`
+	Rules        string `json:"rules"`
+	Turn         string `json:"turn"`
+	MoveBy       int64  `json:"move_by"`
+	LastActivity int64  `json:"last_activity"`
+	StartTime    int64  `json:"start_time"`
+	Rated        bool   `json:"rated"`
+	White        string `json:"white"`
+	Black        string `json:"black"`
+}
+
+// ToMoveResponse represents the response from the games to move endpoint.
+type ToMoveResponse struct {
+	Games []ToMoveGame `json:"games"`
+}
+
+// ToMoveGame represents a daily game in which it is the player's turn to
+// move, or to answer a draw offer.
+type ToMoveGame struct {
+	URL          string `json:"url"`
+	MoveBy       int64  `json:"move_by"`
+	LastActivity int64  `json:"last_activity"`
+	DrawOffer    bool   `json:"draw_offer"`
+}
+
+// PlayerProfile represents the response from the player profile endpoint.
+type PlayerProfile struct {
+	Username string `json:"username"`
+	Name     string `json:"name"`
+	Title    string `json:"title"`   // FIDE or Chess.com title such as "GM", or "" if untitled
+	Country  string `json:"country"` // API URL of the player's country
+	Status   string `json:"status"`  // Membership status: "basic", "premium", "staff", "closed", ...
+	Joined   int64  `json:"joined"`
+}
+
+// CountryCode returns the ISO 3166-1 alpha-2 code of the player's country,
+// as in "US", or "" if the profile does not give one.
+func (p *PlayerProfile) CountryCode() string {
+	if p.Country == "" {
+		return ""
+	}
+	return path.Base(p.Country)
+}
+
+// WhiteUsername returns the username of the white player.
+func (g *CurrentGame) WhiteUsername() string {
+	return path.Base(g.White)
+}
+
+// BlackUsername returns the username of the black player.
+func (g *CurrentGame) BlackUsername() string {
+	return path.Base(g.Black)
+}
+
+// Result returns the result of a finished game as in PGN: "1-0", "0-1" or
+// "1/2-1/2".
+func (g *Game) Result() string {
+	return pgn.ParseChessComResult(g.White.Result, g.Black.Result).String()
+}
+
+// Termination returns how a finished game ended.
+func (g *Game) Termination() pgn.Termination {
+	return pgn.ParseChessComTermination(g.White.Result, g.Black.Result)
+}
@@ -0,0 +1,57 @@
+package chesscom
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/kyleboon/gochess/internal/db"
+	"github.com/kyleboon/gochess/internal/progress"
+)
+
+// FetchOpponentProfiles fetches the profiles of the opponents of players in
+// their Chess.com games that were not fetched before, and stores their
+// title, country and membership status in the database. The accounts that
+// no longer exist are stored as closed, so they are not asked for again. It
+// returns the number of profiles stored.
+func FetchOpponentProfiles(ctx context.Context, client *Client, database *db.DB, players []string, out io.Writer) (int, error) {
+	opponents, err := database.OpponentsWithoutProfile(ctx, players)
+	if err != nil {
+		return 0, err
+	}
+	if len(opponents) == 0 {
+		return 0, nil
+	}
+
+	fmt.Fprintf(out, "Fetching the profiles of %d opponents...\n", len(opponents))
+	bar := progress.ForOutput(out, len(opponents), "profiles")
+	defer bar.Finish()
+
+	stored := 0
+	for _, opponent := range opponents {
+		if err := ctx.Err(); err != nil {
+			return stored, err
+		}
+		profile := db.PlayerProfile{Platform: "chesscom", Username: opponent}
+		p, err := client.GetPlayerProfile(ctx, opponent)
+		switch {
+		case err == nil:
+			profile.Title = p.Title
+			profile.Country = p.CountryCode()
+			profile.Status = p.Status
+		case errors.Is(err, ErrAccountClosed):
+			profile.Status = "closed"
+		case IsAccountError(err):
+			// Renamed or not found: stored empty, so it is not asked for again
+		default:
+			return stored, fmt.Errorf("failed to fetch the profile of %s: %w", opponent, err)
+		}
+		if err := database.SavePlayerProfile(ctx, profile); err != nil {
+			return stored, err
+		}
+		stored++
+		bar.Add(1)
+	}
+	return stored, nil
+}
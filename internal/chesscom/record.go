@@ -0,0 +1,106 @@
+package chesscom
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Environment variables that point clients made by NewClient and
+// NewClientWithLogger somewhere other than the live API, for tests and
+// offline runs.
+const (
+	// BaseURLEnv overrides the base URL of the API, as in
+	// http://127.0.0.1:8080/pub for a FixtureHandler.
+	BaseURLEnv = "GOCHESS_CHESSCOM_URL"
+	// RecordEnv, set to 1, saves every successful response into the
+	// fixture directory, to be served back later by a FixtureHandler.
+	RecordEnv = "GOCHESS_RECORD"
+	// RecordDirEnv sets the fixture directory responses are saved into
+	// (default DefaultFixtureDir).
+	RecordDirEnv = "GOCHESS_RECORD_DIR"
+)
+
+// DefaultFixtureDir is where responses are recorded when RecordDirEnv is
+// not set, relative to the working directory: the package directory when
+// recording from go test.
+const DefaultFixtureDir = "testdata/fixtures"
+
+// pgnContentType is the content type of the monthly PGN downloads.
+const pgnContentType = "application/x-chess-pgn"
+
+// Recorder is an http.RoundTripper that saves the body of every 200 OK
+// response into Dir, named after the request path, before handing it on.
+// Other responses are passed through unsaved.
+type Recorder struct {
+	Dir  string
+	Next http.RoundTripper // http.DefaultTransport if nil
+}
+
+// NewRecorder creates a Recorder saving into dir the responses of next.
+func NewRecorder(dir string, next http.RoundTripper) *Recorder {
+	return &Recorder{Dir: dir, Next: next}
+}
+
+// RoundTrip performs the request and records the response.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	next := r.Next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	resp, err := next.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return resp, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	ext := ".json"
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), pgnContentType) || strings.HasSuffix(req.URL.Path, "/pgn") {
+		ext = ".pgn"
+	}
+	if err := os.MkdirAll(r.Dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create fixture directory: %w", err)
+	}
+	path := filepath.Join(r.Dir, fixtureName(req.URL.Path)+ext)
+	if err := os.WriteFile(path, body, 0644); err != nil {
+		return nil, fmt.Errorf("failed to record response: %w", err)
+	}
+	return resp, nil
+}
+
+// fixtureName returns the name of the fixture file of a request path, as
+// in pub_player_alice_games_archives for /pub/player/alice/games/archives.
+// Usernames are not case sensitive on Chess.com, so neither are the names.
+func fixtureName(urlPath string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.Trim(urlPath, "/"), "/", "_"))
+}
+
+// NewFixtureHandler returns a handler serving the responses recorded in dir
+// by a Recorder, and 404 Not Found for the requests never recorded, as
+// Chess.com answers for unknown players. Serve it with httptest and point
+// clients at its /pub path with BaseURLEnv.
+func NewFixtureHandler(dir string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		name := fixtureName(req.URL.Path)
+		for ext, contentType := range map[string]string{".json": "application/json", ".pgn": pgnContentType} {
+			body, err := os.ReadFile(filepath.Join(dir, name+ext))
+			if err != nil {
+				continue
+			}
+			w.Header().Set("Content-Type", contentType)
+			_, _ = w.Write(body)
+			return
+		}
+		http.NotFound(w, req)
+	})
+}
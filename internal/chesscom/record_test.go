@@ -0,0 +1,263 @@
+package chesscom
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kyleboon/gochess/internal/config"
+	"github.com/kyleboon/gochess/internal/db"
+	"github.com/kyleboon/gochess/internal/logging"
+)
+
+// fixtureUser is the player whose games are in testdata/fixtures
+const fixtureUser = "erik"
+
+// useFixtures points the clients made by the test at a server replaying
+// testdata/fixtures. With GOCHESS_RECORD=1 the test runs against the live
+// API instead and records the fixtures afresh.
+func useFixtures(t *testing.T) {
+	t.Helper()
+	if os.Getenv(RecordEnv) == "1" {
+		t.Setenv(RecordDirEnv, DefaultFixtureDir)
+		return
+	}
+	server := httptest.NewServer(NewFixtureHandler(DefaultFixtureDir))
+	t.Cleanup(server.Close)
+	t.Setenv(BaseURLEnv, server.URL+"/pub")
+}
+
+// readFixture returns the content of a fixture file
+func readFixture(t *testing.T, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(DefaultFixtureDir, name))
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	return string(data)
+}
+
+func TestRecorder(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/pub/player/alice/games/archives":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"archives": ["https://api.chess.com/pub/player/alice/games/2024/03"]}`))
+		case "/pub/player/alice/games/2024/03/pgn":
+			w.Header().Set("Content-Type", "application/x-chess-pgn")
+			_, _ = w.Write([]byte("[Event \"Live Chess\"]\n\n1. e4 e5 1-0\n"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer upstream.Close()
+
+	dir := t.TempDir()
+	client := NewClientWithLogger(logging.Discard())
+	client.SetBaseURL(upstream.URL + "/pub/")
+	client.SetHTTPClient(&http.Client{Transport: NewRecorder(dir, nil)})
+	ctx := context.Background()
+
+	archives, err := client.GetArchivedMonths(ctx, "alice")
+	if err != nil {
+		t.Fatalf("GetArchivedMonths failed: %v", err)
+	}
+	pgnText, err := client.GetPlayerGamesPGN(ctx, "alice", 2024, 3)
+	if err != nil {
+		t.Fatalf("GetPlayerGamesPGN failed: %v", err)
+	}
+	if _, err := client.GetArchivedMonths(ctx, "bob"); !errors.Is(err, ErrUserNotFound) {
+		t.Fatalf("expected ErrUserNotFound, got %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	want := "pub_player_alice_games_2024_03_pgn.pgn pub_player_alice_games_archives.json"
+	if got := strings.Join(names, " "); got != want {
+		t.Errorf("expected the recorded files %q, got %q", want, got)
+	}
+
+	// The recorded responses are served back the same
+	replay := httptest.NewServer(NewFixtureHandler(dir))
+	defer replay.Close()
+	client = NewClientWithLogger(logging.Discard())
+	client.SetBaseURL(replay.URL + "/pub")
+
+	replayed, err := client.GetArchivedMonths(ctx, "alice")
+	if err != nil {
+		t.Fatalf("GetArchivedMonths from the fixtures failed: %v", err)
+	}
+	if len(replayed.Archives) != 1 || replayed.Archives[0] != archives.Archives[0] {
+		t.Errorf("expected the archives %v, got %v", archives.Archives, replayed.Archives)
+	}
+	replayedPGN, err := client.GetPlayerGamesPGN(ctx, "alice", 2024, 3)
+	if err != nil || replayedPGN != pgnText {
+		t.Errorf("expected the PGN %q, got %q (%v)", pgnText, replayedPGN, err)
+	}
+	if _, err := client.GetArchivedMonths(ctx, "bob"); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("expected ErrUserNotFound for a response not recorded, got %v", err)
+	}
+}
+
+func TestNewClient_Environment(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"archives": []}`))
+	}))
+	defer server.Close()
+	dir := t.TempDir()
+	t.Setenv(BaseURLEnv, server.URL+"/pub")
+	t.Setenv(RecordEnv, "1")
+	t.Setenv(RecordDirEnv, dir)
+
+	if _, err := NewClientWithLogger(logging.Discard()).GetArchivedMonths(context.Background(), "alice"); err != nil {
+		t.Fatalf("GetArchivedMonths failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "pub_player_alice_games_archives.json")); err != nil {
+		t.Errorf("expected the response to be recorded: %v", err)
+	}
+}
+
+func TestDownloadMonthlyGames_Fixtures(t *testing.T) {
+	useFixtures(t)
+	outputPath := filepath.Join(t.TempDir(), "games.pgn")
+
+	_, err := downloadAndImportMonthlyGames(context.Background(), io.Discard, fixtureUser, 2024, 1, "pgn", outputPath, "", false, false, nil, logging.Discard())
+	if err != nil {
+		t.Fatalf("downloadAndImportMonthlyGames failed: %v", err)
+	}
+	got, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := readFixture(t, "pub_player_erik_games_2024_01_pgn.pgn"); string(got) != want {
+		t.Errorf("expected the downloaded PGN to be the fixture, got %q", got)
+	}
+}
+
+func TestImportFromConfig_Fixtures(t *testing.T) {
+	useFixtures(t)
+	t.Setenv("HOME", t.TempDir()) // the last import time is saved in the config
+	ctx := context.Background()
+
+	database, err := db.NewInMemory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = database.Close() }()
+
+	// Import only the last month of the archives
+	archives, err := NewClientWithLogger(logging.Discard()).GetArchivedMonths(ctx, fixtureUser)
+	if err != nil {
+		t.Fatalf("GetArchivedMonths failed: %v", err)
+	}
+	if len(archives.Archives) == 0 {
+		t.Fatal("expected archives in the fixtures")
+	}
+	parts := strings.Split(archives.Archives[len(archives.Archives)-1], "/")
+	year, _ := parseArchiveYear(parts[len(parts)-2])
+	month, _ := parseArchiveMonth(parts[len(parts)-1])
+	cfg := &config.Config{}
+	cfg.SetLastImport("chesscom", fixtureUser, time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC))
+
+	count, err := ImportFromConfig(ctx, cfg, fixtureUser, database, logging.Discard(), io.Discard, false)
+	if err != nil {
+		t.Fatalf("ImportFromConfig failed: %v", err)
+	}
+	pgnFixture := readFixture(t, fixtureName(strings.TrimPrefix(archives.Archives[len(archives.Archives)-1], "https://api.chess.com")+"/pgn")+".pgn")
+	if want := strings.Count(pgnFixture, "[Event "); count != want {
+		t.Errorf("expected %d games imported, got %d", want, count)
+	}
+	games, err := database.SearchByTag(ctx, db.SourceAccountTag, "chesscom:"+fixtureUser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(games) != count {
+		t.Errorf("expected the %d games to be tagged with their account, got %d", count, len(games))
+	}
+
+	count, err = ImportFromConfig(ctx, cfg, fixtureUser, database, logging.Discard(), io.Discard, false)
+	if err != nil {
+		t.Fatalf("second ImportFromConfig failed: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("expected no games imported the second time, got %d", count)
+	}
+}
+
+func TestFetchOpponentProfiles_Fixtures(t *testing.T) {
+	useFixtures(t)
+	ctx := context.Background()
+	database, err := db.NewInMemory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = database.Close() }()
+
+	result := database.ImportPGNReader(ctx, "games.pgn", strings.NewReader(readFixture(t, "pub_player_erik_games_2024_02_pgn.pgn")), db.ImportOptions{})
+	if len(result.Errors) > 0 {
+		t.Fatalf("import failed: %v", result.Errors)
+	}
+	client := NewClientWithLogger(logging.Discard())
+	count, err := FetchOpponentProfiles(ctx, client, database, []string{"Marta_L"}, io.Discard)
+	if err != nil {
+		t.Fatalf("FetchOpponentProfiles failed: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("expected 1 profile stored, got %d", count)
+	}
+	profile, err := database.GetPlayerProfile(ctx, "chesscom", fixtureUser)
+	if err != nil || profile == nil {
+		t.Fatalf("expected the profile of %s, got %v (%v)", fixtureUser, profile, err)
+	}
+	if profile.Country != "US" || profile.Status != "staff" {
+		t.Errorf("unexpected profile %+v", profile)
+	}
+}
+
+func TestStreamPlayerGamesPGN_Fixtures(t *testing.T) {
+	useFixtures(t)
+	client := NewClientWithLogger(logging.Discard())
+	ctx := context.Background()
+
+	var games []string
+	count, err := client.StreamPlayerGamesPGN(ctx, fixtureUser, 2024, 2, func(game string) error {
+		games = append(games, game)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("StreamPlayerGamesPGN failed: %v", err)
+	}
+	fixture := readFixture(t, "pub_player_erik_games_2024_02_pgn.pgn")
+	if want := strings.Count(fixture, "[Event "); count != want || len(games) != want {
+		t.Fatalf("expected %d games, got %d (%d handed over)", want, count, len(games))
+	}
+	for _, game := range games {
+		if !strings.HasPrefix(game, "[Event ") || strings.Count(game, "[Event ") != 1 {
+			t.Errorf("expected the text of one game, got %q", game)
+		}
+	}
+
+	stop := errors.New("stop")
+	count, err = client.StreamPlayerGamesPGN(ctx, fixtureUser, 2024, 2, func(string) error { return stop })
+	if !errors.Is(err, stop) || count != 0 {
+		t.Errorf("expected the callback error after 0 games, got %v after %d", err, count)
+	}
+
+	_, err = client.StreamPlayerGamesPGN(ctx, "nonexistent", 2024, 2, func(string) error { return nil })
+	if !errors.Is(err, ErrMonthNotFound) || IsAccountError(err) {
+		t.Errorf("expected ErrMonthNotFound, got %v", err)
+	}
+}
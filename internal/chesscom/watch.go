@@ -0,0 +1,170 @@
+package chesscom
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kyleboon/gochess/internal"
+	"github.com/kyleboon/gochess/internal/pgn"
+)
+
+// DefaultWatchInterval is how often a Watcher polls Chess.com by default.
+const DefaultWatchInterval = 30 * time.Second
+
+// GameUpdate describes a game in progress that changed since the last poll.
+type GameUpdate struct {
+	Game CurrentGame
+	// New is set the first time a game is seen. Its moves so far are not
+	// reported, only the position.
+	New bool
+	// Moves holds the moves played since the last poll in SAN, the first
+	// of them being ply FirstPly (1 is White's first move).
+	Moves    []string
+	FirstPly int
+	// Board is the current position.
+	Board *internal.Board
+}
+
+// WatchUpdate is the result of one poll of a Watcher.
+type WatchUpdate struct {
+	Games []GameUpdate
+	// Finished holds the games that ended since the watch started and
+	// were not reported before, live games included.
+	Finished []Game
+}
+
+// Watcher follows the games of a Chess.com player by polling the API. Daily
+// games in progress are followed move by move; games of any kind are
+// reported once they finish and appear in the player's monthly archive.
+type Watcher struct {
+	client   *Client
+	username string
+	since    time.Time
+	plies    map[string]int  // plies seen per game in progress, by URL
+	finished map[string]bool // finished games reported, by URL
+}
+
+// NewWatcher creates a Watcher for username. Games that finished before now
+// are not reported.
+func NewWatcher(client *Client, username string) *Watcher {
+	return &Watcher{
+		client:   client,
+		username: username,
+		since:    time.Now(),
+		plies:    make(map[string]int),
+		finished: make(map[string]bool),
+	}
+}
+
+// WithSince sets the time from which finished games are reported.
+func (w *Watcher) WithSince(since time.Time) *Watcher {
+	w.since = since
+	return w
+}
+
+// Poll fetches the player's games once and returns what changed since the
+// last poll.
+func (w *Watcher) Poll(ctx context.Context) (*WatchUpdate, error) {
+	current, err := w.client.GetCurrentGames(ctx, w.username)
+	if err != nil {
+		return nil, err
+	}
+
+	update := &WatchUpdate{}
+	for _, game := range current.Games {
+		sans, board, err := gameMoves(game.PGN)
+		if err != nil {
+			w.client.logger.Warn("failed to parse current game", "url", game.URL, "error", err)
+			continue
+		}
+		seen, ok := w.plies[game.URL]
+		w.plies[game.URL] = len(sans)
+		switch {
+		case !ok:
+			update.Games = append(update.Games, GameUpdate{Game: game, New: true, FirstPly: len(sans) + 1, Board: board})
+		case len(sans) > seen:
+			update.Games = append(update.Games, GameUpdate{Game: game, Moves: sans[seen:], FirstPly: seen + 1, Board: board})
+		}
+	}
+
+	finished, err := w.finishedGames(ctx)
+	if err != nil {
+		return nil, err
+	}
+	update.Finished = finished
+	return update, nil
+}
+
+// Run polls every interval until ctx is done, passing each update to fn.
+// Errors from the API are passed to onError and do not stop the watch.
+func (w *Watcher) Run(ctx context.Context, interval time.Duration, fn func(*WatchUpdate), onError func(error)) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		update, err := w.Poll(ctx)
+		switch {
+		case ctx.Err() != nil:
+			return nil
+		case err != nil:
+			onError(err)
+		default:
+			fn(update)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// finishedGames returns the games in the archives of the months since the
+// watch started that ended after it started and were not reported yet.
+func (w *Watcher) finishedGames(ctx context.Context) ([]Game, error) {
+	now := time.Now()
+	var finished []Game
+	since := w.since.UTC()
+	month := time.Date(since.Year(), since.Month(), 1, 0, 0, 0, 0, time.UTC)
+	for ; !month.After(now); month = month.AddDate(0, 1, 0) {
+		games, err := w.client.GetPlayerGames(ctx, w.username, month.Year(), int(month.Month()))
+		if err != nil {
+			return nil, err
+		}
+		for _, game := range games.Games {
+			if w.finished[game.URL] || game.GetEndTime().Before(w.since) {
+				continue
+			}
+			w.finished[game.URL] = true
+			delete(w.plies, game.URL)
+			finished = append(finished, game)
+		}
+	}
+	return finished, nil
+}
+
+// gameMoves returns the main line moves of a game in SAN, and the position
+// they lead to.
+func gameMoves(pgnText string) ([]string, *internal.Board, error) {
+	db := &pgn.DB{}
+	if errs := db.Parse(pgnText); len(db.Games) == 0 {
+		if len(errs) > 0 {
+			return nil, nil, errs[0]
+		}
+		return nil, nil, fmt.Errorf("no game in PGN")
+	}
+	game := db.Games[0]
+	if err := db.ParseMoves(game); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse moves: %w", err)
+	}
+
+	var sans []string
+	board := game.Root.Board
+	for node := game.Root.Next; node != nil; node = node.Next {
+		sans = append(sans, node.Move.San(board))
+		board = node.Board
+	}
+	return sans, board, nil
+}
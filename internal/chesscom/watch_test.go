@@ -0,0 +1,197 @@
+package chesscom
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kyleboon/gochess/internal/logging"
+)
+
+// fakeChessCom serves a player's current games and monthly archive, which a
+// test can change between polls
+type fakeChessCom struct {
+	mu       sync.Mutex
+	current  string // PGN moves of the one daily game in progress, or ""
+	finished []string
+}
+
+func (f *fakeChessCom) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch {
+	case r.URL.Path == "/pub/player/watched/games":
+		games := ""
+		if f.current != "" {
+			games = fmt.Sprintf(`{
+				"url": "https://www.chess.com/game/daily/1",
+				"pgn": "[White \"watched\"]\n[Black \"rival\"]\n\n%s *",
+				"white": "https://api.chess.com/pub/player/watched",
+				"black": "https://api.chess.com/pub/player/rival"
+			}`, f.current)
+		}
+		_, _ = fmt.Fprintf(w, `{"games": [%s]}`, games)
+	case r.URL.Path == "/pub/player/watched/games/to-move":
+		games := ""
+		if f.current != "" {
+			games = `{"url": "https://www.chess.com/game/daily/1", "move_by": 1700000000, "draw_offer": true}`
+		}
+		_, _ = fmt.Fprintf(w, `{"games": [%s]}`, games)
+	case strings.HasPrefix(r.URL.Path, "/pub/player/watched/games/"):
+		_, _ = fmt.Fprintf(w, `{"games": [%s]}`, strings.Join(f.finished, ","))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (f *fakeChessCom) set(current string, finished ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.current = current
+	f.finished = finished
+}
+
+func finishedGame(url string, end time.Time) string {
+	return fmt.Sprintf(`{
+		"url": %q,
+		"pgn": "[White \"watched\"]\n[Black \"rival\"]\n\n1. e4 e5 1-0",
+		"end_time": %d,
+		"white": {"username": "watched", "result": "win"},
+		"black": {"username": "rival", "result": "resigned"}
+	}`, url, end.Unix())
+}
+
+func TestWatcher_Poll(t *testing.T) {
+	fake := &fakeChessCom{}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	client := NewClientWithLogger(logging.Discard())
+	client.baseURL = server.URL + "/pub"
+	start := time.Now()
+	watcher := NewWatcher(client, "watched").WithSince(start)
+	ctx := context.Background()
+
+	// A game that finished before the watch started is not reported
+	fake.set("1. d4 d5", finishedGame("https://www.chess.com/game/live/0", start.Add(-time.Hour)))
+	update, err := watcher.Poll(ctx)
+	if err != nil {
+		t.Fatalf("expected success, got error: %v", err)
+	}
+	if len(update.Games) != 1 || !update.Games[0].New {
+		t.Fatalf("expected the daily game to be reported as new, got %+v", update.Games)
+	}
+	if got := update.Games[0].Game.BlackUsername(); got != "rival" {
+		t.Errorf("expected black player rival, got %q", got)
+	}
+	if len(update.Finished) != 0 {
+		t.Errorf("expected no finished games, got %d", len(update.Finished))
+	}
+
+	// Nothing changed
+	update, err = watcher.Poll(ctx)
+	if err != nil {
+		t.Fatalf("expected success, got error: %v", err)
+	}
+	if len(update.Games) != 0 {
+		t.Errorf("expected no updates, got %+v", update.Games)
+	}
+
+	// Two moves were played
+	fake.set("1. d4 d5 2. c4 e6", finishedGame("https://www.chess.com/game/live/0", start.Add(-time.Hour)))
+	update, err = watcher.Poll(ctx)
+	if err != nil {
+		t.Fatalf("expected success, got error: %v", err)
+	}
+	if len(update.Games) != 1 {
+		t.Fatalf("expected 1 update, got %d", len(update.Games))
+	}
+	game := update.Games[0]
+	if strings.Join(game.Moves, " ") != "c4 e6" || game.FirstPly != 3 {
+		t.Errorf("expected moves c4 e6 from ply 3, got %v from ply %d", game.Moves, game.FirstPly)
+	}
+	if game.Board.SideToMove != 0 {
+		t.Errorf("expected White to move")
+	}
+
+	// A live game finished; it is reported once
+	fake.set("1. d4 d5 2. c4 e6", finishedGame("https://www.chess.com/game/live/2", time.Now().Add(time.Second)))
+	update, err = watcher.Poll(ctx)
+	if err != nil {
+		t.Fatalf("expected success, got error: %v", err)
+	}
+	if len(update.Finished) != 1 || update.Finished[0].Result() != "1-0" {
+		t.Fatalf("expected 1 finished game won by White, got %+v", update.Finished)
+	}
+	update, err = watcher.Poll(ctx)
+	if err != nil {
+		t.Fatalf("expected success, got error: %v", err)
+	}
+	if len(update.Finished) != 0 {
+		t.Errorf("expected the finished game to be reported once, got %d", len(update.Finished))
+	}
+}
+
+func TestClient_GetCurrentGames(t *testing.T) {
+	fake := &fakeChessCom{current: "1. e4"}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	client := NewClientWithLogger(logging.Discard())
+	client.baseURL = server.URL + "/pub"
+	games, err := client.GetCurrentGames(context.Background(), "watched")
+	if err != nil {
+		t.Fatalf("expected success, got error: %v", err)
+	}
+	if len(games.Games) != 1 {
+		t.Fatalf("expected 1 game, got %d", len(games.Games))
+	}
+	if got := games.Games[0].WhiteUsername(); got != "watched" {
+		t.Errorf("expected white player watched, got %q", got)
+	}
+
+	_, err = client.GetCurrentGames(context.Background(), "nonexistent")
+	if err == nil {
+		t.Error("expected error for 404 response")
+	}
+}
+
+func TestClient_GetGamesToMove(t *testing.T) {
+	fake := &fakeChessCom{current: "1. e4"}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	client := NewClientWithLogger(logging.Discard())
+	client.baseURL = server.URL + "/pub"
+	games, err := client.GetGamesToMove(context.Background(), "watched")
+	if err != nil {
+		t.Fatalf("expected success, got error: %v", err)
+	}
+	if len(games.Games) != 1 {
+		t.Fatalf("expected 1 game, got %d", len(games.Games))
+	}
+	game := games.Games[0]
+	if game.URL != "https://www.chess.com/game/daily/1" || game.MoveBy != 1700000000 || !game.DrawOffer {
+		t.Errorf("unexpected game to move: %+v", game)
+	}
+
+	fake.set("")
+	games, err = client.GetGamesToMove(context.Background(), "watched")
+	if err != nil {
+		t.Fatalf("expected success, got error: %v", err)
+	}
+	if len(games.Games) != 0 {
+		t.Errorf("expected no games to move, got %d", len(games.Games))
+	}
+
+	_, err = client.GetGamesToMove(context.Background(), "nonexistent")
+	if err == nil {
+		t.Error("expected error for 404 response")
+	}
+}
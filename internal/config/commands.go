@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 	"time"
 
@@ -145,6 +146,7 @@ func ShowCommand(c *cli.Context) error {
 		} else {
 			fmt.Printf("  Last import: never\n")
 		}
+		showOtherAccounts(cfg, "chesscom")
 	}
 
 	if cfg.Lichess != nil && cfg.Lichess.Username != "" {
@@ -158,11 +160,15 @@ func ShowCommand(c *cli.Context) error {
 		} else {
 			fmt.Printf("  Last import: never\n")
 		}
+		showOtherAccounts(cfg, "lichess")
 	}
 
 	if cfg.Engine != nil && cfg.Engine.Path != "" {
 		fmt.Println("\nEngine:")
 		fmt.Printf("  Path: %s\n", cfg.Engine.Path)
+		if cfg.Engine.Protocol != "" {
+			fmt.Printf("  Protocol: %s\n", cfg.Engine.Protocol)
+		}
 		if cfg.Engine.Threads > 0 {
 			fmt.Printf("  Threads: %d\n", cfg.Engine.Threads)
 		}
@@ -171,6 +177,85 @@ func ShowCommand(c *cli.Context) error {
 		}
 	}
 
+	if len(cfg.Engines) > 0 {
+		fmt.Println("\nEngine profiles:")
+		names := make([]string, 0, len(cfg.Engines))
+		for name := range cfg.Engines {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			e := cfg.Engines[name]
+			fmt.Printf("  %s: %s", name, e.Path)
+			if e.Protocol != "" {
+				fmt.Printf(" (%s)", e.Protocol)
+			}
+			fmt.Println()
+		}
+	}
+
+	if a := cfg.Analysis; a != nil {
+		fmt.Println("\nAnalysis:")
+		if a.Depth > 0 {
+			fmt.Printf("  Depth: %d\n", a.Depth)
+		}
+		if a.Lines > 0 {
+			fmt.Printf("  Lines: %d\n", a.Lines)
+		}
+		if a.InaccuracyThreshold > 0 || a.MistakeThreshold > 0 || a.BlunderThreshold > 0 {
+			fmt.Printf("  Thresholds: inaccuracy %d, mistake %d, blunder %d (centipawns, 0 = default)\n",
+				a.InaccuracyThreshold, a.MistakeThreshold, a.BlunderThreshold)
+		}
+	}
+
+	if cfg.TUI != nil {
+		fmt.Println("\nTUI:")
+		if cfg.TUI.Theme != "" {
+			fmt.Printf("  Theme: %s\n", cfg.TUI.Theme)
+		}
+		if cfg.TUI.Pieces != "" {
+			fmt.Printf("  Pieces: %s\n", cfg.TUI.Pieces)
+		}
+		if cfg.TUI.Highlight != "" {
+			fmt.Printf("  Highlight: %s\n", cfg.TUI.Highlight)
+		}
+		if cfg.TUI.HideCoordinates {
+			fmt.Printf("  Coordinates: hidden\n")
+		}
+		if cfg.TUI.TimeControl != "" {
+			fmt.Printf("  Time control: %s\n", cfg.TUI.TimeControl)
+		}
+		if len(cfg.TUI.Keys) > 0 {
+			fmt.Printf("  Keys:\n")
+			actions := make([]string, 0, len(cfg.TUI.Keys))
+			for action := range cfg.TUI.Keys {
+				actions = append(actions, action)
+			}
+			sort.Strings(actions)
+			for _, action := range actions {
+				fmt.Printf("    %s: %s\n", action, strings.Join(cfg.TUI.Keys[action], ", "))
+			}
+		}
+	}
+
+	if n := cfg.Notify; n != nil {
+		fmt.Println("\nNotifications:")
+		if n.Webhook != "" {
+			fmt.Printf("  Webhook: %s\n", n.Webhook)
+		}
+		if n.Ntfy != "" {
+			fmt.Printf("  ntfy: %s\n", n.Ntfy)
+		}
+		if n.Slack != "" {
+			fmt.Printf("  Slack: configured\n")
+		}
+	}
+
+	if tags := cfg.GetRequiredTags(); len(tags) > 0 {
+		fmt.Println("\nImport:")
+		fmt.Printf("  Required tags: %s\n", strings.Join(tags, ", "))
+	}
+
 	if !cfg.HasAnySource() {
 		fmt.Println("\nNo game sources configured.")
 	}
@@ -178,6 +263,23 @@ func ShowCommand(c *cli.Context) error {
 	return nil
 }
 
+// showOtherAccounts prints the accounts configured on platform besides
+// its main username, with their last imports
+func showOtherAccounts(cfg *Config, platform string) {
+	usernames := cfg.GetUsernames(platform)
+	if len(usernames) < 2 {
+		return
+	}
+	fmt.Println("  Other accounts:")
+	for _, username := range usernames[1:] {
+		last := "never imported"
+		if lastImport, ok := cfg.GetLastImport(platform, username); ok {
+			last = "last import " + lastImport.Format("2006-01-02 15:04:05")
+		}
+		fmt.Printf("    %s (%s)\n", username, last)
+	}
+}
+
 // AddUserCommand adds a user to the configuration
 func AddUserCommand(c *cli.Context) error {
 	platform := c.String("platform")
@@ -219,6 +321,33 @@ func AddUserCommand(c *cli.Context) error {
 		}
 	}
 
+	// Another account is added to those of the platform
+	if c.Bool("additional") {
+		var main string
+		switch platform {
+		case "chesscom":
+			if cfg.ChessCom == nil {
+				cfg.ChessCom = &ChessComConfig{}
+			}
+			main = cfg.ChessCom.Username
+			cfg.ChessCom.Usernames = append(cfg.ChessCom.Usernames, username)
+		case "lichess":
+			if cfg.Lichess == nil {
+				cfg.Lichess = &LichessConfig{}
+			}
+			main = cfg.Lichess.Username
+			cfg.Lichess.Usernames = append(cfg.Lichess.Usernames, username)
+		}
+		if main == "" {
+			return fmt.Errorf("no %s user configured yet; add the main account without --additional first", platform)
+		}
+		if err := cfg.SaveDefault(); err != nil {
+			return fmt.Errorf("failed to save config: %w", err)
+		}
+		fmt.Printf("Added %s as another %s account, imported along with %s\n", username, platform, main)
+		return nil
+	}
+
 	// Add the user
 	switch platform {
 	case "chesscom":
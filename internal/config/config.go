@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"gopkg.in/yaml.v3"
@@ -12,9 +13,10 @@ import (
 
 // EngineConfig holds chess engine configuration
 type EngineConfig struct {
-	Path    string `yaml:"path"`
-	Threads int    `yaml:"threads,omitempty"`
-	Hash    int    `yaml:"hash,omitempty"`
+	Path     string `yaml:"path"`
+	Protocol string `yaml:"protocol,omitempty"` // "uci" (default) or "cecp"
+	Threads  int    `yaml:"threads,omitempty"`
+	Hash     int    `yaml:"hash,omitempty"`
 }
 
 // Config represents the gochess configuration
@@ -24,18 +26,60 @@ type Config struct {
 	ChessCom     *ChessComConfig          `yaml:"chesscom,omitempty"`
 	Lichess      *LichessConfig           `yaml:"lichess,omitempty"`
 	Engine       *EngineConfig            `yaml:"engine,omitempty"`
+	Engines      map[string]*EngineConfig `yaml:"engines,omitempty"` // named engine profiles, selected with --engine <name>
+	Analysis     *AnalysisConfig          `yaml:"analysis,omitempty"`
+	TUI          *TUIConfig               `yaml:"tui,omitempty"`
+	Notify       *NotifyConfig            `yaml:"notify,omitempty"`
+	Import       *ImportConfig            `yaml:"import,omitempty"`
 	LastImport   map[string]time.Time     `yaml:"last_import,omitempty"`
 }
 
+// AnalysisConfig holds defaults for engine analysis. Zero values leave the
+// built-in defaults in place.
+type AnalysisConfig struct {
+	Depth               int `yaml:"depth,omitempty"`                // search depth per position
+	Lines               int `yaml:"lines,omitempty"`                // lines per position
+	InaccuracyThreshold int `yaml:"inaccuracy_threshold,omitempty"` // eval loss in centipawns
+	MistakeThreshold    int `yaml:"mistake_threshold,omitempty"`
+	BlunderThreshold    int `yaml:"blunder_threshold,omitempty"`
+}
+
+// TUIConfig holds terminal UI preferences
+type TUIConfig struct {
+	Theme           string              `yaml:"theme,omitempty"`     // board theme name
+	Pieces          string              `yaml:"pieces,omitempty"`    // "unicode" (default) or "letters"
+	Highlight       string              `yaml:"highlight,omitempty"` // "squares" (default), "markers" or "none"
+	HideCoordinates bool                `yaml:"hide_coordinates,omitempty"`
+	TimeControl     string              `yaml:"time_control,omitempty"` // play mode clock, "base+increment" in seconds
+	Keys            map[string][]string `yaml:"keys,omitempty"`         // remapped keys by action, e.g. "game.flip": ["F"]
+}
+
+// NotifyConfig holds where to send a notification when a sync or an
+// analysis finishes. Any of them can be set.
+type NotifyConfig struct {
+	Webhook string `yaml:"webhook,omitempty"` // URL receiving a JSON payload
+	Ntfy    string `yaml:"ntfy,omitempty"`    // ntfy topic URL, e.g. https://ntfy.sh/my-topic
+	Slack   string `yaml:"slack,omitempty"`   // Slack incoming webhook URL
+}
+
+// ImportConfig holds defaults for importing PGN files
+type ImportConfig struct {
+	// RequireTags are the tags a game must have to be imported; missing
+	// standard tags are otherwise filled with the PGN placeholders
+	RequireTags []string `yaml:"require_tags,omitempty"`
+}
+
 // ChessComConfig holds Chess.com specific configuration
 type ChessComConfig struct {
-	Username string `yaml:"username"`
+	Username  string   `yaml:"username"`
+	Usernames []string `yaml:"usernames,omitempty"` // more accounts imported along, e.g. a separate daily account
 }
 
 // LichessConfig holds Lichess specific configuration
 type LichessConfig struct {
-	Username string `yaml:"username"`
-	APIToken string `yaml:"api_token,omitempty"`
+	Username  string   `yaml:"username"`
+	Usernames []string `yaml:"usernames,omitempty"` // more accounts imported along; the API token is Username's
+	APIToken  string   `yaml:"api_token,omitempty"`
 }
 
 // DefaultConfigPath returns the default path to the config file
@@ -56,6 +100,55 @@ func DefaultDatabasePath() (string, error) {
 	return filepath.Join(home, ".gochess", "games.db"), nil
 }
 
+// DefaultLogPath returns the log file used while a TUI owns the terminal
+func DefaultLogPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".gochess", "gochess.log"), nil
+}
+
+// DefaultSyncLockPath returns the lock file held while a sync runs, so
+// that syncs do not overlap
+func DefaultSyncLockPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".gochess", "sync.lock"), nil
+}
+
+// DefaultSyncStatusPath returns the file where the sync daemon records its
+// state and the outcome of the last sync
+func DefaultSyncStatusPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".gochess", "sync-status.json"), nil
+}
+
+// DefaultSessionPath returns the file where the game browser saves its
+// state on quitting, to be restored on the next launch
+func DefaultSessionPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".gochess", "session.json"), nil
+}
+
+// DefaultArchiveCachePath returns the file caching the game counts of the
+// Chess.com monthly archives that are over
+func DefaultArchiveCachePath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".gochess", "chesscom-archives.json"), nil
+}
+
 // Load reads the configuration from the specified path
 func Load(path string) (*Config, error) {
 	data, err := os.ReadFile(path)
@@ -157,8 +250,7 @@ func (c *Config) ClearAllLastImports() {
 
 // HasAnySource returns true if at least one source is configured
 func (c *Config) HasAnySource() bool {
-	return (c.ChessCom != nil && c.ChessCom.Username != "") ||
-		(c.Lichess != nil && c.Lichess.Username != "")
+	return len(c.GetUsernames("chesscom")) > 0 || len(c.GetUsernames("lichess")) > 0
 }
 
 // GetLogLevel returns the configured log level, defaulting to "error" if not set
@@ -176,3 +268,77 @@ func (c *Config) GetEnginePath() string {
 	}
 	return ""
 }
+
+// GetEngineProtocol returns the configured engine protocol, or empty string if not set.
+func (c *Config) GetEngineProtocol() string {
+	if c.Engine != nil {
+		return c.Engine.Protocol
+	}
+	return ""
+}
+
+// GetRequiredTags returns the tags a game must have to be imported, or nil
+// if none are configured.
+func (c *Config) GetRequiredTags() []string {
+	if c.Import != nil {
+		return c.Import.RequireTags
+	}
+	return nil
+}
+
+// GetEngineProfile returns the engine profile called name, or nil if there
+// is none.
+func (c *Config) GetEngineProfile(name string) *EngineConfig {
+	return c.Engines[name]
+}
+
+// GetUsername returns the configured username for platform ("chesscom" or
+// "lichess"), or empty string if not set.
+func (c *Config) GetUsername(platform string) string {
+	switch platform {
+	case "chesscom":
+		if c.ChessCom != nil {
+			return c.ChessCom.Username
+		}
+	case "lichess":
+		if c.Lichess != nil {
+			return c.Lichess.Username
+		}
+	}
+	return ""
+}
+
+// GetUsernames returns the configured accounts on platform ("chesscom" or
+// "lichess"): the username first, then the other accounts, without
+// duplicates.
+func (c *Config) GetUsernames(platform string) []string {
+	var usernames []string
+	switch platform {
+	case "chesscom":
+		if c.ChessCom != nil {
+			usernames = append([]string{c.ChessCom.Username}, c.ChessCom.Usernames...)
+		}
+	case "lichess":
+		if c.Lichess != nil {
+			usernames = append([]string{c.Lichess.Username}, c.Lichess.Usernames...)
+		}
+	}
+	return uniqueUsernames(usernames)
+}
+
+// uniqueUsernames returns usernames without empty or repeated ones, which
+// are compared ignoring case as on Chess.com and Lichess.
+func uniqueUsernames(usernames []string) []string {
+	var unique []string
+	seen := make(map[string]bool)
+	for _, username := range usernames {
+		username = strings.TrimSpace(username)
+		key := strings.ToLower(username)
+		if username == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		unique = append(unique, username)
+	}
+	return unique
+}
@@ -332,6 +332,133 @@ func TestConfig_GetEnginePath_Nil(t *testing.T) {
 	assert.Equal(t, "", cfg.GetEnginePath())
 }
 
+func TestConfig_EngineProtocolRoundTrip(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "gochess-config-test")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	configPath := filepath.Join(tmpDir, "config.yaml")
+
+	cfg := &Config{
+		DatabasePath: "/path/to/games.db",
+		Engine: &EngineConfig{
+			Path:     "/usr/games/crafty",
+			Protocol: "cecp",
+		},
+		LastImport: map[string]time.Time{},
+	}
+
+	err = cfg.Save(configPath)
+	require.NoError(t, err)
+
+	loaded, err := Load(configPath)
+	require.NoError(t, err)
+
+	assert.Equal(t, "cecp", loaded.GetEngineProtocol())
+	assert.Equal(t, "", (&Config{}).GetEngineProtocol())
+}
+
+func TestConfig_TUIRoundTrip(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "gochess-config-test")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	configPath := filepath.Join(tmpDir, "config.yaml")
+
+	cfg := &Config{
+		DatabasePath: "/path/to/games.db",
+		TUI: &TUIConfig{
+			Theme:           "blue",
+			Pieces:          "letters",
+			Highlight:       "markers",
+			HideCoordinates: true,
+			TimeControl:     "180+2",
+			Keys: map[string][]string{
+				"game.flip": {"F"},
+				"game.prev": {"left", "a"},
+			},
+		},
+		LastImport: map[string]time.Time{},
+	}
+
+	err = cfg.Save(configPath)
+	require.NoError(t, err)
+
+	loaded, err := Load(configPath)
+	require.NoError(t, err)
+
+	require.NotNil(t, loaded.TUI)
+	assert.Equal(t, *cfg.TUI, *loaded.TUI)
+}
+
+func TestConfig_EngineProfilesAndAnalysisRoundTrip(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "gochess-config-test")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	configPath := filepath.Join(tmpDir, "config.yaml")
+
+	cfg := &Config{
+		DatabasePath: "/path/to/games.db",
+		Engines: map[string]*EngineConfig{
+			"sf":     {Path: "/usr/local/bin/stockfish", Threads: 8},
+			"crafty": {Path: "/usr/games/crafty", Protocol: "cecp"},
+		},
+		Analysis: &AnalysisConfig{
+			Depth:            20,
+			Lines:            3,
+			BlunderThreshold: 300,
+		},
+		LastImport: map[string]time.Time{},
+	}
+
+	err = cfg.Save(configPath)
+	require.NoError(t, err)
+
+	loaded, err := Load(configPath)
+	require.NoError(t, err)
+
+	require.NotNil(t, loaded.GetEngineProfile("sf"))
+	assert.Equal(t, 8, loaded.GetEngineProfile("sf").Threads)
+	assert.Equal(t, "cecp", loaded.GetEngineProfile("crafty").Protocol)
+	assert.Nil(t, loaded.GetEngineProfile("missing"))
+	assert.Nil(t, (&Config{}).GetEngineProfile("sf"))
+	require.NotNil(t, loaded.Analysis)
+	assert.Equal(t, *cfg.Analysis, *loaded.Analysis)
+}
+
+func TestConfig_ImportRoundTrip(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "gochess-config-test")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	configPath := filepath.Join(tmpDir, "config.yaml")
+
+	cfg := &Config{
+		DatabasePath: "/path/to/games.db",
+		Import:       &ImportConfig{RequireTags: []string{"White", "Black"}},
+		LastImport:   map[string]time.Time{},
+	}
+
+	err = cfg.Save(configPath)
+	require.NoError(t, err)
+
+	loaded, err := Load(configPath)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"White", "Black"}, loaded.GetRequiredTags())
+	assert.Nil(t, (&Config{}).GetRequiredTags())
+}
+
+func TestConfig_GetUsername(t *testing.T) {
+	cfg := &Config{
+		ChessCom: &ChessComConfig{Username: "alice"},
+	}
+	assert.Equal(t, "alice", cfg.GetUsername("chesscom"))
+	assert.Equal(t, "", cfg.GetUsername("lichess"))
+	assert.Equal(t, "", cfg.GetUsername("unknown"))
+}
+
 func TestClearAllLastImports(t *testing.T) {
 	cfg := &Config{
 		LastImport: map[string]time.Time{
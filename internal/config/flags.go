@@ -0,0 +1,91 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+)
+
+// DatabasePath returns the database a command should use: its --database
+// flag if given, else the configured database path, else the default one.
+func DatabasePath(c *cli.Context) (string, error) {
+	if path := c.String("database"); path != "" {
+		return path, nil
+	}
+	cfg, err := LoadOrDefault()
+	if err != nil {
+		return "", fmt.Errorf("failed to load config: %w", err)
+	}
+	if cfg.DatabasePath != "" {
+		return cfg.DatabasePath, nil
+	}
+	return DefaultDatabasePath()
+}
+
+// Username returns the username a command should use for platform: its
+// --username flag if given, else the configured username.
+func Username(c *cli.Context, platform string) (string, error) {
+	if username := c.String("username"); username != "" {
+		return username, nil
+	}
+	cfg, err := LoadOrDefault()
+	if err != nil {
+		return "", fmt.Errorf("failed to load config: %w", err)
+	}
+	if username := cfg.GetUsername(platform); username != "" {
+		return username, nil
+	}
+	return "", fmt.Errorf("a --username is required (or configure one with 'gochess config add-user --platform %s')", platform)
+}
+
+// UsernamePlaceholder is replaced with the username in the --output path of
+// a download for several accounts, as in games-{username}.pgn.
+const UsernamePlaceholder = "{username}"
+
+// Usernames returns the accounts a download for platform should fetch: the
+// usernames of its repeated --username flag and of its --usernames-file, one
+// per line with # starting a comment, else the configured accounts.
+func Usernames(c *cli.Context, platform string) ([]string, error) {
+	usernames := c.StringSlice("username")
+	if path := c.String("usernames-file"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read usernames file: %w", err)
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			line, _, _ = strings.Cut(line, "#")
+			usernames = append(usernames, line)
+		}
+	}
+	if usernames = uniqueUsernames(usernames); len(usernames) > 0 {
+		return usernames, nil
+	}
+	if c.IsSet("username") || c.IsSet("usernames-file") {
+		return nil, fmt.Errorf("no usernames given")
+	}
+
+	cfg, err := LoadOrDefault()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	if usernames := cfg.GetUsernames(platform); len(usernames) > 0 {
+		return usernames, nil
+	}
+	return nil, fmt.Errorf("a --username is required (or configure one with 'gochess config add-user --platform %s')", platform)
+}
+
+// OutputForUsername returns the --output path of the download for username,
+// one of count accounts. Downloads for several accounts to a file need
+// UsernamePlaceholder in its path, so that each account has its own.
+func OutputForUsername(path, username string, count int) (string, error) {
+	if path == "" || count < 2 {
+		return strings.ReplaceAll(path, UsernamePlaceholder, username), nil
+	}
+	if !strings.Contains(path, UsernamePlaceholder) {
+		return "", fmt.Errorf("--output needs %s in its path to download for several usernames, as in games-%s.pgn",
+			UsernamePlaceholder, UsernamePlaceholder)
+	}
+	return strings.ReplaceAll(path, UsernamePlaceholder, username), nil
+}
@@ -0,0 +1,139 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+// flagContext returns a cli context with the --database and --username
+// flags set to the given values, if not empty.
+func flagContext(t *testing.T, database, username string) *cli.Context {
+	t.Helper()
+	set := flag.NewFlagSet("test", flag.ContinueOnError)
+	set.String("database", "", "")
+	set.String("username", "", "")
+	var args []string
+	if database != "" {
+		args = append(args, "--database", database)
+	}
+	if username != "" {
+		args = append(args, "--username", username)
+	}
+	require.NoError(t, set.Parse(args))
+	return cli.NewContext(cli.NewApp(), set, nil)
+}
+
+func TestDatabasePath(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+
+	t.Run("default without config", func(t *testing.T) {
+		path, err := DatabasePath(flagContext(t, "", ""))
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(tmpDir, ".gochess", "games.db"), path)
+	})
+
+	cfg := &Config{DatabasePath: "/configured/games.db", LastImport: map[string]time.Time{}}
+	require.NoError(t, cfg.SaveDefault())
+
+	t.Run("configured", func(t *testing.T) {
+		path, err := DatabasePath(flagContext(t, "", ""))
+		require.NoError(t, err)
+		assert.Equal(t, "/configured/games.db", path)
+	})
+
+	t.Run("flag overrides config", func(t *testing.T) {
+		path, err := DatabasePath(flagContext(t, "other.db", ""))
+		require.NoError(t, err)
+		assert.Equal(t, "other.db", path)
+	})
+}
+
+func TestUsername(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	_, err := Username(flagContext(t, "", ""), "chesscom")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--username is required")
+
+	cfg := &Config{ChessCom: &ChessComConfig{Username: "alice"}, LastImport: map[string]time.Time{}}
+	require.NoError(t, cfg.SaveDefault())
+
+	username, err := Username(flagContext(t, "", ""), "chesscom")
+	require.NoError(t, err)
+	assert.Equal(t, "alice", username)
+
+	username, err = Username(flagContext(t, "", "bob"), "chesscom")
+	require.NoError(t, err)
+	assert.Equal(t, "bob", username)
+}
+
+// usernamesContext returns a cli context with the repeatable --username
+// flag and the --usernames-file flag parsed from args.
+func usernamesContext(t *testing.T, args ...string) *cli.Context {
+	t.Helper()
+	set := flag.NewFlagSet("test", flag.ContinueOnError)
+	require.NoError(t, (&cli.StringSliceFlag{Name: "username"}).Apply(set))
+	require.NoError(t, (&cli.StringFlag{Name: "usernames-file"}).Apply(set))
+	require.NoError(t, set.Parse(args))
+	return cli.NewContext(cli.NewApp(), set, nil)
+}
+
+func TestUsernames(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+
+	_, err := Usernames(usernamesContext(t), "chesscom")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--username is required")
+
+	cfg := &Config{
+		ChessCom:   &ChessComConfig{Username: "alice", Usernames: []string{"alice_daily", "Alice"}},
+		LastImport: map[string]time.Time{},
+	}
+	require.NoError(t, cfg.SaveDefault())
+	assert.True(t, cfg.HasAnySource())
+
+	usernames, err := Usernames(usernamesContext(t), "chesscom")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alice", "alice_daily"}, usernames, "the configured accounts, without repeats")
+
+	usernames, err = Usernames(usernamesContext(t, "--username", "bob", "--username", "carol"), "chesscom")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bob", "carol"}, usernames)
+
+	file := filepath.Join(tmpDir, "usernames.txt")
+	require.NoError(t, os.WriteFile(file, []byte("# my accounts\ndave\n\n  erin  # daily\nBOB\n"), 0644))
+	usernames, err = Usernames(usernamesContext(t, "--username", "bob", "--usernames-file", file), "chesscom")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bob", "dave", "erin"}, usernames)
+
+	empty := filepath.Join(tmpDir, "empty.txt")
+	require.NoError(t, os.WriteFile(empty, []byte("# none yet\n"), 0644))
+	_, err = Usernames(usernamesContext(t, "--usernames-file", empty), "chesscom")
+	assert.Error(t, err, "an empty file does not fall back to the configured accounts")
+}
+
+func TestOutputForUsername(t *testing.T) {
+	path, err := OutputForUsername("games.pgn", "alice", 1)
+	require.NoError(t, err)
+	assert.Equal(t, "games.pgn", path)
+
+	_, err = OutputForUsername("games.pgn", "alice", 2)
+	assert.Error(t, err, "several accounts would write to the same file")
+
+	path, err = OutputForUsername("games-{username}.pgn", "alice", 2)
+	require.NoError(t, err)
+	assert.Equal(t, "games-alice.pgn", path)
+
+	path, err = OutputForUsername("", "alice", 2)
+	require.NoError(t, err)
+	assert.Equal(t, "", path)
+}
@@ -0,0 +1,263 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/kyleboon/gochess/internal"
+)
+
+// ErrAnalysisNotFound is returned when a game has not been analyzed.
+var ErrAnalysisNotFound = errors.New("analysis not found")
+
+// Where the analyses of games come from
+const (
+	AnalysisSourceEngine  = "engine"  // reviewed with the engine by gochess
+	AnalysisSourceLichess = "lichess" // read from the [%eval] comments of an imported game
+)
+
+// GameAnalysis is an engine review of a game, as saved by the analysis
+// workers of the server or read from a Lichess export when it is imported.
+type GameAnalysis struct {
+	ID        int    `json:"id"`
+	GameID    int    `json:"game_id"`
+	Source    string `json:"source"` // AnalysisSourceEngine, the default, or AnalysisSourceLichess
+	Depth     int    `json:"depth"`  // 0 if unknown
+	Lines     int    `json:"lines"`
+	CreatedAt string `json:"created_at"`
+
+	// Counts of the classified moves, by color
+	WhiteInaccuracies int `json:"white_inaccuracies"`
+	WhiteMistakes     int `json:"white_mistakes"`
+	WhiteBlunders     int `json:"white_blunders"`
+	BlackInaccuracies int `json:"black_inaccuracies"`
+	BlackMistakes     int `json:"black_mistakes"`
+	BlackBlunders     int `json:"black_blunders"`
+
+	Moves []AnalysisMove `json:"moves"`
+}
+
+// AnalysisMove is the verdict on one move of an analyzed game. Evaluations
+// are in centipawns from White's view.
+type AnalysisMove struct {
+	Ply            int      `json:"ply"`
+	SAN            string   `json:"san"`
+	Best           string   `json:"best,omitempty"`
+	EvalBefore     int      `json:"eval_before"`
+	EvalAfter      int      `json:"eval_after"`
+	Loss           int      `json:"loss"`
+	Classification string   `json:"classification"`
+	Motifs         []string `json:"motifs,omitempty"` // tactical motifs, one of internal.MotifTypes each
+}
+
+// ValidateMotif returns an error unless motif is one of internal.MotifTypes.
+func ValidateMotif(motif string) error {
+	if slices.Contains(internal.MotifTypes, motif) {
+		return nil
+	}
+	return fmt.Errorf("unknown motif %q; use %s", motif, strings.Join(internal.MotifTypes, ", "))
+}
+
+// createAnalysisTables creates the tables holding game analyses
+func (db *DB) createAnalysisTables() error {
+	_, err := db.conn.Exec(`
+		CREATE TABLE IF NOT EXISTS analyses (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			game_id INTEGER NOT NULL,
+			depth INTEGER NOT NULL,
+			lines INTEGER NOT NULL,
+			source TEXT NOT NULL DEFAULT 'engine',
+			white_inaccuracies INTEGER NOT NULL DEFAULT 0,
+			white_mistakes INTEGER NOT NULL DEFAULT 0,
+			white_blunders INTEGER NOT NULL DEFAULT 0,
+			black_inaccuracies INTEGER NOT NULL DEFAULT 0,
+			black_mistakes INTEGER NOT NULL DEFAULT 0,
+			black_blunders INTEGER NOT NULL DEFAULT 0,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (game_id) REFERENCES games(id) ON DELETE CASCADE
+		);
+		CREATE INDEX IF NOT EXISTS idx_analyses_game ON analyses(game_id);
+		CREATE TABLE IF NOT EXISTS analysis_moves (
+			analysis_id INTEGER NOT NULL,
+			ply INTEGER NOT NULL,
+			san TEXT NOT NULL,
+			best TEXT,
+			eval_before INTEGER NOT NULL,
+			eval_after INTEGER NOT NULL,
+			loss INTEGER NOT NULL,
+			classification TEXT NOT NULL,
+			PRIMARY KEY (analysis_id, ply),
+			FOREIGN KEY (analysis_id) REFERENCES analyses(id) ON DELETE CASCADE
+		);
+		CREATE TABLE IF NOT EXISTS analysis_motifs (
+			analysis_id INTEGER NOT NULL,
+			ply INTEGER NOT NULL,
+			motif TEXT NOT NULL,
+			PRIMARY KEY (analysis_id, ply, motif),
+			FOREIGN KEY (analysis_id) REFERENCES analyses(id) ON DELETE CASCADE
+		);
+		CREATE INDEX IF NOT EXISTS idx_analysis_motifs_motif ON analysis_motifs(motif)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create analysis tables: %w", err)
+	}
+	// Analyses saved before Lichess evaluations were imported are the engine's
+	if err := db.addColumnIfNotExists("analyses", "source TEXT NOT NULL DEFAULT 'engine'"); err != nil {
+		return fmt.Errorf("failed to add source column: %w", err)
+	}
+	return nil
+}
+
+// SaveAnalysis saves the analysis of a game and sets the ID of a. The
+// evaluations of its positions, in pawns keyed by ply, are stored with the
+// game's positions, where the TUI and the other commands find them.
+func (db *DB) SaveAnalysis(ctx context.Context, a *GameAnalysis, evals map[int]float64) error {
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	id, err := insertAnalysis(ctx, tx, a, evals)
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	a.ID = int(id)
+	return nil
+}
+
+// insertAnalysis inserts the analysis of a game and the evaluations of its
+// positions in tx, and returns the analysis ID.
+func insertAnalysis(ctx context.Context, tx *sql.Tx, a *GameAnalysis, evals map[int]float64) (int64, error) {
+	source := a.Source
+	if source == "" {
+		source = AnalysisSourceEngine
+	}
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO analyses (game_id, depth, lines, source,
+			white_inaccuracies, white_mistakes, white_blunders,
+			black_inaccuracies, black_mistakes, black_blunders)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, a.GameID, a.Depth, a.Lines, source,
+		a.WhiteInaccuracies, a.WhiteMistakes, a.WhiteBlunders,
+		a.BlackInaccuracies, a.BlackMistakes, a.BlackBlunders)
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert analysis: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get analysis ID: %w", err)
+	}
+
+	for _, m := range a.Moves {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO analysis_moves (analysis_id, ply, san, best, eval_before, eval_after, loss, classification)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		`, id, m.Ply, m.SAN, m.Best, m.EvalBefore, m.EvalAfter, m.Loss, m.Classification); err != nil {
+			return 0, fmt.Errorf("failed to insert analysis move: %w", err)
+		}
+		for _, motif := range m.Motifs {
+			if _, err := tx.ExecContext(ctx, `
+				INSERT OR IGNORE INTO analysis_motifs (analysis_id, ply, motif) VALUES (?, ?, ?)
+			`, id, m.Ply, motif); err != nil {
+				return 0, fmt.Errorf("failed to insert analysis motif: %w", err)
+			}
+		}
+	}
+	for ply, eval := range evals {
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE positions SET evaluation = ? WHERE game_id = ? AND move_number = ?
+		`, eval, a.GameID, ply); err != nil {
+			return 0, fmt.Errorf("failed to update evaluation: %w", err)
+		}
+	}
+	return id, nil
+}
+
+// GetLatestAnalysis returns the most recent analysis of a game, or
+// ErrAnalysisNotFound if it has none.
+func (db *DB) GetLatestAnalysis(ctx context.Context, gameID int) (*GameAnalysis, error) {
+	a := &GameAnalysis{GameID: gameID, Moves: []AnalysisMove{}}
+	err := db.conn.QueryRowContext(ctx, `
+		SELECT id, source, depth, lines, created_at,
+			white_inaccuracies, white_mistakes, white_blunders,
+			black_inaccuracies, black_mistakes, black_blunders
+		FROM analyses WHERE game_id = ?
+		ORDER BY id DESC LIMIT 1
+	`, gameID).Scan(&a.ID, &a.Source, &a.Depth, &a.Lines, &a.CreatedAt,
+		&a.WhiteInaccuracies, &a.WhiteMistakes, &a.WhiteBlunders,
+		&a.BlackInaccuracies, &a.BlackMistakes, &a.BlackBlunders)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrAnalysisNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query analysis: %w", err)
+	}
+
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT ply, san, COALESCE(best, ''), eval_before, eval_after, loss, classification
+		FROM analysis_moves WHERE analysis_id = ?
+		ORDER BY ply
+	`, a.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query analysis moves: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		var m AnalysisMove
+		if err := rows.Scan(&m.Ply, &m.SAN, &m.Best, &m.EvalBefore, &m.EvalAfter, &m.Loss, &m.Classification); err != nil {
+			return nil, fmt.Errorf("failed to scan analysis move: %w", err)
+		}
+		a.Moves = append(a.Moves, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read analysis moves: %w", err)
+	}
+
+	motifs, err := db.analysisMotifs(ctx, a.ID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range a.Moves {
+		a.Moves[i].Motifs = motifs[a.Moves[i].Ply]
+	}
+	return a, nil
+}
+
+// analysisMotifs returns the motifs tagged in an analysis, keyed by ply
+func (db *DB) analysisMotifs(ctx context.Context, analysisID int) (map[int][]string, error) {
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT ply, motif FROM analysis_motifs WHERE analysis_id = ?
+	`, analysisID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query analysis motifs: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	motifs := make(map[int][]string)
+	for rows.Next() {
+		var ply int
+		var motif string
+		if err := rows.Scan(&ply, &motif); err != nil {
+			return nil, fmt.Errorf("failed to scan analysis motif: %w", err)
+		}
+		motifs[ply] = append(motifs[ply], motif)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read analysis motifs: %w", err)
+	}
+	// in the order of internal.MotifTypes, as they were found
+	for _, m := range motifs {
+		slices.SortFunc(m, func(a, b string) int {
+			return slices.Index(internal.MotifTypes, a) - slices.Index(internal.MotifTypes, b)
+		})
+	}
+	return motifs, nil
+}
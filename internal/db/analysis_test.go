@@ -0,0 +1,113 @@
+package db
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/kyleboon/gochess/internal"
+	"github.com/kyleboon/gochess/internal/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSaveAnalysis(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "gochess-analysis-test-")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(tempDir) }()
+
+	database, err := NewWithLogger(tempDir+"/test.db", logging.Discard())
+	require.NoError(t, err)
+	defer func() { _ = database.Close() }()
+	ctx := context.Background()
+
+	pgnFile := tempDir + "/game.pgn"
+	require.NoError(t, os.WriteFile(pgnFile, []byte(`[Event "1"] [Site "?"] [Date "2024.01.01"] [White "Alice"] [Black "Bob"] [Result "1-0"] 1. e4 f5 2. Qh5+ 1-0`), 0644))
+	count, errs := database.ImportPGN(ctx, pgnFile)
+	require.Empty(t, errs)
+	require.Equal(t, 1, count)
+
+	_, err = database.GetLatestAnalysis(ctx, 1)
+	assert.ErrorIs(t, err, ErrAnalysisNotFound)
+
+	a := &GameAnalysis{
+		GameID:        1,
+		Depth:         12,
+		Lines:         2,
+		BlackBlunders: 1,
+		Moves: []AnalysisMove{
+			{Ply: 1, SAN: "e4", Best: "e4", EvalBefore: 20, EvalAfter: 30, Classification: "normal"},
+			{Ply: 2, SAN: "f5", Best: "e5", EvalBefore: 30, EvalAfter: 250, Loss: 220, Classification: "blunder"},
+			{Ply: 3, SAN: "Qh5+", Best: "Qh5+", EvalBefore: 250, EvalAfter: 260, Classification: "normal"},
+		},
+	}
+	require.NoError(t, database.SaveAnalysis(ctx, a, map[int]float64{0: 0.2, 1: 0.3, 2: 2.5, 3: 2.6}))
+	assert.NotZero(t, a.ID)
+
+	saved, err := database.GetLatestAnalysis(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, a.ID, saved.ID)
+	assert.Equal(t, 12, saved.Depth)
+	assert.Equal(t, 1, saved.BlackBlunders)
+	require.Len(t, saved.Moves, 3)
+	assert.Equal(t, "blunder", saved.Moves[1].Classification)
+	assert.Equal(t, "e5", saved.Moves[1].Best)
+
+	// The evaluations are stored with the positions
+	positions, err := database.GetPositionsForGame(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, positions, 4)
+	require.NotNil(t, positions[2].Evaluation)
+	assert.Equal(t, 2.5, *positions[2].Evaluation)
+}
+
+func TestAnalysisMotifs(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "gochess-analysis-test-")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(tempDir) }()
+
+	database, err := NewWithLogger(tempDir+"/test.db", logging.Discard())
+	require.NoError(t, err)
+	defer func() { _ = database.Close() }()
+	ctx := context.Background()
+
+	pgnFile := tempDir + "/games.pgn"
+	require.NoError(t, os.WriteFile(pgnFile, []byte(`[Event "1"] [Site "?"] [Date "2024.01.01"] [White "Alice"] [Black "Bob"] [Result "1-0"] 1. e4 f5 2. Qh5+ 1-0
+
+[Event "2"] [Site "?"] [Date "2024.01.02"] [White "Bob"] [Black "Alice"] [Result "1-0"] 1. d4 d5 1-0`), 0644))
+	count, errs := database.ImportPGN(ctx, pgnFile)
+	require.Empty(t, errs)
+	require.Equal(t, 2, count)
+
+	save := func(gameID int, motifs ...string) {
+		require.NoError(t, database.SaveAnalysis(ctx, &GameAnalysis{
+			GameID: gameID,
+			Depth:  12,
+			Lines:  2,
+			Moves: []AnalysisMove{
+				{Ply: 2, SAN: "f5", Best: "e5", EvalBefore: 30, EvalAfter: 250, Loss: 220, Classification: "blunder", Motifs: motifs},
+			},
+		}, nil))
+	}
+	save(1, internal.SkewerMotif, internal.ForkMotif)
+	save(2, internal.PinMotif)
+
+	saved, err := database.GetLatestAnalysis(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, saved.Moves, 1)
+	assert.Equal(t, []string{internal.ForkMotif, internal.SkewerMotif}, saved.Moves[0].Motifs)
+
+	games, err := database.SearchGames(ctx, map[string]string{"motif": internal.ForkMotif}, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, games, 1)
+	assert.Equal(t, "1", games[0]["event"])
+
+	// Only the latest analysis of a game counts
+	save(2)
+	games, err = database.SearchGames(ctx, map[string]string{"motif": internal.PinMotif}, 10, 0)
+	require.NoError(t, err)
+	assert.Empty(t, games)
+
+	assert.NoError(t, ValidateMotif(internal.BackRankMotif))
+	assert.Error(t, ValidateMotif("zwischenzug"))
+}
@@ -0,0 +1,150 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/kyleboon/gochess/internal/pgn"
+)
+
+// maxExportMateDistance bounds the mates written as "#N" by evalComment;
+// analyses score mate in N as ±(commentMateScore-N).
+const maxExportMateDistance = 1000
+
+// classificationNags are the NAGs written for the classified moves of an
+// analysis, the inverse of nagClassifications.
+var classificationNags = map[string]pgn.Nag{
+	"great":      1,
+	"mistake":    2,
+	"brilliant":  3,
+	"blunder":    4,
+	"inaccuracy": 6,
+}
+
+// classificationComments introduce the best move of the moves judged bad,
+// in the words Lichess uses.
+var classificationComments = map[string]string{
+	"inaccuracy": "Inaccuracy.",
+	"mistake":    "Mistake.",
+	"blunder":    "Blunder.",
+}
+
+// ExportPGN returns the PGN of a game. With withAnalysis, the latest
+// analysis of the game is merged into it as annotations SCID, ChessBase and
+// Lichess read: an [%eval] comment after every move, a NAG on every
+// classified move, and the best move as a variation of every inaccuracy,
+// mistake and blunder. Games without an analysis are returned as stored.
+func (db *DB) ExportPGN(ctx context.Context, id int, withAnalysis bool) (string, error) {
+	game, err := db.GetGame(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	text := game.PGNText
+	if !withAnalysis {
+		return text, nil
+	}
+
+	analysis, err := db.GetLatestAnalysis(ctx, id)
+	if errors.Is(err, ErrAnalysisNotFound) {
+		return text, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	parsed, err := parseStoredGame(text)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse game %d: %w", id, err)
+	}
+	annotateGame(parsed, analysis)
+	return parsed.String(), nil
+}
+
+// annotateGame adds the verdicts of an analysis to the main line of a game.
+// Annotations the game already has, as in a Lichess export, are kept and
+// not repeated.
+func annotateGame(game *pgn.Game, a *GameAnalysis) {
+	moves := make(map[int]AnalysisMove, len(a.Moves))
+	for _, m := range a.Moves {
+		moves[m.Ply] = m
+	}
+
+	ply := 0
+	for n := game.Root; n.Next != nil; n = n.Next {
+		ply++
+		next := n.Next
+		m, ok := moves[ply]
+		if !ok || m.SAN != next.Move.San(n.Board) {
+			continue
+		}
+
+		if nag, ok := classificationNags[m.Classification]; ok && !hasMoveNag(next) {
+			next.AddNag(nag)
+		}
+		if intro, ok := classificationComments[m.Classification]; ok && m.Best != "" && m.Best != m.SAN {
+			if best, err := n.Board.ParseMove(m.Best); err == nil && !hasVariation(next, m.Best) {
+				if !hasBestComment(next) {
+					next.Comment = append(next.Comment, fmt.Sprintf("%s %s was best.", intro, m.Best))
+				}
+				next.NewVariation().Insert(best)
+			}
+		}
+		if _, ok := next.Eval(); !ok {
+			if eval, ok := evalComment(m.EvalAfter); ok {
+				next.Comment = append(next.Comment, eval)
+			}
+		}
+	}
+}
+
+// evalComment returns the [%eval] command for an evaluation in centipawns
+// from White's view, with forced mates as "#N" or "#-N". There is none for
+// a position that is already mate.
+func evalComment(cp int) (string, bool) {
+	distance := commentMateScore - max(cp, -cp)
+	switch {
+	case distance <= 0:
+		return "", false
+	case distance < maxExportMateDistance:
+		if cp < 0 {
+			distance = -distance
+		}
+		return fmt.Sprintf("[%%eval #%d]", distance), true
+	}
+	return "[%eval " + strconv.FormatFloat(float64(cp)/100, 'f', 2, 64) + "]", true
+}
+
+// hasMoveNag reports whether the move already has a NAG judging it (!, ?,
+// !!, ??, !? or ?!).
+func hasMoveNag(n *pgn.Node) bool {
+	for _, nag := range n.Nags {
+		if nag >= 1 && nag <= 6 {
+			return true
+		}
+	}
+	return false
+}
+
+// hasVariation reports whether one of the move's variations starts with
+// the move san.
+func hasVariation(n *pgn.Node, san string) bool {
+	for _, v := range n.Variations() {
+		if v.Next.Move.San(v.Board) == san {
+			return true
+		}
+	}
+	return false
+}
+
+// hasBestComment reports whether a comment of the move already names the
+// best move.
+func hasBestComment(n *pgn.Node) bool {
+	for _, c := range n.Comment {
+		if strings.Contains(c, "was best.") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+package db
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/kyleboon/gochess/internal/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportPGN_WithAnalysis(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "gochess-annotate-test-")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(tempDir) }()
+
+	database, err := NewWithLogger(tempDir+"/test.db", logging.Discard())
+	require.NoError(t, err)
+	defer func() { _ = database.Close() }()
+	ctx := context.Background()
+
+	pgnFile := tempDir + "/games.pgn"
+	game := `[Event "1"] [Site "?"] [Date "2024.01.01"] [White "Alice"] [Black "Bob"] [Result "1-0"] 1. e4 f5 2. Qh5+ g6 1-0`
+	require.NoError(t, os.WriteFile(pgnFile, []byte(game+"\n\n"+lichessAnalyzedPGN), 0644))
+	count, errs := database.ImportPGN(ctx, pgnFile)
+	require.Empty(t, errs)
+	require.Equal(t, 2, count)
+
+	plain, err := database.ExportPGN(ctx, 1, true)
+	require.NoError(t, err)
+	assert.Equal(t, game, plain, "a game without analysis is exported as stored")
+
+	require.NoError(t, database.SaveAnalysis(ctx, &GameAnalysis{
+		GameID: 1,
+		Depth:  12,
+		Lines:  2,
+		Moves: []AnalysisMove{
+			{Ply: 1, SAN: "e4", Best: "e4", EvalBefore: 20, EvalAfter: 30, Classification: "normal"},
+			{Ply: 2, SAN: "f5", Best: "e5", EvalBefore: 30, EvalAfter: 250, Loss: 220, Classification: "blunder"},
+			{Ply: 3, SAN: "Qh5+", Best: "Qh5+", EvalBefore: 250, EvalAfter: 260, Classification: "normal"},
+			{Ply: 4, SAN: "g6", Best: "g6", EvalBefore: 260, EvalAfter: 9997, Loss: 100, Classification: "great"},
+		},
+	}, nil))
+
+	stored, err := database.ExportPGN(ctx, 1, false)
+	require.NoError(t, err)
+	assert.Equal(t, game, stored)
+
+	// movetext compares the exported moves, ignoring how the lines wrap
+	movetext := func(text string) string {
+		return strings.Join(strings.Fields(text), " ")
+	}
+	annotated, err := database.ExportPGN(ctx, 1, true)
+	require.NoError(t, err)
+	assert.Contains(t, movetext(annotated), "1. e4 {[%eval 0.30]} 1... f5 $4 {Blunder. e5 was best.} {[%eval 2.50]} (1... e5) 2. Qh5+ {[%eval 2.60]} 2... g6 $1 {[%eval #3]} 1-0")
+
+	// The annotations of a Lichess export are not repeated
+	annotated, err = database.ExportPGN(ctx, 2, true)
+	require.NoError(t, err)
+	assert.Contains(t, movetext(annotated), "2. Qh5 $6 {(0.32 → -0.20) Inaccuracy. Nf3 was best.} {[%eval -0.2]} (2. Nf3)")
+	assert.Contains(t, movetext(annotated), "(3... g6) 4. Qxf7# 1-0", "a mate is not evaluated")
+}
+
+func TestEvalComment(t *testing.T) {
+	tests := []struct {
+		cp   int
+		want string
+	}{
+		{0, "[%eval 0.00]"},
+		{-135, "[%eval -1.35]"},
+		{9998, "[%eval #2]"},
+		{-9995, "[%eval #-5]"},
+		{10000, ""},
+	}
+	for _, tt := range tests {
+		got, _ := evalComment(tt.cp)
+		assert.Equal(t, tt.want, got, "cp %d", tt.cp)
+	}
+}
@@ -0,0 +1,132 @@
+package db
+
+import (
+	"math"
+	"regexp"
+
+	"github.com/kyleboon/gochess/internal"
+	"github.com/kyleboon/gochess/internal/pgn"
+)
+
+// commentMateScore is the evaluation, in centipawns, stored for a forced
+// mate read from a comment, whose distance is not kept by pgn.ParseEval.
+const commentMateScore = 10000
+
+// bestMoveComment matches the move Lichess recommends in the comment of a
+// move it judged, as in "Mistake. Nf6 was best."
+var bestMoveComment = regexp.MustCompile(`(\S+) was best\.`)
+
+// nagClassifications are the classifications of the moves Lichess and
+// other annotators mark with these NAGs.
+var nagClassifications = map[pgn.Nag]string{
+	1: "great",
+	2: "mistake",
+	3: "brilliant",
+	4: "blunder",
+	6: "inaccuracy",
+}
+
+// commentAnalysis returns the analysis of a game exported by Lichess with
+// evaluations, read from the [%eval] comments of its moves and the NAGs
+// and comments of the moves Lichess judged, with the evaluations of its
+// positions in pawns keyed by ply. It returns nil if the moves have no
+// evaluations. A final checkmate is scored as such; other moves without an
+// evaluation keep the previous one, and the start position is taken as
+// level.
+func commentAnalysis(game *pgn.Game) (*GameAnalysis, map[int]float64) {
+	if game.Root == nil || !hasEvals(game) {
+		return nil, nil
+	}
+	a := &GameAnalysis{Source: AnalysisSourceLichess, Moves: []AnalysisMove{}}
+	evals := make(map[int]float64)
+	prev := 0.0
+	ply := 0
+	for n := game.Root; n.Next != nil; n = n.Next {
+		ply++
+		next := n.Next
+		eval, ok := next.Eval()
+		if !ok && next.Next == nil {
+			if check, mate := next.Board.IsCheckOrMate(); check && mate {
+				// the side to move is mated
+				eval, ok = pgn.MateEval, true
+				if next.Board.SideToMove == internal.White {
+					eval = -pgn.MateEval
+				}
+			}
+		}
+		if ok {
+			evals[ply] = eval
+		} else {
+			eval = prev
+		}
+
+		color := n.Board.SideToMove
+		before, after := commentCentipawns(prev), commentCentipawns(eval)
+		loss := after - before
+		if color == internal.White {
+			loss = before - after
+		}
+		m := AnalysisMove{
+			Ply:            ply,
+			SAN:            next.Move.San(n.Board),
+			EvalBefore:     before,
+			EvalAfter:      after,
+			Loss:           max(0, loss),
+			Classification: "normal",
+		}
+		for _, nag := range next.Nags {
+			if c, ok := nagClassifications[nag]; ok {
+				m.Classification = c
+			}
+		}
+		for _, c := range next.Comment {
+			if match := bestMoveComment.FindStringSubmatch(c); match != nil {
+				m.Best = match[1]
+			}
+		}
+		a.Moves = append(a.Moves, m)
+		a.count(color, m.Classification)
+		prev = eval
+	}
+	return a, evals
+}
+
+// hasEvals reports whether a move of the game's main line has an [%eval]
+// comment.
+func hasEvals(game *pgn.Game) bool {
+	for n := game.Root.Next; n != nil; n = n.Next {
+		if _, ok := n.Eval(); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// count adds a move of color with the given classification to the counts
+// of the analysis.
+func (a *GameAnalysis) count(color int, classification string) {
+	counts := [2][3]*int{
+		{&a.WhiteInaccuracies, &a.WhiteMistakes, &a.WhiteBlunders},
+		{&a.BlackInaccuracies, &a.BlackMistakes, &a.BlackBlunders},
+	}[color]
+	switch classification {
+	case "inaccuracy":
+		*counts[0]++
+	case "mistake":
+		*counts[1]++
+	case "blunder":
+		*counts[2]++
+	}
+}
+
+// commentCentipawns converts an evaluation in pawns, as read from a comment,
+// to centipawns.
+func commentCentipawns(pawns float64) int {
+	switch {
+	case pawns >= pgn.MateEval:
+		return commentMateScore
+	case pawns <= -pgn.MateEval:
+		return -commentMateScore
+	}
+	return int(math.Round(pawns * 100))
+}
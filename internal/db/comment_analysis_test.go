@@ -0,0 +1,64 @@
+package db
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/kyleboon/gochess/internal/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// lichessAnalyzedPGN is a game as Lichess exports it after a computer
+// analysis, with evaluations and the judgements of the bad moves
+const lichessAnalyzedPGN = `[Event "Rated blitz game"]
+[Site "https://lichess.org/abcdefgh"]
+[Date "2024.03.01"]
+[White "Me"]
+[Black "Opponent"]
+[Result "1-0"]
+
+1. e4 { [%eval 0.36] } 1... e5 { [%eval 0.32] } 2. Qh5?! { (0.32 → -0.20) Inaccuracy. Nf3 was best. } { [%eval -0.2] } 2... Nc6 { [%eval -0.1] } 3. Bc4 { [%eval -0.15] } 3... Nf6?? { (-0.15 → Mate in 1) Checkmate is now unavoidable. g6 was best. } { [%eval #1] } 4. Qxf7# 1-0
+`
+
+func TestImportCommentAnalysis(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "gochess-comment-analysis-test-")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(tempDir) }()
+
+	database, err := NewWithLogger(tempDir+"/test.db", logging.Discard())
+	require.NoError(t, err)
+	defer func() { _ = database.Close() }()
+	ctx := context.Background()
+
+	pgnFile := tempDir + "/games.pgn"
+	require.NoError(t, os.WriteFile(pgnFile, []byte(lichessAnalyzedPGN+"\n"+miniaturePGN), 0644))
+	count, errs := database.ImportPGN(ctx, pgnFile)
+	require.Empty(t, errs)
+	require.Equal(t, 2, count)
+
+	a, err := database.GetLatestAnalysis(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, AnalysisSourceLichess, a.Source)
+	assert.Equal(t, 1, a.WhiteInaccuracies)
+	assert.Equal(t, 1, a.BlackBlunders)
+	require.Len(t, a.Moves, 7)
+	assert.Equal(t, AnalysisMove{Ply: 1, SAN: "e4", EvalAfter: 36, Classification: "normal"}, a.Moves[0])
+	assert.Equal(t, AnalysisMove{Ply: 3, SAN: "Qh5", Best: "Nf3", EvalBefore: 32, EvalAfter: -20, Loss: 52, Classification: "inaccuracy"}, a.Moves[2])
+	assert.Equal(t, "blunder", a.Moves[5].Classification)
+	assert.Equal(t, "g6", a.Moves[5].Best)
+	assert.Equal(t, commentMateScore, a.Moves[6].EvalAfter, "the final mate is scored")
+
+	// The evaluations are stored with the positions, except the start
+	positions, err := database.GetPositionsForGame(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, positions, 8)
+	assert.Nil(t, positions[0].Evaluation)
+	require.NotNil(t, positions[3].Evaluation)
+	assert.Equal(t, -0.2, *positions[3].Evaluation)
+
+	// Games without evaluations are not analyzed
+	_, err = database.GetLatestAnalysis(ctx, 2)
+	assert.ErrorIs(t, err, ErrAnalysisNotFound)
+}
@@ -0,0 +1,311 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kyleboon/gochess/internal"
+	"github.com/kyleboon/gochess/internal/pgn"
+)
+
+// tournamentGap is the longest break between two games of the same
+// tournament; an event of the same name and site held again later, such as
+// a yearly championship, is another tournament.
+const tournamentGap = 30 * 24 * time.Hour
+
+// Crosstable formats
+const (
+	RoundRobin = "round-robin"
+	Swiss      = "swiss"
+)
+
+// Tournament is a group of games with the same Event and Site, played over a
+// span of dates.
+type Tournament struct {
+	Event     string `json:"event"`
+	Site      string `json:"site"`
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+	Games     int    `json:"games"`
+	Players   int    `json:"players"`
+
+	games []tournamentGame
+}
+
+// tournamentGame is a game of a tournament, as needed for its crosstable.
+type tournamentGame struct {
+	id                 int
+	date, round        string
+	white, black       string
+	result             string
+	whiteElo, blackElo int
+}
+
+// CrosstableResult is one game of a player in a crosstable.
+type CrosstableResult struct {
+	Round    int     `json:"round"`    // 0 if unknown
+	Opponent int     `json:"opponent"` // rank of the opponent
+	Color    string  `json:"color"`    // "white" or "black"
+	Score    float64 `json:"score"`
+}
+
+// CrosstableEntry is a player's line in a crosstable.
+type CrosstableEntry struct {
+	Rank            int                `json:"rank"`
+	Player          string             `json:"player"`
+	Rating          int                `json:"rating,omitempty"` // rating in the player's first game
+	Games           int                `json:"games"`
+	Wins            int                `json:"wins"`
+	Draws           int                `json:"draws"`
+	Losses          int                `json:"losses"`
+	Score           float64            `json:"score"`
+	SonnebornBerger float64            `json:"sonneborn_berger"`
+	Buchholz        float64            `json:"buchholz"`
+	Results         []CrosstableResult `json:"results"` // in the order played
+}
+
+// Crosstable is the standings of a tournament. In a round robin, where every
+// player met every other equally often, ties are broken by Sonneborn-Berger
+// score, then wins; in a Swiss by Buchholz, then Sonneborn-Berger, then wins.
+type Crosstable struct {
+	Tournament
+	Format  string            `json:"format"` // RoundRobin or Swiss
+	Rounds  int               `json:"rounds"`
+	Players []CrosstableEntry `json:"players"`
+}
+
+// GetTournaments groups the games with an Event into tournaments, by Event,
+// Site and dates, the latest first. If event is not empty, only the
+// tournaments of that event are returned.
+func (db *DB) GetTournaments(ctx context.Context, event string) ([]Tournament, error) {
+	query := `
+		SELECT id, event, COALESCE(site, ''), COALESCE(date, ''), COALESCE(round, ''), white, black, result,
+			COALESCE(white_elo, 0), COALESCE(black_elo, 0)
+		FROM games
+		WHERE deleted_at IS NULL AND event != '' AND event != '?'
+	`
+	var args []interface{}
+	if event != "" {
+		query += " AND event = ?"
+		args = append(args, event)
+	}
+	query += " ORDER BY event, site, date, id"
+
+	rows, err := db.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query games: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var tournaments []Tournament
+	var last time.Time // date of the latest dated game of the current tournament
+	for rows.Next() {
+		var g tournamentGame
+		var event, site string
+		if err := rows.Scan(&g.id, &event, &site, &g.date, &g.round, &g.white, &g.black, &g.result,
+			&g.whiteElo, &g.blackElo); err != nil {
+			return nil, fmt.Errorf("failed to scan game: %w", err)
+		}
+		date, dateErr := time.Parse("2006.01.02", g.date)
+		n := len(tournaments)
+		if n == 0 || tournaments[n-1].Event != event || tournaments[n-1].Site != site ||
+			(dateErr == nil && !last.IsZero() && date.Sub(last) > tournamentGap) {
+			tournaments = append(tournaments, Tournament{Event: event, Site: site})
+			last = time.Time{}
+		}
+		t := &tournaments[len(tournaments)-1]
+		if dateErr == nil {
+			if t.StartDate == "" {
+				t.StartDate = g.date
+			}
+			t.EndDate = g.date
+			last = date
+		}
+		t.games = append(t.games, g)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read games: %w", err)
+	}
+
+	for i := range tournaments {
+		t := &tournaments[i]
+		players := make(map[string]bool)
+		for _, g := range t.games {
+			players[g.white] = true
+			players[g.black] = true
+		}
+		t.Games = len(t.games)
+		t.Players = len(players)
+	}
+	sort.SliceStable(tournaments, func(i, j int) bool {
+		return tournaments[i].EndDate > tournaments[j].EndDate
+	})
+	return tournaments, nil
+}
+
+// GetCrosstable computes the crosstable of the tournament of an event from
+// the stored results of its finished games. An event held at several sites
+// or times is narrowed down by site and by a date played, either of which
+// may be empty.
+func (db *DB) GetCrosstable(ctx context.Context, event, site, date string) (*Crosstable, error) {
+	tournaments, err := db.GetTournaments(ctx, event)
+	if err != nil {
+		return nil, err
+	}
+	var matches []Tournament
+	for _, t := range tournaments {
+		if site != "" && !strings.EqualFold(t.Site, site) {
+			continue
+		}
+		if date != "" && (date < t.StartDate || date > t.EndDate) {
+			continue
+		}
+		matches = append(matches, t)
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("no games found for event %q", event)
+	case 1:
+		return computeCrosstable(matches[0])
+	}
+	held := make([]string, len(matches))
+	for i, t := range matches {
+		held[i] = fmt.Sprintf("%s, %s to %s", t.Site, t.StartDate, t.EndDate)
+	}
+	return nil, fmt.Errorf("event %q was held %d times (%s); narrow it down by site or date",
+		event, len(matches), strings.Join(held, "; "))
+}
+
+// roundNumber returns the round of a Round tag such as "3" or "3.1", or 0 if
+// it is unknown.
+func roundNumber(round string) int {
+	prefix, _, _ := strings.Cut(round, ".")
+	n, err := strconv.Atoi(prefix)
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
+
+// computeCrosstable computes the crosstable of a tournament.
+func computeCrosstable(t Tournament) (*Crosstable, error) {
+	entries := make(map[string]*CrosstableEntry)
+	opponents := make(map[string][]string) // opponents of each player, game by game
+	meetings := make(map[[2]string]int)    // games played by each pair of players
+	rounds := 0
+	games := append([]tournamentGame(nil), t.games...)
+	sort.SliceStable(games, func(i, j int) bool {
+		return roundNumber(games[i].round) < roundNumber(games[j].round)
+	})
+	for _, g := range games {
+		result := pgn.ParseResult(g.result)
+		if !result.Finished() || g.white == g.black {
+			continue
+		}
+		round := roundNumber(g.round)
+		rounds = max(rounds, round)
+		for _, side := range []struct {
+			player, opponent string
+			color            int
+			elo              int
+		}{
+			{g.white, g.black, internal.White, g.whiteElo},
+			{g.black, g.white, internal.Black, g.blackElo},
+		} {
+			e := entries[side.player]
+			if e == nil {
+				e = &CrosstableEntry{Player: side.player, Rating: side.elo}
+				entries[side.player] = e
+			}
+			score := result.Score(side.color)
+			e.Games++
+			e.Score += score
+			switch score {
+			case 1:
+				e.Wins++
+			case 0:
+				e.Losses++
+			default:
+				e.Draws++
+			}
+			color := "white"
+			if side.color == internal.Black {
+				color = "black"
+			}
+			e.Results = append(e.Results, CrosstableResult{Round: round, Color: color, Score: score})
+			opponents[side.player] = append(opponents[side.player], side.opponent)
+		}
+		pair := [2]string{g.white, g.black}
+		if pair[1] < pair[0] {
+			pair[0], pair[1] = pair[1], pair[0]
+		}
+		meetings[pair]++
+	}
+	if len(entries) < 2 {
+		return nil, fmt.Errorf("event %q has no finished games between two players", t.Event)
+	}
+
+	// Tiebreaks weigh results by the final scores of the opponents
+	for name, e := range entries {
+		for i, r := range e.Results {
+			opponentScore := entries[opponents[name][i]].Score
+			e.Buchholz += opponentScore
+			e.SonnebornBerger += r.Score * opponentScore
+		}
+	}
+
+	// A round robin has every pair of players meet equally often
+	format := RoundRobin
+	n := len(entries)
+	cycles := 0
+	for _, count := range meetings {
+		if cycles == 0 {
+			cycles = count
+		}
+		if count != cycles {
+			format = Swiss
+		}
+	}
+	if len(meetings) != n*(n-1)/2 {
+		format = Swiss
+	}
+
+	players := make([]CrosstableEntry, 0, n)
+	for _, e := range entries {
+		players = append(players, *e)
+		rounds = max(rounds, e.Games)
+	}
+	sort.Slice(players, func(i, j int) bool {
+		a, b := players[i], players[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if format == Swiss && a.Buchholz != b.Buchholz {
+			return a.Buchholz > b.Buchholz
+		}
+		if a.SonnebornBerger != b.SonnebornBerger {
+			return a.SonnebornBerger > b.SonnebornBerger
+		}
+		if a.Wins != b.Wins {
+			return a.Wins > b.Wins
+		}
+		return a.Player < b.Player
+	})
+	ranks := make(map[string]int, n)
+	for i := range players {
+		players[i].Rank = i + 1
+		ranks[players[i].Player] = i + 1
+	}
+	for i := range players {
+		for j := range players[i].Results {
+			players[i].Results[j].Opponent = ranks[opponents[players[i].Player][j]]
+		}
+	}
+
+	return &Crosstable{Tournament: t, Format: format, Rounds: rounds, Players: players}, nil
+}
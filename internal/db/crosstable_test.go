@@ -0,0 +1,108 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/kyleboon/gochess/internal/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoundNumber(t *testing.T) {
+	assert.Equal(t, 3, roundNumber("3"))
+	assert.Equal(t, 3, roundNumber("3.1"))
+	assert.Equal(t, 0, roundNumber("?"))
+	assert.Equal(t, 0, roundNumber("-"))
+	assert.Equal(t, 0, roundNumber(""))
+}
+
+func TestGetCrosstable(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "gochess-crosstable-test-")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(tempDir) }()
+
+	database, err := NewWithLogger(tempDir+"/test.db", logging.Discard())
+	require.NoError(t, err)
+	defer func() { _ = database.Close() }()
+	ctx := context.Background()
+
+	game := func(event, site, date, round, white, black, result string) string {
+		return fmt.Sprintf(`[Event "%s"] [Site "%s"] [Date "%s"] [Round "%s"] [White "%s"] [Black "%s"] [Result "%s"] [WhiteElo "1800"] [BlackElo "1700"] 1. e4 e5 2. Nf3 Nc6 3. Bb5 a6 %s`,
+			event, site, date, round, white, black, result, result)
+	}
+	games := []string{
+		// A round robin of four players
+		game("Club Championship", "Club", "2024.01.05", "1", "Alice", "Bob", "1-0"),
+		game("Club Championship", "Club", "2024.01.05", "1", "Carol", "Dave", "1/2-1/2"),
+		game("Club Championship", "Club", "2024.01.12", "2", "Bob", "Carol", "1-0"),
+		game("Club Championship", "Club", "2024.01.12", "2", "Dave", "Alice", "0-1"),
+		game("Club Championship", "Club", "2024.01.19", "3", "Alice", "Carol", "1/2-1/2"),
+		game("Club Championship", "Club", "2024.01.19", "3", "Bob", "Dave", "1-0"),
+		// The next year's edition is another tournament, with a game to come
+		game("Club Championship", "Club", "2025.01.03", "1", "Alice", "Dave", "0-1"),
+		game("Club Championship", "Club", "2025.01.03", "1", "Bob", "Carol", "*"),
+		// Four players, two rounds: a Swiss
+		game("Open", "Town", "2024.06.01", "1", "Alice", "Bob", "1-0"),
+		game("Open", "Town", "2024.06.01", "1", "Carol", "Dave", "1-0"),
+		game("Open", "Town", "2024.06.01", "2", "Carol", "Alice", "0-1"),
+		game("Open", "Town", "2024.06.01", "2", "Dave", "Bob", "1/2-1/2"),
+	}
+	pgnFile := tempDir + "/games.pgn"
+	require.NoError(t, os.WriteFile(pgnFile, []byte(strings.Join(games, "\n\n")), 0644))
+	_, errs := database.ImportPGN(ctx, pgnFile)
+	require.Empty(t, errs)
+
+	tournaments, err := database.GetTournaments(ctx, "")
+	require.NoError(t, err)
+	require.Len(t, tournaments, 3)
+	assert.Equal(t, "2025.01.03", tournaments[0].StartDate)
+	assert.Equal(t, "Open", tournaments[1].Event)
+	assert.Equal(t, "2024.01.05", tournaments[2].StartDate)
+	assert.Equal(t, "2024.01.19", tournaments[2].EndDate)
+	assert.Equal(t, 6, tournaments[2].Games)
+	assert.Equal(t, 4, tournaments[2].Players)
+
+	_, err = database.GetCrosstable(ctx, "Club Championship", "", "")
+	assert.ErrorContains(t, err, "held 2 times")
+	_, err = database.GetCrosstable(ctx, "Nonexistent", "", "")
+	assert.Error(t, err)
+
+	ct, err := database.GetCrosstable(ctx, "Club Championship", "", "2024.01.12")
+	require.NoError(t, err)
+	assert.Equal(t, RoundRobin, ct.Format)
+	assert.Equal(t, 3, ct.Rounds)
+	require.Len(t, ct.Players, 4)
+	alice := ct.Players[0]
+	assert.Equal(t, "Alice", alice.Player)
+	assert.Equal(t, 2.5, alice.Score)
+	assert.Equal(t, 1800, alice.Rating)
+	// Alice beat Bob (2) and Dave (0.5) and drew Carol (1): 2 + 0.5 + 0.5
+	assert.Equal(t, 3.0, alice.SonnebornBerger)
+	assert.Equal(t, []CrosstableResult{
+		{Round: 1, Opponent: 2, Color: "white", Score: 1},
+		{Round: 2, Opponent: 4, Color: "black", Score: 1},
+		{Round: 3, Opponent: 3, Color: "white", Score: 0.5},
+	}, alice.Results)
+	assert.Equal(t, "Bob", ct.Players[1].Player)
+	assert.Equal(t, "Carol", ct.Players[2].Player)
+	assert.Equal(t, "Dave", ct.Players[3].Player)
+
+	_, err = database.GetCrosstable(ctx, "open", "", "")
+	assert.Error(t, err, "events are matched exactly")
+	ct, err = database.GetCrosstable(ctx, "Open", "town", "")
+	require.NoError(t, err)
+	assert.Equal(t, Swiss, ct.Format)
+	assert.Equal(t, 2, ct.Rounds)
+	names := make([]string, len(ct.Players))
+	for i, p := range ct.Players {
+		names[i] = p.Player
+	}
+	// Bob and Dave tie on half a point; Bob's opponents scored 2.5, Dave's 1.5
+	assert.Equal(t, []string{"Alice", "Carol", "Bob", "Dave"}, names)
+	assert.Equal(t, 2.5, ct.Players[2].Buchholz)
+	assert.Equal(t, 1.5, ct.Players[3].Buchholz)
+}
@@ -1,13 +1,18 @@
 package db
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/kyleboon/gochess/internal/config"
+	"github.com/kyleboon/gochess/internal/output"
+	"github.com/kyleboon/gochess/internal/progress"
 	"github.com/urfave/cli/v2"
 )
 
@@ -26,20 +31,99 @@ func expandPath(path string) string {
 	return path
 }
 
+// pgnDownloadTimeout bounds the download of a PGN file to import, body
+// included
+const pgnDownloadTimeout = 10 * time.Minute
+
+// importSummary is the --json output of the import command
+type importSummary struct {
+	DryRun     bool     `json:"dry_run,omitempty"`
+	Imported   int      `json:"imported"` // games that would be imported in a dry run
+	Duplicates int      `json:"duplicates"`
+	Trashed    int      `json:"trashed"` // of the duplicates, games in the trash
+	Errors     []string `json:"errors"`
+	TotalGames int      `json:"total_games"`
+	Seconds    float64  `json:"seconds"`
+
+	Files []fileImportSummary `json:"files,omitempty"` // of a directory import, or a file skipped
+}
+
+// fileImportSummary is the result of importing one file of a directory
+type fileImportSummary struct {
+	File       string `json:"file"`
+	Imported   int    `json:"imported"`
+	Duplicates int    `json:"duplicates"`
+	Errors     int    `json:"errors"`
+	Skipped    bool   `json:"skipped,omitempty"` // unchanged since imported
+}
+
 // ImportCommand imports PGN files to the SQLite database
 func ImportCommand(c *cli.Context) error {
 	pgnPath := c.String("pgn")
-	dbPath := expandPath(c.String("database"))
-
-	// Check if PGN file exists
-	fileInfo, err := os.Stat(pgnPath)
+	dbPath, err := config.DatabasePath(c)
 	if err != nil {
-		return fmt.Errorf("error accessing PGN file: %w", err)
+		return err
+	}
+	dbPath = expandPath(dbPath)
+	out := output.Messages(c)
+
+	// "-" reads the PGN from stdin and an http(s) URL downloads it;
+	// anything else is a file or directory
+	var fileInfo os.FileInfo
+	var stream io.ReadCloser
+	switch {
+	case pgnPath == "-":
+		stream = io.NopCloser(os.Stdin)
+	case isPGNURL(pgnPath):
+		stream, err = downloadPGN(c.Context, pgnPath)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = stream.Close() }()
+	default:
+		// Check if PGN file exists
+		fileInfo, err = os.Stat(pgnPath)
+		if err != nil {
+			return fmt.Errorf("error accessing PGN file: %w", err)
+		}
+	}
+
+	// A dry run checks for duplicates against the database opened
+	// read-only, so it neither migrates nor locks it, but only one that
+	// exists: otherwise every game is new, and an empty database is made in
+	// a temporary directory rather than at dbPath. An in-memory database is
+	// discarded anyway.
+	dryRun := c.Bool("dry-run")
+	mode := ReadWrite
+	if dryRun && !IsInMemory(dbPath) {
+		mode = ReadOnly
+	}
+	if _, err := os.Stat(dbPath); mode == ReadOnly && os.IsNotExist(err) {
+		mode = ReadWrite
+		tempDir, err := os.MkdirTemp("", "gochess-dry-run-")
+		if err != nil {
+			return fmt.Errorf("failed to create temporary directory: %w", err)
+		}
+		defer func() { _ = os.RemoveAll(tempDir) }()
+		fmt.Fprintf(out, "Database %s does not exist yet; checking against an empty one\n", dbPath)
+		dbPath = filepath.Join(tempDir, "dry-run.db")
 	}
+	// --require-tags overrides the import.require_tags of the config
+	var requiredTags []string
+	if c.IsSet("require-tags") {
+		requiredTags = parseTagList(c.String("require-tags"))
+	} else {
+		cfg, err := config.LoadOrDefault()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		requiredTags = cfg.GetRequiredTags()
+	}
+	opts := ImportOptions{DryRun: dryRun, RequiredTags: requiredTags, Workers: c.Int("workers")}
 
 	// Open database connection
-	fmt.Printf("Opening database at %s...\n", dbPath)
-	db, err := New(dbPath)
+	fmt.Fprintf(out, "Opening database at %s...\n", dbPath)
+	db, err := Open(dbPath, mode)
 	if err != nil {
 		return fmt.Errorf("failed to open database: %w", err)
 	}
@@ -53,60 +137,98 @@ func ImportCommand(c *cli.Context) error {
 
 	// Import games
 	startTime := time.Now()
+	var totalImported, totalDuplicates, totalTrashed int
+	var allErrors []error
+	var files []fileImportSummary // per file of a directory
 
-	if fileInfo.IsDir() {
-		// If input is a directory, import all PGN files in it
-		fmt.Printf("Importing all PGN files from directory: %s\n", pgnPath)
-		
-		var totalImported int
-		var allErrors []error
-		
-		err := filepath.Walk(pgnPath, func(path string, info os.FileInfo, err error) error {
-			if err != nil {
-				return err
+	if fileInfo != nil && fileInfo.IsDir() {
+		// If input is a directory, import the PGN files in it one by one as
+		// they are found, going on past files that fail
+		filter := importFilter{
+			include:   c.StringSlice("include"),
+			exclude:   c.StringSlice("exclude"),
+			recursive: c.Bool("recursive"),
+		}
+		if err := filter.validate(); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Importing PGN files from directory: %s\n", pgnPath)
+
+		walkErrors := walkPGNFiles(pgnPath, filter, func(path string) {
+			result, skipped := importFile(c, db, path, opts)
+			if skipped {
+				files = append(files, fileImportSummary{File: path, Skipped: true})
+				return
 			}
-			
-			// Skip directories and non-PGN files
-			if info.IsDir() || filepath.Ext(path) != ".pgn" {
-				return nil
+			totalImported += result.Imported
+			totalDuplicates += result.Duplicates
+			totalTrashed += result.Trashed
+			for _, err := range result.Errors {
+				allErrors = append(allErrors, fileImportError(path, err))
 			}
-			
-			fmt.Printf("Importing file: %s\n", path)
-			imported, errors := db.ImportPGN(c.Context, path)
-			totalImported += imported
-			allErrors = append(allErrors, errors...)
-			
-			fmt.Printf("  Imported %d games\n", imported)
-			return nil
+			files = append(files, fileImportSummary{
+				File:       path,
+				Imported:   result.Imported,
+				Duplicates: result.Duplicates,
+				Errors:     len(result.Errors),
+			})
+
+			if dryRun {
+				fmt.Fprintf(out, "  Would import %d games", result.Imported)
+			} else {
+				fmt.Fprintf(out, "  Imported %d games", result.Imported)
+			}
+			fmt.Fprintf(out, ", %d duplicates, %d errors\n", result.Duplicates, len(result.Errors))
 		})
-		
-		if err != nil {
-			return fmt.Errorf("error walking directory: %w", err)
+		allErrors = append(allErrors, walkErrors...)
+		if len(files) == 0 {
+			fmt.Fprintf(out, "No files matching %s found\n", strings.Join(filter.includes(), ", "))
 		}
-		
+
 		// Report import errors
 		if len(allErrors) > 0 {
-			fmt.Printf("Encountered %d errors during import\n", len(allErrors))
+			fmt.Fprintf(out, "Encountered %d errors during import\n", len(allErrors))
 			if c.Bool("verbose") {
 				for _, errInstance := range allErrors {
 					if pgnErr, ok := errInstance.(*PGNImportError); ok && pgnErr.PGNText != "" {
-						fmt.Printf("  - Error in PGN:\n%s\n  - Message: %v\n", pgnErr.PGNText, pgnErr.OriginalError)
+						fmt.Fprintf(out, "  - Error in PGN:\n%s\n  - Message: %v\n", pgnErr.PGNText, pgnErr.OriginalError)
 					} else {
-						fmt.Printf("  - %v\n", errInstance)
+						fmt.Fprintf(out, "  - %v\n", errInstance)
 					}
 				}
 			}
 		}
-		
-		fmt.Printf("Total games imported: %d\n", totalImported)
+		printFileSummaries(out, files, dryRun)
+
+		if !dryRun {
+			fmt.Fprintf(out, "Total games imported: %d\n", totalImported)
+		}
 	} else {
-		// Import single file
-		fmt.Printf("Importing PGN file: %s\n", pgnPath)
-		imported, errors := db.ImportPGN(c.Context, pgnPath)
+		// Import single file, or stdin or a download
+		var result *ImportResult
+		if stream == nil {
+			var skipped bool
+			if result, skipped = importFile(c, db, pgnPath, opts); skipped {
+				files = append(files, fileImportSummary{File: pgnPath, Skipped: true})
+			}
+		} else {
+			bar := progress.ForOutput(out, 0, "games")
+			opts.Progress = bar.Set
+			if pgnPath == "-" {
+				fmt.Fprintln(out, "Importing PGN from stdin")
+				result = db.ImportPGNReader(c.Context, "stdin", stream, opts)
+			} else {
+				fmt.Fprintf(out, "Importing PGN from %s\n", pgnPath)
+				result = db.ImportPGNReader(c.Context, pgnPath, stream, opts)
+			}
+			bar.Finish()
+		}
+		imported, errors := result.Imported, result.Errors
+		totalImported, totalDuplicates, totalTrashed, allErrors = imported, result.Duplicates, result.Trashed, errors
 		
 		// Report import errors
 		if len(errors) > 0 {
-			fmt.Printf("Encountered %d errors during import\n", len(errors))
+			fmt.Fprintf(out, "Encountered %d errors during import\n", len(errors))
 			
 			// Always show a summary of error types
 			errorCounts := make(map[string]int)
@@ -129,52 +251,54 @@ func ImportCommand(c *cli.Context) error {
 			}
 			
 			// Print error type summary
-			fmt.Println("Error summary:")
+			fmt.Fprintln(out, "Error summary:")
 			for errorType, count := range errorCounts {
-				fmt.Printf("  - %s: %d occurrences\n", errorType, count)
+				fmt.Fprintf(out, "  - %s: %d occurrences\n", errorType, count)
 			}
 			
 			// Print detailed errors if verbose
 			if c.Bool("verbose") {
-				fmt.Println("\nDetailed errors:")
+				fmt.Fprintln(out, "\nDetailed errors:")
 				for i, errInstance := range errors {
 					// Only show first 10 detailed errors if there are many
 					if i >= 10 && len(errors) > 12 {
-						fmt.Printf("  ... and %d more errors (use --verbose for full details)\n", len(errors)-10)
+						fmt.Fprintf(out, "  ... and %d more errors (use --verbose for full details)\n", len(errors)-10)
 						break
 					}
 					if pgnErr, ok := errInstance.(*PGNImportError); ok && pgnErr.PGNText != "" {
-						fmt.Printf("  %d. Error in PGN:\n%s\n  Message: %v\n", i+1, pgnErr.PGNText, pgnErr.OriginalError)
+						fmt.Fprintf(out, "  %d. Error in PGN:\n%s\n  Message: %v\n", i+1, pgnErr.PGNText, pgnErr.OriginalError)
 					} else {
-						fmt.Printf("  %d. %v\n", i+1, errInstance)
+						fmt.Fprintf(out, "  %d. %v\n", i+1, errInstance)
 					}
 				}
 			} else if len(errors) <= 5 {
 				// If there are only a few errors, show them even without verbose
-				fmt.Println("\nErrors:")
+				fmt.Fprintln(out, "\nErrors:")
 				for i, errInstance := range errors {
 					if pgnErr, ok := errInstance.(*PGNImportError); ok && pgnErr.PGNText != "" {
-						fmt.Printf("  %d. Error in PGN:\n%s\n  Message: %v\n", i+1, pgnErr.PGNText, pgnErr.OriginalError)
+						fmt.Fprintf(out, "  %d. Error in PGN:\n%s\n  Message: %v\n", i+1, pgnErr.PGNText, pgnErr.OriginalError)
 					} else {
-						fmt.Printf("  %d. %v\n", i+1, errInstance)
+						fmt.Fprintf(out, "  %d. %v\n", i+1, errInstance)
 					}
 				}
 			} else {
 				// Just show the first 3 errors
-				fmt.Println("\nFirst few errors:")
+				fmt.Fprintln(out, "\nFirst few errors:")
 				for i := 0; i < 3 && i < len(errors); i++ {
 					errInstance := errors[i]
 					if pgnErr, ok := errInstance.(*PGNImportError); ok && pgnErr.PGNText != "" {
-						fmt.Printf("  %d. Error in PGN:\n%s\n  Message: %v\n", i+1, pgnErr.PGNText, pgnErr.OriginalError)
+						fmt.Fprintf(out, "  %d. Error in PGN:\n%s\n  Message: %v\n", i+1, pgnErr.PGNText, pgnErr.OriginalError)
 					} else {
-						fmt.Printf("  %d. %v\n", i+1, errInstance)
+						fmt.Fprintf(out, "  %d. %v\n", i+1, errInstance)
 					}
 				}
-				fmt.Println("  Use --verbose flag to see all errors")
+				fmt.Fprintln(out, "  Use --verbose flag to see all errors")
 			}
 		}
 		
-		fmt.Printf("Games imported: %d\n", imported)
+		if !dryRun {
+			fmt.Fprintf(out, "Games imported: %d\n", imported)
+		}
 	}
 
 	// Get end count
@@ -184,16 +308,169 @@ func ImportCommand(c *cli.Context) error {
 	}
 
 	elapsed := time.Since(startTime)
-	fmt.Printf("Import completed in %.2f seconds\n", elapsed.Seconds())
-	fmt.Printf("Database now contains %d games (added %d new games)\n", 
+	if output.JSON(c) {
+		errs := make([]string, len(allErrors))
+		for i, err := range allErrors {
+			errs[i] = err.Error()
+		}
+		return output.WriteJSON(importSummary{
+			DryRun:     dryRun,
+			Imported:   totalImported,
+			Duplicates: totalDuplicates,
+			Trashed:    totalTrashed,
+			Errors:     errs,
+			TotalGames: endCount,
+			Seconds:    elapsed.Seconds(),
+			Files:      files,
+		})
+	}
+	if dryRun {
+		fmt.Fprintf(out, "Dry run completed in %.2f seconds; the database was not changed\n", elapsed.Seconds())
+		fmt.Fprintf(out, "  Would import:        %d games\n", totalImported)
+		fmt.Fprintf(out, "  Would skip:          %d duplicate games%s\n", totalDuplicates, trashedNote(totalTrashed))
+		fmt.Fprintf(out, "  Errors:              %d\n", len(allErrors))
+		return nil
+	}
+	fmt.Fprintf(out, "Import completed in %.2f seconds\n", elapsed.Seconds())
+	if totalDuplicates > 0 {
+		fmt.Fprintf(out, "Skipped %d duplicate games%s\n", totalDuplicates, trashedNote(totalTrashed))
+	}
+	fmt.Fprintf(out, "Database now contains %d games (added %d new games)\n", 
 		endCount, endCount-startCount)
 
 	return nil
 }
 
+// trashedNote notes how many of the duplicates of an import are in the
+// trash, where they are still duplicates until purged
+func trashedNote(trashed int) string {
+	if trashed == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (%d already in the trash; restore them with gochess db restore)", trashed)
+}
+
+// importFile imports a PGN file, unless it was imported before as it is now
+// and --force is not given. A file is recorded as imported once any of its
+// games was imported or found a duplicate, so one that failed altogether is
+// tried again.
+func importFile(c *cli.Context, db *DB, path string, opts ImportOptions) (result *ImportResult, skipped bool) {
+	out := output.Messages(c)
+	file, err := FileChecksum(path)
+	if err != nil {
+		return &ImportResult{Errors: []error{err}}, false
+	}
+	if !c.Bool("force") {
+		recorded, err := db.UnchangedImport(c.Context, file)
+		if err != nil {
+			return &ImportResult{Errors: []error{err}}, false
+		}
+		if recorded != nil {
+			fmt.Fprintf(out, "Skipping %s: unchanged since imported on %s (use --force to import it again)\n",
+				path, recorded.ImportedAt.Local().Format("2006-01-02 15:04"))
+			return &ImportResult{}, true
+		}
+	}
+
+	fmt.Fprintf(out, "Importing PGN file: %s\n", path)
+	bar := progress.ForOutput(out, 0, "games")
+	opts.Progress = bar.Set
+	result = db.ImportPGNWithOptions(c.Context, path, opts)
+	bar.Finish()
+	if !opts.DryRun && (result.Imported+result.Duplicates > 0 || len(result.Errors) == 0) {
+		if err := db.RecordImportedFile(c.Context, file); err != nil {
+			result.Errors = append(result.Errors, err)
+		}
+	}
+	return result, false
+}
+
+// fileImportError names the file of a directory import an error came from
+func fileImportError(path string, err error) error {
+	if pgnErr, ok := err.(*PGNImportError); ok {
+		return &PGNImportError{OriginalError: fmt.Errorf("%s: %w", path, pgnErr.OriginalError), PGNText: pgnErr.PGNText}
+	}
+	return fmt.Errorf("%s: %w", path, err)
+}
+
+// printFileSummaries prints a table of the results of each file of a
+// directory import
+func printFileSummaries(out io.Writer, files []fileImportSummary, dryRun bool) {
+	if len(files) < 2 {
+		return
+	}
+	width := len("FILE")
+	for _, f := range files {
+		width = max(width, len(f.File))
+	}
+	imported := "IMPORTED"
+	if dryRun {
+		imported = "TO IMPORT"
+	}
+	fmt.Fprintf(out, "\n%-*s  %9s  %10s  %6s\n", width, "FILE", imported, "DUPLICATES", "ERRORS")
+	for _, f := range files {
+		if f.Skipped {
+			fmt.Fprintf(out, "%-*s  %9s\n", width, f.File, "unchanged")
+			continue
+		}
+		fmt.Fprintf(out, "%-*s  %9d  %10d  %6d\n", width, f.File, f.Imported, f.Duplicates, f.Errors)
+	}
+	fmt.Fprintln(out)
+}
+
+// FlagOpenMode returns the mode to open the database in: ReadOnly if the
+// command's --read-only flag is set
+func FlagOpenMode(c *cli.Context) OpenMode {
+	if c.Bool("read-only") {
+		return ReadOnly
+	}
+	return ReadWrite
+}
+
+// parseTagList splits a comma-separated list of tag names, as in
+// "White,Black"
+func parseTagList(list string) []string {
+	var tags []string
+	for _, tag := range strings.Split(list, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// isPGNURL reports whether the --pgn of an import is an http(s) URL to
+// download rather than a path
+func isPGNURL(path string) bool {
+	return strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://")
+}
+
+// downloadPGN starts downloading PGN from url, returning the response body
+func downloadPGN(ctx context.Context, url string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", "gochess")
+	client := &http.Client{Timeout: pgnDownloadTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download PGN: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("failed to download PGN from %s: %s", url, resp.Status)
+	}
+	return resp.Body, nil
+}
+
 // ListCommand lists games in the database
 func ListCommand(c *cli.Context) error {
-	dbPath := expandPath(c.String("database"))
+	dbPath, err := config.DatabasePath(c)
+	if err != nil {
+		return err
+	}
+	dbPath = expandPath(dbPath)
 	limit := c.Int("limit")
 	offset := c.Int("offset")
 	
@@ -211,9 +488,34 @@ func ListCommand(c *cli.Context) error {
 	if date := c.String("date"); date != "" {
 		criteria["date"] = date
 	}
+	if endgame := c.String("endgame"); endgame != "" {
+		if err := ValidateEndgame(endgame); err != nil {
+			return err
+		}
+		criteria["endgame"] = endgame
+	}
+	if motif := c.String("motif"); motif != "" {
+		if err := ValidateMotif(motif); err != nil {
+			return err
+		}
+		criteria["motif"] = motif
+	}
+	if timeClass := strings.ToLower(c.String("time-class")); timeClass != "" {
+		if err := ValidateTimeClass(timeClass); err != nil {
+			return err
+		}
+		criteria["time_class"] = timeClass
+	}
+	for _, filter := range c.StringSlice("tag") {
+		name, value, err := ParseTagFilter(filter)
+		if err != nil {
+			return err
+		}
+		criteria[TagCriterion+name] = value
+	}
 	
 	// Open database connection
-	db, err := New(dbPath)
+	db, err := Open(dbPath, FlagOpenMode(c))
 	if err != nil {
 		return fmt.Errorf("failed to open database: %w", err)
 	}
@@ -231,6 +533,17 @@ func ListCommand(c *cli.Context) error {
 		return fmt.Errorf("failed to search games: %w", err)
 	}
 	
+	if output.JSON(c) {
+		if games == nil {
+			games = []map[string]interface{}{}
+		}
+		return output.WriteJSON(map[string]interface{}{
+			"total":  count,
+			"offset": offset,
+			"games":  games,
+		})
+	}
+
 	// Display results
 	fmt.Printf("Database contains %d total games\n", count)
 	fmt.Printf("Showing games %d to %d of matched results:\n\n", offset+1, offset+len(games))
@@ -254,7 +567,11 @@ func ListCommand(c *cli.Context) error {
 
 // ShowCommand shows details of a specific game
 func ShowCommand(c *cli.Context) error {
-	dbPath := expandPath(c.String("database"))
+	dbPath, err := config.DatabasePath(c)
+	if err != nil {
+		return err
+	}
+	dbPath = expandPath(dbPath)
 	id := c.Int("id")
 	
 	// Open database connection
@@ -270,6 +587,13 @@ func ShowCommand(c *cli.Context) error {
 		return fmt.Errorf("failed to get game: %w", err)
 	}
 	
+	if output.JSON(c) {
+		if !c.Bool("pgn") {
+			delete(game, "pgn_text")
+		}
+		return output.WriteJSON(game)
+	}
+
 	// Display game details
 	fmt.Printf("Game #%d\n", id)
 	fmt.Printf("Event: %s\n", game["event"])
@@ -279,13 +603,42 @@ func ShowCommand(c *cli.Context) error {
 	fmt.Printf("Black: %s (%d)\n", game["black"], game["black_elo"])
 	fmt.Printf("Result: %s\n", game["result"])
 	fmt.Printf("Time Control: %s\n", game["time_control"])
-	
+	if timeClass, ok := game["time_class"]; ok {
+		fmt.Printf("Time Class: %s\n", timeClass)
+	}
+	if endgame, ok := game["endgame"]; ok {
+		fmt.Printf("Endgame: %s\n", endgame)
+	}
+
+	// Current FIDE ratings of the players named by FIDE ID, as fetched by
+	// fide ratings
+	tags := game["tags"].(map[string]string)
+	for _, side := range []struct{ name, tag string }{{"White", WhiteFideIDTag}, {"Black", BlackFideIDTag}} {
+		id := tagID(tags, side.tag)
+		if id == 0 {
+			continue
+		}
+		player, err := db.GetFidePlayer(c.Context, id)
+		if err != nil {
+			return err
+		}
+		if player == nil || player.Name == "" {
+			fmt.Printf("%s FIDE ID: %d\n", side.name, id)
+			continue
+		}
+		fmt.Printf("%s FIDE ID: %d (%s, standard %s, rapid %s, blitz %s)\n", side.name, id, player.Name,
+			fideRating(player.Standard), fideRating(player.Rapid), fideRating(player.Blitz))
+	}
+
 	// Show all tags
 	fmt.Printf("\nAll Tags:\n")
-	tags := game["tags"].(map[string]string)
 	for name, value := range tags {
 		fmt.Printf("  %s: %s\n", name, value)
 	}
+
+	if notes, ok := game["notes"]; ok {
+		fmt.Printf("\nNotes:\n%s\n", notes)
+	}
 	
 	// Show PGN
 	if c.Bool("pgn") {
@@ -297,7 +650,11 @@ func ShowCommand(c *cli.Context) error {
 
 // ExportCommand exports games to PGN format
 func ExportCommand(c *cli.Context) error {
-	dbPath := expandPath(c.String("database"))
+	dbPath, err := config.DatabasePath(c)
+	if err != nil {
+		return err
+	}
+	dbPath = expandPath(dbPath)
 	output := c.String("output")
 	id := c.Int("id")
 	
@@ -322,22 +679,35 @@ func ExportCommand(c *cli.Context) error {
 	}
 	
 	// Export specific game or all games
+	withAnalysis := c.Bool("with-analysis")
 	if id > 0 {
 		// Export single game
-		game, err := db.GetGameByID(c.Context, id)
+		pgnText, err := db.ExportPGN(c.Context, id, withAnalysis)
 		if err != nil {
 			return fmt.Errorf("failed to get game: %w", err)
 		}
 		
-		pgnText := game["pgn_text"].(string)
 		_, _ = fmt.Fprintln(outputWriter, pgnText)
 		
 		if output != "" {
 			fmt.Printf("Exported game #%d to %s\n", id, output)
 		}
 	} else {
-		// TODO: Implement exporting all games or filtered games
-		return fmt.Errorf("exporting all games not yet implemented - please specify a game ID")
+		ids, err := db.GetGameIDsAfter(c.Context, 0)
+		if err != nil {
+			return fmt.Errorf("failed to list games: %w", err)
+		}
+		for _, gameID := range ids {
+			pgnText, err := db.ExportPGN(c.Context, gameID, withAnalysis)
+			if err != nil {
+				return fmt.Errorf("failed to export game #%d: %w", gameID, err)
+			}
+			_, _ = fmt.Fprintln(outputWriter, pgnText)
+		}
+		
+		if output != "" {
+			fmt.Printf("Exported %d games to %s\n", len(ids), output)
+		}
 	}
 	
 	return nil
@@ -345,7 +715,11 @@ func ExportCommand(c *cli.Context) error {
 
 // ClearCommand clears all games from the database
 func ClearCommand(c *cli.Context) error {
-	dbPath := expandPath(c.String("database"))
+	dbPath, err := config.DatabasePath(c)
+	if err != nil {
+		return err
+	}
+	dbPath = expandPath(dbPath)
 	
 	// Ask for confirmation unless --force is specified
 	if !c.Bool("force") {
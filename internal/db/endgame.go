@@ -0,0 +1,234 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/kyleboon/gochess/internal"
+	"github.com/kyleboon/gochess/internal/pgn"
+)
+
+// NoEndgame is the endgame type stored for games that never reached an
+// endgame. It can be searched for like the types of internal.EndgameTypes.
+const NoEndgame = "none"
+
+// EndgameStats summarizes the results of the games that reached one type of
+// endgame. When filtered by players the results are theirs; otherwise they
+// are White's.
+type EndgameStats struct {
+	Endgame string  `json:"endgame"`  // The endgame type, one of internal.EndgameTypes
+	Games   int     `json:"games"`    // Games that reached it
+	Wins    int     `json:"wins"`     // Games won
+	Losses  int     `json:"losses"`   // Games lost
+	Draws   int     `json:"draws"`    // Games drawn
+	WinRate float64 `json:"win_rate"` // Percentage of games won (0-100)
+}
+
+// ValidateEndgame returns an error unless endgame is one of
+// internal.EndgameTypes or NoEndgame.
+func ValidateEndgame(endgame string) error {
+	if endgame == NoEndgame || slices.Contains(internal.EndgameTypes, endgame) {
+		return nil
+	}
+	return fmt.Errorf("unknown endgame type %q; use %s or %s",
+		endgame, strings.Join(internal.EndgameTypes, ", "), NoEndgame)
+}
+
+// gameEndgame returns the type of endgame a game reached, "" if none. A game
+// that passes through several endgames, say from a rook endgame to a pawn
+// endgame, is classified by the one it spent the most plies in, the earliest
+// on a tie.
+func gameEndgame(boards []*internal.Board) string {
+	plies := make(map[string]int)
+	best := ""
+	for _, b := range boards {
+		endgame := b.Endgame()
+		if endgame == "" {
+			continue
+		}
+		plies[endgame]++
+		if best == "" || plies[endgame] > plies[best] {
+			best = endgame
+		}
+	}
+	return best
+}
+
+// mainLineBoards returns the positions of the main line of a parsed game,
+// from the starting position.
+func mainLineBoards(game *pgn.Game) []*internal.Board {
+	if game.Root == nil {
+		return nil
+	}
+	boards := []*internal.Board{game.Root.Board}
+	for n := game.Root.Next; n != nil; n = n.Next {
+		boards = append(boards, n.Board)
+	}
+	return boards
+}
+
+// ClassifyEndgames stores the endgame type of the games imported before
+// endgames were classified, from their indexed positions, and returns how
+// many games it classified.
+func (db *DB) ClassifyEndgames(ctx context.Context) (int, error) {
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT g.id, p.fen
+		FROM games g
+		LEFT JOIN positions p ON p.game_id = g.id
+		WHERE g.endgame IS NULL
+		ORDER BY g.id, p.move_number
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query unclassified games: %w", err)
+	}
+
+	var ids []int
+	boards := make(map[int][]*internal.Board)
+	for rows.Next() {
+		var id int
+		var fen sql.NullString
+		if err := rows.Scan(&id, &fen); err != nil {
+			_ = rows.Close()
+			return 0, fmt.Errorf("failed to scan position: %w", err)
+		}
+		if _, ok := boards[id]; !ok {
+			ids = append(ids, id)
+			boards[id] = nil
+		}
+		if !fen.Valid {
+			continue
+		}
+		b, err := internal.ParseFen(fen.String)
+		if err != nil {
+			db.logger.Debug("skipping invalid position", "game_id", id, "error", err)
+			continue
+		}
+		boards[id] = append(boards[id], b)
+	}
+	err = rows.Err()
+	_ = rows.Close()
+	if err != nil {
+		return 0, fmt.Errorf("error iterating positions: %w", err)
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	stmt, err := tx.PrepareContext(ctx, "UPDATE games SET endgame = ? WHERE id = ?")
+	if err != nil {
+		return 0, fmt.Errorf("failed to prepare endgame update: %w", err)
+	}
+	defer func() { _ = stmt.Close() }()
+	for _, id := range ids {
+		if _, err := stmt.ExecContext(ctx, gameEndgame(boards[id]), id); err != nil {
+			return 0, fmt.Errorf("failed to store endgame of game %d: %w", id, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit endgames: %w", err)
+	}
+	db.logger.Info("classified endgames", "games", len(ids))
+	return len(ids), nil
+}
+
+// GetEndgameStats retrieves the results of all games by endgame type, from
+// White's point of view.
+func (db *DB) GetEndgameStats(ctx context.Context) ([]EndgameStats, error) {
+	return db.GetEndgameStatsFiltered(ctx, nil)
+}
+
+// GetEndgameStatsFiltered retrieves the results of the given players by the
+// type of endgame their games reached, most played first. If players is
+// empty, every game is counted from White's point of view. Games that never
+// reached an endgame are left out.
+func (db *DB) GetEndgameStatsFiltered(ctx context.Context, players []string) ([]EndgameStats, error) {
+	if _, err := db.ClassifyEndgames(ctx); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT endgame, white, black, result
+		FROM games
+		WHERE deleted_at IS NULL AND endgame IS NOT NULL AND endgame != ''
+	`
+	var args []interface{}
+	if len(players) > 0 {
+		placeholders := make([]string, len(players))
+		for i, player := range players {
+			placeholders[i] = "?"
+			args = append(args, player)
+		}
+		playerList := strings.Join(placeholders, ",")
+		query += fmt.Sprintf(" AND (white IN (%s) OR black IN (%s))", playerList, playerList)
+		args = append(args, args...) // Duplicate args for both IN clauses
+	}
+
+	rows, err := db.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query games: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	filterSet := make(map[string]bool)
+	for _, player := range players {
+		filterSet[player] = true
+	}
+	isFiltered := len(players) > 0
+
+	byEndgame := make(map[string]*EndgameStats)
+	for rows.Next() {
+		var endgame, white, black, result string
+		if err := rows.Scan(&endgame, &white, &black, &result); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		stats := byEndgame[endgame]
+		if stats == nil {
+			stats = &EndgameStats{Endgame: endgame}
+			byEndgame[endgame] = stats
+		}
+
+		for color, name := range []string{white, black} {
+			if isFiltered && !filterSet[name] || !isFiltered && color == internal.Black {
+				continue
+			}
+			stats.Games++
+			if r := pgn.ParseResult(result); r.Finished() {
+				switch r.Score(color) {
+				case 1:
+					stats.Wins++
+				case 0:
+					stats.Losses++
+				default:
+					stats.Draws++
+				}
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	results := make([]EndgameStats, 0, len(byEndgame))
+	for _, stats := range byEndgame {
+		if stats.Games > 0 {
+			stats.WinRate = float64(stats.Wins) / float64(stats.Games) * 100.0
+		}
+		results = append(results, *stats)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Games != results[j].Games {
+			return results[i].Games > results[j].Games
+		}
+		return results[i].Endgame < results[j].Endgame
+	})
+	return results, nil
+}
@@ -0,0 +1,149 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/kyleboon/gochess/internal"
+	"github.com/kyleboon/gochess/internal/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const miniaturePGN = `[Event "Miniature"]
+[Site "Test"]
+[Date "2024.01.04"]
+[White "Me"]
+[Black "Opponent"]
+[Result "1-0"]
+
+1. e4 e5 2. Qh5 Nc6 3. Bc4 Nf6 4. Qxf7# 1-0
+`
+
+// Endgame positions, as indexed for games that reached them
+const (
+	rookEndgameFEN    = "8/5k2/6p1/8/8/1R6/2K2r2/8 w - - 0 40"
+	pawnEndgameFEN    = "8/5k2/6p1/8/8/8/2K5/8 w - - 0 45"
+	bishopsEndgameFEN = "8/5k2/3b4/8/8/8/2K1B3/8 w - - 0 40"
+	middlegameFEN     = "r2q1rk1/5ppp/8/8/8/8/5PPP/R2Q1RK1 w - - 0 30"
+)
+
+// openEndgameTestDB returns a database with an imported miniature, which
+// reaches no endgame, and games that do, added to the position index
+// without their moves as if imported before endgames were classified.
+func openEndgameTestDB(t *testing.T) *DB {
+	t.Helper()
+	tempDir, err := os.MkdirTemp("", "gochess-test-endgame-")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tempDir) })
+
+	db, err := NewWithLogger(tempDir+"/test.db", logging.Discard())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	pgnFile := tempDir + "/test.pgn"
+	require.NoError(t, os.WriteFile(pgnFile, []byte(miniaturePGN), 0644))
+	count, errs := db.ImportPGN(context.Background(), pgnFile)
+	require.Empty(t, errs)
+	require.Equal(t, 1, count)
+
+	games := []struct {
+		event, white, black, result string
+		fens                        []string
+	}{
+		{"Rook endgame", "Me", "Opponent", "1-0", []string{middlegameFEN, rookEndgameFEN, rookEndgameFEN, pawnEndgameFEN}},
+		{"Opposite bishops", "Opponent", "Me", "1/2-1/2", []string{middlegameFEN, bishopsEndgameFEN}},
+		{"Another rook endgame", "Opponent", "Me", "1-0", []string{rookEndgameFEN}},
+	}
+	for i, g := range games {
+		res, err := db.conn.Exec(`
+			INSERT INTO games (
+				event, site, date, round, white, black, result,
+				white_elo, black_elo, time_control, pgn_text, game_hash
+			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, g.event, "Test", fmt.Sprintf("2024.01.0%d", i+1), "1", g.white, g.black, g.result, 0, 0, "", "", g.event)
+		require.NoError(t, err)
+		id, err := res.LastInsertId()
+		require.NoError(t, err)
+		for ply, fen := range g.fens {
+			_, err := db.conn.Exec(`
+				INSERT INTO positions (game_id, move_number, fen) VALUES (?, ?, ?)
+			`, id, ply, fen)
+			require.NoError(t, err)
+		}
+	}
+	return db
+}
+
+func TestGameEndgame(t *testing.T) {
+	var boards []*internal.Board
+	for _, fen := range []string{middlegameFEN, rookEndgameFEN, pawnEndgameFEN, pawnEndgameFEN} {
+		b, err := internal.ParseFen(fen)
+		require.NoError(t, err)
+		boards = append(boards, b)
+	}
+
+	assert.Equal(t, "", gameEndgame(boards[:1]))
+	assert.Equal(t, internal.RookEndgame, gameEndgame(boards[:3]), "the earlier endgame wins a tie")
+	assert.Equal(t, internal.PawnEndgame, gameEndgame(boards), "the endgame played longest")
+}
+
+func TestSearchGamesByEndgame(t *testing.T) {
+	db := openEndgameTestDB(t)
+	ctx := context.Background()
+
+	games, err := db.SearchGames(ctx, map[string]string{"endgame": internal.RookEndgame}, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, games, 2)
+	assert.Equal(t, "Another rook endgame", games[0]["event"])
+
+	games, err = db.SearchGames(ctx, map[string]string{"endgame": NoEndgame}, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, games, 1)
+	assert.Equal(t, "Miniature", games[0]["event"])
+
+	game, err := db.GetGameByID(ctx, 3)
+	require.NoError(t, err)
+	assert.Equal(t, internal.OppositeBishopsEndgame, game["endgame"])
+	game, err = db.GetGameByID(ctx, 1)
+	require.NoError(t, err)
+	assert.NotContains(t, game, "endgame")
+}
+
+func TestClassifyEndgames(t *testing.T) {
+	db := openEndgameTestDB(t)
+	ctx := context.Background()
+
+	// The miniature was classified when it was imported
+	n, err := db.ClassifyEndgames(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+
+	// The rook endgame spent more plies as a rook endgame than a pawn one
+	game, err := db.GetGameByID(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, internal.RookEndgame, game["endgame"])
+
+	n, err = db.ClassifyEndgames(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n, "games are classified once")
+}
+
+func TestGetEndgameStatsFiltered(t *testing.T) {
+	db := openEndgameTestDB(t)
+	ctx := context.Background()
+
+	stats, err := db.GetEndgameStatsFiltered(ctx, []string{"Me"})
+	require.NoError(t, err)
+	require.Len(t, stats, 2)
+	assert.Equal(t, EndgameStats{Endgame: internal.RookEndgame, Games: 2, Wins: 1, Losses: 1, WinRate: 50}, stats[0])
+	assert.Equal(t, EndgameStats{Endgame: internal.OppositeBishopsEndgame, Games: 1, Draws: 1}, stats[1])
+
+	// All games, from White's point of view
+	stats, err = db.GetEndgameStats(ctx)
+	require.NoError(t, err)
+	require.Len(t, stats, 2)
+	assert.Equal(t, EndgameStats{Endgame: internal.RookEndgame, Games: 2, Wins: 2, WinRate: 100}, stats[0])
+}
@@ -1,5 +1,14 @@
 package db
 
+import "errors"
+
+// ErrGameNotFound is returned when no game has the requested ID.
+var ErrGameNotFound = errors.New("game not found")
+
+// ErrGameNotInTrash is returned when restoring or purging a game that is not
+// in the trash.
+var ErrGameNotInTrash = errors.New("game not in trash")
+
 // PGNImportError wraps an error that occurred during PGN parsing
 // and includes the PGN text that caused the error.
 type PGNImportError struct {
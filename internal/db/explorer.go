@@ -0,0 +1,62 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// PositionMove summarizes the games in which a move was played from a
+// position.
+type PositionMove struct {
+	Move      string // the move in UCI notation, as stored with the position
+	Games     int
+	WhiteWins int
+	BlackWins int
+	Draws     int
+}
+
+// GetPositionMoves returns the moves played from the position of fen, most
+// played first. Positions are matched on their placement, side to move,
+// castling rights and en passant square, so transpositions are included
+// whatever the move counters.
+func (db *DB) GetPositionMoves(ctx context.Context, fen string) ([]PositionMove, error) {
+	fields := strings.Fields(fen)
+	if len(fields) < 4 {
+		return nil, fmt.Errorf("invalid FEN: %q", fen)
+	}
+	key := strings.Join(fields[:4], " ")
+
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT
+			p.next_move,
+			COUNT(*) as games,
+			SUM(CASE WHEN g.result = '1-0' THEN 1 ELSE 0 END) as white_wins,
+			SUM(CASE WHEN g.result = '0-1' THEN 1 ELSE 0 END) as black_wins,
+			SUM(CASE WHEN g.result = '1/2-1/2' THEN 1 ELSE 0 END) as draws
+		FROM positions p
+		JOIN games g ON p.game_id = g.id
+		WHERE (p.fen = ? OR p.fen LIKE ?) AND p.next_move IS NOT NULL AND p.next_move != ''
+			AND g.deleted_at IS NULL
+		GROUP BY p.next_move
+		ORDER BY games DESC, p.next_move
+	`, key, key+" %")
+	if err != nil {
+		db.logger.Error("failed to query position moves", "error", err)
+		return nil, fmt.Errorf("failed to query position moves: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var moves []PositionMove
+	for rows.Next() {
+		var m PositionMove
+		if err := rows.Scan(&m.Move, &m.Games, &m.WhiteWins, &m.BlackWins, &m.Draws); err != nil {
+			return nil, fmt.Errorf("failed to scan position move: %w", err)
+		}
+		moves = append(moves, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating position moves: %w", err)
+	}
+	return moves, nil
+}
@@ -0,0 +1,81 @@
+package db
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/kyleboon/gochess/internal/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetPositionMoves(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "gochess-explorer-test-")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(tempDir) }()
+
+	database, err := NewWithLogger(tempDir+"/test.db", logging.Discard())
+	require.NoError(t, err)
+	defer func() { _ = database.Close() }()
+
+	pgnContent := `[Event "One"]
+[Site "Test"]
+[Date "2024.01.15"]
+[White "Alice"]
+[Black "Bob"]
+[Result "1-0"]
+
+1. e4 e5 2. Nf3 1-0
+
+[Event "Two"]
+[Site "Test"]
+[Date "2024.01.15"]
+[White "Bob"]
+[Black "Alice"]
+[Result "0-1"]
+
+1. e4 c5 2. Nf3 0-1
+
+[Event "Three"]
+[Site "Test"]
+[Date "2024.01.15"]
+[White "Alice"]
+[Black "Carol"]
+[Result "1/2-1/2"]
+
+1. d4 d5 1/2-1/2
+`
+	pgnFile := tempDir + "/test.pgn"
+	require.NoError(t, os.WriteFile(pgnFile, []byte(pgnContent), 0644))
+	count, errs := database.ImportPGN(context.Background(), pgnFile)
+	require.Empty(t, errs)
+	require.Equal(t, 3, count)
+
+	ctx := context.Background()
+
+	t.Run("starting position", func(t *testing.T) {
+		moves, err := database.GetPositionMoves(ctx, "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1")
+		require.NoError(t, err)
+		require.Len(t, moves, 2)
+		assert.Equal(t, PositionMove{Move: "e2e4", Games: 2, WhiteWins: 1, BlackWins: 1}, moves[0])
+		assert.Equal(t, PositionMove{Move: "d2d4", Games: 1, Draws: 1}, moves[1])
+	})
+
+	t.Run("move counters are ignored", func(t *testing.T) {
+		moves, err := database.GetPositionMoves(ctx, "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 7 30")
+		require.NoError(t, err)
+		assert.Len(t, moves, 2)
+	})
+
+	t.Run("unknown position", func(t *testing.T) {
+		moves, err := database.GetPositionMoves(ctx, "8/8/4k3/8/8/4K3/8/8 w - - 0 1")
+		require.NoError(t, err)
+		assert.Empty(t, moves)
+	})
+
+	t.Run("invalid FEN", func(t *testing.T) {
+		_, err := database.GetPositionMoves(ctx, "not a fen")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,195 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Tags naming the players of a game by their FIDE or ICCF ID, as written by
+// over-the-board tournament software and correspondence servers.
+const (
+	WhiteFideIDTag = "WhiteFideId"
+	BlackFideIDTag = "BlackFideId"
+	WhiteICCFIDTag = "WhiteIccfId"
+	BlackICCFIDTag = "BlackIccfId"
+)
+
+// FidePlayer is a player's entry in the FIDE rating list, fetched to enrich
+// over-the-board games that name their players by FIDE ID.
+type FidePlayer struct {
+	ID         int    `json:"fide_id"`
+	Name       string `json:"name"`
+	Federation string `json:"federation,omitempty"` // As in "Norway"
+	Title      string `json:"title,omitempty"`
+	Standard   int    `json:"standard,omitempty"` // Current standard rating, 0 if unrated
+	Rapid      int    `json:"rapid,omitempty"`
+	Blitz      int    `json:"blitz,omitempty"`
+	UpdatedAt  string `json:"updated_at"`
+}
+
+// PlayerIDs are the IDs of the two players of a game, 0 if not given.
+type PlayerIDs struct {
+	White int
+	Black int
+}
+
+// FideIDs returns the players' FIDE IDs from the WhiteFideId and
+// BlackFideId tags.
+func (g *Game) FideIDs() PlayerIDs {
+	return PlayerIDs{White: tagID(g.Tags, WhiteFideIDTag), Black: tagID(g.Tags, BlackFideIDTag)}
+}
+
+// ICCFIDs returns the players' ICCF IDs from the WhiteIccfId and BlackIccfId
+// tags.
+func (g *Game) ICCFIDs() PlayerIDs {
+	return PlayerIDs{White: tagID(g.Tags, WhiteICCFIDTag), Black: tagID(g.Tags, BlackICCFIDTag)}
+}
+
+// tagID reads a numeric ID tag, whose name is matched regardless of case as
+// exports differ ("WhiteFideId", "WhiteFIDEId"). Empty, "0" or malformed
+// values read as 0.
+func tagID(tags map[string]string, name string) int {
+	for tag, value := range tags {
+		if !strings.EqualFold(tag, name) {
+			continue
+		}
+		id, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || id < 0 {
+			return 0
+		}
+		return id
+	}
+	return 0
+}
+
+// createFidePlayersTable creates the table of the FIDE rating list entries
+// of the players named by FIDE ID in the games
+func (db *DB) createFidePlayersTable() error {
+	_, err := db.conn.Exec(`
+		CREATE TABLE IF NOT EXISTS fide_players (
+			fide_id INTEGER PRIMARY KEY,
+			name TEXT NOT NULL DEFAULT '',
+			federation TEXT NOT NULL DEFAULT '',
+			title TEXT NOT NULL DEFAULT '',
+			standard INTEGER NOT NULL DEFAULT 0,
+			rapid INTEGER NOT NULL DEFAULT 0,
+			blitz INTEGER NOT NULL DEFAULT 0,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create fide_players table: %w", err)
+	}
+	return nil
+}
+
+// SaveFidePlayer stores a player's FIDE ratings, replacing those stored.
+func (db *DB) SaveFidePlayer(ctx context.Context, player FidePlayer) error {
+	_, err := db.conn.ExecContext(ctx, `
+		INSERT INTO fide_players (fide_id, name, federation, title, standard, rapid, blitz) VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (fide_id) DO UPDATE SET
+			name = excluded.name,
+			federation = excluded.federation,
+			title = excluded.title,
+			standard = excluded.standard,
+			rapid = excluded.rapid,
+			blitz = excluded.blitz,
+			updated_at = CURRENT_TIMESTAMP
+	`, player.ID, player.Name, player.Federation, player.Title, player.Standard, player.Rapid, player.Blitz)
+	if err != nil {
+		return fmt.Errorf("failed to save FIDE player: %w", err)
+	}
+	return nil
+}
+
+// GetFidePlayer retrieves a player's stored FIDE ratings, or nil if they
+// were never fetched.
+func (db *DB) GetFidePlayer(ctx context.Context, id int) (*FidePlayer, error) {
+	row := db.conn.QueryRowContext(ctx, `
+		SELECT fide_id, name, federation, title, standard, rapid, blitz, COALESCE(updated_at, '')
+		FROM fide_players WHERE fide_id = ?
+	`, id)
+	var p FidePlayer
+	err := row.Scan(&p.ID, &p.Name, &p.Federation, &p.Title, &p.Standard, &p.Rapid, &p.Blitz, &p.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get FIDE player: %w", err)
+	}
+	return &p, nil
+}
+
+// FidePlayers retrieves the stored FIDE ratings of every player, highest
+// standard rating first.
+func (db *DB) FidePlayers(ctx context.Context) ([]FidePlayer, error) {
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT fide_id, name, federation, title, standard, rapid, blitz, COALESCE(updated_at, '')
+		FROM fide_players ORDER BY standard DESC, name, fide_id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query FIDE players: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var players []FidePlayer
+	for rows.Next() {
+		var p FidePlayer
+		if err := rows.Scan(&p.ID, &p.Name, &p.Federation, &p.Title, &p.Standard, &p.Rapid, &p.Blitz, &p.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		players = append(players, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+	return players, nil
+}
+
+// GameFideIDs returns the FIDE IDs named in the WhiteFideId and BlackFideId
+// tags of the games, in increasing order. With missingOnly, only the IDs
+// whose ratings were never fetched are returned.
+func (db *DB) GameFideIDs(ctx context.Context, missingOnly bool) ([]int, error) {
+	query := `
+		SELECT DISTINCT CAST(t.tag_value AS INTEGER) AS fide_id
+		FROM tags t JOIN games g ON g.id = t.game_id
+		WHERE g.deleted_at IS NULL
+			AND t.tag_name COLLATE NOCASE IN (?, ?)
+			AND CAST(t.tag_value AS INTEGER) > 0
+	`
+	if missingOnly {
+		query += " AND CAST(t.tag_value AS INTEGER) NOT IN (SELECT fide_id FROM fide_players)"
+	}
+	query += " ORDER BY fide_id"
+
+	rows, err := db.conn.QueryContext(ctx, query, WhiteFideIDTag, BlackFideIDTag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query FIDE IDs: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+	return ids, nil
+}
+
+// fideRating shows a rating, "unrated" if 0
+func fideRating(rating int) string {
+	if rating == 0 {
+		return "unrated"
+	}
+	return strconv.Itoa(rating)
+}
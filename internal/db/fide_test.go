@@ -0,0 +1,103 @@
+package db
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const fidePGN = `[Event "Norway Chess"]
+[Site "Stavanger NOR"]
+[Date "2024.05.27"]
+[White "Carlsen, Magnus"]
+[Black "Nakamura, Hikaru"]
+[Result "1-0"]
+[WhiteFideId "1503014"]
+[BlackFideId "2016192"]
+
+1. e4 e5 1-0
+
+[Event "Club Championship"]
+[Site "Oslo NOR"]
+[Date "2024.06.01"]
+[White "Nakamura, Hikaru"]
+[Black "Club Player"]
+[Result "1/2-1/2"]
+[WhiteFIDEId "2016192"]
+[BlackFideId "0"]
+
+1. d4 d5 1/2-1/2
+
+[Event "WCCC Final"]
+[Site "ICCF"]
+[Date "2023.01.01"]
+[White "Postal, Paula"]
+[Black "Mail, Martin"]
+[Result "*"]
+[WhiteIccfId "400123"]
+[BlackIccfId "n/a"]
+
+1. c4 *
+`
+
+func TestFideIDs(t *testing.T) {
+	database, err := NewInMemory()
+	require.NoError(t, err)
+	defer func() { _ = database.Close() }()
+	ctx := context.Background()
+
+	result := database.ImportPGNReader(ctx, "otb.pgn", strings.NewReader(fidePGN), ImportOptions{})
+	require.Empty(t, result.Errors)
+	require.Equal(t, 3, result.Imported)
+
+	games, err := database.SearchByTag(ctx, "Event", "Club Championship")
+	require.NoError(t, err)
+	require.Len(t, games, 1)
+	game, err := database.GetGame(ctx, games[0]["id"].(int))
+	require.NoError(t, err)
+	assert.Equal(t, PlayerIDs{White: 2016192}, game.FideIDs(), "tag names are matched regardless of case")
+
+	games, err = database.SearchByTag(ctx, "Event", "WCCC Final")
+	require.NoError(t, err)
+	require.Len(t, games, 1)
+	game, err = database.GetGame(ctx, games[0]["id"].(int))
+	require.NoError(t, err)
+	assert.Equal(t, PlayerIDs{White: 400123}, game.ICCFIDs())
+	assert.Equal(t, PlayerIDs{}, game.FideIDs())
+
+	ids, err := database.GameFideIDs(ctx, false)
+	require.NoError(t, err)
+	assert.Equal(t, []int{1503014, 2016192}, ids)
+
+	require.NoError(t, database.SaveFidePlayer(ctx, FidePlayer{ID: 1503014, Name: "Carlsen, Magnus", Federation: "NOR", Title: "GM", Standard: 2830, Rapid: 2824, Blitz: 2886}))
+	ids, err = database.GameFideIDs(ctx, true)
+	require.NoError(t, err)
+	assert.Equal(t, []int{2016192}, ids)
+
+	player, err := database.GetFidePlayer(ctx, 1503014)
+	require.NoError(t, err)
+	require.NotNil(t, player)
+	assert.Equal(t, 2830, player.Standard)
+	assert.NotEmpty(t, player.UpdatedAt)
+
+	// Saving again replaces the ratings
+	require.NoError(t, database.SaveFidePlayer(ctx, FidePlayer{ID: 1503014, Name: "Carlsen, Magnus", Federation: "NOR", Title: "GM", Standard: 2831}))
+	player, err = database.GetFidePlayer(ctx, 1503014)
+	require.NoError(t, err)
+	assert.Equal(t, 2831, player.Standard)
+	assert.Zero(t, player.Rapid)
+
+	player, err = database.GetFidePlayer(ctx, 2016192)
+	require.NoError(t, err)
+	assert.Nil(t, player)
+
+	require.NoError(t, database.SaveFidePlayer(ctx, FidePlayer{ID: 2016192, Name: "Nakamura, Hikaru", Standard: 2802}))
+	players, err := database.FidePlayers(ctx)
+	require.NoError(t, err)
+	require.Len(t, players, 2)
+	assert.Equal(t, 1503014, players[0].ID)
+	assert.Equal(t, 2016192, players[1].ID)
+}
@@ -0,0 +1,178 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kyleboon/gochess/internal"
+	"github.com/kyleboon/gochess/internal/pgn"
+)
+
+// FirstMoveStats represents a player's results with one first move as White,
+// or one response to White's first move as Black.
+type FirstMoveStats struct {
+	Color string `json:"color"` // "white" or "black"
+	Move  string `json:"move"`  // "e4" as White, "e4 c5" as Black
+	ResultCounts
+	Score float64 `json:"score"` // percentage of the points scored (0-100)
+}
+
+// FirstMoveReport is a snapshot of a player's first moves as White and
+// responses as Black, overall, by time class and by year, to see how their
+// repertoire drifts.
+type FirstMoveReport struct {
+	Overall     []FirstMoveStats            `json:"overall"`
+	ByTimeClass map[string][]FirstMoveStats `json:"by_time_class"`
+	ByYear      map[string][]FirstMoveStats `json:"by_year"`
+}
+
+// firstMoveCounter counts the results of first moves by color and move.
+type firstMoveCounter map[[2]string]*FirstMoveStats
+
+// add counts a game in which the player, playing color, chose move and
+// scored score.
+func (c firstMoveCounter) add(color, move string, score float64) {
+	key := [2]string{color, move}
+	stats := c[key]
+	if stats == nil {
+		stats = &FirstMoveStats{Color: color, Move: move}
+		c[key] = stats
+	}
+	stats.add(score)
+	stats.Score = (float64(stats.Wins) + float64(stats.Draws)/2) / float64(stats.Games) * 100
+}
+
+// list returns the moves counted, White's first, most played first.
+func (c firstMoveCounter) list() []FirstMoveStats {
+	moves := make([]FirstMoveStats, 0, len(c))
+	for _, stats := range c {
+		moves = append(moves, *stats)
+	}
+	sort.Slice(moves, func(i, j int) bool {
+		if moves[i].Color != moves[j].Color {
+			return moves[i].Color == "white"
+		}
+		if moves[i].Games != moves[j].Games {
+			return moves[i].Games > moves[j].Games
+		}
+		return moves[i].Move < moves[j].Move
+	})
+	return moves
+}
+
+// GetFirstMoveStats retrieves the first moves of players as White and their
+// responses to White's first move as Black, with their results, overall, by
+// time class and by year. Only finished games are counted; games of an
+// unknown time class or year are left out of those groups.
+func (db *DB) GetFirstMoveStats(ctx context.Context, players []string) (*FirstMoveReport, error) {
+	if len(players) == 0 {
+		return nil, fmt.Errorf("no players given")
+	}
+	placeholders := make([]string, len(players))
+	var args []interface{}
+	for i, player := range players {
+		placeholders[i] = "?"
+		args = append(args, player)
+	}
+	playerList := strings.Join(placeholders, ",")
+	args = append(args, args...) // Duplicate args for both IN clauses
+
+	rows, err := db.conn.QueryContext(ctx, fmt.Sprintf(`
+		SELECT g.id, g.white, g.black, g.result, COALESCE(g.time_class, ''), COALESCE(g.date, ''),
+			p.fen, p.next_move
+		FROM games g
+		JOIN positions p ON p.game_id = g.id
+		WHERE (g.white IN (%s) OR g.black IN (%s))
+		AND g.deleted_at IS NULL
+		AND g.result IN ('1-0', '0-1', '1/2-1/2')
+		AND p.move_number < 2 AND p.next_move != ''
+		ORDER BY g.id, p.move_number
+	`, playerList, playerList), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query positions: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	filterSet := make(map[string]bool)
+	for _, player := range players {
+		filterSet[player] = true
+	}
+
+	overall := firstMoveCounter{}
+	byTimeClass := make(map[string]firstMoveCounter)
+	byYear := make(map[string]firstMoveCounter)
+	count := func(counters map[string]firstMoveCounter, group, color, move string, score float64) {
+		if group == "" {
+			return
+		}
+		if counters[group] == nil {
+			counters[group] = firstMoveCounter{}
+		}
+		counters[group].add(color, move, score)
+	}
+
+	// The first two moves of a game come in order; the game is counted when
+	// the next one starts
+	type firstMoves struct {
+		id                   int
+		white, black, result string
+		timeClass, year      string
+		sans                 []string
+	}
+	var game firstMoves
+	flush := func() {
+		color, side, plies := "white", internal.White, 1
+		if !filterSet[game.white] {
+			color, side, plies = "black", internal.Black, 2
+		}
+		if game.id == 0 || len(game.sans) < plies {
+			return
+		}
+		move := strings.Join(game.sans[:plies], " ")
+		score := pgn.ParseResult(game.result).Score(side)
+		overall.add(color, move, score)
+		count(byTimeClass, game.timeClass, color, move, score)
+		count(byYear, game.year, color, move, score)
+	}
+	for rows.Next() {
+		var id int
+		var white, black, result, timeClass, date, fen, move string
+		if err := rows.Scan(&id, &white, &black, &result, &timeClass, &date, &fen, &move); err != nil {
+			return nil, fmt.Errorf("failed to scan position: %w", err)
+		}
+		if id != game.id {
+			flush()
+			year, _, _ := strings.Cut(date, ".")
+			if strings.Contains(year, "?") {
+				year = ""
+			}
+			game = firstMoves{id: id, white: white, black: black, result: result, timeClass: timeClass, year: year}
+		}
+		san := move
+		if board, err := internal.ParseFen(fen); err == nil {
+			if mv, err := board.ParseMove(move); err == nil {
+				san = mv.San(board)
+			}
+		}
+		game.sans = append(game.sans, san)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read positions: %w", err)
+	}
+	flush()
+
+	report := &FirstMoveReport{
+		Overall:     overall.list(),
+		ByTimeClass: make(map[string][]FirstMoveStats),
+		ByYear:      make(map[string][]FirstMoveStats),
+	}
+	for class, counter := range byTimeClass {
+		report.ByTimeClass[class] = counter.list()
+	}
+	for year, counter := range byYear {
+		report.ByYear[year] = counter.list()
+	}
+	return report, nil
+}
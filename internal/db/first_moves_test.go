@@ -0,0 +1,57 @@
+package db
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/kyleboon/gochess/internal/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetFirstMoveStats(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "gochess-first-moves-test-")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(tempDir) }()
+
+	database, err := NewWithLogger(tempDir+"/test.db", logging.Discard())
+	require.NoError(t, err)
+	defer func() { _ = database.Close() }()
+	ctx := context.Background()
+
+	games := []string{
+		`[Event "1"] [Site "?"] [Date "2023.05.01"] [White "Alice"] [Black "Bob"] [Result "1-0"] [TimeControl "180"] 1. e4 e5 1-0`,
+		`[Event "2"] [Site "?"] [Date "2024.05.01"] [White "Alice"] [Black "Bob"] [Result "1/2-1/2"] [TimeControl "600"] 1. d4 d5 1/2-1/2`,
+		`[Event "3"] [Site "?"] [Date "2024.06.01"] [White "Alice"] [Black "Bob"] [Result "0-1"] [TimeControl "600"] 1. d4 Nf6 0-1`,
+		`[Event "4"] [Site "?"] [Date "2024.06.02"] [White "Bob"] [Black "Alice"] [Result "0-1"] [TimeControl "180"] 1. e4 c5 0-1`,
+		`[Event "5"] [Site "?"] [Date "2024.06.03"] [White "Bob"] [Black "Alice"] [Result "*"] [TimeControl "180"] 1. e4 e5 *`,
+	}
+	pgnFile := tempDir + "/games.pgn"
+	require.NoError(t, os.WriteFile(pgnFile, []byte(strings.Join(games, "\n\n")), 0644))
+	_, errs := database.ImportPGN(ctx, pgnFile)
+	require.Empty(t, errs)
+
+	report, err := database.GetFirstMoveStats(ctx, []string{"Alice"})
+	require.NoError(t, err)
+	require.Len(t, report.Overall, 3)
+	assert.Equal(t, FirstMoveStats{
+		Color: "white", Move: "d4",
+		ResultCounts: ResultCounts{Games: 2, Losses: 1, Draws: 1},
+		Score:        25,
+	}, report.Overall[0])
+	assert.Equal(t, "e4", report.Overall[1].Move)
+	assert.Equal(t, "black", report.Overall[2].Color)
+	assert.Equal(t, "e4 c5", report.Overall[2].Move)
+	assert.Equal(t, 100.0, report.Overall[2].Score)
+
+	require.Len(t, report.ByTimeClass["blitz"], 2)
+	assert.Equal(t, "e4", report.ByTimeClass["blitz"][0].Move)
+	require.Len(t, report.ByYear["2023"], 1)
+	assert.Equal(t, "e4", report.ByYear["2023"][0].Move)
+	require.Len(t, report.ByYear["2024"], 2)
+
+	_, err = database.GetFirstMoveStats(ctx, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,148 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// Game is a game stored in the database, with its tags and note.
+type Game struct {
+	ID          int               `json:"id"`
+	Event       string            `json:"event"`
+	Site        string            `json:"site"`
+	Date        string            `json:"date"`
+	Round       string            `json:"round"`
+	White       string            `json:"white"`
+	Black       string            `json:"black"`
+	Result      string            `json:"result"`
+	WhiteElo    int               `json:"white_elo"`
+	BlackElo    int               `json:"black_elo"`
+	TimeControl string            `json:"time_control"`
+	TimeClass   string            `json:"time_class,omitempty"`
+	ECOCode     string            `json:"eco_code,omitempty"`
+	OpeningName string            `json:"opening_name,omitempty"`
+	Endgame     string            `json:"endgame,omitempty"`
+	PGNText     string            `json:"pgn_text"`
+	GameHash    string            `json:"-"`
+	CreatedAt   string            `json:"created_at"`
+	Tags        map[string]string `json:"tags"`
+	Notes       string            `json:"notes,omitempty"`
+}
+
+// gameColumns are the columns of the games table read into a Game, named
+// rather than selected with * so that columns added by migrations, which
+// come last in tables created before them, cannot shift the others. Columns
+// that are NULL in games stored before they were added read as zero values.
+const gameColumns = `
+	id, COALESCE(event, ''), COALESCE(site, ''), COALESCE(date, ''), COALESCE(round, ''),
+	COALESCE(white, ''), COALESCE(black, ''), COALESCE(result, ''),
+	COALESCE(white_elo, 0), COALESCE(black_elo, 0), COALESCE(time_control, ''),
+	pgn_text, COALESCE(created_at, ''), COALESCE(game_hash, ''),
+	COALESCE(eco_code, ''), COALESCE(opening_name, ''), COALESCE(endgame, ''), COALESCE(time_class, '')
+`
+
+// scanGame scans a row of gameColumns into a Game.
+func scanGame(row interface{ Scan(...interface{}) error }) (*Game, error) {
+	var g Game
+	err := row.Scan(
+		&g.ID, &g.Event, &g.Site, &g.Date, &g.Round, &g.White, &g.Black, &g.Result,
+		&g.WhiteElo, &g.BlackElo, &g.TimeControl, &g.PGNText, &g.CreatedAt, &g.GameHash,
+		&g.ECOCode, &g.OpeningName, &g.Endgame, &g.TimeClass,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
+
+// GetGame retrieves a game by its ID, with its tags and note.
+func (db *DB) GetGame(ctx context.Context, id int) (*Game, error) {
+	game, err := scanGame(db.conn.QueryRowContext(ctx, "SELECT "+gameColumns+" FROM games WHERE id = ? AND deleted_at IS NULL", id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %d", ErrGameNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan game: %w", err)
+	}
+
+	rows, err := db.conn.QueryContext(ctx, "SELECT tag_name, tag_value FROM tags WHERE game_id = ?", id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tags: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	game.Tags = make(map[string]string)
+	for rows.Next() {
+		var name, value string
+		if err := rows.Scan(&name, &value); err != nil {
+			return nil, fmt.Errorf("failed to scan tag: %w", err)
+		}
+		game.Tags[name] = value
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tag rows: %w", err)
+	}
+
+	if game.Notes, err = db.GetNote(ctx, id); err != nil {
+		return nil, err
+	}
+	return game, nil
+}
+
+// RandomGameID returns the ID of a game picked at random, among the games of
+// player when one is given, matched by part of the name as SearchGames
+// does. ErrGameNotFound is returned when there is none.
+func (db *DB) RandomGameID(ctx context.Context, player string) (int, error) {
+	var id int
+	pattern := "%" + player + "%"
+	err := db.conn.QueryRowContext(ctx, `
+		SELECT id FROM games
+		WHERE deleted_at IS NULL AND (? = '' OR white LIKE ? OR black LIKE ?)
+		ORDER BY RANDOM() LIMIT 1
+	`, player, pattern, pattern).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		if player != "" {
+			return 0, fmt.Errorf("%w: no games of %s", ErrGameNotFound, player)
+		}
+		return 0, fmt.Errorf("%w: the database has no games", ErrGameNotFound)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to pick a game: %w", err)
+	}
+	return id, nil
+}
+
+// Map returns the game as a map keyed by column, as GetGameByID does.
+// Opening, endgame, time class and notes are left out when unknown.
+func (g *Game) Map() map[string]interface{} {
+	m := map[string]interface{}{
+		"id":           g.ID,
+		"event":        g.Event,
+		"site":         g.Site,
+		"date":         g.Date,
+		"round":        g.Round,
+		"white":        g.White,
+		"black":        g.Black,
+		"result":       g.Result,
+		"white_elo":    g.WhiteElo,
+		"black_elo":    g.BlackElo,
+		"time_control": g.TimeControl,
+		"pgn_text":     g.PGNText,
+		"created_at":   g.CreatedAt,
+		"tags":         g.Tags,
+	}
+	for key, value := range map[string]string{
+		"eco_code":     g.ECOCode,
+		"opening_name": g.OpeningName,
+		"endgame":      g.Endgame,
+		"time_class":   g.TimeClass,
+		"notes":        g.Notes,
+	} {
+		if value != "" {
+			m[key] = value
+		}
+	}
+	return m
+}
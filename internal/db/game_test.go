@@ -0,0 +1,142 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/kyleboon/gochess/internal/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGetGame_MigratedSchema reads games from databases created by earlier
+// versions, whose games tables have the columns added by migrations last,
+// in the order they were added, and NULL in the games stored before.
+func TestGetGame_MigratedSchema(t *testing.T) {
+	schemas := map[string][]string{
+		"original schema": nil,
+		"game hash added": {"ALTER TABLE games ADD COLUMN game_hash TEXT"},
+		"columns added in another order": {
+			"ALTER TABLE games ADD COLUMN time_class TEXT",
+			"ALTER TABLE games ADD COLUMN eco_code TEXT",
+			"ALTER TABLE games ADD COLUMN game_hash TEXT",
+		},
+	}
+	for name, migrations := range schemas {
+		t.Run(name, func(t *testing.T) {
+			dbPath := filepath.Join(t.TempDir(), "old.db")
+			conn, err := sql.Open("sqlite3", dbPath)
+			require.NoError(t, err)
+			_, err = conn.Exec(`
+				CREATE TABLE games (
+					id INTEGER PRIMARY KEY AUTOINCREMENT,
+					event TEXT, site TEXT, date TEXT, round TEXT, white TEXT, black TEXT, result TEXT,
+					white_elo INTEGER, black_elo INTEGER, time_control TEXT,
+					pgn_text TEXT NOT NULL,
+					created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+				)
+			`)
+			require.NoError(t, err)
+			for _, migration := range migrations {
+				_, err = conn.Exec(migration)
+				require.NoError(t, err)
+			}
+			_, err = conn.Exec(`
+				INSERT INTO games (event, site, date, round, white, black, result, white_elo, time_control, pgn_text)
+				VALUES ('Old Event', 'Old Site', '2020.05.01', '2', 'Alice', 'Bob', '1-0', 1900, '300+3', '1. e4 e5 1-0')
+			`)
+			require.NoError(t, err)
+			require.NoError(t, conn.Close())
+
+			database, err := NewWithLogger(dbPath, logging.Discard())
+			require.NoError(t, err)
+			defer func() { _ = database.Close() }()
+			ctx := context.Background()
+
+			game, err := database.GetGame(ctx, 1)
+			require.NoError(t, err)
+			assert.Equal(t, 1, game.ID)
+			assert.Equal(t, "Old Event", game.Event)
+			assert.Equal(t, "Alice", game.White)
+			assert.Equal(t, "Bob", game.Black)
+			assert.Equal(t, "1-0", game.Result)
+			assert.Equal(t, 1900, game.WhiteElo)
+			assert.Equal(t, 0, game.BlackElo, "a NULL rating reads as 0")
+			assert.Equal(t, "300+3", game.TimeControl)
+			assert.Equal(t, "1. e4 e5 1-0", game.PGNText)
+			assert.Equal(t, "", game.GameHash)
+			assert.Equal(t, "", game.ECOCode)
+			assert.NotEmpty(t, game.CreatedAt)
+			assert.Empty(t, game.Tags)
+
+			m, err := database.GetGameByID(ctx, 1)
+			require.NoError(t, err)
+			assert.Equal(t, "Alice", m["white"])
+			assert.Equal(t, "1. e4 e5 1-0", m["pgn_text"])
+			assert.NotContains(t, m, "eco_code")
+
+			_, err = database.GetGame(ctx, 2)
+			assert.ErrorIs(t, err, ErrGameNotFound)
+		})
+	}
+}
+
+func TestGetGame(t *testing.T) {
+	database, err := NewInMemory()
+	require.NoError(t, err)
+	defer func() { _ = database.Close() }()
+	ctx := context.Background()
+
+	count, errs := database.ImportPGN(ctx, "../../testdata/lichess_no_fen.pgn")
+	require.Empty(t, errs)
+	require.Equal(t, 1, count)
+	require.NoError(t, database.SetNote(ctx, 1, "Check the endgame"))
+
+	game, err := database.GetGame(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "kyle_boon", game.White)
+	assert.Equal(t, "kyle_boon", game.Tags["White"])
+	assert.NotEmpty(t, game.GameHash)
+	assert.NotEmpty(t, game.ECOCode)
+	assert.Equal(t, "Check the endgame", game.Notes)
+
+	// The map of GetGameByID has the same values
+	m, err := database.GetGameByID(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, game.ECOCode, m["eco_code"])
+	assert.Equal(t, game.TimeClass, m["time_class"])
+	assert.Equal(t, game.Notes, m["notes"])
+	assert.Equal(t, game.Tags, m["tags"])
+}
+
+func TestRandomGameID(t *testing.T) {
+	database, err := NewInMemory()
+	require.NoError(t, err)
+	defer func() { _ = database.Close() }()
+	ctx := context.Background()
+
+	_, err = database.RandomGameID(ctx, "")
+	assert.ErrorIs(t, err, ErrGameNotFound)
+
+	count, errs := database.ImportPGN(ctx, "../../testdata/lichess_no_fen.pgn")
+	require.Empty(t, errs)
+	require.Equal(t, 1, count)
+
+	id, err := database.RandomGameID(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, 1, id)
+
+	id, err = database.RandomGameID(ctx, "boon")
+	require.NoError(t, err)
+	assert.Equal(t, 1, id)
+
+	_, err = database.RandomGameID(ctx, "nobody")
+	assert.ErrorIs(t, err, ErrGameNotFound)
+
+	// Games in the trash are not picked
+	require.NoError(t, database.DeleteGame(ctx, 1))
+	_, err = database.RandomGameID(ctx, "")
+	assert.ErrorIs(t, err, ErrGameNotFound)
+}
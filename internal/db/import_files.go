@@ -0,0 +1,84 @@
+package db
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+)
+
+// defaultImportInclude is the pattern of the files imported from a directory
+// when no other is given.
+const defaultImportInclude = "*.pgn"
+
+// importFilter selects the files imported from a directory. Patterns are
+// matched against file names, not paths, as by filepath.Match and ignoring
+// case; exclude patterns also skip the directories they match.
+type importFilter struct {
+	include   []string // files imported, defaultImportInclude if empty
+	exclude   []string // files and directories skipped
+	recursive bool     // whether to walk subdirectories
+}
+
+// validate checks the patterns of the filter.
+func (f importFilter) validate() error {
+	for _, pattern := range append(append([]string(nil), f.include...), f.exclude...) {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// includes returns the patterns of the files imported.
+func (f importFilter) includes() []string {
+	if len(f.include) == 0 {
+		return []string{defaultImportInclude}
+	}
+	return f.include
+}
+
+// matchAny reports whether name matches any of patterns.
+func matchAny(patterns []string, name string) bool {
+	name = strings.ToLower(name)
+	for _, pattern := range patterns {
+		if ok, _ := filepath.Match(strings.ToLower(pattern), name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// walkPGNFiles calls visit with each file under dir selected by filter, in
+// lexical order, as it is found. An entry that cannot be read is reported
+// and skipped, so the rest of the directory is still visited; the errors are
+// returned once the walk is done.
+func walkPGNFiles(dir string, filter importFilter, visit func(path string)) []error {
+	include := filter.includes()
+	var errs []error
+	_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to read %s: %w", path, err))
+			// Skip what cannot be read, the rest of a directory included
+			// when it is the directory that failed
+			return nil
+		}
+		if d.IsDir() {
+			if path == dir {
+				return nil
+			}
+			if !filter.recursive || matchAny(filter.exclude, d.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() && d.Type()&fs.ModeSymlink == 0 {
+			return nil
+		}
+		if matchAny(include, d.Name()) && !matchAny(filter.exclude, d.Name()) {
+			visit(path)
+		}
+		return nil
+	})
+	return errs
+}
@@ -0,0 +1,49 @@
+package db
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWalkPGNFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.pgn", "b.PGN", "notes.txt", "my_blitz.pgn", "sub/c.pgn", "sub/deep/d.pgn", "blitz/e.pgn"} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, nil, 0644))
+	}
+
+	walk := func(filter importFilter) []string {
+		var files []string
+		errs := walkPGNFiles(dir, filter, func(path string) {
+			rel, err := filepath.Rel(dir, path)
+			require.NoError(t, err)
+			files = append(files, filepath.ToSlash(rel))
+		})
+		assert.Empty(t, errs)
+		return files
+	}
+
+	assert.Equal(t, []string{"a.pgn", "b.PGN", "my_blitz.pgn"}, walk(importFilter{}))
+	assert.Equal(t, []string{"a.pgn", "b.PGN", "blitz/e.pgn", "my_blitz.pgn", "sub/c.pgn", "sub/deep/d.pgn"},
+		walk(importFilter{recursive: true}))
+	assert.Equal(t, []string{"a.pgn", "b.PGN", "sub/c.pgn", "sub/deep/d.pgn"},
+		walk(importFilter{recursive: true, exclude: []string{"*blitz*"}}), "exclude skips directories too")
+	assert.Equal(t, []string{"notes.txt", "sub/c.pgn"},
+		walk(importFilter{recursive: true, include: []string{"*.txt", "c.*"}}))
+
+	// A missing directory is reported rather than failing the walk
+	errs := walkPGNFiles(filepath.Join(dir, "missing"), importFilter{}, func(string) {
+		t.Error("no file should be visited")
+	})
+	assert.Len(t, errs, 1)
+}
+
+func TestImportFilterValidate(t *testing.T) {
+	assert.NoError(t, importFilter{include: []string{"*.pgn"}, exclude: []string{"*blitz*"}}.validate())
+	assert.Error(t, importFilter{exclude: []string{"[blitz"}}.validate())
+}
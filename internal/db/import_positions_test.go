@@ -195,3 +195,40 @@ func TestImportPGN_SkipDuplicatePositions(t *testing.T) {
 
 	assert.Equal(t, 3, positionCount, "Should have 3 positions (not duplicated)")
 }
+
+// TestImportPGNWithProgress tests that the progress callback covers every game
+func TestImportPGNWithProgress(t *testing.T) {
+	tempDir := t.TempDir()
+	db, err := NewWithLogger(tempDir+"/test.db", logging.Discard())
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	pgnContent := `[Event "One"]
+[Site "Test"]
+[Date "2024.01.01"]
+[White "Player1"]
+[Black "Player2"]
+[Result "1-0"]
+
+1. e4 e5 1-0
+
+[Event "Two"]
+[Site "Test"]
+[Date "2024.01.02"]
+[White "Player2"]
+[Black "Player1"]
+[Result "0-1"]
+
+1. d4 d5 0-1
+`
+	pgnFile := tempDir + "/test.pgn"
+	require.NoError(t, os.WriteFile(pgnFile, []byte(pgnContent), 0644))
+
+	var calls [][2]int
+	count, errs := db.ImportPGNWithProgress(context.Background(), pgnFile, func(done, total int) {
+		calls = append(calls, [2]int{done, total})
+	})
+	require.Empty(t, errs)
+	assert.Equal(t, 2, count)
+	assert.Equal(t, [][2]int{{0, 2}, {1, 2}, {2, 2}}, calls)
+}
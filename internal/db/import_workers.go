@@ -0,0 +1,208 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"runtime"
+
+	"github.com/kyleboon/gochess/internal/pgn"
+)
+
+// importCandidate is a game of a PGN to be imported, checked and found new.
+type importCandidate struct {
+	index int // position of the game in the PGN
+	game  *pgn.Game
+	text  string
+	hash  string
+}
+
+// preparedGame holds what is derived from the moves of a game for storing
+// it. Moves that failed to parse leave it empty but for the error.
+type preparedGame struct {
+	parseErr         error
+	ecoCode, opening string
+	endgame          string
+	positions        []Position
+	analysis         *GameAnalysis
+	evals            map[int]float64
+}
+
+// workers returns the number of import workers to use.
+func (opts ImportOptions) workers() int {
+	if opts.Workers > 0 {
+		return opts.Workers
+	}
+	return runtime.GOMAXPROCS(0)
+}
+
+// parallelOrdered calls work for 0 to n-1 on up to workers goroutines, and
+// consume with each result in order, on the calling goroutine. Results wait
+// to be consumed in a window of a few per worker, which bounds the memory
+// used when consuming is slower than working.
+func parallelOrdered[T any](n, workers int, work func(i int) T, consume func(i int, result T)) {
+	workers = max(1, min(workers, n))
+	if workers == 1 {
+		for i := 0; i < n; i++ {
+			consume(i, work(i))
+		}
+		return
+	}
+
+	window := workers * 4
+	slots := make([]chan T, window)
+	for i := range slots {
+		slots[i] = make(chan T, 1)
+	}
+	// Each free slot holds a token, taken to start a result and given back
+	// once it is consumed
+	free := make(chan struct{}, window)
+	for i := 0; i < window; i++ {
+		free <- struct{}{}
+	}
+	next := make(chan int)
+	go func() {
+		defer close(next)
+		for i := 0; i < n; i++ {
+			<-free
+			next <- i
+		}
+	}()
+	for w := 0; w < workers; w++ {
+		go func() {
+			for i := range next {
+				slots[i%window] <- work(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		consume(i, <-slots[i%window])
+		free <- struct{}{}
+	}
+}
+
+// prepareGame parses the moves of a game and derives its opening, endgame,
+// positions and any analysis in its comments. It only reads from db, so
+// games can be prepared concurrently.
+func (db *DB) prepareGame(pgnDB *pgn.DB, game *pgn.Game) preparedGame {
+	var prepared preparedGame
+	if err := pgnDB.ParseMoves(game); err != nil {
+		prepared.parseErr = err
+		return prepared
+	}
+	prepared.endgame = gameEndgame(mainLineBoards(game))
+	if game.Root == nil || game.Root.Next == nil {
+		return prepared
+	}
+
+	// Classify opening using ECO database
+	if moveStrs := extractMoveStrings(game); len(moveStrs) > 0 {
+		prepared.ecoCode, prepared.opening, _ = db.ecoDB.Classify(moveStrs)
+	}
+	prepared.positions = ExtractPositions(game)
+	// Lichess exports with evaluations come with their analysis
+	prepared.analysis, prepared.evals = commentAnalysis(game)
+	return prepared
+}
+
+// importTx is the transaction of an import, with the statements that store
+// its games.
+type importTx struct {
+	tx                  *sql.Tx
+	game, tag, position *sql.Stmt
+}
+
+// beginImport begins the transaction of an import and prepares its
+// statements. The transaction is rolled back if a statement fails to
+// prepare.
+func (db *DB) beginImport(ctx context.Context) (*importTx, error) {
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		db.logger.Error("failed to begin transaction", "error", err)
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	db.logger.Debug("transaction started")
+	itx := &importTx{tx: tx}
+
+	itx.game, err = tx.PrepareContext(ctx, `
+		INSERT INTO games (
+			event, site, date, round, white, black, result,
+			white_elo, black_elo, time_control, pgn_text, game_hash, eco_code, opening_name, endgame, time_class
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		itx.close()
+		_ = tx.Rollback()
+		return nil, fmt.Errorf("failed to prepare game statement: %w", err)
+	}
+	itx.tag, err = tx.PrepareContext(ctx, `
+		INSERT INTO tags (game_id, tag_name, tag_value)
+		VALUES (?, ?, ?)
+	`)
+	if err != nil {
+		itx.close()
+		_ = tx.Rollback()
+		return nil, fmt.Errorf("failed to prepare tag statement: %w", err)
+	}
+	itx.position, err = tx.PrepareContext(ctx, `
+		INSERT INTO positions (game_id, move_number, fen, next_move, evaluation, eco_code, opening_name)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		itx.close()
+		_ = tx.Rollback()
+		return nil, fmt.Errorf("failed to prepare position statement: %w", err)
+	}
+	return itx, nil
+}
+
+// close closes the prepared statements of an import.
+func (itx *importTx) close() {
+	for _, stmt := range []*sql.Stmt{itx.game, itx.tag, itx.position} {
+		if stmt != nil {
+			_ = stmt.Close()
+		}
+	}
+}
+
+// storeGame inserts a prepared game with its positions and analysis. Only a
+// failure to insert the game itself is returned, as a *PGNImportError.
+func (db *DB) storeGame(ctx context.Context, tx *sql.Tx, stmtGame, stmtTag, stmtPosition *sql.Stmt, c importCandidate, prepared preparedGame) error {
+	game := c.game
+	if prepared.parseErr != nil {
+		db.logger.Warn("failed to parse moves for game",
+			"event", game.Tags["Event"], "error", prepared.parseErr)
+		// Don't fail the import if move parsing fails
+	} else if prepared.ecoCode != "" {
+		db.logger.Debug("opening classified",
+			"eco", prepared.ecoCode, "opening", prepared.opening, "event", game.Tags["Event"])
+	}
+
+	// Insert game and tags
+	gameID, err := insertGameRecord(ctx, tx, stmtGame, stmtTag, game, c.text, c.hash, prepared.ecoCode, prepared.opening, prepared.endgame)
+	if err != nil {
+		dbErr := fmt.Errorf("game %d (event: %s): %w", c.index+1, game.Tags["Event"], err)
+		return &PGNImportError{OriginalError: dbErr, PGNText: c.text}
+	}
+
+	if len(prepared.positions) > 0 {
+		if err := insertPositions(ctx, tx, stmtPosition, gameID, prepared.positions, prepared.ecoCode, prepared.opening); err != nil {
+			db.logger.Warn("failed to insert positions for game",
+				"game_id", gameID, "event", game.Tags["Event"], "error", err)
+			// Don't fail the import if position storage fails
+		} else {
+			db.logger.Debug("positions inserted", "game_id", gameID, "count", len(prepared.positions))
+		}
+	}
+
+	if analysis := prepared.analysis; analysis != nil {
+		analysis.GameID = int(gameID)
+		if _, err := insertAnalysis(ctx, tx, analysis, prepared.evals); err != nil {
+			db.logger.Warn("failed to store the analysis of game",
+				"game_id", gameID, "event", game.Tags["Event"], "error", err)
+		} else {
+			db.logger.Debug("analysis imported", "game_id", gameID, "source", analysis.Source)
+		}
+	}
+	return nil
+}
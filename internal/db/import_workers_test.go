@@ -0,0 +1,124 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kyleboon/gochess/internal/logging"
+	"github.com/kyleboon/gochess/internal/pgn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// benchmarkMovetexts are games of some length, to give the workers moves to
+// parse.
+var benchmarkMovetexts = []string{
+	"1. e4 e5 2. Nf3 Nc6 3. Bb5 a6 4. Ba4 Nf6 5. O-O Be7 6. Re1 b5 7. Bb3 d6 8. c3 O-O 9. h3 Nb8 10. d4 Nbd7 11. Nbd2 Bb7 12. Bc2 Re8 13. Nf1 Bf8 14. Ng3 g6 15. a4 c5 16. d5 c4 17. Bg5 h6 18. Be3 Nc5 19. Qd2 h5 20. Bg5 Be7 *",
+	"1. d4 Nf6 2. c4 e6 3. Nc3 Bb4 4. e3 O-O 5. Bd3 d5 6. Nf3 c5 7. O-O Nc6 8. a3 Bxc3 9. bxc3 dxc4 10. Bxc4 Qc7 11. Bd3 e5 12. Qc2 Re8 13. Nxe5 Nxe5 14. dxe5 Qxe5 15. f3 Bd7 16. e4 c4 17. Bxc4 Qc5+ 18. Kh1 Qxc4 19. Qd2 Bc6 20. Re1 Rad8 *",
+	"1. e4 c5 2. Nf3 d6 3. d4 cxd4 4. Nxd4 Nf6 5. Nc3 a6 6. Be3 e5 7. Nb3 Be6 8. f3 Be7 9. Qd2 O-O 10. O-O-O Nbd7 11. g4 b5 12. g5 b4 13. Ne2 Ne8 14. f4 a5 15. f5 a4 16. Nbd4 exd4 17. Nxd4 b3 18. Kb1 bxc2+ 19. Nxc2 Bb3 20. axb3 axb3 *",
+}
+
+// benchmarkPGN returns a PGN of n games by different players.
+func benchmarkPGN(n int) string {
+	var sb strings.Builder
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&sb, "[Event \"Bench\"]\n[Round \"%d\"]\n[White \"White %d\"]\n[Black \"Black %d\"]\n[Result \"*\"]\n\n%s\n\n",
+			i+1, i, i, benchmarkMovetexts[i%len(benchmarkMovetexts)])
+	}
+	return sb.String()
+}
+
+func TestParallelOrdered(t *testing.T) {
+	for _, workers := range []int{0, 1, 3, 8} {
+		t.Run(fmt.Sprintf("workers=%d", workers), func(t *testing.T) {
+			var got []int
+			parallelOrdered(50, workers, func(i int) int {
+				// Later items finish first
+				time.Sleep(time.Duration(50-i) * 10 * time.Microsecond)
+				return i * i
+			}, func(i, result int) {
+				assert.Equal(t, i*i, result)
+				got = append(got, i)
+			})
+			require.Len(t, got, 50)
+			for i, v := range got {
+				assert.Equal(t, i, v)
+			}
+		})
+	}
+}
+
+func TestImportPGNWithOptions_Workers(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "gochess-import-workers-test-")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(tempDir) }()
+
+	pgnFile := tempDir + "/games.pgn"
+	// A duplicate of the first game is skipped, not stored out of order
+	data := benchmarkPGN(40) + strings.SplitAfter(benchmarkPGN(1), "*\n\n")[0]
+	require.NoError(t, os.WriteFile(pgnFile, []byte(data), 0644))
+
+	database, err := NewWithLogger(tempDir+"/test.db", logging.Discard())
+	require.NoError(t, err)
+	defer func() { _ = database.Close() }()
+	ctx := context.Background()
+
+	var done []int
+	result := database.ImportPGNWithOptions(ctx, pgnFile, ImportOptions{
+		Workers:  4,
+		Progress: func(n, total int) { done = append(done, n) },
+	})
+	require.Empty(t, result.Errors)
+	assert.Equal(t, 40, result.Imported)
+	assert.Equal(t, 1, result.Duplicates)
+	assert.IsIncreasing(t, done)
+
+	// Games are stored in the order of the PGN, with their moves parsed
+	for i := 0; i < 40; i++ {
+		game, err := database.GetGameByID(ctx, i+1)
+		require.NoError(t, err)
+		assert.Equal(t, fmt.Sprintf("White %d", i), game["white"])
+	}
+	var positions int
+	require.NoError(t, database.conn.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM positions WHERE game_id = 40").Scan(&positions))
+	assert.Equal(t, 41, positions)
+}
+
+// BenchmarkPrepareGames measures parsing the moves of a PGN and deriving
+// what is stored of its games, the bulk of an import, over a number of
+// workers. The time per game should fall about linearly with the workers, up
+// to the number of CPUs.
+func BenchmarkPrepareGames(b *testing.B) {
+	tempDir := b.TempDir()
+	database, err := NewWithLogger(tempDir+"/bench.db", logging.Discard())
+	require.NoError(b, err)
+	defer func() { _ = database.Close() }()
+
+	const games = 240
+	data := benchmarkPGN(games)
+	for _, workers := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				// Parsing the moves consumes them, so each run parses anew
+				b.StopTimer()
+				pgnDB := &pgn.DB{}
+				require.Empty(b, pgnDB.Parse(data))
+				b.StartTimer()
+
+				parallelOrdered(len(pgnDB.Games), workers, func(j int) preparedGame {
+					return database.prepareGame(pgnDB, pgnDB.Games[j])
+				}, func(j int, prepared preparedGame) {
+					if prepared.parseErr != nil {
+						b.Fatal(prepared.parseErr)
+					}
+				})
+			}
+			b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*games), "ns/game")
+		})
+	}
+}
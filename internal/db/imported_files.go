@@ -0,0 +1,102 @@
+package db
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ImportedFile records a PGN file imported into the database, to skip it on
+// a later import while it is unchanged.
+type ImportedFile struct {
+	Path       string    `json:"path"` // absolute
+	Size       int64     `json:"size"`
+	SHA256     string    `json:"sha256"`
+	ImportedAt time.Time `json:"imported_at"`
+}
+
+// createImportedFilesTable creates the table of the PGN files imported
+func (db *DB) createImportedFilesTable() error {
+	_, err := db.conn.Exec(`
+		CREATE TABLE IF NOT EXISTS imported_files (
+			path TEXT PRIMARY KEY,
+			size INTEGER NOT NULL,
+			sha256 TEXT NOT NULL,
+			imported_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create imported_files table: %w", err)
+	}
+	return nil
+}
+
+// FileChecksum returns the absolute path, size and SHA-256 checksum of a
+// file, identifying it as imported.
+func FileChecksum(path string) (*ImportedFile, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve path %s: %w", path, err)
+	}
+	f, err := os.Open(abs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer func() { _ = f.Close() }()
+
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return &ImportedFile{Path: abs, Size: size, SHA256: hex.EncodeToString(h.Sum(nil))}, nil
+}
+
+// GetImportedFile retrieves the record of the file imported from path, or
+// nil if none was.
+func (db *DB) GetImportedFile(ctx context.Context, path string) (*ImportedFile, error) {
+	f := ImportedFile{Path: path}
+	err := db.conn.QueryRowContext(ctx,
+		"SELECT size, sha256, imported_at FROM imported_files WHERE path = ?", path,
+	).Scan(&f.Size, &f.SHA256, &f.ImportedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query imported file: %w", err)
+	}
+	return &f, nil
+}
+
+// UnchangedImport returns the record of a file imported as it is now, with
+// the same size and checksum, or nil if it was not or has changed since.
+func (db *DB) UnchangedImport(ctx context.Context, file *ImportedFile) (*ImportedFile, error) {
+	recorded, err := db.GetImportedFile(ctx, file.Path)
+	if err != nil || recorded == nil {
+		return nil, err
+	}
+	if recorded.Size != file.Size || recorded.SHA256 != file.SHA256 {
+		return nil, nil
+	}
+	return recorded, nil
+}
+
+// RecordImportedFile records a file as imported now, replacing any earlier
+// record of its path.
+func (db *DB) RecordImportedFile(ctx context.Context, file *ImportedFile) error {
+	_, err := db.conn.ExecContext(ctx, `
+		INSERT INTO imported_files (path, size, sha256, imported_at) VALUES (?, ?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(path) DO UPDATE SET size = excluded.size, sha256 = excluded.sha256, imported_at = excluded.imported_at
+	`, file.Path, file.Size, file.SHA256)
+	if err != nil {
+		return fmt.Errorf("failed to record imported file: %w", err)
+	}
+	return nil
+}
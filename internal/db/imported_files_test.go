@@ -0,0 +1,57 @@
+package db
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kyleboon/gochess/internal/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportedFiles(t *testing.T) {
+	tempDir := t.TempDir()
+	database, err := NewWithLogger(filepath.Join(tempDir, "test.db"), logging.Discard())
+	require.NoError(t, err)
+	defer func() { _ = database.Close() }()
+	ctx := context.Background()
+
+	pgnFile := filepath.Join(tempDir, "games.pgn")
+	require.NoError(t, os.WriteFile(pgnFile, []byte("[Event \"A\"]\n\n1. e4 *\n"), 0644))
+	file, err := FileChecksum(pgnFile)
+	require.NoError(t, err)
+	assert.Equal(t, pgnFile, file.Path)
+	assert.Equal(t, int64(21), file.Size)
+	assert.Len(t, file.SHA256, 64)
+
+	recorded, err := database.UnchangedImport(ctx, file)
+	require.NoError(t, err)
+	assert.Nil(t, recorded, "the file was not imported yet")
+
+	require.NoError(t, database.RecordImportedFile(ctx, file))
+	recorded, err = database.UnchangedImport(ctx, file)
+	require.NoError(t, err)
+	require.NotNil(t, recorded)
+	assert.Equal(t, file.SHA256, recorded.SHA256)
+	assert.False(t, recorded.ImportedAt.IsZero())
+
+	// A file grown since is imported again, and recorded anew
+	require.NoError(t, os.WriteFile(pgnFile, []byte("[Event \"A\"]\n\n1. e4 *\n\n[Event \"B\"]\n\n1. d4 *\n"), 0644))
+	changed, err := FileChecksum(pgnFile)
+	require.NoError(t, err)
+	recorded, err = database.UnchangedImport(ctx, changed)
+	require.NoError(t, err)
+	assert.Nil(t, recorded)
+	require.NoError(t, database.RecordImportedFile(ctx, changed))
+	recorded, err = database.GetImportedFile(ctx, pgnFile)
+	require.NoError(t, err)
+	assert.Equal(t, changed.Size, recorded.Size)
+
+	// Clearing the games forgets the files they came from
+	require.NoError(t, database.ClearGames(ctx))
+	recorded, err = database.GetImportedFile(ctx, pgnFile)
+	require.NoError(t, err)
+	assert.Nil(t, recorded)
+}
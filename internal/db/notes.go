@@ -0,0 +1,67 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// createNotesTable creates the table holding the player's own notes on games,
+// kept apart from the comments in their PGN
+func (db *DB) createNotesTable() error {
+	_, err := db.conn.Exec(`
+		CREATE TABLE IF NOT EXISTS notes (
+			game_id INTEGER PRIMARY KEY,
+			text TEXT NOT NULL,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (game_id) REFERENCES games(id) ON DELETE CASCADE
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create notes table: %w", err)
+	}
+	return nil
+}
+
+// GetNote retrieves the note on a game, or "" if it has none.
+func (db *DB) GetNote(ctx context.Context, gameID int) (string, error) {
+	var text string
+	err := db.conn.QueryRowContext(ctx, "SELECT text FROM notes WHERE game_id = ?", gameID).Scan(&text)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to query note: %w", err)
+	}
+	return text, nil
+}
+
+// SetNote replaces the note on a game. Surrounding whitespace is trimmed, and
+// a blank note deletes it.
+func (db *DB) SetNote(ctx context.Context, gameID int, text string) error {
+	var exists bool
+	if err := db.conn.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM games WHERE id = ? AND deleted_at IS NULL)", gameID).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to query game: %w", err)
+	}
+	if !exists {
+		return fmt.Errorf("%w: %d", ErrGameNotFound, gameID)
+	}
+
+	text = strings.TrimSpace(text)
+	if text == "" {
+		if _, err := db.conn.ExecContext(ctx, "DELETE FROM notes WHERE game_id = ?", gameID); err != nil {
+			return fmt.Errorf("failed to delete note: %w", err)
+		}
+		return nil
+	}
+	_, err := db.conn.ExecContext(ctx, `
+		INSERT INTO notes (game_id, text) VALUES (?, ?)
+		ON CONFLICT (game_id) DO UPDATE SET text = excluded.text, updated_at = CURRENT_TIMESTAMP
+	`, gameID, text)
+	if err != nil {
+		return fmt.Errorf("failed to save note: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package db
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/kyleboon/gochess/internal/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotes(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "gochess-notes-test-")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(tempDir) }()
+
+	database, err := NewWithLogger(tempDir+"/test.db", logging.Discard())
+	require.NoError(t, err)
+	defer func() { _ = database.Close() }()
+	ctx := context.Background()
+
+	pgnFile := tempDir + "/games.pgn"
+	require.NoError(t, os.WriteFile(pgnFile, []byte(`[Event "1"] [Site "?"] [Date "2024.01.01"] [White "Alice"] [Black "Bob"] [Result "1-0"] 1. e4 e5 {PGN comment} 1-0`), 0644))
+	_, errs := database.ImportPGN(ctx, pgnFile)
+	require.Empty(t, errs)
+
+	note, err := database.GetNote(ctx, 1)
+	require.NoError(t, err)
+	assert.Empty(t, note)
+
+	require.NoError(t, database.SetNote(ctx, 1, "Played too fast after e5.\n"))
+	note, err = database.GetNote(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "Played too fast after e5.", note)
+
+	require.NoError(t, database.SetNote(ctx, 1, "Check the engine line."))
+	game, err := database.GetGameByID(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "Check the engine line.", game["notes"])
+	assert.Contains(t, game["pgn_text"], "PGN comment", "the PGN is left alone")
+
+	// A blank note deletes it
+	require.NoError(t, database.SetNote(ctx, 1, "  \n"))
+	game, err = database.GetGameByID(ctx, 1)
+	require.NoError(t, err)
+	assert.NotContains(t, game, "notes")
+
+	err = database.SetNote(ctx, 42, "no such game")
+	assert.ErrorIs(t, err, ErrGameNotFound)
+}
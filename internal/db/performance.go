@@ -0,0 +1,113 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/kyleboon/gochess/internal"
+	"github.com/kyleboon/gochess/internal/pgn"
+	"github.com/kyleboon/gochess/internal/rating"
+)
+
+// PerformanceGame is a finished game counted in a performance rating.
+type PerformanceGame struct {
+	GameID         int     `json:"game_id"`
+	Date           string  `json:"date"`
+	Round          string  `json:"round,omitempty"`
+	Event          string  `json:"event,omitempty"`
+	Color          string  `json:"color"` // "white" or "black"
+	Opponent       string  `json:"opponent"`
+	Rating         int     `json:"rating,omitempty"`          // the player's rating, 0 if unknown
+	OpponentRating int     `json:"opponent_rating,omitempty"` // 0 if unrated
+	Result         string  `json:"result"`
+	Score          float64 `json:"score"`
+	Expected       float64 `json:"expected"` // expected score, 0 if either side is unrated
+}
+
+// Performance summarizes how a player scored against the ratings of their
+// opponents. Only games against rated opponents are counted in Rated,
+// RatedScore, Expected, AverageOpponent and Performance.
+type Performance struct {
+	Player          string            `json:"player"`
+	Event           string            `json:"event,omitempty"`
+	Games           []PerformanceGame `json:"games"`
+	Score           float64           `json:"score"`
+	Rated           int               `json:"rated_games"`
+	RatedScore      float64           `json:"rated_score"`
+	Expected        float64           `json:"expected"`
+	AverageOpponent float64           `json:"average_opponent"`
+	Performance     float64           `json:"performance"`
+}
+
+// GetPerformance computes the performance rating of player, matched case
+// insensitively, over their finished games, optionally only those of event.
+// The expected score of a game is based on the player's rating in the game,
+// unless playerRating is positive, in which case it is used for every game.
+func (db *DB) GetPerformance(ctx context.Context, player, event string, playerRating int) (*Performance, error) {
+	query := `
+		SELECT id, COALESCE(date, ''), COALESCE(round, ''), COALESCE(event, ''),
+			white, black, COALESCE(white_elo, 0), COALESCE(black_elo, 0), result
+		FROM games
+		WHERE (white = ? COLLATE NOCASE OR black = ? COLLATE NOCASE)
+		AND deleted_at IS NULL
+		AND result IN ('1-0', '0-1', '1/2-1/2')
+	`
+	args := []interface{}{player, player}
+	if event != "" {
+		query += " AND event = ? COLLATE NOCASE"
+		args = append(args, event)
+	}
+	query += " ORDER BY date, id"
+
+	rows, err := db.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query games: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	perf := &Performance{Player: player, Event: event, Games: []PerformanceGame{}}
+	var opponents []float64
+	for rows.Next() {
+		var g PerformanceGame
+		var white, black string
+		var whiteElo, blackElo sql.NullInt64
+		if err := rows.Scan(&g.GameID, &g.Date, &g.Round, &g.Event, &white, &black, &whiteElo, &blackElo, &g.Result); err != nil {
+			return nil, fmt.Errorf("failed to scan game: %w", err)
+		}
+
+		result := pgn.ParseResult(g.Result)
+		if strings.EqualFold(white, player) {
+			g.Color, g.Opponent = "white", black
+			g.Rating, g.OpponentRating = int(whiteElo.Int64), int(blackElo.Int64)
+			g.Score = result.Score(internal.White)
+		} else {
+			g.Color, g.Opponent = "black", white
+			g.Rating, g.OpponentRating = int(blackElo.Int64), int(whiteElo.Int64)
+			g.Score = result.Score(internal.Black)
+		}
+		if playerRating > 0 {
+			g.Rating = playerRating
+		}
+
+		perf.Score += g.Score
+		if g.OpponentRating > 0 {
+			perf.Rated++
+			perf.RatedScore += g.Score
+			opponents = append(opponents, float64(g.OpponentRating))
+			if g.Rating > 0 {
+				g.Expected = rating.ExpectedScore(float64(g.Rating), float64(g.OpponentRating))
+				perf.Expected += g.Expected
+			}
+		}
+		perf.Games = append(perf.Games, g)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read games: %w", err)
+	}
+
+	perf.AverageOpponent = rating.Average(opponents)
+	perf.Performance = rating.Performance(opponents, perf.RatedScore)
+	return perf, nil
+}
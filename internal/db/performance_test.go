@@ -0,0 +1,74 @@
+package db
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/kyleboon/gochess/internal/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetPerformance(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "gochess-performance-test-")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(tempDir) }()
+
+	database, err := NewWithLogger(tempDir+"/test.db", logging.Discard())
+	require.NoError(t, err)
+	defer func() { _ = database.Close() }()
+	ctx := context.Background()
+
+	games := []string{
+		`[Event "Club Champ"] [Site "?"] [Date "2024.03.01"] [Round "1"] [White "Alice"] [Black "Bob"] [Result "1-0"] [WhiteElo "1500"] [BlackElo "1400"] 1. e4 e5 1-0`,
+		`[Event "Club Champ"] [Site "?"] [Date "2024.03.08"] [Round "2"] [White "Carol"] [Black "Alice"] [Result "1/2-1/2"] [WhiteElo "1600"] [BlackElo "1500"] 1. d4 d5 1/2-1/2`,
+		`[Event "Club Champ"] [Site "?"] [Date "2024.03.15"] [Round "3"] [White "Alice"] [Black "Dave"] [Result "0-1"] [WhiteElo "1500"] 1. c4 e5 0-1`,
+		`[Event "Club Champ"] [Site "?"] [Date "2024.03.22"] [Round "4"] [White "Alice"] [Black "Erin"] [Result "*"] [WhiteElo "1500"] [BlackElo "1700"] 1. Nf3 *`,
+		`[Event "Blitz"] [Site "?"] [Date "2024.03.23"] [Round "1"] [White "Alice"] [Black "Bob"] [Result "1-0"] [WhiteElo "1500"] [BlackElo "1400"] 1. e4 c5 1-0`,
+	}
+	pgnFile := tempDir + "/games.pgn"
+	require.NoError(t, os.WriteFile(pgnFile, []byte(strings.Join(games, "\n\n")), 0644))
+	count, errs := database.ImportPGN(ctx, pgnFile)
+	require.Empty(t, errs)
+	require.Equal(t, 5, count)
+
+	perf, err := database.GetPerformance(ctx, "alice", "club champ", 0)
+	require.NoError(t, err)
+
+	// The unfinished game is left out, the one against an unrated opponent
+	// only counts towards the total score
+	require.Len(t, perf.Games, 3)
+	assert.Equal(t, 1.5, perf.Score)
+	assert.Equal(t, 2, perf.Rated)
+	assert.Equal(t, 1.5, perf.RatedScore)
+	assert.Equal(t, 1500.0, perf.AverageOpponent)
+	assert.InDelta(t, 1691, perf.Performance, 1)
+	assert.InDelta(t, 0.640+0.360, perf.Expected, 0.01)
+
+	assert.Equal(t, "black", perf.Games[1].Color)
+	assert.Equal(t, "Carol", perf.Games[1].Opponent)
+	assert.Equal(t, 0.5, perf.Games[1].Score)
+	assert.Equal(t, 0.0, perf.Games[2].Expected)
+
+	t.Run("rating override", func(t *testing.T) {
+		perf, err := database.GetPerformance(ctx, "Alice", "Club Champ", 1400)
+		require.NoError(t, err)
+		assert.InDelta(t, 0.5+0.240, perf.Expected, 0.01)
+	})
+
+	t.Run("every event", func(t *testing.T) {
+		perf, err := database.GetPerformance(ctx, "Alice", "", 0)
+		require.NoError(t, err)
+		assert.Len(t, perf.Games, 4)
+		assert.Equal(t, 2.5, perf.Score)
+	})
+
+	t.Run("no games", func(t *testing.T) {
+		perf, err := database.GetPerformance(ctx, "Nobody", "", 0)
+		require.NoError(t, err)
+		assert.Empty(t, perf.Games)
+		assert.Equal(t, 0.0, perf.Performance)
+	})
+}
@@ -45,9 +45,13 @@ func ParsePGNFileWithMoves(filePath string) (*PGNData, []error) {
 	if err != nil {
 		return nil, []error{fmt.Errorf("failed to read PGN file: %w", err)}
 	}
+	return ParsePGNWithMoves(string(data))
+}
 
+// ParsePGNWithMoves parses PGN text like ParsePGNFileWithMoves
+func ParsePGNWithMoves(data string) (*PGNData, []error) {
 	// Pre-process the data to handle Chess.com specific formats
-	processedData := preprocessPGN(string(data))
+	processedData := preprocessPGN(data)
 
 	// Split into individual games to preserve complete text
 	games := splitGames(processedData)
@@ -71,62 +75,40 @@ func preprocessPGN(data string) string {
 	return data
 }
 
-// splitGames attempts to split a PGN string into individual games
+// tagLine matches a line starting with a PGN tag, as in `[White "Carlsen"]`
+var tagLine = regexp.MustCompile(`^\[[A-Za-z0-9_]+\s+"`)
+
+// splitGames splits a PGN string into the text of each game. A game starts
+// at the first tag after the movetext of the previous one, so games missing
+// any tag, Event included, keep their place in the order the parser finds
+// them.
 func splitGames(data string) []string {
-	// First try to split by event tag since this typically indicates the start of a new game
-	// Using regex to find game boundaries more accurately
-	regex := regexp.MustCompile(`(?m)^\[Event `) // Matches [Event at start of line
-	
-	// Find all positions where [Event appears at the start of a line
-	matches := regex.FindAllStringIndex(data, -1)
-	
-	if len(matches) == 0 {
-		// If no matches, check if the whole thing is a single game
-		if strings.Contains(data, "[White ") && strings.Contains(data, "[Black ") {
-			return []string{data}
-		}
-		return nil
-	}
-	
-	// Extract each game using the positions we found
-	games := make([]string, len(matches))
-	for i, match := range matches {
-		startPos := match[0]
-		endPos := len(data)
-		
-		// If this isn't the last game, set end position to the start of the next game
-		if i < len(matches)-1 {
-			endPos = matches[i+1][0]
+	var games []string
+	var current strings.Builder
+	inTags := false
+	flush := func() {
+		if game := strings.TrimSpace(current.String()); game != "" {
+			games = append(games, game)
 		}
-		
-		// Extract this game
-		games[i] = data[startPos:endPos]
+		current.Reset()
 	}
-	
-	// Clean up each game
-	for i, game := range games {
-		// Ensure each game has proper formatting
-		game = strings.TrimSpace(game)
-		
-		// Make sure the game has required tags
-		if !strings.Contains(game, "[White ") || !strings.Contains(game, "[Black ") {
-			// This is not a complete game, remove it
-			games[i] = ""
+	for _, line := range strings.SplitAfter(data, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			current.WriteString(line)
 			continue
 		}
-		
-		games[i] = game
-	}
-	
-	// Remove any empty games
-	validGames := make([]string, 0, len(games))
-	for _, game := range games {
-		if game != "" {
-			validGames = append(validGames, game)
+		isTag := tagLine.MatchString(trimmed)
+		if isTag && !inTags {
+			flush()
 		}
+		// Tags and moves on one line, as in exports with a game per line,
+		// end the tag section
+		inTags = isTag && strings.HasSuffix(trimmed, "]")
+		current.WriteString(line)
 	}
-	
-	return validGames
+	flush()
+	return games
 }
 
 // ExtractMoveText extracts just the moves portion of a PGN game
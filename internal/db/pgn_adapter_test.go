@@ -0,0 +1,48 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitGames(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []string
+	}{
+		{
+			name: "Games with tag sections",
+			data: "[Event \"1\"]\n[White \"A\"]\n\n1. e4 e5 1-0\n\n[Event \"2\"]\n[White \"B\"]\n\n1. d4 *\n",
+			want: []string{"[Event \"1\"]\n[White \"A\"]\n\n1. e4 e5 1-0", "[Event \"2\"]\n[White \"B\"]\n\n1. d4 *"},
+		},
+		{
+			name: "Games missing Event and players",
+			data: "[Site \"?\"]\n\n1. e4 *\n\n[Date \"2024.01.01\"]\n[Result \"1-0\"]\n1. d4 1-0\n",
+			want: []string{"[Site \"?\"]\n\n1. e4 *", "[Date \"2024.01.01\"]\n[Result \"1-0\"]\n1. d4 1-0"},
+		},
+		{
+			name: "A game per line",
+			data: "[Event \"1\"] [White \"A\"] 1. e4 1-0\n\n[Event \"2\"] [White \"B\"] 1. d4 0-1",
+			want: []string{"[Event \"1\"] [White \"A\"] 1. e4 1-0", "[Event \"2\"] [White \"B\"] 1. d4 0-1"},
+		},
+		{
+			name: "Comment lines starting with a command",
+			data: "[Event \"1\"]\n\n1. e4 {\n[%clk 0:03:00] } e5 *\n",
+			want: []string{"[Event \"1\"]\n\n1. e4 {\n[%clk 0:03:00] } e5 *"},
+		},
+		{
+			name: "No games",
+			data: "\n\n",
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := splitGames(tt.data); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitGames() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kyleboon/gochess/internal"
+	"github.com/kyleboon/gochess/internal/pgn"
+)
+
+// PhaseStats is the average length of the phases of a player's games.
+type PhaseStats struct {
+	Player       string  `json:"player"`
+	Games        int     `json:"games"`
+	Opening      float64 `json:"opening"`       // average plies in the opening
+	Middlegame   float64 `json:"middlegame"`    // average plies in the middlegame
+	Endgame      float64 `json:"endgame"`       // average plies in the endgame, over all games
+	EndgameGames int     `json:"endgame_games"` // games that reached an endgame
+}
+
+// gamePhases splits a game, given by its positions from the first, into its
+// phases like pgn.SplitPhases, except that the opening lasts at least until
+// the last position the game reaches of a line of the ECO classifier, so an
+// early queen trade in a known line does not cut the opening short. It never
+// lasts into the endgame.
+func (db *DB) gamePhases(fens []string) pgn.Phases {
+	boards := make([]*internal.Board, 0, len(fens))
+	for _, fen := range fens {
+		b, err := internal.ParseFen(fen)
+		if err != nil {
+			db.logger.Debug("skipping invalid position", "fen", fen, "error", err)
+			return pgn.Phases{}
+		}
+		boards = append(boards, b)
+	}
+	phases := pgn.SplitPhases(boards)
+
+	// The classifier knows the positions its lines end in
+	book := 0
+	for i, fen := range fens {
+		if _, ok := db.ecoDB.ClassifyFEN(fen); ok {
+			book = i
+		}
+	}
+	if extra := min(book, phases.Opening+phases.Middlegame) - phases.Opening; extra > 0 {
+		phases.Opening += extra
+		phases.Middlegame -= extra
+	}
+	return phases
+}
+
+// GetPhaseStats retrieves the average length of the opening, middlegame and
+// endgame of the games of the given players, from their indexed positions,
+// most games first. If players is empty, every player is counted.
+func (db *DB) GetPhaseStats(ctx context.Context, players []string) ([]PhaseStats, error) {
+	query := `
+		SELECT g.id, g.white, g.black, p.fen
+		FROM games g
+		JOIN positions p ON p.game_id = g.id
+		WHERE g.deleted_at IS NULL AND g.white != '' AND g.black != ''
+	`
+	var args []interface{}
+	if len(players) > 0 {
+		placeholders := make([]string, len(players))
+		for i, player := range players {
+			placeholders[i] = "?"
+			args = append(args, player)
+		}
+		playerList := strings.Join(placeholders, ",")
+		query += fmt.Sprintf(" AND (g.white IN (%s) OR g.black IN (%s))", playerList, playerList)
+		args = append(args, args...) // Duplicate args for both IN clauses
+	}
+	query += " ORDER BY g.id, p.move_number"
+
+	rows, err := db.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query positions: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	filterSet := make(map[string]bool)
+	for _, player := range players {
+		filterSet[player] = true
+	}
+	isFiltered := len(players) > 0
+
+	// Sums of plies until the averages are taken
+	byPlayer := make(map[string]*PhaseStats)
+	var lastGame int
+	var white, black string
+	var fens []string
+	flush := func() {
+		if len(fens) == 0 {
+			return
+		}
+		phases := db.gamePhases(fens)
+		for _, name := range []string{white, black} {
+			if isFiltered && !filterSet[name] {
+				continue
+			}
+			stats := byPlayer[name]
+			if stats == nil {
+				stats = &PhaseStats{Player: name}
+				byPlayer[name] = stats
+			}
+			stats.Games++
+			stats.Opening += float64(phases.Opening)
+			stats.Middlegame += float64(phases.Middlegame)
+			stats.Endgame += float64(phases.Endgame)
+			if phases.Endgame > 0 {
+				stats.EndgameGames++
+			}
+		}
+	}
+	for rows.Next() {
+		var id int
+		var w, b, fen string
+		if err := rows.Scan(&id, &w, &b, &fen); err != nil {
+			return nil, fmt.Errorf("failed to scan position: %w", err)
+		}
+		if id != lastGame {
+			flush()
+			lastGame, white, black, fens = id, w, b, nil
+		}
+		fens = append(fens, fen)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read positions: %w", err)
+	}
+	flush()
+
+	results := make([]PhaseStats, 0, len(byPlayer))
+	for _, stats := range byPlayer {
+		games := float64(stats.Games)
+		stats.Opening /= games
+		stats.Middlegame /= games
+		stats.Endgame /= games
+		results = append(results, *stats)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Games != results[j].Games {
+			return results[i].Games > results[j].Games
+		}
+		return results[i].Player < results[j].Player
+	})
+	return results, nil
+}
@@ -0,0 +1,64 @@
+package db
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/kyleboon/gochess/internal/logging"
+	"github.com/kyleboon/gochess/internal/pgn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetPhaseStats(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "gochess-phases-test-")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(tempDir) }()
+
+	database, err := NewWithLogger(tempDir+"/test.db", logging.Discard())
+	require.NoError(t, err)
+	defer func() { _ = database.Close() }()
+	ctx := context.Background()
+
+	games := []string{
+		// Black's back rank is down to three pieces after 7... Be6, in the
+		// Italian Game line of the classifier
+		`[Event "1"] [Site "?"] [Date "2024.01.01"] [White "Alice"] [Black "Bob"] [Result "1-0"] 1. e4 e5 2. Nf3 Nc6 3. Bc4 Bc5 4. d3 Nf6 5. Nc3 Qe7 6. Bg5 d6 7. O-O Be6 8. a3 1-0`,
+		`[Event "2"] [Site "?"] [Date "2024.01.02"] [White "Bob"] [Black "Alice"] [Result "0-1"] 1. e4 e5 2. Nf3 Nc6 0-1`,
+	}
+	pgnFile := tempDir + "/games.pgn"
+	require.NoError(t, os.WriteFile(pgnFile, []byte(strings.Join(games, "\n\n")), 0644))
+	_, errs := database.ImportPGN(ctx, pgnFile)
+	require.Empty(t, errs)
+
+	stats, err := database.GetPhaseStats(ctx, []string{"Alice"})
+	require.NoError(t, err)
+	require.Len(t, stats, 1)
+	assert.Equal(t, PhaseStats{Player: "Alice", Games: 2, Opening: 9, Middlegame: 0.5}, stats[0])
+
+	stats, err = database.GetPhaseStats(ctx, nil)
+	require.NoError(t, err)
+	assert.Len(t, stats, 2)
+}
+
+func TestGamePhasesFollowsBook(t *testing.T) {
+	database, err := NewWithLogger(t.TempDir()+"/test.db", logging.Discard())
+	require.NoError(t, err)
+	defer func() { _ = database.Close() }()
+
+	// The queens come off in the Alekhine Variation of the Exchange Ruy
+	// Lopez, ending the opening by the heuristic a ply before the line ends
+	pgnDB := &pgn.DB{}
+	require.Empty(t, pgnDB.Parse(`[Event "?"] 1. e4 e5 2. Nf3 Nc6 3. Bb5 a6 4. Bxc6 dxc6 5. d4 exd4 6. Qxd4 Qxd4 7. Nxd4 Bd6 8. Be3 *`))
+	game := pgnDB.Games[0]
+	require.NoError(t, pgnDB.ParseMoves(game))
+	var fens []string
+	for _, b := range mainLineBoards(game) {
+		fens = append(fens, b.Fen())
+	}
+
+	assert.Equal(t, pgn.Phases{Opening: 13, Middlegame: 2}, game.Phases())
+	assert.Equal(t, pgn.Phases{Opening: 14, Middlegame: 1}, database.gamePhases(fens))
+}
@@ -266,3 +266,99 @@ func TestGetPlayerStats_Sorting(t *testing.T) {
 	assert.Equal(t, "Alice", stats[0].Name, "Most active player should be first")
 	assert.Equal(t, 3, stats[0].Games, "Alice should have 3 games")
 }
+
+func TestRatingBand(t *testing.T) {
+	tests := []struct {
+		diff int
+		want string
+	}{
+		{0, "even"},
+		{49, "even"},
+		{-49, "even"},
+		{50, "+100"},
+		{-50, "-100"},
+		{149, "+100"},
+		{150, "+200"},
+		{-150, "-200"},
+		{-600, "-200"},
+		{900, "+200"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, ratingBand(tt.diff), "rating difference %d", tt.diff)
+	}
+}
+
+func TestGetPlayerStats_RatingBands(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "gochess-test-rating-bands-")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(tempDir) }()
+
+	db, err := NewWithLogger(tempDir+"/test.db", logging.Discard())
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+	ctx := context.Background()
+
+	pgnContent := `[Event "Game 1"]
+[Site "Test"]
+[Date "2024.01.01"]
+[White "Alice"]
+[Black "Bob"]
+[Result "0-1"]
+[WhiteElo "1500"]
+[BlackElo "1700"]
+
+1. e4 e5 0-1
+
+[Event "Game 2"]
+[Site "Test"]
+[Date "2024.01.02"]
+[White "Bob"]
+[Black "Alice"]
+[Result "1-0"]
+[WhiteElo "1720"]
+[BlackElo "1500"]
+
+1. e4 e5 1-0
+
+[Event "Game 3"]
+[Site "Test"]
+[Date "2024.01.03"]
+[White "Carol"]
+[Black "Alice"]
+[Result "1/2-1/2"]
+[WhiteElo "1510"]
+[BlackElo "1500"]
+
+1. e4 e5 1/2-1/2
+
+[Event "Game 4"]
+[Site "Test"]
+[Date "2024.01.04"]
+[White "Alice"]
+[Black "Dave"]
+[Result "1-0"]
+
+1. e4 e5 1-0
+`
+	pgnFile := tempDir + "/test.pgn"
+	require.NoError(t, os.WriteFile(pgnFile, []byte(pgnContent), 0644))
+	_, errs := db.ImportPGN(ctx, pgnFile)
+	require.Empty(t, errs)
+
+	stats, err := db.GetPlayerStatsFiltered(ctx, []string{"Alice"})
+	require.NoError(t, err)
+	require.Len(t, stats, 1)
+
+	// The unrated game is left out of the bands
+	bands := stats[0].RatingBands
+	require.Len(t, bands, 2)
+	assert.Equal(t, "even", bands[0].Band)
+	assert.Equal(t, 1, bands[0].Draws)
+	assert.Equal(t, 1, bands[0].BlackGames)
+	assert.Equal(t, "+200", bands[1].Band)
+	assert.Equal(t, 2, bands[1].Games)
+	assert.Equal(t, 2, bands[1].Losses)
+	assert.Equal(t, 1, bands[1].WhiteLosses)
+	assert.Equal(t, 1, bands[1].BlackLosses)
+	assert.Equal(t, 0.0, bands[1].WinRate)
+}
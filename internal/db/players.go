@@ -0,0 +1,274 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kyleboon/gochess/internal/pgn"
+)
+
+// Ways GetOpponentStats can group games.
+const (
+	ByOpponentTitle   = "title"
+	ByOpponentCountry = "country"
+	ByOpponentStatus  = "status"
+)
+
+// Groups of opponents that have no title, or whose profile is not known.
+const (
+	Untitled       = "untitled"
+	UnknownProfile = "unknown"
+)
+
+// PlayerProfile is what is known of a player from their profile on a
+// platform, fetched when their games are imported.
+type PlayerProfile struct {
+	Platform string `json:"platform"` // "chesscom"
+	Username string `json:"username"`
+	Title    string `json:"title,omitempty"`   // Title such as "GM", "" if untitled
+	Country  string `json:"country,omitempty"` // ISO 3166-1 alpha-2 code, as in "US"
+	Status   string `json:"status,omitempty"`  // Membership status, as in "premium" or "closed"
+}
+
+// OpponentStats are the results of players against one group of opponents,
+// those of one title, country or membership status.
+type OpponentStats struct {
+	Group  string  `json:"group"`
+	Games  int     `json:"games"`
+	Wins   int     `json:"wins"`
+	Losses int     `json:"losses"`
+	Draws  int     `json:"draws"`
+	Score  float64 `json:"score"` // Points scored out of 100 per game
+}
+
+// createPlayersTable creates the table of the profiles of the players met in
+// the games
+func (db *DB) createPlayersTable() error {
+	_, err := db.conn.Exec(`
+		CREATE TABLE IF NOT EXISTS players (
+			platform TEXT NOT NULL,
+			username TEXT NOT NULL COLLATE NOCASE,
+			title TEXT NOT NULL DEFAULT '',
+			country TEXT NOT NULL DEFAULT '',
+			status TEXT NOT NULL DEFAULT '',
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (platform, username)
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create players table: %w", err)
+	}
+	return nil
+}
+
+// SavePlayerProfile stores a player's profile, replacing the one stored.
+func (db *DB) SavePlayerProfile(ctx context.Context, profile PlayerProfile) error {
+	_, err := db.conn.ExecContext(ctx, `
+		INSERT INTO players (platform, username, title, country, status) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (platform, username) DO UPDATE SET
+			title = excluded.title,
+			country = excluded.country,
+			status = excluded.status,
+			updated_at = CURRENT_TIMESTAMP
+	`, profile.Platform, profile.Username, profile.Title, profile.Country, profile.Status)
+	if err != nil {
+		return fmt.Errorf("failed to save player profile: %w", err)
+	}
+	return nil
+}
+
+// GetPlayerProfile retrieves a player's stored profile, or nil if it was
+// never fetched.
+func (db *DB) GetPlayerProfile(ctx context.Context, platform, username string) (*PlayerProfile, error) {
+	profiles, err := db.queryPlayerProfiles(ctx, "WHERE platform = ? AND username = ?", platform, username)
+	if err != nil || len(profiles) == 0 {
+		return nil, err
+	}
+	return &profiles[0], nil
+}
+
+// queryPlayerProfiles retrieves the stored profiles matching a WHERE clause
+func (db *DB) queryPlayerProfiles(ctx context.Context, where string, args ...interface{}) ([]PlayerProfile, error) {
+	rows, err := db.conn.QueryContext(ctx, "SELECT platform, username, title, country, status FROM players "+where, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query player profiles: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var profiles []PlayerProfile
+	for rows.Next() {
+		var p PlayerProfile
+		if err := rows.Scan(&p.Platform, &p.Username, &p.Title, &p.Country, &p.Status); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		profiles = append(profiles, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+	return profiles, nil
+}
+
+// OpponentsWithoutProfile returns the opponents of the given players in
+// their Chess.com games whose profile was never fetched, sorted by name.
+func (db *DB) OpponentsWithoutProfile(ctx context.Context, players []string) ([]string, error) {
+	if len(players) == 0 {
+		return nil, nil
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(players)), ",")
+	query := fmt.Sprintf(`
+		SELECT DISTINCT opponent FROM (
+			SELECT black AS opponent FROM games
+			WHERE white IN (%[1]s) AND site = 'Chess.com' AND deleted_at IS NULL
+			UNION
+			SELECT white AS opponent FROM games
+			WHERE black IN (%[1]s) AND site = 'Chess.com' AND deleted_at IS NULL
+		)
+		WHERE opponent != '' AND opponent NOT IN (%[1]s)
+			AND NOT EXISTS (SELECT 1 FROM players WHERE platform = 'chesscom' AND username = opponent)
+		ORDER BY opponent
+	`, placeholders)
+	var args []interface{}
+	for range 3 {
+		for _, player := range players {
+			args = append(args, player)
+		}
+	}
+
+	rows, err := db.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query opponents: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var opponents []string
+	for rows.Next() {
+		var opponent string
+		if err := rows.Scan(&opponent); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		opponents = append(opponents, opponent)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+	return opponents, nil
+}
+
+// GetOpponentStats retrieves the results of the given players in their
+// Chess.com games grouped by the opponents' title, country or membership
+// status (ByOpponentTitle, ByOpponentCountry or ByOpponentStatus), most
+// played first. Opponents whose profile was not fetched are grouped as
+// UnknownProfile, and those without a title as Untitled. If timeClass is
+// not empty, only games of that time class are counted. Unfinished games
+// are left out.
+func (db *DB) GetOpponentStats(ctx context.Context, players []string, by, timeClass string) ([]OpponentStats, error) {
+	var column string
+	switch by {
+	case ByOpponentTitle:
+		column = "title"
+	case ByOpponentCountry:
+		column = "country"
+	case ByOpponentStatus:
+		column = "status"
+	default:
+		return nil, fmt.Errorf("unknown opponent grouping %q: use %s, %s or %s", by, ByOpponentTitle, ByOpponentCountry, ByOpponentStatus)
+	}
+	if len(players) == 0 {
+		return nil, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(players)), ",")
+	query := fmt.Sprintf(`
+		SELECT g.white, g.black, g.result, wp.%[1]s, bp.%[1]s
+		FROM games g
+		LEFT JOIN players wp ON wp.platform = 'chesscom' AND wp.username = g.white
+		LEFT JOIN players bp ON bp.platform = 'chesscom' AND bp.username = g.black
+		WHERE g.deleted_at IS NULL AND g.site = 'Chess.com'
+			AND (g.white IN (%[2]s) OR g.black IN (%[2]s))
+	`, column, placeholders)
+	var args []interface{}
+	for range 2 {
+		for _, player := range players {
+			args = append(args, player)
+		}
+	}
+	if timeClass != "" {
+		query += " AND g.time_class = ?"
+		args = append(args, timeClass)
+	}
+
+	rows, err := db.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query games: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	filterSet := make(map[string]bool)
+	for _, player := range players {
+		filterSet[player] = true
+	}
+
+	byGroup := make(map[string]*OpponentStats)
+	for rows.Next() {
+		var white, black, resultTag string
+		var whiteGroup, blackGroup *string
+		if err := rows.Scan(&white, &black, &resultTag, &whiteGroup, &blackGroup); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		result := pgn.ParseResult(resultTag)
+		if !result.Finished() {
+			continue
+		}
+
+		names := []string{white, black}
+		groups := []*string{whiteGroup, blackGroup}
+		for color, name := range names {
+			if !filterSet[name] {
+				continue
+			}
+			group := UnknownProfile
+			if g := groups[1-color]; g != nil {
+				group = *g
+				if group == "" {
+					group = UnknownProfile
+					if by == ByOpponentTitle {
+						group = Untitled
+					}
+				}
+			}
+			stats := byGroup[group]
+			if stats == nil {
+				stats = &OpponentStats{Group: group}
+				byGroup[group] = stats
+			}
+			stats.Games++
+			switch result.Score(color) {
+			case 1:
+				stats.Wins++
+			case 0:
+				stats.Losses++
+			default:
+				stats.Draws++
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	results := make([]OpponentStats, 0, len(byGroup))
+	for _, stats := range byGroup {
+		stats.Score = (float64(stats.Wins) + float64(stats.Draws)/2) / float64(stats.Games) * 100
+		results = append(results, *stats)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Games != results[j].Games {
+			return results[i].Games > results[j].Games
+		}
+		return results[i].Group < results[j].Group
+	})
+	return results, nil
+}
@@ -0,0 +1,100 @@
+package db
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const opponentsPGN = `[Event "Live Chess"]
+[Site "Chess.com"]
+[Date "2024.01.01"]
+[White "alice"]
+[Black "Magnus"]
+[Result "0-1"]
+
+1. e4 e5 0-1
+
+[Event "Live Chess"]
+[Site "Chess.com"]
+[Date "2024.01.02"]
+[White "Bob"]
+[Black "alice"]
+[Result "1/2-1/2"]
+
+1. d4 d5 1/2-1/2
+
+[Event "Live Chess"]
+[Site "Chess.com"]
+[Date "2024.01.03"]
+[White "alice"]
+[Black "Carol"]
+[Result "1-0"]
+
+1. c4 e5 1-0
+
+[Event "Casual"]
+[Site "https://lichess.org/abcdefgh"]
+[Date "2024.01.04"]
+[White "alice"]
+[Black "Dave"]
+[Result "1-0"]
+
+1. Nf3 d5 1-0
+`
+
+func TestPlayerProfiles(t *testing.T) {
+	database, err := NewInMemory()
+	require.NoError(t, err)
+	defer func() { _ = database.Close() }()
+	ctx := context.Background()
+
+	result := database.ImportPGNReader(ctx, "opponents.pgn", strings.NewReader(opponentsPGN), ImportOptions{})
+	require.Empty(t, result.Errors)
+	require.Equal(t, 4, result.Imported)
+
+	players := []string{"alice"}
+	missing, err := database.OpponentsWithoutProfile(ctx, players)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Bob", "Carol", "Magnus"}, missing, "only the Chess.com opponents")
+
+	profile, err := database.GetPlayerProfile(ctx, "chesscom", "magnus")
+	require.NoError(t, err)
+	assert.Nil(t, profile)
+
+	require.NoError(t, database.SavePlayerProfile(ctx, PlayerProfile{Platform: "chesscom", Username: "Magnus", Title: "GM", Country: "NO", Status: "premium"}))
+	require.NoError(t, database.SavePlayerProfile(ctx, PlayerProfile{Platform: "chesscom", Username: "Bob", Country: "US", Status: "basic"}))
+	require.NoError(t, database.SavePlayerProfile(ctx, PlayerProfile{Platform: "chesscom", Username: "bob", Country: "CA", Status: "basic"}))
+
+	profile, err = database.GetPlayerProfile(ctx, "chesscom", "magnus")
+	require.NoError(t, err)
+	require.NotNil(t, profile)
+	assert.Equal(t, "GM", profile.Title)
+	profile, err = database.GetPlayerProfile(ctx, "chesscom", "Bob")
+	require.NoError(t, err)
+	assert.Equal(t, "CA", profile.Country, "usernames are not case sensitive")
+
+	missing, err = database.OpponentsWithoutProfile(ctx, players)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Carol"}, missing)
+
+	byTitle, err := database.GetOpponentStats(ctx, players, ByOpponentTitle, "")
+	require.NoError(t, err)
+	assert.Equal(t, []OpponentStats{
+		{Group: "GM", Games: 1, Losses: 1, Score: 0},
+		{Group: UnknownProfile, Games: 1, Wins: 1, Score: 100},
+		{Group: Untitled, Games: 1, Draws: 1, Score: 50},
+	}, byTitle, "the Lichess game is left out")
+
+	byCountry, err := database.GetOpponentStats(ctx, players, ByOpponentCountry, "")
+	require.NoError(t, err)
+	require.Len(t, byCountry, 3)
+	assert.Equal(t, OpponentStats{Group: "CA", Games: 1, Draws: 1, Score: 50}, byCountry[0])
+	assert.Equal(t, OpponentStats{Group: "NO", Games: 1, Losses: 1, Score: 0}, byCountry[1])
+
+	_, err = database.GetOpponentStats(ctx, players, "rating", "")
+	assert.Error(t, err)
+}
@@ -0,0 +1,148 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// QueryResult holds the rows of an SQL query, each with a value per column.
+// Values are int64, float64, string, time.Time (for timestamp columns) or
+// nil.
+type QueryResult struct {
+	Columns []string
+	Rows    [][]interface{}
+}
+
+// Query runs a single SQL query against the database read-only, for
+// questions the built-in reports do not answer. Queries that would do more
+// than read tables, such as writes, PRAGMA or ATTACH, are refused, as is
+// more than one statement. The database is best opened ReadOnly for it, so
+// that SQLite itself refuses writes too.
+func (db *DB) Query(ctx context.Context, query string, args ...interface{}) (*QueryResult, error) {
+	if err := checkSingleStatement(query); err != nil {
+		return nil, err
+	}
+
+	// The authorizer holds for a connection, so the query gets one to
+	// itself, returned to the pool without it
+	conn, err := db.conn.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer func() { _ = conn.Close() }()
+	if err := setAuthorizer(conn, readOnlyAuthorizer); err != nil {
+		return nil, fmt.Errorf("failed to make connection read-only: %w", err)
+	}
+	defer func() { _ = setAuthorizer(conn, nil) }()
+
+	rows, err := conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run query: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read columns: %w", err)
+	}
+	result := &QueryResult{Columns: columns, Rows: [][]interface{}{}}
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		ptrs := make([]interface{}, len(columns))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		for i, v := range values {
+			if b, ok := v.([]byte); ok {
+				values[i] = string(b)
+			}
+		}
+		result.Rows = append(result.Rows, values)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rows: %w", err)
+	}
+	return result, nil
+}
+
+// sqliteRecursive is the authorizer action of a recursive common table
+// expression, which go-sqlite3 does not define.
+const sqliteRecursive = 33
+
+// readOnlyAuthorizer allows a statement only to select, read tables and
+// call functions. Anything else, such as a write, PRAGMA, ATTACH or a
+// transaction, fails to prepare.
+func readOnlyAuthorizer(action int, _, _, _ string) int {
+	switch action {
+	case sqlite3.SQLITE_SELECT, sqlite3.SQLITE_READ, sqlite3.SQLITE_FUNCTION, sqliteRecursive:
+		return sqlite3.SQLITE_OK
+	}
+	return sqlite3.SQLITE_DENY
+}
+
+// setAuthorizer sets the authorizer of an SQLite connection, or removes it
+// if authorizer is nil.
+func setAuthorizer(conn *sql.Conn, authorizer func(int, string, string, string) int) error {
+	return conn.Raw(func(driverConn interface{}) error {
+		sc, ok := driverConn.(*sqlite3.SQLiteConn)
+		if !ok {
+			return fmt.Errorf("unexpected driver connection %T", driverConn)
+		}
+		sc.RegisterAuthorizer(authorizer)
+		return nil
+	})
+}
+
+// checkSingleStatement returns an error if query holds more than one SQL
+// statement. The driver would run every statement but return the rows of
+// the last only. Semicolons in strings, quoted names and comments do not
+// end a statement.
+func checkSingleStatement(query string) error {
+	ended := false
+	for i := 0; i < len(query); i++ {
+		switch c := query[i]; {
+		case c == '\'' || c == '"' || c == '`' || c == '[':
+			if ended {
+				return fmt.Errorf("only one SQL statement can be run at a time")
+			}
+			closing := c
+			if c == '[' {
+				closing = ']'
+			}
+			// A quote is escaped by doubling it, which reads as two
+			// quoted strings in a row
+			end := strings.IndexByte(query[i+1:], closing)
+			if end < 0 {
+				return nil // unterminated; SQLite reports it
+			}
+			i += end + 1
+		case strings.HasPrefix(query[i:], "--"):
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				return nil
+			}
+			i += end
+		case strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				return nil
+			}
+			i += end + 3
+		case c == ';':
+			ended = true
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f':
+		default:
+			if ended {
+				return fmt.Errorf("only one SQL statement can be run at a time")
+			}
+		}
+	}
+	return nil
+}
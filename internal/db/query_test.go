@@ -0,0 +1,85 @@
+package db
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/kyleboon/gochess/internal/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuery(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "gochess-query-test-")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(tempDir) }()
+
+	database, err := NewWithLogger(tempDir+"/test.db", logging.Discard())
+	require.NoError(t, err)
+	defer func() { _ = database.Close() }()
+	ctx := context.Background()
+
+	pgnFile := tempDir + "/games.pgn"
+	require.NoError(t, os.WriteFile(pgnFile, []byte(`[Event "1"] [Site "?"] [Date "2024.01.01"] [White "Alice"] [Black "Bob"] [Result "1-0"] 1. e4 e5 1-0
+
+[Event "2"] [Site "?"] [Date "2024.01.02"] [White "Alice"] [Black "Carol"] [Result "0-1"] 1. d4 d5 0-1`), 0644))
+	_, errs := database.ImportPGN(ctx, pgnFile)
+	require.Empty(t, errs)
+
+	result, err := database.Query(ctx, "SELECT white, count(*) AS games, NULL AS none FROM games GROUP BY 1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"white", "games", "none"}, result.Columns)
+	assert.Equal(t, [][]interface{}{{"Alice", int64(2), nil}}, result.Rows)
+
+	result, err = database.Query(ctx, "SELECT id FROM games WHERE black = ?", "Carol")
+	require.NoError(t, err)
+	assert.Equal(t, [][]interface{}{{int64(2)}}, result.Rows)
+
+	// Writes are refused, and the database stays writable afterwards
+	_, err = database.Query(ctx, "DELETE FROM games")
+	assert.Error(t, err)
+	_, err = database.Query(ctx, "DROP TABLE games")
+	assert.Error(t, err)
+	count, err := database.GetGameCount(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	require.NoError(t, database.UpdateGamePGN(ctx, 1, "1. e4 e5 1-0"))
+
+	// Nor can a query get around the read-only check
+	for _, query := range []string{
+		"PRAGMA query_only = OFF; DELETE FROM games",
+		"SELECT 1; DELETE FROM games",
+		"PRAGMA query_only = OFF",
+		"PRAGMA table_info(games)",
+		"ATTACH DATABASE '" + tempDir + "/other.db' AS other",
+		"BEGIN",
+	} {
+		_, err = database.Query(ctx, query)
+		assert.Error(t, err, query)
+	}
+	_, err = os.Stat(tempDir + "/other.db")
+	assert.True(t, os.IsNotExist(err), "ATTACH must not create a database")
+	count, err = database.GetGameCount(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	// Semicolons that do not end a statement are fine, as are recursive
+	// queries
+	result, err = database.Query(ctx, "SELECT count(*) FROM games WHERE white <> 'a;b' /* ; */ -- ;\n;")
+	require.NoError(t, err)
+	assert.Equal(t, [][]interface{}{{int64(2)}}, result.Rows)
+	result, err = database.Query(ctx, "WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i + 1 FROM n WHERE i < 3) SELECT sum(i) FROM n")
+	require.NoError(t, err)
+	assert.Equal(t, [][]interface{}{{int64(6)}}, result.Rows)
+
+	// On a database opened read-only as well
+	readOnly, err := OpenWithLogger(tempDir+"/test.db", ReadOnly, logging.Discard())
+	require.NoError(t, err)
+	defer func() { _ = readOnly.Close() }()
+	result, err = readOnly.Query(ctx, "SELECT count(*) FROM games")
+	require.NoError(t, err)
+	assert.Equal(t, [][]interface{}{{int64(2)}}, result.Rows)
+	_, err = readOnly.Query(ctx, "PRAGMA query_only = OFF; DELETE FROM games")
+	assert.Error(t, err)
+}
@@ -0,0 +1,422 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/kyleboon/gochess/internal"
+	"github.com/kyleboon/gochess/internal/pgn"
+)
+
+// repertoireStartFEN is the position repertoires are exported from
+const repertoireStartFEN = "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
+
+// Repertoire is a named tree of opening moves, prepared for one color.
+type Repertoire struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Color string `json:"color"` // "white" or "black"
+	Moves int    `json:"moves"`
+}
+
+// RepertoireMove is a move of a repertoire, from the position of FEN.
+type RepertoireMove struct {
+	FEN  string // placement, side to move, castling and en passant fields
+	Move string // UCI notation, as stored with positions
+	SAN  string
+}
+
+// Deviation says who left a repertoire first in a game.
+type Deviation string
+
+const (
+	// DeviationNone means the game stayed in the repertoire until the
+	// repertoire, or the game, ended.
+	DeviationNone Deviation = ""
+	// DeviationPlayer means the player played a move the repertoire does
+	// not have.
+	DeviationPlayer Deviation = "player"
+	// DeviationOpponent means the opponent played a move the repertoire
+	// does not prepare for.
+	DeviationOpponent Deviation = "opponent"
+)
+
+// RepertoireCheck is how a game of a player compares to their repertoire.
+type RepertoireCheck struct {
+	GameID     int    `json:"game_id"`
+	White      string `json:"white"`
+	Black      string `json:"black"`
+	Date       string `json:"date"`
+	Result     string `json:"result"`
+	Repertoire string `json:"repertoire"`
+	// Plies is the number of plies the game followed the repertoire
+	Plies     int       `json:"plies"`
+	Deviation Deviation `json:"deviation,omitempty"`
+	// Played is the move that left the repertoire, and Expected the moves
+	// the repertoire has in that position, in SAN
+	Played   string   `json:"played,omitempty"`
+	Expected []string `json:"expected,omitempty"`
+}
+
+// createRepertoireTables creates the tables holding repertoires
+func (db *DB) createRepertoireTables() error {
+	_, err := db.conn.Exec(`
+		CREATE TABLE IF NOT EXISTS repertoires (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL UNIQUE,
+			color TEXT NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+		CREATE TABLE IF NOT EXISTS repertoire_moves (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			repertoire_id INTEGER NOT NULL,
+			fen TEXT NOT NULL,
+			move TEXT NOT NULL,
+			san TEXT NOT NULL,
+			UNIQUE (repertoire_id, fen, move),
+			FOREIGN KEY (repertoire_id) REFERENCES repertoires(id) ON DELETE CASCADE
+		);
+		CREATE INDEX IF NOT EXISTS idx_repertoire_moves_fen ON repertoire_moves(repertoire_id, fen);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create repertoire tables: %w", err)
+	}
+	return nil
+}
+
+// ImportRepertoire stores the moves of games, including all their
+// variations, as the repertoire name for color ("white" or "black"). A
+// repertoire of the same name is replaced. Returns the number of moves
+// stored; moves reached more than once are stored once.
+func (db *DB) ImportRepertoire(ctx context.Context, name, color string, games []*pgn.Game) (int, error) {
+	if color != "white" && color != "black" {
+		return 0, fmt.Errorf("invalid repertoire color %q (use white or black)", color)
+	}
+
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM repertoires WHERE name = ?", name); err != nil {
+		return 0, fmt.Errorf("failed to replace repertoire: %w", err)
+	}
+	res, err := tx.ExecContext(ctx, "INSERT INTO repertoires (name, color) VALUES (?, ?)", name, color)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create repertoire: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get repertoire ID: %w", err)
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT OR IGNORE INTO repertoire_moves (repertoire_id, fen, move, san) VALUES (?, ?, ?, ?)
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer func() { _ = stmt.Close() }()
+
+	count := 0
+	for _, game := range games {
+		for _, m := range repertoireMoves(game.Root) {
+			res, err := stmt.ExecContext(ctx, id, m.FEN, m.Move, m.SAN)
+			if err != nil {
+				return 0, fmt.Errorf("failed to insert repertoire move: %w", err)
+			}
+			if n, _ := res.RowsAffected(); n > 0 {
+				count++
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit repertoire: %w", err)
+	}
+	db.logger.Debug("repertoire imported", "name", name, "color", color, "moves", count)
+	return count, nil
+}
+
+// repertoireMoves returns the moves of the line starting at root and of all
+// its variations, depth first
+func repertoireMoves(root *pgn.Node) []RepertoireMove {
+	var moves []RepertoireMove
+	for node := root.Next; node != nil; node = node.Next {
+		if node.Move != internal.NullMove {
+			board := node.Parent.Board
+			moves = append(moves, RepertoireMove{
+				FEN:  positionKey(board.Fen()),
+				Move: formatMove(node.Move, board),
+				SAN:  node.Move.San(board),
+			})
+		}
+		// The moves of the main line come first, then its alternatives
+		for _, v := range node.Variations() {
+			moves = append(moves, repertoireMoves(v)...)
+		}
+	}
+	return moves
+}
+
+// positionKey returns the fields of a FEN that identify a position: piece
+// placement, side to move, castling rights and en passant square
+func positionKey(fen string) string {
+	fields := strings.Fields(fen)
+	if len(fields) > 4 {
+		fields = fields[:4]
+	}
+	return strings.Join(fields, " ")
+}
+
+// ListRepertoires returns all repertoires, by name.
+func (db *DB) ListRepertoires(ctx context.Context) ([]Repertoire, error) {
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT r.id, r.name, r.color, COUNT(m.id)
+		FROM repertoires r
+		LEFT JOIN repertoire_moves m ON m.repertoire_id = r.id
+		GROUP BY r.id
+		ORDER BY r.name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query repertoires: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var repertoires []Repertoire
+	for rows.Next() {
+		var r Repertoire
+		if err := rows.Scan(&r.ID, &r.Name, &r.Color, &r.Moves); err != nil {
+			return nil, fmt.Errorf("failed to scan repertoire: %w", err)
+		}
+		repertoires = append(repertoires, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating repertoires: %w", err)
+	}
+	return repertoires, nil
+}
+
+// GetRepertoire returns a repertoire by name.
+func (db *DB) GetRepertoire(ctx context.Context, name string) (*Repertoire, error) {
+	repertoires, err := db.ListRepertoires(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range repertoires {
+		if r.Name == name {
+			return &r, nil
+		}
+	}
+	return nil, fmt.Errorf("repertoire not found: %s", name)
+}
+
+// DeleteRepertoire deletes a repertoire by name.
+func (db *DB) DeleteRepertoire(ctx context.Context, name string) error {
+	res, err := db.conn.ExecContext(ctx, "DELETE FROM repertoires WHERE name = ?", name)
+	if err != nil {
+		return fmt.Errorf("failed to delete repertoire: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("repertoire not found: %s", name)
+	}
+	return nil
+}
+
+// GetRepertoireMoves returns the moves of a repertoire by position, each
+// position's moves in the order they were imported.
+func (db *DB) GetRepertoireMoves(ctx context.Context, repertoireID int) (map[string][]RepertoireMove, error) {
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT fen, move, san FROM repertoire_moves WHERE repertoire_id = ? ORDER BY id
+	`, repertoireID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query repertoire moves: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	moves := make(map[string][]RepertoireMove)
+	for rows.Next() {
+		var m RepertoireMove
+		if err := rows.Scan(&m.FEN, &m.Move, &m.SAN); err != nil {
+			return nil, fmt.Errorf("failed to scan repertoire move: %w", err)
+		}
+		moves[m.FEN] = append(moves[m.FEN], m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating repertoire moves: %w", err)
+	}
+	return moves, nil
+}
+
+// ExportRepertoire returns a repertoire as a game from the starting
+// position, its alternative moves as variations. A position reached again
+// by transposition is not expanded a second time.
+func (db *DB) ExportRepertoire(ctx context.Context, name string) (*pgn.Game, error) {
+	r, err := db.GetRepertoire(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	moves, err := db.GetRepertoireMoves(ctx, r.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	game, err := pgn.NewGame(map[string]string{"Event": r.Name, "Result": "*", "FEN": repertoireStartFEN})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create game: %w", err)
+	}
+	delete(game.Tags, "FEN")
+	if r.Color == "white" {
+		game.Tags["White"] = r.Name
+	} else {
+		game.Tags["Black"] = r.Name
+	}
+	buildRepertoireTree(game.Root, moves, make(map[string]bool))
+	return game, nil
+}
+
+// buildRepertoireTree adds the repertoire moves from the position of node
+// after it: the first as the next move, the others as variations of it
+func buildRepertoireTree(node *pgn.Node, moves map[string][]RepertoireMove, expanded map[string]bool) {
+	key := positionKey(node.Board.Fen())
+	if expanded[key] {
+		return
+	}
+	expanded[key] = true
+
+	for i, m := range moves[key] {
+		mv, err := node.Board.ParseMove(m.SAN)
+		if err != nil {
+			continue
+		}
+		var child *pgn.Node
+		if i == 0 || node.Next == nil {
+			child = node.Insert(mv)
+		} else {
+			child = node.Next.NewVariation().Insert(mv)
+		}
+		buildRepertoireTree(child, moves, expanded)
+	}
+}
+
+// CheckRepertoire compares the most recent games of player, up to limit,
+// with the repertoires for the color they played. With name set only that
+// repertoire is used; otherwise each game is compared with the repertoire
+// it follows longest. Games with no repertoire for the player's color are
+// left out.
+func (db *DB) CheckRepertoire(ctx context.Context, player, name string, limit int) ([]RepertoireCheck, error) {
+	repertoires, err := db.ListRepertoires(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byColor := make(map[string][]Repertoire)
+	moves := make(map[int]map[string][]RepertoireMove)
+	for _, r := range repertoires {
+		if name != "" && r.Name != name {
+			continue
+		}
+		byColor[r.Color] = append(byColor[r.Color], r)
+		if moves[r.ID], err = db.GetRepertoireMoves(ctx, r.ID); err != nil {
+			return nil, err
+		}
+	}
+	if name != "" && len(moves) == 0 {
+		return nil, fmt.Errorf("repertoire not found: %s", name)
+	}
+
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT id, white, black, date, result FROM games
+		WHERE (white = ? COLLATE NOCASE OR black = ? COLLATE NOCASE) AND deleted_at IS NULL
+		ORDER BY date DESC, id DESC
+		LIMIT ?
+	`, player, player, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query games: %w", err)
+	}
+	var games []RepertoireCheck
+	for rows.Next() {
+		var g RepertoireCheck
+		var date, result sql.NullString
+		if err := rows.Scan(&g.GameID, &g.White, &g.Black, &date, &result); err != nil {
+			_ = rows.Close()
+			return nil, fmt.Errorf("failed to scan game: %w", err)
+		}
+		g.Date, g.Result = date.String, result.String
+		games = append(games, g)
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating games: %w", err)
+	}
+
+	var checks []RepertoireCheck
+	for _, g := range games {
+		color := "black"
+		if strings.EqualFold(g.White, player) {
+			color = "white"
+		}
+		if len(byColor[color]) == 0 {
+			continue
+		}
+		positions, err := db.GetPositionsForGame(ctx, g.GameID)
+		if err != nil {
+			return nil, err
+		}
+
+		var best *RepertoireCheck
+		for _, r := range byColor[color] {
+			check := compareWithRepertoire(g, positions, moves[r.ID], color)
+			check.Repertoire = r.Name
+			if best == nil || check.Plies > best.Plies {
+				best = &check
+			}
+		}
+		checks = append(checks, *best)
+	}
+	return checks, nil
+}
+
+// compareWithRepertoire follows the positions of a game through the
+// repertoire moves, until a move is played that the repertoire does not have
+func compareWithRepertoire(check RepertoireCheck, positions []GamePosition, moves map[string][]RepertoireMove, color string) RepertoireCheck {
+	for _, pos := range positions {
+		if pos.NextMove == "" {
+			break
+		}
+		candidates := moves[positionKey(pos.FEN)]
+		if len(candidates) == 0 {
+			// The repertoire ends here
+			break
+		}
+		found := false
+		for _, m := range candidates {
+			if m.Move == pos.NextMove {
+				found = true
+				break
+			}
+		}
+		if found {
+			check.Plies++
+			continue
+		}
+
+		check.Deviation = DeviationOpponent
+		if fields := strings.Fields(pos.FEN); len(fields) > 1 && (fields[1] == "w") == (color == "white") {
+			check.Deviation = DeviationPlayer
+		}
+		check.Played = pos.NextMove
+		if board, err := internal.ParseFen(pos.FEN); err == nil {
+			if mv, err := board.ParseMove(pos.NextMove); err == nil {
+				check.Played = mv.San(board)
+			}
+		}
+		for _, m := range candidates {
+			check.Expected = append(check.Expected, m.SAN)
+		}
+		break
+	}
+	return check
+}
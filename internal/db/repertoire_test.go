@@ -0,0 +1,144 @@
+package db
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/kyleboon/gochess/internal/logging"
+	"github.com/kyleboon/gochess/internal/pgn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testRepertoirePGN = `[Event "White 1.e4"]
+
+1. e4 e5 (1... c5 2. Nf3 d6 3. d4) 2. Nf3 Nc6 3. Bb5 *
+`
+
+func parseRepertoire(t *testing.T, text string) []*pgn.Game {
+	t.Helper()
+	pgnDB := &pgn.DB{}
+	require.Empty(t, pgnDB.Parse(text))
+	for _, game := range pgnDB.Games {
+		require.NoError(t, pgnDB.ParseMoves(game))
+	}
+	return pgnDB.Games
+}
+
+func TestImportRepertoire(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "gochess-repertoire-test-")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(tempDir) }()
+
+	database, err := NewWithLogger(tempDir+"/test.db", logging.Discard())
+	require.NoError(t, err)
+	defer func() { _ = database.Close() }()
+	ctx := context.Background()
+
+	count, err := database.ImportRepertoire(ctx, "White 1.e4", "white", parseRepertoire(t, testRepertoirePGN))
+	require.NoError(t, err)
+	assert.Equal(t, 9, count)
+
+	t.Run("list", func(t *testing.T) {
+		repertoires, err := database.ListRepertoires(ctx)
+		require.NoError(t, err)
+		require.Len(t, repertoires, 1)
+		assert.Equal(t, "White 1.e4", repertoires[0].Name)
+		assert.Equal(t, "white", repertoires[0].Color)
+		assert.Equal(t, 9, repertoires[0].Moves)
+	})
+
+	t.Run("moves by position", func(t *testing.T) {
+		r, err := database.GetRepertoire(ctx, "White 1.e4")
+		require.NoError(t, err)
+		moves, err := database.GetRepertoireMoves(ctx, r.ID)
+		require.NoError(t, err)
+		replies := moves["rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3"]
+		require.Len(t, replies, 2)
+		assert.Equal(t, "e5", replies[0].SAN)
+		assert.Equal(t, "c7c5", replies[1].Move)
+	})
+
+	t.Run("export", func(t *testing.T) {
+		game, err := database.ExportRepertoire(ctx, "White 1.e4")
+		require.NoError(t, err)
+		assert.Contains(t, game.String(), "1. e4 e5 (1... c5 2. Nf3 d6 3. d4) 2. Nf3 Nc6 3. Bb5 *")
+	})
+
+	t.Run("import replaces", func(t *testing.T) {
+		count, err := database.ImportRepertoire(ctx, "White 1.e4", "white", parseRepertoire(t, "[Event \"White 1.e4\"]\n\n1. e4 *"))
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+		repertoires, err := database.ListRepertoires(ctx)
+		require.NoError(t, err)
+		require.Len(t, repertoires, 1)
+		assert.Equal(t, 1, repertoires[0].Moves)
+	})
+
+	t.Run("invalid color", func(t *testing.T) {
+		_, err := database.ImportRepertoire(ctx, "Both", "green", nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		require.NoError(t, database.DeleteRepertoire(ctx, "White 1.e4"))
+		assert.Error(t, database.DeleteRepertoire(ctx, "White 1.e4"))
+		_, err := database.GetRepertoire(ctx, "White 1.e4")
+		assert.Error(t, err)
+	})
+}
+
+func TestCheckRepertoire(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "gochess-repertoire-test-")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(tempDir) }()
+
+	database, err := NewWithLogger(tempDir+"/test.db", logging.Discard())
+	require.NoError(t, err)
+	defer func() { _ = database.Close() }()
+	ctx := context.Background()
+
+	_, err = database.ImportRepertoire(ctx, "White 1.e4", "white", parseRepertoire(t, testRepertoirePGN))
+	require.NoError(t, err)
+
+	games := []string{
+		// Alice follows the repertoire to its end
+		`[Event "1"] [Site "?"] [Date "2024.01.01"] [White "Alice"] [Black "Bob"] [Result "1-0"] 1. e4 e5 2. Nf3 Nc6 3. Bb5 a6 1-0`,
+		// Alice deviates with 2. Nc3
+		`[Event "2"] [Site "?"] [Date "2024.01.02"] [White "Alice"] [Black "Bob"] [Result "0-1"] 1. e4 c5 2. Nc3 Nc6 0-1`,
+		// The opponent deviates with 1... e6
+		`[Event "3"] [Site "?"] [Date "2024.01.03"] [White "Alice"] [Black "Carol"] [Result "1/2-1/2"] 1. e4 e6 2. d4 d5 1/2-1/2`,
+		// Alice has no repertoire for Black
+		`[Event "4"] [Site "?"] [Date "2024.01.04"] [White "Bob"] [Black "Alice"] [Result "1-0"] 1. d4 d5 1-0`,
+	}
+	pgnFile := tempDir + "/games.pgn"
+	require.NoError(t, os.WriteFile(pgnFile, []byte(strings.Join(games, "\n\n")), 0644))
+	count, errs := database.ImportPGN(ctx, pgnFile)
+	require.Empty(t, errs)
+	require.Equal(t, 4, count)
+
+	checks, err := database.CheckRepertoire(ctx, "alice", "", 10)
+	require.NoError(t, err)
+	require.Len(t, checks, 3)
+
+	// Most recent first
+	assert.Equal(t, "Carol", checks[0].Black)
+	assert.Equal(t, DeviationOpponent, checks[0].Deviation)
+	assert.Equal(t, 1, checks[0].Plies)
+	assert.Equal(t, "e6", checks[0].Played)
+	assert.Equal(t, []string{"e5", "c5"}, checks[0].Expected)
+
+	assert.Equal(t, DeviationPlayer, checks[1].Deviation)
+	assert.Equal(t, 2, checks[1].Plies)
+	assert.Equal(t, "Nc3", checks[1].Played)
+	assert.Equal(t, []string{"Nf3"}, checks[1].Expected)
+
+	assert.Equal(t, DeviationNone, checks[2].Deviation)
+	assert.Equal(t, 5, checks[2].Plies)
+	assert.Equal(t, "White 1.e4", checks[2].Repertoire)
+
+	_, err = database.CheckRepertoire(ctx, "alice", "Missing", 10)
+	assert.Error(t, err)
+}
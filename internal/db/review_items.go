@@ -0,0 +1,251 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kyleboon/gochess/internal/srs"
+)
+
+// Kinds of review items, by the trainer that schedules them.
+const (
+	ReviewGuessMove  = "guess-the-move"
+	ReviewRepertoire = "repertoire"
+)
+
+// reviewTimeFormat is how review times are stored, in UTC, so that they
+// compare as text in the order of time.
+const reviewTimeFormat = "2006-01-02 15:04:05"
+
+// ReviewItem is a position drilled with spaced repetition: the side to move
+// has to find one of the answers. Its card says when it is due.
+type ReviewItem struct {
+	ID       int      `json:"id"`
+	Kind     string   `json:"kind"`   // The trainer the item comes from, as in "guess-the-move" or "repertoire"
+	Source   string   `json:"source"` // Where the position was found, as in "game 12" or a repertoire name
+	FEN      string   `json:"fen"`
+	Answers  []string `json:"answers"` // Moves accepted, in SAN
+	srs.Card `json:"card"`
+	Reviewed string `json:"reviewed,omitempty"` // When the item was last reviewed
+}
+
+// ReviewSummary counts the review items of a kind.
+type ReviewSummary struct {
+	Kind  string    `json:"kind"`
+	Items int       `json:"items"`
+	Due   int       `json:"due"`  // Items due by the time asked about
+	Next  time.Time `json:"next"` // When the earliest item is due
+}
+
+// createReviewItemsTable creates the table of the items scheduled for
+// spaced repetition by the trainers
+func (db *DB) createReviewItemsTable() error {
+	_, err := db.conn.Exec(`
+		CREATE TABLE IF NOT EXISTS review_items (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			kind TEXT NOT NULL,
+			source TEXT NOT NULL DEFAULT '',
+			fen TEXT NOT NULL,
+			answers TEXT NOT NULL,
+			ease REAL NOT NULL,
+			interval_days INTEGER NOT NULL DEFAULT 0,
+			repetitions INTEGER NOT NULL DEFAULT 0,
+			due_at TEXT NOT NULL,
+			reviewed_at TEXT,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE(kind, source, fen)
+		);
+		CREATE INDEX IF NOT EXISTS idx_review_items_due ON review_items(due_at);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create review_items table: %w", err)
+	}
+	return nil
+}
+
+// AddReviewItem schedules a position for review, due at now. A position the
+// kind already has from the same source keeps its schedule and only gets
+// the new answers. It reports whether the item is new.
+func (db *DB) AddReviewItem(ctx context.Context, item ReviewItem, now time.Time) (bool, error) {
+	if item.Kind == "" || item.FEN == "" || len(item.Answers) == 0 {
+		return false, fmt.Errorf("review item needs a kind, a position and answers")
+	}
+	card := srs.New(now)
+	res, err := db.conn.ExecContext(ctx, `
+		INSERT INTO review_items (kind, source, fen, answers, ease, due_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(kind, source, fen) DO NOTHING
+	`, item.Kind, item.Source, item.FEN, strings.Join(item.Answers, " "), card.Ease, formatReviewTime(card.Due))
+	if err != nil {
+		return false, fmt.Errorf("failed to add review item: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n > 0 {
+		return true, nil
+	}
+	_, err = db.conn.ExecContext(ctx, `
+		UPDATE review_items SET answers = ? WHERE kind = ? AND source = ? AND fen = ?
+	`, strings.Join(item.Answers, " "), item.Kind, item.Source, item.FEN)
+	if err != nil {
+		return false, fmt.Errorf("failed to update review item: %w", err)
+	}
+	return false, nil
+}
+
+// reviewItemColumns are the columns of review_items read into a ReviewItem.
+const reviewItemColumns = `
+	id, kind, source, fen, answers, ease, interval_days, repetitions, due_at, COALESCE(reviewed_at, '')
+`
+
+// scanReviewItem scans a row of reviewItemColumns into a ReviewItem.
+func scanReviewItem(row interface{ Scan(...interface{}) error }) (*ReviewItem, error) {
+	var item ReviewItem
+	var answers, due string
+	err := row.Scan(&item.ID, &item.Kind, &item.Source, &item.FEN, &answers,
+		&item.Ease, &item.Interval, &item.Repetitions, &due, &item.Reviewed)
+	if err != nil {
+		return nil, err
+	}
+	item.Answers = strings.Fields(answers)
+	if item.Due, err = time.Parse(reviewTimeFormat, due); err != nil {
+		return nil, fmt.Errorf("invalid due time %q: %w", due, err)
+	}
+	return &item, nil
+}
+
+// DueReviewItems returns the review items due by time by, of one kind or of
+// all if kind is empty, the longest due first. A limit of 0 returns every
+// item.
+func (db *DB) DueReviewItems(ctx context.Context, kind string, by time.Time, limit int) ([]ReviewItem, error) {
+	query := "SELECT " + reviewItemColumns + `
+		FROM review_items
+		WHERE due_at <= ? AND (? = '' OR kind = ?)
+		ORDER BY due_at, id
+	`
+	args := []interface{}{formatReviewTime(by), kind, kind}
+	if limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, limit)
+	}
+	rows, err := db.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query review items: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var items []ReviewItem
+	for rows.Next() {
+		item, err := scanReviewItem(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan review item: %w", err)
+		}
+		items = append(items, *item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+	return items, nil
+}
+
+// RecordReview grades a review of an item at time now and schedules its
+// next review, returning the item as rescheduled.
+func (db *DB) RecordReview(ctx context.Context, id int, q srs.Quality, now time.Time) (*ReviewItem, error) {
+	item, err := scanReviewItem(db.conn.QueryRowContext(ctx, "SELECT "+reviewItemColumns+" FROM review_items WHERE id = ?", id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("review item not found: %d", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get review item: %w", err)
+	}
+
+	item.Card = item.Review(q, now)
+	item.Reviewed = formatReviewTime(now)
+	_, err = db.conn.ExecContext(ctx, `
+		UPDATE review_items
+		SET ease = ?, interval_days = ?, repetitions = ?, due_at = ?, reviewed_at = ?
+		WHERE id = ?
+	`, item.Ease, item.Interval, item.Repetitions, formatReviewTime(item.Due), item.Reviewed, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record review: %w", err)
+	}
+	return item, nil
+}
+
+// ReviewSummaries counts the review items of each kind, and those due by
+// time by.
+func (db *DB) ReviewSummaries(ctx context.Context, by time.Time) ([]ReviewSummary, error) {
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT kind, COUNT(*), SUM(due_at <= ?), MIN(due_at)
+		FROM review_items
+		GROUP BY kind
+		ORDER BY kind
+	`, formatReviewTime(by))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query review items: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var summaries []ReviewSummary
+	for rows.Next() {
+		var s ReviewSummary
+		var next string
+		if err := rows.Scan(&s.Kind, &s.Items, &s.Due, &next); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		if s.Next, err = time.Parse(reviewTimeFormat, next); err != nil {
+			return nil, fmt.Errorf("invalid due time %q: %w", next, err)
+		}
+		summaries = append(summaries, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+	return summaries, nil
+}
+
+// ScheduleRepertoire adds the positions of a repertoire where its side is to
+// move as review items, their repertoire moves as the answers. Positions
+// scheduled before keep their schedule. It returns the number of new items.
+func (db *DB) ScheduleRepertoire(ctx context.Context, name string, now time.Time) (int, error) {
+	r, err := db.GetRepertoire(ctx, name)
+	if err != nil {
+		return 0, err
+	}
+	moves, err := db.GetRepertoireMoves(ctx, r.ID)
+	if err != nil {
+		return 0, err
+	}
+	side := "w"
+	if r.Color == "black" {
+		side = "b"
+	}
+
+	added := 0
+	for key, ms := range moves {
+		if fields := strings.Fields(key); len(fields) < 2 || fields[1] != side {
+			continue
+		}
+		answers := make([]string, len(ms))
+		for i, m := range ms {
+			answers[i] = m.SAN
+		}
+		// The keys leave out the move counters a FEN needs
+		item := ReviewItem{Kind: ReviewRepertoire, Source: name, FEN: key + " 0 1", Answers: answers}
+		isNew, err := db.AddReviewItem(ctx, item, now)
+		if err != nil {
+			return added, err
+		}
+		if isNew {
+			added++
+		}
+	}
+	return added, nil
+}
+
+// formatReviewTime formats a time as review times are stored.
+func formatReviewTime(t time.Time) string {
+	return t.UTC().Format(reviewTimeFormat)
+}
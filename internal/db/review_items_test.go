@@ -0,0 +1,113 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kyleboon/gochess/internal/logging"
+	"github.com/kyleboon/gochess/internal/srs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const startFEN = "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
+
+func TestReviewItems(t *testing.T) {
+	database, err := NewWithLogger(t.TempDir()+"/test.db", logging.Discard())
+	require.NoError(t, err)
+	defer func() { _ = database.Close() }()
+	ctx := context.Background()
+	now := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	isNew, err := database.AddReviewItem(ctx, ReviewItem{Kind: ReviewGuessMove, Source: "game 1", FEN: startFEN, Answers: []string{"e4"}}, now)
+	require.NoError(t, err)
+	assert.True(t, isNew)
+	isNew, err = database.AddReviewItem(ctx, ReviewItem{Kind: ReviewGuessMove, Source: "game 2", FEN: startFEN, Answers: []string{"d4"}}, now.Add(time.Hour))
+	require.NoError(t, err)
+	assert.True(t, isNew)
+	_, err = database.AddReviewItem(ctx, ReviewItem{Kind: ReviewGuessMove, FEN: startFEN}, now)
+	assert.Error(t, err, "an item needs answers")
+
+	items, err := database.DueReviewItems(ctx, "", now, 0)
+	require.NoError(t, err)
+	require.Len(t, items, 1, "the second item is not due yet")
+	first := items[0]
+	assert.Equal(t, "game 1", first.Source)
+	assert.Equal(t, []string{"e4"}, first.Answers)
+	assert.Equal(t, srs.DefaultEase, first.Ease)
+	assert.True(t, first.Due.Equal(now))
+
+	// Adding the item again keeps its schedule and updates its answers
+	isNew, err = database.AddReviewItem(ctx, ReviewItem{Kind: ReviewGuessMove, Source: "game 1", FEN: startFEN, Answers: []string{"e4", "Nf3"}}, now.Add(48*time.Hour))
+	require.NoError(t, err)
+	assert.False(t, isNew)
+
+	item, err := database.RecordReview(ctx, first.ID, srs.Good, now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, item.Interval)
+	assert.Equal(t, 1, item.Repetitions)
+	assert.True(t, item.Due.Equal(now.Add(srs.Day)))
+	assert.NotEmpty(t, item.Reviewed)
+	_, err = database.RecordReview(ctx, 999, srs.Good, now)
+	assert.Error(t, err)
+
+	later := now.Add(2 * time.Hour)
+	items, err = database.DueReviewItems(ctx, ReviewGuessMove, later, 0)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, "game 2", items[0].Source)
+
+	items, err = database.DueReviewItems(ctx, "", now.Add(7*24*time.Hour), 1)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, "game 2", items[0].Source, "longest due first")
+
+	items, err = database.DueReviewItems(ctx, "", now.Add(7*24*time.Hour), 0)
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	assert.Equal(t, []string{"e4", "Nf3"}, items[1].Answers)
+
+	summaries, err := database.ReviewSummaries(ctx, later)
+	require.NoError(t, err)
+	require.Len(t, summaries, 1)
+	assert.Equal(t, ReviewGuessMove, summaries[0].Kind)
+	assert.Equal(t, 2, summaries[0].Items)
+	assert.Equal(t, 1, summaries[0].Due)
+	assert.True(t, summaries[0].Next.Equal(now.Add(time.Hour)))
+}
+
+func TestScheduleRepertoire(t *testing.T) {
+	database, err := NewWithLogger(t.TempDir()+"/test.db", logging.Discard())
+	require.NoError(t, err)
+	defer func() { _ = database.Close() }()
+	ctx := context.Background()
+	now := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	_, err = database.ImportRepertoire(ctx, "White 1.e4", "white", parseRepertoire(t, testRepertoirePGN))
+	require.NoError(t, err)
+
+	// The positions with White to move: the start, after 1...e5, 1...c5,
+	// 2...d6 and 2...Nc6
+	added, err := database.ScheduleRepertoire(ctx, "White 1.e4", now)
+	require.NoError(t, err)
+	assert.Equal(t, 5, added)
+
+	items, err := database.DueReviewItems(ctx, ReviewRepertoire, now, 0)
+	require.NoError(t, err)
+	require.Len(t, items, 5)
+	answers := make(map[string][]string)
+	for _, item := range items {
+		assert.Equal(t, "White 1.e4", item.Source)
+		answers[item.FEN] = item.Answers
+	}
+	assert.Equal(t, []string{"e4"}, answers[startFEN])
+
+	// Scheduling again adds nothing
+	added, err = database.ScheduleRepertoire(ctx, "White 1.e4", now)
+	require.NoError(t, err)
+	assert.Equal(t, 0, added)
+
+	_, err = database.ScheduleRepertoire(ctx, "Missing", now)
+	assert.Error(t, err)
+}
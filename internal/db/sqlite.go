@@ -4,15 +4,18 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
 
 	_ "github.com/mattn/go-sqlite3"
 
+	"github.com/kyleboon/gochess/internal"
 	"github.com/kyleboon/gochess/internal/eco"
 	"github.com/kyleboon/gochess/internal/logging"
 	"github.com/kyleboon/gochess/internal/pgn"
@@ -25,6 +28,10 @@ type DB struct {
 	ecoDB  *eco.Database
 }
 
+// InMemory is the database path of a database kept in memory, which lasts
+// until it is closed.
+const InMemory = ":memory:"
+
 // New creates a new SQLite database connection
 func New(dbPath string) (*DB, error) {
 	return NewWithLogger(dbPath, logging.Default())
@@ -32,12 +39,16 @@ func New(dbPath string) (*DB, error) {
 
 // NewWithLogger creates a new SQLite database connection with a custom logger
 func NewWithLogger(dbPath string, logger *slog.Logger) (*DB, error) {
+	logger = logger.With("component", "db")
 	logger.Debug("opening database", "path", dbPath)
 
 	// Ensure directory exists
-	if err := os.MkdirAll(filepath.Dir(dbPath), 0755); err != nil {
-		logger.Error("failed to create database directory", "path", dbPath, "error", err)
-		return nil, fmt.Errorf("failed to create directory: %w", err)
+	inMemory := IsInMemory(dbPath)
+	if !inMemory {
+		if err := os.MkdirAll(filepath.Dir(dbPath), 0755); err != nil {
+			logger.Error("failed to create database directory", "path", dbPath, "error", err)
+			return nil, fmt.Errorf("failed to create directory: %w", err)
+		}
 	}
 
 	conn, err := sql.Open("sqlite3", dbPath)
@@ -45,6 +56,14 @@ func NewWithLogger(dbPath string, logger *slog.Logger) (*DB, error) {
 		logger.Error("failed to open database connection", "path", dbPath, "error", err)
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
+	if inMemory {
+		// Each connection to :memory: opens a database of its own, so all
+		// use the one connection, kept open for the life of the database
+		conn.SetMaxOpenConns(1)
+		conn.SetMaxIdleConns(1)
+		conn.SetConnMaxLifetime(0)
+		conn.SetConnMaxIdleTime(0)
+	}
 
 	// Initialize ECO database
 	ecoDB, err := eco.NewDatabaseWithLogger(logger)
@@ -70,11 +89,105 @@ func NewWithLogger(dbPath string, logger *slog.Logger) (*DB, error) {
 	return db, nil
 }
 
+// OpenMode is how Open opens a database.
+type OpenMode int
+
+const (
+	// ReadWrite opens a database for reading and writing, creating it and
+	// updating its schema as needed, like New.
+	ReadWrite OpenMode = iota
+	// ReadOnly opens an existing database in SQLite's read-only mode, which
+	// neither takes write locks nor changes the schema, so it can be read
+	// while another process, such as the sync daemon, writes to it.
+	ReadOnly
+)
+
+// readOnlyBusyTimeout is how long, in milliseconds, a read-only database
+// waits for a writer to release its lock before failing a query
+const readOnlyBusyTimeout = 5000
+
+// Open opens the database at dbPath in the given mode.
+func Open(dbPath string, mode OpenMode) (*DB, error) {
+	return OpenWithLogger(dbPath, mode, logging.Default())
+}
+
+// OpenWithLogger opens the database at dbPath in the given mode, with a
+// custom logger.
+func OpenWithLogger(dbPath string, mode OpenMode, logger *slog.Logger) (*DB, error) {
+	if mode != ReadOnly {
+		return NewWithLogger(dbPath, logger)
+	}
+
+	logger = logger.With("component", "db")
+	logger.Debug("opening database read-only", "path", dbPath)
+	if IsInMemory(dbPath) {
+		return nil, fmt.Errorf("an in-memory database cannot be opened read-only")
+	}
+	if _, err := os.Stat(dbPath); err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("database %s does not exist", dbPath)
+		}
+		return nil, fmt.Errorf("failed to access database: %w", err)
+	}
+
+	// The path is escaped as a file URI, so SQLite reads the mode from its
+	// query rather than a file name with "?mode=ro" in it
+	escaped := strings.NewReplacer("%", "%25", "?", "%3f", "#", "%23").Replace(dbPath)
+	dsn := fmt.Sprintf("file:%s?mode=ro&_busy_timeout=%d", escaped, readOnlyBusyTimeout)
+	conn, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		logger.Error("failed to open database connection", "path", dbPath, "error", err)
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	if err := conn.Ping(); err != nil {
+		_ = conn.Close()
+		logger.Error("failed to open database connection", "path", dbPath, "error", err)
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	ecoDB, err := eco.NewDatabaseWithLogger(logger)
+	if err != nil {
+		_ = conn.Close()
+		logger.Error("failed to initialize ECO database", "error", err)
+		return nil, fmt.Errorf("failed to initialize ECO database: %w", err)
+	}
+
+	logger.Debug("database opened read-only", "path", dbPath)
+	return &DB{conn: conn, logger: logger, ecoDB: ecoDB}, nil
+}
+
+// NewInMemory creates a database kept in memory rather than in a file, for
+// tests and one-off analysis. It is discarded when closed.
+func NewInMemory() (*DB, error) {
+	return New(InMemory)
+}
+
+// IsInMemory reports whether a database path, or SQLite URI, names an
+// in-memory database rather than a file.
+func IsInMemory(dbPath string) bool {
+	if dbPath == InMemory {
+		return true
+	}
+	if !strings.HasPrefix(dbPath, "file:") {
+		return false
+	}
+	name, query, _ := strings.Cut(strings.TrimPrefix(dbPath, "file:"), "?")
+	return name == InMemory || slices.Contains(strings.Split(query, "&"), "mode=memory")
+}
+
 // Close closes the database connection
 func (db *DB) Close() error {
 	return db.conn.Close()
 }
 
+// Ping checks that the database can still be reached.
+func (db *DB) Ping(ctx context.Context) error {
+	if err := db.conn.PingContext(ctx); err != nil {
+		return fmt.Errorf("failed to ping database: %w", err)
+	}
+	return nil
+}
+
 // createTables creates the necessary tables if they don't exist
 func (db *DB) createTables() error {
 	// Enable foreign keys
@@ -123,6 +236,28 @@ func (db *DB) createTables() error {
 		return fmt.Errorf("failed to add opening_name column: %w", err)
 	}
 
+	// The type of endgame reached, "" for none; NULL until classified
+	err = db.addColumnIfNotExists("games", "endgame TEXT")
+	if err != nil {
+		return fmt.Errorf("failed to add endgame column: %w", err)
+	}
+
+	// The time class, one of TimeClasses or "" if unknown; NULL until
+	// classified
+	err = db.addColumnIfNotExists("games", "time_class TEXT")
+	if err != nil {
+		return fmt.Errorf("failed to add time_class column: %w", err)
+	}
+	if err := db.classifyTimeClasses(context.Background()); err != nil {
+		return err
+	}
+
+	// When the game was moved to the trash, NULL for the games in use
+	err = db.addColumnIfNotExists("games", "deleted_at TIMESTAMP")
+	if err != nil {
+		return fmt.Errorf("failed to add deleted_at column: %w", err)
+	}
+
 	// Create tags table for additional metadata
 	_, err = db.conn.Exec(`
 		CREATE TABLE IF NOT EXISTS tags (
@@ -171,12 +306,45 @@ func (db *DB) createTables() error {
 		CREATE INDEX IF NOT EXISTS idx_tags ON tags(tag_name, tag_value);
 		CREATE UNIQUE INDEX IF NOT EXISTS idx_game_hash ON games(game_hash);
 		CREATE INDEX IF NOT EXISTS idx_games_eco ON games(eco_code);
+		CREATE INDEX IF NOT EXISTS idx_games_endgame ON games(endgame);
+		CREATE INDEX IF NOT EXISTS idx_games_time_class ON games(time_class);
 		CREATE INDEX IF NOT EXISTS idx_positions_fen ON positions(fen);
 		CREATE INDEX IF NOT EXISTS idx_positions_game_id ON positions(game_id);
 		CREATE INDEX IF NOT EXISTS idx_positions_eco ON positions(eco_code);
 	`)
+	if err != nil {
+		return err
+	}
+
+	if err := db.createRepertoireTables(); err != nil {
+		return err
+	}
+
+	if err := db.createNotesTable(); err != nil {
+		return err
+	}
 
-	return err
+	if err := db.createImportedFilesTable(); err != nil {
+		return err
+	}
+
+	if err := db.createPlayersTable(); err != nil {
+		return err
+	}
+
+	if err := db.createFidePlayersTable(); err != nil {
+		return err
+	}
+
+	if err := db.createTrainingScoresTable(); err != nil {
+		return err
+	}
+
+	if err := db.createReviewItemsTable(); err != nil {
+		return err
+	}
+
+	return db.createAnalysisTables()
 }
 
 // addColumnIfNotExists adds a column to a table if it doesn't already exist
@@ -249,9 +417,31 @@ func processParseErrors(parseErrors []error, pgnData *PGNData) []error {
 	return allErrors
 }
 
+// sevenTagRoster lists the tags the PGN standard requires of every game,
+// with the value each takes when it is unknown.
+var sevenTagRoster = []struct{ name, placeholder string }{
+	{"Event", "?"},
+	{"Site", "?"},
+	{"Date", "????.??.??"},
+	{"Round", "?"},
+	{"White", "?"},
+	{"Black", "?"},
+	{"Result", "*"},
+}
+
+// fillTagPlaceholders gives the tags of the Seven Tag Roster a game lacks, or
+// has empty, their placeholder values, so games from casual sources can be
+// imported.
+func fillTagPlaceholders(game *pgn.Game) {
+	for _, tag := range sevenTagRoster {
+		if game.Tags[tag.name] == "" {
+			game.Tags[tag.name] = tag.placeholder
+		}
+	}
+}
+
 // validateGameTags checks if all required tags are present in a game
-func validateGameTags(game *pgn.Game) error {
-	requiredTags := []string{"Event", "Site", "Date", "White", "Black", "Result"}
+func validateGameTags(game *pgn.Game, requiredTags []string) error {
 	missingTags := []string{}
 
 	for _, tag := range requiredTags {
@@ -267,25 +457,28 @@ func validateGameTags(game *pgn.Game) error {
 	return nil
 }
 
-// checkDuplicateGame checks if a game with the given hash already exists in the database
-func checkDuplicateGame(tx *sql.Tx, gameHash string) (bool, error) {
-	var existingID int
-	err := tx.QueryRow("SELECT id FROM games WHERE game_hash = ?", gameHash).Scan(&existingID)
+// checkDuplicateGame checks if a game with the given hash already exists in the database,
+// within a transaction or, for a dry run, the database itself. It also reports whether
+// the game is in the trash, where it stays a duplicate until purged.
+func checkDuplicateGame(q interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}, gameHash string) (duplicate, trashed bool, err error) {
+	err = q.QueryRow("SELECT deleted_at IS NOT NULL FROM games WHERE game_hash = ?", gameHash).Scan(&trashed)
 	switch err {
 	case nil:
 		// Game exists
-		return true, nil
+		return true, trashed, nil
 	case sql.ErrNoRows:
 		// Game doesn't exist
-		return false, nil
+		return false, false, nil
 	default:
 		// Unexpected error
-		return false, err
+		return false, false, err
 	}
 }
 
 // insertGameRecord inserts a game and its tags into the database and returns the game ID
-func insertGameRecord(ctx context.Context, tx *sql.Tx, stmtGame, stmtTag *sql.Stmt, game *pgn.Game, gameText, gameHash, ecoCode, openingName string) (int64, error) {
+func insertGameRecord(ctx context.Context, tx *sql.Tx, stmtGame, stmtTag *sql.Stmt, game *pgn.Game, gameText, gameHash, ecoCode, openingName, endgame string) (int64, error) {
 	// Parse ELO ratings
 	whiteElo, _ := strconv.Atoi(game.Tags["WhiteElo"])
 	blackElo, _ := strconv.Atoi(game.Tags["BlackElo"])
@@ -295,7 +488,7 @@ func insertGameRecord(ctx context.Context, tx *sql.Tx, stmtGame, stmtTag *sql.St
 		game.Tags["Event"], game.Tags["Site"], game.Tags["Date"], game.Tags["Round"],
 		game.Tags["White"], game.Tags["Black"], game.Tags["Result"],
 		whiteElo, blackElo, game.Tags["TimeControl"],
-		gameText, gameHash, ecoCode, openingName,
+		gameText, gameHash, ecoCode, openingName, endgame, gameTimeClass(game.Tags),
 	)
 	if err != nil {
 		return 0, fmt.Errorf("error inserting game: %w", err)
@@ -329,11 +522,85 @@ func insertPositions(ctx context.Context, tx *sql.Tx, stmtPosition *sql.Stmt, ga
 
 // ImportPGN imports games from a PGN file into the database
 func (db *DB) ImportPGN(ctx context.Context, filePath string) (int, []error) {
-	db.logger.Info("starting PGN import", "file", filePath)
-	allErrors := make([]error, 0)
+	return db.ImportPGNWithProgress(ctx, filePath, nil)
+}
+
+// ImportPGNWithProgress imports games from a PGN file into the database,
+// calling progress, if not nil, with the number of games processed so far
+// and the number of games in the file
+func (db *DB) ImportPGNWithProgress(ctx context.Context, filePath string, progress func(done, total int)) (int, []error) {
+	result := db.ImportPGNWithOptions(ctx, filePath, ImportOptions{Progress: progress})
+	return result.Imported, result.Errors
+}
+
+// ImportOptions changes how games are imported.
+type ImportOptions struct {
+	// DryRun parses, validates and deduplicates the games without storing
+	// them, to report what an import would do.
+	DryRun bool
+	// RequiredTags are the tags a game must have, not empty, to be
+	// imported. Games lacking any of the Seven Tag Roster otherwise get the
+	// placeholders of the PGN standard, such as "?" for an unknown player.
+	RequiredTags []string
+	// Workers is the number of games whose moves are parsed at once; 0
+	// uses a worker for each CPU.
+	Workers int
+	// Progress, if not nil, is called with the number of games processed so
+	// far and the number of games to import.
+	Progress func(done, total int)
+	// Tags are stored with each game imported that does not have them in
+	// its PGN, as the SourceAccountTag of games downloaded for an account.
+	// They are searchable like the game's own tags but left out of its PGN.
+	Tags map[string]string
+}
+
+// SourceAccountTag is the tag of games downloaded from Chess.com or
+// Lichess that records the account they were downloaded for, as in
+// "chesscom:alice".
+const SourceAccountTag = "SourceAccount"
+
+// SourceAccountTags returns the ImportOptions tags of games downloaded for
+// username on platform ("chesscom" or "lichess").
+func SourceAccountTags(platform, username string) map[string]string {
+	return map[string]string{SourceAccountTag: platform + ":" + username}
+}
+
+// ImportResult reports what an import did, or would do in a dry run.
+type ImportResult struct {
+	Imported   int     // games stored
+	Duplicates int     // games skipped as already in the database or earlier in the PGN
+	Trashed    int     // of the duplicates, games in the trash, restored with RestoreGame
+	Errors     []error // parse errors and games rejected, as *PGNImportError
+}
+
+// ImportPGNWithOptions imports games from a PGN file into the database.
+func (db *DB) ImportPGNWithOptions(ctx context.Context, filePath string, opts ImportOptions) *ImportResult {
+	db.logger.Info("starting PGN import", "file", filePath, "dryRun", opts.DryRun)
 
 	// Parse PGN file using our adapter that handles different PGN formats and preserves the move text
 	pgnData, parseErrors := ParsePGNFileWithMoves(filePath)
+	return db.importPGNData(ctx, filePath, pgnData, parseErrors, opts)
+}
+
+// ImportPGNReader imports games read from r, such as stdin or a download,
+// into the database like ImportPGNWithOptions. name stands for the source
+// in errors and logs.
+func (db *DB) ImportPGNReader(ctx context.Context, name string, r io.Reader, opts ImportOptions) *ImportResult {
+	db.logger.Info("starting PGN import", "file", name, "dryRun", opts.DryRun)
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return &ImportResult{Errors: []error{&PGNImportError{OriginalError: fmt.Errorf("failed to read PGN from %s: %w", name, err)}}}
+	}
+	pgnData, parseErrors := ParsePGNWithMoves(string(data))
+	return db.importPGNData(ctx, name, pgnData, parseErrors, opts)
+}
+
+// importPGNData stores the games parsed from a PGN file, or another source
+// named filePath
+func (db *DB) importPGNData(ctx context.Context, filePath string, pgnData *PGNData, parseErrors []error, opts ImportOptions) *ImportResult {
+	progress := opts.Progress
+	result := &ImportResult{}
+	allErrors := make([]error, 0)
 	db.logger.Debug("PGN file parsed", "file", filePath, "parseErrors", len(parseErrors))
 
 	// Process PGN parsing errors
@@ -345,11 +612,13 @@ func (db *DB) ImportPGN(ctx context.Context, filePath string) (int, []error) {
 	if pgnData.PgnDB == nil || len(pgnData.PgnDB.Games) == 0 {
 		db.logger.Warn("no games found in PGN file", "file", filePath, "errors", len(allErrors))
 		if len(allErrors) > 0 { // If there were parse errors, return them
-			return 0, allErrors
+			result.Errors = allErrors
+			return result
 		}
 		// If no parse errors and no games, then it's genuinely "no games found"
 		noGamesErr := fmt.Errorf("no games found in PGN file: %s", filePath)
-		return 0, []error{&PGNImportError{OriginalError: noGamesErr, PGNText: ""}}
+		result.Errors = []error{&PGNImportError{OriginalError: noGamesErr, PGNText: ""}}
+		return result
 	}
 
 	// Get parsed games and their complete text
@@ -357,61 +626,37 @@ func (db *DB) ImportPGN(ctx context.Context, filePath string) (int, []error) {
 	gameTexts := pgnData.GameTexts
 	db.logger.Debug("games parsed successfully", "file", filePath, "totalGames", len(pgnDB.Games))
 
-	// Begin transaction
-	tx, err := db.conn.BeginTx(ctx, nil)
-	if err != nil {
-		db.logger.Error("failed to begin transaction", "error", err)
-		allErrors = append(allErrors, &PGNImportError{OriginalError: fmt.Errorf("failed to begin transaction: %w", err), PGNText: ""})
-		return 0, allErrors
-	}
-	db.logger.Debug("transaction started")
-	defer func() {
-		if err := recover(); err != nil {
-			_ = tx.Rollback()
-			panic(err)
+	// A dry run only reads, so it checks for duplicates outside of a
+	// transaction and works on a database opened read-only
+	var itx *importTx
+	var lookup interface {
+		QueryRow(query string, args ...interface{}) *sql.Row
+	} = db.conn
+	if !opts.DryRun {
+		var err error
+		if itx, err = db.beginImport(ctx); err != nil {
+			allErrors = append(allErrors, &PGNImportError{OriginalError: err, PGNText: ""})
+			result.Errors = allErrors
+			return result
 		}
-	}()
-
-	// Prepare statements
-	stmtGame, err := tx.PrepareContext(ctx, `
-		INSERT INTO games (
-			event, site, date, round, white, black, result,
-			white_elo, black_elo, time_control, pgn_text, game_hash, eco_code, opening_name
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`)
-	if err != nil {
-		_ = tx.Rollback()
-		allErrors = append(allErrors, &PGNImportError{OriginalError: fmt.Errorf("failed to prepare game statement: %w", err), PGNText: ""})
-		return 0, allErrors
-	}
-	defer func() { _ = stmtGame.Close() }()
-
-	stmtTag, err := tx.PrepareContext(ctx, `
-		INSERT INTO tags (game_id, tag_name, tag_value)
-		VALUES (?, ?, ?)
-	`)
-	if err != nil {
-		_ = tx.Rollback()
-		allErrors = append(allErrors, &PGNImportError{OriginalError: fmt.Errorf("failed to prepare tag statement: %w", err), PGNText: ""})
-		return 0, allErrors
-	}
-	defer func() { _ = stmtTag.Close() }()
-
-	stmtPosition, err := tx.PrepareContext(ctx, `
-		INSERT INTO positions (game_id, move_number, fen, next_move, evaluation, eco_code, opening_name)
-		VALUES (?, ?, ?, ?, ?, ?, ?)
-	`)
-	if err != nil {
-		_ = tx.Rollback()
-		allErrors = append(allErrors, &PGNImportError{OriginalError: fmt.Errorf("failed to prepare position statement: %w", err), PGNText: ""})
-		return 0, allErrors
+		defer itx.close()
+		defer func() {
+			if err := recover(); err != nil {
+				_ = itx.tx.Rollback()
+				panic(err)
+			}
+		}()
+		lookup = itx.tx
 	}
-	defer func() { _ = stmtPosition.Close() }()
 
 	// Count of successfully imported games
 	importedCount := 0
 
-	// Process each game
+	// Games are checked and deduplicated in order first, so the work of
+	// parsing their moves is only done for new games. Duplicates within the
+	// PGN are found by their hashes, as the games are not stored yet.
+	var newGames []importCandidate
+	seen := make(map[string]bool)
 	for i, game := range pgnDB.Games {
 		var currentGameText string
 		if i < len(gameTexts) {
@@ -423,10 +668,11 @@ func (db *DB) ImportPGN(ctx context.Context, filePath string) (int, []error) {
 		}
 
 		// Validate required tags
-		if err := validateGameTags(game); err != nil {
+		if err := validateGameTags(game, opts.RequiredTags); err != nil {
 			allErrors = append(allErrors, &PGNImportError{OriginalError: fmt.Errorf("game %d: %w", i+1, err), PGNText: currentGameText})
 			continue
 		}
+		fillTagPlaceholders(game)
 
 		// Ensure FEN tag exists - add standard starting position if missing
 		if _, hasFen := game.Tags["FEN"]; !hasFen {
@@ -438,81 +684,78 @@ func (db *DB) ImportPGN(ctx context.Context, filePath string) (int, []error) {
 		gameHash := CalculateGameHash(game, moveText)
 
 		// Check if game already exists
-		isDuplicate, err := checkDuplicateGame(tx, gameHash)
+		isDuplicate, trashed, err := checkDuplicateGame(lookup, gameHash)
 		if err != nil {
 			dbErr := fmt.Errorf("error checking duplicate for game %d (event: %s): %w", i+1, game.Tags["Event"], err)
 			allErrors = append(allErrors, &PGNImportError{OriginalError: dbErr, PGNText: currentGameText})
 			continue
 		}
-		if isDuplicate {
+		if isDuplicate || seen[gameHash] {
 			// Game already exists, skip
+			result.Duplicates++
+			if trashed {
+				result.Trashed++
+			}
 			continue
 		}
-
-		// Parse moves for ECO classification and position extraction
-		var ecoCode, openingName string
-		if err := pgnDB.ParseMoves(game); err != nil {
-			db.logger.Warn("failed to parse moves for game",
-				"event", game.Tags["Event"], "error", err)
-			// Don't fail the import if move parsing fails
-		} else {
-			// Classify opening using ECO database
-			if game.Root != nil && game.Root.Next != nil {
-				// Extract SAN moves from the game tree
-				moveStrs := extractMoveStrings(game)
-				if len(moveStrs) > 0 {
-					ecoCode, openingName, _ = db.ecoDB.Classify(moveStrs)
-					if ecoCode != "" {
-						db.logger.Debug("opening classified",
-							"eco", ecoCode, "opening", openingName, "event", game.Tags["Event"])
-					}
-				}
+		seen[gameHash] = true
+		for name, value := range opts.Tags {
+			if _, ok := game.Tags[name]; !ok {
+				game.Tags[name] = value
 			}
 		}
+		newGames = append(newGames, importCandidate{index: i, game: game, text: currentGameText, hash: gameHash})
+	}
 
-		// Insert game and tags
-		gameID, err := insertGameRecord(ctx, tx, stmtGame, stmtTag, game, currentGameText, gameHash, ecoCode, openingName)
-		if err != nil {
-			dbErr := fmt.Errorf("game %d (event: %s): %w", i+1, game.Tags["Event"], err)
-			allErrors = append(allErrors, &PGNImportError{OriginalError: dbErr, PGNText: currentGameText})
-			continue
-		}
-
-		// Extract and insert positions (if moves were parsed successfully)
-		if game.Root != nil && game.Root.Next != nil {
-			positions := ExtractPositions(game)
-			if len(positions) > 0 {
-				if err := insertPositions(ctx, tx, stmtPosition, gameID, positions, ecoCode, openingName); err != nil {
-					db.logger.Warn("failed to insert positions for game",
-						"game_id", gameID, "event", game.Tags["Event"], "error", err)
-					// Don't fail the import if position storage fails
-				} else {
-					db.logger.Debug("positions inserted", "game_id", gameID, "count", len(positions))
-				}
+	if opts.DryRun {
+		importedCount = len(newGames)
+	} else {
+		// Moves are parsed and classified across workers, and the games
+		// stored in order as they are ready
+		parallelOrdered(len(newGames), opts.workers(), func(j int) preparedGame {
+			return db.prepareGame(pgnDB, newGames[j].game)
+		}, func(j int, prepared preparedGame) {
+			candidate := newGames[j]
+			if progress != nil {
+				progress(candidate.index, len(pgnDB.Games))
 			}
-		}
+			if err := db.storeGame(ctx, itx.tx, itx.game, itx.tag, itx.position, candidate, prepared); err != nil {
+				allErrors = append(allErrors, err)
+				return
+			}
+			importedCount++
+		})
+	}
 
-		importedCount++
+	if progress != nil {
+		progress(len(pgnDB.Games), len(pgnDB.Games))
+	}
+
+	result.Imported, result.Errors = importedCount, allErrors
+	if opts.DryRun {
+		db.logger.Info("PGN dry run completed", "file", filePath, "wouldImport", importedCount,
+			"duplicates", result.Duplicates, "errors", len(allErrors))
+		return result
 	}
 
 	// Commit transaction
 	db.logger.Debug("committing transaction", "importedGames", importedCount, "errors", len(allErrors))
-	err = tx.Commit()
-	if err != nil {
+	if err := itx.tx.Commit(); err != nil {
 		db.logger.Error("failed to commit transaction", "error", err, "importedGames", importedCount)
-		_ = tx.Rollback()
+		_ = itx.tx.Rollback()
 		allErrors = append(allErrors, &PGNImportError{OriginalError: fmt.Errorf("failed to commit transaction: %w", err), PGNText: ""})
-		return importedCount, allErrors
+		result.Errors = allErrors
+		return result
 	}
 
 	db.logger.Info("PGN import completed", "file", filePath, "imported", importedCount, "errors", len(allErrors))
-	return importedCount, allErrors
+	return result
 }
 
 // GetGameCount returns the total number of games in the database
 func (db *DB) GetGameCount(ctx context.Context) (int, error) {
 	var count int
-	err := db.conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM games").Scan(&count)
+	err := db.conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM games WHERE deleted_at IS NULL").Scan(&count)
 	if err != nil {
 		db.logger.Error("failed to get game count", "error", err)
 		return 0, fmt.Errorf("failed to get game count: %w", err)
@@ -521,12 +764,46 @@ func (db *DB) GetGameCount(ctx context.Context) (int, error) {
 	return count, nil
 }
 
+// GetLastGameID returns the ID of the most recently added game not in the
+// trash, or 0 if there is none
+func (db *DB) GetLastGameID(ctx context.Context) (int, error) {
+	var id int
+	err := db.conn.QueryRowContext(ctx, "SELECT COALESCE(MAX(id), 0) FROM games WHERE deleted_at IS NULL").Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get last game ID: %w", err)
+	}
+	return id, nil
+}
+
+// GetGameIDsAfter returns the IDs of the games added after the game with
+// id, in the order they were added. Games in the trash are left out.
+func (db *DB) GetGameIDsAfter(ctx context.Context, id int) ([]int, error) {
+	rows, err := db.conn.QueryContext(ctx, "SELECT id FROM games WHERE id > ? AND deleted_at IS NULL ORDER BY id", id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query game IDs: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var ids []int
+	for rows.Next() {
+		var gameID int
+		if err := rows.Scan(&gameID); err != nil {
+			return nil, fmt.Errorf("failed to scan game ID: %w", err)
+		}
+		ids = append(ids, gameID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read game IDs: %w", err)
+	}
+	return ids, nil
+}
+
 // SearchGames searches for games matching the specified criteria
 func (db *DB) SearchGames(ctx context.Context, criteria map[string]string, limit, offset int) ([]map[string]interface{}, error) {
 	db.logger.Debug("searching games", "criteria", criteria, "limit", limit, "offset", offset)
 
 	// Build the query
-	query := "SELECT id, event, site, date, white, black, result FROM games WHERE 1=1"
+	query := "SELECT id, event, site, date, white, black, result FROM games WHERE deleted_at IS NULL"
 	var args []interface{}
 
 	// Add search criteria
@@ -535,6 +812,35 @@ func (db *DB) SearchGames(ctx context.Context, criteria map[string]string, limit
 		case "white", "black", "event", "site", "date", "result":
 			query += fmt.Sprintf(" AND %s LIKE ?", field)
 			args = append(args, "%"+value+"%")
+		case "time_class":
+			query += " AND time_class = ?"
+			args = append(args, value)
+		case "endgame":
+			if value == NoEndgame {
+				value = ""
+			}
+			query += " AND endgame = ?"
+			args = append(args, value)
+		case "motif":
+			// tagged in the latest analysis of the game
+			query += ` AND id IN (
+				SELECT a.game_id FROM analyses a
+				JOIN analysis_motifs m ON m.analysis_id = a.id
+				WHERE m.motif = ? AND a.id = (SELECT MAX(id) FROM analyses WHERE game_id = a.game_id)
+			)`
+			args = append(args, value)
+		default:
+			if name, ok := strings.CutPrefix(field, TagCriterion); ok {
+				query += " AND id IN (SELECT game_id FROM tags WHERE tag_name = ? AND tag_value = ?)"
+				args = append(args, name, value)
+			}
+		}
+	}
+
+	// Games imported before endgames were classified are classified first
+	if _, ok := criteria["endgame"]; ok {
+		if _, err := db.ClassifyEndgames(ctx); err != nil {
+			return nil, err
 		}
 	}
 
@@ -582,77 +888,34 @@ func (db *DB) SearchGames(ctx context.Context, criteria map[string]string, limit
 	return games, nil
 }
 
-// GetGameByID retrieves a game by its ID
+// GetGameByID retrieves a game by its ID as a map keyed by column, as shown
+// in JSON output. Use GetGame for the typed Game.
 func (db *DB) GetGameByID(ctx context.Context, id int) (map[string]interface{}, error) {
-	// Query the game
-	row := db.conn.QueryRowContext(ctx, "SELECT * FROM games WHERE id = ?", id)
-
-	var gameID int
-	var event, site, date, round, white, black, result string
-	var whiteElo, blackElo int
-	var timeControl, pgnText, gameHash string
-	var createdAt string
-	var ecoCode, openingName sql.NullString
-
-	err := row.Scan(
-		&gameID, &event, &site, &date, &round, &white, &black, &result,
-		&whiteElo, &blackElo, &timeControl, &pgnText, &createdAt, &gameHash, &ecoCode, &openingName,
-	)
+	game, err := db.GetGame(ctx, id)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("game not found: %d", id)
-		}
-		return nil, fmt.Errorf("failed to scan game: %w", err)
-	}
-	
-	// Build the game data
-	game := map[string]interface{}{
-		"id":           gameID,
-		"event":        event,
-		"site":         site,
-		"date":         date,
-		"round":        round,
-		"white":        white,
-		"black":        black,
-		"result":       result,
-		"white_elo":    whiteElo,
-		"black_elo":    blackElo,
-		"time_control": timeControl,
-		"pgn_text":     pgnText,
-		"created_at":   createdAt,
-	}
-
-	// Add ECO fields if available
-	if ecoCode.Valid {
-		game["eco_code"] = ecoCode.String
-	}
-	if openingName.Valid {
-		game["opening_name"] = openingName.String
+		return nil, err
 	}
-	
-	// Get all tags
-	rows, err := db.conn.QueryContext(ctx, "SELECT tag_name, tag_value FROM tags WHERE game_id = ?", id)
+	return game.Map(), nil
+}
+
+// UpdateGamePGN replaces the PGN text of a game, for example after its
+// comments or variations were edited. The game hash is left alone, so the
+// game is still recognized as a duplicate when the original is imported again.
+func (db *DB) UpdateGamePGN(ctx context.Context, id int, pgnText string) error {
+	result, err := db.conn.ExecContext(ctx, `
+		UPDATE games SET pgn_text = ? WHERE id = ?
+	`, pgnText, id)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query tags: %w", err)
+		return fmt.Errorf("failed to update game: %w", err)
 	}
-	defer func() { _ = rows.Close() }()
-
-	tags := make(map[string]string)
-	for rows.Next() {
-		var name, value string
-		if err := rows.Scan(&name, &value); err != nil {
-			return nil, fmt.Errorf("failed to scan tag: %w", err)
-		}
-		tags[name] = value
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
 	}
-	
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating tag rows: %w", err)
+	if rows == 0 {
+		return fmt.Errorf("%w: %d", ErrGameNotFound, id)
 	}
-	
-	game["tags"] = tags
-	
-	return game, nil
+	return nil
 }
 
 // ClearGames removes all games from the database
@@ -689,6 +952,13 @@ func (db *DB) ClearGames(ctx context.Context) error {
 		return fmt.Errorf("failed to delete games: %w", err)
 	}
 
+	// Files imported before are to be imported again
+	_, err = tx.Exec("DELETE FROM imported_files")
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("failed to delete imported files: %w", err)
+	}
+
 	// Reset the auto-increment counters
 	_, err = tx.Exec("DELETE FROM sqlite_sequence WHERE name='games' OR name='tags' OR name='positions'")
 	if err != nil {
@@ -706,83 +976,132 @@ func (db *DB) ClearGames(ctx context.Context) error {
 
 // PlayerStats represents statistics for a player
 type PlayerStats struct {
-	Name          string  // Player's name
-	Games         int     // Total games played
-	Wins          int     // Total wins
-	Losses        int     // Total losses
-	Draws         int     // Total draws
-	WinRate       float64 // Win rate as a percentage
-	WhiteGames    int     // Games played as white
-	BlackGames    int     // Games played as black
-	WhiteWins     int     // Wins as white
-	BlackWins     int     // Wins as black
-	WhiteLosses   int     // Losses as white
-	BlackLosses   int     // Losses as black
-	WhiteDraws    int     // Draws as white
-	BlackDraws    int     // Draws as black
-	WhiteWinRate  float64 // Win rate as white (0-100)
-	BlackWinRate  float64 // Win rate as black (0-100)
-	BulletGames   int     // Games in bullet time control
-	BlitzGames    int     // Games in blitz time control
-	RapidGames    int     // Games in rapid time control
-	ClassicalGames int    // Games in classical/daily time control
+	Name           string            `json:"name"`            // Player's name
+	Games          int               `json:"games"`           // Total games played
+	Wins           int               `json:"wins"`            // Total wins
+	Losses         int               `json:"losses"`          // Total losses
+	Draws          int               `json:"draws"`           // Total draws
+	WinRate        float64           `json:"win_rate"`        // Win rate as a percentage
+	WhiteGames     int               `json:"white_games"`     // Games played as white
+	BlackGames     int               `json:"black_games"`     // Games played as black
+	WhiteWins      int               `json:"white_wins"`      // Wins as white
+	BlackWins      int               `json:"black_wins"`      // Wins as black
+	WhiteLosses    int               `json:"white_losses"`    // Losses as white
+	BlackLosses    int               `json:"black_losses"`    // Losses as black
+	WhiteDraws     int               `json:"white_draws"`     // Draws as white
+	BlackDraws     int               `json:"black_draws"`     // Draws as black
+	WhiteWinRate   float64           `json:"white_win_rate"`  // Win rate as white (0-100)
+	BlackWinRate   float64           `json:"black_win_rate"`  // Win rate as black (0-100)
+	BulletGames    int               `json:"bullet_games"`    // Games in bullet time control
+	BlitzGames     int               `json:"blitz_games"`     // Games in blitz time control
+	RapidGames     int               `json:"rapid_games"`     // Games in rapid time control
+	ClassicalGames int               `json:"classical_games"` // Games in classical/daily time control
+	RatingBands    []RatingBandStats `json:"rating_bands"`    // Results by opponent rating, in the order of RatingBands
 }
 
-// OpeningStats represents statistics for a chess opening
-type OpeningStats struct {
-	ECOCode      string  // ECO code (e.g., "C50")
-	OpeningName  string  // Opening name (e.g., "Italian Game")
-	Games        int     // Total games with this opening
-	Wins         int     // Wins with this opening
-	Losses       int     // Losses with this opening
-	Draws        int     // Draws with this opening
-	WinRate      float64 // Win rate as a percentage (0-100)
-	WhiteGames   int     // Games where player was white
-	BlackGames   int     // Games where player was black
-	WhiteWins    int     // Wins as white
-	BlackWins    int     // Wins as black
-	WhiteWinRate float64 // Win rate as white (0-100)
-	BlackWinRate float64 // Win rate as black (0-100)
+// RatingBands are the bands of opponent rating difference, the opponent's
+// rating less the player's, rounded to the nearest 100 and capped at 200
+// either way, halves away from even: "-200" holds every opponent rated 150
+// or more below, and "even" those less than 50 apart.
+var RatingBands = []string{"-200", "-100", "even", "+100", "+200"}
+
+// RatingBandStats represents a player's results against opponents in one of
+// RatingBands, overall and by color.
+type RatingBandStats struct {
+	Band         string  `json:"band"`           // One of RatingBands
+	Games        int     `json:"games"`          // Games against opponents in the band
+	Wins         int     `json:"wins"`           // Total wins
+	Losses       int     `json:"losses"`         // Total losses
+	Draws        int     `json:"draws"`          // Total draws
+	WinRate      float64 `json:"win_rate"`       // Win rate as a percentage (0-100)
+	WhiteGames   int     `json:"white_games"`    // Games played as white
+	BlackGames   int     `json:"black_games"`    // Games played as black
+	WhiteWins    int     `json:"white_wins"`     // Wins as white
+	BlackWins    int     `json:"black_wins"`     // Wins as black
+	WhiteLosses  int     `json:"white_losses"`   // Losses as white
+	BlackLosses  int     `json:"black_losses"`   // Losses as black
+	WhiteDraws   int     `json:"white_draws"`    // Draws as white
+	BlackDraws   int     `json:"black_draws"`    // Draws as black
+	WhiteWinRate float64 `json:"white_win_rate"` // Win rate as white (0-100)
+	BlackWinRate float64 `json:"black_win_rate"` // Win rate as black (0-100)
 }
 
-// categorizeTimeControl categorizes a time control string into bullet/blitz/rapid/classical
-func categorizeTimeControl(tc string) string {
-	if tc == "" {
-		return "unknown"
+// ratingBand returns the one of RatingBands an opponent rated diff points
+// above the player falls in.
+func ratingBand(diff int) string {
+	band := diff / 100
+	if rest := diff % 100; rest >= 50 {
+		band++
+	} else if rest <= -50 {
+		band--
 	}
+	return RatingBands[min(max(band, -2), 2)+2]
+}
 
-	// Parse time control formats like "180+0", "600+5", "1/86400", etc.
-	// For Chess.com/Lichess, typical formats are:
-	// - Bullet: < 3 minutes (180 seconds)
-	// - Blitz: 3-10 minutes (180-600 seconds)
-	// - Rapid: 10-60 minutes (600-3600 seconds)
-	// - Classical: > 60 minutes or daily/correspondence
-
-	// Handle daily/correspondence formats (like "1/86400")
-	if strings.Contains(tc, "/") {
-		return "classical"
+// addRatingBandResult counts a game the player played with color against an
+// opponent rated diff points above them, with the given result.
+func (s *PlayerStats) addRatingBandResult(diff, color int, result pgn.Result) {
+	band := ratingBand(diff)
+	var stats *RatingBandStats
+	for i := range s.RatingBands {
+		if s.RatingBands[i].Band == band {
+			stats = &s.RatingBands[i]
+		}
 	}
-
-	// Parse base time in seconds
-	parts := strings.Split(tc, "+")
-	if len(parts) == 0 {
-		return "unknown"
+	if stats == nil {
+		s.RatingBands = append(s.RatingBands, RatingBandStats{Band: band})
+		stats = &s.RatingBands[len(s.RatingBands)-1]
 	}
 
-	baseTime, err := strconv.Atoi(parts[0])
-	if err != nil {
-		return "unknown"
+	games, wins, losses, draws := &stats.WhiteGames, &stats.WhiteWins, &stats.WhiteLosses, &stats.WhiteDraws
+	if color == internal.Black {
+		games, wins, losses, draws = &stats.BlackGames, &stats.BlackWins, &stats.BlackLosses, &stats.BlackDraws
+	}
+	stats.Games++
+	*games++
+	if !result.Finished() {
+		return
 	}
+	switch result.Score(color) {
+	case 1:
+		stats.Wins++
+		*wins++
+	case 0:
+		stats.Losses++
+		*losses++
+	default:
+		stats.Draws++
+		*draws++
+	}
+}
+
+// OpeningStats represents statistics for a chess opening
+type OpeningStats struct {
+	ECOCode      string  `json:"eco_code"`       // ECO code (e.g., "C50")
+	OpeningName  string  `json:"opening_name"`   // Opening name (e.g., "Italian Game")
+	Games        int     `json:"games"`          // Total games with this opening
+	Wins         int     `json:"wins"`           // Wins with this opening
+	Losses       int     `json:"losses"`         // Losses with this opening
+	Draws        int     `json:"draws"`          // Draws with this opening
+	WinRate      float64 `json:"win_rate"`       // Win rate as a percentage (0-100)
+	WhiteGames   int     `json:"white_games"`    // Games where player was white
+	BlackGames   int     `json:"black_games"`    // Games where player was black
+	WhiteWins    int     `json:"white_wins"`     // Wins as white
+	BlackWins    int     `json:"black_wins"`     // Wins as black
+	WhiteWinRate float64 `json:"white_win_rate"` // Win rate as white (0-100)
+	BlackWinRate float64 `json:"black_win_rate"` // Win rate as black (0-100)
+}
 
-	// Categorize based on base time
-	if baseTime < 180 {
-		return "bullet"
-	} else if baseTime < 600 {
-		return "blitz"
-	} else if baseTime < 3600 {
-		return "rapid"
+// categorizeTimeControl categorizes a time control string into bullet/blitz/rapid/classical
+func categorizeTimeControl(tc string) string {
+	switch class := TimeClass(tc); class {
+	case "":
+		return "unknown"
+	case TimeClassDaily:
+		return TimeClassClassical
+	default:
+		return class
 	}
-	return "classical"
 }
 
 // GetPlayerStats retrieves statistics for all players in the database
@@ -793,6 +1112,13 @@ func (db *DB) GetPlayerStats(ctx context.Context) ([]PlayerStats, error) {
 // GetPlayerStatsFiltered retrieves statistics for specific players in the database
 // If players is nil or empty, returns stats for all players
 func (db *DB) GetPlayerStatsFiltered(ctx context.Context, players []string) ([]PlayerStats, error) {
+	return db.GetPlayerStatsForTimeClass(ctx, players, "")
+}
+
+// GetPlayerStatsForTimeClass retrieves statistics for specific players, or
+// all players if empty, from their games of one of TimeClasses. An empty
+// timeClass counts every game.
+func (db *DB) GetPlayerStatsForTimeClass(ctx context.Context, players []string, timeClass string) ([]PlayerStats, error) {
 	// Build query based on whether we're filtering
 	var query string
 	var args []interface{}
@@ -800,9 +1126,9 @@ func (db *DB) GetPlayerStatsFiltered(ctx context.Context, players []string) ([]P
 	if len(players) == 0 {
 		// Query all games
 		query = `
-			SELECT white, black, result, time_control
+			SELECT white, black, result, time_control, COALESCE(white_elo, 0), COALESCE(black_elo, 0)
 			FROM games
-			WHERE white != '' AND black != ''
+			WHERE deleted_at IS NULL AND white != '' AND black != ''
 		`
 	} else {
 		// Query games for specific players
@@ -814,13 +1140,17 @@ func (db *DB) GetPlayerStatsFiltered(ctx context.Context, players []string) ([]P
 		}
 		playerList := strings.Join(placeholders, ",")
 		query = fmt.Sprintf(`
-			SELECT white, black, result, time_control
+			SELECT white, black, result, time_control, COALESCE(white_elo, 0), COALESCE(black_elo, 0)
 			FROM games
 			WHERE (white IN (%s) OR black IN (%s))
-			AND white != '' AND black != ''
+			AND deleted_at IS NULL AND white != '' AND black != ''
 		`, playerList, playerList)
 		args = append(args, args...) // Duplicate args for both IN clauses
 	}
+	if timeClass != "" {
+		query += " AND time_class = ?"
+		args = append(args, timeClass)
+	}
 
 	rows, err := db.conn.QueryContext(ctx, query, args...)
 	if err != nil {
@@ -842,7 +1172,8 @@ func (db *DB) GetPlayerStatsFiltered(ctx context.Context, players []string) ([]P
 	for rows.Next() {
 		var white, black, result string
 		var timeControl sql.NullString
-		if err := rows.Scan(&white, &black, &result, &timeControl); err != nil {
+		var whiteElo, blackElo int
+		if err := rows.Scan(&white, &black, &result, &timeControl, &whiteElo, &blackElo); err != nil {
 			return nil, fmt.Errorf("failed to scan row: %w", err)
 		}
 
@@ -901,8 +1232,8 @@ func (db *DB) GetPlayerStatsFiltered(ctx context.Context, players []string) ([]P
 		}
 
 		// Update win/loss/draw counts based on result
-		switch result {
-		case "1-0": // White win
+		switch pgn.ParseResult(result) {
+		case pgn.WhiteWin:
 			if trackWhite {
 				playerStats[white].Wins++
 				playerStats[white].WhiteWins++
@@ -911,7 +1242,7 @@ func (db *DB) GetPlayerStatsFiltered(ctx context.Context, players []string) ([]P
 				playerStats[black].Losses++
 				playerStats[black].BlackLosses++
 			}
-		case "0-1": // Black win
+		case pgn.BlackWin:
 			if trackBlack {
 				playerStats[black].Wins++
 				playerStats[black].BlackWins++
@@ -920,7 +1251,7 @@ func (db *DB) GetPlayerStatsFiltered(ctx context.Context, players []string) ([]P
 				playerStats[white].Losses++
 				playerStats[white].WhiteLosses++
 			}
-		case "1/2-1/2": // Draw
+		case pgn.Draw:
 			if trackWhite {
 				playerStats[white].Draws++
 				playerStats[white].WhiteDraws++
@@ -932,6 +1263,16 @@ func (db *DB) GetPlayerStatsFiltered(ctx context.Context, players []string) ([]P
 		default: // Unknown result or ongoing game
 			// Skip updating win/loss/draw counts
 		}
+
+		// Results by opponent rating, when both players are rated
+		if whiteElo > 0 && blackElo > 0 {
+			if trackWhite {
+				playerStats[white].addRatingBandResult(blackElo-whiteElo, internal.White, pgn.ParseResult(result))
+			}
+			if trackBlack {
+				playerStats[black].addRatingBandResult(whiteElo-blackElo, internal.Black, pgn.ParseResult(result))
+			}
+		}
 	}
 
 	if err := rows.Err(); err != nil {
@@ -956,6 +1297,21 @@ func (db *DB) GetPlayerStatsFiltered(ctx context.Context, players []string) ([]P
 			stats.BlackWinRate = float64(stats.BlackWins) / float64(stats.BlackGames) * 100.0
 		}
 
+		// Calculate win rates by opponent rating
+		for i := range stats.RatingBands {
+			band := &stats.RatingBands[i]
+			band.WinRate = float64(band.Wins) / float64(band.Games) * 100.0
+			if band.WhiteGames > 0 {
+				band.WhiteWinRate = float64(band.WhiteWins) / float64(band.WhiteGames) * 100.0
+			}
+			if band.BlackGames > 0 {
+				band.BlackWinRate = float64(band.BlackWins) / float64(band.BlackGames) * 100.0
+			}
+		}
+		sort.Slice(stats.RatingBands, func(i, j int) bool {
+			return slices.Index(RatingBands, stats.RatingBands[i].Band) < slices.Index(RatingBands, stats.RatingBands[j].Band)
+		})
+
 		results = append(results, *stats)
 	}
 
@@ -975,6 +1331,13 @@ func (db *DB) GetOpeningStats(ctx context.Context) ([]OpeningStats, error) {
 // GetOpeningStatsFiltered retrieves statistics for specific players' games with openings
 // If players is nil or empty, returns stats for all players
 func (db *DB) GetOpeningStatsFiltered(ctx context.Context, players []string) ([]OpeningStats, error) {
+	return db.GetOpeningStatsForTimeClass(ctx, players, "")
+}
+
+// GetOpeningStatsForTimeClass retrieves opening statistics for specific
+// players, or all players if empty, from their games of one of TimeClasses.
+// An empty timeClass counts every game.
+func (db *DB) GetOpeningStatsForTimeClass(ctx context.Context, players []string, timeClass string) ([]OpeningStats, error) {
 	// Build query based on whether we're filtering by players
 	var query string
 	var args []interface{}
@@ -984,7 +1347,7 @@ func (db *DB) GetOpeningStatsFiltered(ctx context.Context, players []string) ([]
 		query = `
 			SELECT eco_code, opening_name, white, black, result
 			FROM games
-			WHERE eco_code IS NOT NULL AND eco_code != ''
+			WHERE deleted_at IS NULL AND eco_code IS NOT NULL AND eco_code != ''
 			AND white != '' AND black != ''
 		`
 	} else {
@@ -1000,11 +1363,15 @@ func (db *DB) GetOpeningStatsFiltered(ctx context.Context, players []string) ([]
 			SELECT eco_code, opening_name, white, black, result
 			FROM games
 			WHERE (white IN (%s) OR black IN (%s))
-			AND eco_code IS NOT NULL AND eco_code != ''
+			AND deleted_at IS NULL AND eco_code IS NOT NULL AND eco_code != ''
 			AND white != '' AND black != ''
 		`, playerList, playerList)
 		args = append(args, args...) // Duplicate args for both IN clauses
 	}
+	if timeClass != "" {
+		query += " AND time_class = ?"
+		args = append(args, timeClass)
+	}
 
 	rows, err := db.conn.QueryContext(ctx, query, args...)
 	if err != nil {
@@ -1067,8 +1434,8 @@ func (db *DB) GetOpeningStatsFiltered(ctx context.Context, players []string) ([]
 		}
 
 		// Update win/loss/draw counts based on result and player color
-		switch result {
-		case "1-0": // White win
+		switch pgn.ParseResult(result) {
+		case pgn.WhiteWin:
 			if whiteIsTracked {
 				stats.Wins++
 				stats.WhiteWins++
@@ -1076,7 +1443,7 @@ func (db *DB) GetOpeningStatsFiltered(ctx context.Context, players []string) ([]
 			if blackIsTracked && !whiteIsTracked {
 				stats.Losses++
 			}
-		case "0-1": // Black win
+		case pgn.BlackWin:
 			if blackIsTracked {
 				stats.Wins++
 				stats.BlackWins++
@@ -1084,7 +1451,7 @@ func (db *DB) GetOpeningStatsFiltered(ctx context.Context, players []string) ([]
 			if whiteIsTracked && !blackIsTracked {
 				stats.Losses++
 			}
-		case "1/2-1/2": // Draw
+		case pgn.Draw:
 			if whiteIsTracked {
 				stats.Draws++
 			}
@@ -1129,23 +1496,23 @@ func (db *DB) GetOpeningStatsFiltered(ctx context.Context, players []string) ([]
 
 // PositionFrequency represents a position and how often it occurs
 type PositionFrequency struct {
-	FEN            string  // The position in FEN notation
-	Count          int     // Number of times this position appears in the database
-	WhiteWins      int     // Number of games where white won from this position
-	BlackWins      int     // Number of games where black won from this position
-	Draws          int     // Number of games that were drawn from this position
-	WhiteWinPct    float64 // Percentage of games white won (0-100)
-	BlackWinPct    float64 // Percentage of games black won (0-100)
-	DrawPct        float64 // Percentage of games drawn (0-100)
-	ECOCode        string  // Most common ECO code for this position (if available)
-	OpeningName    string  // Most common opening name for this position (if available)
+	FEN         string  `json:"fen"`           // The position in FEN notation
+	Count       int     `json:"count"`         // Number of times this position appears in the database
+	WhiteWins   int     `json:"white_wins"`    // Number of games where white won from this position
+	BlackWins   int     `json:"black_wins"`    // Number of games where black won from this position
+	Draws       int     `json:"draws"`         // Number of games that were drawn from this position
+	WhiteWinPct float64 `json:"white_win_pct"` // Percentage of games white won (0-100)
+	BlackWinPct float64 `json:"black_win_pct"` // Percentage of games black won (0-100)
+	DrawPct     float64 `json:"draw_pct"`      // Percentage of games drawn (0-100)
+	ECOCode     string  `json:"eco_code"`      // Most common ECO code for this position (if available)
+	OpeningName string  `json:"opening_name"`  // Most common opening name for this position (if available)
 }
 
 // GetPositionStats retrieves statistics about positions in the database
 // Only includes positions reached after move 10 (move_number >= 20 half-moves)
 func (db *DB) GetPositionStats(ctx context.Context) (uniqueCount int, topPositions []PositionFrequency, err error) {
 	// Get count of unique positions (all moves)
-	err = db.conn.QueryRowContext(ctx, "SELECT COUNT(DISTINCT fen) FROM positions").Scan(&uniqueCount)
+	err = db.conn.QueryRowContext(ctx, "SELECT COUNT(DISTINCT p.fen) FROM positions p JOIN games g ON p.game_id = g.id WHERE g.deleted_at IS NULL").Scan(&uniqueCount)
 	if err != nil {
 		db.logger.Error("failed to get unique position count", "error", err)
 		return 0, nil, fmt.Errorf("failed to get unique position count: %w", err)
@@ -1164,7 +1531,7 @@ func (db *DB) GetPositionStats(ctx context.Context) (uniqueCount int, topPositio
 			p.opening_name
 		FROM positions p
 		JOIN games g ON p.game_id = g.id
-		WHERE p.move_number >= 20
+		WHERE p.move_number >= 20 AND g.deleted_at IS NULL
 		GROUP BY p.fen
 		ORDER BY frequency DESC
 		LIMIT 10
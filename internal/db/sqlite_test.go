@@ -74,6 +74,232 @@ func TestImportPGN_NoFEN(t *testing.T) {
 	}
 }
 
+func TestImportPGNReader(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "gochess-test-db-")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer func() { _ = os.RemoveAll(tempDir) }()
+
+	db, err := NewWithLogger(tempDir+"/test.db", logging.Discard())
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	data, err := os.ReadFile("../../testdata/lichess_no_fen.pgn")
+	if err != nil {
+		t.Fatalf("failed to read PGN file: %v", err)
+	}
+	result := db.ImportPGNReader(context.Background(), "stdin", strings.NewReader(string(data)), ImportOptions{})
+	if len(result.Errors) != 0 {
+		t.Fatalf("expected 0 errors, but got %d: %v", len(result.Errors), result.Errors)
+	}
+	if result.Imported != 1 {
+		t.Fatalf("expected 1 game to be imported, but got %d", result.Imported)
+	}
+
+	// Nothing to import names the source
+	result = db.ImportPGNReader(context.Background(), "stdin", strings.NewReader(""), ImportOptions{})
+	if len(result.Errors) != 1 || !strings.Contains(result.Errors[0].Error(), "stdin") {
+		t.Fatalf("expected an error naming stdin, but got %v", result.Errors)
+	}
+}
+
+func TestNewInMemory(t *testing.T) {
+	db, err := NewInMemory()
+	if err != nil {
+		t.Fatalf("failed to create in-memory database: %v", err)
+	}
+	defer func() { _ = db.Close() }()
+	ctx := context.Background()
+
+	count, errors := db.ImportPGN(ctx, "../../testdata/lichess_no_fen.pgn")
+	if len(errors) != 0 || count != 1 {
+		t.Fatalf("expected 1 game imported without errors, but got %d: %v", count, errors)
+	}
+	// Every query sees the games, whichever connection it would have used
+	for i := 0; i < 3; i++ {
+		total, err := db.GetGameCount(ctx)
+		if err != nil {
+			t.Fatalf("failed to count games: %v", err)
+		}
+		if total != 1 {
+			t.Fatalf("expected 1 game, but got %d", total)
+		}
+	}
+	if _, err := os.Stat(InMemory); !os.IsNotExist(err) {
+		t.Fatalf("expected no file named %s, but got %v", InMemory, err)
+	}
+
+	// Another in-memory database starts empty
+	other, err := NewInMemory()
+	if err != nil {
+		t.Fatalf("failed to create in-memory database: %v", err)
+	}
+	defer func() { _ = other.Close() }()
+	if total, err := other.GetGameCount(ctx); err != nil || total != 0 {
+		t.Fatalf("expected an empty database, but got %d games (%v)", total, err)
+	}
+}
+
+func TestOpenReadOnly(t *testing.T) {
+	tempDir := t.TempDir()
+	dbPath := tempDir + "/games#1.db"
+	ctx := context.Background()
+
+	if _, err := OpenWithLogger(dbPath, ReadOnly, logging.Discard()); err == nil {
+		t.Fatal("expected an error opening a missing database read-only")
+	}
+
+	// The database stays open for writing, as by the sync daemon
+	writer, err := OpenWithLogger(dbPath, ReadWrite, logging.Discard())
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+	defer func() { _ = writer.Close() }()
+	if count, errors := writer.ImportPGN(ctx, "../../testdata/lichess_no_fen.pgn"); len(errors) != 0 || count != 1 {
+		t.Fatalf("expected 1 game imported without errors, but got %d: %v", count, errors)
+	}
+
+	reader, err := OpenWithLogger(dbPath, ReadOnly, logging.Discard())
+	if err != nil {
+		t.Fatalf("failed to open database read-only: %v", err)
+	}
+	defer func() { _ = reader.Close() }()
+	if total, err := reader.GetGameCount(ctx); err != nil || total != 1 {
+		t.Fatalf("expected 1 game, but got %d (%v)", total, err)
+	}
+	if err := reader.SetNote(ctx, 1, "a note"); err == nil {
+		t.Fatal("expected an error writing to a read-only database")
+	}
+
+	// Games the writer adds later are read
+	if count, errors := writer.ImportPGN(ctx, "../../testdata/invalid_fen.pgn"); len(errors) != 0 || count != 1 {
+		t.Fatalf("expected 1 game imported without errors, but got %d: %v", count, errors)
+	}
+	if total, err := reader.GetGameCount(ctx); err != nil || total != 2 {
+		t.Fatalf("expected 2 games, but got %d (%v)", total, err)
+	}
+	if _, err := os.Stat(tempDir + "/games"); !os.IsNotExist(err) {
+		t.Fatalf("expected the path to be read as a file name, but found %v", err)
+	}
+}
+
+func TestIsInMemory(t *testing.T) {
+	tests := map[string]bool{
+		":memory:":                      true,
+		"file::memory:":                 true,
+		"file::memory:?cache=shared":    true,
+		"file:test.db?mode=memory":      true,
+		"file:test.db?mode=ro":          false,
+		"games.db":                      false,
+		"/home/player/.gochess/memory:": false,
+	}
+	for path, want := range tests {
+		if got := IsInMemory(path); got != want {
+			t.Errorf("IsInMemory(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestImportPGNWithOptions_DryRun(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "gochess-test-db-")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer func() { _ = os.RemoveAll(tempDir) }()
+
+	db, err := NewWithLogger(tempDir+"/test.db", logging.Discard())
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+	defer func() { _ = db.Close() }()
+	ctx := context.Background()
+
+	// Two games, one of them twice, and one missing its players
+	pgnText := `[Event "1"]
+[Site "?"]
+[Date "2024.01.01"]
+[White "Alice"]
+[Black "Bob"]
+[Result "1-0"]
+
+1. e4 e5 1-0
+
+[Event "2"]
+[Site "?"]
+[Date "2024.01.02"]
+[White "Bob"]
+[Black "Alice"]
+[Result "0-1"]
+
+1. d4 d5 0-1
+
+[Event "1"]
+[Site "?"]
+[Date "2024.01.01"]
+[White "Alice"]
+[Black "Bob"]
+[Result "1-0"]
+
+1. e4 e5 1-0
+
+[Event "3"]
+[Site "?"]
+[Date "2024.01.03"]
+[Result "*"]
+
+1. c4 *
+`
+	pgnPath := tempDir + "/games.pgn"
+	if err := os.WriteFile(pgnPath, []byte(pgnText), 0644); err != nil {
+		t.Fatalf("failed to write PGN file: %v", err)
+	}
+
+	players := []string{"White", "Black"}
+	result := db.ImportPGNWithOptions(ctx, pgnPath, ImportOptions{DryRun: true, RequiredTags: players})
+	if result.Imported != 2 || result.Duplicates != 1 || len(result.Errors) != 1 {
+		t.Fatalf("expected 2 games to import, 1 duplicate and 1 error, got %d, %d and %v",
+			result.Imported, result.Duplicates, result.Errors)
+	}
+	if count, _ := db.GetGameCount(ctx); count != 0 {
+		t.Fatalf("expected a dry run to store no games, but found %d", count)
+	}
+
+	result = db.ImportPGNWithOptions(ctx, pgnPath, ImportOptions{RequiredTags: players})
+	if result.Imported != 2 || result.Duplicates != 1 {
+		t.Fatalf("expected 2 games imported and 1 duplicate, got %d and %d", result.Imported, result.Duplicates)
+	}
+
+	result = db.ImportPGNWithOptions(ctx, pgnPath, ImportOptions{DryRun: true, RequiredTags: players})
+	if result.Imported != 0 || result.Duplicates != 3 {
+		t.Fatalf("expected nothing to import and 3 duplicates, got %d and %d", result.Imported, result.Duplicates)
+	}
+
+	// A dry run only reads, so it works on the database opened read-only
+	readOnly, err := OpenWithLogger(tempDir+"/test.db", ReadOnly, logging.Discard())
+	if err != nil {
+		t.Fatalf("failed to open the database read-only: %v", err)
+	}
+	defer func() { _ = readOnly.Close() }()
+	result = readOnly.ImportPGNWithOptions(ctx, pgnPath, ImportOptions{DryRun: true, RequiredTags: players})
+	if result.Imported != 0 || result.Duplicates != 3 || len(result.Errors) != 1 {
+		t.Fatalf("expected a read-only dry run to find 3 duplicates and 1 error, got %d, %d and %v",
+			result.Imported, result.Duplicates, result.Errors)
+	}
+
+	// Without required tags, the game missing its players gets placeholders
+	result = db.ImportPGNWithOptions(ctx, pgnPath, ImportOptions{})
+	if result.Imported != 1 || len(result.Errors) != 0 {
+		t.Fatalf("expected 1 game imported without errors, got %d and %v", result.Imported, result.Errors)
+	}
+	games, err := db.SearchGames(ctx, map[string]string{"event": "3"}, 10, 0)
+	if err != nil || len(games) != 1 || games[0]["white"] != "?" || games[0]["black"] != "?" {
+		t.Fatalf("expected the game with placeholder players, got %+v (%v)", games, err)
+	}
+}
+
 // TestValidateGameTags tests the validateGameTags function
 func TestValidateGameTags(t *testing.T) {
 	tests := []struct {
@@ -155,7 +381,7 @@ func TestValidateGameTags(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := validateGameTags(tt.game)
+			err := validateGameTags(tt.game, []string{"Event", "Site", "Date", "White", "Black", "Result"})
 			if tt.wantError {
 				if err == nil {
 					t.Errorf("validateGameTags() expected error but got nil")
@@ -171,6 +397,24 @@ func TestValidateGameTags(t *testing.T) {
 	}
 }
 
+func TestValidateGameTags_NoneRequired(t *testing.T) {
+	game := &pgn.Game{Tags: map[string]string{"White": "Player1", "Site": ""}}
+	if err := validateGameTags(game, nil); err != nil {
+		t.Fatalf("validateGameTags() unexpected error = %v", err)
+	}
+
+	fillTagPlaceholders(game)
+	want := map[string]string{
+		"Event": "?", "Site": "?", "Date": "????.??.??", "Round": "?",
+		"White": "Player1", "Black": "?", "Result": "*",
+	}
+	for name, value := range want {
+		if game.Tags[name] != value {
+			t.Errorf("tag %s = %q, want %q", name, game.Tags[name], value)
+		}
+	}
+}
+
 // TestProcessParseErrors tests the processParseErrors function
 func TestProcessParseErrors(t *testing.T) {
 	tests := []struct {
@@ -271,10 +515,19 @@ func TestCheckDuplicateGame(t *testing.T) {
 		t.Fatalf("failed to insert test game: %v", err)
 	}
 
+	_, err = tx.Exec(`
+		INSERT INTO games (event, site, date, round, white, black, result, pgn_text, game_hash, deleted_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+	`, "Test Event", "Test Site", "2024.01.02", "1", "Player1", "Player2", "0-1", "test pgn", "trashed-hash")
+	if err != nil {
+		t.Fatalf("failed to insert trashed game: %v", err)
+	}
+
 	tests := []struct {
 		name         string
 		gameHash     string
 		wantDuplicate bool
+		wantTrashed  bool
 		wantError    bool
 	}{
 		{
@@ -283,6 +536,12 @@ func TestCheckDuplicateGame(t *testing.T) {
 			wantDuplicate: true,
 			wantError:    false,
 		},
+		{
+			name:         "Duplicate game in the trash",
+			gameHash:     "trashed-hash",
+			wantDuplicate: true,
+			wantTrashed:  true,
+		},
 		{
 			name:         "No duplicate - different hash",
 			gameHash:     "different-hash-456",
@@ -299,7 +558,7 @@ func TestCheckDuplicateGame(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			isDuplicate, err := checkDuplicateGame(tx, tt.gameHash)
+			isDuplicate, trashed, err := checkDuplicateGame(tx, tt.gameHash)
 			if tt.wantError {
 				if err == nil {
 					t.Errorf("checkDuplicateGame() expected error but got nil")
@@ -308,8 +567,8 @@ func TestCheckDuplicateGame(t *testing.T) {
 				if err != nil {
 					t.Errorf("checkDuplicateGame() unexpected error = %v", err)
 				}
-				if isDuplicate != tt.wantDuplicate {
-					t.Errorf("checkDuplicateGame() = %v, want %v", isDuplicate, tt.wantDuplicate)
+				if isDuplicate != tt.wantDuplicate || trashed != tt.wantTrashed {
+					t.Errorf("checkDuplicateGame() = %v, %v, want %v, %v", isDuplicate, trashed, tt.wantDuplicate, tt.wantTrashed)
 				}
 			}
 		})
@@ -342,8 +601,8 @@ func TestInsertGameRecord(t *testing.T) {
 	stmtGame, err := tx.PrepareContext(ctx, `
 		INSERT INTO games (
 			event, site, date, round, white, black, result,
-			white_elo, black_elo, time_control, pgn_text, game_hash, eco_code, opening_name
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			white_elo, black_elo, time_control, pgn_text, game_hash, eco_code, opening_name, endgame, time_class
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`)
 	if err != nil {
 		t.Fatalf("failed to prepare game statement: %v", err)
@@ -426,7 +685,7 @@ func TestInsertGameRecord(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gameID, err := insertGameRecord(ctx, tx, stmtGame, stmtTag, tt.game, tt.gameText, tt.gameHash, "", "")
+			gameID, err := insertGameRecord(ctx, tx, stmtGame, stmtTag, tt.game, tt.gameText, tt.gameHash, "", "", "")
 			if tt.wantError {
 				if err == nil {
 					t.Errorf("insertGameRecord() expected error but got nil")
@@ -631,6 +890,62 @@ func TestGetGameByID(t *testing.T) {
 	})
 }
 
+func TestUpdateGamePGN(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "gochess-test-updategame-")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer func() { _ = os.RemoveAll(tempDir) }()
+
+	db, err := NewWithLogger(tempDir+"/test.db", logging.Discard())
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	ctx := context.Background()
+
+	pgnContent := `[Event "Test"]
+[Site "Here"]
+[Date "2024.01.15"]
+[Round "1"]
+[White "Alice"]
+[Black "Bob"]
+[Result "1-0"]
+
+1. e4 e5 1-0
+`
+	pgnFile := tempDir + "/test.pgn"
+	if err := os.WriteFile(pgnFile, []byte(pgnContent), 0644); err != nil {
+		t.Fatalf("failed to write PGN file: %v", err)
+	}
+	if _, errs := db.ImportPGN(ctx, pgnFile); len(errs) > 0 {
+		t.Fatalf("import errors: %v", errs)
+	}
+
+	annotated := strings.Replace(pgnContent, "1. e4 e5", "1. e4 {Best by test} e5", 1)
+	if err := db.UpdateGamePGN(ctx, 1, annotated); err != nil {
+		t.Fatalf("failed to update game: %v", err)
+	}
+	game, err := db.GetGameByID(ctx, 1)
+	if err != nil {
+		t.Fatalf("failed to get game: %v", err)
+	}
+	if game["pgn_text"] != annotated {
+		t.Errorf("expected updated pgn_text, got %v", game["pgn_text"])
+	}
+
+	// The original is still a duplicate of the edited game
+	count, _ := db.ImportPGN(ctx, pgnFile)
+	if count != 0 {
+		t.Errorf("expected re-import to be skipped, got %d games", count)
+	}
+
+	if err := db.UpdateGamePGN(ctx, 999, annotated); err == nil || !strings.Contains(err.Error(), "game not found") {
+		t.Errorf("expected 'game not found' error, got: %v", err)
+	}
+}
+
 func TestClearGames(t *testing.T) {
 	tempDir, err := os.MkdirTemp("", "gochess-test-clear-")
 	if err != nil {
@@ -770,3 +1085,63 @@ func TestClearGames(t *testing.T) {
 		}
 	})
 }
+
+func TestGetGameIDsAfter(t *testing.T) {
+	tempDir := t.TempDir()
+	db, err := NewWithLogger(tempDir+"/test.db", logging.Discard())
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+	defer func() { _ = db.Close() }()
+	ctx := context.Background()
+
+	last, err := db.GetLastGameID(ctx)
+	if err != nil || last != 0 {
+		t.Fatalf("GetLastGameID() on an empty database = %d, %v", last, err)
+	}
+
+	pgnFile := tempDir + "/games.pgn"
+	pgnContent := `[Event "1"] [Site "?"] [Date "2024.01.01"] [White "A"] [Black "B"] [Result "1-0"] 1. e4 1-0
+[Event "2"] [Site "?"] [Date "2024.01.02"] [White "A"] [Black "B"] [Result "0-1"] 1. d4 0-1
+[Event "3"] [Site "?"] [Date "2024.01.03"] [White "A"] [Black "B"] [Result "1/2-1/2"] 1. c4 1/2-1/2`
+	if err := os.WriteFile(pgnFile, []byte(pgnContent), 0644); err != nil {
+		t.Fatalf("failed to write PGN: %v", err)
+	}
+	if count, errs := db.ImportPGN(ctx, pgnFile); count != 3 || len(errs) > 0 {
+		t.Fatalf("ImportPGN() = %d, %v", count, errs)
+	}
+
+	last, err = db.GetLastGameID(ctx)
+	if err != nil || last != 3 {
+		t.Errorf("GetLastGameID() = %d, %v, want 3", last, err)
+	}
+	ids, err := db.GetGameIDsAfter(ctx, 1)
+	if err != nil {
+		t.Fatalf("GetGameIDsAfter() failed: %v", err)
+	}
+	if len(ids) != 2 || ids[0] != 2 || ids[1] != 3 {
+		t.Errorf("GetGameIDsAfter(1) = %v, want [2 3]", ids)
+	}
+
+	// Games in the trash are left out, as GetGame no longer finds them
+	for _, id := range []int{2, 3} {
+		if err := db.DeleteGame(ctx, id); err != nil {
+			t.Fatalf("DeleteGame(%d) failed: %v", id, err)
+		}
+	}
+	ids, err = db.GetGameIDsAfter(ctx, 0)
+	if err != nil || len(ids) != 1 || ids[0] != 1 {
+		t.Errorf("GetGameIDsAfter(0) with games in the trash = %v, %v, want [1]", ids, err)
+	}
+	last, err = db.GetLastGameID(ctx)
+	if err != nil || last != 1 {
+		t.Errorf("GetLastGameID() with games in the trash = %d, %v, want 1", last, err)
+	}
+
+	// Importing them again finds them in the trash
+	result := db.ImportPGNWithOptions(ctx, pgnFile, ImportOptions{})
+	if result.Imported != 0 || result.Duplicates != 3 || result.Trashed != 2 {
+		t.Errorf("reimport = %d imported, %d duplicates, %d trashed, want 0, 3 and 2",
+			result.Imported, result.Duplicates, result.Trashed)
+	}
+}
@@ -0,0 +1,195 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kyleboon/gochess/internal"
+	"github.com/kyleboon/gochess/internal/pgn"
+)
+
+// sessionGap is the longest break between two games of the same session.
+const sessionGap = time.Hour
+
+// ResultCounts counts the results of a set of games.
+type ResultCounts struct {
+	Games   int     `json:"games"`
+	Wins    int     `json:"wins"`
+	Losses  int     `json:"losses"`
+	Draws   int     `json:"draws"`
+	WinRate float64 `json:"win_rate"` // percentage of games won (0-100)
+}
+
+// add counts a game that scored score.
+func (r *ResultCounts) add(score float64) {
+	r.Games++
+	switch score {
+	case 1:
+		r.Wins++
+	case 0:
+		r.Losses++
+	default:
+		r.Draws++
+	}
+	r.WinRate = float64(r.Wins) / float64(r.Games) * 100
+}
+
+// Streaks describes the runs of results in a player's finished games, in the
+// order they were played, and how they played in sessions: games at most an
+// hour apart, or on the same day when the time of day of a game is not
+// known.
+type Streaks struct {
+	Games             int          `json:"games"`
+	Overall           ResultCounts `json:"overall"`
+	LongestWinStreak  int          `json:"longest_win_streak"`
+	LongestLossStreak int          `json:"longest_loss_streak"`
+	CurrentStreak     int          `json:"current_streak"` // wins if positive, losses if negative
+	AfterWin          ResultCounts `json:"after_win"`      // the next game of the session after a win
+	AfterLoss         ResultCounts `json:"after_loss"`     // the next game of the session after a loss
+	Sessions          int          `json:"sessions"`
+	GamesPerSession   float64      `json:"games_per_session"`
+	LongestSession    int          `json:"longest_session"`
+}
+
+// streakGame is a finished game of the player, with when it was played.
+type streakGame struct {
+	id     int
+	played time.Time
+	timed  bool // whether played has the time of day
+	score  float64
+}
+
+// gamePlayedAt returns when a game was played from its tags: the end of the
+// game as Chess.com records it, or its start as Lichess does, or only its
+// date. timed reports whether the time of day is known.
+func gamePlayedAt(date, endDate, endTime, utcDate, utcTime string) (played time.Time, timed bool) {
+	for _, dt := range [][2]string{{endDate, endTime}, {utcDate, utcTime}} {
+		if t, err := time.Parse("2006.01.02 15:04:05", dt[0]+" "+dt[1]); err == nil {
+			return t, true
+		}
+	}
+	t, _ := time.Parse("2006.01.02", date)
+	return t, false
+}
+
+// sameSession reports whether b, played after a, belongs to the same session.
+func sameSession(a, b streakGame) bool {
+	if a.timed && b.timed {
+		return b.played.Sub(a.played) <= sessionGap
+	}
+	return !a.played.IsZero() && a.played.Format("2006-01-02") == b.played.Format("2006-01-02")
+}
+
+// GetStreaks computes the streaks and sessions of the games of players, all
+// taken as one player, such as a player's accounts on several sites.
+func (db *DB) GetStreaks(ctx context.Context, players []string) (*Streaks, error) {
+	if len(players) == 0 {
+		return nil, fmt.Errorf("no players given")
+	}
+	placeholders := make([]string, len(players))
+	var args []interface{}
+	for i, player := range players {
+		placeholders[i] = "?"
+		args = append(args, player)
+	}
+	playerList := strings.Join(placeholders, ",")
+	args = append(args, args...) // Duplicate args for both IN clauses
+
+	tag := func(name string) string {
+		return fmt.Sprintf("COALESCE((SELECT tag_value FROM tags WHERE game_id = g.id AND tag_name = '%s'), '')", name)
+	}
+	query := fmt.Sprintf(`
+		SELECT g.id, g.white, g.black, g.result, COALESCE(g.date, ''), %s, %s, %s, %s
+		FROM games g
+		WHERE (g.white IN (%s) OR g.black IN (%s))
+		AND g.deleted_at IS NULL
+	`, tag("EndDate"), tag("EndTime"), tag("UTCDate"), tag("UTCTime"), playerList, playerList)
+
+	rows, err := db.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query games: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	filterSet := make(map[string]bool)
+	for _, player := range players {
+		filterSet[player] = true
+	}
+
+	var games []streakGame
+	for rows.Next() {
+		var g streakGame
+		var white, black, result, date, endDate, endTime, utcDate, utcTime string
+		if err := rows.Scan(&g.id, &white, &black, &result, &date, &endDate, &endTime, &utcDate, &utcTime); err != nil {
+			return nil, fmt.Errorf("failed to scan game: %w", err)
+		}
+		r := pgn.ParseResult(result)
+		if !r.Finished() {
+			continue
+		}
+		color := internal.White
+		if !filterSet[white] && filterSet[black] {
+			color = internal.Black
+		}
+		g.score = r.Score(color)
+		g.played, g.timed = gamePlayedAt(date, endDate, endTime, utcDate, utcTime)
+		games = append(games, g)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read games: %w", err)
+	}
+
+	sort.SliceStable(games, func(i, j int) bool {
+		if !games[i].played.Equal(games[j].played) {
+			return games[i].played.Before(games[j].played)
+		}
+		return games[i].id < games[j].id
+	})
+	return computeStreaks(games), nil
+}
+
+// computeStreaks computes the streaks and sessions of games, in the order
+// they were played.
+func computeStreaks(games []streakGame) *Streaks {
+	s := &Streaks{Games: len(games)}
+	session := 0
+	for i, g := range games {
+		s.Overall.add(g.score)
+
+		switch {
+		case g.score == 1 && s.CurrentStreak > 0:
+			s.CurrentStreak++
+		case g.score == 1:
+			s.CurrentStreak = 1
+		case g.score == 0 && s.CurrentStreak < 0:
+			s.CurrentStreak--
+		case g.score == 0:
+			s.CurrentStreak = -1
+		default:
+			s.CurrentStreak = 0
+		}
+		s.LongestWinStreak = max(s.LongestWinStreak, s.CurrentStreak)
+		s.LongestLossStreak = max(s.LongestLossStreak, -s.CurrentStreak)
+
+		if i > 0 && sameSession(games[i-1], g) {
+			session++
+			switch games[i-1].score {
+			case 1:
+				s.AfterWin.add(g.score)
+			case 0:
+				s.AfterLoss.add(g.score)
+			}
+		} else {
+			s.Sessions++
+			session = 1
+		}
+		s.LongestSession = max(s.LongestSession, session)
+	}
+	if s.Sessions > 0 {
+		s.GamesPerSession = float64(s.Games) / float64(s.Sessions)
+	}
+	return s
+}
@@ -0,0 +1,84 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/kyleboon/gochess/internal/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGamePlayedAt(t *testing.T) {
+	played, timed := gamePlayedAt("2024.01.01", "2024.01.02", "00:10:00", "", "")
+	assert.True(t, timed)
+	assert.Equal(t, "2024-01-02T00:10:00Z", played.Format("2006-01-02T15:04:05Z07:00"))
+
+	played, timed = gamePlayedAt("2024.01.01", "", "", "2024.01.01", "20:00:00")
+	assert.True(t, timed)
+	assert.Equal(t, 20, played.Hour())
+
+	played, timed = gamePlayedAt("2024.01.01", "", "", "", "")
+	assert.False(t, timed)
+	assert.Equal(t, 1, played.Day())
+
+	played, timed = gamePlayedAt("????.??.??", "", "", "", "")
+	assert.False(t, timed)
+	assert.True(t, played.IsZero())
+}
+
+func TestGetStreaks(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "gochess-streaks-test-")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(tempDir) }()
+
+	database, err := NewWithLogger(tempDir+"/test.db", logging.Discard())
+	require.NoError(t, err)
+	defer func() { _ = database.Close() }()
+	ctx := context.Background()
+
+	// Alice's results in order: W W L L L D W, in two sessions: the first
+	// five games in one evening, the last two the next day. The games are
+	// imported out of order.
+	games := []struct {
+		time, white, black, result string
+	}{
+		{"2024.01.01 20:30:00", "Bob", "Alice", "1-0"},
+		{"2024.01.01 20:00:00", "Alice", "Bob", "1-0"},
+		{"2024.01.01 20:10:00", "Bob", "Alice", "0-1"},
+		{"2024.01.01 20:20:00", "Alice", "Bob", "0-1"},
+		{"2024.01.01 21:15:00", "Alice", "Carol", "0-1"},
+		{"2024.01.02 09:00:00", "Carol", "Alice", "1/2-1/2"},
+		{"2024.01.02 09:30:00", "Alice", "Carol", "1-0"},
+		{"2024.01.02 10:00:00", "Alice", "Carol", "*"},
+	}
+	var pgnText []string
+	for i, g := range games {
+		date, clock, _ := strings.Cut(g.time, " ")
+		pgnText = append(pgnText, fmt.Sprintf(`[Event "%d"] [Site "?"] [Date "%s"] [White "%s"] [Black "%s"] [Result "%s"] [EndDate "%s"] [EndTime "%s"] 1. e4 e5 %s`,
+			i, date, g.white, g.black, g.result, date, clock, g.result))
+	}
+	pgnFile := tempDir + "/games.pgn"
+	require.NoError(t, os.WriteFile(pgnFile, []byte(strings.Join(pgnText, "\n\n")), 0644))
+	_, errs := database.ImportPGN(ctx, pgnFile)
+	require.Empty(t, errs)
+
+	streaks, err := database.GetStreaks(ctx, []string{"Alice"})
+	require.NoError(t, err)
+	assert.Equal(t, 7, streaks.Games)
+	assert.Equal(t, ResultCounts{Games: 7, Wins: 3, Losses: 3, Draws: 1, WinRate: 3.0 / 7 * 100}, streaks.Overall)
+	assert.Equal(t, 2, streaks.LongestWinStreak)
+	assert.Equal(t, 3, streaks.LongestLossStreak)
+	assert.Equal(t, 1, streaks.CurrentStreak)
+	assert.Equal(t, ResultCounts{Games: 2, Wins: 1, Losses: 1, WinRate: 50}, streaks.AfterWin)
+	assert.Equal(t, ResultCounts{Games: 2, Losses: 2}, streaks.AfterLoss)
+	assert.Equal(t, 2, streaks.Sessions)
+	assert.Equal(t, 3.5, streaks.GamesPerSession)
+	assert.Equal(t, 5, streaks.LongestSession)
+
+	_, err = database.GetStreaks(ctx, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,30 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// TagCriterion prefixes a search criterion on a PGN tag, as in
+// criteria[TagCriterion+"ECO"] = "B90". Tags are matched by exact name and
+// value, through the index on the tags table.
+const TagCriterion = "tag:"
+
+// ParseTagFilter splits a tag filter of the form Name=Value, as given to
+// db list --tag, into the tag's name and value.
+func ParseTagFilter(filter string) (name, value string, err error) {
+	name, value, ok := strings.Cut(filter, "=")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" {
+		return "", "", fmt.Errorf("invalid tag filter %q: expected Name=Value, as in ECO=B90", filter)
+	}
+	return name, value, nil
+}
+
+// SearchByTag returns the games with a PGN tag of the given name and value,
+// the latest first.
+func (db *DB) SearchByTag(ctx context.Context, name, value string) ([]map[string]interface{}, error) {
+	// A negative limit has SQLite return every game
+	return db.SearchGames(ctx, map[string]string{TagCriterion + name: value}, -1, 0)
+}
@@ -0,0 +1,98 @@
+package db
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTagFilter(t *testing.T) {
+	name, value, err := ParseTagFilter("Termination=won on time")
+	require.NoError(t, err)
+	assert.Equal(t, "Termination", name)
+	assert.Equal(t, "won on time", value)
+
+	name, value, err = ParseTagFilter("Annotator=")
+	require.NoError(t, err)
+	assert.Equal(t, "Annotator", name)
+	assert.Equal(t, "", value)
+
+	_, _, err = ParseTagFilter("ECO")
+	assert.Error(t, err)
+	_, _, err = ParseTagFilter("=B90")
+	assert.Error(t, err)
+}
+
+func TestSearchByTag(t *testing.T) {
+	database, err := NewInMemory()
+	require.NoError(t, err)
+	defer func() { _ = database.Close() }()
+	ctx := context.Background()
+
+	pgn := `[Event "Open"] [Date "2024.01.01"] [White "Alice"] [Black "Bob"] [Result "1-0"] [ECO "B90"] [Termination "won on time"] 1. e4 c5 1-0
+
+[Event "Open"] [Date "2024.01.02"] [White "Carol"] [Black "Alice"] [Result "0-1"] [ECO "B90"] [Termination "won by resignation"] 1. e4 c5 0-1
+
+[Event "Open"] [Date "2024.01.03"] [White "Bob"] [Black "Carol"] [Result "1/2-1/2"] [ECO "D02"] 1. d4 d5 1/2-1/2`
+	result := database.ImportPGNReader(ctx, "test", strings.NewReader(pgn), ImportOptions{})
+	require.Empty(t, result.Errors)
+	require.Equal(t, 3, result.Imported)
+
+	games, err := database.SearchByTag(ctx, "ECO", "B90")
+	require.NoError(t, err)
+	require.Len(t, games, 2)
+	assert.Equal(t, "Carol", games[0]["white"], "the latest first")
+
+	games, err = database.SearchByTag(ctx, "ECO", "b90")
+	require.NoError(t, err)
+	assert.Empty(t, games, "values are matched exactly")
+
+	// Tag criteria combine with each other and the other criteria
+	games, err = database.SearchGames(ctx, map[string]string{
+		TagCriterion + "ECO":         "B90",
+		TagCriterion + "Termination": "won on time",
+		"black":                      "Bob",
+	}, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, games, 1)
+	assert.Equal(t, "Alice", games[0]["white"])
+}
+
+func TestImportTags(t *testing.T) {
+	database, err := NewInMemory()
+	require.NoError(t, err)
+	defer func() { _ = database.Close() }()
+	ctx := context.Background()
+
+	blitz := `[Event "Live"] [Date "2024.01.01"] [White "alice"] [Black "Bob"] [Result "1-0"] 1. e4 e5 1-0
+
+[Event "Live"] [Date "2024.01.02"] [White "Carol"] [Black "alice"] [Result "0-1"] [SourceAccount "other"] 1. d4 d5 0-1`
+	result := database.ImportPGNReader(ctx, "blitz", strings.NewReader(blitz),
+		ImportOptions{Tags: SourceAccountTags("chesscom", "alice")})
+	require.Empty(t, result.Errors)
+	require.Equal(t, 2, result.Imported)
+
+	daily := `[Event "Daily"] [Date "2024.01.03"] [White "alice_daily"] [Black "Dan"] [Result "1-0"] 1. c4 e5 1-0
+
+[Event "Live"] [Date "2024.01.01"] [White "alice"] [Black "Bob"] [Result "1-0"] 1. e4 e5 1-0`
+	result = database.ImportPGNReader(ctx, "daily", strings.NewReader(daily),
+		ImportOptions{Tags: SourceAccountTags("chesscom", "alice_daily")})
+	require.Empty(t, result.Errors)
+	require.Equal(t, 1, result.Imported)
+
+	games, err := database.SearchByTag(ctx, SourceAccountTag, "chesscom:alice")
+	require.NoError(t, err)
+	require.Len(t, games, 1, "a tag in the PGN is kept")
+	assert.Equal(t, "Bob", games[0]["black"])
+
+	games, err = database.SearchByTag(ctx, SourceAccountTag, "chesscom:alice_daily")
+	require.NoError(t, err)
+	require.Len(t, games, 1, "a duplicate keeps the account it was first imported for")
+	game, err := database.GetGame(ctx, games[0]["id"].(int))
+	require.NoError(t, err)
+	assert.Equal(t, "chesscom:alice_daily", game.Tags[SourceAccountTag])
+	assert.NotContains(t, game.PGNText, SourceAccountTag)
+}
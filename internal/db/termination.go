@@ -0,0 +1,154 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/kyleboon/gochess/internal"
+	"github.com/kyleboon/gochess/internal/pgn"
+)
+
+// TerminationStats counts how the games of players ended, for one way a game
+// can end. When filtered by players the wins and losses are theirs;
+// otherwise they are White's.
+type TerminationStats struct {
+	Termination string `json:"termination"` // One of the names of pgn.Terminations, or "unknown"
+	Games       int    `json:"games"`       // Games that ended this way
+	Wins        int    `json:"wins"`        // Games won
+	Losses      int    `json:"losses"`      // Games lost
+	Draws       int    `json:"draws"`       // Games drawn
+}
+
+// gameTermination returns how a game ended from its Termination tag, its
+// result and its final position, TerminationUnknown if it cannot be told.
+// When the tag does not tell, a finished game ended by checkmate or
+// stalemate if the final position is one and the result agrees. Only a tag
+// of "Normal", as Lichess writes for a game that was neither lost on time
+// nor abandoned, says more: a won game was then resigned and a drawn game
+// agreed, unless neither side could mate. Without a tag nothing else is
+// assumed, so that games lost on time are not taken for resignations.
+func gameTermination(tag string, result pgn.Result, final *internal.Board) pgn.Termination {
+	if t := pgn.ParseTermination(tag); t != pgn.TerminationUnknown {
+		return t
+	}
+	if !result.Finished() {
+		return pgn.TerminationUnknown
+	}
+	if final != nil {
+		if check, mate := final.IsCheckOrMate(); mate {
+			switch {
+			case check && result != pgn.Draw:
+				return pgn.Checkmate
+			case !check && result == pgn.Draw:
+				return pgn.Stalemate
+			}
+			return pgn.TerminationUnknown
+		}
+	}
+	if !strings.EqualFold(strings.TrimSpace(tag), "normal") || final == nil {
+		return pgn.TerminationUnknown
+	}
+	switch {
+	case result != pgn.Draw:
+		return pgn.Resignation
+	case final.InsufficientMaterial():
+		return pgn.InsufficientMaterial
+	}
+	return pgn.Agreement
+}
+
+// GetTerminationStats retrieves how the games of the given players ended,
+// in the order of pgn.Terminations with unknown terminations last. If
+// players is empty, every game is counted from White's point of view. If
+// timeClass is not empty, only games of that time class are counted.
+// Unfinished games are left out.
+func (db *DB) GetTerminationStats(ctx context.Context, players []string, timeClass string) ([]TerminationStats, error) {
+	query := `
+		SELECT g.white, g.black, g.result, COALESCE(t.tag_value, ''), COALESCE(p.fen, '')
+		FROM games g
+		LEFT JOIN tags t ON t.game_id = g.id AND t.tag_name = 'Termination'
+		LEFT JOIN positions p ON p.game_id = g.id
+			AND p.move_number = (SELECT MAX(move_number) FROM positions WHERE game_id = g.id)
+		WHERE g.deleted_at IS NULL
+	`
+	var args []interface{}
+	if len(players) > 0 {
+		placeholders := make([]string, len(players))
+		for i, player := range players {
+			placeholders[i] = "?"
+			args = append(args, player)
+		}
+		playerList := strings.Join(placeholders, ",")
+		query += fmt.Sprintf(" AND (g.white IN (%s) OR g.black IN (%s))", playerList, playerList)
+		args = append(args, args...) // Duplicate args for both IN clauses
+	}
+	if timeClass != "" {
+		query += " AND g.time_class = ?"
+		args = append(args, timeClass)
+	}
+
+	rows, err := db.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query games: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	filterSet := make(map[string]bool)
+	for _, player := range players {
+		filterSet[player] = true
+	}
+	isFiltered := len(players) > 0
+
+	byTermination := make(map[pgn.Termination]*TerminationStats)
+	for rows.Next() {
+		var white, black, resultTag, tag, fen string
+		if err := rows.Scan(&white, &black, &resultTag, &tag, &fen); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		result := pgn.ParseResult(resultTag)
+		if !result.Finished() {
+			continue
+		}
+		var final *internal.Board
+		if fen != "" {
+			if final, err = internal.ParseFen(fen); err != nil {
+				db.logger.Debug("skipping invalid final position", "fen", fen, "error", err)
+				final = nil
+			}
+		}
+		termination := gameTermination(tag, result, final)
+		stats := byTermination[termination]
+		if stats == nil {
+			stats = &TerminationStats{Termination: termination.String()}
+			byTermination[termination] = stats
+		}
+
+		for color, name := range []string{white, black} {
+			if isFiltered && !filterSet[name] || !isFiltered && color == internal.Black {
+				continue
+			}
+			stats.Games++
+			switch result.Score(color) {
+			case 1:
+				stats.Wins++
+			case 0:
+				stats.Losses++
+			default:
+				stats.Draws++
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	var results []TerminationStats
+	for _, t := range slices.Concat(pgn.Terminations, []pgn.Termination{pgn.TerminationUnknown}) {
+		if stats := byTermination[t]; stats != nil && stats.Games > 0 {
+			results = append(results, *stats)
+		}
+	}
+	return results, nil
+}
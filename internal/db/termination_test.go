@@ -0,0 +1,87 @@
+package db
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/kyleboon/gochess/internal"
+	"github.com/kyleboon/gochess/internal/logging"
+	"github.com/kyleboon/gochess/internal/pgn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGameTermination(t *testing.T) {
+	mated, err := internal.ParseFen("rnb1kbnr/pppp1ppp/8/4p3/6Pq/5P2/PPPPP2P/RNBQKBNR w KQkq - 1 3")
+	require.NoError(t, err)
+	start, err := internal.ParseFen("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1")
+	require.NoError(t, err)
+	bareKings, err := internal.ParseFen("8/8/4k3/8/8/4K3/8/8 w - - 0 60")
+	require.NoError(t, err)
+
+	assert.Equal(t, pgn.Timeout, gameTermination("Alice won on time", pgn.BlackWin, mated))
+	assert.Equal(t, pgn.Checkmate, gameTermination("Normal", pgn.BlackWin, mated))
+	assert.Equal(t, pgn.Checkmate, gameTermination("", pgn.BlackWin, mated))
+	assert.Equal(t, pgn.TerminationUnknown, gameTermination("", pgn.Draw, mated), "the result contradicts the mate")
+	assert.Equal(t, pgn.Resignation, gameTermination("Normal", pgn.WhiteWin, start))
+	assert.Equal(t, pgn.Agreement, gameTermination("Normal", pgn.Draw, start))
+	assert.Equal(t, pgn.InsufficientMaterial, gameTermination("Normal", pgn.Draw, bareKings))
+	assert.Equal(t, pgn.TerminationUnknown, gameTermination("Normal", pgn.WhiteWin, nil))
+
+	// Without a tag that tells, a game not ended on the board is unknown:
+	// it may have been lost on time
+	assert.Equal(t, pgn.TerminationUnknown, gameTermination("", pgn.WhiteWin, start))
+	assert.Equal(t, pgn.TerminationUnknown, gameTermination("", pgn.Draw, start))
+	assert.Equal(t, pgn.TerminationUnknown, gameTermination("", pgn.Draw, bareKings))
+	assert.Equal(t, pgn.TerminationUnknown, gameTermination("Unterminated", pgn.WhiteWin, start))
+	assert.Equal(t, pgn.TerminationUnknown, gameTermination("", pgn.Ongoing, start))
+	assert.Equal(t, pgn.TerminationUnknown, gameTermination("", pgn.WhiteWin, nil))
+}
+
+func TestGetTerminationStats(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "gochess-termination-test-")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(tempDir) }()
+
+	database, err := NewWithLogger(tempDir+"/test.db", logging.Discard())
+	require.NoError(t, err)
+	defer func() { _ = database.Close() }()
+	ctx := context.Background()
+
+	games := []string{
+		`[Event "1"] [Site "?"] [Date "2024.01.01"] [White "Alice"] [Black "Bob"] [Result "0-1"] [Termination "Bob won on time"] [TimeControl "180"] 1. e4 e5 0-1`,
+		`[Event "2"] [Site "?"] [Date "2024.01.02"] [White "Bob"] [Black "Alice"] [Result "1-0"] [Termination "Normal"] [TimeControl "180"] 1. e4 e5 2. Qh5 Nc6 3. Bc4 Nf6 4. Qxf7# 1-0`,
+		`[Event "3"] [Site "?"] [Date "2024.01.03"] [White "Alice"] [Black "Bob"] [Result "1-0"] [Termination "Normal"] [TimeControl "600"] 1. d4 d5 1-0`,
+		`[Event "4"] [Site "?"] [Date "2024.01.04"] [White "Alice"] [Black "Bob"] [Result "0-1"] [Termination "Bob won on time"] [TimeControl "600"] 1. c4 c5 0-1`,
+		`[Event "5"] [Site "?"] [Date "2024.01.05"] [White "Alice"] [Black "Bob"] [Result "*"] [TimeControl "600"] 1. Nf3 *`,
+		`[Event "6"] [Site "?"] [Date "2024.01.06"] [White "Bob"] [Black "Alice"] [Result "0-1"] 1. e4 e5 0-1`,
+	}
+	pgnFile := tempDir + "/games.pgn"
+	require.NoError(t, os.WriteFile(pgnFile, []byte(strings.Join(games, "\n\n")), 0644))
+	_, errs := database.ImportPGN(ctx, pgnFile)
+	require.Empty(t, errs)
+
+	stats, err := database.GetTerminationStats(ctx, []string{"Alice"}, "")
+	require.NoError(t, err)
+	assert.Equal(t, []TerminationStats{
+		{Termination: "checkmate", Games: 1, Losses: 1},
+		{Termination: "resignation", Games: 1, Wins: 1},
+		{Termination: "timeout", Games: 2, Losses: 2},
+		{Termination: "unknown", Games: 1, Wins: 1},
+	}, stats)
+
+	stats, err = database.GetTerminationStats(ctx, []string{"Alice"}, "blitz")
+	require.NoError(t, err)
+	assert.Equal(t, []TerminationStats{
+		{Termination: "checkmate", Games: 1, Losses: 1},
+		{Termination: "timeout", Games: 1, Losses: 1},
+	}, stats)
+
+	// Without players the results are White's
+	stats, err = database.GetTerminationStats(ctx, nil, "")
+	require.NoError(t, err)
+	require.Len(t, stats, 4)
+	assert.Equal(t, TerminationStats{Termination: "checkmate", Games: 1, Wins: 1}, stats[0])
+}
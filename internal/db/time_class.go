@@ -0,0 +1,109 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Time classes of games, by how long a game is expected to last
+const (
+	TimeClassBullet    = "bullet"
+	TimeClassBlitz     = "blitz"
+	TimeClassRapid     = "rapid"
+	TimeClassClassical = "classical"
+	TimeClassDaily     = "daily" // correspondence, with days per move
+)
+
+// TimeClasses lists the time classes from fastest to slowest.
+var TimeClasses = []string{TimeClassBullet, TimeClassBlitz, TimeClassRapid, TimeClassClassical, TimeClassDaily}
+
+// ValidateTimeClass returns an error unless class is one of TimeClasses.
+func ValidateTimeClass(class string) error {
+	if slices.Contains(TimeClasses, class) {
+		return nil
+	}
+	return fmt.Errorf("unknown time class %q; use %s", class, strings.Join(TimeClasses, ", "))
+}
+
+// TimeClass returns the time class of a PGN TimeControl tag, "" if it
+// cannot be told. Like Chess.com and Lichess, live games are classed by
+// their expected duration, the base time plus 40 increments: under 3
+// minutes is bullet, under 10 blitz, under an hour rapid, and longer
+// classical. Correspondence controls ("1/86400", or "-" from Lichess) are
+// daily.
+func TimeClass(timeControl string) string {
+	timeControl = strings.TrimSpace(timeControl)
+	switch {
+	case timeControl == "" || timeControl == "?":
+		return ""
+	case timeControl == "-" || strings.Contains(timeControl, "/"):
+		return TimeClassDaily
+	}
+
+	base, increment, _ := strings.Cut(timeControl, "+")
+	baseTime, err := strconv.Atoi(base)
+	if err != nil {
+		return ""
+	}
+	incrementTime := 0
+	if increment != "" {
+		if incrementTime, err = strconv.Atoi(increment); err != nil {
+			return ""
+		}
+	}
+
+	switch duration := baseTime + 40*incrementTime; {
+	case duration < 180:
+		return TimeClassBullet
+	case duration < 600:
+		return TimeClassBlitz
+	case duration < 3600:
+		return TimeClassRapid
+	}
+	return TimeClassClassical
+}
+
+// gameTimeClass returns the time class of a game from its tags: the
+// TimeClass tag if it holds one, as Chess.com's time_class is recorded,
+// and otherwise the class of its TimeControl tag.
+func gameTimeClass(tags map[string]string) string {
+	if class := strings.ToLower(tags["TimeClass"]); ValidateTimeClass(class) == nil {
+		return class
+	}
+	return TimeClass(tags["TimeControl"])
+}
+
+// classifyTimeClasses sets the time class of the games stored before time
+// classes were, from their time controls.
+func (db *DB) classifyTimeClasses(ctx context.Context) error {
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT id, COALESCE(time_control, '') FROM games WHERE time_class IS NULL
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to query unclassified games: %w", err)
+	}
+	classes := make(map[int]string)
+	for rows.Next() {
+		var id int
+		var timeControl string
+		if err := rows.Scan(&id, &timeControl); err != nil {
+			_ = rows.Close()
+			return fmt.Errorf("failed to scan game: %w", err)
+		}
+		classes[id] = TimeClass(timeControl)
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read games: %w", err)
+	}
+
+	for id, class := range classes {
+		if _, err := db.conn.ExecContext(ctx, "UPDATE games SET time_class = ? WHERE id = ?", class, id); err != nil {
+			return fmt.Errorf("failed to set time class: %w", err)
+		}
+	}
+	return nil
+}
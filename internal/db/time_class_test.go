@@ -0,0 +1,95 @@
+package db
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/kyleboon/gochess/internal/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeClass(t *testing.T) {
+	tests := []struct {
+		timeControl string
+		want        string
+	}{
+		{"", ""},
+		{"?", ""},
+		{"invalid", ""},
+		{"60", "bullet"},
+		{"120+1", "bullet"},
+		{"60+3", "blitz"},
+		{"180+2", "blitz"},
+		{"300", "blitz"},
+		{"600", "rapid"},
+		{"900+10", "rapid"},
+		{"3600", "classical"},
+		{"5400+30", "classical"},
+		{"1/86400", "daily"},
+		{"-", "daily"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, TimeClass(tt.timeControl), "time control %q", tt.timeControl)
+	}
+
+	assert.NoError(t, ValidateTimeClass("daily"))
+	assert.Error(t, ValidateTimeClass("hyperbullet"))
+	assert.Equal(t, "rapid", gameTimeClass(map[string]string{"TimeClass": "rapid", "TimeControl": "180"}))
+	assert.Equal(t, "blitz", gameTimeClass(map[string]string{"TimeClass": "?", "TimeControl": "180"}))
+}
+
+func TestTimeClassFilters(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "gochess-time-class-test-")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(tempDir) }()
+
+	dbPath := tempDir + "/test.db"
+	database, err := NewWithLogger(dbPath, logging.Discard())
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	games := []string{
+		`[Event "1"] [Site "?"] [Date "2024.01.01"] [White "Alice"] [Black "Bob"] [Result "1-0"] [TimeControl "180+2"] 1. e4 e5 1-0`,
+		`[Event "2"] [Site "?"] [Date "2024.01.02"] [White "Alice"] [Black "Bob"] [Result "0-1"] [TimeControl "600"] 1. d4 d5 0-1`,
+		`[Event "3"] [Site "?"] [Date "2024.01.03"] [White "Bob"] [Black "Alice"] [Result "1-0"] [TimeControl "1/86400"] 1. e4 c5 1-0`,
+	}
+	pgnFile := tempDir + "/games.pgn"
+	require.NoError(t, os.WriteFile(pgnFile, []byte(strings.Join(games, "\n\n")), 0644))
+	_, errs := database.ImportPGN(ctx, pgnFile)
+	require.Empty(t, errs)
+
+	game, err := database.GetGameByID(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, "rapid", game["time_class"])
+
+	found, err := database.SearchGames(ctx, map[string]string{"time_class": "blitz"}, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	assert.Equal(t, 1, found[0]["id"])
+
+	stats, err := database.GetPlayerStatsForTimeClass(ctx, []string{"Alice"}, "rapid")
+	require.NoError(t, err)
+	require.Len(t, stats, 1)
+	assert.Equal(t, 1, stats[0].Games)
+	assert.Equal(t, 1, stats[0].Losses)
+
+	tree, err := database.GetOpeningTreeFiltered(ctx, []string{"Alice"}, "", "daily", 2, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, tree.Games)
+
+	// Games stored before time classes are classified when the database
+	// is opened
+	_, err = database.conn.Exec("UPDATE games SET time_class = NULL")
+	require.NoError(t, err)
+	require.NoError(t, database.Close())
+	database, err = NewWithLogger(dbPath, logging.Discard())
+	require.NoError(t, err)
+	defer func() { _ = database.Close() }()
+	found, err = database.SearchGames(ctx, map[string]string{"time_class": "daily"}, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	assert.Equal(t, 3, found[0]["id"])
+}
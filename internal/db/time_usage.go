@@ -0,0 +1,205 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kyleboon/gochess/internal/pgn"
+)
+
+const (
+	// timeUsageMaxMove is the last move number tracked by the time-usage
+	// statistics. Later moves are rare enough to only add noise.
+	timeUsageMaxMove = 60
+
+	// timeUsageWindow is the number of consecutive moves over which the
+	// heaviest clock use is reported.
+	timeUsageWindow = 5
+)
+
+// TimeUsageStats summarizes how a player uses the clock, overall or within one
+// opening. Only games with clock comments and a parseable TimeControl tag are
+// counted.
+type TimeUsageStats struct {
+	ECOCode          string  `json:"eco_code"`           // ECO code, empty for the overall summary
+	OpeningName      string  `json:"opening_name"`       // Opening name, empty for the overall summary
+	Games            int     `json:"games"`              // Games with clock data
+	AvgMoveSeconds   float64 `json:"avg_move_seconds"`   // Average thinking time per move in seconds
+	PeakStart        int     `json:"peak_start"`         // First move number of the heaviest five-move stretch
+	PeakEnd          int     `json:"peak_end"`           // Last move number of the heaviest five-move stretch
+	PeakShare        float64 `json:"peak_share"`         // Average share of the starting clock spent in that stretch (0-100)
+	TimeTroubleGames int     `json:"time_trouble_games"` // Games in which the player fell into time trouble
+
+	// share[i] accumulates the share of the starting clock spent on move i+1
+	share      [timeUsageMaxMove]float64
+	moves      int
+	spentTotal float64
+}
+
+// GetTimeUsageStats retrieves clock usage statistics for all players.
+func (db *DB) GetTimeUsageStats(ctx context.Context) (*TimeUsageStats, []TimeUsageStats, error) {
+	return db.GetTimeUsageStatsFiltered(ctx, []string{})
+}
+
+// GetTimeUsageStatsFiltered retrieves clock usage statistics for the given
+// players, both overall and broken down by opening. Openings are sorted by
+// number of games. If players is empty, both sides of every game are counted.
+func (db *DB) GetTimeUsageStatsFiltered(ctx context.Context, players []string) (*TimeUsageStats, []TimeUsageStats, error) {
+	query := `
+		SELECT white, black, COALESCE(eco_code, ''), COALESCE(opening_name, ''), pgn_text
+		FROM games
+		WHERE deleted_at IS NULL
+		AND time_control IS NOT NULL AND time_control != ''
+		AND pgn_text LIKE '%[%clk %'
+	`
+	var args []interface{}
+	if len(players) > 0 {
+		placeholders := make([]string, len(players))
+		for i, player := range players {
+			placeholders[i] = "?"
+			args = append(args, player)
+		}
+		playerList := strings.Join(placeholders, ",")
+		query += fmt.Sprintf(" AND (white IN (%s) OR black IN (%s))", playerList, playerList)
+		args = append(args, args...) // Duplicate args for both IN clauses
+	}
+
+	rows, err := db.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query games: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	filterSet := make(map[string]bool)
+	for _, player := range players {
+		filterSet[player] = true
+	}
+	isFiltered := len(players) > 0
+
+	overall := &TimeUsageStats{}
+	byOpening := make(map[string]*TimeUsageStats)
+
+	for rows.Next() {
+		var white, black, ecoCode, openingName, pgnText string
+		if err := rows.Scan(&white, &black, &ecoCode, &openingName, &pgnText); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		game, err := parseStoredGame(pgnText)
+		if err != nil {
+			db.logger.Debug("skipping game for time usage", "error", err)
+			continue
+		}
+		base, _, ok := pgn.ParseTimeControl(game.Tags["TimeControl"])
+		if !ok {
+			continue
+		}
+		times := game.MoveTimes()
+		if len(times) == 0 {
+			continue
+		}
+
+		stats := byOpening[ecoCode]
+		if ecoCode != "" && stats == nil {
+			stats = &TimeUsageStats{ECOCode: ecoCode, OpeningName: openingName}
+			byOpening[ecoCode] = stats
+		}
+
+		for color, name := range []string{white, black} {
+			if isFiltered && !filterSet[name] {
+				continue
+			}
+			overall.addGame(times, color, base)
+			if stats != nil {
+				stats.addGame(times, color, base)
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	overall.finish()
+	result := make([]TimeUsageStats, 0, len(byOpening))
+	for _, stats := range byOpening {
+		if stats.Games == 0 {
+			continue
+		}
+		stats.finish()
+		result = append(result, *stats)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Games != result[j].Games {
+			return result[i].Games > result[j].Games
+		}
+		return result[i].ECOCode < result[j].ECOCode
+	})
+
+	return overall, result, nil
+}
+
+// addGame accumulates the moves of one side of a game.
+func (s *TimeUsageStats) addGame(times []pgn.MoveTime, color int, base time.Duration) {
+	threshold := pgn.TimeTroubleThreshold(base)
+	inTrouble := false
+	for _, t := range times {
+		if t.Color != color {
+			continue
+		}
+		if moveNr := (t.Ply + 1) / 2; moveNr <= timeUsageMaxMove {
+			s.share[moveNr-1] += t.Spent.Seconds() / base.Seconds() * 100
+		}
+		s.moves++
+		s.spentTotal += t.Spent.Seconds()
+		if t.Clock < threshold {
+			inTrouble = true
+		}
+	}
+	s.Games++
+	if inTrouble {
+		s.TimeTroubleGames++
+	}
+}
+
+// finish computes the averages once all games have been added.
+func (s *TimeUsageStats) finish() {
+	if s.moves > 0 {
+		s.AvgMoveSeconds = s.spentTotal / float64(s.moves)
+	}
+	if s.Games == 0 {
+		return
+	}
+	best := -1.0
+	for start := 0; start+timeUsageWindow <= timeUsageMaxMove; start++ {
+		sum := 0.0
+		for _, v := range s.share[start : start+timeUsageWindow] {
+			sum += v
+		}
+		if sum > best {
+			best = sum
+			s.PeakStart, s.PeakEnd = start+1, start+timeUsageWindow
+		}
+	}
+	s.PeakShare = best / float64(s.Games)
+}
+
+// parseStoredGame parses a single game, including its moves, from PGN text
+// stored in the database.
+func parseStoredGame(text string) (*pgn.Game, error) {
+	pgnDB := &pgn.DB{}
+	errs := pgnDB.Parse(text)
+	if len(pgnDB.Games) == 0 {
+		if len(errs) > 0 {
+			return nil, errs[0]
+		}
+		return nil, fmt.Errorf("no game found")
+	}
+	game := pgnDB.Games[0]
+	if err := pgnDB.ParseMoves(game); err != nil {
+		return nil, err
+	}
+	return game, nil
+}
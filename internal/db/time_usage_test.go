@@ -0,0 +1,99 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/kyleboon/gochess/internal/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetTimeUsageStatsFiltered(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "gochess-test-time-usage-")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(tempDir) }()
+
+	db, err := NewWithLogger(tempDir+"/test.db", logging.Discard())
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	ctx := context.Background()
+
+	pgnContent := `[Event "Game 1"]
+[Site "Test"]
+[Date "2024.01.01"]
+[White "Opponent"]
+[Black "Me"]
+[Result "1-0"]
+[TimeControl "60"]
+
+1. e4 {[%clk 0:01:00]} 1... c6 {[%clk 0:00:58]} 2. d4 {[%clk 0:00:59]} 2... d5 {[%clk 0:00:40]} 3. e5 {[%clk 0:00:58]} 3... Bf5 {[%clk 0:00:04]} 1-0
+
+[Event "Game 2"]
+[Site "Test"]
+[Date "2024.01.02"]
+[White "Me"]
+[Black "Opponent"]
+[Result "1-0"]
+[TimeControl "60"]
+
+1. d4 d5 2. c4 e6 1-0
+
+[Event "Game 3"]
+[Site "Test"]
+[Date "2024.01.03"]
+[White "Someone"]
+[Black "Else"]
+[Result "0-1"]
+[TimeControl "60"]
+
+1. e4 {[%clk 0:00:59]} 1... e5 {[%clk 0:00:59]} 0-1
+`
+	pgnFile := tempDir + "/test.pgn"
+	require.NoError(t, os.WriteFile(pgnFile, []byte(pgnContent), 0644))
+	count, errs := db.ImportPGN(ctx, pgnFile)
+	require.Empty(t, errs)
+	require.Equal(t, 3, count)
+
+	t.Run("Filtered to one player", func(t *testing.T) {
+		overall, byOpening, err := db.GetTimeUsageStatsFiltered(ctx, []string{"Me"})
+		require.NoError(t, err)
+		require.NotNil(t, overall)
+
+		// Only game 1 has clock data for Me
+		assert.Equal(t, 1, overall.Games)
+		assert.Equal(t, 1, overall.TimeTroubleGames)
+		assert.InDelta(t, 56.0/3, overall.AvgMoveSeconds, 0.01)
+		assert.Equal(t, 1, overall.PeakStart)
+		assert.Equal(t, 5, overall.PeakEnd)
+		assert.InDelta(t, 56.0/60*100, overall.PeakShare, 0.01)
+
+		require.Len(t, byOpening, 1)
+		assert.True(t, strings.HasPrefix(byOpening[0].ECOCode, "B1"), "expected a Caro-Kann ECO code, got %s", byOpening[0].ECOCode)
+		assert.Equal(t, 1, byOpening[0].Games)
+	})
+
+	t.Run("All players", func(t *testing.T) {
+		overall, _, err := db.GetTimeUsageStats(ctx)
+		require.NoError(t, err)
+		// Both sides of games 1 and 3
+		assert.Equal(t, 4, overall.Games)
+	})
+}
+
+func TestTimeUsageStatsJSON(t *testing.T) {
+	stats := TimeUsageStats{ECOCode: "C50", Games: 2, TimeTroubleGames: 1, moves: 3, spentTotal: 4}
+	stats.share[0] = 1
+	data, err := json.Marshal(stats)
+	require.NoError(t, err)
+
+	var keys map[string]any
+	require.NoError(t, json.Unmarshal(data, &keys))
+	assert.Equal(t, "C50", keys["eco_code"])
+	assert.Equal(t, float64(1), keys["time_trouble_games"])
+	assert.Len(t, keys, 8, "only the exported statistics are written: %s", data)
+}
@@ -0,0 +1,100 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kyleboon/gochess/internal"
+)
+
+// TrainingPosition is an evaluated position of a game, as exported to train
+// evaluation and policy models.
+type TrainingPosition struct {
+	GameID   int
+	Ply      int     // half-moves played before the position
+	FEN      string  // the position
+	Eval     float64 // pawns from White's view, forced mates as ±pgn.MateEval
+	BestMove string  // the engine's move in UCI notation, "" if unknown
+	White    string
+	Black    string
+	WhiteElo int
+	BlackElo int
+	Result   string
+	Date     string
+}
+
+// TrainingPositions calls fn with every evaluated position in the database,
+// by game and ply, and returns how many there were: the positions of
+// analyzed games, including Lichess exports imported with evaluations, and
+// those evaluated with "analyze position --save". With fromAnalysis, only
+// analyzed games are included, with the best move of their latest analysis.
+func (db *DB) TrainingPositions(ctx context.Context, fromAnalysis bool, fn func(TrainingPosition) error) (int, error) {
+	query := `
+		SELECT g.id, p.move_number, p.fen, p.evaluation, '',
+			COALESCE(g.white, ''), COALESCE(g.black, ''),
+			COALESCE(g.white_elo, 0), COALESCE(g.black_elo, 0),
+			COALESCE(g.result, ''), COALESCE(g.date, '')
+		FROM positions p
+		JOIN games g ON g.id = p.game_id
+		WHERE p.evaluation IS NOT NULL AND g.deleted_at IS NULL
+		ORDER BY g.id, p.move_number
+	`
+	if fromAnalysis {
+		// The best move of the analysis of ply n is played from position n-1
+		query = `
+			SELECT g.id, p.move_number, p.fen, p.evaluation, COALESCE(m.best, ''),
+				COALESCE(g.white, ''), COALESCE(g.black, ''),
+				COALESCE(g.white_elo, 0), COALESCE(g.black_elo, 0),
+				COALESCE(g.result, ''), COALESCE(g.date, '')
+			FROM analyses a
+			JOIN games g ON g.id = a.game_id
+			JOIN positions p ON p.game_id = a.game_id
+			LEFT JOIN analysis_moves m ON m.analysis_id = a.id AND m.ply = p.move_number + 1
+			WHERE a.id = (SELECT MAX(id) FROM analyses WHERE game_id = a.game_id)
+				AND p.evaluation IS NOT NULL AND g.deleted_at IS NULL
+			ORDER BY g.id, p.move_number
+		`
+	}
+
+	rows, err := db.conn.QueryContext(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query evaluated positions: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	count := 0
+	for rows.Next() {
+		var p TrainingPosition
+		var best string
+		if err := rows.Scan(&p.GameID, &p.Ply, &p.FEN, &p.Eval, &best,
+			&p.White, &p.Black, &p.WhiteElo, &p.BlackElo, &p.Result, &p.Date); err != nil {
+			return count, fmt.Errorf("failed to scan position: %w", err)
+		}
+		if best != "" {
+			p.BestMove = uciMove(p.FEN, best)
+		}
+		if err := fn(p); err != nil {
+			return count, err
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return count, fmt.Errorf("error iterating positions: %w", err)
+	}
+	return count, nil
+}
+
+// uciMove converts a move in SAN, as analyses store them, to UCI notation
+// in the position given by fen. It returns "" if the move is not legal
+// there.
+func uciMove(fen, san string) string {
+	b, err := internal.ParseFen(fen)
+	if err != nil {
+		return ""
+	}
+	m, err := b.ParseMove(san)
+	if err != nil {
+		return ""
+	}
+	return m.Uci(b)
+}
@@ -0,0 +1,130 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// TrainingScore is the score of a session of one of the training games,
+// such as blindfold play or the square color quiz.
+type TrainingScore struct {
+	ID       int           `json:"id"`
+	Mode     string        `json:"mode"`  // As in "blindfold", "square-colors" or "knight-paths"
+	Score    int           `json:"score"` // Correct answers, or legal moves in blindfold play
+	Total    int           `json:"total"` // Questions asked, or moves tried
+	Duration time.Duration `json:"duration"`
+	PlayedAt string        `json:"played_at"`
+}
+
+// TrainingSummary sums up the sessions of a training game.
+type TrainingSummary struct {
+	Mode     string  `json:"mode"`
+	Sessions int     `json:"sessions"`
+	Best     int     `json:"best"`     // Highest score
+	Accuracy float64 `json:"accuracy"` // Percentage of correct answers over all sessions
+	Last     string  `json:"last"`     // When the last session was played
+}
+
+// createTrainingScoresTable creates the table of the scores of the training
+// games
+func (db *DB) createTrainingScoresTable() error {
+	_, err := db.conn.Exec(`
+		CREATE TABLE IF NOT EXISTS training_scores (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			mode TEXT NOT NULL,
+			score INTEGER NOT NULL,
+			total INTEGER NOT NULL,
+			duration_ms INTEGER NOT NULL DEFAULT 0,
+			played_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+		CREATE INDEX IF NOT EXISTS idx_training_scores_mode ON training_scores(mode, played_at);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create training_scores table: %w", err)
+	}
+	return nil
+}
+
+// SaveTrainingScore stores the score of a training session.
+func (db *DB) SaveTrainingScore(ctx context.Context, score TrainingScore) error {
+	if score.Mode == "" {
+		return fmt.Errorf("training score without a mode")
+	}
+	_, err := db.conn.ExecContext(ctx, `
+		INSERT INTO training_scores (mode, score, total, duration_ms) VALUES (?, ?, ?, ?)
+	`, score.Mode, score.Score, score.Total, score.Duration.Milliseconds())
+	if err != nil {
+		return fmt.Errorf("failed to save training score: %w", err)
+	}
+	return nil
+}
+
+// TrainingScores retrieves the latest scores of a training game, or of all
+// of them if mode is empty, most recent first. A limit of 0 returns every
+// score.
+func (db *DB) TrainingScores(ctx context.Context, mode string, limit int) ([]TrainingScore, error) {
+	query := `
+		SELECT id, mode, score, total, duration_ms, COALESCE(played_at, '')
+		FROM training_scores
+		WHERE ? = '' OR mode = ?
+		ORDER BY played_at DESC, id DESC
+	`
+	args := []interface{}{mode, mode}
+	if limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, limit)
+	}
+	rows, err := db.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query training scores: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var scores []TrainingScore
+	for rows.Next() {
+		var s TrainingScore
+		var ms int64
+		if err := rows.Scan(&s.ID, &s.Mode, &s.Score, &s.Total, &ms, &s.PlayedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		s.Duration = time.Duration(ms) * time.Millisecond
+		scores = append(scores, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+	return scores, nil
+}
+
+// TrainingSummaries sums up the sessions of each training game played, by
+// mode.
+func (db *DB) TrainingSummaries(ctx context.Context) ([]TrainingSummary, error) {
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT mode, COUNT(*), MAX(score), SUM(score), SUM(total), COALESCE(MAX(played_at), '')
+		FROM training_scores
+		GROUP BY mode
+		ORDER BY mode
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query training scores: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var summaries []TrainingSummary
+	for rows.Next() {
+		var s TrainingSummary
+		var correct, total int
+		if err := rows.Scan(&s.Mode, &s.Sessions, &s.Best, &correct, &total, &s.Last); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		if total > 0 {
+			s.Accuracy = 100 * float64(correct) / float64(total)
+		}
+		summaries = append(summaries, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+	return summaries, nil
+}
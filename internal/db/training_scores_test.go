@@ -0,0 +1,50 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kyleboon/gochess/internal/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrainingScores(t *testing.T) {
+	database, err := NewWithLogger(t.TempDir()+"/test.db", logging.Discard())
+	require.NoError(t, err)
+	defer func() { _ = database.Close() }()
+	ctx := context.Background()
+
+	scores, err := database.TrainingScores(ctx, "", 0)
+	require.NoError(t, err)
+	assert.Empty(t, scores)
+
+	require.NoError(t, database.SaveTrainingScore(ctx, TrainingScore{Mode: "square-colors", Score: 18, Total: 20, Duration: 42 * time.Second}))
+	require.NoError(t, database.SaveTrainingScore(ctx, TrainingScore{Mode: "square-colors", Score: 20, Total: 20, Duration: 35 * time.Second}))
+	require.NoError(t, database.SaveTrainingScore(ctx, TrainingScore{Mode: "knight-paths", Score: 7, Total: 10}))
+	assert.Error(t, database.SaveTrainingScore(ctx, TrainingScore{Score: 1, Total: 1}))
+
+	scores, err = database.TrainingScores(ctx, "square-colors", 0)
+	require.NoError(t, err)
+	require.Len(t, scores, 2)
+	assert.Equal(t, 20, scores[0].Score, "most recent first")
+	assert.Equal(t, 35*time.Second, scores[0].Duration)
+	assert.NotEmpty(t, scores[0].PlayedAt)
+
+	scores, err = database.TrainingScores(ctx, "", 1)
+	require.NoError(t, err)
+	require.Len(t, scores, 1)
+	assert.Equal(t, "knight-paths", scores[0].Mode)
+
+	summaries, err := database.TrainingSummaries(ctx)
+	require.NoError(t, err)
+	require.Len(t, summaries, 2)
+	assert.Equal(t, "knight-paths", summaries[0].Mode)
+	assert.Equal(t, 1, summaries[0].Sessions)
+	assert.InDelta(t, 70.0, summaries[0].Accuracy, 0.01)
+	assert.Equal(t, "square-colors", summaries[1].Mode)
+	assert.Equal(t, 2, summaries[1].Sessions)
+	assert.Equal(t, 20, summaries[1].Best)
+	assert.InDelta(t, 95.0, summaries[1].Accuracy, 0.01)
+}
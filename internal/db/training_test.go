@@ -0,0 +1,78 @@
+package db
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/kyleboon/gochess/internal/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrainingPositions(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "gochess-training-test-")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(tempDir) }()
+
+	database, err := NewWithLogger(tempDir+"/test.db", logging.Discard())
+	require.NoError(t, err)
+	defer func() { _ = database.Close() }()
+	ctx := context.Background()
+
+	pgnFile := tempDir + "/games.pgn"
+	require.NoError(t, os.WriteFile(pgnFile, []byte(`[Event "1"] [Site "?"] [Date "2024.01.01"] [White "Alice"] [Black "Bob"] [WhiteElo "1500"] [Result "1-0"] 1. e4 f5 2. Qh5+ 1-0
+
+[Event "2"] [Site "?"] [Date "2024.01.02"] [White "Bob"] [Black "Alice"] [Result "0-1"] 1. d4 d5 0-1`), 0644))
+	count, errs := database.ImportPGN(ctx, pgnFile)
+	require.Empty(t, errs)
+	require.Equal(t, 2, count)
+
+	require.NoError(t, database.SaveAnalysis(ctx, &GameAnalysis{
+		GameID: 1,
+		Depth:  12,
+		Lines:  1,
+		Moves: []AnalysisMove{
+			{Ply: 1, SAN: "e4", Best: "e4", EvalBefore: 20, EvalAfter: 30, Classification: "normal"},
+			{Ply: 2, SAN: "f5", Best: "e5", EvalBefore: 30, EvalAfter: 250, Loss: 220, Classification: "blunder"},
+			{Ply: 3, SAN: "Qh5+", Best: "Qh5+", EvalBefore: 250, EvalAfter: 260, Classification: "normal"},
+		},
+	}, map[int]float64{0: 0.2, 1: 0.3, 2: 2.5, 3: 2.6}))
+	// A position of the other game evaluated on its own
+	_, err = database.conn.Exec("UPDATE positions SET evaluation = 0.1 WHERE game_id = 2 AND move_number = 1")
+	require.NoError(t, err)
+
+	collect := func(fromAnalysis bool) []TrainingPosition {
+		var positions []TrainingPosition
+		n, err := database.TrainingPositions(ctx, fromAnalysis, func(p TrainingPosition) error {
+			positions = append(positions, p)
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Len(t, positions, n)
+		return positions
+	}
+
+	all := collect(false)
+	require.Len(t, all, 5)
+	assert.Equal(t, 2, all[4].GameID)
+	assert.Empty(t, all[0].BestMove, "best moves come with --from-analysis")
+
+	analyzed := collect(true)
+	require.Len(t, analyzed, 4)
+	assert.Equal(t, TrainingPosition{
+		GameID:   1,
+		Ply:      1,
+		FEN:      analyzed[1].FEN,
+		Eval:     0.3,
+		BestMove: "e7e5",
+		White:    "Alice",
+		Black:    "Bob",
+		WhiteElo: 1500,
+		Result:   "1-0",
+		Date:     "2024.01.01",
+	}, analyzed[1])
+	assert.Contains(t, analyzed[1].FEN, " b KQkq")
+	assert.Equal(t, "d1h5", analyzed[2].BestMove)
+	assert.Empty(t, analyzed[3].BestMove, "no move was played from the final position")
+}
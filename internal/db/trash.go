@@ -0,0 +1,136 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TrashedGame is a game moved to the trash by DeleteGame. Games in the trash
+// are left out of searches, statistics and the explorer until restored, and
+// are only removed for good by PurgeTrash.
+type TrashedGame struct {
+	ID        int       `json:"id"`
+	Event     string    `json:"event"`
+	Date      string    `json:"date"`
+	White     string    `json:"white"`
+	Black     string    `json:"black"`
+	Result    string    `json:"result"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// DeleteGame moves a game to the trash.
+func (db *DB) DeleteGame(ctx context.Context, id int) error {
+	result, err := db.conn.ExecContext(ctx,
+		"UPDATE games SET deleted_at = CURRENT_TIMESTAMP WHERE id = ? AND deleted_at IS NULL", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete game: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("%w: %d", ErrGameNotFound, id)
+	}
+	db.logger.Debug("game moved to trash", "game_id", id)
+	return nil
+}
+
+// RestoreGame takes a game out of the trash.
+func (db *DB) RestoreGame(ctx context.Context, id int) error {
+	result, err := db.conn.ExecContext(ctx,
+		"UPDATE games SET deleted_at = NULL WHERE id = ? AND deleted_at IS NOT NULL", id)
+	if err != nil {
+		return fmt.Errorf("failed to restore game: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("%w: %d", ErrGameNotInTrash, id)
+	}
+	db.logger.Debug("game restored from trash", "game_id", id)
+	return nil
+}
+
+// TrashedGames lists the games in the trash, the latest deleted first.
+func (db *DB) TrashedGames(ctx context.Context) ([]TrashedGame, error) {
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT id, COALESCE(event, ''), COALESCE(date, ''), COALESCE(white, ''), COALESCE(black, ''),
+			COALESCE(result, ''), deleted_at
+		FROM games
+		WHERE deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC, id DESC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query trash: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var games []TrashedGame
+	for rows.Next() {
+		var g TrashedGame
+		if err := rows.Scan(&g.ID, &g.Event, &g.Date, &g.White, &g.Black, &g.Result, &g.DeletedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan game: %w", err)
+		}
+		games = append(games, g)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read trash: %w", err)
+	}
+	return games, nil
+}
+
+// PurgeTrash removes games in the trash for good, with their tags,
+// positions, notes and analyses: those of ids, or every game in the trash if
+// none are given. It returns the number of games removed.
+func (db *DB) PurgeTrash(ctx context.Context, ids ...int) (int, error) {
+	where := "deleted_at IS NOT NULL"
+	var args []interface{}
+	if len(ids) > 0 {
+		where += " AND id IN (?" + strings.Repeat(", ?", len(ids)-1) + ")"
+		for _, id := range ids {
+			args = append(args, id)
+		}
+	}
+
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var count int
+	if err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM games WHERE "+where, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count games in trash: %w", err)
+	}
+	if len(ids) > 0 && count < len(ids) {
+		return 0, fmt.Errorf("%w: only %d of the %d games given are", ErrGameNotInTrash, count, len(ids))
+	}
+
+	// Child rows are deleted along, whether or not the connection enforces
+	// foreign keys
+	purged := "SELECT id FROM games WHERE " + where
+	analyses := "SELECT id FROM analyses WHERE game_id IN (" + purged + ")"
+	for _, stmt := range []struct{ table, query string }{
+		{"analysis moves", "DELETE FROM analysis_moves WHERE analysis_id IN (" + analyses + ")"},
+		{"analysis motifs", "DELETE FROM analysis_motifs WHERE analysis_id IN (" + analyses + ")"},
+		{"analyses", "DELETE FROM analyses WHERE game_id IN (" + purged + ")"},
+		{"notes", "DELETE FROM notes WHERE game_id IN (" + purged + ")"},
+		{"tags", "DELETE FROM tags WHERE game_id IN (" + purged + ")"},
+		{"positions", "DELETE FROM positions WHERE game_id IN (" + purged + ")"},
+		{"games", "DELETE FROM games WHERE " + where},
+	} {
+		if _, err := tx.ExecContext(ctx, stmt.query, args...); err != nil {
+			return 0, fmt.Errorf("failed to delete %s: %w", stmt.table, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	db.logger.Info("trash purged", "games", count)
+	return count, nil
+}
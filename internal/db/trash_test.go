@@ -0,0 +1,75 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrash(t *testing.T) {
+	database, err := NewInMemory()
+	require.NoError(t, err)
+	defer func() { _ = database.Close() }()
+	ctx := context.Background()
+
+	count, errs := database.ImportPGN(ctx, "../../testdata/lichess_no_fen.pgn")
+	require.Empty(t, errs)
+	require.Equal(t, 1, count)
+	require.NoError(t, database.SetNote(ctx, 1, "Check the endgame"))
+
+	require.NoError(t, database.DeleteGame(ctx, 1))
+	assert.ErrorIs(t, database.DeleteGame(ctx, 1), ErrGameNotFound, "a game already in the trash")
+	assert.ErrorIs(t, database.DeleteGame(ctx, 99), ErrGameNotFound)
+
+	// A game in the trash is left out of searches, counts and lookups
+	_, err = database.GetGame(ctx, 1)
+	assert.ErrorIs(t, err, ErrGameNotFound)
+	total, err := database.GetGameCount(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, total)
+	games, err := database.SearchGames(ctx, map[string]string{}, 10, 0)
+	require.NoError(t, err)
+	assert.Empty(t, games)
+	stats, err := database.GetPlayerStats(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, stats)
+
+	trashed, err := database.TrashedGames(ctx)
+	require.NoError(t, err)
+	require.Len(t, trashed, 1)
+	assert.Equal(t, 1, trashed[0].ID)
+	assert.Equal(t, "kyle_boon", trashed[0].White)
+	assert.False(t, trashed[0].DeletedAt.IsZero())
+
+	// Importing it again does not bring in a copy
+	result := database.ImportPGNWithOptions(ctx, "../../testdata/lichess_no_fen.pgn", ImportOptions{})
+	assert.Equal(t, 0, result.Imported)
+	assert.Equal(t, 1, result.Duplicates)
+
+	require.NoError(t, database.RestoreGame(ctx, 1))
+	assert.ErrorIs(t, database.RestoreGame(ctx, 1), ErrGameNotInTrash)
+	game, err := database.GetGame(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "Check the endgame", game.Notes, "restoring keeps the note")
+
+	// Purging removes the game for good
+	require.NoError(t, database.DeleteGame(ctx, 1))
+	_, err = database.PurgeTrash(ctx, 1, 2)
+	assert.ErrorIs(t, err, ErrGameNotInTrash, "game 2 is not in the trash")
+	purged, err := database.PurgeTrash(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+	trashed, err = database.TrashedGames(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, trashed)
+	assert.ErrorIs(t, database.RestoreGame(ctx, 1), ErrGameNotInTrash)
+	note, err := database.GetNote(ctx, 1)
+	require.NoError(t, err)
+	assert.Empty(t, note)
+
+	count, errs = database.ImportPGN(ctx, "../../testdata/lichess_no_fen.pgn")
+	require.Empty(t, errs)
+	assert.Equal(t, 1, count, "a purged game can be imported again")
+}
@@ -0,0 +1,190 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kyleboon/gochess/internal"
+	"github.com/kyleboon/gochess/internal/pgn"
+)
+
+// TreeNode is a move of an opening tree, with how the player scored in the
+// games that reached it. The root of a tree has no move and counts every
+// game.
+type TreeNode struct {
+	Move     string      `json:"move,omitempty"` // SAN, empty for the root
+	Ply      int         `json:"ply"`            // 1 for the first move, 0 for the root
+	Games    int         `json:"games"`
+	Wins     int         `json:"wins"`
+	Draws    int         `json:"draws"`
+	Losses   int         `json:"losses"`
+	Children []*TreeNode `json:"children,omitempty"`
+}
+
+// Score returns the player's score in the games of the node as a fraction,
+// counting draws as half a point.
+func (n *TreeNode) Score() float64 {
+	if n.Games == 0 {
+		return 0
+	}
+	return (float64(n.Wins) + float64(n.Draws)/2) / float64(n.Games)
+}
+
+// child returns the child of n for move, adding it if needed
+func (n *TreeNode) child(move string) *TreeNode {
+	for _, c := range n.Children {
+		if c.Move == move {
+			return c
+		}
+	}
+	c := &TreeNode{Move: move, Ply: n.Ply + 1}
+	n.Children = append(n.Children, c)
+	return c
+}
+
+// add counts a game with the player's score in it
+func (n *TreeNode) add(score float64) {
+	n.Games++
+	switch score {
+	case 1:
+		n.Wins++
+	case 0.5:
+		n.Draws++
+	default:
+		n.Losses++
+	}
+}
+
+// prune removes the children played in fewer than minGames games and
+// orders the rest by games, most played first
+func (n *TreeNode) prune(minGames int) {
+	kept := n.Children[:0]
+	for _, c := range n.Children {
+		if c.Games >= minGames {
+			c.prune(minGames)
+			kept = append(kept, c)
+		}
+	}
+	n.Children = kept
+	sort.SliceStable(n.Children, func(i, j int) bool {
+		return n.Children[i].Games > n.Children[j].Games
+	})
+}
+
+// GetOpeningTree builds the tree of the first depth plies of the finished
+// games of player, matched case insensitively. Color "white" or "black"
+// only counts the games the player played with that color; empty counts
+// both. Moves played in fewer than minGames games are left out.
+func (db *DB) GetOpeningTree(ctx context.Context, player, color string, depth, minGames int) (*TreeNode, error) {
+	return db.GetOpeningTreeFiltered(ctx, []string{player}, color, "", depth, minGames)
+}
+
+// GetOpeningTreeFiltered builds the opening tree of the finished games of
+// any of players, like GetOpeningTree, scored for the player in each game.
+// If players is empty, every game is counted from White's view and color
+// must be empty. A timeClass, one of TimeClasses, only counts the games of
+// that class.
+func (db *DB) GetOpeningTreeFiltered(ctx context.Context, players []string, color, timeClass string, depth, minGames int) (*TreeNode, error) {
+	var args []interface{}
+	placeholders := make([]string, len(players))
+	for i, player := range players {
+		placeholders[i] = "?"
+		args = append(args, player)
+	}
+	playerList := strings.Join(placeholders, ",")
+
+	var filter string
+	switch {
+	case len(players) == 0 && color == "":
+		filter = "1 = 1"
+	case len(players) == 0:
+		return nil, fmt.Errorf("a color needs a player")
+	case color == "":
+		filter = fmt.Sprintf("(g.white COLLATE NOCASE IN (%s) OR g.black COLLATE NOCASE IN (%s))", playerList, playerList)
+		args = append(args, args...) // Duplicate args for both IN clauses
+	case color == "white":
+		filter = fmt.Sprintf("g.white COLLATE NOCASE IN (%s)", playerList)
+	case color == "black":
+		filter = fmt.Sprintf("g.black COLLATE NOCASE IN (%s)", playerList)
+	default:
+		return nil, fmt.Errorf("invalid color %q: must be white or black", color)
+	}
+	if timeClass != "" {
+		filter += " AND g.time_class = ?"
+		args = append(args, timeClass)
+	}
+
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT g.id, g.white, g.result, p.fen, p.next_move
+		FROM games g
+		JOIN positions p ON p.game_id = g.id
+		WHERE `+filter+`
+		AND g.deleted_at IS NULL
+		AND g.result IN ('1-0', '0-1', '1/2-1/2')
+		AND p.move_number < ? AND p.next_move != ''
+		ORDER BY g.id, p.move_number
+	`, append(args, depth)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query positions: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	// isPlayer reports whether name is one of players. Without players,
+	// every game is scored for White
+	isPlayer := func(name string) bool {
+		for _, player := range players {
+			if strings.EqualFold(name, player) {
+				return true
+			}
+		}
+		return len(players) == 0
+	}
+
+	root := &TreeNode{}
+	sans := make(map[string]string) // SAN by position and UCI move
+	var node *TreeNode
+	var score float64
+	lastGame := 0
+	for rows.Next() {
+		var gameID int
+		var white, result, fen, move string
+		if err := rows.Scan(&gameID, &white, &result, &fen, &move); err != nil {
+			return nil, fmt.Errorf("failed to scan position: %w", err)
+		}
+		if gameID != lastGame {
+			lastGame = gameID
+			side := internal.White
+			if !isPlayer(white) || color == "black" {
+				side = internal.Black
+			}
+			score = pgn.ParseResult(result).Score(side)
+			node = root
+			node.add(score)
+		}
+
+		key := fen + " " + move
+		san, ok := sans[key]
+		if !ok {
+			san = move
+			if board, err := internal.ParseFen(fen); err == nil {
+				if mv, err := board.ParseMove(move); err == nil {
+					san = mv.San(board)
+				}
+			}
+			sans[key] = san
+		}
+		node = node.child(san)
+		node.add(score)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read positions: %w", err)
+	}
+
+	if minGames < 1 {
+		minGames = 1
+	}
+	root.prune(minGames)
+	return root, nil
+}
@@ -0,0 +1,118 @@
+package db
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/kyleboon/gochess/internal/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetOpeningTree(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "gochess-tree-test-")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(tempDir) }()
+
+	database, err := NewWithLogger(tempDir+"/test.db", logging.Discard())
+	require.NoError(t, err)
+	defer func() { _ = database.Close() }()
+	ctx := context.Background()
+
+	games := []string{
+		`[Event "1"] [Site "?"] [Date "2024.01.01"] [White "Alice"] [Black "Bob"] [Result "1-0"] 1. e4 e5 2. Nf3 Nc6 1-0`,
+		`[Event "2"] [Site "?"] [Date "2024.01.02"] [White "Alice"] [Black "Bob"] [Result "1/2-1/2"] 1. e4 e5 2. Bc4 Nf6 1/2-1/2`,
+		`[Event "3"] [Site "?"] [Date "2024.01.03"] [White "Alice"] [Black "Carol"] [Result "0-1"] 1. e4 c5 2. Nf3 d6 0-1`,
+		`[Event "4"] [Site "?"] [Date "2024.01.04"] [White "Bob"] [Black "Alice"] [Result "0-1"] 1. d4 d5 0-1`,
+		`[Event "5"] [Site "?"] [Date "2024.01.05"] [White "Alice"] [Black "Bob"] [Result "*"] 1. e4 e5 *`,
+	}
+	pgnFile := tempDir + "/games.pgn"
+	require.NoError(t, os.WriteFile(pgnFile, []byte(strings.Join(games, "\n\n")), 0644))
+	count, errs := database.ImportPGN(ctx, pgnFile)
+	require.Empty(t, errs)
+	require.Equal(t, 5, count)
+
+	root, err := database.GetOpeningTree(ctx, "alice", "", 2, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 4, root.Games)
+	require.Len(t, root.Children, 2)
+
+	// Most played first, scored for Alice
+	e4 := root.Children[0]
+	assert.Equal(t, "e4", e4.Move)
+	assert.Equal(t, 1, e4.Ply)
+	assert.Equal(t, 3, e4.Games)
+	assert.Equal(t, 1, e4.Wins)
+	assert.Equal(t, 1, e4.Draws)
+	assert.Equal(t, 1, e4.Losses)
+	assert.Equal(t, 0.5, e4.Score())
+	require.Len(t, e4.Children, 2)
+	assert.Equal(t, "e5", e4.Children[0].Move)
+	assert.Empty(t, e4.Children[0].Children, "depth limits the tree")
+
+	d4 := root.Children[1]
+	assert.Equal(t, "d4", d4.Move)
+	assert.Equal(t, 1.0, d4.Score())
+
+	t.Run("color", func(t *testing.T) {
+		root, err := database.GetOpeningTree(ctx, "Alice", "black", 8, 1)
+		require.NoError(t, err)
+		assert.Equal(t, 1, root.Games)
+		require.Len(t, root.Children, 1)
+		assert.Equal(t, "d4", root.Children[0].Move)
+	})
+
+	t.Run("min games", func(t *testing.T) {
+		root, err := database.GetOpeningTree(ctx, "Alice", "white", 8, 2)
+		require.NoError(t, err)
+		require.Len(t, root.Children, 1)
+		require.Len(t, root.Children[0].Children, 1)
+		assert.Equal(t, "e5", root.Children[0].Children[0].Move)
+		assert.Empty(t, root.Children[0].Children[0].Children)
+	})
+
+	t.Run("invalid color", func(t *testing.T) {
+		_, err := database.GetOpeningTree(ctx, "Alice", "green", 8, 1)
+		assert.Error(t, err)
+	})
+}
+
+func TestGetOpeningTreeFiltered(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "gochess-tree-test-")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(tempDir) }()
+
+	database, err := NewWithLogger(tempDir+"/test.db", logging.Discard())
+	require.NoError(t, err)
+	defer func() { _ = database.Close() }()
+	ctx := context.Background()
+
+	games := []string{
+		`[Event "1"] [Site "?"] [Date "2024.01.01"] [White "alice_lichess"] [Black "Bob"] [Result "1-0"] 1. e4 e5 1-0`,
+		`[Event "2"] [Site "?"] [Date "2024.01.02"] [White "Carol"] [Black "Alice_ChessCom"] [Result "1-0"] 1. e4 c5 1-0`,
+		`[Event "3"] [Site "?"] [Date "2024.01.03"] [White "Bob"] [Black "Carol"] [Result "1/2-1/2"] 1. d4 d5 1/2-1/2`,
+	}
+	pgnFile := tempDir + "/games.pgn"
+	require.NoError(t, os.WriteFile(pgnFile, []byte(strings.Join(games, "\n\n")), 0644))
+	_, errs := database.ImportPGN(ctx, pgnFile)
+	require.Empty(t, errs)
+
+	root, err := database.GetOpeningTreeFiltered(ctx, []string{"Alice_Lichess", "alice_chesscom"}, "", "", 2, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 2, root.Games)
+	require.Len(t, root.Children, 1)
+	e4 := root.Children[0]
+	assert.Equal(t, 1, e4.Wins, "scored for the player of each game")
+	assert.Equal(t, 1, e4.Losses)
+	assert.Len(t, e4.Children, 2)
+
+	root, err = database.GetOpeningTreeFiltered(ctx, nil, "", "", 2, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 3, root.Games)
+	assert.Equal(t, 2, root.Children[0].Wins, "every game is scored for White")
+
+	_, err = database.GetOpeningTreeFiltered(ctx, nil, "white", "", 2, 1)
+	assert.Error(t, err)
+}
@@ -0,0 +1,169 @@
+// Package dgt reads the positions set up on a DGT electronic chess board
+// connected over serial or USB, so that games played over the board can be
+// followed move by move.
+package dgt
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/kyleboon/gochess/internal"
+)
+
+// Commands sent to the board, from the DGT serial protocol.
+const (
+	cmdSendBoard      = 0x42 // answered with a board dump
+	cmdSendUpdateNice = 0x4b // field updates are sent as pieces are moved
+)
+
+// Messages sent by the board: a header of the message ID with its high bit
+// set and the message size in two 7-bit bytes, header included, then the
+// data.
+const (
+	messageBit     = 0x80
+	msgBoardDump   = 0x06 // 64 piece codes, a8 to h1
+	msgFieldUpdate = 0x0e // a field and the piece code now on it
+	headerSize     = 3
+	maxMessageSize = 1 << 14
+)
+
+// pieces maps the board's piece codes to pieces. The codes 13 to 15 of the
+// special pieces used to enter results are read as empty squares.
+var pieces = [16]internal.Piece{
+	internal.NoPiece,
+	internal.WP, internal.WR, internal.WN, internal.WB, internal.WK, internal.WQ,
+	internal.BP, internal.BR, internal.BN, internal.BB, internal.BK, internal.BQ,
+	internal.NoPiece, internal.NoPiece, internal.NoPiece,
+}
+
+// Position is the placement of the pieces on the board, indexed by square
+// like internal.Board.Piece.
+type Position [64]internal.Piece
+
+// Rotated returns the position seen from the other side, as read from a
+// board set up with Black on the side of the cable.
+func (p Position) Rotated() Position {
+	var r Position
+	for sq := range p {
+		r[63-sq] = p[sq]
+	}
+	return r
+}
+
+// String returns the piece placement field of a FEN of the position.
+func (p Position) String() string {
+	b := internal.Board{Piece: p}
+	fen := b.Fen()
+	for i := range fen {
+		if fen[i] == ' ' {
+			return fen[:i]
+		}
+	}
+	return fen
+}
+
+// fieldSquare returns the square of a DGT field number: field 0 is a8 and
+// field 63 is h1.
+func fieldSquare(field byte) internal.Sq {
+	return internal.Square(int(field%8), 7-int(field/8))
+}
+
+// Board is a connection to a DGT board.
+type Board struct {
+	rw io.ReadWriter
+	r  *bufio.Reader
+}
+
+// NewBoard talks to a DGT board over rw, a serial port already set to 9600
+// baud, 8 data bits, no parity and 1 stop bit.
+func NewBoard(rw io.ReadWriter) *Board {
+	return &Board{rw: rw, r: bufio.NewReader(rw)}
+}
+
+// Close closes the connection to the board, if it can be closed. A Watch in
+// progress returns.
+func (b *Board) Close() error {
+	if c, ok := b.rw.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// Watch asks the board for its position and passes it to fn, then again
+// each time a piece is lifted or put down, until the connection ends, ctx is
+// done or fn returns an error. Positions between moves, with a piece in the
+// hand, are passed on too. Close the board to stop a Watch blocked reading.
+func (b *Board) Watch(ctx context.Context, fn func(Position) error) error {
+	if _, err := b.rw.Write([]byte{cmdSendBoard, cmdSendUpdateNice}); err != nil {
+		return fmt.Errorf("failed to write to the board: %w", err)
+	}
+
+	var pos Position
+	dumped := false
+	for {
+		id, data, err := b.readMessage()
+		if ctx.Err() != nil {
+			return nil
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch id {
+		case msgBoardDump:
+			if len(data) != 64 {
+				return fmt.Errorf("invalid board dump of %d fields", len(data))
+			}
+			for field, code := range data {
+				pos[fieldSquare(byte(field))] = pieces[code&0x0f]
+			}
+			dumped = true
+		case msgFieldUpdate:
+			if len(data) != 2 || data[0] > 63 {
+				return fmt.Errorf("invalid field update % x", data)
+			}
+			pos[fieldSquare(data[0])] = pieces[data[1]&0x0f]
+		default:
+			continue // clock times, serial numbers and versions
+		}
+		if !dumped {
+			continue // updates before the whole board was read
+		}
+		if err := fn(pos); err != nil {
+			return err
+		}
+	}
+}
+
+// readMessage reads the next message from the board, skipping bytes until a
+// message header
+func (b *Board) readMessage() (byte, []byte, error) {
+	var header [headerSize]byte
+	for {
+		c, err := b.r.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		if c&messageBit != 0 {
+			header[0] = c
+			break
+		}
+	}
+	if _, err := io.ReadFull(b.r, header[1:]); err != nil {
+		return 0, nil, fmt.Errorf("failed to read message header: %w", err)
+	}
+	size := int(header[1]&0x7f)<<7 | int(header[2]&0x7f)
+	if size < headerSize || size > maxMessageSize {
+		return 0, nil, fmt.Errorf("invalid message size %d", size)
+	}
+	data := make([]byte, size-headerSize)
+	if _, err := io.ReadFull(b.r, data); err != nil {
+		return 0, nil, fmt.Errorf("failed to read message: %w", err)
+	}
+	return header[0] &^ messageBit, data, nil
+}
@@ -0,0 +1,115 @@
+package dgt
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/kyleboon/gochess/internal"
+)
+
+const startFEN = "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
+
+// fakeBoard records the commands written and answers with the messages read
+type fakeBoard struct {
+	io.Reader
+	written bytes.Buffer
+}
+
+func (f *fakeBoard) Write(p []byte) (int, error) { return f.written.Write(p) }
+
+// boardDump encodes a board dump message of the placement of fen
+func boardDump(t *testing.T, fen string) []byte {
+	t.Helper()
+	b, err := internal.ParseFen(fen)
+	if err != nil {
+		t.Fatal(err)
+	}
+	codes := map[internal.Piece]byte{}
+	for code, p := range pieces[:13] {
+		codes[p] = byte(code)
+	}
+	msg := []byte{messageBit | msgBoardDump, 0, 67}
+	for field := byte(0); field < 64; field++ {
+		msg = append(msg, codes[b.Piece[fieldSquare(field)]])
+	}
+	return msg
+}
+
+// fieldUpdate encodes a field update message putting code on square
+func fieldUpdate(square string, code byte) []byte {
+	field := (7-(square[1]-'1'))*8 + square[0] - 'a'
+	return []byte{messageBit | msgFieldUpdate, 0, 5, field, code}
+}
+
+func TestFieldSquare(t *testing.T) {
+	tests := map[byte]internal.Sq{0: internal.A8, 7: internal.H8, 12: internal.E7, 56: internal.A1, 63: internal.H1}
+	for field, want := range tests {
+		if got := fieldSquare(field); got != want {
+			t.Errorf("fieldSquare(%d) = %s, want %s", field, got, want)
+		}
+	}
+}
+
+func TestBoard_Watch(t *testing.T) {
+	var stream []byte
+	stream = append(stream, fieldUpdate("e2", 0)...) // before the dump: ignored
+	stream = append(stream, 0x00, 0x12)              // noise between messages
+	stream = append(stream, boardDump(t, startFEN)...)
+	stream = append(stream, messageBit|0x13, 0, 5, 1, 2) // version: ignored
+	stream = append(stream, fieldUpdate("e2", 0)...)
+	stream = append(stream, fieldUpdate("e4", 1)...)
+
+	fake := &fakeBoard{Reader: bytes.NewReader(stream)}
+	var positions []string
+	err := NewBoard(fake).Watch(context.Background(), func(pos Position) error {
+		positions = append(positions, pos.String())
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Watch() error: %v", err)
+	}
+	if got := fake.written.Bytes(); !bytes.Equal(got, []byte{cmdSendBoard, cmdSendUpdateNice}) {
+		t.Errorf("expected the board and updates to be asked for, got % x", got)
+	}
+	want := []string{
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR",
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPP1PPP/RNBQKBNR",
+		"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR",
+	}
+	if len(positions) != len(want) {
+		t.Fatalf("expected %d positions, got %q", len(want), positions)
+	}
+	for i := range want {
+		if positions[i] != want[i] {
+			t.Errorf("position %d = %s, want %s", i, positions[i], want[i])
+		}
+	}
+}
+
+func TestBoard_WatchErrors(t *testing.T) {
+	stop := errors.New("stop")
+	stream := append(boardDump(t, startFEN), fieldUpdate("e2", 0)...)
+	calls := 0
+	err := NewBoard(&fakeBoard{Reader: bytes.NewReader(stream)}).Watch(context.Background(), func(Position) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Errorf("expected Watch to stop at the first error, got %v after %d calls", err, calls)
+	}
+
+	truncated := boardDump(t, startFEN)[:40]
+	err = NewBoard(&fakeBoard{Reader: bytes.NewReader(truncated)}).Watch(context.Background(), func(Position) error { return nil })
+	if err == nil {
+		t.Error("expected an error for a truncated message")
+	}
+
+	bad := []byte{messageBit | msgFieldUpdate, 0, 5, 64, 1}
+	err = NewBoard(&fakeBoard{Reader: bytes.NewReader(append(boardDump(t, startFEN), bad...))}).Watch(context.Background(), func(Position) error { return nil })
+	if err == nil {
+		t.Error("expected an error for an update of field 64")
+	}
+}
@@ -0,0 +1,45 @@
+package dgt
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// Open connects to a DGT board at the serial port path, as in /dev/ttyUSB0
+// or /dev/ttyACM0. The port is set to 9600 baud, 8N1, without echo or line
+// editing. A path that is not a terminal, such as a recording of a board's
+// output, is read as is.
+func Open(path string) (*Board, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|unix.O_NOCTTY, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	if err := configureSerial(int(f.Fd())); err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("failed to configure %s: %w", path, err)
+	}
+	return NewBoard(f), nil
+}
+
+// configureSerial sets the serial port to the board's 9600 baud 8N1, raw
+func configureSerial(fd int) error {
+	t, err := unix.IoctlGetTermios(fd, unix.TCGETS)
+	if errors.Is(err, unix.ENOTTY) || errors.Is(err, unix.EINVAL) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	t.Iflag = 0
+	t.Oflag = 0
+	t.Lflag = 0
+	t.Cflag = unix.B9600 | unix.CS8 | unix.CLOCAL | unix.CREAD
+	t.Ispeed = unix.B9600
+	t.Ospeed = unix.B9600
+	t.Cc[unix.VMIN] = 1
+	t.Cc[unix.VTIME] = 0
+	return unix.IoctlSetTermios(fd, unix.TCSETS, t)
+}
@@ -0,0 +1,19 @@
+//go:build !linux
+
+package dgt
+
+import (
+	"fmt"
+	"os"
+)
+
+// Open connects to a DGT board at the serial port path, as in
+// /dev/cu.usbserial-1410. The port must already be set to 9600 baud, 8N1,
+// for example with stty.
+func Open(path string) (*Board, error) {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	return NewBoard(f), nil
+}
@@ -0,0 +1,58 @@
+package dgt
+
+import "github.com/kyleboon/gochess/internal"
+
+// Tracker follows a game from the positions read on a DGT board, finding the
+// moves that lead to each one.
+type Tracker struct {
+	board   *internal.Board
+	rotated bool // the board is set up with Black on the side of the cable
+	started bool
+}
+
+// NewTracker follows a game from the position of board.
+func NewTracker(board *internal.Board) *Tracker {
+	return &Tracker{board: board}
+}
+
+// Board returns the position reached in the game.
+func (t *Tracker) Board() *internal.Board {
+	return t.board
+}
+
+// Update reads a position from the DGT board and returns the moves played
+// to reach it: one, or two when the reply was played before the board was
+// read. It returns none for the position of the game and the positions
+// between moves, with a piece in the hand or a captured piece taken off.
+// Castling is recognized when the king is moved first. A board set up with
+// Black on the side of the cable is recognized from the first position read.
+func (t *Tracker) Update(pos Position) []internal.Move {
+	if !t.started {
+		t.started = true
+		t.rotated = pos != t.board.Piece && pos.Rotated() == t.board.Piece
+	}
+	if t.rotated {
+		pos = pos.Rotated()
+	}
+	if pos == t.board.Piece {
+		return nil
+	}
+
+	for _, m := range t.board.LegalMoves() {
+		next := t.board.MakeMove(m)
+		if next.Piece == pos {
+			t.board = next
+			return []internal.Move{m}
+		}
+	}
+	for _, m := range t.board.LegalMoves() {
+		next := t.board.MakeMove(m)
+		for _, reply := range next.LegalMoves() {
+			if after := next.MakeMove(reply); after.Piece == pos {
+				t.board = after
+				return []internal.Move{m, reply}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package dgt
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kyleboon/gochess/internal"
+)
+
+// placement returns the position of the pieces of fen
+func placement(t *testing.T, fen string) Position {
+	t.Helper()
+	b, err := internal.ParseFen(fen)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b.Piece
+}
+
+// sans writes moves played from b in SAN
+func sans(b *internal.Board, moves []internal.Move) string {
+	var out []string
+	for _, m := range moves {
+		out = append(out, m.San(b))
+		b = b.MakeMove(m)
+	}
+	return strings.Join(out, " ")
+}
+
+func TestTracker_Update(t *testing.T) {
+	start, _ := internal.ParseFen(startFEN)
+	tracker := NewTracker(start)
+
+	steps := []struct {
+		placement string
+		want      string
+	}{
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR", ""},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPP1PPP/RNBQKBNR", ""}, // e-pawn lifted
+		{"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR", "e4"},
+		{"rnbqkbnr/pppp1ppp/8/4p3/4P3/5N2/PPPP1PPP/RNBQKB1R", "e5 Nf3"}, // read late
+		{"r1bqkbnr/pppp1ppp/2n5/4p3/4P3/5N2/PPPP1PPP/RNBQKB1R", "Nc6"},
+		{"r1bqkbnr/pppp1ppp/2n5/1B2p3/4P3/5N2/PPPP1PPP/RNBQK2R", "Bb5"},
+		{"r1bqkb1r/pppp1ppp/2n2n2/1B2p3/4P3/5N2/PPPP1PPP/RNBQK2R", "Nf6"},
+		{"r1bqkb1r/pppp1ppp/2n2n2/1B2p3/4P3/5N2/PPPP1PPP/RNBQ2KR", ""}, // king first
+		{"r1bqkb1r/pppp1ppp/2n2n2/1B2p3/4P3/5N2/PPPP1PPP/RNBQ2K1", ""},
+		{"r1bqkb1r/pppp1ppp/2n2n2/1B2p3/4P3/5N2/PPPP1PPP/RNBQ1RK1", "O-O"},
+		{"r1bqkb1r/pppp1ppp/2n5/1B2p3/4P3/5N2/PPPP1PPP/RNBQ1RK1", ""}, // knight lifted
+		{"r1bqkb1r/pppp1ppp/2n5/1B2p3/4n3/5N2/PPPP1PPP/RNBQ1RK1", "Nxe4"},
+	}
+	for i, step := range steps {
+		before := tracker.Board()
+		got := sans(before, tracker.Update(placement(t, step.placement+" w - - 0 1")))
+		if got != step.want {
+			t.Errorf("step %d: got moves %q, want %q", i, got, step.want)
+		}
+	}
+	if got := tracker.Board().SideToMove; got != internal.White {
+		t.Errorf("expected White to move, got %d", got)
+	}
+}
+
+func TestTracker_Rotated(t *testing.T) {
+	start, _ := internal.ParseFen(startFEN)
+	tracker := NewTracker(start)
+	pos := placement(t, startFEN)
+	if moves := tracker.Update(pos.Rotated()); len(moves) != 0 {
+		t.Fatalf("expected no move for the starting position, got %d", len(moves))
+	}
+	after := placement(t, "rnbqkbnr/pppppppp/8/8/3P4/8/PPP1PPPP/RNBQKBNR w - - 0 1")
+	if got := sans(start, tracker.Update(after.Rotated())); got != "d4" {
+		t.Errorf("expected d4 on a rotated board, got %q", got)
+	}
+}
+
+func TestTracker_Promotion(t *testing.T) {
+	board, _ := internal.ParseFen("8/4P3/8/8/8/k7/8/K7 w - - 0 1")
+	tracker := NewTracker(board)
+	if got := sans(board, tracker.Update(placement(t, "4N3/8/8/8/8/k7/8/K7 w - - 0 1"))); got != "e8=N" {
+		t.Errorf("expected the pawn to promote to a knight, got %q", got)
+	}
+}
@@ -7,7 +7,9 @@ import (
 	"log/slog"
 	"sort"
 	"strings"
+	"sync"
 
+	"github.com/kyleboon/gochess/internal"
 	"github.com/kyleboon/gochess/internal/logging"
 )
 
@@ -23,8 +25,16 @@ type Opening struct {
 type Database struct {
 	openings []Opening
 	logger   *slog.Logger
+
+	// positions indexes openings by the position they reach, built on first
+	// use by ClassifyFEN
+	positionsOnce sync.Once
+	positions     map[string]Opening
 }
 
+// startFEN is the position every ECO line starts from
+const startFEN = "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
+
 //go:embed data/a.tsv
 var aTSV string
 
@@ -193,3 +203,74 @@ func (db *Database) GetByName(name string) []Opening {
 
 	return results
 }
+
+// GetByCode returns all openings with an ECO code, shortest line first, so the
+// first opening is the line that defines the code
+func (db *Database) GetByCode(ecoCode string) []Opening {
+	ecoCode = strings.ToUpper(ecoCode)
+	results := make([]Opening, 0)
+
+	for _, opening := range db.openings {
+		if opening.ECOCode == ecoCode {
+			results = append(results, opening)
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return len(results[i].Moves) < len(results[j].Moves)
+	})
+	return results
+}
+
+// ClassifyFEN finds the opening whose moves reach the position in fen, so
+// positions reached by a transposition are classified too. When several
+// openings reach the position the longest line wins.
+func (db *Database) ClassifyFEN(fen string) (Opening, bool) {
+	board, err := internal.ParseFen(fen)
+	if err != nil {
+		return Opening{}, false
+	}
+	db.positionsOnce.Do(db.indexPositions)
+	opening, ok := db.positions[positionKey(board)]
+	return opening, ok
+}
+
+// indexPositions plays out the moves of every opening and records the
+// position each one reaches
+func (db *Database) indexPositions() {
+	db.positions = make(map[string]Opening, len(db.openings))
+	start, _ := internal.ParseFen(startFEN)
+
+	// Openings are sorted longest first, so the first opening to reach a
+	// position is kept
+	for _, opening := range db.openings {
+		board := start
+		for _, san := range opening.Moves {
+			mv, err := board.ParseMove(san)
+			if err != nil {
+				db.logger.Warn("invalid move in ECO line", "eco", opening.ECOCode, "move", san)
+				board = nil
+				break
+			}
+			board = board.MakeMove(mv)
+		}
+		if board == nil {
+			continue
+		}
+		key := positionKey(board)
+		if _, ok := db.positions[key]; !ok {
+			db.positions[key] = opening
+		}
+	}
+}
+
+// positionKey is the FEN of a board without the en passant square and move
+// counters. The en passant square is dropped as FENs differ on whether to
+// give it when no capture is possible.
+func positionKey(board *internal.Board) string {
+	fields := strings.Fields(board.Fen())
+	if len(fields) > 3 {
+		fields = fields[:3]
+	}
+	return strings.Join(fields, " ")
+}
@@ -303,6 +303,89 @@ func TestGetByName(t *testing.T) {
 	}
 }
 
+func TestGetByCode(t *testing.T) {
+	db, err := NewDatabaseWithLogger(logging.Discard())
+	if err != nil {
+		t.Fatalf("failed to create ECO database: %v", err)
+	}
+
+	openings := db.GetByCode("b90")
+	if len(openings) < 2 {
+		t.Fatalf("expected several B90 lines, got %d", len(openings))
+	}
+	for i, opening := range openings {
+		if opening.ECOCode != "B90" {
+			t.Errorf("expected ECO B90, got %s", opening.ECOCode)
+		}
+		if i > 0 && len(opening.Moves) < len(openings[i-1].Moves) {
+			t.Errorf("expected lines ordered shortest first, %q is shorter than %q", opening.PGN, openings[i-1].PGN)
+		}
+	}
+	if !contains(openings[0].Name, "Najdorf") {
+		t.Errorf("expected the defining B90 line to be the Najdorf, got %q", openings[0].Name)
+	}
+
+	if got := db.GetByCode("Z99"); len(got) != 0 {
+		t.Errorf("expected no openings for Z99, got %d", len(got))
+	}
+}
+
+func TestClassifyFEN(t *testing.T) {
+	db, err := NewDatabaseWithLogger(logging.Discard())
+	if err != nil {
+		t.Fatalf("failed to create ECO database: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		fen        string
+		shouldFind bool
+		ecoCode    string
+	}{
+		{
+			name:       "Najdorf",
+			fen:        "rnbqkb1r/1p2pppp/p2p1n2/8/3NP3/2N5/PPP2PPP/R1BQKB1R w KQkq - 0 6",
+			shouldFind: true,
+			ecoCode:    "B90",
+		},
+		{
+			name:       "Move counters are ignored",
+			fen:        "rnbqkb1r/1p2pppp/p2p1n2/8/3NP3/2N5/PPP2PPP/R1BQKB1R w KQkq - 3 20",
+			shouldFind: true,
+			ecoCode:    "B90",
+		},
+		{
+			name:       "Queen's Gambit Declined by transposition",
+			fen:        "rnbqkb1r/ppp2ppp/4pn2/3p4/2PP4/2N5/PP2PPPP/R1BQKBNR w KQkq - 0 4",
+			shouldFind: true,
+			ecoCode:    "D35",
+		},
+		{
+			name:       "Not an opening position",
+			fen:        "8/8/4k3/8/8/4K3/8/8 w - - 0 1",
+			shouldFind: false,
+		},
+		{
+			name:       "Invalid FEN",
+			fen:        "not a fen",
+			shouldFind: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opening, found := db.ClassifyFEN(tt.fen)
+
+			if found != tt.shouldFind {
+				t.Fatalf("expected found=%v, got %v (%s %s)", tt.shouldFind, found, opening.ECOCode, opening.Name)
+			}
+			if tt.shouldFind && opening.ECOCode != tt.ecoCode {
+				t.Errorf("expected ECO %s, got %s (%s)", tt.ecoCode, opening.ECOCode, opening.Name)
+			}
+		})
+	}
+}
+
 func TestMovesEqual(t *testing.T) {
 	tests := []struct {
 		name     string
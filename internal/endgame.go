@@ -0,0 +1,62 @@
+package internal
+
+// The endgame types returned by Board.Endgame.
+const (
+	PawnEndgame            = "pawn"             // kings and pawns only
+	RookEndgame            = "rook"             // rooks
+	QueenEndgame           = "queen"            // queens
+	OppositeBishopsEndgame = "opposite-bishops" // a bishop each, on squares of different colors
+	MinorPieceEndgame      = "minor"            // bishops and knights
+	RookMinorEndgame       = "rook-minor"       // rooks with bishops or knights
+	MixedEndgame           = "mixed"            // queens with other pieces, such as queen against rook
+)
+
+// EndgameTypes are the endgame types, from the simplest.
+var EndgameTypes = []string{
+	PawnEndgame, RookEndgame, QueenEndgame, OppositeBishopsEndgame,
+	MinorPieceEndgame, RookMinorEndgame, MixedEndgame,
+}
+
+// maxEndgamePieces is the most pieces, besides the king and pawns, a side
+// can have in an endgame.
+const maxEndgamePieces = 2
+
+// Endgame returns the type of endgame of the position, by the pieces left
+// besides kings and pawns, or "" if it is not an endgame: a side has more
+// than two pieces left.
+func (b *Board) Endgame() string {
+	var pieces [2]int
+	var types [King + 1]int
+	var bishops []Sq
+	for sq, p := range b.Piece {
+		switch p.Type() {
+		case NoPiece, Pawn, King:
+			continue
+		case Bishop:
+			bishops = append(bishops, Sq(sq))
+		}
+		pieces[p.Color()]++
+		types[p.Type()]++
+	}
+	if pieces[White] > maxEndgamePieces || pieces[Black] > maxEndgamePieces {
+		return ""
+	}
+
+	minors := types[Bishop] + types[Knight]
+	switch {
+	case pieces[White]+pieces[Black] == 0:
+		return PawnEndgame
+	case types[Queen] > 0 && types[Rook]+minors == 0:
+		return QueenEndgame
+	case types[Queen] > 0:
+		return MixedEndgame
+	case minors == 0:
+		return RookEndgame
+	case types[Rook] > 0:
+		return RookMinorEndgame
+	case len(bishops) == 2 && types[Knight] == 0 && pieces[White] == 1 && pieces[Black] == 1 &&
+		bishops[0].Color() != bishops[1].Color():
+		return OppositeBishopsEndgame
+	}
+	return MinorPieceEndgame
+}
@@ -0,0 +1,35 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEndgame(t *testing.T) {
+	tests := []struct {
+		name, fen, want string
+	}{
+		{"starting position", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", ""},
+		{"three pieces each", "r1b1k2r/pp3ppp/8/8/8/8/PP3PPP/R1B1K2R w KQkq - 0 20", ""},
+		{"kings and pawns", "8/5k2/8/3P4/8/8/2K5/8 w - - 0 1", PawnEndgame},
+		{"rook each", "8/5k2/6p1/8/8/1R6/2K2r2/8 w - - 0 40", RookEndgame},
+		{"two rooks each", "r4r2/5k2/8/8/8/8/2K5/R6R w - - 0 40", RookEndgame},
+		{"rook against pawns", "8/5k2/8/8/3p4/2p5/2K5/7R w - - 0 50", RookEndgame},
+		{"queen each", "8/5k2/6q1/8/8/8/2K5/3Q4 w - - 0 40", QueenEndgame},
+		{"opposite bishops", "8/5k2/3b4/8/8/8/2K1B3/8 w - - 0 40", OppositeBishopsEndgame},
+		{"same colored bishops", "8/5k2/4b3/8/8/8/2K1B3/8 w - - 0 40", MinorPieceEndgame},
+		{"bishop against knight", "8/5k2/4n3/8/8/8/2K1B3/8 w - - 0 40", MinorPieceEndgame},
+		{"two bishops against one", "8/5k2/3b4/8/8/8/2KBB3/8 w - - 0 40", MinorPieceEndgame},
+		{"rook and bishop against rook", "8/5k2/3r4/8/8/8/2K1B3/7R w - - 0 40", RookMinorEndgame},
+		{"queen against rook", "8/5k2/3r4/8/8/8/2K5/7Q w - - 0 40", MixedEndgame},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := ParseFen(tt.fen)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, b.Endgame())
+		})
+	}
+}
@@ -5,12 +5,14 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // AnalysisOptions configures the engine analysis.
 type AnalysisOptions struct {
-	Depth   int // search depth (default 20)
-	MultiPV int // number of lines to report (default 1)
+	Depth    int           // search depth (default 20)
+	MultiPV  int           // number of lines to report (default 1)
+	MoveTime time.Duration // search time per position; overrides Depth when set
 }
 
 // Score represents an engine evaluation score.
@@ -56,7 +58,7 @@ type AnalysisResult struct {
 
 // Analyze runs a position analysis and returns the result.
 func (e *Engine) Analyze(ctx context.Context, fen string, opts AnalysisOptions) (*AnalysisResult, error) {
-	if opts.Depth <= 0 {
+	if opts.Depth <= 0 && opts.MoveTime <= 0 {
 		opts.Depth = 20
 	}
 	if opts.MultiPV <= 0 {
@@ -79,7 +81,11 @@ func (e *Engine) Analyze(ctx context.Context, fen string, opts AnalysisOptions)
 	}
 
 	// Start search
-	if err := e.sendLocked(fmt.Sprintf("go depth %d", opts.Depth)); err != nil {
+	goCmd := fmt.Sprintf("go depth %d", opts.Depth)
+	if opts.MoveTime > 0 {
+		goCmd = fmt.Sprintf("go movetime %d", opts.MoveTime.Milliseconds())
+	}
+	if err := e.sendLocked(goCmd); err != nil {
 		return nil, err
 	}
 
@@ -130,6 +136,10 @@ func (e *Engine) Analyze(ctx context.Context, fen string, opts AnalysisOptions)
 			result.Lines = append(result.Lines, *al)
 		}
 	}
+	// A timed search reports the depth it actually reached
+	if result.Depth == 0 && len(result.Lines) > 0 {
+		result.Depth = result.Lines[0].Depth
+	}
 
 	return result, nil
 }
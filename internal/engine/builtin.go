@@ -0,0 +1,232 @@
+package engine
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/kyleboon/gochess/internal"
+)
+
+// Defaults of the built-in engine's search.
+const (
+	builtinDepth      = 3  // plies searched when neither depth nor time is given
+	builtinMaxDepth   = 64 // plies searched at most within a move time
+	builtinQuiescence = 6  // plies of captures searched past the depth
+	mateScore         = 100000
+)
+
+// Builtin is a small engine searching with the static evaluation of the
+// board package, so that games can be played and positions looked at when no
+// engine is installed. It searches a few plies with alpha-beta and resolves
+// captures, which is enough to avoid blunders but far weaker than a real
+// engine. It reports a single line, whatever the MultiPV asked.
+type Builtin struct{}
+
+// NewBuiltin creates the built-in engine.
+func NewBuiltin() *Builtin {
+	return &Builtin{}
+}
+
+// Close does nothing: the built-in engine holds no resources.
+func (e *Builtin) Close() error {
+	return nil
+}
+
+// builtinSearch is the state of one search.
+type builtinSearch struct {
+	ctx      context.Context
+	deadline time.Time
+	nodes    int64
+	aborted  bool
+}
+
+// Analyze searches the position to opts.Depth plies, or deepening until
+// opts.MoveTime is up, and returns the best line found.
+func (e *Builtin) Analyze(ctx context.Context, fen string, opts AnalysisOptions) (*AnalysisResult, error) {
+	board, err := internal.ParseFen(fen)
+	if err != nil {
+		return nil, err
+	}
+	depth := opts.Depth
+	s := &builtinSearch{ctx: ctx}
+	if opts.MoveTime > 0 {
+		s.deadline = time.Now().Add(opts.MoveTime)
+		if depth <= 0 {
+			depth = builtinMaxDepth
+		}
+	} else if depth <= 0 {
+		depth = builtinDepth
+	}
+
+	start := time.Now()
+	result := &AnalysisResult{FEN: fen}
+	for d := 1; d <= depth; d++ {
+		score, pv := s.negamax(board, d, 0, -mateScore-1, mateScore+1)
+		if s.aborted {
+			break
+		}
+		line := AnalysisLine{Rank: 1, Depth: d, Nodes: s.nodes, Score: builtinScore(score, board.SideToMove)}
+		for b, i := board, 0; i < len(pv); i++ {
+			line.Moves = append(line.Moves, pv[i].Uci(b))
+			b = b.MakeMove(pv[i])
+		}
+		if elapsed := time.Since(start); elapsed > 0 {
+			line.NPS = int64(float64(s.nodes) / elapsed.Seconds())
+		}
+		result.Lines = []AnalysisLine{line}
+		result.Depth = d
+		if len(pv) == 0 || line.Score.IsMate {
+			break // no moves, or a mate found: deeper searches change nothing
+		}
+	}
+	if len(result.Lines) == 0 {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// builtinScore converts a search score for the side to move into a Score
+// from White's point of view.
+func builtinScore(score, sideToMove int) Score {
+	if sideToMove == internal.Black {
+		score = -score
+	}
+	if score > mateScore-1000 || score < -mateScore+1000 {
+		plies := mateScore - score
+		if score < 0 {
+			plies = mateScore + score
+		}
+		moves := (plies + 1) / 2
+		if score < 0 {
+			moves = -moves
+		}
+		return Score{Mate: moves, IsMate: true}
+	}
+	return Score{Centipawns: score}
+}
+
+// stop reports whether the search must end, checking the context and the
+// deadline every few thousand nodes.
+func (s *builtinSearch) stop() bool {
+	if s.aborted {
+		return true
+	}
+	s.nodes++
+	if s.nodes%2048 == 0 {
+		if s.ctx.Err() != nil || (!s.deadline.IsZero() && time.Now().After(s.deadline)) {
+			s.aborted = true
+		}
+	}
+	return s.aborted
+}
+
+// evaluate returns the static evaluation of b for the side to move.
+func evaluate(b *internal.Board) int {
+	score := internal.Eval(b).Score()
+	if b.SideToMove == internal.Black {
+		return -score
+	}
+	return score
+}
+
+// negamax returns the score of b for the side to move searched depth plies
+// deep, and the line leading to it.
+func (s *builtinSearch) negamax(b *internal.Board, depth, ply, alpha, beta int) (int, []internal.Move) {
+	if s.stop() {
+		return 0, nil
+	}
+	moves := b.LegalMoves()
+	if len(moves) == 0 {
+		if check, _ := b.IsCheckOrMate(); check {
+			return -mateScore + ply, nil
+		}
+		return 0, nil
+	}
+	if ply > 0 && (b.Rule50 >= 100 || b.InsufficientMaterial()) {
+		return 0, nil
+	}
+	if depth == 0 {
+		return s.quiesce(b, builtinQuiescence, alpha, beta), nil
+	}
+
+	orderMoves(b, moves)
+	var best []internal.Move
+	for _, m := range moves {
+		score, line := s.negamax(b.MakeMove(m), depth-1, ply+1, -beta, -alpha)
+		score = -score
+		if s.aborted {
+			return 0, nil
+		}
+		if score > alpha || best == nil {
+			best = append([]internal.Move{m}, line...)
+		}
+		if score > alpha {
+			alpha = score
+			if alpha >= beta {
+				break
+			}
+		}
+	}
+	return alpha, best
+}
+
+// quiesce searches the captures that do not lose material, so that the
+// position is not evaluated in the middle of an exchange.
+func (s *builtinSearch) quiesce(b *internal.Board, depth, alpha, beta int) int {
+	standPat := evaluate(b)
+	if standPat >= beta || depth == 0 || s.stop() {
+		return standPat
+	}
+	alpha = max(alpha, standPat)
+
+	var captures []internal.Move
+	for _, m := range b.LegalMoves() {
+		if isCapture(b, m) && b.SEE(m) >= 0 {
+			captures = append(captures, m)
+		}
+	}
+	orderMoves(b, captures)
+	for _, m := range captures {
+		score := -s.quiesce(b.MakeMove(m), depth-1, -beta, -alpha)
+		if s.aborted {
+			return alpha
+		}
+		if score > alpha {
+			alpha = score
+			if alpha >= beta {
+				break
+			}
+		}
+	}
+	return alpha
+}
+
+// isCapture reports whether m takes a piece or promotes.
+func isCapture(b *internal.Board, m internal.Move) bool {
+	target := b.Piece[m.To]
+	if m.Promotion != internal.NoPiece {
+		return true
+	}
+	if target != internal.NoPiece {
+		return target.Color() != b.Piece[m.From].Color() // not castling
+	}
+	return b.Piece[m.From].Type() == internal.Pawn && m.To == b.EpSquare
+}
+
+// orderMoves puts the captures first, best exchanges first, so that
+// alpha-beta cuts off early.
+func orderMoves(b *internal.Board, moves []internal.Move) {
+	gain := make(map[internal.Move]int, len(moves))
+	for _, m := range moves {
+		if isCapture(b, m) {
+			gain[m] = 10000 + b.SEE(m)
+		}
+	}
+	sort.SliceStable(moves, func(i, j int) bool { return gain[moves[i]] > gain[moves[j]] })
+}
+
+// Compile-time check that the built-in engine satisfies Analyzer.
+var _ Analyzer = (*Builtin)(nil)
@@ -0,0 +1,88 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuiltin_Analyze(t *testing.T) {
+	eng := NewBuiltin()
+	defer func() { _ = eng.Close() }()
+	ctx := context.Background()
+
+	tests := []struct {
+		name  string
+		fen   string
+		opts  AnalysisOptions
+		move  string
+		score Score
+	}{
+		{
+			name:  "back rank mate",
+			fen:   "6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1",
+			move:  "a1a8",
+			score: Score{Mate: 1, IsMate: true},
+		},
+		{
+			name:  "mate by Black, scored from White's side",
+			fen:   "r5k1/8/8/8/8/8/5PPP/6K1 b - - 0 1",
+			move:  "a8a1",
+			score: Score{Mate: -1, IsMate: true},
+		},
+		{
+			name: "takes the hanging queen",
+			fen:  "4k3/8/8/3q4/8/8/3R4/4K3 w - - 0 1",
+			opts: AnalysisOptions{Depth: 2},
+			move: "d2d5",
+		},
+		{
+			name: "does not take a defended pawn with the queen",
+			fen:  "4k3/8/2p5/3p4/8/8/8/3QK3 w - - 0 1",
+			opts: AnalysisOptions{Depth: 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := eng.Analyze(ctx, tt.fen, tt.opts)
+			require.NoError(t, err)
+			require.Len(t, result.Lines, 1)
+			line := result.Lines[0]
+			require.NotEmpty(t, line.Moves)
+			if tt.move == "" {
+				assert.NotEqual(t, "d1d5", line.Moves[0])
+				return
+			}
+			assert.Equal(t, tt.move, line.Moves[0])
+			if tt.score.IsMate {
+				assert.Equal(t, tt.score, line.Score)
+			}
+		})
+	}
+}
+
+func TestBuiltin_NoMoves(t *testing.T) {
+	result, err := NewBuiltin().Analyze(context.Background(), "7k/5Q2/6K1/8/8/8/8/8 b - - 0 1", AnalysisOptions{})
+	require.NoError(t, err)
+	require.Len(t, result.Lines, 1)
+	assert.Empty(t, result.Lines[0].Moves, "stalemate has no moves")
+	assert.Equal(t, Score{}, result.Lines[0].Score)
+}
+
+func TestBuiltin_MoveTime(t *testing.T) {
+	start := time.Now()
+	result, err := NewBuiltin().Analyze(context.Background(),
+		"r1bqkbnr/pppp1ppp/2n5/4p3/4P3/5N2/PPPP1PPP/RNBQKB1R w KQkq - 2 3",
+		AnalysisOptions{MoveTime: 200 * time.Millisecond})
+	require.NoError(t, err)
+	assert.Less(t, time.Since(start), 2*time.Second)
+	require.Len(t, result.Lines, 1)
+	assert.NotEmpty(t, result.Lines[0].Moves)
+	assert.GreaterOrEqual(t, result.Depth, 1)
+
+	_, err = NewBuiltin().Analyze(context.Background(), "not a fen", AnalysisOptions{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,413 @@
+package engine
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kyleboon/gochess/internal"
+)
+
+const (
+	// featureTimeout is how long to wait for "feature ... done=1" after
+	// "protover 2". Protocol version 1 engines never answer, so the timeout
+	// is not an error.
+	featureTimeout = 2 * time.Second
+
+	// cecpMateScore is the score offset CECP engines use to report mates:
+	// 100000+N means mate in N moves, -100000-N means mated in N moves.
+	cecpMateScore = 100000
+)
+
+// CECPEngine manages a chess engine process speaking the Chess Engine
+// Communication Protocol (also known as the xboard or winboard protocol).
+// Classic engines such as Crafty and GNU Chess use this protocol instead of UCI.
+type CECPEngine struct {
+	cmd      *exec.Cmd
+	stdin    io.WriteCloser
+	lines    chan string
+	mu       sync.Mutex
+	logger   *slog.Logger
+	features map[string]string
+}
+
+// NewCECP starts a CECP engine process and performs the protocol handshake.
+func NewCECP(ctx context.Context, path string, logger *slog.Logger) (*CECPEngine, error) {
+	return NewCECPWithOptions(ctx, path, logger, Options{})
+}
+
+// NewCECPWithOptions starts a CECP engine process, performs the protocol
+// handshake and applies the given options. Threads maps to the "cores" command
+// and Hash to the "memory" command; engines ignore commands they don't support.
+func NewCECPWithOptions(ctx context.Context, path string, logger *slog.Logger, opts Options) (*CECPEngine, error) {
+	cmd := exec.CommandContext(ctx, path)
+
+	stdinPipe, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("engine stdin pipe: %w", err)
+	}
+
+	stdoutPipe, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("engine stdout pipe: %w", err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("engine start: %w", err)
+	}
+
+	e := NewCECPFromStreams(stdinPipe, stdoutPipe, logger)
+	e.cmd = cmd
+
+	if err := e.handshake(ctx); err != nil {
+		_ = cmd.Process.Kill()
+		return nil, err
+	}
+
+	if opts.Threads > 0 {
+		if err := e.send(fmt.Sprintf("cores %d", opts.Threads)); err != nil {
+			_ = cmd.Process.Kill()
+			return nil, err
+		}
+	}
+	if opts.Hash > 0 {
+		if err := e.send(fmt.Sprintf("memory %d", opts.Hash)); err != nil {
+			_ = cmd.Process.Kill()
+			return nil, err
+		}
+	}
+
+	return e, nil
+}
+
+// NewCECPFromStreams creates a CECPEngine from pre-existing streams (for
+// testing). No handshake is performed.
+func NewCECPFromStreams(stdin io.WriteCloser, stdout io.Reader, logger *slog.Logger) *CECPEngine {
+	e := &CECPEngine{
+		stdin:    stdin,
+		lines:    make(chan string, 64),
+		logger:   logger.With("component", "engine"),
+		features: make(map[string]string),
+	}
+	go func() {
+		defer close(e.lines)
+		scan := bufio.NewScanner(stdout)
+		for scan.Scan() {
+			e.lines <- scan.Text()
+		}
+	}()
+	return e
+}
+
+// Feature returns the value of a feature announced by the engine during the
+// handshake (e.g. "myname", "setboard").
+func (e *CECPEngine) Feature(name string) (string, bool) {
+	v, ok := e.features[name]
+	return v, ok
+}
+
+// Close sends "quit" and waits for the engine process to exit.
+func (e *CECPEngine) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	_ = e.sendLocked("quit")
+	_ = e.stdin.Close()
+
+	if e.cmd != nil {
+		return e.cmd.Wait()
+	}
+	return nil
+}
+
+// handshake switches the engine into xboard mode and collects its features.
+func (e *CECPEngine) handshake(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := e.sendLocked("xboard"); err != nil {
+		return err
+	}
+	if err := e.sendLocked("protover 2"); err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(featureTimeout)
+	for {
+		line, err := e.readLineLocked(ctx, time.Until(deadline))
+		if err == errReadTimeout {
+			e.logger.Debug("engine did not finish feature negotiation, assuming protocol version 1")
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("engine handshake: %w", err)
+		}
+		if !strings.HasPrefix(line, "feature ") {
+			continue
+		}
+		for name, value := range parseFeatures(line) {
+			e.features[name] = value
+			if err := e.sendLocked("accepted " + name); err != nil {
+				return err
+			}
+			if name == "done" {
+				switch value {
+				case "1":
+					return nil
+				case "0":
+					// The engine asked for more time to initialize.
+					deadline = time.Now().Add(time.Hour)
+				}
+			}
+		}
+	}
+}
+
+// Analyze runs a position analysis and returns the result. CECP has no
+// equivalent of MultiPV, so at most one line is returned.
+func (e *CECPEngine) Analyze(ctx context.Context, fen string, opts AnalysisOptions) (*AnalysisResult, error) {
+	if opts.Depth <= 0 && opts.MoveTime <= 0 {
+		opts.Depth = 20
+	}
+	if opts.MultiPV > 1 {
+		e.logger.Debug("CECP engines do not support MultiPV, returning a single line", "requested", opts.MultiPV)
+	}
+
+	board, err := internal.ParseFen(fen)
+	if err != nil {
+		return nil, fmt.Errorf("invalid FEN: %w", err)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	// CECP's "st" takes whole seconds
+	limit := fmt.Sprintf("sd %d", opts.Depth)
+	if opts.MoveTime > 0 {
+		limit = fmt.Sprintf("st %d", max(1, int(opts.MoveTime.Round(time.Second)/time.Second)))
+	}
+	for _, cmd := range []string{
+		"new",
+		"force",
+		"post",
+		"setboard " + fen,
+		limit,
+		"go",
+	} {
+		if err := e.sendLocked(cmd); err != nil {
+			return nil, err
+		}
+	}
+
+	var best *AnalysisLine
+	for {
+		line, err := e.readLineLocked(ctx, 0)
+		if err != nil {
+			return nil, fmt.Errorf("analysis failed: %w", err)
+		}
+		if isCECPError(line) {
+			return nil, fmt.Errorf("analysis failed: engine replied %q", line)
+		}
+		if isCECPMove(line) {
+			break
+		}
+		al := parseThinkingLine(line, board)
+		if al != nil && (best == nil || al.Depth >= best.Depth) {
+			best = al
+		}
+	}
+
+	// Stop the engine from pondering or playing on after its move.
+	if err := e.sendLocked("force"); err != nil {
+		return nil, err
+	}
+
+	result := &AnalysisResult{
+		FEN:   fen,
+		Depth: opts.Depth,
+	}
+	if best != nil && result.Depth == 0 {
+		result.Depth = best.Depth
+	}
+	if best != nil {
+		// Thinking output is from the side to move's perspective.
+		if board.SideToMove == internal.Black {
+			if best.Score.IsMate {
+				best.Score.Mate = -best.Score.Mate
+			} else {
+				best.Score.Centipawns = -best.Score.Centipawns
+			}
+		}
+		result.Lines = append(result.Lines, *best)
+	}
+	return result, nil
+}
+
+// send writes a command to the engine's stdin (acquires lock).
+func (e *CECPEngine) send(cmd string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.sendLocked(cmd)
+}
+
+// sendLocked writes a command to the engine's stdin (caller must hold lock).
+func (e *CECPEngine) sendLocked(cmd string) error {
+	e.logger.Debug("engine send", "cmd", cmd)
+	_, err := fmt.Fprintf(e.stdin, "%s\n", cmd)
+	if err != nil {
+		return fmt.Errorf("engine send %q: %w", cmd, err)
+	}
+	return nil
+}
+
+// errReadTimeout is returned by readLineLocked when the timeout expires.
+var errReadTimeout = fmt.Errorf("engine: read timed out")
+
+// readLineLocked reads the next line of engine output. A timeout of zero
+// waits indefinitely (caller must hold lock).
+func (e *CECPEngine) readLineLocked(ctx context.Context, timeout time.Duration) (string, error) {
+	var timer <-chan time.Time
+	if timeout > 0 {
+		t := time.NewTimer(timeout)
+		defer t.Stop()
+		timer = t.C
+	}
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case <-timer:
+		return "", errReadTimeout
+	case line, ok := <-e.lines:
+		if !ok {
+			return "", fmt.Errorf("engine: unexpected EOF")
+		}
+		e.logger.Debug("engine recv", "line", line)
+		return line, nil
+	}
+}
+
+// parseFeatures parses a "feature" line such as
+//
+//	feature setboard=1 myname="Crafty 25.2" done=1
+//
+// into a map of feature names to values (quotes removed).
+func parseFeatures(line string) map[string]string {
+	features := make(map[string]string)
+	s := strings.TrimPrefix(line, "feature ")
+	for s = strings.TrimSpace(s); s != ""; s = strings.TrimSpace(s) {
+		eq := strings.IndexByte(s, '=')
+		if eq < 0 {
+			break
+		}
+		name := s[:eq]
+		s = s[eq+1:]
+		var value string
+		if strings.HasPrefix(s, `"`) {
+			end := strings.IndexByte(s[1:], '"')
+			if end < 0 {
+				value, s = s[1:], ""
+			} else {
+				value, s = s[1:end+1], s[end+2:]
+			}
+		} else if sp := strings.IndexByte(s, ' '); sp >= 0 {
+			value, s = s[:sp], s[sp:]
+		} else {
+			value, s = s, ""
+		}
+		features[name] = value
+	}
+	return features
+}
+
+// isCECPMove reports whether line ends a search: either the engine's move or
+// a game result claim when the position is already over.
+func isCECPMove(line string) bool {
+	for _, prefix := range []string{"move ", "resign", "1-0", "0-1", "1/2-1/2"} {
+		if strings.HasPrefix(line, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// isCECPError reports whether line is an error reply from the engine.
+func isCECPError(line string) bool {
+	return strings.HasPrefix(line, "Error") || strings.HasPrefix(line, "Illegal move")
+}
+
+// parseThinkingLine parses a CECP thinking output line of the form
+//
+//	ply score time nodes pv
+//
+// into an AnalysisLine. Time is in centiseconds and the PV is usually in SAN;
+// PV moves are converted to UCI notation by replaying them from board.
+// Returns nil for lines that are not thinking output.
+func parseThinkingLine(line string, board *internal.Board) *AnalysisLine {
+	fields := strings.Fields(line)
+	if len(fields) < 4 {
+		return nil
+	}
+	// Some engines append '.' or '&' to the ply to mark partial iterations.
+	depth, err := strconv.Atoi(strings.TrimRight(fields[0], ".&"))
+	if err != nil || depth <= 0 {
+		return nil
+	}
+	score, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return nil
+	}
+	centis, err := strconv.ParseInt(fields[2], 10, 64)
+	if err != nil {
+		return nil
+	}
+	nodes, err := strconv.ParseInt(fields[3], 10, 64)
+	if err != nil {
+		return nil
+	}
+
+	al := &AnalysisLine{
+		Rank:  1,
+		Depth: depth,
+		Nodes: nodes,
+		Moves: pvToUci(fields[4:], board),
+	}
+	switch {
+	case score >= cecpMateScore:
+		al.Score = Score{Mate: score - cecpMateScore, IsMate: true}
+	case score <= -cecpMateScore:
+		al.Score = Score{Mate: score + cecpMateScore, IsMate: true}
+	default:
+		al.Score = Score{Centipawns: score}
+	}
+	if centis > 0 {
+		al.NPS = nodes * 100 / centis
+	}
+	return al
+}
+
+// pvToUci converts a CECP principal variation to UCI notation. Move numbers
+// and annotations are skipped; conversion stops at the first token that is
+// not a legal move.
+func pvToUci(tokens []string, board *internal.Board) []string {
+	var moves []string
+	for _, tok := range tokens {
+		tok = strings.TrimRight(tok, "!?")
+		if tok == "" || tok[0] == '<' || tok[0] == '(' || (tok[0] >= '0' && tok[0] <= '9' && strings.Contains(tok, ".")) {
+			continue
+		}
+		m, err := board.ParseMove(tok)
+		if err != nil || m == internal.NullMove {
+			break
+		}
+		moves = append(moves, m.Uci(board))
+		board = board.MakeMove(m)
+	}
+	return moves
+}
@@ -0,0 +1,229 @@
+package engine
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/kyleboon/gochess/internal"
+	"github.com/kyleboon/gochess/internal/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const startFEN = "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
+
+func TestParseThinkingLine(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want *AnalysisLine
+	}{
+		{
+			name: "centipawn score with SAN pv",
+			line: "12 35 150 1500000 e4 e5 Nf3",
+			want: &AnalysisLine{
+				Rank:  1,
+				Depth: 12,
+				Score: Score{Centipawns: 35},
+				Nodes: 1500000,
+				NPS:   1000000,
+				Moves: []string{"e2e4", "e7e5", "g1f3"},
+			},
+		},
+		{
+			name: "move numbers in pv are skipped",
+			line: "8. -12 50 20000 1. d4 d5 2. c4",
+			want: &AnalysisLine{
+				Rank:  1,
+				Depth: 8,
+				Score: Score{Centipawns: -12},
+				Nodes: 20000,
+				NPS:   40000,
+				Moves: []string{"d2d4", "d7d5", "c2c4"},
+			},
+		},
+		{
+			name: "mate score",
+			line: "20 100003 300 900000 e4",
+			want: &AnalysisLine{
+				Rank:  1,
+				Depth: 20,
+				Score: Score{Mate: 3, IsMate: true},
+				Nodes: 900000,
+				NPS:   300000,
+				Moves: []string{"e2e4"},
+			},
+		},
+		{
+			name: "mated score",
+			line: "20 -100002 0 900000",
+			want: &AnalysisLine{
+				Rank:  1,
+				Depth: 20,
+				Score: Score{Mate: -2, IsMate: true},
+				Nodes: 900000,
+			},
+		},
+		{
+			name: "pv stops at unparsable move",
+			line: "5 10 10 1000 e4 Qxh7 e5",
+			want: &AnalysisLine{
+				Rank:  1,
+				Depth: 5,
+				Score: Score{Centipawns: 10},
+				Nodes: 1000,
+				NPS:   10000,
+				Moves: []string{"e2e4"},
+			},
+		},
+		{
+			name: "feature line returns nil",
+			line: "feature setboard=1 done=1",
+			want: nil,
+		},
+		{
+			name: "move line returns nil",
+			line: "move e2e4",
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board, err := internal.ParseFen(startFEN)
+			require.NoError(t, err)
+			got := parseThinkingLine(tt.line, board)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseFeatures(t *testing.T) {
+	got := parseFeatures(`feature setboard=1 myname="Crafty 25.2" analyze=1 done=1`)
+	assert.Equal(t, map[string]string{
+		"setboard": "1",
+		"myname":   "Crafty 25.2",
+		"analyze":  "1",
+		"done":     "1",
+	}, got)
+}
+
+func TestParseProtocol(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Protocol
+		wantErr bool
+	}{
+		{in: "", want: ProtocolUCI},
+		{in: "uci", want: ProtocolUCI},
+		{in: "CECP", want: ProtocolCECP},
+		{in: "xboard", want: ProtocolCECP},
+		{in: "winboard", want: ProtocolCECP},
+		{in: "telnet", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseProtocol(tt.in)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// mockCECPEngine simulates CECP responses using io.Pipe for testing.
+func mockCECPEngine(t *testing.T, responses []string) (*CECPEngine, func()) {
+	t.Helper()
+
+	engineStdinR, engineStdinW := io.Pipe()
+	engineStdoutR, engineStdoutW := io.Pipe()
+
+	e := NewCECPFromStreams(engineStdinW, engineStdoutR, logging.Discard())
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer func() { _ = engineStdoutW.Close() }()
+		_, _ = io.WriteString(engineStdoutW, strings.Join(responses, "\n")+"\n")
+	}()
+
+	go func() {
+		_, _ = io.Copy(io.Discard, engineStdinR)
+	}()
+
+	cleanup := func() {
+		_ = engineStdinR.Close()
+		_ = engineStdinW.Close()
+		<-done
+	}
+
+	return e, cleanup
+}
+
+func TestMockCECPEngine_Handshake(t *testing.T) {
+	e, cleanup := mockCECPEngine(t, []string{
+		`feature ping=1 setboard=1 myname="Test Engine"`,
+		"feature done=1",
+	})
+	defer cleanup()
+
+	require.NoError(t, e.handshake(context.Background()))
+
+	name, ok := e.Feature("myname")
+	assert.True(t, ok)
+	assert.Equal(t, "Test Engine", name)
+}
+
+func TestMockCECPEngine_Analyze(t *testing.T) {
+	e, cleanup := mockCECPEngine(t, []string{
+		"1 10 1 20 e4",
+		"2 15 2 200 e4 e5",
+		"3 20 4 2000 e4 e5 Nf3",
+		"move e2e4",
+	})
+	defer cleanup()
+
+	result, err := e.Analyze(context.Background(), startFEN, AnalysisOptions{Depth: 3, MultiPV: 3})
+	require.NoError(t, err)
+	require.NotNil(t, result)
+
+	assert.Equal(t, startFEN, result.FEN)
+	assert.Equal(t, 3, result.Depth)
+	require.Len(t, result.Lines, 1)
+	assert.Equal(t, 3, result.Lines[0].Depth)
+	assert.Equal(t, Score{Centipawns: 20}, result.Lines[0].Score)
+	assert.Equal(t, []string{"e2e4", "e7e5", "g1f3"}, result.Lines[0].Moves)
+}
+
+func TestMockCECPEngine_AnalyzeBlackToMove(t *testing.T) {
+	e, cleanup := mockCECPEngine(t, []string{
+		"4 25 10 5000 e5 Nf3",
+		"move e7e5",
+	})
+	defer cleanup()
+
+	fen := "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1"
+	result, err := e.Analyze(context.Background(), fen, AnalysisOptions{Depth: 4})
+	require.NoError(t, err)
+	require.Len(t, result.Lines, 1)
+
+	// Scores are normalized to White's perspective.
+	assert.Equal(t, Score{Centipawns: -25}, result.Lines[0].Score)
+	assert.Equal(t, []string{"e7e5", "g1f3"}, result.Lines[0].Moves)
+}
+
+func TestMockCECPEngine_AnalyzeError(t *testing.T) {
+	e, cleanup := mockCECPEngine(t, []string{
+		"Error (unknown command): setboard",
+	})
+	defer cleanup()
+
+	_, err := e.Analyze(context.Background(), startFEN, AnalysisOptions{Depth: 4})
+	assert.Error(t, err)
+}
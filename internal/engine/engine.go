@@ -0,0 +1,55 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// Analyzer is the common interface implemented by all engine adapters. It lets
+// callers analyze positions without caring which protocol the engine speaks.
+type Analyzer interface {
+	// Analyze runs a position analysis and returns the result. Scores are
+	// always reported from White's perspective.
+	Analyze(ctx context.Context, fen string, opts AnalysisOptions) (*AnalysisResult, error)
+	// Close shuts the engine down and releases its resources.
+	Close() error
+}
+
+// Protocol identifies the communication protocol spoken by an engine.
+type Protocol string
+
+const (
+	ProtocolUCI  Protocol = "uci"
+	ProtocolCECP Protocol = "cecp" // Chess Engine Communication Protocol (xboard/winboard)
+)
+
+// ParseProtocol parses a protocol name. An empty string defaults to UCI, and
+// "xboard" and "winboard" are accepted as aliases for CECP.
+func ParseProtocol(s string) (Protocol, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "uci":
+		return ProtocolUCI, nil
+	case "cecp", "xboard", "winboard":
+		return ProtocolCECP, nil
+	}
+	return "", fmt.Errorf("unknown engine protocol %q (expected uci or cecp)", s)
+}
+
+// Open starts the engine at path using the given protocol.
+func Open(ctx context.Context, path string, protocol Protocol, logger *slog.Logger, opts Options) (Analyzer, error) {
+	switch protocol {
+	case ProtocolUCI, "":
+		return NewWithOptions(ctx, path, logger, opts)
+	case ProtocolCECP:
+		return NewCECPWithOptions(ctx, path, logger, opts)
+	}
+	return nil, fmt.Errorf("unsupported engine protocol %q", protocol)
+}
+
+// Compile-time checks that both adapters satisfy Analyzer.
+var (
+	_ Analyzer = (*Engine)(nil)
+	_ Analyzer = (*CECPEngine)(nil)
+)
@@ -53,7 +53,7 @@ func NewWithOptions(ctx context.Context, path string, logger *slog.Logger, opts
 		cmd:    cmd,
 		stdin:  stdinPipe,
 		scan:   bufio.NewScanner(stdoutPipe),
-		logger: logger,
+		logger: logger.With("component", "engine"),
 	}
 
 	// Send "uci" and wait for "uciok"
@@ -96,7 +96,7 @@ func NewFromStreams(stdin io.WriteCloser, stdout io.Reader, logger *slog.Logger)
 	return &Engine{
 		stdin:  stdin,
 		scan:   bufio.NewScanner(stdout),
-		logger: logger,
+		logger: logger.With("component", "engine"),
 	}
 }
 
@@ -5,6 +5,7 @@ import (
 	"io"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/kyleboon/gochess/internal/logging"
 	"github.com/stretchr/testify/assert"
@@ -291,3 +292,22 @@ func TestMockEngine_AnalyzeContextCanceled(t *testing.T) {
 	_ = engineStdinW.Close()
 	_ = engineStdoutW.Close()
 }
+
+func TestMockEngine_AnalyzeMoveTime(t *testing.T) {
+	responses := []string{
+		"info depth 7 multipv 1 score cp 25 nodes 9000 nps 90000 pv e2e4 e7e5",
+		"info depth 9 multipv 1 score cp 30 nodes 20000 nps 100000 pv d2d4 d7d5",
+		"bestmove d2d4",
+	}
+	e, cleanup := mockEngine(t, responses)
+	defer cleanup()
+
+	fen := "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
+	result, err := e.Analyze(context.Background(), fen, AnalysisOptions{MoveTime: 100 * time.Millisecond})
+	require.NoError(t, err)
+	require.Len(t, result.Lines, 1)
+
+	// A timed search reports the depth reached rather than a requested depth
+	assert.Equal(t, 9, result.Depth)
+	assert.Equal(t, []string{"d2d4", "d7d5"}, result.Lines[0].Moves)
+}
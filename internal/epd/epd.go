@@ -0,0 +1,198 @@
+// Package epd parses Extended Position Description records and runs EPD test
+// suites such as WAC and STS against a chess engine.
+package epd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Position is a single EPD record: a position and its operations.
+type Position struct {
+	FEN  string              // full six-field FEN
+	Ops  map[string][]string // operands by opcode, quotes removed
+	Line int                 // 1-based line number in the source
+}
+
+// Parse reads EPD records, one per line. Blank lines and lines starting with
+// '#' are skipped.
+func Parse(r io.Reader) ([]*Position, error) {
+	var positions []*Position
+	scanner := bufio.NewScanner(r)
+	lineNr := 0
+	for scanner.Scan() {
+		lineNr++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		pos, err := ParseLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNr, err)
+		}
+		pos.Line = lineNr
+		positions = append(positions, pos)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return positions, nil
+}
+
+// ParseLine parses a single EPD record. The move counters of the FEN are
+// taken from the hmvc and fmvn operations when present.
+func ParseLine(line string) (*Position, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 4 {
+		return nil, fmt.Errorf("invalid EPD: expected at least 4 position fields")
+	}
+
+	// Everything after the fourth field is the operation list
+	rest := line
+	for i := 0; i < 4; i++ {
+		rest = strings.TrimLeft(rest, " \t")
+		rest = rest[len(fields[i]):]
+	}
+
+	pos := &Position{Ops: make(map[string][]string)}
+	ops, err := splitOps(rest)
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range ops {
+		if len(op) == 0 {
+			continue
+		}
+		pos.Ops[op[0]] = op[1:]
+	}
+
+	halfmove, fullmove := "0", "1"
+	if v := pos.first("hmvc"); v != "" {
+		halfmove = v
+	}
+	if v := pos.first("fmvn"); v != "" {
+		fullmove = v
+	}
+	pos.FEN = strings.Join(append(fields[:4:4], halfmove, fullmove), " ")
+	return pos, nil
+}
+
+// splitOps splits an operation list into operations, each given as the
+// opcode followed by its operands. Quoted operands may contain spaces and
+// semicolons.
+func splitOps(s string) ([][]string, error) {
+	var (
+		ops     [][]string
+		current []string
+		token   strings.Builder
+		inToken bool
+		quoted  bool
+	)
+	flush := func() {
+		if inToken {
+			current = append(current, token.String())
+			token.Reset()
+			inToken = false
+		}
+	}
+	for _, r := range s {
+		switch {
+		case quoted:
+			if r == '"' {
+				quoted = false
+			} else {
+				token.WriteRune(r)
+			}
+		case r == '"':
+			quoted, inToken = true, true
+		case r == ';':
+			flush()
+			ops = append(ops, current)
+			current = nil
+		case r == ' ' || r == '\t':
+			flush()
+		default:
+			token.WriteRune(r)
+			inToken = true
+		}
+	}
+	if quoted {
+		return nil, fmt.Errorf("invalid EPD: unterminated string")
+	}
+	flush()
+	if len(current) > 0 {
+		ops = append(ops, current)
+	}
+	return ops, nil
+}
+
+// first returns the first operand of an opcode, or "" if absent.
+func (p *Position) first(opcode string) string {
+	if v := p.Ops[opcode]; len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+// ID returns the "id" operation of the position.
+func (p *Position) ID() string {
+	return p.first("id")
+}
+
+// BestMoves returns the moves of the "bm" operation (SAN).
+func (p *Position) BestMoves() []string {
+	return p.Ops["bm"]
+}
+
+// AvoidMoves returns the moves of the "am" operation (SAN).
+func (p *Position) AvoidMoves() []string {
+	return p.Ops["am"]
+}
+
+// idNumber matches the running number at the end of a suite id, as in
+// "WAC.001" or "STS(v1.0) Undermining.001".
+var idNumber = regexp.MustCompile(`[.\s]*\d+$`)
+
+// Theme returns the theme of the position, derived from its id: the id
+// without its running number and without a parenthesized version prefix.
+// "STS(v1.0) Undermining.001" has theme "Undermining" and "WAC.001" has
+// theme "WAC".
+func (p *Position) Theme() string {
+	id := p.ID()
+	if i := strings.Index(id, ") "); i >= 0 && strings.Contains(id[:i], "(") {
+		id = id[i+2:]
+	}
+	return strings.TrimSpace(idNumber.ReplaceAllString(id, ""))
+}
+
+// Points returns the points awarded per move (SAN), as given by an STS
+// style c0 comment such as "f5=10, Be5+=2, Bf2=3". It returns nil when the
+// position has no point table.
+func (p *Position) Points() map[string]int {
+	c0 := strings.Join(p.Ops["c0"], " ")
+	if !strings.Contains(c0, "=") {
+		return nil
+	}
+	points := make(map[string]int)
+	for _, entry := range strings.Split(c0, ",") {
+		// Split at the last '=' so that promotions such as "e8=Q=10" work
+		entry = strings.TrimSpace(entry)
+		i := strings.LastIndex(entry, "=")
+		if i < 0 {
+			continue
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(entry[i+1:]))
+		if err != nil {
+			continue
+		}
+		points[strings.TrimSpace(entry[:i])] = n
+	}
+	if len(points) == 0 {
+		return nil
+	}
+	return points
+}
@@ -0,0 +1,75 @@
+package epd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseLine(t *testing.T) {
+	pos, err := ParseLine(`2rr3k/pp3pp1/1nnqbN1p/3pN3/2pP4/2P3Q1/PPB4P/R4RK1 w - - bm Qg6; id "WAC.001";`)
+	if err != nil {
+		t.Fatalf("ParseLine: %v", err)
+	}
+	if want := "2rr3k/pp3pp1/1nnqbN1p/3pN3/2pP4/2P3Q1/PPB4P/R4RK1 w - - 0 1"; pos.FEN != want {
+		t.Errorf("FEN = %q, want %q", pos.FEN, want)
+	}
+	if got := pos.BestMoves(); len(got) != 1 || got[0] != "Qg6" {
+		t.Errorf("BestMoves = %v, want [Qg6]", got)
+	}
+	if got := pos.ID(); got != "WAC.001" {
+		t.Errorf("ID = %q, want WAC.001", got)
+	}
+	if got := pos.Theme(); got != "WAC" {
+		t.Errorf("Theme = %q, want WAC", got)
+	}
+	if pos.Points() != nil {
+		t.Errorf("Points = %v, want nil", pos.Points())
+	}
+}
+
+func TestParseLine_Operations(t *testing.T) {
+	pos, err := ParseLine(`8/8/8/8/8/8/4k3/4K3 b - - am Kd3 Kf3; hmvc 12; fmvn 40; c0 "f5=10, Be5+=2, e8=Q=3"; id "STS(v1.0) Undermining.001";`)
+	if err != nil {
+		t.Fatalf("ParseLine: %v", err)
+	}
+	if want := "8/8/8/8/8/8/4k3/4K3 b - - 12 40"; pos.FEN != want {
+		t.Errorf("FEN = %q, want %q", pos.FEN, want)
+	}
+	if got := pos.AvoidMoves(); len(got) != 2 || got[0] != "Kd3" || got[1] != "Kf3" {
+		t.Errorf("AvoidMoves = %v, want [Kd3 Kf3]", got)
+	}
+	if got := pos.Theme(); got != "Undermining" {
+		t.Errorf("Theme = %q, want Undermining", got)
+	}
+	points := pos.Points()
+	for move, want := range map[string]int{"f5": 10, "Be5+": 2, "e8=Q": 3} {
+		if points[move] != want {
+			t.Errorf("Points[%s] = %d, want %d", move, points[move], want)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	input := `# comment
+2rr3k/pp3pp1/1nnqbN1p/3pN3/2pP4/2P3Q1/PPB4P/R4RK1 w - - bm Qg6; id "WAC.001";
+
+8/7p/5k2/5p2/p1p2P2/Pr1pPK2/1P1R3P/8 b - - bm Rxb2; id "WAC.002";
+`
+	positions, err := Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(positions) != 2 {
+		t.Fatalf("got %d positions, want 2", len(positions))
+	}
+	if positions[1].Line != 4 {
+		t.Errorf("Line = %d, want 4", positions[1].Line)
+	}
+
+	if _, err := Parse(strings.NewReader("8/8/8 w\n")); err == nil {
+		t.Error("expected an error for a short record")
+	}
+	if _, err := Parse(strings.NewReader(`8/8/8/8/8/8/4k3/4K3 w - - id "open;`)); err == nil {
+		t.Error("expected an error for an unterminated string")
+	}
+}
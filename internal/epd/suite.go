@@ -0,0 +1,184 @@
+package epd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kyleboon/gochess/internal"
+	"github.com/kyleboon/gochess/internal/engine"
+)
+
+// Result is the outcome of a single suite position.
+type Result struct {
+	Position  *Position
+	Move      string // the engine's move (SAN), empty if it had none
+	Passed    bool   // the move satisfies the bm and am operations
+	Points    int    // points scored; 1 for a pass without a point table
+	MaxPoints int    // points available for the position
+	Depth     int    // depth reached by the engine
+	Err       error  // set when the position could not be scored
+}
+
+// Score aggregates the results of a group of positions.
+type Score struct {
+	Theme     string
+	Positions int
+	Passed    int
+	Points    int
+	MaxPoints int
+}
+
+// Report is the outcome of a suite run.
+type Report struct {
+	Results []Result
+	Themes  []Score // per theme, in order of first appearance
+	Total   Score
+}
+
+// Run searches every position with eng and scores the engine's moves. The
+// progress callback, if not nil, is called after each position. Run stops at
+// the first engine error, returning the results so far along with the error.
+func Run(ctx context.Context, eng engine.Analyzer, positions []*Position, opts engine.AnalysisOptions, progress func(Result)) (*Report, error) {
+	report := &Report{Total: Score{Theme: "Total"}}
+	themes := make(map[string]*Score)
+	var order []string
+
+	for _, pos := range positions {
+		res, err := runPosition(ctx, eng, pos, opts)
+		if err != nil {
+			report.finish(themes, order)
+			return report, fmt.Errorf("%s: %w", describe(pos), err)
+		}
+		report.Results = append(report.Results, res)
+
+		theme := pos.Theme()
+		if theme == "" {
+			theme = "-"
+		}
+		s, ok := themes[theme]
+		if !ok {
+			s = &Score{Theme: theme}
+			themes[theme] = s
+			order = append(order, theme)
+		}
+		s.add(res)
+		report.Total.add(res)
+
+		if progress != nil {
+			progress(res)
+		}
+	}
+	report.finish(themes, order)
+	return report, nil
+}
+
+// runPosition searches and scores a single position. Problems with the
+// position itself are reported in the result; only engine failures are
+// returned as errors.
+func runPosition(ctx context.Context, eng engine.Analyzer, pos *Position, opts engine.AnalysisOptions) (Result, error) {
+	res := Result{Position: pos, MaxPoints: 1}
+
+	board, err := internal.ParseFen(pos.FEN)
+	if err != nil {
+		res.Err = err
+		return res, nil
+	}
+
+	points := pos.Points()
+	best, err := parseMoves(board, pos.BestMoves())
+	if err != nil {
+		res.Err = err
+		return res, nil
+	}
+	avoid, err := parseMoves(board, pos.AvoidMoves())
+	if err != nil {
+		res.Err = err
+		return res, nil
+	}
+	if len(best) == 0 && len(avoid) == 0 && points == nil {
+		res.Err = fmt.Errorf("no bm or am operation")
+		return res, nil
+	}
+
+	var table map[internal.Move]int
+	if points != nil {
+		table = make(map[internal.Move]int, len(points))
+		res.MaxPoints = 0
+		for san, n := range points {
+			m, err := board.ParseMove(san)
+			if err != nil {
+				res.Err = fmt.Errorf("invalid move %q in point table: %w", san, err)
+				return res, nil
+			}
+			table[m] = n
+			res.MaxPoints = max(res.MaxPoints, n)
+		}
+	}
+
+	result, err := eng.Analyze(ctx, pos.FEN, opts)
+	if err != nil {
+		return res, err
+	}
+	res.Depth = result.Depth
+	if len(result.Lines) == 0 || len(result.Lines[0].Moves) == 0 {
+		res.Err = fmt.Errorf("engine returned no move")
+		return res, nil
+	}
+	move, err := board.ParseMove(result.Lines[0].Moves[0])
+	if err != nil {
+		res.Err = fmt.Errorf("engine move %q: %w", result.Lines[0].Moves[0], err)
+		return res, nil
+	}
+	res.Move = move.San(board)
+
+	res.Passed = (len(best) == 0 || best[move]) && !avoid[move]
+	switch {
+	case table != nil:
+		res.Points = table[move]
+	case res.Passed:
+		res.Points = 1
+	}
+	return res, nil
+}
+
+// parseMoves parses SAN moves into a set.
+func parseMoves(board *internal.Board, sans []string) (map[internal.Move]bool, error) {
+	moves := make(map[internal.Move]bool, len(sans))
+	for _, san := range sans {
+		m, err := board.ParseMove(san)
+		if err != nil {
+			return nil, fmt.Errorf("invalid move %q: %w", san, err)
+		}
+		moves[m] = true
+	}
+	return moves, nil
+}
+
+// add accounts a result in the score.
+func (s *Score) add(res Result) {
+	s.Positions++
+	if res.Passed {
+		s.Passed++
+	}
+	s.Points += res.Points
+	s.MaxPoints += res.MaxPoints
+}
+
+// finish fills in the per-theme scores. Single-theme suites such as WAC
+// report only the total.
+func (r *Report) finish(themes map[string]*Score, order []string) {
+	if len(order) < 2 {
+		return
+	}
+	for _, theme := range order {
+		r.Themes = append(r.Themes, *themes[theme])
+	}
+}
+
+// describe names a position in error messages.
+func describe(pos *Position) string {
+	if id := pos.ID(); id != "" {
+		return id
+	}
+	return fmt.Sprintf("line %d", pos.Line)
+}
@@ -0,0 +1,100 @@
+package epd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/kyleboon/gochess/internal/engine"
+)
+
+// fakeAnalyzer plays a fixed move (UCI) per FEN.
+type fakeAnalyzer struct {
+	moves map[string]string
+}
+
+func (f *fakeAnalyzer) Analyze(_ context.Context, fen string, _ engine.AnalysisOptions) (*engine.AnalysisResult, error) {
+	move, ok := f.moves[fen]
+	if !ok {
+		return nil, fmt.Errorf("unexpected position %s", fen)
+	}
+	return &engine.AnalysisResult{
+		FEN:   fen,
+		Depth: 10,
+		Lines: []engine.AnalysisLine{{Rank: 1, Moves: []string{move}}},
+	}, nil
+}
+
+func (f *fakeAnalyzer) Close() error { return nil }
+
+func TestRun(t *testing.T) {
+	suite := `2rr3k/pp3pp1/1nnqbN1p/3pN3/2pP4/2P3Q1/PPB4P/R4RK1 w - - bm Qg6; id "STS Attack.001";
+8/7p/5k2/5p2/p1p2P2/Pr1pPK2/1P1R3P/8 b - - bm Rxb2; c0 "Rxb2=10, Rb8=3"; id "STS Attack.002";
+r1bqkbnr/pppp1ppp/2n5/4p3/4P3/5N2/PPPP1PPP/RNBQKB1R w KQkq - am Ng5; id "STS Greed.001";
+`
+	positions, err := Parse(strings.NewReader(suite))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	eng := &fakeAnalyzer{moves: map[string]string{
+		positions[0].FEN: "g3g6", // Qg6, the best move
+		positions[1].FEN: "b3b8", // Rb8, partial credit
+		positions[2].FEN: "f3g5", // Ng5, the move to avoid
+	}}
+
+	var seen int
+	report, err := Run(context.Background(), eng, positions, engine.AnalysisOptions{}, func(Result) { seen++ })
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if seen != 3 {
+		t.Errorf("progress called %d times, want 3", seen)
+	}
+
+	want := []struct {
+		move   string
+		passed bool
+		points int
+		max    int
+	}{
+		{"Qg6", true, 1, 1},
+		{"Rb8", false, 3, 10},
+		{"Ng5", false, 0, 1},
+	}
+	for i, w := range want {
+		r := report.Results[i]
+		if r.Err != nil {
+			t.Errorf("result %d: unexpected error %v", i, r.Err)
+		}
+		if r.Move != w.move || r.Passed != w.passed || r.Points != w.points || r.MaxPoints != w.max {
+			t.Errorf("result %d = %s passed=%v %d/%d, want %s passed=%v %d/%d",
+				i, r.Move, r.Passed, r.Points, r.MaxPoints, w.move, w.passed, w.points, w.max)
+		}
+	}
+
+	if len(report.Themes) != 2 {
+		t.Fatalf("got %d themes, want 2", len(report.Themes))
+	}
+	if got := report.Themes[0]; got.Theme != "STS Attack" || got.Positions != 2 || got.Passed != 1 || got.Points != 4 || got.MaxPoints != 11 {
+		t.Errorf("theme 0 = %+v", got)
+	}
+	if got := report.Total; got.Positions != 3 || got.Passed != 1 || got.Points != 4 || got.MaxPoints != 12 {
+		t.Errorf("total = %+v", got)
+	}
+}
+
+func TestRun_EngineError(t *testing.T) {
+	positions, err := Parse(strings.NewReader(`2rr3k/pp3pp1/1nnqbN1p/3pN3/2pP4/2P3Q1/PPB4P/R4RK1 w - - bm Qg6; id "WAC.001";`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	report, err := Run(context.Background(), &fakeAnalyzer{}, positions, engine.AnalysisOptions{}, nil)
+	if err == nil || !strings.Contains(err.Error(), "WAC.001") {
+		t.Errorf("expected an error naming the position, got %v", err)
+	}
+	if report == nil || len(report.Results) != 0 {
+		t.Errorf("expected an empty report, got %+v", report)
+	}
+}
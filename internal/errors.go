@@ -0,0 +1,73 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidMove is returned by ParseMove for text that is not a move. The
+// typed errors below wrap it, so errors.Is(err, ErrInvalidMove) holds for
+// every move ParseMove rejects.
+var ErrInvalidMove = errors.New("invalid move")
+
+// pieceNames are the names of the piece types, for messages.
+var pieceNames = [...]string{
+	Pawn:   "pawn",
+	Knight: "knight",
+	Bishop: "bishop",
+	Rook:   "rook",
+	Queen:  "queen",
+	King:   "king",
+}
+
+// AmbiguousMoveError is returned by ParseMove when more than one legal move
+// matches, as with Nd2 when knights on b1 and f3 can both reach d2.
+type AmbiguousMoveError struct {
+	Move       string   // the move as given
+	Piece      int      // the type of the moving pieces, NoPiece if they differ
+	To         Sq       // the destination, NoSquare if the moves go to different squares
+	Candidates []string // the matching moves in SAN
+}
+
+func (e *AmbiguousMoveError) Error() string {
+	specify := e.Candidates[0]
+	if n := len(e.Candidates); n > 1 {
+		specify = strings.Join(e.Candidates[:n-1], ", ") + " or " + e.Candidates[n-1]
+	}
+	if e.Piece == NoPiece || e.To == NoSquare {
+		return fmt.Sprintf("%s is ambiguous; specify %s", e.Move, specify)
+	}
+	count := "both"
+	if len(e.Candidates) > 2 {
+		count = fmt.Sprint(len(e.Candidates))
+	}
+	return fmt.Sprintf("%s %ss can reach %s; specify %s", count, pieceNames[e.Piece], e.To, specify)
+}
+
+func (e *AmbiguousMoveError) Unwrap() error { return ErrInvalidMove }
+
+// IllegalMoveError is returned by ParseMove when no legal move matches. Reason
+// explains why, for instance "no knight can reach d2" or "the bishop on e2
+// is pinned".
+type IllegalMoveError struct {
+	Move   string // the move as given
+	Reason string
+}
+
+func (e *IllegalMoveError) Error() string { return e.Reason }
+
+func (e *IllegalMoveError) Unwrap() error { return ErrInvalidMove }
+
+// UnknownPieceError is returned by ParseMove when a move starts with a letter
+// that is not a piece, as in Ze4.
+type UnknownPieceError struct {
+	Move   string // the move as given
+	Letter rune
+}
+
+func (e *UnknownPieceError) Error() string {
+	return fmt.Sprintf("unknown piece %q in %s; use K, Q, R, B or N", e.Letter, e.Move)
+}
+
+func (e *UnknownPieceError) Unwrap() error { return ErrInvalidMove }
@@ -0,0 +1,207 @@
+package internal
+
+// EvalTerms are the parts of one side's static evaluation, in centipawns.
+type EvalTerms struct {
+	Material     int // the value of the pieces, without the king
+	PieceSquares int // bonuses and penalties for the squares the pieces stand on
+	Mobility     int // bonuses for the squares the pieces attack
+}
+
+// Total returns the sum of the terms.
+func (t EvalTerms) Total() int {
+	return t.Material + t.PieceSquares + t.Mobility
+}
+
+// Evaluation is a static evaluation of a position, term by term for each
+// side, so that it can be shown why one side stands better.
+type Evaluation struct {
+	White   EvalTerms
+	Black   EvalTerms
+	Endgame bool // the kings are scored for the endgame
+}
+
+// Score returns the evaluation in centipawns from White's point of view.
+func (e Evaluation) Score() int {
+	return e.White.Total() - e.Black.Total()
+}
+
+// Diff returns each term from White's point of view: White's minus Black's.
+func (e Evaluation) Diff() EvalTerms {
+	return EvalTerms{
+		Material:     e.White.Material - e.Black.Material,
+		PieceSquares: e.White.PieceSquares - e.Black.PieceSquares,
+		Mobility:     e.White.Mobility - e.Black.Mobility,
+	}
+}
+
+// Eval returns a simple static evaluation of b: material, piece-square tables
+// and mobility. It does not search, so it misses hanging pieces and tactics,
+// and it ignores whose move it is; it is meant to explain a quiet position,
+// not to replace an engine.
+func Eval(b *Board) Evaluation {
+	e := Evaluation{Endgame: b.isEndgame()}
+	for color, terms := range []*EvalTerms{&e.White, &e.Black} {
+		terms.Material = b.Material(color)
+	}
+	for sq := range Squares() {
+		p := b.Piece[sq]
+		if p == NoPiece {
+			continue
+		}
+		terms := &e.White
+		if p.Color() == Black {
+			terms = &e.Black
+		}
+		terms.PieceSquares += pieceSquareValue(p, sq, e.Endgame)
+		terms.Mobility += mobilityWeights[p.Type()] * b.mobility(sq)
+	}
+	return e
+}
+
+// isEndgame reports whether the kings should come out: neither side has a
+// queen, or a side with a queen has at most one minor piece besides it.
+func (b *Board) isEndgame() bool {
+	for color := White; color <= Black; color++ {
+		if b.Count(Piece(color|Queen)) == 0 {
+			continue
+		}
+		minors := b.Count(Piece(color|Knight)) + b.Count(Piece(color|Bishop))
+		if b.Count(Piece(color|Rook)) > 0 || minors > 1 {
+			return false
+		}
+	}
+	return true
+}
+
+// mobilityWeights are the centipawns per attacked square, by piece type.
+var mobilityWeights = [...]int{
+	Knight: 4,
+	Bishop: 5,
+	Rook:   2,
+	Queen:  1,
+	King:   0,
+}
+
+// mobility returns the number of squares the knight, bishop, rook or queen on
+// sq attacks that are not occupied by its own pieces, and 0 for pawns and
+// kings.
+func (b *Board) mobility(sq Sq) int {
+	p := b.Piece[sq]
+	var offsets []int
+	slides := true
+	switch p.Type() {
+	case Knight:
+		offsets, slides = knightOffsets, false
+	case Bishop:
+		offsets = diagonalOffsets
+	case Rook:
+		offsets = straightOffsets
+	case Queen:
+		offsets = kingOffsets
+	default:
+		return 0
+	}
+	n := 0
+	for _, offset := range offsets {
+		for to := sq.step(offset); to != NoSquare; to = to.step(offset) {
+			if b.Piece[to] == NoPiece || b.Piece[to].Color() != p.Color() {
+				n++
+			}
+			if !slides || b.Piece[to] != NoPiece {
+				break
+			}
+		}
+	}
+	return n
+}
+
+// pieceSquareValue returns the piece-square table value of piece p on sq.
+// The tables are drawn from White's side, with a8 at the top left, and are
+// mirrored for Black.
+func pieceSquareValue(p Piece, sq Sq, endgame bool) int {
+	table := pieceSquareTables[p.Type()]
+	if p.Type() == King && endgame {
+		table = kingEndgameTable
+	}
+	rank := sq.Rank()
+	if p.Color() == White {
+		rank = 7 - rank
+	}
+	return table[rank*8+sq.File()]
+}
+
+// The piece-square tables of Tomasz Michniewski's Simplified Evaluation
+// Function.
+var pieceSquareTables = [...]*[64]int{
+	Pawn: {
+		0, 0, 0, 0, 0, 0, 0, 0,
+		50, 50, 50, 50, 50, 50, 50, 50,
+		10, 10, 20, 30, 30, 20, 10, 10,
+		5, 5, 10, 25, 25, 10, 5, 5,
+		0, 0, 0, 20, 20, 0, 0, 0,
+		5, -5, -10, 0, 0, -10, -5, 5,
+		5, 10, 10, -20, -20, 10, 10, 5,
+		0, 0, 0, 0, 0, 0, 0, 0,
+	},
+	Knight: {
+		-50, -40, -30, -30, -30, -30, -40, -50,
+		-40, -20, 0, 0, 0, 0, -20, -40,
+		-30, 0, 10, 15, 15, 10, 0, -30,
+		-30, 5, 15, 20, 20, 15, 5, -30,
+		-30, 0, 15, 20, 20, 15, 0, -30,
+		-30, 5, 10, 15, 15, 10, 5, -30,
+		-40, -20, 0, 5, 5, 0, -20, -40,
+		-50, -40, -30, -30, -30, -30, -40, -50,
+	},
+	Bishop: {
+		-20, -10, -10, -10, -10, -10, -10, -20,
+		-10, 0, 0, 0, 0, 0, 0, -10,
+		-10, 0, 5, 10, 10, 5, 0, -10,
+		-10, 5, 5, 10, 10, 5, 5, -10,
+		-10, 0, 10, 10, 10, 10, 0, -10,
+		-10, 10, 10, 10, 10, 10, 10, -10,
+		-10, 5, 0, 0, 0, 0, 5, -10,
+		-20, -10, -10, -10, -10, -10, -10, -20,
+	},
+	Rook: {
+		0, 0, 0, 0, 0, 0, 0, 0,
+		5, 10, 10, 10, 10, 10, 10, 5,
+		-5, 0, 0, 0, 0, 0, 0, -5,
+		-5, 0, 0, 0, 0, 0, 0, -5,
+		-5, 0, 0, 0, 0, 0, 0, -5,
+		-5, 0, 0, 0, 0, 0, 0, -5,
+		-5, 0, 0, 0, 0, 0, 0, -5,
+		0, 0, 0, 5, 5, 0, 0, 0,
+	},
+	Queen: {
+		-20, -10, -10, -5, -5, -10, -10, -20,
+		-10, 0, 0, 0, 0, 0, 0, -10,
+		-10, 0, 5, 5, 5, 5, 0, -10,
+		-5, 0, 5, 5, 5, 5, 0, -5,
+		0, 0, 5, 5, 5, 5, 0, -5,
+		-10, 5, 5, 5, 5, 5, 0, -10,
+		-10, 0, 5, 0, 0, 0, 0, -10,
+		-20, -10, -10, -5, -5, -10, -10, -20,
+	},
+	King: {
+		-30, -40, -40, -50, -50, -40, -40, -30,
+		-30, -40, -40, -50, -50, -40, -40, -30,
+		-30, -40, -40, -50, -50, -40, -40, -30,
+		-30, -40, -40, -50, -50, -40, -40, -30,
+		-20, -30, -30, -40, -40, -30, -30, -20,
+		-10, -20, -20, -20, -20, -20, -20, -10,
+		20, 20, 0, 0, 0, 0, 20, 20,
+		20, 30, 10, 0, 0, 10, 30, 20,
+	},
+}
+
+var kingEndgameTable = &[64]int{
+	-50, -40, -30, -20, -20, -30, -40, -50,
+	-30, -20, -10, 0, 0, -10, -20, -30,
+	-30, -10, 20, 30, 30, 20, -10, -30,
+	-30, -10, 30, 40, 40, 30, -10, -30,
+	-30, -10, 30, 40, 40, 30, -10, -30,
+	-30, -10, 20, 30, 30, 20, -10, -30,
+	-30, -30, 0, 0, 0, 0, -30, -30,
+	-50, -30, -30, -30, -30, -30, -30, -50,
+}
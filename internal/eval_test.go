@@ -0,0 +1,56 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvalStartingPosition(t *testing.T) {
+	b, err := ParseFen("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1")
+	require.NoError(t, err)
+	e := Eval(b)
+	assert.Equal(t, 0, e.Score())
+	assert.Equal(t, e.White, e.Black)
+	assert.Equal(t, 3900, e.White.Material)
+	assert.False(t, e.Endgame)
+	// Only the knights can move: a3, c3, f3 and h3
+	assert.Equal(t, 4*4, e.White.Mobility)
+}
+
+func TestEvalMirrored(t *testing.T) {
+	// The same position with the colors swapped evaluates the other way
+	tests := []struct {
+		fen, mirrored string
+	}{
+		{"r1bqkbnr/pppp1ppp/2n5/4p3/4P3/5N2/PPPP1PPP/RNBQKB1R w KQkq - 2 3", "rnbqkb1r/pppp1ppp/5n2/4p3/4P3/2N5/PPPP1PPP/R1BQKBNR b KQkq - 2 3"},
+		{"8/5k2/8/3P4/8/8/2K5/8 w - - 0 1", "8/2k5/8/8/3p4/8/5K2/8 b - - 0 1"},
+	}
+	for _, tt := range tests {
+		b, err := ParseFen(tt.fen)
+		require.NoError(t, err)
+		m, err := ParseFen(tt.mirrored)
+		require.NoError(t, err)
+		e, em := Eval(b), Eval(m)
+		assert.Equal(t, e.Score(), -em.Score(), tt.fen)
+		assert.Equal(t, e.White, em.Black, tt.fen)
+		assert.Equal(t, e.Endgame, em.Endgame, tt.fen)
+	}
+}
+
+func TestEvalTerms(t *testing.T) {
+	// White is a knight up, centralized, against a cornered king
+	b, err := ParseFen("7k/8/8/8/3N4/8/8/4K3 w - - 0 1")
+	require.NoError(t, err)
+	e := Eval(b)
+	assert.True(t, e.Endgame)
+	assert.Equal(t, EvalTerms{Material: 300, PieceSquares: 20 - 30 - (-50), Mobility: 8 * 4}, e.Diff())
+	assert.Equal(t, e.Diff().Total(), e.Score())
+
+	// The rook attacks the a-file and b1-d1, up to its own king
+	b, err = ParseFen("4k3/8/8/8/8/8/8/R3K3 w - - 0 1")
+	require.NoError(t, err)
+	assert.Equal(t, 10, b.mobility(A1))
+	assert.Equal(t, 0, b.mobility(E1))
+}
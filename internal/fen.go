@@ -55,6 +55,7 @@ func parsePiecePlacement(board *Board, placement string) error {
 	for i := range board.Piece {
 		board.Piece[i] = NoPiece
 	}
+	board.count = [BK + 1]int8{}
 
 	for rank := 7; rank >= 0; rank-- {
 		rankStr := ranks[7-rank]
@@ -77,6 +78,7 @@ func parsePiecePlacement(board *Board, placement string) error {
 
 				square := Square(file, rank)
 				board.Piece[square] = piece
+				board.count[piece]++
 				file++
 			}
 		}
@@ -0,0 +1,202 @@
+// Package fics reads the game history of a player on the Free Internet
+// Chess Server, as printed by its history command, into PGN games that can
+// be imported with the rest of the player's games.
+package fics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Site is the Site tag of the games played on FICS, as in its PGN exports.
+const Site = "FICS freechess.org"
+
+// HistoryEntry is one game of a player's history.
+type HistoryEntry struct {
+	Index          int
+	Result         byte // '+' won, '-' lost or '=' drawn, for the player
+	Rating         int  // the player's rating, 0 if unrated
+	Color          byte // 'W' or 'B', the player's color
+	OpponentRating int  // 0 if unrated
+	Opponent       string
+	Type           byte // 'b' blitz, 's' standard, 'l' lightning, 'u' untimed, 'w' wild, ...
+	Rated          bool
+	Minutes        int    // initial time
+	Increment      int    // seconds added per move
+	ECO            string // opening code, as in "B01"
+	End            string // how the game ended, as in "Res" or "Fla"
+	Date           time.Time
+}
+
+// gameTypes names the types of standard chess games. The others, such as
+// wild, atomic or crazyhouse, are variants.
+var gameTypes = map[byte]string{
+	'b': "blitz",
+	's': "standard",
+	'l': "lightning",
+	'u': "untimed",
+	'n': "nonstandard",
+}
+
+// endings describe how a game ended from its history code, for the
+// Termination tag. "%s" is the winner.
+var endings = map[string]string{
+	"Res": "%s won by resignation",
+	"Mat": "%s won by checkmate",
+	"Fla": "%s won on time",
+	"Adj": "%s won by adjudication",
+	"Dis": "%s won - game abandoned",
+	"Agr": "Game drawn by agreement",
+	"Rep": "Game drawn by repetition",
+	"Sta": "Game drawn by stalemate",
+	"50":  "Game drawn by the 50-move rule",
+	"NM":  "Game drawn by insufficient material",
+	"TM":  "Game drawn by timeout vs insufficient material",
+}
+
+var (
+	headerPattern = regexp.MustCompile(`^History for (\S+):`)
+	entryPattern  = regexp.MustCompile(`^\s*(\d+):\s+([-+=])\s+(\S+)\s+([WB])\s+(\S+)\s+(\S+)\s+\[\s*([a-zA-Z])([ru])\s+(\d+)\s+(\d+)\]\s+(\S+)\s+(\S+)\s+(.+?)\s*$`)
+)
+
+// ParseHistory reads the output of the FICS history command. It returns
+// the player named by its "History for" line, or "" if there is none, and
+// the games listed. Lines that are not games, such as the column headings,
+// are skipped.
+func ParseHistory(r io.Reader) (string, []HistoryEntry, error) {
+	var username string
+	var entries []HistoryEntry
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := scanner.Text()
+		if m := headerPattern.FindStringSubmatch(strings.TrimSpace(text)); m != nil {
+			username = m[1]
+			continue
+		}
+		m := entryPattern.FindStringSubmatch(text)
+		if m == nil {
+			continue
+		}
+		entry, err := parseEntry(m)
+		if err != nil {
+			return username, entries, fmt.Errorf("line %d: %w", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return username, entries, fmt.Errorf("failed to read history: %w", err)
+	}
+	return username, entries, nil
+}
+
+// parseEntry builds an entry from the submatches of entryPattern
+func parseEntry(m []string) (HistoryEntry, error) {
+	index, _ := strconv.Atoi(m[1])
+	minutes, _ := strconv.Atoi(m[9])
+	increment, _ := strconv.Atoi(m[10])
+	// Days are padded with spaces, as in "Sep  3,"
+	date, err := time.Parse("Mon Jan 2, 15:04 MST 2006", strings.Join(strings.Fields(m[13]), " "))
+	if err != nil {
+		return HistoryEntry{}, fmt.Errorf("invalid date %q: %w", m[13], err)
+	}
+	return HistoryEntry{
+		Index:          index,
+		Result:         m[2][0],
+		Rating:         parseRating(m[3]),
+		Color:          m[4][0],
+		OpponentRating: parseRating(m[5]),
+		Opponent:       m[6],
+		Type:           m[7][0],
+		Rated:          m[8] == "r",
+		Minutes:        minutes,
+		Increment:      increment,
+		ECO:            m[11],
+		End:            m[12],
+		Date:           date,
+	}, nil
+}
+
+// parseRating reads a rating, 0 for the "++++" or "----" of unrated players
+func parseRating(s string) int {
+	rating, err := strconv.Atoi(s)
+	if err != nil {
+		return 0
+	}
+	return rating
+}
+
+// Variant reports whether the game was of a chess variant, such as wild or
+// crazyhouse, rather than standard chess.
+func (e HistoryEntry) Variant() bool {
+	_, ok := gameTypes[e.Type]
+	return !ok
+}
+
+// PGN returns the game as PGN, from the point of view of username, the
+// player whose history it is. FICS histories do not keep the moves, so the
+// game has only its tags and result.
+func (e HistoryEntry) PGN(username string) string {
+	white, black := username, e.Opponent
+	whiteElo, blackElo := e.Rating, e.OpponentRating
+	if e.Color == 'B' {
+		white, black = black, white
+		whiteElo, blackElo = blackElo, whiteElo
+	}
+
+	result, winner := "1/2-1/2", ""
+	switch {
+	case e.Result == '+' && e.Color == 'W', e.Result == '-' && e.Color == 'B':
+		result, winner = "1-0", white
+	case e.Result == '+' && e.Color == 'B', e.Result == '-' && e.Color == 'W':
+		result, winner = "0-1", black
+	}
+
+	rated := "unrated"
+	if e.Rated {
+		rated = "rated"
+	}
+	gameType, ok := gameTypes[e.Type]
+	if !ok {
+		gameType = "variant"
+	}
+
+	var b strings.Builder
+	tag := func(name, value string) {
+		fmt.Fprintf(&b, "[%s \"%s\"]\n", name, value)
+	}
+	tag("Event", fmt.Sprintf("FICS %s %s game", rated, gameType))
+	tag("Site", Site)
+	tag("Date", e.Date.Format("2006.01.02"))
+	tag("Round", "-")
+	tag("White", white)
+	tag("Black", black)
+	tag("Result", result)
+	if whiteElo > 0 {
+		tag("WhiteElo", strconv.Itoa(whiteElo))
+	}
+	if blackElo > 0 {
+		tag("BlackElo", strconv.Itoa(blackElo))
+	}
+	if e.Type != 'u' {
+		tag("TimeControl", fmt.Sprintf("%d+%d", e.Minutes*60, e.Increment))
+	}
+	tag("Time", e.Date.Format("15:04:05"))
+	if e.ECO != "" && e.ECO != "---" {
+		tag("ECO", e.ECO)
+	}
+	if ending, ok := endings[e.End]; ok {
+		if strings.Contains(ending, "%s") {
+			ending = fmt.Sprintf(ending, winner)
+		}
+		tag("Termination", ending)
+	}
+	fmt.Fprintf(&b, "\n%s\n", result)
+	return b.String()
+}
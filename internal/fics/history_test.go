@@ -0,0 +1,100 @@
+package fics
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/kyleboon/gochess/internal/pgn"
+)
+
+func TestParseHistory(t *testing.T) {
+	f, err := os.Open("testdata/history.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+
+	username, entries, err := ParseHistory(f)
+	if err != nil {
+		t.Fatalf("ParseHistory failed: %v", err)
+	}
+	if username != "kyleboon" {
+		t.Errorf("expected the player kyleboon, got %q", username)
+	}
+	if len(entries) != 5 {
+		t.Fatalf("expected 5 games, got %d", len(entries))
+	}
+
+	first := entries[0]
+	if first.Result != '-' || first.Color != 'B' || first.Opponent != "GMfoo" || first.OpponentRating != 1737 {
+		t.Errorf("unexpected first game %+v", first)
+	}
+	if first.Type != 'b' || first.Rated || first.Minutes != 2 || first.Increment != 12 || first.ECO != "B01" || first.End != "Res" {
+		t.Errorf("unexpected first game %+v", first)
+	}
+	if got := first.Date.Format("2006-01-02 15:04"); got != "2019-09-13 16:09" {
+		t.Errorf("expected the date 2019-09-13 16:09, got %s", got)
+	}
+	if entries[2].Date.Day() != 7 {
+		t.Errorf("expected a padded day to be read, got %v", entries[2].Date)
+	}
+	if entries[3].OpponentRating != 0 {
+		t.Errorf("expected an unrated opponent, got %d", entries[3].OpponentRating)
+	}
+	if entries[3].Variant() || !entries[4].Variant() {
+		t.Error("expected only the crazyhouse game to be a variant")
+	}
+}
+
+func TestHistoryEntryPGN(t *testing.T) {
+	_, entries, err := ParseHistory(strings.NewReader(` 0: - 1500 B 1737 GMfoo         [ bu  2  12] B01 Res Fri Sep 13, 16:09 EDT 2019
+ 1: = 1520 W 1530 drawmaster    [ sr 15   5] D35 Rep Sat Sep  7, 21:30 EDT 2019
+ 2: + 1498 B ++++ GuestABCD     [ lu  1   0] A00 Fla Sat Sep  7, 20:11 EDT 2019`))
+	if err != nil {
+		t.Fatalf("ParseHistory failed: %v", err)
+	}
+
+	var db pgn.DB
+	if errs := db.Parse(entries[0].PGN("kyleboon") + "\n" + entries[1].PGN("kyleboon") + "\n" + entries[2].PGN("kyleboon")); len(errs) > 0 {
+		t.Fatalf("the PGN does not parse: %v", errs)
+	}
+	if len(db.Games) != 3 {
+		t.Fatalf("expected 3 games, got %d", len(db.Games))
+	}
+
+	lost := db.Games[0].Tags
+	for name, want := range map[string]string{
+		"Event":       "FICS unrated blitz game",
+		"Site":        Site,
+		"Date":        "2019.09.13",
+		"White":       "GMfoo",
+		"Black":       "kyleboon",
+		"Result":      "1-0",
+		"WhiteElo":    "1737",
+		"BlackElo":    "1500",
+		"TimeControl": "120+12",
+		"ECO":         "B01",
+		"Termination": "GMfoo won by resignation",
+	} {
+		if lost[name] != want {
+			t.Errorf("expected %s %q, got %q", name, want, lost[name])
+		}
+	}
+	if got := pgn.ParseTermination(lost["Termination"]); got != pgn.Resignation {
+		t.Errorf("expected the termination to read as resignation, got %v", got)
+	}
+
+	drawn := db.Games[1].Tags
+	if drawn["White"] != "kyleboon" || drawn["Result"] != "1/2-1/2" || drawn["Termination"] != "Game drawn by repetition" {
+		t.Errorf("unexpected drawn game %v", drawn)
+	}
+
+	won := db.Games[2].Tags
+	if won["Result"] != "0-1" || won["Termination"] != "kyleboon won on time" {
+		t.Errorf("unexpected won game %v", won)
+	}
+	if _, ok := won["WhiteElo"]; ok {
+		t.Errorf("expected no rating for an unrated player, got %q", won["WhiteElo"])
+	}
+}
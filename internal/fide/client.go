@@ -0,0 +1,153 @@
+// Package fide looks up players in the FIDE rating list. FIDE publishes no
+// API, so a player's current ratings are read from their profile page on
+// ratings.fide.com.
+package fide
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kyleboon/gochess/internal/logging"
+)
+
+const baseURL = "https://ratings.fide.com"
+
+// ErrPlayerNotFound is returned when no player has the FIDE ID looked up.
+var ErrPlayerNotFound = errors.New("FIDE player not found")
+
+// Player is a player's entry in the FIDE rating list.
+type Player struct {
+	ID         int
+	Name       string // As listed, "Surname, Firstname"
+	Federation string // As in "Norway"
+	Title      string // Abbreviated, as in "GM", "" if untitled
+	Standard   int    // Current ratings, 0 if unrated
+	Rapid      int
+	Blitz      int
+}
+
+// titles abbreviates the titles spelled out on the profile pages.
+var titles = map[string]string{
+	"Grandmaster":                "GM",
+	"International Master":       "IM",
+	"FIDE Master":                "FM",
+	"Candidate Master":           "CM",
+	"Woman Grandmaster":          "WGM",
+	"Woman International Master": "WIM",
+	"Woman FIDE Master":          "WFM",
+	"Woman Candidate Master":     "WCM",
+}
+
+var (
+	namePattern   = regexp.MustCompile(`(?s)<div class="profile-top-title">\s*(.*?)\s*</div>`)
+	ratingPattern = regexp.MustCompile(`(?s)<span class="profile-top-rating-dataDesc">\s*(std|rapid|blitz)\s*</span>\s*([^<]*?)\s*</div>`)
+	infoPattern   = regexp.MustCompile(`(?s)<div class="profile-top-info__block__row__header">\s*(.*?)\s*</div>\s*<div class="profile-top-info__block__row__data">\s*(.*?)\s*</div>`)
+)
+
+// Client looks players up on ratings.fide.com.
+type Client struct {
+	httpClient *http.Client
+	logger     *slog.Logger
+	baseURL    string // Base URL for requests (exposed for testing)
+}
+
+// NewClient creates a new FIDE ratings client with default settings.
+func NewClient() *Client {
+	return NewClientWithLogger(logging.Default())
+}
+
+// NewClientWithLogger creates a new FIDE ratings client with a custom logger.
+func NewClientWithLogger(logger *slog.Logger) *Client {
+	return &Client{
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		logger:     logger.With("component", "fide"),
+		baseURL:    baseURL,
+	}
+}
+
+// SetBaseURL sets the base URL of the profile pages, https://ratings.fide.com
+// by default.
+func (c *Client) SetBaseURL(url string) {
+	c.baseURL = strings.TrimSuffix(url, "/")
+}
+
+// GetPlayer fetches the entry of the player with FIDE ID id.
+func (c *Client) GetPlayer(ctx context.Context, id int) (*Player, error) {
+	url := fmt.Sprintf("%s/profile/%d", c.baseURL, id)
+	c.logger.Debug("fetching FIDE profile", "fideId", id, "url", url)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		c.logger.Error("HTTP request failed", "error", err, "url", url)
+		return nil, fmt.Errorf("failed to fetch FIDE profile: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %d", ErrPlayerNotFound, id)
+	}
+	if resp.StatusCode != http.StatusOK {
+		c.logger.Warn("unexpected status code from FIDE", "statusCode", resp.StatusCode, "url", url)
+		return nil, fmt.Errorf("FIDE ratings returned status code %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read FIDE profile: %w", err)
+	}
+
+	player, err := ParseProfile(string(body))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %d", err, id)
+	}
+	player.ID = id
+	return player, nil
+}
+
+// ParseProfile reads a player's name, federation, title and ratings from
+// their profile page. The page of an unknown ID has no name, and
+// ErrPlayerNotFound is returned.
+func ParseProfile(page string) (*Player, error) {
+	m := namePattern.FindStringSubmatch(page)
+	if m == nil || m[1] == "" {
+		return nil, ErrPlayerNotFound
+	}
+	player := &Player{Name: html.UnescapeString(m[1])}
+
+	for _, m := range ratingPattern.FindAllStringSubmatch(page, -1) {
+		// Unrated players have "Not rated" in place of the rating
+		rating, _ := strconv.Atoi(m[2])
+		switch m[1] {
+		case "std":
+			player.Standard = rating
+		case "rapid":
+			player.Rapid = rating
+		case "blitz":
+			player.Blitz = rating
+		}
+	}
+	for _, m := range infoPattern.FindAllStringSubmatch(page, -1) {
+		value := html.UnescapeString(m[2])
+		switch strings.TrimSuffix(m[1], ":") {
+		case "Federation":
+			player.Federation = value
+		case "FIDE title":
+			if title, ok := titles[value]; ok {
+				player.Title = title
+			}
+		}
+	}
+	return player, nil
+}
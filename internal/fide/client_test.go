@@ -0,0 +1,71 @@
+package fide
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/kyleboon/gochess/internal/logging"
+)
+
+const unratedProfile = `<div class="profile-top-title">Nordmann, Ola</div>
+<div class="profile-top-rating-data"><span class="profile-top-rating-dataDesc">std</span> 1904</div>
+<div class="profile-top-rating-data"><span class="profile-top-rating-dataDesc">rapid</span> Not rated</div>
+<div class="profile-top-rating-data"><span class="profile-top-rating-dataDesc">blitz</span> Not rated</div>
+<div class="profile-top-info__block__row__header">Federation:</div><div class="profile-top-info__block__row__data">Norway</div>
+<div class="profile-top-info__block__row__header">FIDE title:</div><div class="profile-top-info__block__row__data">None</div>`
+
+func TestParseProfile(t *testing.T) {
+	player, err := ParseProfile(unratedProfile)
+	if err != nil {
+		t.Fatalf("ParseProfile failed: %v", err)
+	}
+	want := Player{Name: "Nordmann, Ola", Federation: "Norway", Standard: 1904}
+	if *player != want {
+		t.Errorf("expected %+v, got %+v", want, *player)
+	}
+
+	if _, err := ParseProfile("<html><body>No record found</body></html>"); !errors.Is(err, ErrPlayerNotFound) {
+		t.Errorf("expected ErrPlayerNotFound for a page without a player, got %v", err)
+	}
+}
+
+func TestClient_GetPlayer(t *testing.T) {
+	page, err := os.ReadFile("testdata/profile_1503014.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/profile/1503014":
+			_, _ = w.Write(page)
+		case "/profile/1":
+			_, _ = w.Write([]byte("<html><body>No record found</body></html>"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClientWithLogger(logging.Discard())
+	client.SetBaseURL(server.URL + "/")
+	ctx := context.Background()
+
+	player, err := client.GetPlayer(ctx, 1503014)
+	if err != nil {
+		t.Fatalf("GetPlayer failed: %v", err)
+	}
+	want := Player{ID: 1503014, Name: "Carlsen, Magnus", Federation: "Norway", Title: "GM", Standard: 2830, Rapid: 2824, Blitz: 2886}
+	if *player != want {
+		t.Errorf("expected %+v, got %+v", want, *player)
+	}
+
+	for _, id := range []int{1, 2} {
+		if _, err := client.GetPlayer(ctx, id); !errors.Is(err, ErrPlayerNotFound) {
+			t.Errorf("expected ErrPlayerNotFound for %d, got %v", id, err)
+		}
+	}
+}
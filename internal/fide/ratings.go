@@ -0,0 +1,68 @@
+package fide
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/kyleboon/gochess/internal/db"
+	"github.com/kyleboon/gochess/internal/progress"
+)
+
+// requestDelay spaces out the profile requests, ratings.fide.com being a
+// website rather than an API
+var requestDelay = 500 * time.Millisecond
+
+// FetchRatings looks up the players named by the WhiteFideId and
+// BlackFideId tags of the games in the FIDE rating list and stores their
+// current standard, rapid and blitz ratings in the database. Only the
+// players never looked up are fetched, unless refresh is set. IDs FIDE does
+// not know are stored without a name or ratings, so they are not asked for
+// again. It returns the number of players stored.
+func FetchRatings(ctx context.Context, client *Client, database *db.DB, refresh bool, out io.Writer) (int, error) {
+	ids, err := database.GameFideIDs(ctx, !refresh)
+	if err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	fmt.Fprintf(out, "Fetching the FIDE ratings of %d players...\n", len(ids))
+	bar := progress.ForOutput(out, len(ids), "players")
+	defer bar.Finish()
+
+	stored := 0
+	for i, id := range ids {
+		if i > 0 {
+			select {
+			case <-time.After(requestDelay):
+			case <-ctx.Done():
+				return stored, ctx.Err()
+			}
+		}
+		player := db.FidePlayer{ID: id}
+		p, err := client.GetPlayer(ctx, id)
+		switch {
+		case err == nil:
+			player.Name = p.Name
+			player.Federation = p.Federation
+			player.Title = p.Title
+			player.Standard = p.Standard
+			player.Rapid = p.Rapid
+			player.Blitz = p.Blitz
+		case errors.Is(err, ErrPlayerNotFound):
+			// Stored empty, so it is not asked for again
+		default:
+			return stored, fmt.Errorf("failed to fetch the FIDE ratings of %d: %w", id, err)
+		}
+		if err := database.SaveFidePlayer(ctx, player); err != nil {
+			return stored, err
+		}
+		stored++
+		bar.Add(1)
+	}
+	return stored, nil
+}
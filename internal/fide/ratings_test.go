@@ -0,0 +1,85 @@
+package fide
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kyleboon/gochess/internal/db"
+	"github.com/kyleboon/gochess/internal/logging"
+)
+
+func TestFetchRatings(t *testing.T) {
+	page, err := os.ReadFile("testdata/profile_1503014.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path == "/profile/1503014" {
+			_, _ = w.Write(page)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+	defer func(delay time.Duration) { requestDelay = delay }(requestDelay)
+	requestDelay = 0
+
+	ctx := context.Background()
+	database, err := db.NewInMemory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = database.Close() }()
+	result := database.ImportPGNReader(ctx, "otb.pgn", strings.NewReader(`[Event "Norway Chess"]
+[Site "Stavanger NOR"]
+[Date "2024.05.27"]
+[White "Carlsen, Magnus"]
+[Black "Unknown, Player"]
+[Result "1-0"]
+[WhiteFideId "1503014"]
+[BlackFideId "999999999"]
+
+1. e4 e5 1-0
+`), db.ImportOptions{})
+	if len(result.Errors) > 0 || result.Imported != 1 {
+		t.Fatalf("import failed: %v", result.Errors)
+	}
+
+	client := NewClientWithLogger(logging.Discard())
+	client.SetBaseURL(server.URL)
+	count, err := FetchRatings(ctx, client, database, false, io.Discard)
+	if err != nil {
+		t.Fatalf("FetchRatings failed: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("expected 2 players stored, got %d", count)
+	}
+	player, err := database.GetFidePlayer(ctx, 1503014)
+	if err != nil || player == nil {
+		t.Fatalf("expected the ratings of 1503014, got %v (%v)", player, err)
+	}
+	if player.Standard != 2830 || player.Rapid != 2824 || player.Blitz != 2886 || player.Title != "GM" {
+		t.Errorf("unexpected ratings %+v", player)
+	}
+	unknown, err := database.GetFidePlayer(ctx, 999999999)
+	if err != nil || unknown == nil || unknown.Name != "" {
+		t.Errorf("expected the unknown ID to be stored empty, got %v (%v)", unknown, err)
+	}
+
+	// The players stored are not fetched again unless refreshed
+	requests = 0
+	if count, err := FetchRatings(ctx, client, database, false, io.Discard); err != nil || count != 0 || requests != 0 {
+		t.Errorf("expected nothing fetched, got %d stored after %d requests (%v)", count, requests, err)
+	}
+	if count, err := FetchRatings(ctx, client, database, true, io.Discard); err != nil || count != 2 {
+		t.Errorf("expected 2 players refreshed, got %d (%v)", count, err)
+	}
+}
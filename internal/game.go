@@ -0,0 +1,163 @@
+package internal
+
+import "fmt"
+
+// GameState follows a game move by move. Besides the current position it
+// keeps the positions played since the last irreversible move, so that it
+// can tell repetitions, and it answers whether the game is over or a draw
+// can be claimed without replaying the moves.
+type GameState struct {
+	boards []*Board      // the start position and the position after each move
+	keys   []positionKey // the repetition key of each of the boards
+	moves  []Move        // the moves played
+}
+
+// positionKey identifies a position for repetitions: positions are the same
+// when the same pieces stand on the same squares with the same side to move,
+// the same castling rights and the same en passant capture.
+type positionKey struct {
+	pieces     [64]Piece
+	sideToMove int
+	castleSq   [4]Sq
+	epSquare   Sq
+}
+
+// NewGameState starts following a game from position b.
+func NewGameState(b *Board) *GameState {
+	return &GameState{
+		boards: []*Board{b},
+		keys:   []positionKey{b.positionKey()},
+	}
+}
+
+// Board returns the current position.
+func (g *GameState) Board() *Board {
+	return g.boards[len(g.boards)-1]
+}
+
+// Moves returns the moves played.
+func (g *GameState) Moves() []Move {
+	return g.moves
+}
+
+// Push plays m in the current position. It returns an error, leaving the
+// game unchanged, if m is not legal.
+func (g *GameState) Push(m Move) error {
+	b := g.Board()
+	if !b.IsLegal(m) {
+		return fmt.Errorf("illegal move %s", m.Uci(b))
+	}
+	b = b.MakeMove(m)
+	g.boards = append(g.boards, b)
+	g.keys = append(g.keys, b.positionKey())
+	g.moves = append(g.moves, m)
+	return nil
+}
+
+// Pop takes back the last move and returns it, or returns NullMove if no
+// move has been played.
+func (g *GameState) Pop() Move {
+	n := len(g.moves)
+	if n == 0 {
+		return NullMove
+	}
+	m := g.moves[n-1]
+	g.boards, g.keys, g.moves = g.boards[:n], g.keys[:n], g.moves[:n-1]
+	return m
+}
+
+// Repetitions returns how many times the current position has occurred,
+// counting itself. Only the positions since the last capture or pawn move
+// are compared, as no earlier position can occur again.
+func (g *GameState) Repetitions() int {
+	n := len(g.keys) - 1
+	count := 1
+	for i := n - 2; i >= 0 && i >= n-g.Board().Rule50; i -= 2 {
+		if g.keys[i] == g.keys[n] {
+			count++
+		}
+	}
+	return count
+}
+
+// Termination returns the result ("1-0", "0-1" or "1/2-1/2") and the reason
+// when the game has ended without a claim: by checkmate, stalemate,
+// insufficient material, fivefold repetition or the 75-move rule. Both are
+// empty while the game goes on.
+func (g *GameState) Termination() (result, reason string) {
+	b := g.Board()
+	if check, mate := b.IsCheckOrMate(); mate {
+		if !check {
+			return "1/2-1/2", "stalemate"
+		}
+		if b.SideToMove == White {
+			return "0-1", "checkmate"
+		}
+		return "1-0", "checkmate"
+	}
+	switch {
+	case b.InsufficientMaterial():
+		return "1/2-1/2", "insufficient material"
+	case g.Repetitions() >= 5:
+		return "1/2-1/2", "fivefold repetition"
+	case b.Rule50 >= 150:
+		return "1/2-1/2", "75-move rule"
+	}
+	return "", ""
+}
+
+// ClaimableDraw returns why the player to move can claim a draw, by
+// threefold repetition or the fifty-move rule, or "" if they cannot.
+func (g *GameState) ClaimableDraw() string {
+	switch {
+	case g.Repetitions() >= 3:
+		return "threefold repetition"
+	case g.Board().Rule50 >= 100:
+		return "fifty-move rule"
+	}
+	return ""
+}
+
+// positionKey returns the key of the position for repetitions. The en
+// passant square only counts if a pawn could capture on it.
+func (b *Board) positionKey() positionKey {
+	key := positionKey{
+		pieces:     b.Piece,
+		sideToMove: b.SideToMove,
+		castleSq:   b.CastleSq,
+		epSquare:   NoSquare,
+	}
+	if b.EpSquare != NoSquare {
+		pushed := b.EpSquare.Down(b.SideToMove)
+		for _, sq := range []Sq{pushed.Left(b.SideToMove), pushed.Right(b.SideToMove)} {
+			if sq != NoSquare && b.Piece[sq] == b.my(Pawn) {
+				key.epSquare = b.EpSquare
+			}
+		}
+	}
+	return key
+}
+
+// InsufficientMaterial reports whether neither side can mate: kings with at
+// most one minor piece between them, or a bishop each on squares of the same
+// color.
+func (b *Board) InsufficientMaterial() bool {
+	var minors []Sq
+	for sq := A1; sq <= H8; sq++ {
+		switch b.Piece[sq].Type() {
+		case Pawn, Rook, Queen:
+			return false
+		case Knight, Bishop:
+			minors = append(minors, sq)
+		}
+	}
+	switch len(minors) {
+	case 0, 1:
+		return true
+	case 2:
+		p, q := b.Piece[minors[0]], b.Piece[minors[1]]
+		return p.Type() == Bishop && q.Type() == Bishop &&
+			p.Color() != q.Color() && minors[0].Color() == minors[1].Color()
+	}
+	return false
+}
@@ -0,0 +1,131 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// push plays moves given in SAN.
+func push(t *testing.T, g *GameState, moves ...string) {
+	t.Helper()
+	for _, s := range moves {
+		m, err := g.Board().ParseMove(s)
+		require.NoError(t, err, s)
+		require.NoError(t, g.Push(m), s)
+	}
+}
+
+func TestGameStateRepetitions(t *testing.T) {
+	b, err := ParseFen("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1")
+	require.NoError(t, err)
+	g := NewGameState(b)
+	assert.Equal(t, 1, g.Repetitions())
+
+	shuffle := []string{"Nf3", "Nf6", "Ng1", "Ng8"}
+	push(t, g, shuffle...)
+	assert.Equal(t, 2, g.Repetitions())
+	assert.Equal(t, "", g.ClaimableDraw())
+
+	push(t, g, shuffle...)
+	assert.Equal(t, 3, g.Repetitions())
+	assert.Equal(t, "threefold repetition", g.ClaimableDraw())
+	result, reason := g.Termination()
+	assert.Equal(t, "", result)
+	assert.Equal(t, "", reason)
+
+	push(t, g, shuffle...)
+	push(t, g, shuffle...)
+	assert.Equal(t, 5, g.Repetitions())
+	result, reason = g.Termination()
+	assert.Equal(t, "1/2-1/2", result)
+	assert.Equal(t, "fivefold repetition", reason)
+
+	// Taking back a move goes back to the position after the fourth Ng1
+	m := g.Pop()
+	assert.Equal(t, "g8", m.To.String())
+	assert.Equal(t, 4, g.Repetitions())
+	assert.Len(t, g.Moves(), 15)
+
+	// A pawn move cannot be undone: the earlier positions never repeat
+	push(t, g, "Ng8", "e4", "e5", "Nf3", "Nf6", "Ng1", "Ng8")
+	assert.Equal(t, 2, g.Repetitions())
+}
+
+func TestGameStateCastlingRights(t *testing.T) {
+	// The rooks return, but the castling rights are lost
+	b, err := ParseFen("r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1")
+	require.NoError(t, err)
+	g := NewGameState(b)
+	push(t, g, "Rb1", "Rb8", "Ra1", "Ra8")
+	assert.Equal(t, 1, g.Repetitions())
+	push(t, g, "Rb1", "Rb8", "Ra1", "Ra8")
+	assert.Equal(t, 2, g.Repetitions())
+}
+
+func TestGameStateEnPassant(t *testing.T) {
+	// After d4 the en passant square only counts when a pawn can take
+	b, err := ParseFen("4k3/8/8/8/8/8/3P4/4K3 w - - 0 1")
+	require.NoError(t, err)
+	assert.Equal(t, NoSquare, b.MakeMove(Move{From: D2, To: D4}).positionKey().epSquare)
+
+	b, err = ParseFen("4k3/8/8/8/4p3/8/3P4/4K3 w - - 0 1")
+	require.NoError(t, err)
+	assert.Equal(t, D3, b.MakeMove(Move{From: D2, To: D4}).positionKey().epSquare)
+}
+
+func TestGameStateMoveRules(t *testing.T) {
+	b, err := ParseFen("4k3/8/8/8/8/8/8/R3K3 w - - 99 80")
+	require.NoError(t, err)
+	g := NewGameState(b)
+	assert.Equal(t, "", g.ClaimableDraw())
+	push(t, g, "Ra2")
+	assert.Equal(t, "fifty-move rule", g.ClaimableDraw())
+
+	b, err = ParseFen("4k3/8/8/8/8/8/8/R3K3 w - - 149 100")
+	require.NoError(t, err)
+	g = NewGameState(b)
+	push(t, g, "Ra2")
+	result, reason := g.Termination()
+	assert.Equal(t, "1/2-1/2", result)
+	assert.Equal(t, "75-move rule", reason)
+
+	// Mate on the 150th half-move stands
+	b, err = ParseFen("4k3/R7/4K3/8/8/8/8/8 w - - 149 100")
+	require.NoError(t, err)
+	g = NewGameState(b)
+	push(t, g, "Ra8#")
+	result, reason = g.Termination()
+	assert.Equal(t, "1-0", result)
+	assert.Equal(t, "checkmate", reason)
+}
+
+func TestGameStatePush(t *testing.T) {
+	b, err := ParseFen("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1")
+	require.NoError(t, err)
+	g := NewGameState(b)
+	assert.EqualError(t, g.Push(Move{From: E2, To: E5}), "illegal move e2e5")
+	assert.Empty(t, g.Moves())
+	assert.Equal(t, NullMove, g.Pop())
+	assert.Same(t, b, g.Board())
+}
+
+func TestInsufficientMaterial(t *testing.T) {
+	tests := []struct {
+		fen  string
+		want bool
+	}{
+		{"4k3/8/8/8/8/8/8/4K3 w - - 0 1", true},
+		{"4k3/8/8/8/8/8/8/4KN2 w - - 0 1", true},
+		{"4kb2/8/8/8/8/8/8/2B1K3 w - - 0 1", true},   // bishops on light squares
+		{"4k1b1/8/8/8/8/8/8/2B1K3 w - - 0 1", false}, // bishops on opposite colors
+		{"4k3/8/8/8/8/8/8/3NKN2 w - - 0 1", false},
+		{"4k3/8/8/8/8/8/4P3/4K3 w - - 0 1", false},
+	}
+	for _, tt := range tests {
+		b, err := ParseFen(tt.fen)
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, b.InsufficientMaterial(), tt.fen)
+	}
+}
@@ -0,0 +1,127 @@
+package internal
+
+// IsLegal returns whether m can be played in this position, exactly when m
+// is one of LegalMoves. Only the one move is checked: that the piece moves
+// that way, that nothing blocks it, and that it does not leave the king
+// attacked. No other moves are generated, which makes it cheap enough to
+// validate moves entered by a player or received from the network.
+func (b *Board) IsLegal(m Move) bool {
+	if m.From < A1 || m.From > H8 || m.To < A1 || m.To > H8 || m.From == m.To {
+		return false
+	}
+	piece := b.Piece[m.From]
+	if piece == NoPiece || piece.Color() != b.SideToMove {
+		return false
+	}
+
+	// castling is written as the king taking its own rook
+	target := b.Piece[m.To]
+	if piece.Type() == King && target == b.my(Rook) {
+		for _, side := range []int{queenSide, kingSide} {
+			if b.CastleSq[b.SideToMove|side] == m.To {
+				return m.Promotion == NoPiece && b.CanCastle(b.SideToMove, side)
+			}
+		}
+		return false
+	}
+	if target != NoPiece && target.Color() == b.SideToMove {
+		return false
+	}
+	if !b.reaches(m) {
+		return false
+	}
+
+	// Make the move on a copy of the squares and look for attacks on the
+	// king. An en passant capture removes the pawn beside the destination.
+	pieces := b.Piece
+	if piece.Type() == Pawn && m.To == b.EpSquare {
+		pieces[Square(m.To.File(), m.From.Rank())] = NoPiece
+	}
+	pieces[m.To], pieces[m.From] = piece, NoPiece
+	king := m.To
+	if piece.Type() != King {
+		if king = b.find(b.my(King), A1, H8); king == NoSquare {
+			return true
+		}
+	}
+	return len(attackers(&pieces, king, b.SideToMove^1)) == 0
+}
+
+// reaches returns whether the piece on m.From moves to m.To the way its type
+// moves, over empty squares for a slider, with the promotion m.Promotion. It
+// does not check whether the move leaves the king in check.
+func (b *Board) reaches(m Move) bool {
+	piece := b.Piece[m.From]
+	df, dr := m.To.File()-m.From.File(), m.To.Rank()-m.From.Rank()
+
+	if piece.Type() == Pawn {
+		promotes := m.To.RelativeRank(b.SideToMove) == Rank8
+		switch p := m.Promotion; {
+		case promotes && (p.Color() != b.SideToMove || p.Type() < Knight || p.Type() > Queen):
+			return false
+		case !promotes && p != NoPiece:
+			return false
+		}
+		forward := []int{1, -1}[b.SideToMove]
+		switch {
+		case df == 0 && dr == forward:
+			return b.Piece[m.To] == NoPiece
+		case df == 0 && dr == 2*forward:
+			between := Square(m.From.File(), m.From.Rank()+forward)
+			return m.From.RelativeRank(b.SideToMove) == Rank2 &&
+				b.Piece[between] == NoPiece && b.Piece[m.To] == NoPiece
+		case (df == 1 || df == -1) && dr == forward:
+			return b.Piece[m.To] != NoPiece || m.To == b.EpSquare
+		}
+		return false
+	}
+	if m.Promotion != NoPiece {
+		return false
+	}
+
+	fd, rd := FileDistance(m.From, m.To), RankDistance(m.From, m.To)
+	switch piece.Type() {
+	case Knight:
+		return (fd == 1 && rd == 2) || (fd == 2 && rd == 1)
+	case King:
+		return KingDistance(m.From, m.To) == 1
+	case Bishop:
+		if fd != rd {
+			return false
+		}
+	case Rook:
+		if df != 0 && dr != 0 {
+			return false
+		}
+	case Queen:
+		if fd != rd && df != 0 && dr != 0 {
+			return false
+		}
+	}
+
+	// a slider needs the squares between from and to to be empty
+	offset := 8*sign(dr) + sign(df)
+	for sq := m.From.step(offset); sq != m.To; sq = sq.step(offset) {
+		if b.Piece[sq] != NoPiece {
+			return false
+		}
+	}
+	return true
+}
+
+// sign returns -1, 0 or 1 for a negative, zero or positive x.
+func sign(x int) int {
+	if x < 0 {
+		return -1
+	} else if x > 0 {
+		return 1
+	}
+	return 0
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,73 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// assertIsLegalAgrees checks IsLegal on every from, to and promotion
+// combination against LegalMoves, in b and the positions depth moves on.
+func assertIsLegalAgrees(t *testing.T, b *Board, depth int) {
+	t.Helper()
+	legal := make(map[Move]bool)
+	for _, m := range b.LegalMoves() {
+		legal[m] = true
+	}
+	promotions := []Piece{NoPiece, b.my(Knight), b.my(Bishop), b.my(Rook), b.my(Queen), b.opp(Queen)}
+	for from := A1; from <= H8; from++ {
+		if b.Piece[from] == NoPiece {
+			continue
+		}
+		for to := A1; to <= H8; to++ {
+			for _, p := range promotions {
+				m := Move{From: from, To: to, Promotion: p}
+				if b.IsLegal(m) != legal[m] {
+					t.Fatalf("%s: IsLegal(%s) = %v", b.Fen(), m.Uci(b), !legal[m])
+				}
+			}
+		}
+	}
+	if depth > 1 {
+		for m := range legal {
+			assertIsLegalAgrees(t, b.MakeMove(m), depth-1)
+		}
+	}
+}
+
+func TestIsLegal(t *testing.T) {
+	tests := []struct {
+		name string
+		fen  string
+	}{
+		{"Starting position", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"},
+		{"Kiwipete", "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1"},
+		{"Endgame", "8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1"},
+		{"Promotions", "r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1"},
+		{"Pins and en passant", "8/8/8/8/k2Pp2Q/8/8/3K4 b - d3 0 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := ParseFen(tt.fen)
+			require.NoError(t, err)
+			assertIsLegalAgrees(t, b, 2)
+		})
+	}
+}
+
+func TestIsLegalMoves(t *testing.T) {
+	b, err := ParseFen("r3k2r/8/8/8/8/8/5r2/R3K2R w KQ - 0 1")
+	require.NoError(t, err)
+
+	assert.True(t, b.IsLegal(Move{From: E1, To: A1}), "O-O-O")
+	assert.False(t, b.IsLegal(Move{From: E1, To: H1}), "O-O through an attacked square")
+	assert.False(t, b.IsLegal(Move{From: E1, To: G1}), "castling as a two-square king move")
+	assert.True(t, b.IsLegal(Move{From: E1, To: F2}), "king taking the rook")
+	assert.False(t, b.IsLegal(Move{From: E1, To: F1}), "king into check")
+	assert.True(t, b.IsLegal(Move{From: E1, To: D1}))
+	assert.False(t, b.IsLegal(Move{From: A1, To: A8, Promotion: WQ}), "promotion of a rook")
+	assert.False(t, b.IsLegal(Move{From: A8, To: A1}), "opponent's piece")
+	assert.False(t, b.IsLegal(Move{From: NoSquare, To: A1}))
+	assert.False(t, b.IsLegal(NullMove))
+}
@@ -0,0 +1,442 @@
+package lichess
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kyleboon/gochess/internal"
+	"github.com/kyleboon/gochess/internal/engine"
+)
+
+// startFEN is the standard starting position, "startpos" in game streams.
+const startFEN = "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
+
+// GetAccount fetches the account the API token belongs to.
+func (c *Client) GetAccount(ctx context.Context) (*Account, error) {
+	var account Account
+	if err := c.botRequest(ctx, http.MethodGet, "/account", nil, &account); err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// AcceptChallenge accepts a challenge sent to the account.
+func (c *Client) AcceptChallenge(ctx context.Context, id string) error {
+	return c.botRequest(ctx, http.MethodPost, "/challenge/"+id+"/accept", nil, nil)
+}
+
+// DeclineChallenge declines a challenge sent to the account, giving one of
+// the reasons Lichess knows, as in "later", "variant" or "timeControl".
+func (c *Client) DeclineChallenge(ctx context.Context, id, reason string) error {
+	form := url.Values{"reason": {reason}}
+	return c.botRequest(ctx, http.MethodPost, "/challenge/"+id+"/decline", form, nil)
+}
+
+// MakeBotMove plays a move, in UCI, in a game of the bot account.
+func (c *Client) MakeBotMove(ctx context.Context, gameID, move string) error {
+	return c.botRequest(ctx, http.MethodPost, "/bot/game/"+gameID+"/move/"+move, nil, nil)
+}
+
+// StreamBotEvents passes the incoming events of the bot account to fn as
+// they come, until the stream ends, ctx is done or fn returns an error.
+func (c *Client) StreamBotEvents(ctx context.Context, fn func(BotEvent) error) error {
+	return c.streamNDJSON(ctx, "/stream/event", func(line []byte) error {
+		var event BotEvent
+		if err := json.Unmarshal(line, &event); err != nil {
+			return fmt.Errorf("failed to decode event: %w", err)
+		}
+		return fn(event)
+	})
+}
+
+// StreamBotGame passes the events of a game of the bot account to fn as
+// they come, until the game ends, ctx is done or fn returns an error.
+func (c *Client) StreamBotGame(ctx context.Context, gameID string, fn func(BotGameEvent) error) error {
+	return c.streamNDJSON(ctx, "/bot/game/stream/"+gameID, func(line []byte) error {
+		var event BotGameEvent
+		if err := json.Unmarshal(line, &event); err != nil {
+			return fmt.Errorf("failed to decode game event: %w", err)
+		}
+		return fn(event)
+	})
+}
+
+// ExportGamePGN fetches the PGN of a game, with its clock times.
+func (c *Client) ExportGamePGN(ctx context.Context, gameID string) (string, error) {
+	apiURL := strings.TrimSuffix(c.baseURL, "/api") + "/game/export/" + gameID + "?clocks=true&evals=false"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/x-chess-pgn")
+	if c.apiToken != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.apiToken))
+	}
+	resp, err := c.doRequestWithRetry(ctx, req)
+	if err != nil {
+		return "", fmt.Errorf("failed to export game: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("lichess API returned status code %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read game: %w", err)
+	}
+	return string(body), nil
+}
+
+// botRequest makes an authenticated request to the bot API, sending form
+// if not nil and decoding the JSON answer into out if not nil
+func (c *Client) botRequest(ctx context.Context, method, path string, form url.Values, out interface{}) error {
+	if c.apiToken == "" {
+		return errors.New("the bot API requires a Lichess API token")
+	}
+	var body io.Reader
+	if form != nil {
+		body = strings.NewReader(form.Encode())
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.apiToken))
+	req.Header.Set("Accept", "application/json")
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to request %s: %w", path, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("lichess API returned status code %d for %s: %s", resp.StatusCode, path, strings.TrimSpace(string(message)))
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// streamNDJSON opens a stream of newline-delimited JSON and passes each
+// line to fn, skipping the empty lines Lichess sends to keep it open
+func (c *Client) streamNDJSON(ctx context.Context, path string, fn func([]byte) error) error {
+	if c.apiToken == "" {
+		return errors.New("the bot API requires a Lichess API token")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.apiToken))
+	req.Header.Set("Accept", "application/x-ndjson")
+
+	// Streams stay open for hours, past the client's timeout
+	streamClient := &http.Client{Transport: c.httpClient.Transport}
+	resp, err := streamClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to open stream %s: %w", path, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("lichess API returned status code %d for %s", resp.StatusCode, path)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(strings.TrimSpace(string(line))) == 0 {
+			continue
+		}
+		if err := fn(line); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil && ctx.Err() == nil {
+		return fmt.Errorf("failed to read stream %s: %w", path, err)
+	}
+	return nil
+}
+
+// BotOptions configures which challenges a Bot accepts and how long it
+// thinks.
+type BotOptions struct {
+	MaxGames    int           // games played at once; more challenges are declined (default 1)
+	MaxMoveTime time.Duration // longest think per move (default 10s)
+	Rated       bool          // accept rated challenges, not only casual ones
+	Retry       time.Duration // wait before reconnecting a dropped event stream (default 5s)
+}
+
+// Bot plays the games of a Lichess bot account with an engine: it accepts
+// the challenges to standard chess with a clock and moves whenever it is its
+// turn.
+type Bot struct {
+	client   *Client
+	engine   engine.Analyzer
+	opts     BotOptions
+	logger   *slog.Logger
+	onFinish func(gameID, pgnText string)
+
+	account string // lowercase username of the bot
+	mu      sync.Mutex
+	games   map[string]bool // being played
+	wg      sync.WaitGroup
+}
+
+// NewBot creates a bot playing with eng through client, which must have
+// the API token of a bot account with the bot:play scope.
+func NewBot(client *Client, eng engine.Analyzer, opts BotOptions) *Bot {
+	if opts.MaxGames <= 0 {
+		opts.MaxGames = 1
+	}
+	if opts.MaxMoveTime <= 0 {
+		opts.MaxMoveTime = 10 * time.Second
+	}
+	if opts.Retry <= 0 {
+		opts.Retry = 5 * time.Second
+	}
+	return &Bot{
+		client: client,
+		engine: eng,
+		opts:   opts,
+		logger: client.logger.With("bot", true),
+		games:  make(map[string]bool),
+	}
+}
+
+// OnGameFinished sets a function passed the PGN of each game once it is
+// over.
+func (b *Bot) OnGameFinished(fn func(gameID, pgnText string)) {
+	b.onFinish = fn
+}
+
+// Run answers challenges and plays games until ctx is done, reconnecting
+// when the event stream drops. It returns once the games being played have
+// stopped.
+func (b *Bot) Run(ctx context.Context) error {
+	account, err := b.client.GetAccount(ctx)
+	if err != nil {
+		return err
+	}
+	b.account = account.ID
+	b.logger.Info("bot connected", "account", account.Username)
+	defer b.wg.Wait()
+
+	for {
+		err := b.client.StreamBotEvents(ctx, func(event BotEvent) error {
+			b.handleEvent(ctx, event)
+			return nil
+		})
+		if ctx.Err() != nil {
+			return nil
+		}
+		b.logger.Warn("event stream ended, reconnecting", "error", err, "after", b.opts.Retry)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(b.opts.Retry):
+		}
+	}
+}
+
+// handleEvent answers a challenge or starts playing a game
+func (b *Bot) handleEvent(ctx context.Context, event BotEvent) {
+	switch event.Type {
+	case "challenge":
+		if event.Challenge == nil {
+			return
+		}
+		ch := event.Challenge
+		if reason := b.declineReason(ch); reason != "" {
+			b.logger.Info("declining challenge", "id", ch.ID, "from", ch.Challenger.Name, "reason", reason)
+			if err := b.client.DeclineChallenge(ctx, ch.ID, reason); err != nil {
+				b.logger.Warn("failed to decline challenge", "id", ch.ID, "error", err)
+			}
+			return
+		}
+		b.logger.Info("accepting challenge", "id", ch.ID, "from", ch.Challenger.Name, "speed", ch.Speed)
+		if err := b.client.AcceptChallenge(ctx, ch.ID); err != nil {
+			b.logger.Warn("failed to accept challenge", "id", ch.ID, "error", err)
+		}
+	case "gameStart":
+		if event.Game == nil {
+			return
+		}
+		b.mu.Lock()
+		playing := b.games[event.Game.GameID]
+		b.games[event.Game.GameID] = true
+		b.mu.Unlock()
+		if playing {
+			return
+		}
+		b.wg.Add(1)
+		go func(gameID string) {
+			defer b.wg.Done()
+			defer func() {
+				b.mu.Lock()
+				delete(b.games, gameID)
+				b.mu.Unlock()
+			}()
+			if err := b.playGame(ctx, gameID); err != nil && ctx.Err() == nil {
+				b.logger.Error("game failed", "game", gameID, "error", err)
+			}
+		}(event.Game.GameID)
+	}
+}
+
+// declineReason returns why a challenge is declined, or "" to accept it
+func (b *Bot) declineReason(ch *Challenge) string {
+	b.mu.Lock()
+	busy := len(b.games) >= b.opts.MaxGames
+	b.mu.Unlock()
+	switch {
+	case ch.Variant.Key != "standard":
+		return "standard"
+	case ch.TimeControl.Type != "clock":
+		return "timeControl"
+	case ch.Rated && !b.opts.Rated:
+		return "casual"
+	case busy:
+		return "later"
+	}
+	return ""
+}
+
+// playGame follows a game, moving whenever it is the bot's turn, and hands
+// its PGN over once it is over
+func (b *Bot) playGame(ctx context.Context, gameID string) error {
+	var initial *internal.Board
+	color := -1
+	err := b.client.StreamBotGame(ctx, gameID, func(event BotGameEvent) error {
+		state := &event.BotGameState
+		switch event.Type {
+		case "gameFull":
+			fen := event.InitialFen
+			if fen == "" || fen == "startpos" {
+				fen = startFEN
+			}
+			board, err := internal.ParseFen(fen)
+			if err != nil {
+				return fmt.Errorf("invalid initial position: %w", err)
+			}
+			initial = board
+			switch b.account {
+			case strings.ToLower(event.White.ID):
+				color = internal.White
+			case strings.ToLower(event.Black.ID):
+				color = internal.Black
+			}
+			if event.State == nil {
+				return nil
+			}
+			state = event.State
+		case "gameState":
+		default:
+			return nil
+		}
+		if initial == nil || color < 0 {
+			return fmt.Errorf("game %s is not a game of %s", gameID, b.account)
+		}
+		if state.Status != "started" && state.Status != "created" {
+			return errGameOver
+		}
+		return b.move(ctx, gameID, initial, color, state)
+	})
+	if err != nil && !errors.Is(err, errGameOver) {
+		return err
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+
+	b.logger.Info("game over", "game", gameID)
+	if b.onFinish == nil {
+		return nil
+	}
+	pgnText, err := b.client.ExportGamePGN(ctx, gameID)
+	if err != nil {
+		return err
+	}
+	b.onFinish(gameID, pgnText)
+	return nil
+}
+
+// errGameOver ends the stream of a game once it is over
+var errGameOver = errors.New("game over")
+
+// move plays the engine's move if it is the bot's turn in the state
+func (b *Bot) move(ctx context.Context, gameID string, initial *internal.Board, color int, state *BotGameState) error {
+	board := initial
+	for _, uci := range strings.Fields(state.Moves) {
+		m, err := board.ParseMove(uci)
+		if err != nil {
+			return fmt.Errorf("invalid move %s: %w", uci, err)
+		}
+		board = board.MakeMove(m)
+	}
+	if board.SideToMove != color {
+		return nil
+	}
+
+	left, inc := state.WTime, state.WInc
+	if color == internal.Black {
+		left, inc = state.BTime, state.BInc
+	}
+	result, err := b.engine.Analyze(ctx, board.Fen(), engine.AnalysisOptions{MoveTime: b.thinkTime(left, inc)})
+	if err != nil {
+		return fmt.Errorf("engine failed: %w", err)
+	}
+	if len(result.Lines) == 0 || len(result.Lines[0].Moves) == 0 {
+		return nil // no legal move: the game is over
+	}
+	m, err := board.ParseMove(result.Lines[0].Moves[0])
+	if err != nil {
+		return fmt.Errorf("engine played an invalid move %s: %w", result.Lines[0].Moves[0], err)
+	}
+	uci := standardUci(board, m)
+	b.logger.Debug("playing move", "game", gameID, "move", uci)
+	return b.client.MakeBotMove(ctx, gameID, uci)
+}
+
+// thinkTime returns how long to think on a move with left milliseconds on
+// the clock and inc added per move: a thirtieth of the time left and most of
+// the increment, at most MaxMoveTime
+func (b *Bot) thinkTime(left, inc int) time.Duration {
+	think := time.Duration(left/30+inc*3/4) * time.Millisecond
+	if left <= 0 {
+		think = b.opts.MaxMoveTime // untimed
+	}
+	return min(max(think, 50*time.Millisecond), b.opts.MaxMoveTime)
+}
+
+// standardUci writes a move in UCI with castling as the king's two square
+// move, e1g1, rather than the king taking its rook as Move.Uci writes it
+func standardUci(b *internal.Board, m internal.Move) string {
+	king, target := b.Piece[m.From], b.Piece[m.To]
+	if king.Type() != internal.King || target == internal.NoPiece || target.Color() != king.Color() {
+		return m.Uci(b)
+	}
+	file := internal.FileG
+	if m.To.File() < m.From.File() {
+		file = internal.FileC
+	}
+	return m.From.String() + internal.Square(file, m.From.Rank()).String()
+}
@@ -0,0 +1,172 @@
+package lichess
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kyleboon/gochess/internal"
+	"github.com/kyleboon/gochess/internal/engine"
+	"github.com/kyleboon/gochess/internal/logging"
+)
+
+// stubEngine always plays the same move
+type stubEngine struct {
+	move string
+	fens []string
+}
+
+func (e *stubEngine) Analyze(ctx context.Context, fen string, opts engine.AnalysisOptions) (*engine.AnalysisResult, error) {
+	e.fens = append(e.fens, fen)
+	return &engine.AnalysisResult{FEN: fen, Lines: []engine.AnalysisLine{{Rank: 1, Moves: []string{e.move}}}}, nil
+}
+
+func (e *stubEngine) Close() error { return nil }
+
+func TestBot_Run(t *testing.T) {
+	const pgnText = "[Event \"Casual game\"]\n\n1. e4 1-0\n"
+	var (
+		mu       sync.Mutex
+		accepted []string
+		declined []string
+		moves    []string
+	)
+	moved := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		flusher := w.(http.Flusher)
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case r.URL.Path == "/api/account":
+			_, _ = w.Write([]byte(`{"id":"gobot","username":"GoBot","title":"BOT"}`))
+		case r.URL.Path == "/api/stream/event":
+			fmt.Fprintln(w, `{"type":"challenge","challenge":{"id":"c1","rated":false,"speed":"blitz","challenger":{"name":"Anna"},"variant":{"key":"standard"},"timeControl":{"type":"clock","limit":300,"increment":3}}}`)
+			fmt.Fprintln(w, `{"type":"challenge","challenge":{"id":"c2","rated":false,"challenger":{"name":"Ben"},"variant":{"key":"chess960"},"timeControl":{"type":"clock","limit":300,"increment":3}}}`)
+			fmt.Fprintln(w)
+			fmt.Fprintln(w, `{"type":"gameStart","game":{"gameId":"g1"}}`)
+			flusher.Flush()
+			mu.Unlock()
+			<-r.Context().Done()
+			mu.Lock()
+		case r.URL.Path == "/api/bot/game/stream/g1":
+			fmt.Fprintln(w, `{"type":"gameFull","id":"g1","initialFen":"startpos","white":{"id":"gobot","name":"GoBot"},"black":{"id":"anna","name":"Anna"},"state":{"type":"gameState","moves":"","wtime":300000,"btime":300000,"winc":3000,"binc":3000,"status":"started"}}`)
+			flusher.Flush()
+			mu.Unlock()
+			select {
+			case <-moved:
+			case <-r.Context().Done():
+			}
+			mu.Lock()
+			fmt.Fprintln(w, `{"type":"gameState","moves":"e2e4","wtime":299000,"btime":300000,"winc":3000,"binc":3000,"status":"started"}`)
+			fmt.Fprintln(w, `{"type":"gameState","moves":"e2e4","wtime":299000,"btime":300000,"winc":3000,"binc":3000,"status":"resign","winner":"white"}`)
+		case strings.HasPrefix(r.URL.Path, "/api/bot/game/g1/move/"):
+			moves = append(moves, strings.TrimPrefix(r.URL.Path, "/api/bot/game/g1/move/"))
+			_, _ = w.Write([]byte(`{"ok":true}`))
+			close(moved)
+		case strings.HasSuffix(r.URL.Path, "/accept"):
+			accepted = append(accepted, strings.Split(r.URL.Path, "/")[3])
+			_, _ = w.Write([]byte(`{"ok":true}`))
+		case strings.HasSuffix(r.URL.Path, "/decline"):
+			declined = append(declined, strings.Split(r.URL.Path, "/")[3]+":"+r.FormValue("reason"))
+			_, _ = w.Write([]byte(`{"ok":true}`))
+		case r.URL.Path == "/game/export/g1":
+			if accept := r.Header.Get("Accept"); accept != "application/x-chess-pgn" {
+				t.Errorf("expected the PGN to be asked for, got %q", accept)
+			}
+			_, _ = w.Write([]byte(pgnText))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClientWithLogger(logging.Discard())
+	client.baseURL = server.URL + "/api"
+	client.SetAPIToken("secret")
+
+	eng := &stubEngine{move: "e2e4"}
+	bot := NewBot(client, eng, BotOptions{Retry: 10 * time.Millisecond})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var finished []string
+	bot.OnGameFinished(func(gameID, pgn string) {
+		finished = append(finished, gameID+":"+pgn)
+		cancel()
+	})
+	if err := bot.Run(ctx); err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(accepted) != 1 || accepted[0] != "c1" {
+		t.Errorf("expected challenge c1 to be accepted, got %v", accepted)
+	}
+	if len(declined) != 1 || declined[0] != "c2:standard" {
+		t.Errorf("expected challenge c2 to be declined as a variant, got %v", declined)
+	}
+	if len(moves) != 1 || moves[0] != "e2e4" {
+		t.Errorf("expected the bot to play e2e4 once, got %v", moves)
+	}
+	if len(eng.fens) != 1 || eng.fens[0] != startFEN {
+		t.Errorf("expected the engine to think on the starting position only, got %v", eng.fens)
+	}
+	if len(finished) != 1 || finished[0] != "g1:"+pgnText {
+		t.Errorf("expected the PGN of g1 once the game was over, got %q", finished)
+	}
+}
+
+func TestBot_RequiresToken(t *testing.T) {
+	client := NewClientWithLogger(logging.Discard())
+	bot := NewBot(client, &stubEngine{}, BotOptions{})
+	if err := bot.Run(context.Background()); err == nil {
+		t.Error("expected an error without an API token")
+	}
+}
+
+func TestBot_ThinkTime(t *testing.T) {
+	bot := NewBot(NewClientWithLogger(logging.Discard()), &stubEngine{}, BotOptions{MaxMoveTime: 5 * time.Second})
+	tests := []struct {
+		left, inc int
+		want      time.Duration
+	}{
+		{300000, 0, 5 * time.Second},
+		{60000, 2000, 3500 * time.Millisecond},
+		{600, 0, 50 * time.Millisecond},
+		{0, 0, 5 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := bot.thinkTime(tt.left, tt.inc); got != tt.want {
+			t.Errorf("thinkTime(%d, %d) = %v, want %v", tt.left, tt.inc, got, tt.want)
+		}
+	}
+}
+
+func TestStandardUci(t *testing.T) {
+	board, err := internal.ParseFen("r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string]string{"e1g1": "e1g1", "e1c1": "e1c1", "a1a8": "a1a8", "e1e2": "e1e2"}
+	for input, want := range tests {
+		m, err := board.ParseMove(input)
+		if err != nil {
+			t.Fatalf("ParseMove(%q) error: %v", input, err)
+		}
+		if got := standardUci(board, m); got != want {
+			t.Errorf("standardUci(%s) = %q, want %q", input, got, want)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package lichess
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// roundIDPattern matches the ID of a broadcast round, the last part of its
+// URL as in https://lichess.org/broadcast/club-open/round-1/AbCd1234
+var roundIDPattern = regexp.MustCompile(`^[A-Za-z0-9]{8}$`)
+
+// BroadcastRoundID returns the ID of a broadcast round from its URL, or the
+// ID itself.
+func BroadcastRoundID(roundURL string) (string, error) {
+	id := strings.TrimSpace(roundURL)
+	if u, err := url.Parse(id); err == nil && u.Host != "" {
+		parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+		id = parts[len(parts)-1]
+	}
+	if !roundIDPattern.MatchString(id) {
+		return "", fmt.Errorf("%q is not a Lichess broadcast round URL or ID", roundURL)
+	}
+	return id, nil
+}
+
+// PushBroadcastPGN sends the PGN of the games of a broadcast round to
+// Lichess, which updates the round's games with it. The API token must have
+// the study:write scope and belong to a contributor of the broadcast.
+//
+// Pushes are not retried when rate limited, as PGN is pushed again with the
+// next moves anyway.
+func (c *Client) PushBroadcastPGN(ctx context.Context, roundID, pgnText string) (*BroadcastPushResult, error) {
+	if c.apiToken == "" {
+		return nil, errors.New("pushing to a broadcast requires a Lichess API token")
+	}
+	apiURL := fmt.Sprintf("%s/broadcast/round/%s/push", c.baseURL, roundID)
+	c.logger.Debug("pushing PGN to broadcast round", "round", roundID, "pgnSize", len(pgnText))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, apiURL, strings.NewReader(pgnText))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.apiToken))
+	req.Header.Set("Content-Type", "text/plain")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		c.logger.Error("HTTP request failed", "error", err, "url", apiURL)
+		return nil, fmt.Errorf("failed to push PGN: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		c.logger.Warn("unexpected status code from Lichess broadcast push",
+			"statusCode", resp.StatusCode,
+			"url", apiURL)
+		if message := strings.TrimSpace(string(body)); message != "" {
+			return nil, fmt.Errorf("lichess API returned status code %d: %s", resp.StatusCode, message)
+		}
+		return nil, fmt.Errorf("lichess API returned status code %d", resp.StatusCode)
+	}
+
+	var result BroadcastPushResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode push response: %w", err)
+	}
+	return &result, nil
+}
+
+// Broadcaster relays a game to a broadcast round, pushing its PGN whenever
+// it changes.
+type Broadcaster struct {
+	client  *Client
+	roundID string
+	last    string // PGN last pushed
+}
+
+// NewBroadcaster creates a Broadcaster pushing to the round roundID with
+// client, which must have an API token.
+func NewBroadcaster(client *Client, roundID string) *Broadcaster {
+	return &Broadcaster{client: client, roundID: roundID}
+}
+
+// Push pushes pgnText to the round, unless it is empty or was the last PGN
+// pushed. It returns nil if nothing was pushed.
+func (b *Broadcaster) Push(ctx context.Context, pgnText string) (*BroadcastPushResult, error) {
+	if strings.TrimSpace(pgnText) == "" || pgnText == b.last {
+		return nil, nil
+	}
+	result, err := b.client.PushBroadcastPGN(ctx, b.roundID, pgnText)
+	if err != nil {
+		return nil, err
+	}
+	b.last = pgnText
+	return result, nil
+}
+
+// Run reads the PGN to relay from source every interval until ctx is done,
+// pushing it when it changed and passing the answer to fn. Errors reading
+// or pushing the PGN are passed to onError and do not stop the relay.
+func (b *Broadcaster) Run(ctx context.Context, interval time.Duration, source func() (string, error), fn func(*BroadcastPushResult), onError func(error)) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		pgnText, err := source()
+		if err == nil {
+			var result *BroadcastPushResult
+			result, err = b.Push(ctx, pgnText)
+			if result != nil {
+				fn(result)
+			}
+		}
+		switch {
+		case ctx.Err() != nil:
+			return nil
+		case err != nil:
+			onError(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
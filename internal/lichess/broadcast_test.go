@@ -0,0 +1,165 @@
+package lichess
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kyleboon/gochess/internal/logging"
+)
+
+func TestBroadcastRoundID(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"https://lichess.org/broadcast/club-open/round-1/AbCd1234", "AbCd1234", false},
+		{"https://lichess.org/broadcast/club-open/round-1/AbCd1234/", "AbCd1234", false},
+		{"AbCd1234", "AbCd1234", false},
+		{"https://lichess.org/broadcast/club-open", "", true},
+		{"", "", true},
+	}
+	for _, tt := range tests {
+		got, err := BroadcastRoundID(tt.input)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("BroadcastRoundID(%q) = %q, %v; want %q (error: %v)", tt.input, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestClient_PushBroadcastPGN(t *testing.T) {
+	const game = "[White \"Anna\"]\n[Black \"Ben\"]\n\n1. e4 e5 *\n"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/broadcast/round/AbCd1234/push" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":"No such token"}`))
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != game {
+			t.Errorf("expected the PGN %q to be pushed, got %q", game, body)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"games":[{"tags":{"White":"Anna","Black":"Ben"},"moves":2}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithLogger(logging.Discard())
+	client.baseURL = server.URL
+	ctx := context.Background()
+
+	if _, err := client.PushBroadcastPGN(ctx, "AbCd1234", game); err == nil {
+		t.Error("expected an error without a token")
+	}
+
+	client.SetAPIToken("wrong")
+	if _, err := client.PushBroadcastPGN(ctx, "AbCd1234", game); err == nil {
+		t.Error("expected an error for a rejected token")
+	}
+
+	client.SetAPIToken("secret")
+	result, err := client.PushBroadcastPGN(ctx, "AbCd1234", game)
+	if err != nil {
+		t.Fatalf("PushBroadcastPGN failed: %v", err)
+	}
+	if len(result.Games) != 1 || result.Games[0].Moves != 2 || result.Games[0].Tags["White"] != "Anna" {
+		t.Errorf("unexpected result %+v", result)
+	}
+}
+
+func TestBroadcaster_Run(t *testing.T) {
+	var mu sync.Mutex
+	var pushed []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		pushed = append(pushed, string(body))
+		mu.Unlock()
+		_, _ = w.Write([]byte(`{"games":[{"tags":{},"moves":1}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithLogger(logging.Discard())
+	client.baseURL = server.URL
+	client.SetAPIToken("secret")
+	broadcaster := NewBroadcaster(client, "AbCd1234")
+
+	// The game changes on the third read; reading fails on the fourth
+	reads := 0
+	games := []string{"1. e4 *", "1. e4 *", "1. e4 e5 *", "", "1. e4 e5 *"}
+	ctx, cancel := context.WithCancel(context.Background())
+	source := func() (string, error) {
+		reads++
+		if reads == len(games) {
+			cancel()
+		}
+		if reads == 4 {
+			return "", errors.New("file is being written")
+		}
+		return games[reads-1], nil
+	}
+	results, errs := 0, 0
+	err := broadcaster.Run(ctx, time.Millisecond, source,
+		func(*BroadcastPushResult) { results++ },
+		func(error) { errs++ })
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(pushed) != 2 || pushed[0] != "1. e4 *" || pushed[1] != "1. e4 e5 *" {
+		t.Errorf("expected each version of the game pushed once, got %q", pushed)
+	}
+	if results != 2 || errs != 1 {
+		t.Errorf("expected 2 results and 1 error, got %d and %d", results, errs)
+	}
+}
+
+func TestBroadcaster_Push(t *testing.T) {
+	requests, fail := 0, true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if fail {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte(`{"games":[{"tags":{"White":"Anna","Black":"Ben"},"error":"Invalid move"}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithLogger(logging.Discard())
+	client.baseURL = server.URL
+	client.SetAPIToken("secret")
+	broadcaster := NewBroadcaster(client, "AbCd1234")
+	ctx := context.Background()
+
+	if result, err := broadcaster.Push(ctx, "  \n"); result != nil || err != nil || requests != 0 {
+		t.Errorf("expected an empty game not to be pushed, got %v (%v) after %d requests", result, err, requests)
+	}
+
+	// A failed push is not retried, and the game is pushed again next time
+	if _, err := broadcaster.Push(ctx, "1. e4 *"); err == nil || requests != 1 {
+		t.Fatalf("expected the rate limited push to fail once, got %v after %d requests", err, requests)
+	}
+	fail = false
+	result, err := broadcaster.Push(ctx, "1. e4 *")
+	if err != nil || result == nil {
+		t.Fatalf("expected the game to be pushed again, got %v (%v)", result, err)
+	}
+	if len(result.Games) != 1 || result.Games[0].Error != "Invalid move" {
+		t.Errorf("expected the game rejected by Lichess to be reported, got %+v", result)
+	}
+	if result, err := broadcaster.Push(ctx, "1. e4 *"); result != nil || err != nil || requests != 2 {
+		t.Errorf("expected the unchanged game not to be pushed, got %v (%v) after %d requests", result, err, requests)
+	}
+}
@@ -13,6 +13,7 @@ package lichess
 import (
 	"bufio"
 	"context"
+	"encoding/json"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -27,6 +28,10 @@ import (
 const (
 	baseURL = "https://lichess.org/api"
 
+	// explorerURL is the base URL of the opening explorer, which is served
+	// from its own host
+	explorerURL = "https://explorer.lichess.ovh"
+
 	// Default retry configuration
 	defaultMaxRetries     = 3
 	defaultInitialBackoff = 1 * time.Second
@@ -63,6 +68,7 @@ type Client struct {
 	retryConfig RetryConfig
 	apiToken    string
 	baseURL     string // Base URL for API requests (exposed for testing)
+	explorerURL string // Base URL for opening explorer requests (exposed for testing)
 }
 
 // NewClient creates a new Lichess API client with default settings.
@@ -76,9 +82,10 @@ func NewClientWithLogger(logger *slog.Logger) *Client {
 		httpClient: &http.Client{
 			Timeout: 60 * time.Second, // Longer timeout for streaming responses
 		},
-		logger:      logger,
+		logger:      logger.With("component", "lichess"),
 		retryConfig: DefaultRetryConfig(),
 		baseURL:     baseURL,
+		explorerURL: explorerURL,
 	}
 }
 
@@ -236,6 +243,49 @@ func (c *Client) GetPlayerGamesPGN(ctx context.Context, params GamesParams) (str
 	return pgn, nil
 }
 
+// GetMastersExplorer fetches the moves played from the position of fen in
+// the Lichess masters database, most played first. At most moves moves are
+// returned, or the explorer's default number if moves is 0.
+func (c *Client) GetMastersExplorer(ctx context.Context, fen string, moves int) (*ExplorerResult, error) {
+	query := url.Values{}
+	query.Set("fen", fen)
+	if moves > 0 {
+		query.Set("moves", strconv.Itoa(moves))
+	}
+	apiURL := c.explorerURL + "/masters?" + query.Encode()
+
+	c.logger.Debug("fetching masters explorer position", "fen", fen, "url", apiURL)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if c.apiToken != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.apiToken))
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.doRequestWithRetry(ctx, req)
+	if err != nil {
+		c.logger.Error("HTTP request failed", "error", err, "url", apiURL)
+		return nil, fmt.Errorf("failed to fetch explorer position: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		c.logger.Warn("unexpected status code from Lichess explorer",
+			"statusCode", resp.StatusCode,
+			"url", apiURL)
+		return nil, fmt.Errorf("lichess explorer returned status code %d", resp.StatusCode)
+	}
+
+	var result ExplorerResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode explorer response: %w", err)
+	}
+	return &result, nil
+}
+
 // buildQueryParams constructs the query string from GamesParams.
 func (c *Client) buildQueryParams(params GamesParams) string {
 	queryParams := url.Values{}
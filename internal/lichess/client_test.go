@@ -719,3 +719,58 @@ func TestClient_GetPlayerGamesPGN(t *testing.T) {
 		}
 	})
 }
+
+func TestClient_GetMastersExplorer(t *testing.T) {
+	const fen = "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq - 0 1"
+
+	t.Run("Success", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/masters" {
+				t.Errorf("unexpected path: %s", r.URL.Path)
+			}
+			if got := r.URL.Query().Get("fen"); got != fen {
+				t.Errorf("expected fen=%q, got %q", fen, got)
+			}
+			if got := r.URL.Query().Get("moves"); got != "2" {
+				t.Errorf("expected moves=2, got %q", got)
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"white": 30, "draws": 40, "black": 20, "moves": [
+				{"uci": "c7c5", "san": "c5", "averageRating": 2400, "white": 12, "draws": 15, "black": 10},
+				{"uci": "e7e5", "san": "e5", "averageRating": 2410, "white": 9, "draws": 14, "black": 5}
+			]}`))
+		}))
+		defer server.Close()
+
+		client := NewClientWithLogger(logging.Discard())
+		client.explorerURL = server.URL
+
+		result, err := client.GetMastersExplorer(context.Background(), fen, 2)
+		if err != nil {
+			t.Fatalf("expected success, got error: %v", err)
+		}
+		if result.White != 30 || result.Draws != 40 || result.Black != 20 {
+			t.Errorf("unexpected totals: %+v", result)
+		}
+		if len(result.Moves) != 2 {
+			t.Fatalf("expected 2 moves, got %d", len(result.Moves))
+		}
+		if m := result.Moves[0]; m.UCI != "c7c5" || m.SAN != "c5" || m.Games() != 37 {
+			t.Errorf("unexpected first move: %+v", m)
+		}
+	})
+
+	t.Run("Error status", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		defer server.Close()
+
+		client := NewClientWithLogger(logging.Discard())
+		client.explorerURL = server.URL
+
+		if _, err := client.GetMastersExplorer(context.Background(), fen, 0); err == nil {
+			t.Error("expected an error for status 401")
+		}
+	})
+}
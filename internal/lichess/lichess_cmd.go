@@ -3,6 +3,7 @@ package lichess
 import (
 	"context"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"path/filepath"
@@ -11,6 +12,8 @@ import (
 
 	"github.com/kyleboon/gochess/internal/config"
 	"github.com/kyleboon/gochess/internal/db"
+	"github.com/kyleboon/gochess/internal/output"
+	"github.com/kyleboon/gochess/internal/progress"
 	"github.com/urfave/cli/v2"
 )
 
@@ -29,12 +32,76 @@ func expandPath(path string) string {
 	return path
 }
 
-// DownloadGames downloads games for a Lichess user
+// downloadSummary is the --json output of the download command
+type downloadSummary struct {
+	Username   string `json:"username"`
+	Games      int    `json:"games"`
+	Imported   int    `json:"imported"`
+	TotalGames int    `json:"total_games,omitempty"`
+	Output     string `json:"output,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// DownloadGames downloads games for one or more Lichess users, one after
+// the other
 func DownloadGames(c *cli.Context) error {
-	username := c.String("username")
-	output := c.String("output")
+	usernames, err := config.Usernames(c, "lichess")
+	if err != nil {
+		return err
+	}
+	if output.JSON(c) && c.String("output") == "" && !c.Bool("import-db") {
+		return fmt.Errorf("--json needs --output or --import-db, as the games would be written to stdout")
+	}
+	out := output.Messages(c)
+
+	var summaries []downloadSummary
+	var failed []string
+	for _, username := range usernames {
+		outputPath, err := config.OutputForUsername(c.String("output"), username, len(usernames))
+		if err != nil {
+			return err
+		}
+		if len(usernames) > 1 {
+			fmt.Fprintf(out, "\n=== %s ===\n", username)
+		}
+		summary, err := downloadUserGames(c, username, outputPath)
+		if err != nil && len(usernames) == 1 {
+			return err
+		}
+		if err != nil {
+			// The other accounts are still downloaded
+			fmt.Fprintf(out, "Error downloading the games of %s: %v\n", username, err)
+			failed = append(failed, username)
+			summary = &downloadSummary{Username: username, Error: err.Error()}
+		}
+		summaries = append(summaries, *summary)
+	}
+
+	if output.JSON(c) {
+		var err error
+		if len(summaries) == 1 {
+			err = output.WriteJSON(summaries[0])
+		} else {
+			err = output.WriteJSON(map[string]interface{}{"accounts": summaries})
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to download the games of %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// downloadUserGames downloads the games of one Lichess user, writing them to
+// outputPath or stdout and importing them with --import-db
+func downloadUserGames(c *cli.Context, username, outputPath string) (*downloadSummary, error) {
 	importDB := c.Bool("import-db")
-	dbPath := c.String("database")
+	dbPath, err := config.DatabasePath(c)
+	if err != nil {
+		return nil, err
+	}
 	verbose := c.Bool("verbose")
 	apiToken := c.String("api-token")
 
@@ -49,9 +116,15 @@ func DownloadGames(c *cli.Context) error {
 	perfType := c.String("perf-type")
 	color := c.String("color")
 
+	out := output.Messages(c)
 	client := NewClient()
 
-	// Set API token if provided
+	// Set API token if provided, or configured for the user
+	if apiToken == "" {
+		if cfg, err := config.LoadOrDefault(); err == nil && cfg.Lichess != nil && cfg.Lichess.Username == username {
+			apiToken = cfg.Lichess.APIToken
+		}
+	}
 	if apiToken != "" {
 		client.SetAPIToken(apiToken)
 	}
@@ -66,7 +139,7 @@ func DownloadGames(c *cli.Context) error {
 	if since != "" {
 		sinceTime, err := parseTimeString(since)
 		if err != nil {
-			return fmt.Errorf("failed to parse --since: %w", err)
+			return nil, fmt.Errorf("failed to parse --since: %w", err)
 		}
 		sinceMillis := sinceTime.UnixMilli()
 		params.Since = &sinceMillis
@@ -75,7 +148,7 @@ func DownloadGames(c *cli.Context) error {
 	if until != "" {
 		untilTime, err := parseTimeString(until)
 		if err != nil {
-			return fmt.Errorf("failed to parse --until: %w", err)
+			return nil, fmt.Errorf("failed to parse --until: %w", err)
 		}
 		untilMillis := untilTime.UnixMilli()
 		params.Until = &untilMillis
@@ -106,28 +179,29 @@ func DownloadGames(c *cli.Context) error {
 		params.Color = color
 	}
 
-	fmt.Printf("Fetching games for %s from Lichess...\n", username)
+	fmt.Fprintf(out, "Fetching games for %s from Lichess...\n", username)
 
 	// Get the PGN data
 	pgn, err := client.GetPlayerGamesPGN(c.Context, params)
 	if err != nil {
-		return fmt.Errorf("failed to fetch games: %w", err)
-	}
-
-	if pgn == "" {
-		fmt.Printf("No games found for %s\n", username)
-		return nil
+		return nil, fmt.Errorf("failed to fetch games: %w", err)
 	}
 
 	// Count games (rough estimate based on [Event tags)
 	gameCount := strings.Count(pgn, "[Event ")
+	summary := downloadSummary{Username: username, Games: gameCount, Output: outputPath}
+
+	if pgn == "" {
+		fmt.Fprintf(out, "No games found for %s\n", username)
+		return &summary, nil
+	}
 
 	// If we're importing to DB
 	if importDB {
 		// Create a temporary file to store the PGN for import
 		tmpfile, err := os.CreateTemp("", "lichess-*.pgn")
 		if err != nil {
-			return fmt.Errorf("failed to create temporary file: %w", err)
+			return nil, fmt.Errorf("failed to create temporary file: %w", err)
 		}
 		tmpPath := tmpfile.Name()
 		defer func() { _ = os.Remove(tmpPath) }() // Clean up
@@ -135,71 +209,79 @@ func DownloadGames(c *cli.Context) error {
 		// Write PGN to temporary file
 		if _, err := tmpfile.WriteString(pgn); err != nil {
 			_ = tmpfile.Close()
-			return fmt.Errorf("failed to write to temporary file: %w", err)
+			return nil, fmt.Errorf("failed to write to temporary file: %w", err)
 		}
 		_ = tmpfile.Close()
 
 		// Open database
-		fmt.Printf("Opening database at %s...\n", dbPath)
+		fmt.Fprintf(out, "Opening database at %s...\n", dbPath)
 		database, err := db.New(dbPath)
 		if err != nil {
-			return fmt.Errorf("failed to open database: %w", err)
+			return nil, fmt.Errorf("failed to open database: %w", err)
 		}
 		defer func() { _ = database.Close() }()
 
 		// Import the PGN file
-		count, errors := database.ImportPGN(c.Context, tmpPath)
+		bar := progress.ForOutput(out, gameCount, "games")
+		result := database.ImportPGNWithOptions(c.Context, tmpPath, db.ImportOptions{
+			Progress: bar.Set,
+			Tags:     db.SourceAccountTags("lichess", username),
+		})
+		count, errors := result.Imported, result.Errors
+		bar.Finish()
 
 		// Print import results
 		if len(errors) > 0 && verbose {
-			fmt.Printf("Encountered %d errors during import:\n", len(errors))
+			fmt.Fprintf(out, "Encountered %d errors during import:\n", len(errors))
 			for i, err := range errors {
-				fmt.Printf("  Error %d: %s\n", i+1, err)
+				fmt.Fprintf(out, "  Error %d: %s\n", i+1, err)
 				if pgnErr, ok := err.(*db.PGNImportError); ok && pgnErr.PGNText != "" {
-					fmt.Printf("    PGN: %s\n", pgnErr.PGNText)
+					fmt.Fprintf(out, "    PGN: %s\n", pgnErr.PGNText)
 				}
 			}
 		} else if len(errors) > 0 {
-			fmt.Printf("Encountered %d errors during import. Use --verbose to see details.\n", len(errors))
+			fmt.Fprintf(out, "Encountered %d errors during import. Use --verbose to see details.\n", len(errors))
 		}
 
-		fmt.Printf("Successfully imported %d games from Lichess\n", count)
+		fmt.Fprintf(out, "Successfully imported %d games from Lichess\n", count)
+		summary.Imported = count
 
 		// Get current game count in database
 		currentCount, err := database.GetGameCount(c.Context)
 		if err == nil {
-			fmt.Printf("Total games in database: %d\n", currentCount)
+			fmt.Fprintf(out, "Total games in database: %d\n", currentCount)
+			summary.TotalGames = currentCount
 		}
 
 		// If the user also wants to output to a file, do that too
-		if output != "" {
-			fmt.Println("\nAdditionally saving PGN to file...")
-			outputFile, err := os.Create(output)
+		if outputPath != "" {
+			fmt.Fprintln(out, "\nAdditionally saving PGN to file...")
+			outputFile, err := os.Create(outputPath)
 			if err != nil {
-				return fmt.Errorf("failed to create output file: %w", err)
+				return nil, fmt.Errorf("failed to create output file: %w", err)
 			}
 			defer func() { _ = outputFile.Close() }()
 
 			_, err = outputFile.WriteString(pgn)
 			if err != nil {
-				return fmt.Errorf("failed to write to output file: %w", err)
+				return nil, fmt.Errorf("failed to write to output file: %w", err)
 			}
 
-			fmt.Printf("Saved PGN to %s\n", output)
+			fmt.Fprintf(out, "Saved PGN to %s\n", outputPath)
 		}
 
-		return nil
+		return &summary, nil
 	}
 
 	// Handle output to file or stdout
 	var outputWriter *os.File
-	if output == "" {
+	if outputPath == "" {
 		outputWriter = os.Stdout
 	} else {
 		var err error
-		outputWriter, err = os.Create(output)
+		outputWriter, err = os.Create(outputPath)
 		if err != nil {
-			return fmt.Errorf("failed to create output file: %w", err)
+			return nil, fmt.Errorf("failed to create output file: %w", err)
 		}
 		defer func() { _ = outputWriter.Close() }()
 	}
@@ -207,13 +289,13 @@ func DownloadGames(c *cli.Context) error {
 	// Write PGN to output
 	_, _ = fmt.Fprintln(outputWriter, pgn)
 
-	if output != "" {
-		fmt.Printf("Downloaded %d games for %s to %s\n", gameCount, username, output)
+	if outputPath != "" {
+		fmt.Fprintf(out, "Downloaded %d games for %s to %s\n", gameCount, username, outputPath)
 	} else {
-		fmt.Printf("Downloaded %d games for %s\n", gameCount, username)
+		fmt.Fprintf(out, "Downloaded %d games for %s\n", gameCount, username)
 	}
 
-	return nil
+	return &summary, nil
 }
 
 // parseTimeString parses various time string formats into time.Time
@@ -235,18 +317,14 @@ func parseTimeString(timeStr string) (time.Time, error) {
 	return time.Time{}, fmt.Errorf("unable to parse time string %q (supported formats: YYYY-MM-DD, YYYY-MM, YYYY)", timeStr)
 }
 
-// ImportFromConfig imports games using configuration settings
-// If lastImport is non-zero, only games since that time are imported
-func ImportFromConfig(ctx context.Context, cfg *config.Config, database *db.DB, logger *slog.Logger, verbose bool) (int, error) {
-	if cfg.Lichess == nil || cfg.Lichess.Username == "" {
-		return 0, fmt.Errorf("no Lichess user configured")
-	}
-
-	username := cfg.Lichess.Username
+// ImportFromConfig imports the games of username, one of the accounts
+// configured in cfg. If it was imported before, only games since the last
+// import are imported
+func ImportFromConfig(ctx context.Context, cfg *config.Config, username string, database *db.DB, logger *slog.Logger, out io.Writer, verbose bool) (int, error) {
 	client := NewClientWithLogger(logger)
 
-	// Set API token if available
-	if cfg.Lichess.APIToken != "" {
+	// The API token is that of the main account
+	if cfg.Lichess != nil && cfg.Lichess.APIToken != "" && strings.EqualFold(cfg.Lichess.Username, username) {
 		client.SetAPIToken(cfg.Lichess.APIToken)
 	}
 
@@ -260,9 +338,9 @@ func ImportFromConfig(ctx context.Context, cfg *config.Config, database *db.DB,
 		sinceTime := lastImport.Add(1 * time.Second)
 		sinceMillis := sinceTime.UnixMilli()
 		params.Since = &sinceMillis
-		fmt.Printf("Fetching Lichess games for %s since %s...\n", username, lastImport.Format("2006-01-02 15:04:05"))
+		fmt.Fprintf(out, "Fetching Lichess games for %s since %s...\n", username, lastImport.Format("2006-01-02 15:04:05"))
 	} else {
-		fmt.Printf("Fetching all Lichess games for %s...\n", username)
+		fmt.Fprintf(out, "Fetching all Lichess games for %s...\n", username)
 	}
 
 	// Get the PGN data
@@ -272,7 +350,7 @@ func ImportFromConfig(ctx context.Context, cfg *config.Config, database *db.DB,
 	}
 
 	if pgn == "" {
-		fmt.Printf("No new games found for %s on Lichess\n", username)
+		fmt.Fprintf(out, "No new games found for %s on Lichess\n", username)
 		return 0, nil
 	}
 
@@ -292,23 +370,29 @@ func ImportFromConfig(ctx context.Context, cfg *config.Config, database *db.DB,
 	_ = tmpfile.Close()
 
 	// Import the PGN file
-	count, errors := database.ImportPGN(ctx, tmpPath)
+	bar := progress.ForOutput(out, 0, "games")
+	result := database.ImportPGNWithOptions(ctx, tmpPath, db.ImportOptions{
+		Progress: bar.Set,
+		Tags:     db.SourceAccountTags("lichess", username),
+	})
+	count, errors := result.Imported, result.Errors
+	bar.Finish()
 
 	// Print import results
 	if len(errors) > 0 && verbose {
-		fmt.Printf("Encountered %d errors during Lichess import:\n", len(errors))
+		fmt.Fprintf(out, "Encountered %d errors during Lichess import:\n", len(errors))
 		for i, err := range errors {
-			fmt.Printf("  Error %d: %s\n", i+1, err)
+			fmt.Fprintf(out, "  Error %d: %s\n", i+1, err)
 			if pgnErr, ok := err.(*db.PGNImportError); ok && pgnErr.PGNText != "" {
-				fmt.Printf("    PGN: %s\n", pgnErr.PGNText)
+				fmt.Fprintf(out, "    PGN: %s\n", pgnErr.PGNText)
 			}
 		}
 	} else if len(errors) > 0 {
-		fmt.Printf("Encountered %d errors during Lichess import. Use --verbose to see details.\n", len(errors))
+		fmt.Fprintf(out, "Encountered %d errors during Lichess import. Use --verbose to see details.\n", len(errors))
 	}
 
 	if count > 0 {
-		fmt.Printf("Successfully imported %d games from Lichess\n", count)
+		fmt.Fprintf(out, "Successfully imported %d games from Lichess\n", count)
 		// Update last import time
 		cfg.SetLastImport("lichess", username, time.Now())
 		if err := cfg.SaveDefault(); err != nil {
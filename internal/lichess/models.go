@@ -68,3 +68,109 @@ func DefaultGamesParams(username string) GamesParams {
 		Sort:     "dateDesc",
 	}
 }
+
+// ExplorerResult is a position in the Lichess opening explorer: the results
+// of the games that reached it and the moves played from it.
+type ExplorerResult struct {
+	White int            `json:"white"` // games won by White
+	Draws int            `json:"draws"`
+	Black int            `json:"black"` // games won by Black
+	Moves []ExplorerMove `json:"moves"`
+}
+
+// ExplorerMove is a move played from an explorer position, with the results
+// of the games in which it was played.
+type ExplorerMove struct {
+	UCI           string `json:"uci"`
+	SAN           string `json:"san"`
+	AverageRating int    `json:"averageRating"`
+	White         int    `json:"white"`
+	Draws         int    `json:"draws"`
+	Black         int    `json:"black"`
+}
+
+// Games returns the number of games in which the move was played.
+func (m ExplorerMove) Games() int {
+	return m.White + m.Draws + m.Black
+}
+
+// BroadcastPushResult is the answer of Lichess to PGN pushed to a broadcast
+// round: the games it read from it.
+type BroadcastPushResult struct {
+	Games []BroadcastGame `json:"games"`
+}
+
+// BroadcastGame is a game read from PGN pushed to a broadcast round, with
+// the number of moves it has or why it was rejected.
+type BroadcastGame struct {
+	Tags  map[string]string `json:"tags"`
+	Moves int               `json:"moves"`
+	Error string            `json:"error,omitempty"`
+}
+
+// Account is the Lichess account an API token belongs to.
+type Account struct {
+	ID       string `json:"id"` // lowercase username
+	Username string `json:"username"`
+	Title    string `json:"title,omitempty"` // "BOT" for bot accounts
+}
+
+// BotEvent is an event of the stream of incoming events of a bot account:
+// a challenge received, or a game started or finished.
+type BotEvent struct {
+	Type      string     `json:"type"` // "challenge", "gameStart", "gameFinish", "challengeCanceled", ...
+	Challenge *Challenge `json:"challenge,omitempty"`
+	Game      *EventGame `json:"game,omitempty"`
+}
+
+// Challenge is a challenge sent to a bot account.
+type Challenge struct {
+	ID         string `json:"id"`
+	Rated      bool   `json:"rated"`
+	Speed      string `json:"speed"` // "bullet", "blitz", ..., "correspondence"
+	Challenger struct {
+		Name   string `json:"name"`
+		Rating int    `json:"rating"`
+	} `json:"challenger"`
+	Variant struct {
+		Key string `json:"key"` // "standard", "chess960", ...
+	} `json:"variant"`
+	TimeControl struct {
+		Type      string `json:"type"` // "clock", "correspondence" or "unlimited"
+		Limit     int    `json:"limit"`
+		Increment int    `json:"increment"`
+	} `json:"timeControl"`
+}
+
+// EventGame is the game a gameStart or gameFinish event is about.
+type EventGame struct {
+	GameID string `json:"gameId"`
+}
+
+// BotGameEvent is an event of the stream of a game played by a bot: the
+// full game when the stream opens, then its state after each move.
+type BotGameEvent struct {
+	Type       string `json:"type"` // "gameFull", "gameState", "chatLine" or "opponentGone"
+	ID         string `json:"id,omitempty"`
+	InitialFen string `json:"initialFen,omitempty"` // "startpos" for the standard position
+	White      struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"white"`
+	Black struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"black"`
+	State *BotGameState `json:"state,omitempty"` // of gameFull events
+	BotGameState
+}
+
+// BotGameState is the state of a game played by a bot.
+type BotGameState struct {
+	Moves  string `json:"moves"` // every move played, in UCI, separated by spaces
+	WTime  int    `json:"wtime"` // milliseconds left on the clocks
+	BTime  int    `json:"btime"`
+	WInc   int    `json:"winc"`
+	BInc   int    `json:"binc"`
+	Status string `json:"status"` // "started" while being played, else how it ended
+}
@@ -1,9 +1,12 @@
 package logging
 
 import (
+	"fmt"
 	"io"
 	"log/slog"
 	"os"
+	"strings"
+	"sync"
 )
 
 // Level represents the logging level
@@ -16,6 +19,16 @@ const (
 	LevelError Level = "error"
 )
 
+// ParseLevel parses a level name such as "debug" or "WARN"
+func ParseLevel(s string) (Level, error) {
+	level := Level(strings.ToLower(strings.TrimSpace(s)))
+	switch level {
+	case LevelDebug, LevelInfo, LevelWarn, LevelError:
+		return level, nil
+	}
+	return "", fmt.Errorf("invalid log level %q (use debug, info, warn or error)", s)
+}
+
 // Format represents the log output format
 type Format string
 
@@ -73,8 +86,37 @@ func New(cfg Config) *slog.Logger {
 	return slog.New(handler)
 }
 
-// Default returns a logger with default configuration
+// ParseFormat parses a format name, "text" or "json"
+func ParseFormat(s string) (Format, error) {
+	format := Format(strings.ToLower(strings.TrimSpace(s)))
+	switch format {
+	case FormatText, FormatJSON:
+		return format, nil
+	}
+	return "", fmt.Errorf("invalid log format %q (use text or json)", s)
+}
+
+var (
+	defaultMu     sync.RWMutex
+	defaultLogger *slog.Logger
+)
+
+// SetDefault makes logger the one returned by Default, so that packages
+// creating their own logger follow the command line settings
+func SetDefault(logger *slog.Logger) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultLogger = logger
+}
+
+// Default returns the logger set with SetDefault, or a logger with default
+// configuration
 func Default() *slog.Logger {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	if defaultLogger != nil {
+		return defaultLogger
+	}
 	return New(DefaultConfig())
 }
 
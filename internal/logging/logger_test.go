@@ -156,3 +156,37 @@ func TestDiscard(t *testing.T) {
 	logger.Debug("debug message")
 	logger.Error("error message")
 }
+
+func TestParseLevel(t *testing.T) {
+	for _, s := range []string{"debug", "INFO", " warn ", "error"} {
+		if _, err := ParseLevel(s); err != nil {
+			t.Errorf("ParseLevel(%q) failed: %v", s, err)
+		}
+	}
+	if level, _ := ParseLevel("Debug"); level != LevelDebug {
+		t.Errorf("ParseLevel(\"Debug\") = %q, want %q", level, LevelDebug)
+	}
+	if _, err := ParseLevel("verbose"); err == nil {
+		t.Error("ParseLevel(\"verbose\") should fail")
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	if format, err := ParseFormat("JSON"); err != nil || format != FormatJSON {
+		t.Errorf("ParseFormat(\"JSON\") = %q, %v", format, err)
+	}
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Error("ParseFormat(\"xml\") should fail")
+	}
+}
+
+func TestSetDefault(t *testing.T) {
+	buf := &bytes.Buffer{}
+	SetDefault(New(Config{Level: LevelDebug, Format: FormatText, Output: buf}))
+	defer SetDefault(nil)
+
+	Default().Debug("routed message")
+	if !strings.Contains(buf.String(), "routed message") {
+		t.Errorf("Default() should use the logger set with SetDefault, got: %q", buf.String())
+	}
+}
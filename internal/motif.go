@@ -0,0 +1,220 @@
+package internal
+
+// The tactical motifs returned by Board.Motifs.
+const (
+	ForkMotif             = "fork"              // a piece attacks two or more enemy pieces at once
+	PinMotif              = "pin"               // a piece cannot move without exposing a more valuable one behind it
+	SkewerMotif           = "skewer"            // a valuable piece must move, exposing a piece behind it
+	BackRankMotif         = "back-rank"         // mate by a rook or queen on the back rank
+	DiscoveredAttackMotif = "discovered-attack" // a move uncovers an attack by a piece behind it
+	SmotheredMateMotif    = "smothered-mate"    // mate by a knight against a king hemmed in by its own pieces
+)
+
+// MotifTypes are the tactical motifs, in the order Board.Motifs returns them.
+var MotifTypes = []string{
+	ForkMotif, PinMotif, SkewerMotif, BackRankMotif, DiscoveredAttackMotif, SmotheredMateMotif,
+}
+
+// Motifs returns the tactical motifs created by playing move m, which must be
+// legal: the forks, pins and skewers of the moved piece, the attacks it
+// discovers and the patterns of the mate it gives. It returns nil if m
+// creates none.
+func (b *Board) Motifs(m Move) []string {
+	after := b.MakeMove(m)
+	mover := b.SideToMove
+	found := make(map[string]bool)
+
+	// castling moves the king and rook, neither of which is "the moved piece"
+	if to := m.To; after.Piece[to] != NoPiece && after.Piece[to].Color() == mover {
+		found[ForkMotif] = isFork(&after.Piece, to)
+		found[PinMotif], found[SkewerMotif] = pinsAndSkewers(&after.Piece, to)
+	}
+	found[DiscoveredAttackMotif] = discoversAttack(&after.Piece, m, mover)
+
+	if check, mate := after.IsCheckOrMate(); check && mate {
+		king := after.find(after.my(King), A1, H8)
+		if checkers := after.Checkers(); len(checkers) == 1 {
+			found[BackRankMotif] = isBackRankMate(&after.Piece, king, checkers[0])
+			found[SmotheredMateMotif] = isSmotheredMate(&after.Piece, king, checkers[0])
+		}
+	}
+
+	var motifs []string
+	for _, motif := range MotifTypes {
+		if found[motif] {
+			motifs = append(motifs, motif)
+		}
+	}
+	return motifs
+}
+
+// isFork reports whether the piece on sq attacks two or more enemy pieces
+// worth winning, from a square where it cannot be taken for less than it is
+// worth. Pieces worth winning are the king, pieces more valuable than the
+// attacker and undefended pieces other than pawns.
+func isFork(pieces *[64]Piece, sq Sq) bool {
+	attacker := pieces[sq]
+	color := attacker.Color()
+	if !safeSquare(pieces, sq, color) {
+		return false
+	}
+	targets := 0
+	for _, target := range attackedSquares(pieces, sq) {
+		p := pieces[target]
+		if p == NoPiece || p.Color() == color || p.Type() == Pawn {
+			continue
+		}
+		if p.Type() == King || PieceValue(p.Type()) > PieceValue(attacker.Type()) ||
+			len(attackers(pieces, target, color^1)) == 0 {
+			targets++
+		}
+	}
+	return targets >= 2
+}
+
+// safeSquare reports whether the piece of color on sq can stay there: it is
+// not attacked, or it is defended and every attacker is worth at least as
+// much as it is.
+func safeSquare(pieces *[64]Piece, sq Sq, color int) bool {
+	lva := leastValuableAttacker(pieces, sq, color^1)
+	if lva == NoSquare {
+		return true
+	}
+	defended := len(attackers(pieces, sq, color)) > 0
+	return defended && PieceValue(pieces[lva].Type()) >= PieceValue(pieces[sq].Type())
+}
+
+// pinsAndSkewers reports whether the slider on sq pins or skewers an enemy
+// piece: it attacks an enemy piece with another behind it on the same line.
+// It is a pin when the piece behind is more valuable, such as the king, and a
+// skewer when the piece in front is the king or the more valuable one.
+func pinsAndSkewers(pieces *[64]Piece, sq Sq) (pin, skewer bool) {
+	color := pieces[sq].Color()
+	for _, offset := range sliderOffsets(pieces[sq].Type()) {
+		front := firstPiece(pieces, sq, offset)
+		if front == NoSquare || pieces[front].Color() == color {
+			continue
+		}
+		back := firstPiece(pieces, front, offset)
+		if back == NoSquare || pieces[back].Color() == color {
+			continue
+		}
+		frontValue, backValue := PieceValue(pieces[front].Type()), PieceValue(pieces[back].Type())
+		switch {
+		case frontValue < backValue:
+			pin = true
+		case frontValue > backValue && pieces[back].Type() != Pawn:
+			skewer = true
+		}
+	}
+	return
+}
+
+// discoversAttack reports whether move m, already made, vacates a square
+// that uncovers an attack by another slider of color on the enemy king, on
+// a piece more valuable than the slider or on an undefended piece other
+// than a pawn.
+func discoversAttack(pieces *[64]Piece, m Move, color int) bool {
+	from := m.From
+	for _, offset := range kingOffsets {
+		slider := firstPiece(pieces, from, -offset)
+		if slider == NoSquare || slider == m.To || pieces[slider].Color() != color {
+			continue
+		}
+		// the slider must move along the line through from
+		moves := false
+		for _, o := range sliderOffsets(pieces[slider].Type()) {
+			moves = moves || o == offset
+		}
+		target := firstPiece(pieces, from, offset)
+		if !moves || target == NoSquare || pieces[target].Color() == color {
+			continue
+		}
+		p := pieces[target]
+		if p.Type() == King || PieceValue(p.Type()) > PieceValue(pieces[slider].Type()) ||
+			p.Type() != Pawn && len(attackers(pieces, target, color^1)) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// isBackRankMate reports whether the mated king on sq is mated on its back
+// rank by a rook or queen along the rank, walled in by its own pieces.
+func isBackRankMate(pieces *[64]Piece, king, checker Sq) bool {
+	color := pieces[king].Color()
+	if king.RelativeRank(color) != Rank1 || checker.Rank() != king.Rank() {
+		return false
+	}
+	if t := pieces[checker].Type(); t != Rook && t != Queen {
+		return false
+	}
+	up := king.Up(color)
+	for _, sq := range []Sq{up.Left(color), up, up.Right(color)} {
+		if sq != NoSquare && pieces[sq] != NoPiece && pieces[sq].Color() == color {
+			return true
+		}
+	}
+	return false
+}
+
+// isSmotheredMate reports whether the mated king on sq is mated by a knight
+// with every square around it taken by its own pieces.
+func isSmotheredMate(pieces *[64]Piece, king, checker Sq) bool {
+	if pieces[checker].Type() != Knight {
+		return false
+	}
+	color := pieces[king].Color()
+	for _, offset := range kingOffsets {
+		sq := king.step(offset)
+		if sq != NoSquare && (pieces[sq] == NoPiece || pieces[sq].Color() != color) {
+			return false
+		}
+	}
+	return true
+}
+
+// attackedSquares returns the squares attacked by the piece on sq.
+func attackedSquares(pieces *[64]Piece, sq Sq) []Sq {
+	p := pieces[sq]
+	var squares []Sq
+	add := func(offsets []int) {
+		for _, offset := range offsets {
+			if to := sq.step(offset); to != NoSquare {
+				squares = append(squares, to)
+			}
+		}
+	}
+	switch p.Type() {
+	case Pawn:
+		add([2][]int{{7, 9}, {-7, -9}}[p.Color()])
+	case Knight:
+		add(knightOffsets)
+	case King:
+		add(kingOffsets)
+	default:
+		for _, offset := range sliderOffsets(p.Type()) {
+			for to := sq.step(offset); to != NoSquare; to = to.step(offset) {
+				squares = append(squares, to)
+				if pieces[to] != NoPiece {
+					break
+				}
+			}
+		}
+	}
+	return squares
+}
+
+// sliderOffsets returns the directions a piece of the given type slides in,
+// none for pieces that do not slide.
+func sliderOffsets(pieceType int) []int {
+	switch pieceType {
+	case Bishop:
+		return diagonalOffsets
+	case Rook:
+		return straightOffsets
+	case Queen:
+		return kingOffsets
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMotifs(t *testing.T) {
+	tests := []struct {
+		name, fen, move string
+		want            []string
+	}{
+		{"quiet move", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", "e4", nil},
+		{"knight fork", "r3k3/8/8/3N4/8/8/8/4K3 w - - 0 1", "Nc7+", []string{ForkMotif}},
+		{"fork of a defended piece worth less", "r3k3/1p6/8/3N4/8/8/8/4K3 w - - 0 1", "Nc7+", []string{ForkMotif}},
+		{"forking piece can be taken", "r2bk3/8/8/3N4/8/8/8/4K3 w - - 0 1", "Nc7+", nil},
+		{"pin", "4k3/8/2n5/8/8/8/8/4KB2 w - - 0 1", "Bb5", []string{PinMotif}},
+		{"skewer", "q3k3/8/8/8/8/8/8/1K5R w - - 0 1", "Rh8+", []string{SkewerMotif}},
+		{"discovered check", "4k3/8/8/8/8/8/4N3/4R1K1 w - - 0 1", "Nc3", []string{DiscoveredAttackMotif}},
+		{"no discovery along the moved piece's line", "3k4/4n3/8/8/4R3/8/8/6K1 w - - 0 1", "Re2", nil},
+		{"back-rank mate", "6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1", "Ra8#", []string{BackRankMotif}},
+		{"smothered mate", "6rk/6pp/8/6N1/8/8/8/6K1 w - - 0 1", "Nf7#", []string{SmotheredMateMotif}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := ParseFen(tt.fen)
+			require.NoError(t, err)
+			m, err := b.ParseMove(tt.move)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, b.Motifs(m))
+		})
+	}
+}
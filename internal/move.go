@@ -1,7 +1,7 @@
 package internal
 
 import (
-	"errors"
+	"fmt"
 	"strings"
 )
 
@@ -24,9 +24,16 @@ func (m Move) isLegal(b *Board) bool {
 // will accept varying forms of algebraic notation, including slightly
 // incorrect notations (for instance with uncapitalized piece characters).
 // Examples: e4, Bb5, cxd3, O-O, 0-0-0, Rae1+, f8=Q, f8/Q, e2-e4, Bf1-b5, e2e4,
-// f1b5, e1g1 (castling), f7f8q.
+// f1b5, e1g1 (castling), f7f8q. Trailing check, mate and annotation marks
+// (+, #, !, ?) are ignored, and "--" and "Z0" are null moves.
+//
+// A move that cannot be played is reported with an *AmbiguousMoveError,
+// *IllegalMoveError or *UnknownPieceError explaining why, other text with
+// ErrInvalidMove.
 func (b *Board) ParseMove(s string) (Move, error) {
-	if s == "--" {
+	given := s
+	s = strings.TrimRight(s, "+#!?")
+	if s == "--" || s == "Z0" {
 		return NullMove, nil
 	}
 	var (
@@ -35,11 +42,10 @@ func (b *Board) ParseMove(s string) (Move, error) {
 		piece     = NoPiece
 		promotion = NoPiece
 		castle    = -1
-		err       = errors.New("invalid move")
 	)
 
 	if len(s) < 2 {
-		return NullMove, err
+		return NullMove, ErrInvalidMove
 	}
 	switch {
 	case strings.HasPrefix(s, "O-O-O") || strings.HasPrefix(s, "0-0-0"):
@@ -51,6 +57,9 @@ func (b *Board) ParseMove(s string) (Move, error) {
 		// piece letters are also accepted. For a 'b' we guess whether
 		// it is 'b'ishop or 'b'-file. "bc3" will be interpreted as
 		// Bc3, but "b3c4" as b3-c4, not B3c4.
+		if c := s[0]; c >= 'A' && c <= 'Z' && c != 'O' && pieceFromChar(rune(c)) == NoPiece {
+			return NullMove, &UnknownPieceError{Move: given, Letter: rune(c)}
+		}
 		if p := pieceFromChar(rune(s[0])); p != NoPiece {
 			if s[0] != 'b' || (len(s) > 2 && s[1] >= 'a' && s[1] <= 'h') {
 				piece = p.Type()
@@ -98,35 +107,102 @@ func (b *Board) ParseMove(s string) (Move, error) {
 	}
 	// Set f0, r0, f1, r1 for a castling move.
 	if castle != -1 {
-		rook, king, _, _, _, _ := b.castleSquares(castle)
-		if rook == NoSquare || king == NoSquare {
-			return NullMove, err
+		if castleErr := b.castlingError(b.SideToMove, castle); castleErr != nil {
+			return NullMove, &IllegalMoveError{Move: given, Reason: "castling not allowed: " + castleErr.Error()}
 		}
+		rook, king, _, _, _, _ := b.castleSquares(castle)
 		f0, r0, f1, r1 = king.File(), king.Rank(), rook.File(), rook.Rank()
 	}
 	// Find the one move matching the parsed files, ranks, piece type and
 	// promotion.
-	move := NullMove
+	var matches, legal []Move
+	promotionMissing := false
 	moves, _ := b.pseudoLegalMoves()
 	for _, m := range moves {
 		if (piece == NoPiece || b.Piece[m.From].Type() == piece) &&
 			(f0 == -1 || f0 == m.From.File()) &&
 			(r0 == -1 || r0 == m.From.Rank()) &&
 			(f1 == -1 || f1 == m.To.File()) &&
-			(r1 == -1 || r1 == m.To.Rank()) &&
-			m.Promotion.Type() == promotion &&
-			m.isLegal(b) {
-			// the move matches
-			if move != NullMove {
-				return NullMove, err // ambiguous move
+			(r1 == -1 || r1 == m.To.Rank()) {
+			if m.Promotion.Type() != promotion {
+				promotionMissing = promotionMissing || promotion == NoPiece
+				continue
+			}
+			matches = append(matches, m)
+			if m.isLegal(b) {
+				legal = append(legal, m)
 			}
-			move = m
 		}
 	}
-	if move == NullMove {
-		return NullMove, err
+	switch {
+	case len(legal) == 1:
+		return legal[0], nil
+	case len(legal) > 1:
+		return NullMove, b.ambiguousMove(given, legal)
+	case len(matches) == 0 && promotionMissing:
+		return NullMove, &IllegalMoveError{Move: given, Reason: "promotion piece missing; add =Q, =R, =B or =N"}
+	}
+	return NullMove, &IllegalMoveError{Move: given, Reason: b.illegalReason(piece, f0, r0, f1, r1, matches)}
+}
+
+// ambiguousMove describes the legal moves matching a move given to ParseMove.
+func (b *Board) ambiguousMove(given string, moves []Move) *AmbiguousMoveError {
+	e := &AmbiguousMoveError{Move: given, Piece: b.Piece[moves[0].From].Type(), To: moves[0].To}
+	for _, m := range moves {
+		if b.Piece[m.From].Type() != e.Piece {
+			e.Piece = NoPiece
+		}
+		if m.To != e.To {
+			e.To = NoSquare
+		}
+		e.Candidates = append(e.Candidates, m.San(b))
 	}
-	return move, nil
+	return e
+}
+
+// illegalReason explains why a move given to ParseMove as piece, from-square
+// and to-square (files and ranks are -1 when not given) cannot be played.
+// matches are the pseudo-legal moves it matches, none of which are legal.
+func (b *Board) illegalReason(piece, f0, r0, f1, r1 int, matches []Move) string {
+	if len(matches) == 0 {
+		to := "there"
+		if f1 != -1 && r1 != -1 {
+			to = Square(f1, r1).String()
+		}
+		if f0 != -1 && r0 != -1 {
+			from := Square(f0, r0)
+			p := b.Piece[from]
+			if p == NoPiece || p.Color() != b.SideToMove {
+				return fmt.Sprintf("there is no %s piece on %s", [2]string{"white", "black"}[b.SideToMove], from)
+			}
+			return fmt.Sprintf("the %s on %s cannot reach %s", pieceNames[p.Type()], from, to)
+		}
+		return fmt.Sprintf("no %s can reach %s", pieceNames[piece], to)
+	}
+
+	m := matches[0]
+	switch {
+	case b.Piece[m.From].Type() == King && b.Piece[m.To] != b.my(Rook):
+		return fmt.Sprintf("the king would be in check on %s", m.To)
+	case len(b.Checkers()) > 0:
+		return "the king is in check"
+	case len(matches) == 1 && b.pinned()[m.From]:
+		return fmt.Sprintf("the %s on %s is pinned", pieceNames[b.Piece[m.From].Type()], m.From)
+	}
+	return "the move would leave the king in check"
+}
+
+// suffixNags are the NAGs of the annotations that may follow a move.
+var suffixNags = map[string]int{"!": 1, "?": 2, "!!": 3, "??": 4, "!?": 5, "?!": 6}
+
+// ParseAnnotatedMove is like ParseMove, and also returns the NAG of an
+// annotation following the move, as in Nf3!? or Qxf7+?: 1 for "!", 2 for "?",
+// 3 for "!!", 4 for "??", 5 for "!?" and 6 for "?!". The NAG is 0 if there
+// is no annotation.
+func (b *Board) ParseAnnotatedMove(s string) (Move, int, error) {
+	move := strings.TrimRight(s, "!?")
+	m, err := b.ParseMove(s)
+	return m, suffixNags[s[len(move):]], err
 }
 
 // Uci returns the move in Universal Chess Interface notation (b1c3, f7f8q).
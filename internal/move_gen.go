@@ -1,6 +1,10 @@
 package internal
 
-import "sort"
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
 
 type movegen struct {
 	*Board
@@ -8,12 +12,31 @@ type movegen struct {
 }
 
 // LegalMoves returns the list of moves that can be played in this position.
+// In a double check only king moves are generated. Pieces pinned to their
+// king are only allowed moves along the pin.
 func (b *Board) LegalMoves() []Move {
 	moves, _ := b.pseudoLegalMoves()
+	checkers := len(b.Checkers())
+	pins := b.pinned()
 	j := 0
 	for i := 0; i < len(moves); i++ {
-		if moves[i].isLegal(b) {
-			moves[j] = moves[i]
+		m := moves[i]
+		piece := b.Piece[m.From]
+		var legal bool
+		switch {
+		case checkers >= 2 && piece.Type() != King:
+			// a double check cannot be blocked or captured away
+			legal = false
+		case checkers == 0 && piece.Type() != King && !pins[m.From] &&
+			!(piece.Type() == Pawn && m.To == b.EpSquare):
+			// nothing can expose the king: an en passant capture
+			// is checked as it removes two pieces from a rank
+			legal = true
+		default:
+			legal = m.isLegal(b)
+		}
+		if legal {
+			moves[j] = m
 			j++
 		}
 	}
@@ -125,8 +148,15 @@ func (gen *movegen) pawnPush(from, to Sq) bool {
 	return false
 }
 
+// pawnCapture adds a pawn capture. The empty squares the opponent's castling
+// king moved through count as occupied, so that castling out of or through a
+// pawn's attack is detected as illegal.
 func (gen *movegen) pawnCapture(from, to Sq) {
-	if to != NoSquare && (gen.Piece[to] != NoPiece || to == gen.EpSquare) {
+	if to == NoSquare {
+		return
+	}
+	castled := gen.checkFrom != gen.checkTo && to >= gen.checkFrom && to <= gen.checkTo
+	if gen.Piece[to] != NoPiece || to == gen.EpSquare || castled {
 		gen.addPawnMove(from, to)
 	}
 }
@@ -229,6 +259,65 @@ func (b *Board) canCastle(wing int) bool {
 	return true
 }
 
+// Castling sides, for CanCastle.
+const (
+	QueenSide = queenSide
+	KingSide  = kingSide
+)
+
+// CanCastle returns whether color can castle on side (KingSide or
+// QueenSide) in this position: it has the right to, the squares between
+// the king and the rook are empty, and the king is not in check and does
+// not pass through or land on an attacked square. It does not check whose
+// move it is.
+func (b *Board) CanCastle(color, side int) bool {
+	return b.castlingError(color, side) == nil
+}
+
+// castlingError returns why color cannot castle on side, or nil if it can.
+func (b *Board) castlingError(color, side int) error {
+	rf := b.CastleSq[color|side]
+	if rf == NoSquare {
+		return errors.New("no castling rights")
+	}
+	kf := b.find(Piece(color|King), A1, H8)
+	if kf == NoSquare {
+		return errors.New("no king")
+	}
+	rt := []Sq{D1, D8, F1, F8}[color|side]
+	kt := []Sq{C1, C8, G1, G8}[color|side]
+
+	// Every square the king and rook cross or land on must be empty
+	lo, hi := kf, kf
+	for _, sq := range []Sq{rf, kt, rt} {
+		lo, hi = min(lo, sq), max(hi, sq)
+	}
+	for sq := lo; sq <= hi; sq++ {
+		if b.Piece[sq] != NoPiece && sq != kf && sq != rf {
+			return fmt.Errorf("%s occupied", sq)
+		}
+	}
+
+	// The king must not be in check or cross or land on an attacked square.
+	// The castling pieces are lifted, so that they do not block attacks.
+	pieces := b.Piece
+	pieces[kf], pieces[rf] = NoPiece, NoPiece
+	if len(attackers(&b.Piece, kf, color^1)) > 0 {
+		return errors.New("king in check")
+	}
+	step := Sq(1)
+	if kt < kf {
+		step = -1
+	}
+	for sq := kf; sq != kt; {
+		sq += step
+		if len(attackers(&pieces, sq, color^1)) > 0 {
+			return fmt.Errorf("%s attacked", sq)
+		}
+	}
+	return nil
+}
+
 // IsCheckOrMate returns whether the side to move is in check and/or has been
 // mated. Mate without check means stalemate.
 func (b *Board) IsCheckOrMate() (check, mate bool) {
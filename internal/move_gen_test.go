@@ -0,0 +1,68 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanCastle(t *testing.T) {
+	tests := []struct {
+		name  string
+		fen   string
+		color int
+		side  int
+		err   string // empty if castling is allowed
+	}{
+		{"allowed", "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1", White, KingSide, ""},
+		{"allowed queenside", "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1", White, QueenSide, ""},
+		{"black, not to move", "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1", Black, KingSide, ""},
+		{"no rights", "r3k2r/8/8/8/8/8/8/R3K2R w Qkq - 0 1", White, KingSide, "no castling rights"},
+		{"blocked", "rn2k2r/8/8/8/8/8/8/R3KB1R b KQkq - 0 1", White, KingSide, "f1 occupied"},
+		{"blocked next to the rook", "r3k2r/8/8/8/8/8/8/RN2K2R w KQkq - 0 1", White, QueenSide, "b1 occupied"},
+		{"in check", "r3k2r/8/8/8/8/8/4r3/R3K2R w KQ - 0 1", White, KingSide, "king in check"},
+		{"crossing an attacked square", "r3k2r/8/8/8/8/8/5r2/R3K2R w KQ - 0 1", White, KingSide, "f1 attacked"},
+		{"landing on an attacked square", "r3k2r/8/8/8/8/8/6r1/R3K2R w KQ - 0 1", White, KingSide, "g1 attacked"},
+		// b1 is attacked, but only the rook crosses it
+		{"rook crossing an attacked square", "r3k2r/8/8/8/8/8/1r6/R3K2R w KQ - 0 1", White, QueenSide, ""},
+		{"black to move", "r3k2r/8/8/8/8/8/8/R3K2R b KQkq - 0 1", Black, KingSide, ""},
+		{"attacked by a pawn", "r3k2r/4P3/8/8/8/8/8/R3K2R b KQkq - 0 1", Black, KingSide, "f8 attacked"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := ParseFen(tt.fen)
+			require.NoError(t, err)
+			assert.Equal(t, tt.err == "", b.CanCastle(tt.color, tt.side))
+			if err := b.castlingError(tt.color, tt.side); tt.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.err)
+			}
+
+			// CanCastle agrees with the move generator for the side to move
+			if tt.color == b.SideToMove {
+				castles := false
+				for _, m := range b.LegalMoves() {
+					if m.To == b.CastleSq[tt.color|tt.side] && b.Piece[m.From] == Piece(tt.color|King) {
+						castles = true
+					}
+				}
+				assert.Equal(t, castles, b.CanCastle(tt.color, tt.side))
+			}
+		})
+	}
+}
+
+func TestParseMoveCastlingError(t *testing.T) {
+	b, err := ParseFen("r3k2r/8/8/8/8/8/5r2/R3K2R w KQ - 0 1")
+	require.NoError(t, err)
+	_, err = b.ParseMove("O-O")
+	assert.EqualError(t, err, "castling not allowed: f1 attacked")
+	_, err = b.ParseMove("e1g1")
+	assert.EqualError(t, err, "castling not allowed: f1 attacked")
+
+	m, err := b.ParseMove("O-O-O")
+	require.NoError(t, err)
+	assert.Equal(t, "O-O-O", m.San(b))
+}
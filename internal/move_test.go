@@ -1,6 +1,7 @@
 package internal
 
 import (
+	"errors"
 	"testing"
 )
 
@@ -55,3 +56,137 @@ func TestAlgebraicNotation(t *testing.T) {
 		t.Errorf("Expected SAN notation to be 'e4', got '%s'", san)
 	}
 }
+
+// A king in check from a pawn cannot castle out of it
+func TestCastlingOutOfPawnCheck(t *testing.T) {
+	tests := []struct {
+		name   string
+		fen    string
+		castle string
+	}{
+		// The d7 pawn gives check
+		{"Kingside", "r3k2r/p1pPqpb1/1n3np1/1b2N3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R b KQkq - 0 2", "O-O"},
+		// The f7 pawn gives check
+		{"Queenside", "r3k2r/p1ppqPb1/1n3np1/1b2N3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R b KQkq - 0 2", "O-O-O"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board, err := ParseFen(tt.fen)
+			if err != nil {
+				t.Fatalf("Failed to parse FEN: %v", err)
+			}
+			for _, move := range board.LegalMoves() {
+				if san := move.San(board); san == tt.castle || san == tt.castle+"+" {
+					t.Errorf("%s should be illegal", tt.castle)
+				}
+			}
+		})
+	}
+}
+
+func TestParseMoveErrors(t *testing.T) {
+	const start = "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
+	tests := []struct {
+		name string
+		fen  string
+		move string
+		err  string
+	}{
+		{"ambiguous knights", "4k3/8/8/8/8/5N2/8/1N2K3 w - - 0 1", "Nd2",
+			"both knights can reach d2; specify Nbd2 or Nfd2"},
+		{"ambiguous queens", "1k6/8/8/8/Q6Q/8/8/4K2Q w - - 0 1", "Qe4",
+			"3 queens can reach e4; specify Q1e4, Qae4 or Qh4e4"},
+		{"unknown piece", start, "Ze4", "unknown piece 'Z' in Ze4; use K, Q, R, B or N"},
+		{"no piece can reach", start, "Nd4", "no knight can reach d4"},
+		{"piece cannot reach", start, "e2e5", "the pawn on e2 cannot reach e5"},
+		{"empty from-square", start, "e3e4", "there is no white piece on e3"},
+		{"pinned", "4r2k/8/8/8/1b6/8/3NR3/4K3 w - - 0 1", "Nf3", "the knight on d2 is pinned"},
+		{"king into check", "4k3/8/8/8/8/8/3r4/4K3 w - - 0 1", "Kd1", "the king would be in check on d1"},
+		{"in check", "4k3/8/8/8/8/8/8/r3K2N w - - 0 1", "Ng3", "the king is in check"},
+		{"en passant exposing the king", "8/8/8/8/k2Pp2Q/8/8/3K4 b - d3 0 1", "exd3",
+			"the move would leave the king in check"},
+		{"promotion missing", "4k3/P7/8/8/8/8/8/4K3 w - - 0 1", "a8",
+			"promotion piece missing; add =Q, =R, =B or =N"},
+		{"castling", "r3k2r/8/8/8/8/8/5r2/R3K2R w KQ - 0 1", "O-O", "castling not allowed: f1 attacked"},
+		{"not a move", start, "x", "invalid move"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board, err := ParseFen(tt.fen)
+			if err != nil {
+				t.Fatalf("Failed to parse FEN: %v", err)
+			}
+			_, err = board.ParseMove(tt.move)
+			if err == nil {
+				t.Fatalf("ParseMove(%q) succeeded", tt.move)
+			}
+			if err.Error() != tt.err {
+				t.Errorf("ParseMove(%q) error = %q, want %q", tt.move, err, tt.err)
+			}
+			if !errors.Is(err, ErrInvalidMove) {
+				t.Errorf("ParseMove(%q) error does not wrap ErrInvalidMove", tt.move)
+			}
+		})
+	}
+}
+
+func TestParseMoveErrorTypes(t *testing.T) {
+	board, err := ParseFen("4k3/8/8/8/8/5N2/8/1N2K3 w - - 0 1")
+	if err != nil {
+		t.Fatalf("Failed to parse FEN: %v", err)
+	}
+
+	_, err = board.ParseMove("Nd2")
+	var ambiguous *AmbiguousMoveError
+	if !errors.As(err, &ambiguous) {
+		t.Fatalf("Nd2: got %T, want *AmbiguousMoveError", err)
+	}
+	if ambiguous.Piece != Knight || ambiguous.To != D2 || len(ambiguous.Candidates) != 2 {
+		t.Errorf("Nd2: got %+v", ambiguous)
+	}
+
+	_, err = board.ParseMove("Nd5")
+	var illegal *IllegalMoveError
+	if !errors.As(err, &illegal) || illegal.Move != "Nd5" {
+		t.Errorf("Nd5: got %#v, want *IllegalMoveError", err)
+	}
+
+	_, err = board.ParseMove("Sd2")
+	var unknown *UnknownPieceError
+	if !errors.As(err, &unknown) || unknown.Letter != 'S' {
+		t.Errorf("Sd2: got %#v, want *UnknownPieceError", err)
+	}
+}
+
+func TestParseAnnotatedMove(t *testing.T) {
+	board, err := ParseFen("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1")
+	if err != nil {
+		t.Fatalf("Failed to parse FEN: %v", err)
+	}
+	tests := []struct {
+		move string
+		want Move
+		nag  int
+	}{
+		{"e4", Move{From: E2, To: E4}, 0},
+		{"e4!", Move{From: E2, To: E4}, 1},
+		{"e4??", Move{From: E2, To: E4}, 4},
+		{"Nf3!?", Move{From: G1, To: F3}, 5},
+		{"Nf3+?!", Move{From: G1, To: F3}, 6},
+		{"Nf3#", Move{From: G1, To: F3}, 0},
+		{"--", NullMove, 0},
+		{"Z0", NullMove, 0},
+		{"--!", NullMove, 1},
+	}
+	for _, tt := range tests {
+		m, nag, err := board.ParseAnnotatedMove(tt.move)
+		if err != nil {
+			t.Errorf("ParseAnnotatedMove(%q) failed: %v", tt.move, err)
+			continue
+		}
+		if m != tt.want || nag != tt.nag {
+			t.Errorf("ParseAnnotatedMove(%q) = %v, %d, want %v, %d", tt.move, m, nag, tt.want, tt.nag)
+		}
+	}
+}
@@ -0,0 +1,226 @@
+// Package notify tells the user when a sync or an analysis finishes, by
+// posting to a webhook, an ntfy topic or a Slack incoming webhook.
+//
+// Webhooks receive the Event as JSON. ntfy and Slack receive its Text,
+// which summarizes it in a line.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kyleboon/gochess/internal/config"
+)
+
+const (
+	// EventSync is sent when an import of new games finishes.
+	EventSync = "sync"
+
+	// EventAnalysis is sent when a requested game analysis finishes.
+	EventAnalysis = "analysis"
+
+	// sendTimeout bounds each notification request.
+	sendTimeout = 10 * time.Second
+)
+
+// Event is the payload of a notification.
+type Event struct {
+	Type     string           `json:"type"` // EventSync or EventAnalysis
+	Time     time.Time        `json:"time"`
+	Sync     *SyncSummary     `json:"sync,omitempty"`
+	Analysis *AnalysisSummary `json:"analysis,omitempty"`
+}
+
+// SyncSummary sums up a sync of the configured sources.
+type SyncSummary struct {
+	GamesAdded    int               `json:"games_added"`
+	TotalGames    int               `json:"total_games"`
+	GamesAnalyzed int               `json:"games_analyzed,omitempty"` // new games analyzed after the sync
+	Blunders      int               `json:"blunders,omitempty"`       // found in the analyzed games
+	Errors        map[string]string `json:"errors,omitempty"`         // by source
+}
+
+// AnalysisSummary sums up the analysis of a game.
+type AnalysisSummary struct {
+	GameID       int    `json:"game_id"`
+	White        string `json:"white"`
+	Black        string `json:"black"`
+	AnalysisID   int    `json:"analysis_id,omitempty"`
+	Blunders     int    `json:"blunders"`
+	Mistakes     int    `json:"mistakes"`
+	Inaccuracies int    `json:"inaccuracies"`
+	Error        string `json:"error,omitempty"`
+}
+
+// NewSyncEvent returns the event sent when a sync finishes.
+func NewSyncEvent(summary SyncSummary) Event {
+	return Event{Type: EventSync, Time: time.Now().UTC(), Sync: &summary}
+}
+
+// NewAnalysisEvent returns the event sent when an analysis finishes.
+func NewAnalysisEvent(summary AnalysisSummary) Event {
+	return Event{Type: EventAnalysis, Time: time.Now().UTC(), Analysis: &summary}
+}
+
+// Title returns a short title for the event.
+func (e Event) Title() string {
+	failed := (e.Sync != nil && len(e.Sync.Errors) > 0) || (e.Analysis != nil && e.Analysis.Error != "")
+	if failed {
+		return fmt.Sprintf("gochess %s failed", e.Type)
+	}
+	return fmt.Sprintf("gochess %s finished", e.Type)
+}
+
+// Text summarizes the event in a line.
+func (e Event) Text() string {
+	switch {
+	case e.Sync != nil:
+		s := e.Sync
+		text := fmt.Sprintf("Sync finished: %s added, %d in the database", plural(s.GamesAdded, "new game"), s.TotalGames)
+		if s.GamesAnalyzed > 0 {
+			text += fmt.Sprintf(", %d analyzed (%s)", s.GamesAnalyzed, plural(s.Blunders, "blunder"))
+		}
+		sources := make([]string, 0, len(s.Errors))
+		for source := range s.Errors {
+			sources = append(sources, source)
+		}
+		sort.Strings(sources)
+		for _, source := range sources {
+			text += fmt.Sprintf("; %s failed: %s", source, s.Errors[source])
+		}
+		return text
+	case e.Analysis != nil:
+		a := e.Analysis
+		game := fmt.Sprintf("game %d", a.GameID)
+		if a.White != "" || a.Black != "" {
+			game = fmt.Sprintf("%s vs %s (game %d)", a.White, a.Black, a.GameID)
+		}
+		if a.Error != "" {
+			return fmt.Sprintf("Analysis of %s failed: %s", game, a.Error)
+		}
+		return fmt.Sprintf("Analysis of %s finished: %s, %s, %s", game,
+			plural(a.Blunders, "blunder"), plural(a.Mistakes, "mistake"), plural(a.Inaccuracies, "inaccuracy"))
+	}
+	return e.Title()
+}
+
+// plural returns n with noun, made plural unless n is 1.
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	if strings.HasSuffix(noun, "y") {
+		return fmt.Sprintf("%d %sies", n, strings.TrimSuffix(noun, "y"))
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// Notifier sends events to the configured destinations.
+type Notifier struct {
+	httpClient *http.Client
+	logger     *slog.Logger
+	webhook    string
+	ntfy       string
+	slack      string
+}
+
+// New returns a Notifier without destinations, which sends nothing.
+func New(logger *slog.Logger) *Notifier {
+	return &Notifier{
+		httpClient: &http.Client{Timeout: sendTimeout},
+		logger:     logger.With("component", "notify"),
+	}
+}
+
+// FromConfig returns a Notifier sending to the destinations of cfg, which
+// may be nil.
+func FromConfig(cfg *config.NotifyConfig, logger *slog.Logger) *Notifier {
+	n := New(logger)
+	if cfg != nil {
+		n.WithWebhook(cfg.Webhook).WithNtfy(cfg.Ntfy).WithSlack(cfg.Slack)
+	}
+	return n
+}
+
+// WithWebhook sets the URL receiving events as JSON.
+func (n *Notifier) WithWebhook(url string) *Notifier {
+	n.webhook = url
+	return n
+}
+
+// WithNtfy sets the URL of the ntfy topic receiving events.
+func (n *Notifier) WithNtfy(url string) *Notifier {
+	n.ntfy = url
+	return n
+}
+
+// WithSlack sets the Slack incoming webhook URL receiving events.
+func (n *Notifier) WithSlack(url string) *Notifier {
+	n.slack = url
+	return n
+}
+
+// Enabled reports whether the notifier has a destination.
+func (n *Notifier) Enabled() bool {
+	return n != nil && (n.webhook != "" || n.ntfy != "" || n.slack != "")
+}
+
+// Send sends e to every destination. It tries them all, returning their
+// errors joined.
+func (n *Notifier) Send(ctx context.Context, e Event) error {
+	if !n.Enabled() {
+		return nil
+	}
+	var errs []error
+	if n.webhook != "" {
+		body, err := json.Marshal(e)
+		if err != nil {
+			return fmt.Errorf("failed to marshal event: %w", err)
+		}
+		errs = append(errs, n.post(ctx, "webhook", n.webhook, "application/json", body, nil))
+	}
+	if n.ntfy != "" {
+		headers := map[string]string{"Title": e.Title(), "Tags": "chess_pawn"}
+		errs = append(errs, n.post(ctx, "ntfy", n.ntfy, "text/plain; charset=utf-8", []byte(e.Text()), headers))
+	}
+	if n.slack != "" {
+		body, err := json.Marshal(map[string]string{"text": e.Text()})
+		if err != nil {
+			return fmt.Errorf("failed to marshal Slack message: %w", err)
+		}
+		errs = append(errs, n.post(ctx, "slack", n.slack, "application/json", body, nil))
+	}
+	return errors.Join(errs...)
+}
+
+// post posts body to the destination called name at url.
+func (n *Notifier) post(ctx context.Context, name, url, contentType string, body []byte, headers map[string]string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create %s request: %w", name, err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("User-Agent", "gochess")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to notify %s: %w", name, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("failed to notify %s: status %d", name, resp.StatusCode)
+	}
+	n.logger.Debug("notification sent", "destination", name)
+	return nil
+}
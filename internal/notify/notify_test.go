@@ -0,0 +1,141 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kyleboon/gochess/internal/config"
+	"github.com/kyleboon/gochess/internal/logging"
+)
+
+// received is a request made to a test server.
+type received struct {
+	path, contentType, title, body string
+}
+
+func newRecorder(t *testing.T, status int) (*httptest.Server, *[]received) {
+	t.Helper()
+	var requests []received
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, received{r.URL.Path, r.Header.Get("Content-Type"), r.Header.Get("Title"), string(body)})
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(ts.Close)
+	return ts, &requests
+}
+
+func TestSend(t *testing.T) {
+	ts, requests := newRecorder(t, http.StatusOK)
+	n := FromConfig(&config.NotifyConfig{
+		Webhook: ts.URL + "/hook",
+		Ntfy:    ts.URL + "/topic",
+		Slack:   ts.URL + "/slack",
+	}, logging.Discard())
+
+	e := NewAnalysisEvent(AnalysisSummary{GameID: 3, White: "Alice", Black: "Bob", Blunders: 2, Mistakes: 1})
+	if err := n.Send(context.Background(), e); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if len(*requests) != 3 {
+		t.Fatalf("got %d requests, want 3", len(*requests))
+	}
+
+	hook := (*requests)[0]
+	if hook.path != "/hook" || hook.contentType != "application/json" {
+		t.Errorf("webhook request = %+v", hook)
+	}
+	var payload Event
+	if err := json.Unmarshal([]byte(hook.body), &payload); err != nil {
+		t.Fatalf("invalid webhook payload: %v", err)
+	}
+	if payload.Type != EventAnalysis || payload.Analysis == nil || payload.Analysis.Blunders != 2 {
+		t.Errorf("webhook payload = %s", hook.body)
+	}
+
+	text := "Analysis of Alice vs Bob (game 3) finished: 2 blunders, 1 mistake, 0 inaccuracies"
+	ntfy := (*requests)[1]
+	if ntfy.path != "/topic" || ntfy.body != text || ntfy.title != "gochess analysis finished" {
+		t.Errorf("ntfy request = %+v", ntfy)
+	}
+	slack := (*requests)[2]
+	if slack.path != "/slack" || !strings.Contains(slack.body, `"text":"`+text+`"`) {
+		t.Errorf("Slack request = %+v", slack)
+	}
+}
+
+func TestSendErrors(t *testing.T) {
+	ts, requests := newRecorder(t, http.StatusInternalServerError)
+	n := New(logging.Discard()).WithWebhook(ts.URL).WithNtfy(ts.URL)
+
+	err := n.Send(context.Background(), NewSyncEvent(SyncSummary{GamesAdded: 1}))
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	// Every destination is tried
+	if len(*requests) != 2 {
+		t.Errorf("got %d requests, want 2", len(*requests))
+	}
+	if !strings.Contains(err.Error(), "webhook") || !strings.Contains(err.Error(), "ntfy") {
+		t.Errorf("error = %v", err)
+	}
+}
+
+func TestDisabled(t *testing.T) {
+	var nilNotifier *Notifier
+	for _, n := range []*Notifier{nilNotifier, New(logging.Discard()), FromConfig(nil, logging.Discard())} {
+		if n.Enabled() {
+			t.Error("notifier without destinations is enabled")
+		}
+		if err := n.Send(context.Background(), NewSyncEvent(SyncSummary{})); err != nil {
+			t.Errorf("Send failed: %v", err)
+		}
+	}
+}
+
+func TestEventText(t *testing.T) {
+	tests := []struct {
+		event Event
+		title string
+		text  string
+	}{
+		{
+			NewSyncEvent(SyncSummary{GamesAdded: 1, TotalGames: 40}),
+			"gochess sync finished",
+			"Sync finished: 1 new game added, 40 in the database",
+		},
+		{
+			NewSyncEvent(SyncSummary{GamesAdded: 3, TotalGames: 43, GamesAnalyzed: 3, Blunders: 2}),
+			"gochess sync finished",
+			"Sync finished: 3 new games added, 43 in the database, 3 analyzed (2 blunders)",
+		},
+		{
+			NewSyncEvent(SyncSummary{GamesAdded: 5, TotalGames: 45, Errors: map[string]string{"lichess": "timeout", "chesscom": "not found"}}),
+			"gochess sync failed",
+			"Sync finished: 5 new games added, 45 in the database; chesscom failed: not found; lichess failed: timeout",
+		},
+		{
+			NewAnalysisEvent(AnalysisSummary{GameID: 7, Error: "no engine"}),
+			"gochess analysis failed",
+			"Analysis of game 7 failed: no engine",
+		},
+		{
+			NewAnalysisEvent(AnalysisSummary{GameID: 7, Blunders: 1, Inaccuracies: 1}),
+			"gochess analysis finished",
+			"Analysis of game 7 finished: 1 blunder, 0 mistakes, 1 inaccuracy",
+		},
+	}
+	for _, tt := range tests {
+		if got := tt.event.Title(); got != tt.title {
+			t.Errorf("Title() = %q, want %q", got, tt.title)
+		}
+		if got := tt.event.Text(); got != tt.text {
+			t.Errorf("Text() = %q, want %q", got, tt.text)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+// Package output writes the machine-readable output of commands run with
+// --json. The JSON goes to stdout and human-readable messages to stderr, so
+// that the output can be piped into other tools.
+package output
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+
+	"github.com/urfave/cli/v2"
+)
+
+// Stdout is where JSON output is written
+var Stdout io.Writer = os.Stdout
+
+// JSONFlag returns the --json flag of commands with machine-readable output
+func JSONFlag() cli.Flag {
+	return &cli.BoolFlag{
+		Name:  "json",
+		Usage: "Write the result as JSON to stdout, and messages to stderr",
+	}
+}
+
+// JSON reports whether the command was run with --json
+func JSON(c *cli.Context) bool {
+	return c.Bool("json")
+}
+
+// Messages returns where a command writes its human-readable messages: stderr
+// when it writes JSON, stdout otherwise
+func Messages(c *cli.Context) io.Writer {
+	if JSON(c) {
+		return os.Stderr
+	}
+	return Stdout
+}
+
+// WriteJSON writes v to stdout as indented JSON
+func WriteJSON(v any) error {
+	enc := json.NewEncoder(Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
@@ -0,0 +1,44 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+func jsonContext(t *testing.T, asJSON bool) *cli.Context {
+	t.Helper()
+	set := flag.NewFlagSet("test", flag.ContinueOnError)
+	set.Bool("json", false, "")
+	var args []string
+	if asJSON {
+		args = append(args, "--json")
+	}
+	require.NoError(t, set.Parse(args))
+	return cli.NewContext(cli.NewApp(), set, nil)
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	Stdout = &buf
+	defer func() { Stdout = os.Stdout }()
+
+	require.NoError(t, WriteJSON(map[string]int{"games": 3}))
+	var got map[string]int
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+	assert.Equal(t, 3, got["games"])
+}
+
+func TestMessages(t *testing.T) {
+	assert.False(t, JSON(jsonContext(t, false)))
+	assert.Equal(t, Stdout, Messages(jsonContext(t, false)))
+
+	assert.True(t, JSON(jsonContext(t, true)))
+	assert.Equal(t, os.Stderr, Messages(jsonContext(t, true)))
+}
@@ -0,0 +1,104 @@
+package parquet
+
+import "bytes"
+
+// Thrift compact protocol field types
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// compactWriter encodes Thrift structs with the compact protocol, which
+// Parquet uses for its page headers and file metadata.
+type compactWriter struct {
+	buf  bytes.Buffer
+	last []int16 // ID of the last field written in each open struct
+}
+
+// newCompactWriter returns a writer positioned inside a top-level struct.
+func newCompactWriter() *compactWriter {
+	return &compactWriter{last: []int16{0}}
+}
+
+// Bytes returns the encoding of the top-level struct, ending it.
+func (c *compactWriter) Bytes() []byte {
+	c.buf.WriteByte(0) // stop
+	return c.buf.Bytes()
+}
+
+func (c *compactWriter) varint(v uint64) {
+	for v >= 0x80 {
+		c.buf.WriteByte(byte(v) | 0x80)
+		v >>= 7
+	}
+	c.buf.WriteByte(byte(v))
+}
+
+func (c *compactWriter) zigzag(v int64) {
+	c.varint(uint64(v<<1) ^ uint64(v>>63))
+}
+
+// field writes the header of field id: the ID as a delta from the previous
+// field of the struct when it fits in four bits.
+func (c *compactWriter) field(id int16, typ byte) {
+	top := len(c.last) - 1
+	if delta := id - c.last[top]; delta > 0 && delta <= 15 {
+		c.buf.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		c.buf.WriteByte(typ)
+		c.zigzag(int64(id))
+	}
+	c.last[top] = id
+}
+
+func (c *compactWriter) i32(id int16, v int32) {
+	c.field(id, thriftI32)
+	c.zigzag(int64(v))
+}
+
+func (c *compactWriter) i64(id int16, v int64) {
+	c.field(id, thriftI64)
+	c.zigzag(v)
+}
+
+func (c *compactWriter) binary(id int16, s string) {
+	c.field(id, thriftBinary)
+	c.varint(uint64(len(s)))
+	c.buf.WriteString(s)
+}
+
+// list writes the header of a list field of size elements of type typ.
+func (c *compactWriter) list(id int16, typ byte, size int) {
+	c.field(id, thriftList)
+	if size < 15 {
+		c.buf.WriteByte(byte(size)<<4 | typ)
+		return
+	}
+	c.buf.WriteByte(0xf0 | typ)
+	c.varint(uint64(size))
+}
+
+// i32Elem and binaryElem write elements of a list.
+func (c *compactWriter) i32Elem(v int32) { c.zigzag(int64(v)) }
+
+func (c *compactWriter) binaryElem(s string) {
+	c.varint(uint64(len(s)))
+	c.buf.WriteString(s)
+}
+
+// beginStruct opens a struct field, or a struct element of a list when id
+// is 0; endStruct closes it.
+func (c *compactWriter) beginStruct(id int16) {
+	if id != 0 {
+		c.field(id, thriftStruct)
+	}
+	c.last = append(c.last, 0)
+}
+
+func (c *compactWriter) endStruct() {
+	c.buf.WriteByte(0) // stop
+	c.last = c.last[:len(c.last)-1]
+}
@@ -0,0 +1,286 @@
+// Package parquet writes Apache Parquet files: flat tables of required
+// columns, PLAIN encoded and uncompressed, which any Parquet reader (pandas,
+// Polars, DuckDB, Spark) can load.
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// magic starts and ends every Parquet file.
+const magic = "PAR1"
+
+// DefaultRowGroupSize is the number of rows buffered into each row group.
+const DefaultRowGroupSize = 100000
+
+// Type is the type of the values of a column.
+type Type int
+
+const (
+	Int64  Type = iota // int64, or int
+	Double             // float64
+	String             // string, stored as UTF-8
+)
+
+// Parquet physical types, converted types and encodings
+const (
+	physicalInt64     = 2
+	physicalDouble    = 5
+	physicalByteArray = 6
+	convertedUTF8     = 0
+	encodingPlain     = 0
+	encodingRLE       = 3
+	repetitionReq     = 0
+	pageTypeData      = 0
+	codecUncompressed = 0
+)
+
+// Column describes a column of the table.
+type Column struct {
+	Name string
+	Type Type
+}
+
+// physical returns the Parquet physical type of the column.
+func (c Column) physical() int32 {
+	switch c.Type {
+	case Double:
+		return physicalDouble
+	case String:
+		return physicalByteArray
+	}
+	return physicalInt64
+}
+
+// chunk is the metadata of a column chunk that has been written.
+type chunk struct {
+	offset int64 // offset of the data page
+	size   int64 // size of the page, with its header
+}
+
+// rowGroup is the metadata of a row group that has been written.
+type rowGroup struct {
+	rows   int64
+	chunks []chunk
+}
+
+// Writer writes rows to a Parquet file. Rows are buffered and written a
+// row group at a time; Close writes the last one and the file metadata.
+type Writer struct {
+	w            io.Writer
+	columns      []Column
+	rowGroupSize int
+	offset       int64
+	values       []bytes.Buffer // PLAIN encoded values of the buffered rows, by column
+	rows         int            // buffered rows
+	total        int64          // rows written
+	groups       []rowGroup
+	err          error
+}
+
+// NewWriter returns a Writer of a table with the given columns to w.
+func NewWriter(w io.Writer, columns []Column) *Writer {
+	return &Writer{
+		w:            w,
+		columns:      columns,
+		rowGroupSize: DefaultRowGroupSize,
+		values:       make([]bytes.Buffer, len(columns)),
+	}
+}
+
+// WithRowGroupSize sets the number of rows per row group.
+func (w *Writer) WithRowGroupSize(rows int) *Writer {
+	if rows > 0 {
+		w.rowGroupSize = rows
+	}
+	return w
+}
+
+// Write adds a row, with a value of the column's type for each column.
+func (w *Writer) Write(row ...any) error {
+	if w.err != nil {
+		return w.err
+	}
+	if len(row) != len(w.columns) {
+		return fmt.Errorf("row has %d values for %d columns", len(row), len(w.columns))
+	}
+	// Check the whole row first, so a bad value leaves no partial row
+	for i, v := range row {
+		if !w.columns[i].accepts(v) {
+			return fmt.Errorf("column %s: unexpected value %v of type %T", w.columns[i].Name, v, v)
+		}
+	}
+	for i, v := range row {
+		buf := &w.values[i]
+		switch v := v.(type) {
+		case int:
+			_ = binary.Write(buf, binary.LittleEndian, int64(v))
+		case int64:
+			_ = binary.Write(buf, binary.LittleEndian, v)
+		case float64:
+			_ = binary.Write(buf, binary.LittleEndian, math.Float64bits(v))
+		case string:
+			_ = binary.Write(buf, binary.LittleEndian, uint32(len(v)))
+			buf.WriteString(v)
+		}
+	}
+	w.rows++
+	if w.rows >= w.rowGroupSize {
+		return w.flush()
+	}
+	return nil
+}
+
+// accepts reports whether v is a value of the column's type.
+func (c Column) accepts(v any) bool {
+	switch v.(type) {
+	case int, int64:
+		return c.Type == Int64
+	case float64:
+		return c.Type == Double
+	case string:
+		return c.Type == String
+	}
+	return false
+}
+
+// Close writes the buffered rows and the file metadata. It does not close
+// the underlying writer.
+func (w *Writer) Close() error {
+	if w.err != nil {
+		return w.err
+	}
+	if w.offset == 0 {
+		if err := w.write([]byte(magic)); err != nil {
+			return err
+		}
+	}
+	if w.rows > 0 {
+		if err := w.flush(); err != nil {
+			return err
+		}
+	}
+	footer := w.metadata()
+	if err := w.write(footer); err != nil {
+		return err
+	}
+	size := binary.LittleEndian.AppendUint32(nil, uint32(len(footer)))
+	if err := w.write(append(size, magic...)); err != nil {
+		return err
+	}
+	w.err = fmt.Errorf("parquet writer is closed")
+	return nil
+}
+
+// write writes p to the file, keeping track of the offset.
+func (w *Writer) write(p []byte) error {
+	n, err := w.w.Write(p)
+	w.offset += int64(n)
+	if err != nil {
+		w.err = fmt.Errorf("failed to write parquet file: %w", err)
+		return w.err
+	}
+	return nil
+}
+
+// flush writes the buffered rows as a row group, a single data page for
+// each column.
+func (w *Writer) flush() error {
+	if w.offset == 0 {
+		if err := w.write([]byte(magic)); err != nil {
+			return err
+		}
+	}
+	group := rowGroup{rows: int64(w.rows)}
+	for i := range w.columns {
+		data := w.values[i].Bytes()
+		header := pageHeader(w.rows, len(data))
+		c := chunk{offset: w.offset, size: int64(len(header) + len(data))}
+		if err := w.write(header); err != nil {
+			return err
+		}
+		if err := w.write(data); err != nil {
+			return err
+		}
+		group.chunks = append(group.chunks, c)
+		w.values[i].Reset()
+	}
+	w.groups = append(w.groups, group)
+	w.total += int64(w.rows)
+	w.rows = 0
+	return nil
+}
+
+// pageHeader encodes the header of a data page of rows PLAIN encoded values
+// taking size bytes. Required columns have no repetition or definition
+// levels.
+func pageHeader(rows, size int) []byte {
+	c := newCompactWriter()
+	c.i32(1, pageTypeData)
+	c.i32(2, int32(size)) // uncompressed
+	c.i32(3, int32(size)) // compressed
+	c.beginStruct(5)      // data page header
+	c.i32(1, int32(rows))
+	c.i32(2, encodingPlain)
+	c.i32(3, encodingRLE) // definition levels
+	c.i32(4, encodingRLE) // repetition levels
+	c.endStruct()
+	return c.Bytes()
+}
+
+// metadata encodes the file metadata: the schema and the row groups.
+func (w *Writer) metadata() []byte {
+	c := newCompactWriter()
+	c.i32(1, 1) // version
+
+	c.list(2, thriftStruct, len(w.columns)+1)
+	c.beginStruct(0) // the root of the schema
+	c.binary(4, "schema")
+	c.i32(5, int32(len(w.columns)))
+	c.endStruct()
+	for _, col := range w.columns {
+		c.beginStruct(0)
+		c.i32(1, col.physical())
+		c.i32(3, repetitionReq)
+		c.binary(4, col.Name)
+		if col.Type == String {
+			c.i32(6, convertedUTF8)
+		}
+		c.endStruct()
+	}
+
+	c.i64(3, w.total)
+	c.list(4, thriftStruct, len(w.groups))
+	for _, g := range w.groups {
+		c.beginStruct(0)
+		c.list(1, thriftStruct, len(g.chunks))
+		var total int64
+		for i, ch := range g.chunks {
+			total += ch.size
+			c.beginStruct(0)
+			c.i64(2, ch.offset) // file offset
+			c.beginStruct(3)    // column metadata
+			c.i32(1, w.columns[i].physical())
+			c.list(2, thriftI32, 1)
+			c.i32Elem(encodingPlain)
+			c.list(3, thriftBinary, 1)
+			c.binaryElem(w.columns[i].Name)
+			c.i32(4, codecUncompressed)
+			c.i64(5, g.rows)
+			c.i64(6, ch.size) // uncompressed
+			c.i64(7, ch.size) // compressed
+			c.i64(9, ch.offset)
+			c.endStruct()
+			c.endStruct()
+		}
+		c.i64(2, total)
+		c.i64(3, g.rows)
+		c.endStruct()
+	}
+	c.binary(6, "gochess")
+	return c.Bytes()
+}
@@ -0,0 +1,148 @@
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// compactReader decodes Thrift compact structs into maps keyed by field ID,
+// to check what the writer wrote.
+type compactReader struct {
+	b   []byte
+	pos int
+}
+
+func (r *compactReader) varint() uint64 {
+	var v uint64
+	for shift := 0; ; shift += 7 {
+		b := r.b[r.pos]
+		r.pos++
+		v |= uint64(b&0x7f) << shift
+		if b < 0x80 {
+			return v
+		}
+	}
+}
+
+func (r *compactReader) zigzag() int64 {
+	v := r.varint()
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+func (r *compactReader) value(typ byte) any {
+	switch typ {
+	case thriftI32, thriftI64:
+		return r.zigzag()
+	case thriftBinary:
+		n := int(r.varint())
+		r.pos += n
+		return string(r.b[r.pos-n : r.pos])
+	case thriftList:
+		header := r.b[r.pos]
+		r.pos++
+		size := int(header >> 4)
+		if size == 15 {
+			size = int(r.varint())
+		}
+		list := make([]any, size)
+		for i := range list {
+			list[i] = r.value(header & 0x0f)
+		}
+		return list
+	case thriftStruct:
+		return r.structure()
+	}
+	panic("unexpected thrift type")
+}
+
+func (r *compactReader) structure() map[int16]any {
+	fields := make(map[int16]any)
+	var id int16
+	for {
+		header := r.b[r.pos]
+		r.pos++
+		if header == 0 {
+			return fields
+		}
+		if delta := int16(header >> 4); delta != 0 {
+			id += delta
+		} else {
+			id = int16(r.zigzag())
+		}
+		fields[id] = r.value(header & 0x0f)
+	}
+}
+
+func TestWriter(t *testing.T) {
+	columns := []Column{{"fen", String}, {"ply", Int64}, {"eval", Double}}
+	var buf bytes.Buffer
+	w := NewWriter(&buf, columns).WithRowGroupSize(2)
+	require.NoError(t, w.Write("8/8/8/8/8/8/8/K6k w - - 0 1", 0, 0.25))
+	require.NoError(t, w.Write("startpos", int64(1), -1.5))
+	assert.Error(t, w.Write("too few", 2))
+	assert.Error(t, w.Write("wrong type", "2", 0.0))
+	require.NoError(t, w.Write("", 2, math.Inf(1)))
+	require.NoError(t, w.Close())
+
+	file := buf.Bytes()
+	require.Equal(t, magic, string(file[:4]))
+	require.Equal(t, magic, string(file[len(file)-4:]))
+	size := int(binary.LittleEndian.Uint32(file[len(file)-8:]))
+	footer := &compactReader{b: file[len(file)-8-size : len(file)-8]}
+	meta := footer.structure()
+	assert.Equal(t, size, footer.pos, "the footer is read to its end")
+
+	assert.Equal(t, int64(3), meta[3], "rows")
+	schema := meta[2].([]any)
+	require.Len(t, schema, 4)
+	assert.Equal(t, int64(3), schema[0].(map[int16]any)[5], "the root has a child per column")
+	for i, col := range columns {
+		assert.Equal(t, col.Name, schema[i+1].(map[int16]any)[4])
+	}
+
+	groups := meta[4].([]any)
+	require.Len(t, groups, 2)
+	assert.Equal(t, int64(2), groups[0].(map[int16]any)[3])
+	assert.Equal(t, int64(1), groups[1].(map[int16]any)[3])
+
+	// pageValues returns the values of a column chunk, after its page header
+	pageValues := func(group, column int) (map[int16]any, []byte) {
+		chunk := groups[group].(map[int16]any)[1].([]any)[column].(map[int16]any)
+		meta := chunk[3].(map[int16]any)
+		offset := int(meta[9].(int64))
+		r := &compactReader{b: file[offset:]}
+		header := r.structure()
+		size := int(header[2].(int64))
+		assert.Equal(t, int64(r.pos+size), meta[6], "the chunk is its page")
+		return header, file[offset+r.pos : offset+r.pos+size]
+	}
+
+	header, values := pageValues(0, 1)
+	assert.Equal(t, int64(2), header[5].(map[int16]any)[1], "values in the page")
+	assert.Equal(t, []byte{0, 0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0}, values)
+
+	_, values = pageValues(0, 2)
+	assert.Equal(t, -1.5, math.Float64frombits(binary.LittleEndian.Uint64(values[8:])))
+
+	_, values = pageValues(1, 0)
+	assert.Equal(t, []byte{0, 0, 0, 0}, values, "an empty string")
+}
+
+func TestWriter_Empty(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf, []Column{{"fen", String}})
+	require.NoError(t, w.Close())
+
+	file := buf.Bytes()
+	size := int(binary.LittleEndian.Uint32(file[len(file)-8:]))
+	require.Equal(t, len(file)-12, size, "magic, metadata, size, magic")
+	meta := (&compactReader{b: file[4 : 4+size]}).structure()
+	assert.Equal(t, int64(0), meta[3])
+	assert.Empty(t, meta[4])
+	assert.Error(t, w.Write("closed"))
+}
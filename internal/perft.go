@@ -0,0 +1,112 @@
+package internal
+
+// Perft counts the leaf nodes of the tree of legal moves depth plies deep
+// from b. The counts of well-known positions are published, which makes
+// perft the standard test (and benchmark) of a move generator.
+func (b *Board) Perft(depth int) uint64 {
+	if depth == 0 {
+		return 1
+	}
+
+	moves := b.LegalMoves()
+	if depth == 1 {
+		return uint64(len(moves))
+	}
+
+	var nodes uint64
+	for _, move := range moves {
+		nodes += b.MakeMove(move).Perft(depth - 1)
+	}
+	return nodes
+}
+
+// PerftStats are the counts of a perft search, broken down by the kind of
+// the moves leading to the leaf nodes, as published alongside the node
+// counts of the standard perft positions. The counters are 64 bits wide, as
+// the counts pass 2^32 from depth 7 of the starting position on.
+type PerftStats struct {
+	Nodes           uint64 `json:"nodes"`
+	Captures        uint64 `json:"captures"`
+	EnPassant       uint64 `json:"en_passant"`
+	Castles         uint64 `json:"castles"`
+	Promotions      uint64 `json:"promotions"`
+	Checks          uint64 `json:"checks"`
+	DiscoveryChecks uint64 `json:"discovery_checks"`
+	DoubleChecks    uint64 `json:"double_checks"`
+	Checkmates      uint64 `json:"checkmates"`
+}
+
+// PerftWithStats is like Perft, but also counts the captures, en passant
+// captures, castles, promotions, checks and checkmates among the moves of the
+// last ply. A check by a piece other than the one that moved is a discovery
+// check; a double check is counted as one too if the moved piece does not
+// give check. It is much slower than Perft, as it looks at every leaf.
+func (b *Board) PerftWithStats(depth int) PerftStats {
+	var stats PerftStats
+	b.perftWithStats(depth, &stats)
+	return stats
+}
+
+func (b *Board) perftWithStats(depth int, stats *PerftStats) {
+	if depth == 0 {
+		stats.Nodes++
+		return
+	}
+
+	moves := b.LegalMoves()
+	if depth > 1 {
+		for _, move := range moves {
+			b.MakeMove(move).perftWithStats(depth-1, stats)
+		}
+		return
+	}
+
+	for _, move := range moves {
+		stats.Nodes++
+		piece := b.Piece[move.From]
+		target := b.Piece[move.To]
+		landing := move.To // where the moved piece that may give check lands
+		switch {
+		case piece.Type() == King && target == b.my(Rook):
+			stats.Castles++
+			_, _, landing, _, _, _ = b.castleSquares(castlingSide(move))
+		case piece.Type() == Pawn && move.To == b.EpSquare:
+			stats.Captures++
+			stats.EnPassant++
+		case target != NoPiece:
+			stats.Captures++
+		}
+		if move.Promotion != NoPiece {
+			stats.Promotions++
+		}
+
+		after := b.MakeMove(move)
+		checkers := after.Checkers()
+		if len(checkers) == 0 {
+			continue
+		}
+		stats.Checks++
+		if len(checkers) > 1 {
+			stats.DoubleChecks++
+		}
+		direct := false
+		for _, sq := range checkers {
+			direct = direct || sq == landing
+		}
+		if !direct {
+			stats.DiscoveryChecks++
+		}
+		if len(after.LegalMoves()) == 0 {
+			stats.Checkmates++
+		}
+	}
+}
+
+// castlingSide returns the side (queenSide or kingSide) a castling move,
+// written as the king taking its own rook, castles to.
+func castlingSide(m Move) int {
+	if m.To < m.From {
+		return queenSide
+	}
+	return kingSide
+}
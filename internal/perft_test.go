@@ -1,6 +1,7 @@
 package internal
 
 import (
+	"encoding/json"
 	"fmt"
 	"testing"
 	"time"
@@ -8,19 +9,6 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
-// PerftStats tracks various statistics during perft
-type PerftStats struct {
-	Nodes           int
-	Captures        int
-	EnPassant       int
-	Castles         int
-	Promotions      int
-	Checks          int
-	DiscoveryChecks int
-	DoubleChecks    int
-	Checkmates      int
-}
-
 // PerftExpected contains expected perft results for validation
 var PerftExpected = []PerftStats{
 	{Nodes: 1},                              // depth 0
@@ -36,227 +24,6 @@ var PerftExpected = []PerftStats{
 	{Nodes: 2439530234167}, // depth 9
 }
 
-// isDiscoveryCheck determines if a move is a discovery check
-func isDiscoveryCheck(b *Board, move Move) bool {
-	// A discovery check occurs when a piece moves out of the way to reveal an attack on the king
-	oldBoard := b.Copy()
-	newBoard := b.MakeMove(move)
-
-	// Get the opponent's king position
-	kingPos := newBoard.find(newBoard.opp(King), A1, H8)
-	if kingPos == NoSquare {
-		return false
-	}
-
-	// Check if it's check in the new position
-	check, _ := newBoard.IsCheckOrMate()
-	if !check {
-		return false
-	}
-
-	// The piece that moved shouldn't be the one giving check
-	pieceType := oldBoard.Piece[move.From].Type()
-	kingFile, kingRank := kingPos.File(), kingPos.Rank()
-	moveToFile, moveToRank := move.To.File(), move.To.Rank()
-
-	switch pieceType {
-	case Queen:
-		// If the queen is aligned with the king, it might be giving the check directly
-		if kingFile == moveToFile || kingRank == moveToRank ||
-			abs(kingFile-moveToFile) == abs(kingRank-moveToRank) {
-			return false
-		}
-	case Rook:
-		// If the rook is aligned with the king, it might be giving the check directly
-		if kingFile == moveToFile || kingRank == moveToRank {
-			return false
-		}
-	case Bishop:
-		// If the bishop is aligned with the king, it might be giving the check directly
-		if abs(kingFile-moveToFile) == abs(kingRank-moveToRank) {
-			return false
-		}
-	case Knight:
-		// Check if knight is giving the check
-		dx, dy := abs(kingFile-moveToFile), abs(kingRank-moveToRank)
-		if (dx == 1 && dy == 2) || (dx == 2 && dy == 1) {
-			return false
-		}
-	case Pawn:
-		// Check if pawn is giving the check
-		dx := abs(kingFile - moveToFile)
-		if dx <= 1 && moveToRank-kingRank == oldBoard.SideToMove*2-1 {
-			return false
-		}
-	}
-
-	return true
-}
-
-// isDoubleCheck determines if a move results in a double check
-func isDoubleCheck(b *Board, move Move) bool {
-	newBoard := b.MakeMove(move)
-
-	// Get the opponent's king position
-	kingPos := newBoard.find(newBoard.opp(King), A1, H8)
-	if kingPos == NoSquare {
-		return false
-	}
-
-	// Count how many pieces are giving check
-	checkCount := 0
-	for i, piece := range newBoard.Piece {
-		if piece == NoPiece || piece.Color() != newBoard.SideToMove {
-			continue
-		}
-
-		sq := Sq(i)
-
-		// Check if this piece is attacking the king
-		switch piece.Type() {
-		case Pawn:
-			// Pawns attack diagonally
-			if abs(sq.File()-kingPos.File()) == 1 &&
-				sq.Rank()-kingPos.Rank() == newBoard.SideToMove*2-1 {
-				checkCount++
-			}
-		case Knight:
-			// Knights attack in an L shape
-			dx, dy := abs(sq.File()-kingPos.File()), abs(sq.Rank()-kingPos.Rank())
-			if (dx == 1 && dy == 2) || (dx == 2 && dy == 1) {
-				checkCount++
-			}
-		case Bishop:
-			// Bishops attack diagonally
-			if abs(sq.File()-kingPos.File()) == abs(sq.Rank()-kingPos.Rank()) {
-				// Check that the path is clear
-				if isPathClear(newBoard, sq, kingPos) {
-					checkCount++
-				}
-			}
-		case Rook:
-			// Rooks attack in straight lines
-			if sq.File() == kingPos.File() || sq.Rank() == kingPos.Rank() {
-				// Check that the path is clear
-				if isPathClear(newBoard, sq, kingPos) {
-					checkCount++
-				}
-			}
-		case Queen:
-			// Queens attack like bishops and rooks combined
-			if sq.File() == kingPos.File() || sq.Rank() == kingPos.Rank() ||
-				abs(sq.File()-kingPos.File()) == abs(sq.Rank()-kingPos.Rank()) {
-				// Check that the path is clear
-				if isPathClear(newBoard, sq, kingPos) {
-					checkCount++
-				}
-			}
-		}
-
-		if checkCount >= 2 {
-			return true
-		}
-	}
-
-	return false
-}
-
-// isPathClear checks if there are no pieces between the two squares
-func isPathClear(b *Board, from, to Sq) bool {
-	dx := sign(to.File() - from.File())
-	dy := sign(to.Rank() - from.Rank())
-
-	x, y := from.File(), from.Rank()
-	for {
-		x += dx
-		y += dy
-		sq := Square(x, y)
-		if sq == to {
-			return true
-		}
-		if b.Piece[sq] != NoPiece {
-			return false
-		}
-	}
-}
-
-func sign(x int) int {
-	if x < 0 {
-		return -1
-	} else if x > 0 {
-		return 1
-	}
-	return 0
-}
-
-func abs(x int) int {
-	if x < 0 {
-		return -x
-	}
-	return x
-}
-
-// perftWithStats performs a perft search with detailed statistics
-func perftWithStats(b *Board, depth int, stats *PerftStats) {
-	if depth == 0 {
-		stats.Nodes++
-		return
-	}
-
-	moves := b.LegalMoves()
-	if depth == 1 {
-		for _, move := range moves {
-			stats.Nodes++
-
-			// Count captures
-			if b.Piece[move.To] != NoPiece {
-				stats.Captures++
-			}
-
-			// Count en passant captures
-			if move.To == b.EpSquare && b.Piece[move.From].Type() == Pawn {
-				stats.EnPassant++
-			}
-
-			// Count castling moves
-			if b.Piece[move.From].Type() == King && abs(move.From.File()-move.To.File()) > 1 {
-				stats.Castles++
-			}
-
-			// Count promotions
-			if move.Promotion != NoPiece {
-				stats.Promotions++
-			}
-
-			// Make the move to check for checks and checkmates
-			newBoard := b.MakeMove(move)
-			check, mate := newBoard.IsCheckOrMate()
-			if check {
-				stats.Checks++
-
-				// Check for discovery and double checks
-				if isDiscoveryCheck(b, move) {
-					stats.DiscoveryChecks++
-				}
-
-				if isDoubleCheck(b, move) {
-					stats.DoubleChecks++
-				}
-
-				if mate {
-					stats.Checkmates++
-				}
-			}
-		}
-		return
-	}
-
-	for _, move := range moves {
-		newBoard := b.MakeMove(move)
-		perftWithStats(newBoard, depth-1, stats)
-	}
-}
-
 func TestPerft(t *testing.T) {
 	// We'll test starting from the initial position
 	board, err := ParseFen("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1")
@@ -275,7 +42,7 @@ func TestPerft(t *testing.T) {
 
 			go func() {
 				startTime := time.Now()
-				perftWithStats(board, depth, stats)
+				*stats = board.PerftWithStats(depth)
 				duration := time.Since(startTime)
 				nodesPerSecond := float64(stats.Nodes) / duration.Seconds()
 
@@ -321,6 +88,61 @@ func TestPerft(t *testing.T) {
 	}
 }
 
+func TestBoardPerft(t *testing.T) {
+	tests := []struct {
+		name  string
+		fen   string
+		nodes []uint64 // by depth, from depth 1
+	}{
+		{"Starting position", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", []uint64{20, 400, 8902}},
+		{"Kiwipete", "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1", []uint64{48, 2039, 97862}},
+		{"Endgame", "8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1", []uint64{14, 191, 2812}},
+		{"Promotions", "r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1", []uint64{6, 264, 9467}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board, err := ParseFen(tt.fen)
+			assert.NoError(t, err)
+
+			assert.Equal(t, uint64(1), board.Perft(0))
+			for i, nodes := range tt.nodes {
+				assert.Equal(t, nodes, board.Perft(i+1), "Perft(%d)", i+1)
+			}
+		})
+	}
+}
+
+func TestPerftWithStats(t *testing.T) {
+	// Published counts, which include castling, en passant, promotions and
+	// discovered and double checks
+	tests := []struct {
+		name  string
+		fen   string
+		depth int
+		want  PerftStats
+	}{
+		{"Kiwipete", "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1", 3,
+			PerftStats{Nodes: 97862, Captures: 17102, EnPassant: 45, Castles: 3162, Checks: 993, Checkmates: 1}},
+		{"Endgame", "8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1", 4,
+			PerftStats{Nodes: 43238, Captures: 3348, EnPassant: 123, Checks: 1680, DiscoveryChecks: 106, Checkmates: 17}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board, err := ParseFen(tt.fen)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, board.PerftWithStats(tt.depth))
+		})
+	}
+}
+
+func TestPerftStatsJSON(t *testing.T) {
+	data, err := json.Marshal(PerftStats{Nodes: 3195901860, EnPassant: 1})
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"nodes":3195901860`)
+	assert.Contains(t, string(data), `"en_passant":1`)
+}
+
 // For running individual perft tests at specific depths
 func TestPerftAtDepth(t *testing.T) {
 	// Skip this in normal testing
@@ -333,7 +155,7 @@ func TestPerftAtDepth(t *testing.T) {
 	stats := &PerftStats{}
 
 	startTime := time.Now()
-	perftWithStats(board, depth, stats)
+	board.perftWithStats(depth, stats)
 	duration := time.Since(startTime)
 
 	t.Logf("Perft(%d) results:", depth)
@@ -359,14 +181,14 @@ func TestPerftDivide(t *testing.T) {
 	depth := 5 // Change this to the desired depth
 
 	moves := board.LegalMoves()
-	var totalNodes int
+	var totalNodes uint64
 
 	t.Logf("Perft Divide at depth %d:", depth)
 	for _, move := range moves {
 		stats := &PerftStats{}
 		newBoard := board.MakeMove(move)
 
-		perftWithStats(newBoard, depth-1, stats)
+		newBoard.perftWithStats(depth-1, stats)
 		t.Logf("  %s: %d", move.San(board), stats.Nodes)
 		totalNodes += stats.Nodes
 	}
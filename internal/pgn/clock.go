@@ -0,0 +1,134 @@
+package pgn
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseClock extracts the remaining clock time from a comment containing a
+// "[%clk H:MM:SS]" command, as written by Chess.com and Lichess. Fractional
+// seconds ("[%clk 0:09:59.9]") are supported.
+func ParseClock(comment string) (time.Duration, bool) {
+	i := strings.Index(comment, "[%clk ")
+	if i < 0 {
+		return 0, false
+	}
+	s := comment[i+len("[%clk "):]
+	end := strings.IndexByte(s, ']')
+	if end < 0 {
+		return 0, false
+	}
+	parts := strings.Split(strings.TrimSpace(s[:end]), ":")
+	if len(parts) > 3 {
+		return 0, false
+	}
+
+	// all but the last component are hours and minutes
+	minutes := 0
+	for _, part := range parts[:len(parts)-1] {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return 0, false
+		}
+		minutes = minutes*60 + n
+	}
+	// the last component holds the seconds, possibly fractional
+	secs, err := strconv.ParseFloat(parts[len(parts)-1], 64)
+	if err != nil || secs < 0 {
+		return 0, false
+	}
+	d := time.Duration(minutes)*time.Minute + time.Duration(secs*float64(time.Second)).Round(time.Millisecond)
+	return d, true
+}
+
+// Clock returns the remaining clock time of the player who made the move, as
+// recorded in the move's comments.
+func (n *Node) Clock() (time.Duration, bool) {
+	for _, c := range n.Comment {
+		if d, ok := ParseClock(c); ok {
+			return d, true
+		}
+	}
+	return 0, false
+}
+
+// ParseTimeControl parses a TimeControl tag value of the form "base+increment"
+// ("600+5") or "base" ("300"), both in seconds. Multi-period, sandclock and
+// correspondence time controls ("40/7200:3600", "*60", "1/86400") are not
+// supported and return false.
+func ParseTimeControl(tc string) (base, increment time.Duration, ok bool) {
+	baseStr, incStr, hasInc := strings.Cut(strings.TrimSpace(tc), "+")
+	b, err := strconv.Atoi(baseStr)
+	if err != nil || b <= 0 {
+		return 0, 0, false
+	}
+	inc := 0
+	if hasInc {
+		inc, err = strconv.Atoi(incStr)
+		if err != nil || inc < 0 {
+			return 0, 0, false
+		}
+	}
+	return time.Duration(b) * time.Second, time.Duration(inc) * time.Second, true
+}
+
+// MoveTime records the clock state after one move of the main line.
+type MoveTime struct {
+	Ply   int           // 1-based half-move number
+	Color int           // side that made the move (internal.White or internal.Black)
+	Clock time.Duration // clock remaining after the move
+	Spent time.Duration // time spent thinking on the move
+}
+
+// MoveTimes returns the time spent on each move of the main line, derived
+// from the %clk comments and the TimeControl tag. The increment is added back
+// so that Spent is the actual thinking time. Each side's first move is
+// measured against the base time when the time control is known, and counts
+// as zero otherwise. The list stops at the first move without a clock. The
+// moves of the game must already have been parsed.
+func (g *Game) MoveTimes() []MoveTime {
+	base, inc, hasBase := ParseTimeControl(g.Tags["TimeControl"])
+
+	var times []MoveTime
+	var last [2]time.Duration
+	var seen [2]bool
+	ply := 0
+	for n := g.Root.Next; n != nil; n = n.Next {
+		ply++
+		clock, ok := n.Clock()
+		if !ok {
+			break
+		}
+		color := n.Parent.Board.SideToMove
+
+		prev := clock
+		switch {
+		case seen[color]:
+			prev = last[color]
+		case hasBase:
+			prev = base
+		}
+		spent := prev - clock
+		if seen[color] || hasBase {
+			spent += inc
+		}
+		if spent < 0 {
+			spent = 0
+		}
+
+		times = append(times, MoveTime{Ply: ply, Color: color, Clock: clock, Spent: spent})
+		last[color], seen[color] = clock, true
+	}
+	return times
+}
+
+// TimeTroubleThreshold returns the remaining clock time below which a player
+// is considered to be in time trouble: 10% of the base time, or 30 seconds
+// when the base time is unknown.
+func TimeTroubleThreshold(base time.Duration) time.Duration {
+	if base <= 0 {
+		return 30 * time.Second
+	}
+	return base / 10
+}
@@ -0,0 +1,84 @@
+package pgn
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseClock(t *testing.T) {
+	tests := []struct {
+		comment string
+		want    time.Duration
+		ok      bool
+	}{
+		{"[%clk 0:09:57]", 9*time.Minute + 57*time.Second, true},
+		{"[%clk 0:09:59.9]", 9*time.Minute + 59900*time.Millisecond, true},
+		{"[%clk 1:30:00]", 90 * time.Minute, true},
+		{"[%eval 0.3] [%clk 0:00:05]", 5 * time.Second, true},
+		{"[%clk 2:05]", 2*time.Minute + 5*time.Second, true},
+		{"a normal comment", 0, false},
+		{"[%clk 0:xx:00]", 0, false},
+		{"[%clk 0:01:00", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := ParseClock(tt.comment)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("ParseClock(%q) = %v, %v; want %v, %v", tt.comment, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParseTimeControl(t *testing.T) {
+	tests := []struct {
+		tc        string
+		base, inc time.Duration
+		ok        bool
+	}{
+		{"600", 10 * time.Minute, 0, true},
+		{"180+2", 3 * time.Minute, 2 * time.Second, true},
+		{"1/86400", 0, 0, false},
+		{"-", 0, 0, false},
+		{"", 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		base, inc, ok := ParseTimeControl(tt.tc)
+		if ok != tt.ok || base != tt.base || inc != tt.inc {
+			t.Errorf("ParseTimeControl(%q) = %v, %v, %v; want %v, %v, %v", tt.tc, base, inc, ok, tt.base, tt.inc, tt.ok)
+		}
+	}
+}
+
+func TestMoveTimes(t *testing.T) {
+	pgnText := `[Event "Live Chess"]
+[Result "*"]
+[TimeControl "600+5"]
+
+1. e4 {[%clk 0:10:04]} 1... c6 {[%clk 0:09:50]} 2. d4 {[%clk 0:09:39]} 2... d5 {[%clk 0:09:55]} 3. e5 *`
+
+	db := &DB{}
+	if errs := db.Parse(pgnText); len(errs) > 0 {
+		t.Fatalf("unexpected parse errors: %v", errs)
+	}
+	game := db.Games[0]
+	if err := db.ParseMoves(game); err != nil {
+		t.Fatalf("unexpected error parsing moves: %v", err)
+	}
+
+	want := []MoveTime{
+		{Ply: 1, Color: 0, Clock: 10*time.Minute + 4*time.Second, Spent: 1 * time.Second},
+		{Ply: 2, Color: 1, Clock: 9*time.Minute + 50*time.Second, Spent: 15 * time.Second},
+		{Ply: 3, Color: 0, Clock: 9*time.Minute + 39*time.Second, Spent: 30 * time.Second},
+		{Ply: 4, Color: 1, Clock: 9*time.Minute + 55*time.Second, Spent: 0},
+	}
+	got := game.MoveTimes()
+	if len(got) != len(want) {
+		t.Fatalf("expected %d move times (stopping at the move without a clock), got %d", len(want), len(got))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("move %d: got %+v, want %+v", i+1, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package pgn
+
+import (
+	"strconv"
+	"strings"
+)
+
+// MateEval is the evaluation, in pawns, used for a forced mate. It matches the
+// value stored for mates in the positions table.
+const MateEval = 999.0
+
+// ParseEval extracts the engine evaluation from a comment containing a
+// "[%eval ...]" command, as written by Lichess. The evaluation is in pawns
+// from White's perspective; forced mates ("[%eval #-3]") are returned as
+// ±MateEval. A trailing search depth ("[%eval 0.25,30]") is ignored.
+func ParseEval(comment string) (float64, bool) {
+	i := strings.Index(comment, "[%eval ")
+	if i < 0 {
+		return 0, false
+	}
+	s := comment[i+len("[%eval "):]
+	end := strings.IndexByte(s, ']')
+	if end < 0 {
+		return 0, false
+	}
+	s = strings.TrimSpace(s[:end])
+	if j := strings.IndexByte(s, ','); j >= 0 {
+		s = s[:j]
+	}
+
+	if mate, ok := strings.CutPrefix(s, "#"); ok {
+		n, err := strconv.Atoi(mate)
+		if err != nil {
+			return 0, false
+		}
+		if n < 0 {
+			return -MateEval, true
+		}
+		return MateEval, true
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, false
+	}
+	return v, true
+}
+
+// Eval returns the evaluation of the position after the move, as recorded in
+// the move's comments.
+func (n *Node) Eval() (float64, bool) {
+	for _, c := range n.Comment {
+		if v, ok := ParseEval(c); ok {
+			return v, true
+		}
+	}
+	return 0, false
+}
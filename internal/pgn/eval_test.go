@@ -0,0 +1,27 @@
+package pgn
+
+import "testing"
+
+func TestParseEval(t *testing.T) {
+	tests := []struct {
+		comment string
+		want    float64
+		ok      bool
+	}{
+		{"[%eval 0.17]", 0.17, true},
+		{"[%eval -1.5] [%clk 0:03:00]", -1.5, true},
+		{"[%clk 0:03:00] [%eval 0.25,30]", 0.25, true},
+		{"[%eval #3]", MateEval, true},
+		{"[%eval #-2]", -MateEval, true},
+		{"a normal comment", 0, false},
+		{"[%eval abc]", 0, false},
+		{"[%eval 0.3", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := ParseEval(tt.comment)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("ParseEval(%q) = %v, %v; want %v, %v", tt.comment, got, ok, tt.want, tt.ok)
+		}
+	}
+}
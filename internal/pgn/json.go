@@ -0,0 +1,92 @@
+package pgn
+
+import "fmt"
+
+// GameJSON is the JSON form of a game: its tags and the moves of its main
+// line. Variations are not included.
+type GameJSON struct {
+	Tags     map[string]string `json:"tags"`
+	FEN      string            `json:"fen,omitempty"` // starting position, if not the standard one
+	Comments []string          `json:"comments,omitempty"`
+	Moves    []MoveJSON        `json:"moves"`
+}
+
+// MoveJSON is a move of a GameJSON, in both SAN and UCI notation.
+type MoveJSON struct {
+	SAN      string   `json:"san"`
+	UCI      string   `json:"uci"`
+	Comments []string `json:"comments,omitempty"`
+	Nags     []int    `json:"nags,omitempty"`
+}
+
+// JSON returns the game in its JSON form.
+func (g *Game) JSON() GameJSON {
+	j := GameJSON{
+		Tags:     make(map[string]string, len(g.Tags)),
+		Comments: g.Root.Comment,
+		Moves:    []MoveJSON{},
+	}
+	for name, value := range g.Tags {
+		if name != "FEN" && name != "SetUp" {
+			j.Tags[name] = value
+		}
+	}
+	if fen := g.Root.Board.Fen(); fen != startFEN {
+		j.FEN = fen
+	}
+	for n := g.Root.Next; n != nil; n = n.Next {
+		m := MoveJSON{
+			SAN:      n.Move.San(n.Parent.Board),
+			UCI:      n.Move.Uci(n.Parent.Board),
+			Comments: n.Comment,
+		}
+		for _, nag := range n.Nags {
+			m.Nags = append(m.Nags, int(nag))
+		}
+		j.Moves = append(j.Moves, m)
+	}
+	return j
+}
+
+// GameFromJSON builds a game from its JSON form. Moves are read from their
+// SAN, or from their UCI notation if the SAN is missing.
+func GameFromJSON(j GameJSON) (*Game, error) {
+	tags := make(map[string]string, len(j.Tags)+1)
+	for name, value := range j.Tags {
+		tags[name] = value
+	}
+	tags["FEN"] = startFEN
+	if j.FEN != "" {
+		tags["FEN"] = j.FEN
+		tags["SetUp"] = "1"
+	}
+	if tags["Result"] == "" {
+		tags["Result"] = "*"
+	}
+	g, err := NewGame(tags)
+	if err != nil {
+		return nil, err
+	}
+	g.Root.Comment = j.Comments
+
+	n := g.Root
+	for i, m := range j.Moves {
+		s := m.SAN
+		if s == "" {
+			s = m.UCI
+		}
+		mv, nag, err := n.Board.ParseAnnotatedMove(s)
+		if err != nil {
+			return nil, fmt.Errorf("move %d: illegal move %q: %w", i+1, s, err)
+		}
+		n = n.Insert(mv)
+		n.Comment = m.Comments
+		if nag != 0 {
+			n.AddNag(Nag(nag))
+		}
+		for _, nag := range m.Nags {
+			n.AddNag(Nag(nag))
+		}
+	}
+	return g, nil
+}
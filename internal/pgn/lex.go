@@ -201,6 +201,13 @@ func (l *lexer) item() item {
 		case '.':
 			l.acceptRun(".")
 			l.emit(itemDots)
+		case '-':
+			// a null move
+			if l.next() != '-' {
+				l.panicf("unexpected character: %#U", r)
+			}
+			l.acceptRun("+#")
+			l.emit(itemSymbol)
 		default:
 			if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') {
 				l.panicf("unexpected character: %#U", r)
@@ -207,11 +207,14 @@ func (p *parser) variation(node *Node, level int) {
 	for {
 		switch p.item.typ {
 		case itemSymbol: // a move
-			move, err := node.Board.ParseMove(p.item.val)
+			move, nag, err := node.Board.ParseAnnotatedMove(p.item.val)
 			if err != nil {
 				p.panicf("%q: %s", p.item.val, err)
 			}
 			node = node.Insert(move)
+			if nag != 0 {
+				node.AddNag(Nag(nag))
+			}
 		case itemComment:
 			node.Comment = append(node.Comment, unquote(p.item.val))
 		case itemAnnotation:
@@ -187,3 +187,26 @@ func TestChesscomClockNotation(t *testing.T) {
 		t.Errorf("Expected 3 moves with clock notation, got %d", moveCount)
 	}
 }
+
+func TestParseNullMovesAndAnnotations(t *testing.T) {
+	game := parseGame(t, "[Event \"Annotated\"]\n\n1. e4 -- 2. Nf3 Z0 3. Nc3!? e5?! 4. Bc4+ *")
+
+	var sans []string
+	var nags [][]Nag
+	for n := game.Root.Next; n != nil; n = n.Next {
+		sans = append(sans, n.Move.San(n.Parent.Board))
+		nags = append(nags, n.Nags)
+	}
+	if got, want := strings.Join(sans, " "), "e4 -- Nf3 -- Nc3 e5 Bc4"; got != want {
+		t.Errorf("moves = %q, want %q", got, want)
+	}
+	if len(nags[4]) != 1 || nags[4][0] != 5 || len(nags[5]) != 1 || nags[5][0] != 6 {
+		t.Errorf("NAGs = %v, want !? on Nc3 and ?! on e5", nags)
+	}
+
+	// The null moves survive writing the game out
+	again := parseGame(t, game.String())
+	if got, want := again.String(), game.String(); got != want {
+		t.Errorf("round trip =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,77 @@
+package pgn
+
+import "github.com/kyleboon/gochess/internal"
+
+// maxOpeningPieces is the most queens, rooks, bishops and knights left on
+// the board, of both sides, at which the opening is over.
+const maxOpeningPieces = 10
+
+// minDevelopedBackRank is the fewest pieces a side keeps on its back rank
+// while still in the opening.
+const minDevelopedBackRank = 4
+
+// Phases is how many plies of a game were played in each phase.
+type Phases struct {
+	Opening    int `json:"opening"`
+	Middlegame int `json:"middlegame"`
+	Endgame    int `json:"endgame"`
+}
+
+// Phases splits the main line of the game into its phases, see
+// SplitPhases.
+func (g *Game) Phases() Phases {
+	boards := []*internal.Board{g.Root.Board}
+	for n := g.Root.Next; n != nil; n = n.Next {
+		boards = append(boards, n.Board)
+	}
+	return SplitPhases(boards)
+}
+
+// SplitPhases splits a game, given by its positions from the first, into
+// its phases by a heuristic. The opening lasts until pieces are traded down
+// to ten or fewer queens, rooks, bishops and knights, or a side has fewer
+// than four pieces left on its back rank. The endgame starts at
+// the first position that is an endgame by internal.Board.Endgame, and
+// ends the opening too if it comes first. The middlegame is the rest.
+func SplitPhases(boards []*internal.Board) Phases {
+	if len(boards) == 0 {
+		return Phases{}
+	}
+	plies := len(boards) - 1
+	middlegame, endgame := plies, plies
+	for i, b := range boards {
+		if middlegame == plies && !inOpening(b) {
+			middlegame = i
+		}
+		if b.Endgame() != "" {
+			endgame = i
+			break
+		}
+	}
+	middlegame = min(middlegame, endgame)
+	return Phases{
+		Opening:    middlegame,
+		Middlegame: endgame - middlegame,
+		Endgame:    plies - endgame,
+	}
+}
+
+// inOpening reports whether b still looks like an opening position: most
+// pieces are on the board and both sides keep pieces on their back rank.
+func inOpening(b *internal.Board) bool {
+	pieces := 0
+	var backRank [2]int
+	for sq, p := range b.Piece {
+		if p.Type() == internal.NoPiece {
+			continue
+		}
+		if p.Type() != internal.Pawn && p.Type() != internal.King {
+			pieces++
+		}
+		if internal.Sq(sq).RelativeRank(p.Color()) == internal.Rank1 {
+			backRank[p.Color()]++
+		}
+	}
+	return pieces > maxOpeningPieces &&
+		backRank[internal.White] >= minDevelopedBackRank && backRank[internal.Black] >= minDevelopedBackRank
+}
@@ -0,0 +1,66 @@
+package pgn
+
+import "testing"
+
+func TestGamePhases(t *testing.T) {
+	tests := []struct {
+		name string
+		pgn  string
+		want Phases
+	}{
+		{
+			name: "no moves",
+			pgn:  `[Event "?"] *`,
+			want: Phases{},
+		},
+		{
+			name: "opening only",
+			pgn:  `[Event "?"] 1. e4 e5 2. Nf3 Nc6 *`,
+			want: Phases{Opening: 4},
+		},
+		{
+			// Black's back rank is down to three pieces after 7... Be6
+			name: "opening and middlegame",
+			pgn:  `[Event "?"] 1. e4 e5 2. Nf3 Nc6 3. Bc4 Bc5 4. d3 Nf6 5. Nc3 Qe7 6. Bg5 d6 7. O-O Be6 8. a3 *`,
+			want: Phases{Opening: 14, Middlegame: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &DB{}
+			if errs := db.Parse(tt.pgn); len(errs) > 0 {
+				t.Fatalf("Parse: %v", errs)
+			}
+			game := db.Games[0]
+			if err := db.ParseMoves(game); err != nil {
+				t.Fatalf("ParseMoves: %v", err)
+			}
+			if got := game.Phases(); got != tt.want {
+				t.Errorf("Phases() = %+v; want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGamePhasesEndgame(t *testing.T) {
+	// Few pieces are left from the start; the endgame starts once the
+	// queens come off
+	game, err := NewGame(map[string]string{"FEN": "r3k3/8/1n6/3q4/8/2B5/3Q4/R3K3 w - - 0 1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := game.Root
+	for _, san := range []string{"Qxd5", "Nxd5", "Bd2", "Nf6"} {
+		move, err := n.Board.ParseMove(san)
+		if err != nil {
+			t.Fatalf("ParseMove(%q): %v", san, err)
+		}
+		n = n.Insert(move)
+	}
+
+	want := Phases{Middlegame: 2, Endgame: 2}
+	if got := game.Phases(); got != want {
+		t.Errorf("Phases() = %+v; want %+v", got, want)
+	}
+}
@@ -0,0 +1,129 @@
+package pgn
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// Reader reads games one at a time from a PGN stream, so that files of any
+// size can be processed without loading them into memory whole.
+type Reader struct {
+	r           *bufio.Reader
+	line        int    // number of lines read
+	pending     string // first line of the next game, already read
+	pendingLine int
+	err         error // error that ended the stream
+}
+
+// NewReader returns a Reader reading PGN games from r.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReader(r)}
+}
+
+// Read returns the next game, with its moves parsed. It returns io.EOF once
+// all games have been read. A game that cannot be parsed is reported as a
+// *ParseError, after which reading continues with the next game.
+func (r *Reader) Read() (*Game, error) {
+	text, line, err := r.nextGame()
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{lex: newLexer(text, line)}
+	game, err := p.readGame()
+	if err != nil {
+		return nil, err
+	}
+	if game == nil {
+		return nil, io.EOF
+	}
+	var db DB
+	if err := db.ParseMoves(game); err != nil {
+		return nil, err
+	}
+	return game, nil
+}
+
+// ReadText returns the text of the next game as it appears in the stream,
+// without parsing it, for copying games between files unchanged. It returns
+// io.EOF once all games have been read.
+func (r *Reader) ReadText() (string, error) {
+	text, _, err := r.nextGame()
+	return text, err
+}
+
+// nextGame returns the text of the next game and the line it starts on. A
+// game ends where a tag line follows its movetext.
+func (r *Reader) nextGame() (text string, line int, err error) {
+	var b strings.Builder
+	tags, moves, comment := false, false, false
+	for {
+		s, n, err := r.readLine()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", 0, err
+		}
+		trimmed := strings.TrimSpace(s)
+		if !comment && strings.HasPrefix(trimmed, "[") {
+			if moves {
+				r.pending, r.pendingLine = s, n
+				break
+			}
+			tags = true
+		} else if trimmed != "" {
+			moves = moves || tags
+			comment = inComment(s, comment)
+		}
+		if b.Len() == 0 {
+			if trimmed == "" {
+				continue
+			}
+			line = n
+		}
+		b.WriteString(s)
+	}
+	if b.Len() == 0 {
+		return "", 0, io.EOF
+	}
+	return b.String(), line, nil
+}
+
+// readLine returns the next line, with its line ending, and its number.
+func (r *Reader) readLine() (string, int, error) {
+	if r.pending != "" {
+		s := r.pending
+		r.pending = ""
+		return s, r.pendingLine, nil
+	}
+	if r.err != nil {
+		return "", 0, r.err
+	}
+	s, err := r.r.ReadString('\n')
+	if err != nil {
+		r.err = err
+		if s == "" {
+			return "", 0, err
+		}
+	}
+	r.line++
+	return s, r.line, nil
+}
+
+// inComment reports whether a brace comment is still open at the end of
+// line, given whether one was open at its start. Rest-of-line comments
+// starting with a semicolon are skipped.
+func inComment(line string, open bool) bool {
+	for _, c := range line {
+		switch {
+		case open:
+			open = c != '}'
+		case c == '{':
+			open = true
+		case c == ';':
+			return false
+		}
+	}
+	return open
+}
@@ -0,0 +1,122 @@
+package pgn
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestReader(t *testing.T) {
+	text := `[Event "One"]
+[Result "1-0"]
+
+1. e4 e5 2. Qh5 Nc6 3. Bc4 Nf6 4. Qxf7# 1-0
+
+[Event "Two"]
+[Result "*"]
+
+1. d4 {a comment
+[that looks like a tag]} d5 *
+[Event "Three"]
+[Result "*"]
+
+1. e4 e4 *
+
+[Event "Four"]
+[Result "0-1"]
+
+1. f3 e5 2. g4 Qh4# 0-1
+`
+	r := NewReader(strings.NewReader(text))
+	var events []string
+	var failed int
+	for {
+		game, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var perr *ParseError
+			if !errors.As(err, &perr) {
+				t.Fatalf("Read: unexpected error %v", err)
+			}
+			failed++
+			continue
+		}
+		events = append(events, game.Tags["Event"])
+		if game.Root.Next == nil {
+			t.Errorf("game %q: moves not parsed", game.Tags["Event"])
+		}
+	}
+	if got := strings.Join(events, ","); got != "One,Two,Four" {
+		t.Errorf("events = %s, want One,Two,Four", got)
+	}
+	if failed != 1 {
+		t.Errorf("failed = %d, want 1", failed)
+	}
+}
+
+func TestReaderReadText(t *testing.T) {
+	text := `[Event "One"]
+
+1. e4 {a comment
+[that looks like a tag]} e5 *
+
+[Event "Two"]
+
+1. e4 e4 *
+`
+	r := NewReader(strings.NewReader(text))
+	var games []string
+	for {
+		game, err := r.ReadText()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("ReadText: unexpected error %v", err)
+		}
+		games = append(games, game)
+	}
+	if len(games) != 2 {
+		t.Fatalf("got %d games, want 2", len(games))
+	}
+	// Games are returned as written, the illegal one included
+	if want := "[Event \"One\"]\n\n1. e4 {a comment\n[that looks like a tag]} e5 *\n\n"; games[0] != want {
+		t.Errorf("game 1 = %q, want %q", games[0], want)
+	}
+	if want := "[Event \"Two\"]\n\n1. e4 e4 *\n"; games[1] != want {
+		t.Errorf("game 2 = %q, want %q", games[1], want)
+	}
+}
+
+func TestGameJSON(t *testing.T) {
+	game := parseGame(t, "[Event \"Club\"]\n[Result \"1-0\"]\n\n{Start} 1. e4 e5 2. Nf3! {Develops} Nc6 3. Bb5 1-0")
+	j := game.JSON()
+	if _, ok := j.Tags["FEN"]; ok {
+		t.Errorf("tags include the FEN of the standard starting position")
+	}
+	if j.FEN != "" {
+		t.Errorf("FEN = %q, want none", j.FEN)
+	}
+	if len(j.Moves) != 5 {
+		t.Fatalf("got %d moves, want 5", len(j.Moves))
+	}
+	if m := j.Moves[2]; m.SAN != "Nf3" || m.UCI != "g1f3" || len(m.Nags) != 1 || m.Comments[0] != "Develops" {
+		t.Errorf("move 3 = %+v", m)
+	}
+
+	again, err := GameFromJSON(j)
+	if err != nil {
+		t.Fatalf("GameFromJSON: %v", err)
+	}
+	if got, want := again.String(), game.String(); got != want {
+		t.Errorf("round trip =\n%s\nwant\n%s", got, want)
+	}
+
+	j.Moves[1].SAN = "e4"
+	if _, err := GameFromJSON(j); err == nil {
+		t.Errorf("GameFromJSON accepted an illegal move")
+	}
+}
@@ -0,0 +1,96 @@
+package pgn
+
+import (
+	"strings"
+
+	"github.com/kyleboon/gochess/internal"
+)
+
+// Result is the outcome of a game.
+type Result int
+
+const (
+	Unknown  Result = iota // the result could not be read
+	Ongoing                // the game is not over, "*" in PGN
+	WhiteWin               // "1-0"
+	BlackWin               // "0-1"
+	Draw                   // "1/2-1/2"
+)
+
+// ParseResult reads a result as written in the Result tag or at the end of
+// the movetext: "1-0", "0-1", "1/2-1/2" (or "½-½") and "*". Anything else
+// is Unknown.
+func ParseResult(s string) Result {
+	switch strings.TrimSpace(s) {
+	case "1-0":
+		return WhiteWin
+	case "0-1":
+		return BlackWin
+	case "1/2-1/2", "½-½":
+		return Draw
+	case "*":
+		return Ongoing
+	}
+	return Unknown
+}
+
+// chessComDraws are the results Chess.com reports for both players of a
+// drawn game.
+var chessComDraws = map[string]bool{
+	"agreed":             true,
+	"repetition":         true,
+	"stalemate":          true,
+	"insufficient":       true,
+	"50move":             true,
+	"timevsinsufficient": true,
+}
+
+// ParseChessComResult reads the result of a game from the results
+// Chess.com reports for its players: "win" for the winner and how the
+// loser lost ("checkmated", "timeout", "resigned", "abandoned", "lose"), or
+// how the game was drawn ("agreed", "repetition", "stalemate", ...) for
+// both. A game without results for either player is Ongoing.
+func ParseChessComResult(white, black string) Result {
+	switch {
+	case white == "" && black == "":
+		return Ongoing
+	case white == "win":
+		return WhiteWin
+	case black == "win":
+		return BlackWin
+	case chessComDraws[white] || chessComDraws[black]:
+		return Draw
+	}
+	return Unknown
+}
+
+// String returns the result as written in PGN. An Unknown result is
+// written as "*", like an ongoing game.
+func (r Result) String() string {
+	switch r {
+	case WhiteWin:
+		return "1-0"
+	case BlackWin:
+		return "0-1"
+	case Draw:
+		return "1/2-1/2"
+	}
+	return "*"
+}
+
+// Finished reports whether the game was won or drawn.
+func (r Result) Finished() bool {
+	return r == WhiteWin || r == BlackWin || r == Draw
+}
+
+// Score returns the points color scored: 1 for a win, 0.5 for a draw and 0
+// for a loss or an unfinished game.
+func (r Result) Score(color int) float64 {
+	switch {
+	case r == Draw:
+		return 0.5
+	case r == WhiteWin && color == internal.White, r == BlackWin && color == internal.Black:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,76 @@
+package pgn
+
+import (
+	"testing"
+
+	"github.com/kyleboon/gochess/internal"
+)
+
+func TestParseResult(t *testing.T) {
+	tests := []struct {
+		s    string
+		want Result
+	}{
+		{"1-0", WhiteWin},
+		{"0-1", BlackWin},
+		{"1/2-1/2", Draw},
+		{"½-½", Draw},
+		{"*", Ongoing},
+		{"", Unknown},
+		{"2-0", Unknown},
+	}
+
+	for _, tt := range tests {
+		if got := ParseResult(tt.s); got != tt.want {
+			t.Errorf("ParseResult(%q) = %v; want %v", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestParseChessComResult(t *testing.T) {
+	tests := []struct {
+		white, black string
+		want         Result
+	}{
+		{"win", "checkmated", WhiteWin},
+		{"timeout", "win", BlackWin},
+		{"resigned", "win", BlackWin},
+		{"agreed", "agreed", Draw},
+		{"timevsinsufficient", "timeout", Draw},
+		{"stalemate", "stalemate", Draw},
+		{"", "", Ongoing},
+		{"kingofthehill", "lose", Unknown},
+	}
+
+	for _, tt := range tests {
+		if got := ParseChessComResult(tt.white, tt.black); got != tt.want {
+			t.Errorf("ParseChessComResult(%q, %q) = %v; want %v", tt.white, tt.black, got, tt.want)
+		}
+	}
+}
+
+func TestResultScore(t *testing.T) {
+	tests := []struct {
+		r            Result
+		white, black float64
+		finished     bool
+	}{
+		{WhiteWin, 1, 0, true},
+		{BlackWin, 0, 1, true},
+		{Draw, 0.5, 0.5, true},
+		{Ongoing, 0, 0, false},
+		{Unknown, 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.r.Score(internal.White); got != tt.white {
+			t.Errorf("%v.Score(White) = %v; want %v", tt.r, got, tt.white)
+		}
+		if got := tt.r.Score(internal.Black); got != tt.black {
+			t.Errorf("%v.Score(Black) = %v; want %v", tt.r, got, tt.black)
+		}
+		if got := tt.r.Finished(); got != tt.finished {
+			t.Errorf("%v.Finished() = %v; want %v", tt.r, got, tt.finished)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package pgn
+
+import "strings"
+
+// Termination is how a game ended.
+type Termination int
+
+const (
+	TerminationUnknown   Termination = iota // the reason could not be read
+	Checkmate                               // a king was mated
+	Resignation                             // a player resigned
+	Timeout                                 // a player ran out of time
+	Abandonment                             // a player left the game
+	Agreement                               // the players agreed to a draw
+	Stalemate                               // the player to move had no legal move
+	Repetition                              // drawn by repetition of the position
+	InsufficientMaterial                    // neither side could mate
+	FiftyMoveRule                           // drawn by the 50 or 75-move rule
+)
+
+// Terminations lists the known terminations, in the order they are reported.
+var Terminations = []Termination{
+	Checkmate, Resignation, Timeout, Abandonment, Agreement,
+	Stalemate, Repetition, InsufficientMaterial, FiftyMoveRule,
+}
+
+var terminationNames = map[Termination]string{
+	Checkmate:            "checkmate",
+	Resignation:          "resignation",
+	Timeout:              "timeout",
+	Abandonment:          "abandonment",
+	Agreement:            "agreement",
+	Stalemate:            "stalemate",
+	Repetition:           "repetition",
+	InsufficientMaterial: "insufficient material",
+	FiftyMoveRule:        "50-move rule",
+}
+
+// terminationPhrases map the words of Termination tags to terminations.
+// They are matched in order, so that "drawn by timeout vs insufficient
+// material" is a timeout and "won - game abandoned" an abandonment.
+var terminationPhrases = []struct {
+	phrase      string
+	termination Termination
+}{
+	{"abandon", Abandonment},
+	{"checkmate", Checkmate},
+	{"resign", Resignation},
+	{"on time", Timeout},
+	{"time forfeit", Timeout},
+	{"timeout", Timeout},
+	{"agree", Agreement},
+	{"stalemate", Stalemate},
+	{"repetition", Repetition},
+	{"insufficient", InsufficientMaterial},
+	{"50-move", FiftyMoveRule},
+	{"50 move", FiftyMoveRule},
+	{"fifty-move", FiftyMoveRule},
+	{"75-move", FiftyMoveRule},
+}
+
+// ParseTermination reads the reason a game ended from its Termination tag,
+// as written by Chess.com ("Alice won on time", "Game drawn by agreement"),
+// Lichess ("Time forfeit", "Abandoned") or the play command ("checkmate",
+// "fivefold repetition"). Tags that do not say how the game ended, such as
+// Lichess's "Normal", are TerminationUnknown.
+func ParseTermination(tag string) Termination {
+	tag = strings.ToLower(tag)
+	for _, p := range terminationPhrases {
+		if strings.Contains(tag, p.phrase) {
+			return p.termination
+		}
+	}
+	return TerminationUnknown
+}
+
+// chessComTerminations are the results Chess.com reports for the loser of a
+// game, or for both players of a drawn game.
+var chessComTerminations = map[string]Termination{
+	"checkmated":         Checkmate,
+	"resigned":           Resignation,
+	"timeout":            Timeout,
+	"abandoned":          Abandonment,
+	"agreed":             Agreement,
+	"stalemate":          Stalemate,
+	"repetition":         Repetition,
+	"insufficient":       InsufficientMaterial,
+	"50move":             FiftyMoveRule,
+	"timevsinsufficient": Timeout,
+}
+
+// ParseChessComTermination reads how a game ended from the results
+// Chess.com reports for its players, see ParseChessComResult.
+func ParseChessComTermination(white, black string) Termination {
+	for _, result := range []string{white, black} {
+		if t, ok := chessComTerminations[result]; ok {
+			return t
+		}
+	}
+	return TerminationUnknown
+}
+
+// String returns the name of the termination, "unknown" if it is not known.
+func (t Termination) String() string {
+	if name, ok := terminationNames[t]; ok {
+		return name
+	}
+	return "unknown"
+}
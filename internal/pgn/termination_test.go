@@ -0,0 +1,54 @@
+package pgn
+
+import "testing"
+
+func TestParseTermination(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want Termination
+	}{
+		{"danpin won on time", Timeout},
+		{"Alice won by checkmate", Checkmate},
+		{"Bob won by resignation", Resignation},
+		{"Alice won - game abandoned", Abandonment},
+		{"Game drawn by agreement", Agreement},
+		{"Game drawn by stalemate", Stalemate},
+		{"Game drawn by repetition", Repetition},
+		{"Game drawn by insufficient material", InsufficientMaterial},
+		{"Game drawn by timeout vs insufficient material", Timeout},
+		{"Game drawn by 50-move rule", FiftyMoveRule},
+		{"Time forfeit", Timeout},
+		{"Abandoned", Abandonment},
+		{"fivefold repetition", Repetition},
+		{"75-move rule", FiftyMoveRule},
+		{"Normal", TerminationUnknown},
+		{"", TerminationUnknown},
+	}
+
+	for _, tt := range tests {
+		if got := ParseTermination(tt.tag); got != tt.want {
+			t.Errorf("ParseTermination(%q) = %v; want %v", tt.tag, got, tt.want)
+		}
+	}
+}
+
+func TestParseChessComTermination(t *testing.T) {
+	tests := []struct {
+		white, black string
+		want         Termination
+	}{
+		{"win", "checkmated", Checkmate},
+		{"timeout", "win", Timeout},
+		{"resigned", "win", Resignation},
+		{"win", "abandoned", Abandonment},
+		{"agreed", "agreed", Agreement},
+		{"timevsinsufficient", "timeout", Timeout},
+		{"", "", TerminationUnknown},
+	}
+
+	for _, tt := range tests {
+		if got := ParseChessComTermination(tt.white, tt.black); got != tt.want {
+			t.Errorf("ParseChessComTermination(%q, %q) = %v; want %v", tt.white, tt.black, got, tt.want)
+		}
+	}
+}
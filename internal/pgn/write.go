@@ -0,0 +1,142 @@
+package pgn
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kyleboon/gochess/internal"
+)
+
+// startFEN is the FEN of the standard starting position. Games starting from
+// it are written without a FEN tag.
+const startFEN = "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
+
+// sevenTagRoster lists the tags that come first in an exported game, in order.
+var sevenTagRoster = []string{"Event", "Site", "Date", "Round", "White", "Black", "Result"}
+
+// lineWidth is the maximum length of a movetext line.
+const lineWidth = 80
+
+// String returns the game in PGN export format: the seven tag roster first,
+// the other tags sorted by name, and the movetext, including comments, NAGs
+// and variations, wrapped at 80 columns.
+func (g *Game) String() string {
+	var b strings.Builder
+
+	tag := func(name, value string) {
+		value = strings.ReplaceAll(value, `\`, `\\`)
+		value = strings.ReplaceAll(value, `"`, `\"`)
+		fmt.Fprintf(&b, "[%s \"%s\"]\n", name, value)
+	}
+	for _, name := range sevenTagRoster {
+		value, ok := g.Tags[name]
+		if !ok {
+			value = "?"
+			if name == "Result" {
+				value = "*"
+			}
+		}
+		tag(name, value)
+	}
+	var names []string
+	for name := range g.Tags {
+		if !isRosterTag(name) && name != "FEN" && name != "SetUp" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		tag(name, g.Tags[name])
+	}
+	if fen := g.Root.Board.Fen(); fen != startFEN {
+		tag("SetUp", "1")
+		tag("FEN", fen)
+	}
+	b.WriteString("\n")
+
+	var tokens []string
+	writeVariation(&tokens, g.Root)
+	result := g.Tags["Result"]
+	if result == "" {
+		result = "*"
+	}
+	tokens = append(tokens, result)
+
+	// Parentheses hug the moves they enclose
+	var words []string
+	glue := false
+	for _, word := range strings.Fields(strings.Join(tokens, " ")) {
+		switch {
+		case word == ")" && len(words) > 0:
+			words[len(words)-1] += word
+		case glue:
+			words[len(words)-1] += word
+		default:
+			words = append(words, word)
+		}
+		glue = word == "("
+	}
+
+	width := 0
+	for _, word := range words {
+		if width > 0 && width+1+len(word) > lineWidth {
+			b.WriteString("\n")
+			width = 0
+		}
+		if width > 0 {
+			b.WriteString(" ")
+			width++
+		}
+		b.WriteString(word)
+		width += len(word)
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+// isRosterTag reports whether name is one of the seven tag roster.
+func isRosterTag(name string) bool {
+	for _, n := range sevenTagRoster {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// writeVariation appends the movetext tokens of the variation starting at
+// root.
+func writeVariation(tokens *[]string, root *Node) {
+	writeComments(tokens, root)
+	number := true // the next Black move needs a move number
+	for n := root.Next; n != nil; n = n.Next {
+		before := n.Parent.Board
+		switch {
+		case before.SideToMove == internal.White:
+			*tokens = append(*tokens, fmt.Sprintf("%d.", before.MoveNr))
+		case number:
+			*tokens = append(*tokens, fmt.Sprintf("%d...", before.MoveNr))
+		}
+		*tokens = append(*tokens, n.Move.San(before))
+		for _, nag := range n.Nags {
+			*tokens = append(*tokens, fmt.Sprintf("$%d", nag))
+		}
+		number = writeComments(tokens, n)
+		for _, v := range n.Variations() {
+			*tokens = append(*tokens, "(")
+			writeVariation(tokens, v)
+			*tokens = append(*tokens, ")")
+			number = true
+		}
+	}
+}
+
+// writeComments appends the comments of n, reporting whether there were any.
+func writeComments(tokens *[]string, n *Node) bool {
+	for _, c := range n.Comment {
+		// A comment cannot contain its closing brace
+		*tokens = append(*tokens, "{"+strings.ReplaceAll(c, "}", "")+"}")
+	}
+	return len(n.Comment) > 0
+}
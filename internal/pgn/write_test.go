@@ -0,0 +1,86 @@
+package pgn
+
+import (
+	"strings"
+	"testing"
+)
+
+func parseGame(t *testing.T, text string) *Game {
+	t.Helper()
+	db := &DB{}
+	if errs := db.Parse(text); len(errs) > 0 {
+		t.Fatalf("Parse: %v", errs)
+	}
+	if len(db.Games) != 1 {
+		t.Fatalf("expected 1 game, got %d", len(db.Games))
+	}
+	if err := db.ParseMoves(db.Games[0]); err != nil {
+		t.Fatalf("ParseMoves: %v", err)
+	}
+	return db.Games[0]
+}
+
+func TestGameString(t *testing.T) {
+	text := `[Black "Bob"]
+[White "Alice"]
+[Event "Club"]
+[ECO "C50"]
+[Result "1-0"]
+
+{Opening comment} 1. e4 e5 2. Nf3 Nc6 (2... d6 {Philidor} 3. d4 (3. Bc4) 3... Nf6) 3. Bc4! {[%eval 0.3]} Bc5?! 1-0`
+
+	want := `[Event "Club"]
+[Site "?"]
+[Date "?"]
+[Round "?"]
+[White "Alice"]
+[Black "Bob"]
+[Result "1-0"]
+[ECO "C50"]
+
+{Opening comment} 1. e4 e5 2. Nf3 Nc6 (2... d6 {Philidor} 3. d4 (3. Bc4) 3...
+Nf6) 3. Bc4 $1 {[%eval 0.3]} 3... Bc5 $6 1-0
+`
+	game := parseGame(t, text)
+	if got := game.String(); got != want {
+		t.Errorf("String() =\n%s\nwant\n%s", got, want)
+	}
+
+	// The output parses back to the same game
+	again := parseGame(t, game.String())
+	if got := again.String(); got != want {
+		t.Errorf("round trip =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestGameStringEdited(t *testing.T) {
+	game := parseGame(t, "[Event \"Edit\"]\n\n1. d4 d5 *")
+
+	d4 := game.Root.Next
+	d4.Comment = []string{"Queen's pawn"}
+	d4.AddNag(3)
+	c5, err := d4.Board.ParseMove("c5")
+	if err != nil {
+		t.Fatalf("ParseMove: %v", err)
+	}
+	d4.Next.NewVariation().Insert(c5)
+
+	got := game.String()
+	if !strings.HasSuffix(got, "\n1. d4 $3 {Queen's pawn} 1... d5 (1... c5) *\n") {
+		t.Errorf("unexpected movetext:\n%s", got)
+	}
+}
+
+func TestGameStringWraps(t *testing.T) {
+	var moves strings.Builder
+	for i := 0; i < 20; i++ {
+		moves.WriteString("Nf3 Nf6 Ng1 Ng8 ")
+	}
+	game := parseGame(t, "[Event \"Long\"]\n\n"+moves.String()+"*")
+
+	for _, line := range strings.Split(game.String(), "\n") {
+		if len(line) > lineWidth {
+			t.Errorf("line longer than %d: %q", lineWidth, line)
+		}
+	}
+}
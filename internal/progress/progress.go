@@ -0,0 +1,206 @@
+// Package progress draws progress bars with the rate and estimated time left
+// for long-running commands.
+package progress
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	barWidth = 30
+
+	// redrawInterval limits how often the bar is redrawn, so that fast
+	// operations don't spend their time writing to the terminal
+	redrawInterval = 100 * time.Millisecond
+)
+
+// Bar is a progress bar for an operation of a known number of steps. Text
+// written to the bar is printed above it, so that messages and the bar can
+// share the terminal.
+//
+// A nil *Bar is valid and draws nothing, which is what ForTerminal returns
+// when the output is not a terminal.
+type Bar struct {
+	mu       sync.Mutex
+	w        io.Writer
+	label    string
+	total    int
+	done     int
+	start    time.Time
+	lastDraw time.Time
+	drawn    bool
+	finished bool
+	now      func() time.Time
+}
+
+// New returns a bar drawn on w, for total steps
+func New(w io.Writer, total int, label string) *Bar {
+	b := &Bar{w: w, label: label, total: total, now: time.Now}
+	b.start = b.now()
+	return b
+}
+
+// ForTerminal returns a bar drawn on f if f is a terminal, and nil otherwise
+func ForTerminal(f *os.File, total int, label string) *Bar {
+	if !IsTerminal(f) {
+		return nil
+	}
+	return New(f, total, label)
+}
+
+// ForOutput returns a bar for a command whose messages go to w: a bar on
+// stdout if w is stdout and a terminal, and nil otherwise. Commands writing
+// JSON send their messages to stderr, so they get no bar.
+func ForOutput(w io.Writer, total int, label string) *Bar {
+	if w != io.Writer(os.Stdout) {
+		return nil
+	}
+	return ForTerminal(os.Stdout, total, label)
+}
+
+// IsTerminal reports whether f is a terminal
+func IsTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// Add advances the bar by n steps
+func (b *Bar) Add(n int) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.finished {
+		return
+	}
+	b.done += n
+	b.redraw(false)
+}
+
+// Set sets the number of steps done and the total, for operations that
+// report both
+func (b *Bar) Set(done, total int) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.finished {
+		return
+	}
+	b.done, b.total = done, total
+	b.redraw(false)
+}
+
+// Finish draws the final state of the bar and ends its line. A bar that
+// never had any steps is not drawn. Text written after Finish is passed
+// through.
+func (b *Bar) Finish() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.finished {
+		return
+	}
+	b.finished = true
+	if b.total == 0 && b.done == 0 {
+		b.clear()
+		return
+	}
+	b.redraw(true)
+	_, _ = fmt.Fprintln(b.w)
+	b.drawn = false
+}
+
+// Write prints p above the bar
+func (b *Bar) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.finished {
+		return b.w.Write(p)
+	}
+	b.clear()
+	n, err := b.w.Write(p)
+	if err != nil {
+		return n, err
+	}
+	b.redraw(true)
+	return n, nil
+}
+
+// Writer returns a writer that prints above the bar, or w for a nil bar
+func (b *Bar) Writer(w io.Writer) io.Writer {
+	if b == nil {
+		return w
+	}
+	return b
+}
+
+func (b *Bar) clear() {
+	if b.drawn {
+		_, _ = io.WriteString(b.w, "\r\033[K")
+		b.drawn = false
+	}
+}
+
+func (b *Bar) redraw(force bool) {
+	now := b.now()
+	if !force && b.drawn && now.Sub(b.lastDraw) < redrawInterval && b.done < b.total {
+		return
+	}
+	b.clear()
+	_, _ = io.WriteString(b.w, b.line(now))
+	b.drawn = true
+	b.lastDraw = now
+}
+
+// line renders the bar, e.g.
+// "months [=========>          ]  12/40  30%  3.2/s  ETA 0:08"
+func (b *Bar) line(now time.Time) string {
+	done, total := b.done, b.total
+	if total < done {
+		total = done
+	}
+	fraction := 1.0
+	if total > 0 {
+		fraction = float64(done) / float64(total)
+	}
+
+	filled := int(fraction * barWidth)
+	bar := strings.Repeat("=", filled)
+	if filled < barWidth {
+		bar += ">" + strings.Repeat(" ", barWidth-filled-1)
+	}
+
+	s := fmt.Sprintf("%s [%s] %d/%d %3.0f%%", b.label, bar, done, total, fraction*100)
+	elapsed := now.Sub(b.start)
+	if done > 0 && elapsed > 0 {
+		rate := float64(done) / elapsed.Seconds()
+		s += fmt.Sprintf("  %.1f/s", rate)
+		if done < total {
+			eta := time.Duration(float64(total-done) / rate * float64(time.Second))
+			s += "  ETA " + formatDuration(eta)
+		}
+	}
+	return s
+}
+
+// formatDuration formats d as m:ss, or h:mm:ss from an hour
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	h := int(d / time.Hour)
+	m := int(d % time.Hour / time.Minute)
+	s := int(d % time.Minute / time.Second)
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, m, s)
+	}
+	return fmt.Sprintf("%d:%02d", m, s)
+}
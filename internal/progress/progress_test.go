@@ -0,0 +1,73 @@
+package progress
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testBar returns a bar on buf whose clock advances by step on every read
+func testBar(buf *bytes.Buffer, total int, step time.Duration) *Bar {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	b := New(buf, total, "games")
+	b.now = func() time.Time {
+		now = now.Add(step)
+		return now
+	}
+	b.start = now
+	return b
+}
+
+func TestBarLine(t *testing.T) {
+	var buf bytes.Buffer
+	b := testBar(&buf, 40, time.Second)
+	b.Add(10)
+
+	line := buf.String()
+	assert.Contains(t, line, "games [")
+	assert.Contains(t, line, "10/40")
+	assert.Contains(t, line, "25%")
+	assert.Contains(t, line, "10.0/s")
+	assert.Contains(t, line, "ETA 0:03")
+}
+
+func TestBarWrite(t *testing.T) {
+	var buf bytes.Buffer
+	b := testBar(&buf, 2, time.Second)
+	b.Add(1)
+	_, err := b.Writer(nil).Write([]byte("Fetching 2024/01\n"))
+	assert.NoError(t, err)
+	b.Add(1)
+	b.Finish()
+
+	out := buf.String()
+	// The message clears the bar, and the bar is redrawn after it
+	msg := strings.Index(out, "\r\033[KFetching 2024/01\n")
+	assert.GreaterOrEqual(t, msg, 0, "message should clear the bar: %q", out)
+	assert.Contains(t, out[msg:], "2/2 100%")
+	assert.True(t, strings.HasSuffix(out, "\n"))
+
+	// After Finish, text is passed through
+	buf.Reset()
+	_, _ = b.Write([]byte("done\n"))
+	assert.Equal(t, "done\n", buf.String())
+}
+
+func TestNilBar(t *testing.T) {
+	var b *Bar
+	b.Add(1)
+	b.Set(1, 2)
+	b.Finish()
+
+	var buf bytes.Buffer
+	assert.Equal(t, &buf, b.Writer(&buf))
+}
+
+func TestFormatDuration(t *testing.T) {
+	assert.Equal(t, "0:07", formatDuration(7*time.Second))
+	assert.Equal(t, "2:05", formatDuration(125*time.Second))
+	assert.Equal(t, "1:00:01", formatDuration(time.Hour+time.Second))
+}
@@ -0,0 +1,62 @@
+// Package rating implements Elo rating arithmetic: expected scores, rating
+// changes and tournament performance ratings.
+package rating
+
+import (
+	"math"
+)
+
+// MaxDifference is the largest rating difference a performance rating is
+// allowed to differ from the average rating of the opponents, as for a score
+// of 0% or 100% under FIDE rules.
+const MaxDifference = 800
+
+// ExpectedScore returns the score a player rated a is expected to make
+// against one rated b in a single game, between 0 and 1.
+func ExpectedScore(a, b float64) float64 {
+	return 1 / (1 + math.Pow(10, (b-a)/400))
+}
+
+// Change returns the rating change for a player rated a who scored score
+// (1 for a win, 0.5 for a draw, 0 for a loss) against one rated b, with
+// development coefficient k.
+func Change(a, b, score, k float64) float64 {
+	return k * (score - ExpectedScore(a, b))
+}
+
+// Difference returns the rating difference at which the expected score is
+// fraction, the inverse of ExpectedScore. It is clamped to ±MaxDifference,
+// which is also the result for fractions of 0 and 1.
+func Difference(fraction float64) float64 {
+	if fraction <= 0 {
+		return -MaxDifference
+	}
+	if fraction >= 1 {
+		return MaxDifference
+	}
+	d := 400 * math.Log10(fraction/(1-fraction))
+	return math.Max(-MaxDifference, math.Min(MaxDifference, d))
+}
+
+// Performance returns the performance rating of a player who scored score
+// points against opponents with the given ratings: the average rating of
+// the opponents plus the rating difference the score fraction implies. It
+// returns 0 when there are no opponents.
+func Performance(opponents []float64, score float64) float64 {
+	if len(opponents) == 0 {
+		return 0
+	}
+	return Average(opponents) + Difference(score/float64(len(opponents)))
+}
+
+// Average returns the average of ratings, or 0 if there are none.
+func Average(ratings []float64) float64 {
+	if len(ratings) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, r := range ratings {
+		sum += r
+	}
+	return sum / float64(len(ratings))
+}
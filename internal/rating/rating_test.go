@@ -0,0 +1,69 @@
+package rating
+
+import (
+	"math"
+	"testing"
+)
+
+func TestExpectedScore(t *testing.T) {
+	tests := []struct {
+		a, b     float64
+		expected float64
+	}{
+		{1500, 1500, 0.5},
+		{1500, 1650, 0.2966},
+		{1650, 1500, 0.7034},
+		{2000, 1600, 0.9091},
+	}
+
+	for _, tt := range tests {
+		got := ExpectedScore(tt.a, tt.b)
+		if math.Abs(got-tt.expected) > 0.0001 {
+			t.Errorf("ExpectedScore(%v, %v) = %.4f, expected %.4f", tt.a, tt.b, got, tt.expected)
+		}
+	}
+}
+
+func TestChange(t *testing.T) {
+	// A win against an equal opponent gains half of k
+	if got := Change(1500, 1500, 1, 20); got != 10 {
+		t.Errorf("expected +10, got %v", got)
+	}
+	// A draw against a stronger opponent gains rating
+	if got := Change(1500, 1650, 0.5, 20); got <= 0 {
+		t.Errorf("expected a gain for a draw against a stronger player, got %v", got)
+	}
+}
+
+func TestDifference(t *testing.T) {
+	if got := Difference(0.5); got != 0 {
+		t.Errorf("expected 0 for an even score, got %v", got)
+	}
+	if got := Difference(1); got != MaxDifference {
+		t.Errorf("expected %d for a perfect score, got %v", MaxDifference, got)
+	}
+	if got := Difference(0); got != -MaxDifference {
+		t.Errorf("expected -%d for a zero score, got %v", MaxDifference, got)
+	}
+	// Difference is the inverse of ExpectedScore
+	if got := ExpectedScore(1500+Difference(0.75), 1500); math.Abs(got-0.75) > 1e-9 {
+		t.Errorf("expected an expected score of 0.75, got %v", got)
+	}
+}
+
+func TestPerformance(t *testing.T) {
+	opponents := []float64{1400, 1500, 1600}
+
+	if got := Performance(opponents, 1.5); got != 1500 {
+		t.Errorf("expected 1500 for a 50%% score, got %v", got)
+	}
+	if got := Performance(opponents, 3); got != 2300 {
+		t.Errorf("expected 2300 for a perfect score, got %v", got)
+	}
+	if got := Performance(opponents, 2); math.Round(got) != 1620 {
+		t.Errorf("expected 1620 for 2/3, got %v", got)
+	}
+	if got := Performance(nil, 0); got != 0 {
+		t.Errorf("expected 0 with no opponents, got %v", got)
+	}
+}
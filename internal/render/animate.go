@@ -0,0 +1,169 @@
+package render
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"image/png"
+	"io"
+	"time"
+
+	"github.com/kyleboon/gochess/internal"
+	"github.com/kyleboon/gochess/internal/pgn"
+)
+
+// finalFrameHold is how many times longer than the others the last frame of
+// an animation is shown, so the final position can be seen before it loops.
+const finalFrameHold = 3
+
+// Frame is a position of an animation and the move that led to it.
+type Frame struct {
+	Board    *internal.Board
+	LastMove internal.Move // NullMove for the starting position
+}
+
+// GameFrames returns the positions of the main line of game, starting with
+// its initial position.
+func GameFrames(game *pgn.Game) []Frame {
+	frames := []Frame{{Board: game.Root.Board, LastMove: internal.NullMove}}
+	for n := game.Root.Next; n != nil; n = n.Next {
+		frames = append(frames, Frame{Board: n.Board, LastMove: n.Move})
+	}
+	return frames
+}
+
+// frameImages draws each frame with opts, highlighting its last move.
+func frameImages(frames []Frame, opts Options) []*image.RGBA {
+	images := make([]*image.RGBA, len(frames))
+	for i, f := range frames {
+		opts.LastMove = f.LastMove
+		images[i] = Image(f.Board, opts)
+	}
+	return images
+}
+
+// frameDelays returns how long each of n frames is shown.
+func frameDelays(n int, delay time.Duration) []time.Duration {
+	delays := make([]time.Duration, n)
+	for i := range delays {
+		delays[i] = delay
+	}
+	if n > 0 {
+		delays[n-1] = finalFrameHold * delay
+	}
+	return delays
+}
+
+// palette holds every color Image draws with.
+var palette = color.Palette{
+	lightSquare, darkSquare, lightHighlight, darkHighlight,
+	whitePieceColor, blackPieceColor, outlineColor,
+}
+
+// GIF writes an animated GIF of frames to w, showing each for delay and
+// looping forever.
+func GIF(w io.Writer, frames []Frame, opts Options, delay time.Duration) error {
+	if len(frames) == 0 {
+		return fmt.Errorf("no positions to animate")
+	}
+	anim := &gif.GIF{}
+	delays := frameDelays(len(frames), delay)
+	for i, img := range frameImages(frames, opts) {
+		p := image.NewPaletted(img.Bounds(), palette)
+		draw.Draw(p, p.Bounds(), img, image.Point{}, draw.Src)
+		anim.Image = append(anim.Image, p)
+		anim.Delay = append(anim.Delay, int(delays[i]/(10*time.Millisecond)))
+	}
+	return gif.EncodeAll(w, anim)
+}
+
+// APNG writes an animated PNG of frames to w, showing each for delay and
+// looping forever. Viewers without APNG support show the first frame.
+func APNG(w io.Writer, frames []Frame, opts Options, delay time.Duration) error {
+	if len(frames) == 0 {
+		return fmt.Errorf("no positions to animate")
+	}
+	delays := frameDelays(len(frames), delay)
+	images := frameImages(frames, opts)
+	bounds := images[0].Bounds()
+
+	var out bytes.Buffer
+	out.WriteString("\x89PNG\r\n\x1a\n")
+	seq := uint32(0)
+	for i, img := range images {
+		// Each frame is encoded as a PNG of its own, whose image data is
+		// moved into the animation
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, img); err != nil {
+			return err
+		}
+		chunks, err := pngChunks(buf.Bytes())
+		if err != nil {
+			return err
+		}
+		if i == 0 {
+			writeChunk(&out, "IHDR", chunks["IHDR"])
+			actl := make([]byte, 8)
+			binary.BigEndian.PutUint32(actl[0:], uint32(len(images)))
+			binary.BigEndian.PutUint32(actl[4:], 0) // loop forever
+			writeChunk(&out, "acTL", actl)
+		}
+
+		fctl := make([]byte, 26)
+		binary.BigEndian.PutUint32(fctl[0:], seq)
+		binary.BigEndian.PutUint32(fctl[4:], uint32(bounds.Dx()))
+		binary.BigEndian.PutUint32(fctl[8:], uint32(bounds.Dy()))
+		binary.BigEndian.PutUint16(fctl[20:], uint16(min(delays[i].Milliseconds(), 0xffff)))
+		binary.BigEndian.PutUint16(fctl[22:], 1000)
+		writeChunk(&out, "fcTL", fctl)
+		seq++
+
+		if i == 0 {
+			writeChunk(&out, "IDAT", chunks["IDAT"])
+			continue
+		}
+		fdat := make([]byte, 4, 4+len(chunks["IDAT"]))
+		binary.BigEndian.PutUint32(fdat, seq)
+		writeChunk(&out, "fdAT", append(fdat, chunks["IDAT"]...))
+		seq++
+	}
+	writeChunk(&out, "IEND", nil)
+	_, err := w.Write(out.Bytes())
+	return err
+}
+
+// pngChunks returns the data of the chunks of a PNG file by type. The data
+// of repeated chunks, such as IDAT, is joined.
+func pngChunks(data []byte) (map[string][]byte, error) {
+	chunks := make(map[string][]byte)
+	data = data[8:] // signature
+	for len(data) >= 12 {
+		n := binary.BigEndian.Uint32(data)
+		if int(n) > len(data)-12 {
+			return nil, fmt.Errorf("truncated PNG chunk")
+		}
+		typ := string(data[4:8])
+		chunks[typ] = append(chunks[typ], data[8:8+n]...)
+		data = data[12+n:]
+	}
+	return chunks, nil
+}
+
+// writeChunk writes a PNG chunk of type typ holding data to buf.
+func writeChunk(buf *bytes.Buffer, typ string, data []byte) {
+	var n [4]byte
+	binary.BigEndian.PutUint32(n[:], uint32(len(data)))
+	buf.Write(n[:])
+	crc := crc32.NewIEEE()
+	crc.Write([]byte(typ))
+	crc.Write(data)
+	buf.WriteString(typ)
+	buf.Write(data)
+	binary.BigEndian.PutUint32(n[:], crc.Sum32())
+	buf.Write(n[:])
+}
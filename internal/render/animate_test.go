@@ -0,0 +1,71 @@
+package render
+
+import (
+	"bytes"
+	"image/gif"
+	"image/png"
+	"testing"
+	"time"
+
+	"github.com/kyleboon/gochess/internal/pgn"
+)
+
+func testFrames(t *testing.T) []Frame {
+	t.Helper()
+	db := &pgn.DB{}
+	if errs := db.Parse("[Result \"*\"]\n\n1. e4 e5 2. Nf3 *\n"); len(errs) > 0 {
+		t.Fatal(errs[0])
+	}
+	if err := db.ParseMoves(db.Games[0]); err != nil {
+		t.Fatal(err)
+	}
+	return GameFrames(db.Games[0])
+}
+
+func TestGIF(t *testing.T) {
+	frames := testFrames(t)
+	if len(frames) != 4 {
+		t.Fatalf("got %d frames, want 4", len(frames))
+	}
+	var buf bytes.Buffer
+	if err := GIF(&buf, frames, Options{SquareSize: 8}, 500*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	anim, err := gif.DecodeAll(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(anim.Image) != 4 {
+		t.Fatalf("got %d images, want 4", len(anim.Image))
+	}
+	if anim.Delay[0] != 50 || anim.Delay[3] != 50*finalFrameHold {
+		t.Errorf("got delays %v", anim.Delay)
+	}
+	if b := anim.Image[0].Bounds(); b.Dx() != 64 {
+		t.Errorf("image is %d pixels wide, want 64", b.Dx())
+	}
+}
+
+func TestAPNG(t *testing.T) {
+	var buf bytes.Buffer
+	if err := APNG(&buf, testFrames(t), Options{SquareSize: 8}, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	chunks, err := pngChunks(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, typ := range []string{"IHDR", "acTL", "fcTL", "IDAT", "fdAT", "IEND"} {
+		if _, ok := chunks[typ]; !ok {
+			t.Errorf("missing %s chunk", typ)
+		}
+	}
+	// Decoders without APNG support see the first frame
+	img, err := png.Decode(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != 64 {
+		t.Errorf("image is %d pixels wide, want 64", b.Dx())
+	}
+}
@@ -0,0 +1,191 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+
+	"github.com/kyleboon/gochess/internal"
+)
+
+// pieceMasks are 16x16 silhouettes of the pieces, '#' marking the cells they
+// cover. They are scaled up to the square size and drawn with an outline.
+var pieceMasks = map[int][16]string{
+	internal.Pawn: {
+		"................",
+		"................",
+		"................",
+		"......####......",
+		".....######.....",
+		".....######.....",
+		"......####......",
+		".....######.....",
+		"......####......",
+		"......####......",
+		".....######.....",
+		"....########....",
+		"...##########...",
+		"...##########...",
+		"................",
+		"................",
+	},
+	internal.Knight: {
+		"................",
+		"................",
+		".......##.......",
+		"......#####.....",
+		".....#######....",
+		"....#########...",
+		"...####.#####...",
+		"..#####.######..",
+		"..###..#######..",
+		".......#######..",
+		"......#######...",
+		".....#######....",
+		"....#########...",
+		"...###########..",
+		"...###########..",
+		"................",
+	},
+	internal.Bishop: {
+		"................",
+		".......##.......",
+		"......####......",
+		".....##.###.....",
+		".....#.####.....",
+		".....######.....",
+		"......####......",
+		".......##.......",
+		"......####......",
+		".....######.....",
+		"......####......",
+		"......####......",
+		"....########....",
+		"...##########...",
+		"...##########...",
+		"................",
+	},
+	internal.Rook: {
+		"................",
+		"................",
+		"...##..##..##...",
+		"...##########...",
+		"...##########...",
+		"....########....",
+		".....######.....",
+		".....######.....",
+		".....######.....",
+		".....######.....",
+		"....########....",
+		"...##########...",
+		"..############..",
+		"..############..",
+		"................",
+		"................",
+	},
+	internal.Queen: {
+		"................",
+		"..#..#....#..#..",
+		"..#..##..##..#..",
+		"..##.##..##.##..",
+		"..############..",
+		"...##########...",
+		"...##########...",
+		"....########....",
+		".....######.....",
+		".....######.....",
+		"....########....",
+		"...##########...",
+		"..############..",
+		"..############..",
+		"................",
+		"................",
+	},
+	internal.King: {
+		"................",
+		".......##.......",
+		"......####......",
+		".......##.......",
+		"..####.##.####..",
+		".##############.",
+		".##############.",
+		".##############.",
+		"..############..",
+		"...##########...",
+		"....########....",
+		"....########....",
+		"...##########...",
+		"..############..",
+		"..############..",
+		"................",
+	},
+}
+
+// maskSize is the width and height of the piece masks.
+const maskSize = 16
+
+// Image returns a raster image of board, for PNG files and animations.
+// Coordinates are not drawn.
+func Image(b *internal.Board, opts Options) *image.RGBA {
+	size := opts.squareSize()
+	img := image.NewRGBA(image.Rect(0, 0, 8*size, 8*size))
+	for row := 0; row < 8; row++ {
+		for col := 0; col < 8; col++ {
+			sq := opts.squareAt(col, row)
+			fill(img, image.Rect(col*size, row*size, (col+1)*size, (row+1)*size), opts.squareColor(sq))
+			if p := b.Piece[sq]; p != internal.NoPiece {
+				drawPiece(img, p, col*size, row*size, size)
+			}
+		}
+	}
+	return img
+}
+
+// PNG writes a PNG image of board to w.
+func PNG(w io.Writer, b *internal.Board, opts Options) error {
+	return png.Encode(w, Image(b, opts))
+}
+
+// fill paints rectangle r of img in c.
+func fill(img *image.RGBA, r image.Rectangle, c color.RGBA) {
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			img.SetRGBA(x, y, c)
+		}
+	}
+}
+
+// drawPiece draws piece p centered in the square of the given size whose top
+// left corner is (x0, y0).
+func drawPiece(img *image.RGBA, p internal.Piece, x0, y0, size int) {
+	mask := pieceMasks[p.Type()]
+	scale := max(1, size/maskSize)
+	offset := (size - maskSize*scale) / 2
+	line := max(1, scale/3) // outline width in pixels
+	body := whitePieceColor
+	if p.Color() == internal.Black {
+		body = blackPieceColor
+	}
+
+	// inside reports whether pixel (x, y) of the scaled mask is covered
+	inside := func(x, y int) bool {
+		if x < 0 || y < 0 {
+			return false
+		}
+		row, col := y/scale, x/scale
+		return row < maskSize && col < maskSize && mask[row][col] == '#'
+	}
+	for y := 0; y < maskSize*scale; y++ {
+		for x := 0; x < maskSize*scale; x++ {
+			if !inside(x, y) {
+				continue
+			}
+			c := body
+			if !inside(x-line, y) || !inside(x+line, y) || !inside(x, y-line) || !inside(x, y+line) {
+				c = outlineColor
+			}
+			img.SetRGBA(x0+offset+x, y0+offset+y, c)
+		}
+	}
+}
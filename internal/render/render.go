@@ -0,0 +1,92 @@
+// Package render draws chess positions as images: SVG for documents and web
+// pages, and raster images for PNG files and animations.
+package render
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/kyleboon/gochess/internal"
+)
+
+// DefaultSquareSize is the size of a square in pixels when none is given.
+const DefaultSquareSize = 48
+
+// Options controls how a board is drawn.
+type Options struct {
+	Flipped         bool          // Black at the bottom
+	LastMove        internal.Move // squares to highlight; NullMove for none
+	SquareSize      int           // in pixels; DefaultSquareSize if zero
+	HideCoordinates bool          // leave out the file and rank labels (SVG only)
+}
+
+// Board colors
+var (
+	lightSquare     = color.RGBA{0xf0, 0xd9, 0xb5, 0xff}
+	darkSquare      = color.RGBA{0xb5, 0x88, 0x63, 0xff}
+	lightHighlight  = color.RGBA{0xf7, 0xec, 0x74, 0xff}
+	darkHighlight   = color.RGBA{0xda, 0xc3, 0x4b, 0xff}
+	whitePieceColor = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	blackPieceColor = color.RGBA{0x22, 0x22, 0x22, 0xff}
+	outlineColor    = color.RGBA{0x00, 0x00, 0x00, 0xff}
+)
+
+// squareSize returns the size of a square in pixels.
+func (o Options) squareSize() int {
+	if o.SquareSize <= 0 {
+		return DefaultSquareSize
+	}
+	return o.SquareSize
+}
+
+// squareAt returns the square drawn in column col and row row, counted from
+// the top left corner.
+func (o Options) squareAt(col, row int) internal.Sq {
+	if o.Flipped {
+		return internal.Square(7-col, row)
+	}
+	return internal.Square(col, 7-row)
+}
+
+// highlighted reports whether sq is a square of the last move.
+func (o Options) highlighted(sq internal.Sq) bool {
+	if o.LastMove == internal.NullMove {
+		return false
+	}
+	return sq == o.LastMove.From || sq == o.LastMove.To
+}
+
+// squareColor returns the color sq is drawn in.
+func (o Options) squareColor(sq internal.Sq) color.RGBA {
+	light := sq.Color() == internal.White
+	switch {
+	case o.highlighted(sq) && light:
+		return lightHighlight
+	case o.highlighted(sq):
+		return darkHighlight
+	case light:
+		return lightSquare
+	}
+	return darkSquare
+}
+
+// hex formats c as an HTML color.
+func hex(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// ParseSquares parses a move given by its squares, as in "e2e4", for
+// highlighting. The move is not checked against a position.
+func ParseSquares(s string) (internal.Move, error) {
+	square := func(s string) internal.Sq {
+		return internal.Square(int(s[0])-'a', int(s[1])-'1')
+	}
+	if len(s) < 4 {
+		return internal.NullMove, fmt.Errorf("invalid move %q (expected squares, e.g. e2e4)", s)
+	}
+	mv := internal.Move{From: square(s[:2]), To: square(s[2:4])}
+	if mv.From == internal.NoSquare || mv.To == internal.NoSquare {
+		return internal.NullMove, fmt.Errorf("invalid move %q (expected squares, e.g. e2e4)", s)
+	}
+	return mv, nil
+}
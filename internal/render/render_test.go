@@ -0,0 +1,84 @@
+package render
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/kyleboon/gochess/internal"
+)
+
+const startFEN = "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
+
+func TestSVG(t *testing.T) {
+	board, err := internal.ParseFen(startFEN)
+	if err != nil {
+		t.Fatal(err)
+	}
+	svg := SVG(board, Options{LastMove: internal.Move{From: internal.G1, To: internal.F3}})
+	if !strings.HasPrefix(svg, "<svg ") || !strings.HasSuffix(svg, "</svg>\n") {
+		t.Fatalf("not an SVG document:\n%s", svg)
+	}
+	if n := strings.Count(svg, "♚"); n != 2 {
+		t.Errorf("got %d kings, want 2", n)
+	}
+	if n := strings.Count(svg, "♟"); n != 16 {
+		t.Errorf("got %d pawns, want 16", n)
+	}
+	// g1 is dark and f3 light
+	if !strings.Contains(svg, hex(lightHighlight)) || !strings.Contains(svg, hex(darkHighlight)) {
+		t.Error("last move is not highlighted")
+	}
+	if !strings.Contains(svg, ">a</text>") {
+		t.Error("coordinates are missing")
+	}
+
+	svg = SVG(board, Options{HideCoordinates: true, SquareSize: 10})
+	if strings.Contains(svg, ">a</text>") {
+		t.Error("coordinates are drawn although hidden")
+	}
+	if !strings.Contains(svg, `width="80"`) {
+		t.Error("board is not 8 squares wide")
+	}
+}
+
+func TestSquareAt(t *testing.T) {
+	tests := []struct {
+		flipped  bool
+		col, row int
+		want     string
+	}{
+		{false, 0, 0, "a8"},
+		{false, 7, 7, "h1"},
+		{true, 0, 0, "h1"},
+		{true, 7, 7, "a8"},
+	}
+	for _, tt := range tests {
+		if got := (Options{Flipped: tt.flipped}).squareAt(tt.col, tt.row).String(); got != tt.want {
+			t.Errorf("squareAt(%d, %d) flipped=%v = %s, want %s", tt.col, tt.row, tt.flipped, got, tt.want)
+		}
+	}
+}
+
+func TestPNG(t *testing.T) {
+	board, err := internal.ParseFen("8/8/8/4k3/8/8/8/4K3 w - - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := PNG(&buf, board, Options{SquareSize: 16}); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != 128 || b.Dy() != 128 {
+		t.Fatalf("image is %dx%d, want 128x128", b.Dx(), b.Dy())
+	}
+	// The top left corner of a8, which is light and empty
+	if r, g, b, _ := img.At(0, 0).RGBA(); r>>8 != uint32(lightSquare.R) || g>>8 != uint32(lightSquare.G) || b>>8 != uint32(lightSquare.B) {
+		t.Errorf("a8 has color %02x%02x%02x, want %s", r>>8, g>>8, b>>8, hex(lightSquare))
+	}
+}
@@ -0,0 +1,69 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kyleboon/gochess/internal"
+)
+
+// svgGlyphs are the chess symbols drawn for each piece type. The solid
+// symbols are used for both colors, and filled with the piece color.
+var svgGlyphs = map[int]string{
+	internal.Pawn:   "♟",
+	internal.Knight: "♞",
+	internal.Bishop: "♝",
+	internal.Rook:   "♜",
+	internal.Queen:  "♛",
+	internal.King:   "♚",
+}
+
+// SVG returns an SVG image of board.
+func SVG(b *internal.Board, opts Options) string {
+	size := opts.squareSize()
+	margin := 0
+	if !opts.HideCoordinates {
+		margin = size / 3
+	}
+	width := 8*size + margin
+
+	var s strings.Builder
+	fmt.Fprintf(&s, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n",
+		width, width, width, width)
+	fmt.Fprintf(&s, `<rect width="%d" height="%d" fill="#ffffff"/>`+"\n", width, width)
+
+	for row := 0; row < 8; row++ {
+		for col := 0; col < 8; col++ {
+			sq := opts.squareAt(col, row)
+			x, y := margin+col*size, row*size
+			fmt.Fprintf(&s, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s"/>`+"\n",
+				x, y, size, size, hex(opts.squareColor(sq)))
+
+			p := b.Piece[sq]
+			if p == internal.NoPiece {
+				continue
+			}
+			fill := whitePieceColor
+			if p.Color() == internal.Black {
+				fill = blackPieceColor
+			}
+			fmt.Fprintf(&s, `<text x="%d" y="%d" font-size="%d" text-anchor="middle" dominant-baseline="central" `+
+				`fill="%s" stroke="%s" stroke-width="%.1f" font-family="DejaVu Sans, Segoe UI Symbol, sans-serif">%s</text>`+"\n",
+				x+size/2, y+size/2, size*7/8, hex(fill), hex(outlineColor), float64(size)/48, svgGlyphs[p.Type()])
+		}
+	}
+
+	if !opts.HideCoordinates {
+		label := func(x, y int, text string) {
+			fmt.Fprintf(&s, `<text x="%d" y="%d" font-size="%d" text-anchor="middle" dominant-baseline="central" `+
+				`fill="#555555" font-family="sans-serif">%s</text>`+"\n", x, y, margin*3/4, text)
+		}
+		for i := 0; i < 8; i++ {
+			sq := opts.squareAt(i, i)
+			label(margin+i*size+size/2, 8*size+margin/2, string(rune('a'+sq.File())))
+			label(margin/2, i*size+size/2, string(rune('1'+sq.Rank())))
+		}
+	}
+	s.WriteString("</svg>\n")
+	return s.String()
+}
@@ -0,0 +1,128 @@
+package internal
+
+// Piece values in centipawns, indexed by piece type. The king is given a
+// prohibitive value so that static exchange evaluation never lets it capture
+// onto a defended square.
+var pieceValues = [...]int{
+	NoPiece: 0,
+	Pawn:    100,
+	Knight:  300,
+	Bishop:  300,
+	Rook:    500,
+	Queen:   900,
+	King:    20000,
+}
+
+// PieceValue returns the material value of the given piece type (Pawn,
+// Knight, ...) in centipawns.
+func PieceValue(pieceType int) int {
+	if pieceType < 0 || pieceType >= len(pieceValues) {
+		return 0
+	}
+	return pieceValues[pieceType]
+}
+
+// SEE returns the static exchange evaluation of move m: the material balance,
+// in centipawns from the mover's point of view, after all captures on the
+// destination square have been played out with the least valuable attacker
+// first. Either side may stop capturing when continuing would lose material.
+// A negative value means the move gives up material. Castling moves evaluate
+// to 0.
+func (b *Board) SEE(m Move) int {
+	if m == NullMove {
+		return 0
+	}
+	pieces := b.Piece
+	moving := pieces[m.From]
+	if moving == NoPiece || pieces[m.To] != NoPiece && pieces[m.To].Color() == moving.Color() {
+		return 0 // castling (king takes own rook) or an invalid move
+	}
+
+	gain := PieceValue(pieces[m.To].Type())
+	if moving.Type() == Pawn && m.To == b.EpSquare {
+		gain = PieceValue(Pawn)
+		pieces[Square(m.To.File(), m.From.Rank())] = NoPiece
+	}
+	if m.Promotion != NoPiece {
+		gain += PieceValue(m.Promotion.Type()) - PieceValue(Pawn)
+		moving = m.Promotion
+	}
+	pieces[m.From] = NoPiece
+	pieces[m.To] = moving
+
+	return gain - seeSquare(&pieces, m.To, moving.Color()^1)
+}
+
+// seeSquare returns the best material gain the given side can achieve by
+// capturing on sq, or 0 if it is better not to capture at all. pieces is
+// modified.
+func seeSquare(pieces *[64]Piece, sq Sq, side int) int {
+	from := leastValuableAttacker(pieces, sq, side)
+	if from == NoSquare {
+		return 0
+	}
+	captured := pieces[sq]
+	pieces[sq] = pieces[from]
+	pieces[from] = NoPiece
+	gain := PieceValue(captured.Type()) - seeSquare(pieces, sq, side^1)
+	if gain < 0 {
+		return 0
+	}
+	return gain
+}
+
+// leastValuableAttacker returns the square of the least valuable piece of the
+// given color attacking sq, or NoSquare if sq is not attacked.
+func leastValuableAttacker(pieces *[64]Piece, sq Sq, color int) Sq {
+	// pawns attack from the rank behind them
+	pawnOffsets := [2][2]int{{-7, -9}, {7, 9}}[color]
+	for _, offset := range pawnOffsets {
+		if from := sq.step(offset); from != NoSquare && pieces[from] == Piece(color|Pawn) {
+			return from
+		}
+	}
+	for _, offset := range []int{-17, -15, -10, -6, 6, 10, 15, 17} {
+		if from := sq.step(offset); from != NoSquare && pieces[from] == Piece(color|Knight) {
+			return from
+		}
+	}
+
+	diagonal := []int{-9, -7, 7, 9}
+	straight := []int{-8, -1, 1, 8}
+	if from := sliderAttacker(pieces, sq, diagonal, Piece(color|Bishop)); from != NoSquare {
+		return from
+	}
+	if from := sliderAttacker(pieces, sq, straight, Piece(color|Rook)); from != NoSquare {
+		return from
+	}
+	if from := sliderAttacker(pieces, sq, diagonal, Piece(color|Queen)); from != NoSquare {
+		return from
+	}
+	if from := sliderAttacker(pieces, sq, straight, Piece(color|Queen)); from != NoSquare {
+		return from
+	}
+
+	for _, offset := range []int{-9, -8, -7, -1, 1, 7, 8, 9} {
+		if from := sq.step(offset); from != NoSquare && pieces[from] == Piece(color|King) {
+			return from
+		}
+	}
+	return NoSquare
+}
+
+// sliderAttacker returns the square of the first piece found along any of the
+// given rays from sq, if it is the requested piece, or NoSquare otherwise.
+func sliderAttacker(pieces *[64]Piece, sq Sq, offsets []int, piece Piece) Sq {
+	for _, offset := range offsets {
+		for from := sq.step(offset); from != NoSquare; from = from.step(offset) {
+			if pieces[from] == NoPiece {
+				continue
+			}
+			if pieces[from] == piece {
+				return from
+			}
+			break
+		}
+	}
+	return NoSquare
+}
@@ -0,0 +1,95 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSEE(t *testing.T) {
+	tests := []struct {
+		name string
+		fen  string
+		move string
+		want int
+	}{
+		{
+			name: "quiet move to a safe square",
+			fen:  "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+			move: "e4",
+			want: 0,
+		},
+		{
+			name: "free pawn",
+			fen:  "4k3/8/8/3p4/4P3/8/8/4K3 w - - 0 1",
+			move: "exd5",
+			want: 100,
+		},
+		{
+			name: "pawn defended by pawn, capture with pawn",
+			fen:  "4k3/8/2p5/3p4/4P3/8/8/4K3 w - - 0 1",
+			move: "exd5",
+			want: 0,
+		},
+		{
+			name: "queen takes defended pawn",
+			fen:  "4k3/8/2p5/3p4/8/8/3Q4/4K3 w - - 0 1",
+			move: "Qxd5",
+			want: 100 - 900,
+		},
+		{
+			name: "rook takes rook with x-ray support",
+			fen:  "3rk3/8/8/8/8/8/3R4/3RK3 w - - 0 1",
+			move: "Rxd8+",
+			want: 500,
+		},
+		{
+			name: "knight to a square the pawn does not cover",
+			fen:  "4k3/8/3p4/8/3N4/8/8/4K3 w - - 0 1",
+			move: "Nc6",
+			want: 0,
+		},
+		{
+			name: "knight hangs to a pawn",
+			fen:  "4k3/1p6/8/8/3N4/8/8/4K3 w - - 0 1",
+			move: "Nc6",
+			want: -300,
+		},
+		{
+			name: "king cannot recapture a defended piece",
+			fen:  "4k3/8/8/8/8/2b5/3n4/4K3 b - - 0 1",
+			move: "Nf3+",
+			want: 0,
+		},
+		{
+			name: "en passant capture",
+			fen:  "4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 1",
+			move: "exd6",
+			want: 100,
+		},
+		{
+			name: "castling",
+			fen:  "4k3/8/8/8/8/8/8/4K2R w K - 0 1",
+			move: "O-O",
+			want: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board, err := ParseFen(tt.fen)
+			require.NoError(t, err)
+			m, err := board.ParseMove(tt.move)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, board.SEE(m))
+		})
+	}
+}
+
+func TestPieceValue(t *testing.T) {
+	assert.Equal(t, 100, PieceValue(Pawn))
+	assert.Equal(t, 900, PieceValue(Queen))
+	assert.Equal(t, 0, PieceValue(NoPiece))
+	assert.Equal(t, 0, PieceValue(99))
+}
@@ -0,0 +1,336 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/kyleboon/gochess/internal"
+	"github.com/kyleboon/gochess/internal/analysis"
+	"github.com/kyleboon/gochess/internal/db"
+	"github.com/kyleboon/gochess/internal/pgn"
+	"github.com/kyleboon/gochess/internal/render"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+	maxSquareSize   = 256
+	// maxAnalysisDepth and maxAnalysisLines bound the engine work one
+	// request can ask for, as the API takes requests from anyone.
+	maxAnalysisDepth = 30
+	maxAnalysisLines = 5
+	startFEN         = "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
+)
+
+// searchFields are the query parameters games can be searched by.
+var searchFields = []string{"white", "black", "event", "site", "date", "result", "time_class"}
+
+// listGames searches the games, by the fields of searchFields, a page at a
+// time.
+func (s *Server) listGames(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	limit, err := intParam(q.Get("limit"), defaultPageSize)
+	if err != nil || limit < 1 || limit > maxPageSize {
+		s.writeError(w, http.StatusBadRequest, fmt.Sprintf("limit must be between 1 and %d", maxPageSize))
+		return
+	}
+	offset, err := intParam(q.Get("offset"), 0)
+	if err != nil || offset < 0 {
+		s.writeError(w, http.StatusBadRequest, "offset must be a non-negative number")
+		return
+	}
+
+	criteria := make(map[string]string)
+	for _, field := range searchFields {
+		if v := q.Get(field); v != "" {
+			criteria[field] = v
+		}
+	}
+	for _, filter := range q["tag"] {
+		name, value, err := db.ParseTagFilter(filter)
+		if err != nil {
+			s.writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		criteria[db.TagCriterion+name] = value
+	}
+	start := time.Now()
+	games, err := s.db.SearchGames(r.Context(), criteria, limit, offset)
+	s.timeQuery("search_games", start)
+	if err != nil {
+		s.internalError(w, err)
+		return
+	}
+	if games == nil {
+		games = []map[string]interface{}{}
+	}
+	s.writeJSON(w, http.StatusOK, map[string]any{
+		"games":  games,
+		"limit":  limit,
+		"offset": offset,
+	})
+}
+
+// getGame returns the details and tags of a game.
+func (s *Server) getGame(w http.ResponseWriter, r *http.Request) {
+	game, ok := s.lookupGame(w, r)
+	if !ok {
+		return
+	}
+	delete(game, "pgn_text")
+	s.writeJSON(w, http.StatusOK, game)
+}
+
+// getGamePGN returns the PGN of a game.
+func (s *Server) getGamePGN(w http.ResponseWriter, r *http.Request) {
+	game, ok := s.lookupGame(w, r)
+	if !ok {
+		return
+	}
+	text, _ := game["pgn_text"].(string)
+	w.Header().Set("Content-Type", "application/x-chess-pgn")
+	_, _ = w.Write([]byte(text))
+}
+
+// getGameJSON returns a game in the JSON form of the convert command.
+func (s *Server) getGameJSON(w http.ResponseWriter, r *http.Request) {
+	game, ok := s.parsedGame(w, r)
+	if !ok {
+		return
+	}
+	s.writeJSON(w, http.StatusOK, game.JSON())
+}
+
+// explorerMove is a move played from an explored position.
+type explorerMove struct {
+	UCI       string `json:"uci"`
+	SAN       string `json:"san"`
+	FEN       string `json:"fen,omitempty"` // the position after the move
+	Games     int    `json:"games"`
+	WhiteWins int    `json:"white_wins"`
+	BlackWins int    `json:"black_wins"`
+	Draws     int    `json:"draws"`
+}
+
+// explorePosition returns the moves played from the position given by the
+// fen parameter, the starting position by default, and how those games
+// ended.
+func (s *Server) explorePosition(w http.ResponseWriter, r *http.Request) {
+	fen := r.URL.Query().Get("fen")
+	if fen == "" {
+		fen = startFEN
+	}
+	board, err := internal.ParseFen(fen)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	start := time.Now()
+	stats, err := s.db.GetPositionMoves(r.Context(), board.Fen())
+	s.timeQuery("position_moves", start)
+	if err != nil {
+		s.internalError(w, err)
+		return
+	}
+	moves := make([]explorerMove, 0, len(stats))
+	for _, st := range stats {
+		m := explorerMove{
+			UCI:       st.Move,
+			Games:     st.Games,
+			WhiteWins: st.WhiteWins,
+			BlackWins: st.BlackWins,
+			Draws:     st.Draws,
+		}
+		if mv, err := board.ParseMove(st.Move); err == nil {
+			m.SAN = mv.San(board)
+			m.FEN = board.MakeMove(mv).Fen()
+		}
+		moves = append(moves, m)
+	}
+	s.writeJSON(w, http.StatusOK, map[string]any{
+		"fen":   board.Fen(),
+		"moves": moves,
+	})
+}
+
+// diagram returns an SVG image of the position given by the fen parameter,
+// with the squares of the last_move parameter highlighted, drawn from
+// Black's side if flip is true.
+func (s *Server) diagram(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	board, err := internal.ParseFen(q.Get("fen"))
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	opts := render.Options{Flipped: q.Get("flip") == "true"}
+	if v := q.Get("last_move"); v != "" {
+		if opts.LastMove, err = render.ParseSquares(v); err != nil {
+			s.writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	if opts.SquareSize, err = intParam(q.Get("size"), render.DefaultSquareSize); err != nil || opts.SquareSize < 1 || opts.SquareSize > maxSquareSize {
+		s.writeError(w, http.StatusBadRequest, fmt.Sprintf("size must be between 1 and %d", maxSquareSize))
+		return
+	}
+	w.Header().Set("Content-Type", "image/svg+xml")
+	_, _ = w.Write([]byte(render.SVG(board, opts)))
+}
+
+// playerStats returns the statistics of the players given by the player
+// parameters, or of every player if there are none.
+func (s *Server) playerStats(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	stats, err := s.db.GetPlayerStatsFiltered(r.Context(), r.URL.Query()["player"])
+	s.timeQuery("player_stats", start)
+	if err != nil {
+		s.internalError(w, err)
+		return
+	}
+	if stats == nil {
+		stats = []db.PlayerStats{}
+	}
+	s.writeJSON(w, http.StatusOK, stats)
+}
+
+// analyzedMove is a move of an analyzed game.
+type analyzedMove struct {
+	Ply            int      `json:"ply"`
+	Color          string   `json:"color"`
+	SAN            string   `json:"san"`
+	Best           string   `json:"best,omitempty"`
+	EvalBefore     int      `json:"eval_before"`
+	EvalAfter      int      `json:"eval_after"`
+	Loss           int      `json:"loss"`
+	WinBefore      float64  `json:"win_before"`
+	WinAfter       float64  `json:"win_after"`
+	Classification string   `json:"classification"`
+	Motifs         []string `json:"motifs,omitempty"`
+}
+
+// analyzeGame reviews a game with the engine, at the depth and number of
+// lines given by the depth and lines parameters. The response holds the
+// verdict on every move; evaluations are in centipawns and win
+// probabilities from 0 to 1, both from White's view.
+func (s *Server) analyzeGame(w http.ResponseWriter, r *http.Request) {
+	if s.openEngine == nil {
+		s.writeError(w, http.StatusServiceUnavailable, "no engine configured")
+		return
+	}
+	opts := analysis.DefaultOptions()
+	var err error
+	if opts.Depth, err = intParam(r.URL.Query().Get("depth"), opts.Depth); err != nil || opts.Depth < 1 || opts.Depth > maxAnalysisDepth {
+		s.writeError(w, http.StatusBadRequest, fmt.Sprintf("depth must be between 1 and %d", maxAnalysisDepth))
+		return
+	}
+	if opts.MultiPV, err = intParam(r.URL.Query().Get("lines"), opts.MultiPV); err != nil || opts.MultiPV < 1 || opts.MultiPV > maxAnalysisLines {
+		s.writeError(w, http.StatusBadRequest, fmt.Sprintf("lines must be between 1 and %d", maxAnalysisLines))
+		return
+	}
+	game, ok := s.parsedGame(w, r)
+	if !ok {
+		return
+	}
+
+	eng, err := s.startEngine(r.Context())
+	if err != nil {
+		s.internalError(w, err)
+		return
+	}
+	defer func() { _ = eng.Close() }()
+
+	annotations, err := analysis.New(eng, opts, s.logger).AnnotateGame(r.Context(), game)
+	if err != nil {
+		s.internalError(w, fmt.Errorf("analysis failed: %w", err))
+		return
+	}
+	moves := make([]analyzedMove, len(annotations))
+	for i, a := range annotations {
+		moves[i] = analyzedMove{
+			Ply:            a.Ply,
+			Color:          [2]string{"white", "black"}[a.Color],
+			SAN:            a.San,
+			Best:           a.Best,
+			EvalBefore:     a.EvalBefore,
+			EvalAfter:      a.EvalAfter,
+			Loss:           a.Loss,
+			WinBefore:      a.WinBefore,
+			WinAfter:       a.WinAfter,
+			Classification: a.Classification.String(),
+			Motifs:         a.Motifs,
+		}
+	}
+	s.writeJSON(w, http.StatusOK, map[string]any{
+		"depth": opts.Depth,
+		"moves": moves,
+	})
+}
+
+// lookupGame returns the game given by the id path parameter. If there is
+// none, an error response is written and ok is false.
+func (s *Server) lookupGame(w http.ResponseWriter, r *http.Request) (game map[string]interface{}, ok bool) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, "invalid game id")
+		return nil, false
+	}
+	start := time.Now()
+	game, err = s.db.GetGameByID(r.Context(), id)
+	s.timeQuery("game_by_id", start)
+	if errors.Is(err, db.ErrGameNotFound) {
+		s.writeError(w, http.StatusNotFound, err.Error())
+		return nil, false
+	}
+	if err != nil {
+		s.internalError(w, err)
+		return nil, false
+	}
+	return game, true
+}
+
+// parsedGame is lookupGame, with the game's PGN parsed.
+func (s *Server) parsedGame(w http.ResponseWriter, r *http.Request) (*pgn.Game, bool) {
+	game, ok := s.lookupGame(w, r)
+	if !ok {
+		return nil, false
+	}
+	text, _ := game["pgn_text"].(string)
+	parsed, err := parseGameText(text)
+	if err != nil {
+		s.internalError(w, fmt.Errorf("game %v: %w", game["id"], err))
+		return nil, false
+	}
+	return parsed, true
+}
+
+// parseGameText parses the PGN of a stored game, moves included.
+func parseGameText(text string) (*pgn.Game, error) {
+	pgnDB := &pgn.DB{}
+	pgnDB.Parse(text)
+	if len(pgnDB.Games) == 0 {
+		return nil, errors.New("no valid PGN")
+	}
+	if err := pgnDB.ParseMoves(pgnDB.Games[0]); err != nil {
+		return nil, err
+	}
+	return pgnDB.Games[0], nil
+}
+
+// internalError logs err and reports it in a server error response.
+func (s *Server) internalError(w http.ResponseWriter, err error) {
+	s.logger.Error("request failed", "error", err)
+	s.writeError(w, http.StatusInternalServerError, err.Error())
+}
+
+// intParam parses an integer query parameter, which is def if empty.
+func intParam(s string, def int) (int, error) {
+	if s == "" {
+		return def, nil
+	}
+	return strconv.Atoi(s)
+}
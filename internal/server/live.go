@@ -0,0 +1,186 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/kyleboon/gochess/internal/engine"
+	"github.com/kyleboon/gochess/internal/pgn"
+)
+
+// defaultReplayDelay is how long each position is shown when a game is
+// replayed.
+const defaultReplayDelay = time.Second
+
+// defaultLiveDepth is the engine depth of live analysis.
+const defaultLiveDepth = 16
+
+// liveUpdate is a message of a live game stream.
+type liveUpdate struct {
+	Type  string   `json:"type"` // "position", "done" or "error"
+	Ply   int      `json:"ply"`
+	FEN   string   `json:"fen,omitempty"`
+	Move  string   `json:"move,omitempty"` // the move that led to the position, in SAN
+	UCI   string   `json:"uci,omitempty"`
+	Eval  *float64 `json:"eval,omitempty"` // pawns from White's view; ±999 for a mate
+	Mate  int      `json:"mate,omitempty"` // moves to mate, negative if Black mates
+	Best  string   `json:"best,omitempty"` // the engine's move in the position, in SAN
+	Error string   `json:"error,omitempty"`
+}
+
+// streamGame streams the positions of a game over a WebSocket, for browser
+// based viewers. With mode=replay (the default) positions are sent every
+// delay, with their saved evaluations or [%eval] comments. With
+// mode=analysis each position is sent once the engine has analyzed it to
+// depth, with its evaluation and best move. The stream ends with a "done"
+// message, and stops when the client closes the connection.
+func (s *Server) streamGame(w http.ResponseWriter, r *http.Request) {
+	if err := checkHandshake(r); err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	q := r.URL.Query()
+	mode := q.Get("mode")
+	if mode == "" {
+		mode = "replay"
+	}
+	if mode != "replay" && mode != "analysis" {
+		s.writeError(w, http.StatusBadRequest, "mode must be replay or analysis")
+		return
+	}
+	if mode == "analysis" && s.openEngine == nil {
+		s.writeError(w, http.StatusServiceUnavailable, "no engine configured")
+		return
+	}
+	delay := defaultReplayDelay
+	if mode == "analysis" {
+		delay = 0
+	}
+	if v := q.Get("delay"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			s.writeError(w, http.StatusBadRequest, "delay must be a duration such as 500ms")
+			return
+		}
+		delay = d
+	}
+	depth, err := intParam(q.Get("depth"), defaultLiveDepth)
+	if err != nil || depth < 1 || depth > maxAnalysisDepth {
+		s.writeError(w, http.StatusBadRequest, fmt.Sprintf("depth must be between 1 and %d", maxAnalysisDepth))
+		return
+	}
+	game, ok := s.parsedGame(w, r)
+	if !ok {
+		return
+	}
+
+	evals := make(map[int]float64)
+	if mode == "replay" {
+		id, _ := strconv.Atoi(r.PathValue("id")) // checked by parsedGame
+		start := time.Now()
+		positions, err := s.db.GetPositionsForGame(r.Context(), id)
+		s.timeQuery("game_positions", start)
+		if err != nil {
+			s.internalError(w, err)
+			return
+		}
+		for _, pos := range positions {
+			if pos.Evaluation != nil {
+				evals[pos.MoveNumber] = *pos.Evaluation
+			}
+		}
+	}
+
+	ws, err := upgradeWebSocket(w, r)
+	if err != nil {
+		s.internalError(w, err)
+		return
+	}
+	defer func() { _ = ws.conn.Close() }()
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	go ws.readLoop(cancel)
+
+	var eng engine.Analyzer
+	if mode == "analysis" {
+		if eng, err = s.startEngine(ctx); err != nil {
+			s.closeWithError(ws, err)
+			return
+		}
+		defer func() { _ = eng.Close() }()
+	}
+
+	ply := 0
+	for n := game.Root; n != nil; n, ply = n.Next, ply+1 {
+		update := liveUpdate{Type: "position", Ply: ply, FEN: n.Board.Fen()}
+		if !n.IsRoot() {
+			update.Move = n.Move.San(n.Parent.Board)
+			update.UCI = n.Move.Uci(n.Parent.Board)
+		}
+		if eng != nil {
+			if err := analyzeUpdate(ctx, eng, n, depth, &update); err != nil {
+				if ctx.Err() == nil {
+					s.closeWithError(ws, err)
+				}
+				return
+			}
+		} else if e, ok := evals[ply]; ok {
+			update.Eval = &e
+		} else if e, ok := n.Eval(); ok {
+			update.Eval = &e
+		}
+		if err := ws.WriteJSON(update); err != nil {
+			return
+		}
+
+		if n.Next != nil && delay > 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(delay):
+			}
+		}
+	}
+	if err := ws.WriteJSON(liveUpdate{Type: "done", Ply: ply - 1}); err != nil {
+		return
+	}
+	_ = ws.Close(closeNormal, "")
+}
+
+// analyzeUpdate adds the engine's evaluation and best move in the position
+// of n to update.
+func analyzeUpdate(ctx context.Context, eng engine.Analyzer, n *pgn.Node, depth int, update *liveUpdate) error {
+	result, err := eng.Analyze(ctx, n.Board.Fen(), engine.AnalysisOptions{Depth: depth, MultiPV: 1})
+	if err != nil {
+		return fmt.Errorf("analysis failed: %w", err)
+	}
+	if len(result.Lines) == 0 {
+		return nil
+	}
+	line := result.Lines[0]
+	eval := float64(line.Score.Centipawns) / 100
+	if line.Score.IsMate {
+		update.Mate = line.Score.Mate
+		eval = pgn.MateEval
+		if line.Score.Mate < 0 {
+			eval = -pgn.MateEval
+		}
+	}
+	update.Eval = &eval
+	if len(line.Moves) > 0 {
+		if mv, err := n.Board.ParseMove(line.Moves[0]); err == nil {
+			update.Best = mv.San(n.Board)
+		}
+	}
+	return nil
+}
+
+// closeWithError sends err to the client and closes the connection.
+func (s *Server) closeWithError(ws *wsConn, err error) {
+	s.logger.Error("live stream failed", "error", err)
+	_ = ws.WriteJSON(liveUpdate{Type: "error", Error: err.Error()})
+	_ = ws.Close(closeInternalError, "")
+}
@@ -0,0 +1,139 @@
+package server
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcceptKey(t *testing.T) {
+	// The example of RFC 6455, section 1.3
+	assert.Equal(t, "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", acceptKey("dGhlIHNhbXBsZSBub25jZQ=="))
+}
+
+// dialLive opens a WebSocket to path on the test server at addr and returns
+// a reader positioned after the handshake response.
+func dialLive(t *testing.T, addr, path string) (net.Conn, *bufio.Reader) {
+	t.Helper()
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	_, err = io.WriteString(conn, "GET "+path+" HTTP/1.1\r\nHost: "+addr+"\r\n"+
+		"Upgrade: websocket\r\nConnection: Upgrade\r\n"+
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\nSec-WebSocket-Version: 13\r\n\r\n")
+	require.NoError(t, err)
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, nil)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+	assert.Equal(t, "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", resp.Header.Get("Sec-WebSocket-Accept"))
+	return conn, br
+}
+
+// readMessages reads the text messages of a stream until it is closed.
+func readMessages(t *testing.T, br *bufio.Reader) []liveUpdate {
+	t.Helper()
+	var updates []liveUpdate
+	for {
+		var head [2]byte
+		_, err := io.ReadFull(br, head[:])
+		require.NoError(t, err)
+		n := int(head[1] & 0x7f)
+		if n == 126 {
+			var ext [2]byte
+			_, err = io.ReadFull(br, ext[:])
+			require.NoError(t, err)
+			n = int(binary.BigEndian.Uint16(ext[:]))
+		}
+		payload := make([]byte, n)
+		_, err = io.ReadFull(br, payload)
+		require.NoError(t, err)
+
+		if head[0]&0x0f == opClose {
+			return updates
+		}
+		var u liveUpdate
+		require.NoError(t, json.Unmarshal(payload, &u))
+		updates = append(updates, u)
+	}
+}
+
+func TestStreamGameReplay(t *testing.T) {
+	ts := setupServer(t)
+	addr := strings.TrimPrefix(ts.URL, "http://")
+
+	_, br := dialLive(t, addr, "/api/games/2/live?delay=0s")
+	updates := readMessages(t, br)
+	require.Len(t, updates, 4)
+	assert.Equal(t, liveUpdate{Type: "position", Ply: 0, FEN: "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"}, updates[0])
+	assert.Equal(t, "d4", updates[1].Move)
+	assert.Equal(t, "d2d4", updates[1].UCI)
+	assert.Equal(t, "d5", updates[2].Move)
+	assert.Equal(t, liveUpdate{Type: "done", Ply: 2}, updates[3])
+}
+
+func TestStreamGameAnalysis(t *testing.T) {
+	ts := setupServer(t)
+	addr := strings.TrimPrefix(ts.URL, "http://")
+
+	_, br := dialLive(t, addr, "/api/games/2/live?mode=analysis&depth=1")
+	updates := readMessages(t, br)
+	require.Len(t, updates, 4)
+	for _, u := range updates[:3] {
+		require.NotNil(t, u.Eval)
+		assert.Equal(t, 0.0, *u.Eval)
+	}
+}
+
+func TestStreamGameClientClose(t *testing.T) {
+	ts := setupServer(t)
+	addr := strings.TrimPrefix(ts.URL, "http://")
+
+	conn, br := dialLive(t, addr, "/api/games/1/live?delay=1h")
+	var head [2]byte
+	_, err := io.ReadFull(br, head[:])
+	require.NoError(t, err)
+
+	// A masked close frame with an empty payload ends the stream without
+	// waiting for the next position
+	_, err = conn.Write([]byte{0x80 | opClose, 0x80, 1, 2, 3, 4})
+	require.NoError(t, err)
+	_, err = io.ReadAll(br)
+	assert.NoError(t, err)
+}
+
+func TestStreamGameErrors(t *testing.T) {
+	ts := setupServer(t)
+
+	var e map[string]string
+	assert.Equal(t, http.StatusBadRequest, get(t, ts.URL+"/api/games/1/live", &e))
+	assert.Contains(t, e["error"], "WebSocket")
+
+	// Handshakes that are refused before the connection is upgraded
+	handshake := func(path string) int {
+		req, err := http.NewRequest(http.MethodGet, ts.URL+path, nil)
+		require.NoError(t, err)
+		req.Header.Set("Connection", "Upgrade")
+		req.Header.Set("Upgrade", "websocket")
+		req.Header.Set("Sec-WebSocket-Version", "13")
+		req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+		return resp.StatusCode
+	}
+	assert.Equal(t, http.StatusBadRequest, handshake("/api/games/1/live?mode=fast"))
+	assert.Equal(t, http.StatusBadRequest, handshake("/api/games/1/live?delay=soon"))
+	assert.Equal(t, http.StatusBadRequest, handshake("/api/games/1/live?mode=analysis&depth=1000"))
+	assert.Equal(t, http.StatusNotFound, handshake("/api/games/99/live"))
+}
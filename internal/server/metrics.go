@@ -0,0 +1,264 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kyleboon/gochess/internal/engine"
+)
+
+// durationBuckets are the upper bounds, in seconds, of the buckets of the
+// duration histograms.
+var durationBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// histogram counts observed durations in durationBuckets.
+type histogram struct {
+	counts []int64 // cumulative count per bucket
+	sum    float64
+	count  int64
+}
+
+func (h *histogram) observe(d time.Duration) {
+	if h.counts == nil {
+		h.counts = make([]int64, len(durationBuckets))
+	}
+	v := d.Seconds()
+	for i, le := range durationBuckets {
+		if v <= le {
+			h.counts[i]++
+		}
+	}
+	h.sum += v
+	h.count++
+}
+
+// requestKey identifies the requests counted together.
+type requestKey struct {
+	method, route string
+	status        int
+}
+
+// metrics holds the counters exposed on /metrics in the Prometheus text
+// format.
+type metrics struct {
+	mu           sync.Mutex
+	start        time.Time
+	requests     map[requestKey]int64
+	requestTimes map[string]*histogram // by route
+	queryTimes   map[string]*histogram // by query
+	enginesInUse int
+	engineStarts int64
+}
+
+func newMetrics() *metrics {
+	return &metrics{
+		start:        time.Now(),
+		requests:     make(map[requestKey]int64),
+		requestTimes: make(map[string]*histogram),
+		queryTimes:   make(map[string]*histogram),
+	}
+}
+
+// observeRequest counts a request to route, the pattern it matched.
+func (m *metrics) observeRequest(method, route string, status int, d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests[requestKey{method, route, status}]++
+	h := m.requestTimes[route]
+	if h == nil {
+		h = &histogram{}
+		m.requestTimes[route] = h
+	}
+	h.observe(d)
+}
+
+// observeQuery records how long a database query took.
+func (m *metrics) observeQuery(query string, d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	h := m.queryTimes[query]
+	if h == nil {
+		h = &histogram{}
+		m.queryTimes[query] = h
+	}
+	h.observe(d)
+}
+
+// engineStarted and engineClosed track the engines running analyses.
+func (m *metrics) engineStarted() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.enginesInUse++
+	m.engineStarts++
+}
+
+func (m *metrics) engineClosed() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.enginesInUse--
+}
+
+// write writes the metrics in the Prometheus text exposition format, with
+// the extra gauges given by gauges.
+func (m *metrics) write(w io.Writer, gauges map[string]float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	writeHeader(w, "gochess_uptime_seconds", "gauge", "Time since the server started.")
+	fmt.Fprintf(w, "gochess_uptime_seconds %g\n", time.Since(m.start).Seconds())
+
+	names := make([]string, 0, len(gauges))
+	for name := range gauges {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		writeHeader(w, name, "gauge", gaugeHelp[name])
+		fmt.Fprintf(w, "%s %g\n", name, gauges[name])
+	}
+
+	writeHeader(w, "gochess_http_requests_total", "counter", "HTTP requests handled, by route and status.")
+	keys := make([]requestKey, 0, len(m.requests))
+	for k := range m.requests {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.route != b.route {
+			return a.route < b.route
+		}
+		if a.method != b.method {
+			return a.method < b.method
+		}
+		return a.status < b.status
+	})
+	for _, k := range keys {
+		fmt.Fprintf(w, "gochess_http_requests_total{method=%s,route=%s,status=\"%d\"} %d\n",
+			labelValue(k.method), labelValue(k.route), k.status, m.requests[k])
+	}
+
+	writeHistograms(w, "gochess_http_request_duration_seconds", "route",
+		"Time taken to handle HTTP requests, by route.", m.requestTimes)
+	writeHistograms(w, "gochess_db_query_duration_seconds", "query",
+		"Time taken by database queries, by query.", m.queryTimes)
+
+	writeHeader(w, "gochess_engines_in_use", "gauge", "Engines running an analysis.")
+	fmt.Fprintf(w, "gochess_engines_in_use %d\n", m.enginesInUse)
+	writeHeader(w, "gochess_engine_starts_total", "counter", "Engines started for analyses.")
+	fmt.Fprintf(w, "gochess_engine_starts_total %d\n", m.engineStarts)
+}
+
+// gaugeHelp describes the gauges computed when the metrics are scraped.
+var gaugeHelp = map[string]string{
+	"gochess_games":                 "Games in the database.",
+	"gochess_analysis_queue_depth":  "Analyses waiting for a worker.",
+	"gochess_analysis_workers":      "Analysis workers.",
+	"gochess_analysis_workers_busy": "Analysis workers running an analysis.",
+}
+
+func writeHeader(w io.Writer, name, typ, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+func writeHistograms(w io.Writer, name, label, help string, hs map[string]*histogram) {
+	writeHeader(w, name, "histogram", help)
+	values := make([]string, 0, len(hs))
+	for v := range hs {
+		values = append(values, v)
+	}
+	sort.Strings(values)
+	for _, v := range values {
+		h := hs[v]
+		for i, le := range durationBuckets {
+			fmt.Fprintf(w, "%s_bucket{%s=%s,le=\"%g\"} %d\n", name, label, labelValue(v), le, h.counts[i])
+		}
+		fmt.Fprintf(w, "%s_bucket{%s=%s,le=\"+Inf\"} %d\n", name, label, labelValue(v), h.count)
+		fmt.Fprintf(w, "%s_sum{%s=%s} %g\n", name, label, labelValue(v), h.sum)
+		fmt.Fprintf(w, "%s_count{%s=%s} %d\n", name, label, labelValue(v), h.count)
+	}
+}
+
+// labelValue quotes a label value, escaping as the text format requires.
+func labelValue(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+	return `"` + s + `"`
+}
+
+// routeOf returns the route of the pattern r matched, without its method,
+// so that paths with game IDs are counted together.
+func routeOf(r *http.Request) string {
+	if r.Pattern == "" {
+		return "unmatched"
+	}
+	if _, route, ok := strings.Cut(r.Pattern, " "); ok {
+		return route
+	}
+	return r.Pattern
+}
+
+// timeQuery records in the metrics how long the database query named query
+// takes, from start until it is called.
+func (s *Server) timeQuery(query string, start time.Time) {
+	s.metrics.observeQuery(query, time.Since(start))
+}
+
+// trackedEngine counts an engine as in use until it is closed.
+type trackedEngine struct {
+	engine.Analyzer
+	once    sync.Once
+	metrics *metrics
+}
+
+func (e *trackedEngine) Close() error {
+	e.once.Do(e.metrics.engineClosed)
+	return e.Analyzer.Close()
+}
+
+// startEngine starts an engine with the configured EngineOpener, counting it
+// in the metrics until it is closed.
+func (s *Server) startEngine(ctx context.Context) (engine.Analyzer, error) {
+	eng, err := s.openEngine(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.metrics.engineStarted()
+	return &trackedEngine{Analyzer: eng, metrics: s.metrics}, nil
+}
+
+// serveMetrics writes the metrics in the Prometheus text format.
+func (s *Server) serveMetrics(w http.ResponseWriter, r *http.Request) {
+	queued, busy := s.queue.stats()
+	gauges := map[string]float64{
+		"gochess_analysis_queue_depth":  float64(queued),
+		"gochess_analysis_workers":      float64(s.queue.workers),
+		"gochess_analysis_workers_busy": float64(busy),
+	}
+	start := time.Now()
+	if games, err := s.db.GetGameCount(r.Context()); err == nil {
+		gauges["gochess_games"] = float64(games)
+	} else {
+		s.logger.Error("failed to count games for metrics", "error", err)
+	}
+	s.timeQuery("game_count", start)
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	s.metrics.write(w, gauges)
+}
+
+// healthz reports whether the server can reach its database.
+func (s *Server) healthz(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), healthTimeout)
+	defer cancel()
+	if err := s.db.Ping(ctx); err != nil {
+		s.logger.Error("health check failed", "error", err)
+		s.writeJSON(w, http.StatusServiceUnavailable, map[string]string{"status": "unavailable", "error": err.Error()})
+		return
+	}
+	s.writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
@@ -0,0 +1,342 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/kyleboon/gochess/internal/analysis"
+	"github.com/kyleboon/gochess/internal/db"
+	"github.com/kyleboon/gochess/internal/notify"
+)
+
+const (
+	// DefaultAnalysisWorkers is the number of games analyzed at once, each
+	// by its own engine.
+	DefaultAnalysisWorkers = 1
+
+	// maxQueuedJobs is how many analyses can wait for a worker.
+	maxQueuedJobs = 100
+
+	// maxKeptJobs is how many jobs are remembered; the oldest finished
+	// ones are forgotten first. Their results stay in the database.
+	maxKeptJobs = 1000
+)
+
+// JobStatus is the state of an analysis job.
+type JobStatus string
+
+const (
+	JobQueued  JobStatus = "queued"
+	JobRunning JobStatus = "running"
+	JobDone    JobStatus = "done"
+	JobFailed  JobStatus = "failed"
+)
+
+// Job is a game analysis requested with POST /api/analyze and run in the
+// background by a worker.
+type Job struct {
+	ID         int        `json:"id"`
+	GameID     int        `json:"game_id"`
+	White      string     `json:"white"`
+	Black      string     `json:"black"`
+	Depth      int        `json:"depth"`
+	Lines      int        `json:"lines"`
+	Status     JobStatus  `json:"status"`
+	Done       int        `json:"done"`  // positions analyzed
+	Total      int        `json:"total"` // positions to analyze, once running
+	Error      string     `json:"error,omitempty"`
+	AnalysisID int        `json:"analysis_id,omitempty"` // the saved analysis, once done
+	CreatedAt  time.Time  `json:"created_at"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// jobQueue holds the analysis jobs and feeds them to the workers.
+type jobQueue struct {
+	mu      sync.Mutex
+	jobs    map[int]*Job
+	order   []int // job IDs, oldest first
+	nextID  int
+	pending chan *Job
+	workers int
+	busy    int
+	start   sync.Once
+}
+
+func newJobQueue(workers int) *jobQueue {
+	return &jobQueue{
+		jobs:    make(map[int]*Job),
+		pending: make(chan *Job, maxQueuedJobs),
+		workers: workers,
+	}
+}
+
+// add queues job, giving it an ID, and returns a copy of it. It fails if the queue is full.
+func (q *jobQueue) add(job *Job) (Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.nextID++
+	job.ID = q.nextID
+	job.Status = JobQueued
+	job.CreatedAt = time.Now().UTC()
+	select {
+	case q.pending <- job:
+	default:
+		q.nextID--
+		return Job{}, errors.New("analysis queue is full")
+	}
+	q.jobs[job.ID] = job
+	q.order = append(q.order, job.ID)
+	q.forgetOldJobs()
+	return *job, nil
+}
+
+// forgetOldJobs drops the oldest finished jobs beyond maxKeptJobs.
+func (q *jobQueue) forgetOldJobs() {
+	for i := 0; len(q.jobs) > maxKeptJobs && i < len(q.order); {
+		id := q.order[i]
+		if s := q.jobs[id].Status; s == JobDone || s == JobFailed {
+			delete(q.jobs, id)
+			q.order = append(q.order[:i], q.order[i+1:]...)
+			continue
+		}
+		i++
+	}
+}
+
+// get returns a copy of the job with id.
+func (q *jobQueue) get(id int) (Job, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	job, ok := q.jobs[id]
+	if !ok {
+		return Job{}, false
+	}
+	return *job, true
+}
+
+// list returns copies of the jobs, most recent first.
+func (q *jobQueue) list() []Job {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	jobs := make([]Job, 0, len(q.jobs))
+	for _, id := range q.order {
+		jobs = append(jobs, *q.jobs[id])
+	}
+	sort.SliceStable(jobs, func(i, j int) bool { return jobs[i].ID > jobs[j].ID })
+	return jobs
+}
+
+// update changes a job under the lock.
+func (q *jobQueue) update(job *Job, fn func(*Job)) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	fn(job)
+}
+
+// stats returns the number of waiting jobs and of busy workers.
+func (q *jobQueue) stats() (queued, busy int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.pending), q.busy
+}
+
+// startWorkers starts the analysis workers, once, until the server is
+// closed.
+func (s *Server) startWorkers() {
+	s.queue.start.Do(func() {
+		for i := 0; i < s.queue.workers; i++ {
+			go s.worker()
+		}
+	})
+}
+
+// worker runs queued jobs one at a time.
+func (s *Server) worker() {
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case job := <-s.queue.pending:
+			s.queue.update(job, func(j *Job) {
+				now := time.Now().UTC()
+				j.Status, j.StartedAt = JobRunning, &now
+				s.queue.busy++
+			})
+			result, err := s.runJob(s.ctx, job)
+			var finished Job
+			s.queue.update(job, func(j *Job) {
+				now := time.Now().UTC()
+				j.FinishedAt = &now
+				j.Status = JobDone
+				if err != nil {
+					j.Status, j.Error = JobFailed, err.Error()
+				}
+				s.queue.busy--
+				finished = *j
+			})
+			if err != nil {
+				s.logger.Error("analysis failed", "job", job.ID, "game", job.GameID, "error", err)
+			} else {
+				s.logger.Info("analysis done", "job", job.ID, "game", job.GameID)
+			}
+			s.notifyJob(finished, result)
+		}
+	}
+}
+
+// notifyJob tells the notifier that job finished, with result unless it
+// failed.
+func (s *Server) notifyJob(job Job, result *db.GameAnalysis) {
+	if !s.notifier.Enabled() {
+		return
+	}
+	summary := notify.AnalysisSummary{GameID: job.GameID, White: job.White, Black: job.Black, Error: job.Error}
+	if result != nil {
+		summary.AnalysisID = result.ID
+		summary.Blunders = result.WhiteBlunders + result.BlackBlunders
+		summary.Mistakes = result.WhiteMistakes + result.BlackMistakes
+		summary.Inaccuracies = result.WhiteInaccuracies + result.BlackInaccuracies
+	}
+	if err := s.notifier.Send(s.ctx, notify.NewAnalysisEvent(summary)); err != nil {
+		s.logger.Error("failed to send notification", "job", job.ID, "error", err)
+	}
+}
+
+// runJob analyzes the game of job with a new engine and saves the result.
+func (s *Server) runJob(ctx context.Context, job *Job) (*db.GameAnalysis, error) {
+	record, err := s.db.GetGame(ctx, job.GameID)
+	if err != nil {
+		return nil, err
+	}
+	game, err := parseGameText(record.PGNText)
+	if err != nil {
+		return nil, fmt.Errorf("game %d: %w", job.GameID, err)
+	}
+
+	eng, err := s.startEngine(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = eng.Close() }()
+
+	opts := analysis.DefaultOptions()
+	opts.Depth, opts.MultiPV = job.Depth, job.Lines
+	progress := func(done, total int) {
+		s.queue.update(job, func(j *Job) { j.Done, j.Total = done, total })
+	}
+	annotations, err := analysis.New(eng, opts, s.logger).WithProgress(progress).AnnotateGame(ctx, game)
+	if err != nil {
+		return nil, fmt.Errorf("analysis failed: %w", err)
+	}
+
+	result, evals := analysis.Record(job.GameID, opts, annotations)
+	if err := s.db.SaveAnalysis(ctx, result, evals); err != nil {
+		return nil, err
+	}
+	s.queue.update(job, func(j *Job) { j.AnalysisID = result.ID })
+	return result, nil
+}
+
+// analyzeRequest is the body of POST /api/analyze.
+type analyzeRequest struct {
+	GameID int `json:"game_id"`
+	Depth  int `json:"depth"`
+	Lines  int `json:"lines"`
+}
+
+// queueAnalysis queues the analysis of the game given by the game_id field
+// of the JSON body, at its depth and number of lines. The response is the
+// queued job, whose progress can be followed at its Location.
+func (s *Server) queueAnalysis(w http.ResponseWriter, r *http.Request) {
+	if s.openEngine == nil {
+		s.writeError(w, http.StatusServiceUnavailable, "no engine configured")
+		return
+	}
+	defaults := analysis.DefaultOptions()
+	req := analyzeRequest{Depth: defaults.Depth, Lines: defaults.MultiPV}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+	switch {
+	case req.GameID < 1:
+		s.writeError(w, http.StatusBadRequest, "game_id is required")
+		return
+	case req.Depth < 1 || req.Depth > maxAnalysisDepth:
+		s.writeError(w, http.StatusBadRequest, fmt.Sprintf("depth must be between 1 and %d", maxAnalysisDepth))
+		return
+	case req.Lines < 1 || req.Lines > maxAnalysisLines:
+		s.writeError(w, http.StatusBadRequest, fmt.Sprintf("lines must be between 1 and %d", maxAnalysisLines))
+		return
+	}
+
+	start := time.Now()
+	record, err := s.db.GetGame(r.Context(), req.GameID)
+	s.timeQuery("game_by_id", start)
+	if errors.Is(err, db.ErrGameNotFound) {
+		s.writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		s.internalError(w, err)
+		return
+	}
+
+	job, err := s.queue.add(&Job{GameID: req.GameID, White: record.White, Black: record.Black, Depth: req.Depth, Lines: req.Lines})
+	if err != nil {
+		s.writeError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	s.startWorkers()
+	w.Header().Set("Location", fmt.Sprintf("/api/analyze/%d", job.ID))
+	s.writeJSON(w, http.StatusAccepted, job)
+}
+
+// getJob returns the analysis job given by the id path parameter.
+func (s *Server) getJob(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, "invalid job id")
+		return
+	}
+	job, ok := s.queue.get(id)
+	if !ok {
+		s.writeError(w, http.StatusNotFound, "job not found")
+		return
+	}
+	s.writeJSON(w, http.StatusOK, job)
+}
+
+// listJobs returns the analysis jobs, most recent first.
+func (s *Server) listJobs(w http.ResponseWriter, r *http.Request) {
+	s.writeJSON(w, http.StatusOK, map[string]any{"jobs": s.queue.list()})
+}
+
+// getAnalysis returns the latest saved analysis of a game.
+func (s *Server) getAnalysis(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, "invalid game id")
+		return
+	}
+	start := time.Now()
+	a, err := s.db.GetLatestAnalysis(r.Context(), id)
+	s.timeQuery("latest_analysis", start)
+	if errors.Is(err, db.ErrAnalysisNotFound) {
+		s.writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		s.internalError(w, err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, a)
+}
@@ -0,0 +1,180 @@
+// Package server exposes the game database over HTTP as a JSON API, so web
+// frontends and other tools can be built on the same database as the CLI.
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/kyleboon/gochess/internal/db"
+	"github.com/kyleboon/gochess/internal/engine"
+	"github.com/kyleboon/gochess/internal/notify"
+)
+
+const (
+	// shutdownTimeout is how long running requests are given to finish when
+	// the server is stopped.
+	shutdownTimeout = 5 * time.Second
+
+	// healthTimeout is how long the health check waits for the database.
+	healthTimeout = 2 * time.Second
+)
+
+// EngineOpener starts an engine for a game analysis. The engine is closed
+// once the analysis is done.
+type EngineOpener func(ctx context.Context) (engine.Analyzer, error)
+
+// Server serves the API for a game database.
+type Server struct {
+	db         *db.DB
+	logger     *slog.Logger
+	openEngine EngineOpener
+	metrics    *metrics
+	queue      *jobQueue
+	notifier   *notify.Notifier
+
+	// ctx is canceled by Close, stopping the analysis workers
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// New returns a server for database.
+func New(database *db.DB, logger *slog.Logger) *Server {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Server{
+		db:      database,
+		logger:  logger.With("component", "server"),
+		metrics: newMetrics(),
+		queue:   newJobQueue(DefaultAnalysisWorkers),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+}
+
+// WithEngine enables game analysis, with engines started by open.
+func (s *Server) WithEngine(open EngineOpener) *Server {
+	s.openEngine = open
+	return s
+}
+
+// WithAnalysisWorkers sets how many queued analyses run at once, each with
+// its own engine. It must be called before the first analysis is queued.
+func (s *Server) WithAnalysisWorkers(n int) *Server {
+	if n > 0 {
+		s.queue.workers = n
+	}
+	return s
+}
+
+// WithNotifier sets the notifier told when a queued analysis finishes.
+func (s *Server) WithNotifier(n *notify.Notifier) *Server {
+	s.notifier = n
+	return s
+}
+
+// Close stops the analysis workers. Running analyses are canceled.
+func (s *Server) Close() {
+	s.cancel()
+}
+
+// Handler returns the HTTP handler of the API.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/games", s.listGames)
+	mux.HandleFunc("GET /api/games/{id}", s.getGame)
+	mux.HandleFunc("GET /api/games/{id}/pgn", s.getGamePGN)
+	mux.HandleFunc("GET /api/games/{id}/json", s.getGameJSON)
+	mux.HandleFunc("POST /api/games/{id}/analysis", s.analyzeGame)
+	mux.HandleFunc("GET /api/games/{id}/analysis", s.getAnalysis)
+	mux.HandleFunc("POST /api/analyze", s.queueAnalysis)
+	mux.HandleFunc("GET /api/analyze", s.listJobs)
+	mux.HandleFunc("GET /api/analyze/{id}", s.getJob)
+	mux.HandleFunc("GET /api/games/{id}/live", s.streamGame)
+	mux.HandleFunc("GET /api/explorer", s.explorePosition)
+	mux.HandleFunc("GET /api/stats/players", s.playerStats)
+	mux.HandleFunc("GET /api/diagram", s.diagram)
+	mux.HandleFunc("GET /metrics", s.serveMetrics)
+	mux.HandleFunc("GET /healthz", s.healthz)
+	mux.Handle("GET /", http.FileServerFS(webFiles))
+	return s.logRequests(mux)
+}
+
+// ListenAndServe serves the API on addr until ctx is done, then closes the
+// server.
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	defer s.Close()
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	errc := make(chan error, 1)
+	go func() { errc <- srv.ListenAndServe() }()
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			return err
+		}
+		if err := <-errc; !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	}
+}
+
+// logRequests logs each request handled by next and counts it in the
+// metrics.
+func (s *Server) logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		duration := time.Since(start)
+		s.metrics.observeRequest(r.Method, routeOf(r), rec.status, duration)
+		s.logger.Info("request", "method", r.Method, "path", r.URL.Path,
+			"status", rec.status, "duration", duration)
+	})
+}
+
+// statusRecorder remembers the status code written to a response.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Hijack lets WebSocket handlers take over the connection.
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	r.status = http.StatusSwitchingProtocols
+	return http.NewResponseController(r.ResponseWriter).Hijack()
+}
+
+// writeJSON writes v as the JSON body of a response with the given status.
+func (s *Server) writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		s.logger.Error("failed to write response", "error", err)
+	}
+}
+
+// writeError writes an error response, as a JSON object with an "error"
+// field holding message.
+func (s *Server) writeError(w http.ResponseWriter, status int, message string) {
+	s.writeJSON(w, status, map[string]string{"error": message})
+}
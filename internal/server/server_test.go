@@ -0,0 +1,333 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kyleboon/gochess/internal/db"
+	"github.com/kyleboon/gochess/internal/engine"
+	"github.com/kyleboon/gochess/internal/logging"
+	"github.com/kyleboon/gochess/internal/notify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flatAnalyzer evaluates every position as equal.
+type flatAnalyzer struct{}
+
+func (flatAnalyzer) Analyze(_ context.Context, fen string, _ engine.AnalysisOptions) (*engine.AnalysisResult, error) {
+	return &engine.AnalysisResult{FEN: fen, Depth: 1, Lines: []engine.AnalysisLine{{Rank: 1, Depth: 1}}}, nil
+}
+
+func (flatAnalyzer) Close() error { return nil }
+
+func setupServer(t *testing.T, configure ...func(*Server)) *httptest.Server {
+	t.Helper()
+	dir := t.TempDir()
+	database, err := db.NewWithLogger(filepath.Join(dir, "test.db"), logging.Discard())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = database.Close() })
+
+	pgnContent := `[Event "Club Championship"]
+[Site "Test"]
+[Date "2024.01.15"]
+[White "Alice"]
+[Black "Bob"]
+[Result "1-0"]
+
+1. e4 e5 2. Qh5 Nc6 3. Bc4 Nf6 4. Qxf7# 1-0
+
+[Event "Casual"]
+[Site "Test"]
+[Date "2024.02.01"]
+[White "Bob"]
+[Black "Carol"]
+[Result "1/2-1/2"]
+
+1. d4 d5 1/2-1/2
+`
+	pgnFile := filepath.Join(dir, "test.pgn")
+	require.NoError(t, os.WriteFile(pgnFile, []byte(pgnContent), 0644))
+	count, errs := database.ImportPGN(context.Background(), pgnFile)
+	require.Empty(t, errs)
+	require.Equal(t, 2, count)
+
+	srv := New(database, logging.Discard()).WithEngine(func(context.Context) (engine.Analyzer, error) {
+		return flatAnalyzer{}, nil
+	})
+	for _, f := range configure {
+		f(srv)
+	}
+	t.Cleanup(srv.Close)
+	ts := httptest.NewServer(srv.Handler())
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func get(t *testing.T, url string, v any) int {
+	t.Helper()
+	resp, err := http.Get(url)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+	if v != nil {
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(v))
+	}
+	return resp.StatusCode
+}
+
+func TestListGames(t *testing.T) {
+	ts := setupServer(t)
+
+	var body struct {
+		Games []map[string]any `json:"games"`
+		Limit int              `json:"limit"`
+	}
+	assert.Equal(t, http.StatusOK, get(t, ts.URL+"/api/games", &body))
+	assert.Len(t, body.Games, 2)
+	assert.Equal(t, defaultPageSize, body.Limit)
+
+	assert.Equal(t, http.StatusOK, get(t, ts.URL+"/api/games?white=alice", &body))
+	require.Len(t, body.Games, 1)
+	assert.Equal(t, "Bob", body.Games[0]["black"])
+
+	assert.Equal(t, http.StatusOK, get(t, ts.URL+"/api/games?tag=Event=Casual&tag=Black=Carol", &body))
+	require.Len(t, body.Games, 1)
+	assert.Equal(t, "Bob", body.Games[0]["white"])
+	assert.Equal(t, http.StatusBadRequest, get(t, ts.URL+"/api/games?tag=Casual", nil))
+
+	assert.Equal(t, http.StatusBadRequest, get(t, ts.URL+"/api/games?limit=0", nil))
+}
+
+func TestGetGame(t *testing.T) {
+	ts := setupServer(t)
+
+	var game map[string]any
+	assert.Equal(t, http.StatusOK, get(t, ts.URL+"/api/games/1", &game))
+	assert.Equal(t, "Alice", game["white"])
+	assert.NotContains(t, game, "pgn_text")
+
+	var e map[string]string
+	assert.Equal(t, http.StatusNotFound, get(t, ts.URL+"/api/games/99", &e))
+	assert.Contains(t, e["error"], "game not found")
+	assert.Equal(t, http.StatusBadRequest, get(t, ts.URL+"/api/games/abc", nil))
+
+	resp, err := http.Get(ts.URL + "/api/games/1/pgn")
+	require.NoError(t, err)
+	text, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	assert.True(t, strings.Contains(string(text), "Qxf7#"))
+
+	var j struct {
+		Moves []struct {
+			SAN string `json:"san"`
+		} `json:"moves"`
+	}
+	assert.Equal(t, http.StatusOK, get(t, ts.URL+"/api/games/1/json", &j))
+	require.Len(t, j.Moves, 7)
+	assert.Equal(t, "Qxf7#", j.Moves[6].SAN)
+}
+
+func TestExplorer(t *testing.T) {
+	ts := setupServer(t)
+
+	var body struct {
+		Moves []explorerMove `json:"moves"`
+	}
+	assert.Equal(t, http.StatusOK, get(t, ts.URL+"/api/explorer", &body))
+	require.Len(t, body.Moves, 2)
+	assert.Contains(t, []string{"e4", "d4"}, body.Moves[0].SAN)
+	assert.NotEmpty(t, body.Moves[0].FEN)
+
+	assert.Equal(t, http.StatusBadRequest, get(t, ts.URL+"/api/explorer?fen=nonsense", nil))
+}
+
+func TestPlayerStats(t *testing.T) {
+	ts := setupServer(t)
+
+	var stats []db.PlayerStats
+	assert.Equal(t, http.StatusOK, get(t, ts.URL+"/api/stats/players?player=Bob", &stats))
+	require.Len(t, stats, 1)
+	assert.Equal(t, 2, stats[0].Games)
+
+	// Keys are snake_case, as elsewhere in the API, nested ones included
+	var raw []map[string]any
+	assert.Equal(t, http.StatusOK, get(t, ts.URL+"/api/stats/players?player=Bob", &raw))
+	require.Len(t, raw, 1)
+	assert.Contains(t, raw[0], "win_rate")
+	assert.NotContains(t, raw[0], "WinRate")
+	assert.Contains(t, raw[0], "rating_bands")
+}
+
+func TestAnalyzeGame(t *testing.T) {
+	ts := setupServer(t)
+
+	resp, err := http.Post(ts.URL+"/api/games/2/analysis?depth=1&lines=1", "", nil)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var body struct {
+		Moves []analyzedMove `json:"moves"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	require.Len(t, body.Moves, 2)
+	assert.Equal(t, "d4", body.Moves[0].SAN)
+	assert.Equal(t, "black", body.Moves[1].Color)
+
+	// The engine work a request can ask for is bounded
+	for _, query := range []string{"depth=0", "depth=1000", "lines=0", "lines=500", "depth=1000&lines=500"} {
+		resp, err := http.Post(ts.URL+"/api/games/2/analysis?"+query, "", nil)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, query)
+	}
+}
+
+func TestDiagram(t *testing.T) {
+	ts := setupServer(t)
+
+	resp, err := http.Get(ts.URL + "/api/diagram?last_move=e2e4&fen=" + url.QueryEscape(startFEN))
+	require.NoError(t, err)
+	svg, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "image/svg+xml", resp.Header.Get("Content-Type"))
+	assert.True(t, strings.HasPrefix(string(svg), "<svg "))
+
+	assert.Equal(t, http.StatusBadRequest, get(t, ts.URL+"/api/diagram?fen=nonsense", nil))
+	assert.Equal(t, http.StatusBadRequest, get(t, ts.URL+"/api/diagram?last_move=e9&fen="+url.QueryEscape(startFEN), nil))
+}
+
+func TestWebUI(t *testing.T) {
+	ts := setupServer(t)
+
+	for _, path := range []string{"/", "/app.js", "/style.css"} {
+		resp, err := http.Get(ts.URL + path)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode, path)
+	}
+}
+
+func TestHealthz(t *testing.T) {
+	ts := setupServer(t)
+
+	var body map[string]string
+	assert.Equal(t, http.StatusOK, get(t, ts.URL+"/healthz", &body))
+	assert.Equal(t, "ok", body["status"])
+}
+
+func TestMetrics(t *testing.T) {
+	ts := setupServer(t)
+
+	assert.Equal(t, http.StatusOK, get(t, ts.URL+"/api/games/1", nil))
+	assert.Equal(t, http.StatusOK, get(t, ts.URL+"/api/games/2", nil))
+	assert.Equal(t, http.StatusNotFound, get(t, ts.URL+"/api/games/99", nil))
+	resp, err := http.Post(ts.URL+"/api/games/2/analysis?depth=1", "", nil)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	resp, err = http.Get(ts.URL + "/metrics")
+	require.NoError(t, err)
+	text, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.True(t, strings.HasPrefix(resp.Header.Get("Content-Type"), "text/plain"))
+
+	metrics := string(text)
+	assert.Contains(t, metrics, "# TYPE gochess_http_requests_total counter\n")
+	// Requests are counted by route, not by path
+	assert.Contains(t, metrics, `gochess_http_requests_total{method="GET",route="/api/games/{id}",status="200"} 2`)
+	assert.Contains(t, metrics, `gochess_http_requests_total{method="GET",route="/api/games/{id}",status="404"} 1`)
+	assert.Contains(t, metrics, `gochess_db_query_duration_seconds_count{query="game_by_id"} 4`)
+	assert.Contains(t, metrics, `gochess_http_request_duration_seconds_bucket{route="/api/games/{id}",le="+Inf"} 3`)
+	assert.Contains(t, metrics, "gochess_games 2\n")
+	assert.Contains(t, metrics, "gochess_engine_starts_total 1\n")
+	assert.Contains(t, metrics, "gochess_engines_in_use 0\n")
+}
+
+func TestQueueAnalysis(t *testing.T) {
+	notifications := make(chan notify.Event, 1)
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var e notify.Event
+		if err := json.NewDecoder(r.Body).Decode(&e); err == nil {
+			notifications <- e
+		}
+	}))
+	t.Cleanup(hook.Close)
+	ts := setupServer(t, func(s *Server) {
+		s.WithNotifier(notify.New(logging.Discard()).WithWebhook(hook.URL))
+	})
+
+	post := func(body string) (*http.Response, Job) {
+		t.Helper()
+		resp, err := http.Post(ts.URL+"/api/analyze", "application/json", strings.NewReader(body))
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+		var job Job
+		if resp.StatusCode == http.StatusAccepted {
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&job))
+		}
+		return resp, job
+	}
+
+	resp, job := post(`{"game_id": 1, "depth": 1, "lines": 1}`)
+	require.Equal(t, http.StatusAccepted, resp.StatusCode)
+	assert.Equal(t, fmt.Sprintf("/api/analyze/%d", job.ID), resp.Header.Get("Location"))
+	assert.Equal(t, 1, job.GameID)
+
+	// The job is run in the background
+	require.Eventually(t, func() bool {
+		get(t, ts.URL+resp.Header.Get("Location"), &job)
+		return job.Status == JobDone || job.Status == JobFailed
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, JobDone, job.Status, job.Error)
+	assert.Equal(t, 8, job.Total)
+	assert.Equal(t, job.Total, job.Done)
+	assert.NotZero(t, job.AnalysisID)
+
+	assert.Equal(t, "Alice", job.White)
+
+	select {
+	case e := <-notifications:
+		assert.Equal(t, notify.EventAnalysis, e.Type)
+		require.NotNil(t, e.Analysis)
+		assert.Equal(t, 1, e.Analysis.GameID)
+		assert.Equal(t, job.AnalysisID, e.Analysis.AnalysisID)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no notification sent")
+	}
+
+	var saved db.GameAnalysis
+	assert.Equal(t, http.StatusOK, get(t, ts.URL+"/api/games/1/analysis", &saved))
+	assert.Equal(t, job.AnalysisID, saved.ID)
+	require.Len(t, saved.Moves, 7)
+	assert.Equal(t, "Qxf7#", saved.Moves[6].SAN)
+
+	var jobs struct {
+		Jobs []Job `json:"jobs"`
+	}
+	assert.Equal(t, http.StatusOK, get(t, ts.URL+"/api/analyze", &jobs))
+	assert.Len(t, jobs.Jobs, 1)
+
+	resp, _ = post(`{"game_id": 99}`)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	resp, _ = post(`{}`)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	resp, _ = post(`{"game_id": 1, "depth": 1000}`)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	resp, _ = post(`{"game_id": 1, "lines": 500}`)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, http.StatusNotFound, get(t, ts.URL+"/api/analyze/42", nil))
+	assert.Equal(t, http.StatusNotFound, get(t, ts.URL+"/api/games/2/analysis", nil))
+}
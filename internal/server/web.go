@@ -0,0 +1,13 @@
+package server
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed web
+var webContent embed.FS
+
+// webFiles is the single page web UI served at the root: a game list, a
+// game viewer and an opening explorer built on the API.
+var webFiles, _ = fs.Sub(webContent, "web")
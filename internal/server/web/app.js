@@ -0,0 +1,263 @@
+// The gochess web UI: a game list, a game viewer and an opening explorer,
+// built on the JSON API and the live WebSocket of `gochess serve`.
+"use strict";
+
+const START_FEN = "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1";
+const PAGE_SIZE = 50;
+
+const app = document.getElementById("app");
+
+// el creates an element with the given attributes and children. Strings are
+// added as text, so values from the database are never parsed as HTML.
+function el(tag, attrs, ...children) {
+  const e = document.createElement(tag);
+  for (const [name, value] of Object.entries(attrs || {})) {
+    if (name.startsWith("on")) {
+      e.addEventListener(name.slice(2), value);
+    } else {
+      e.setAttribute(name, value);
+    }
+  }
+  for (const c of children) {
+    e.append(c);
+  }
+  return e;
+}
+
+async function api(path) {
+  const resp = await fetch(path);
+  const body = await resp.json();
+  if (!resp.ok) {
+    throw new Error(body.error || resp.statusText);
+  }
+  return body;
+}
+
+function showError(err) {
+  app.replaceChildren(el("p", { class: "error" }, String(err.message || err)));
+}
+
+function diagramURL(fen, lastMove, flip) {
+  const q = new URLSearchParams({ fen: fen });
+  if (lastMove) {
+    q.set("last_move", lastMove);
+  }
+  if (flip) {
+    q.set("flip", "true");
+  }
+  return "/api/diagram?" + q;
+}
+
+function liveSocket(path) {
+  const scheme = location.protocol === "https:" ? "wss://" : "ws://";
+  return new WebSocket(scheme + location.host + path);
+}
+
+// Game list
+
+async function showGames(params) {
+  const offset = Number(params.get("offset") || 0);
+  const form = el("form", { class: "search" });
+  for (const field of ["white", "black", "event", "date", "result"]) {
+    form.append(el("input", { name: field, placeholder: field, value: params.get(field) || "" }));
+  }
+  form.append(el("button", { type: "submit" }, "Search"));
+  form.addEventListener("submit", (e) => {
+    e.preventDefault();
+    const q = new URLSearchParams();
+    for (const input of form.querySelectorAll("input")) {
+      if (input.value) {
+        q.set(input.name, input.value);
+      }
+    }
+    location.hash = "#/?" + q;
+  });
+
+  const q = new URLSearchParams(params);
+  q.set("limit", PAGE_SIZE);
+  q.set("offset", offset);
+  const { games } = await api("/api/games?" + q);
+
+  const table = el("table", {},
+    el("tr", {}, ...["Date", "White", "Black", "Result", "Event"].map((h) => el("th", {}, h))));
+  for (const g of games) {
+    table.append(el("tr", { class: "link", onclick: () => { location.hash = "#/game/" + g.id; } },
+      el("td", {}, g.date), el("td", {}, g.white), el("td", {}, g.black),
+      el("td", {}, g.result), el("td", {}, g.event)));
+  }
+
+  const pager = el("div", { class: "pager" });
+  const page = (o) => {
+    const p = new URLSearchParams(params);
+    p.set("offset", o);
+    return "#/?" + p;
+  };
+  if (offset > 0) {
+    pager.append(el("a", { href: page(Math.max(0, offset - PAGE_SIZE)) }, "« Previous"), " ");
+  }
+  if (games.length === PAGE_SIZE) {
+    pager.append(el("a", { href: page(offset + PAGE_SIZE) }, "Next »"));
+  }
+
+  app.replaceChildren(form, games.length ? table : el("p", {}, "No games found."), pager);
+}
+
+// Game viewer
+
+async function showGame(id) {
+  const game = await api("/api/games/" + id);
+  const positions = [];
+  let current = 0;
+  let flip = false;
+
+  const board = el("img", { class: "board", alt: "board" });
+  const moves = el("div", { class: "moves" });
+  const status = el("div", { class: "status" });
+
+  function draw() {
+    const p = positions[current];
+    if (!p) {
+      return;
+    }
+    board.src = diagramURL(p.fen, p.uci, flip);
+    for (const m of moves.querySelectorAll(".move")) {
+      m.classList.toggle("current", Number(m.dataset.ply) === current);
+    }
+  }
+
+  function go(ply) {
+    current = Math.max(0, Math.min(positions.length - 1, ply));
+    draw();
+  }
+
+  function evalText(p) {
+    if (p.eval === undefined) {
+      return "";
+    }
+    if (p.mate) {
+      return "#" + p.mate;
+    }
+    return (p.eval > 0 ? "+" : "") + p.eval.toFixed(2);
+  }
+
+  function listMoves() {
+    moves.replaceChildren();
+    for (const p of positions.slice(1)) {
+      if (p.ply % 2 === 1) {
+        moves.append((p.ply + 1) / 2 + ". ");
+      }
+      moves.append(el("span", { class: "move", "data-ply": p.ply, onclick: () => go(p.ply) }, p.move), " ");
+      const e = evalText(p);
+      if (e) {
+        moves.append(el("span", { class: "eval" }, e));
+      }
+    }
+    draw();
+  }
+
+  // The positions are streamed without delay; analysis adds evaluations
+  function stream(mode) {
+    const ws = liveSocket("/api/games/" + id + "/live?delay=0s&mode=" + mode);
+    let done = false;
+    status.textContent = mode === "analysis" ? "Analyzing…" : "Loading…";
+    ws.onmessage = (e) => {
+      const u = JSON.parse(e.data);
+      if (u.type === "position") {
+        positions[u.ply] = u;
+        if (mode === "analysis") {
+          status.textContent = "Analyzing… move " + Math.ceil(u.ply / 2);
+        }
+      } else if (u.type === "done") {
+        done = true;
+        status.textContent = "";
+      } else if (u.type === "error") {
+        done = true;
+        status.textContent = u.error;
+      }
+      listMoves();
+    };
+    ws.onclose = () => {
+      if (!done) {
+        status.textContent = mode === "analysis" ? "Analysis is not available." : "Connection closed.";
+      }
+    };
+  }
+
+  const controls = el("div", { class: "controls" },
+    el("button", { onclick: () => go(0) }, "⏮"),
+    el("button", { onclick: () => go(current - 1) }, "◀"),
+    el("button", { onclick: () => go(current + 1) }, "▶"),
+    el("button", { onclick: () => go(positions.length - 1) }, "⏭"), " ",
+    el("button", { onclick: () => { flip = !flip; draw(); } }, "Flip"), " ",
+    el("button", { onclick: () => stream("analysis") }, "Analyze"), " ",
+    el("a", { href: "/api/games/" + id + "/pgn", download: "game-" + id + ".pgn" }, "PGN"));
+
+  const title = el("h2", {}, `${game.white} – ${game.black} ${game.result}`);
+  const info = el("p", {}, [game.event, game.date, game.opening_name].filter(Boolean).join(" · "));
+  app.replaceChildren(title, info,
+    el("div", { class: "viewer" }, el("div", {}, board, controls, status), moves));
+
+  document.onkeydown = (e) => {
+    if (e.key === "ArrowLeft") {
+      go(current - 1);
+    } else if (e.key === "ArrowRight") {
+      go(current + 1);
+    }
+  };
+  stream("replay");
+}
+
+// Opening explorer
+
+async function showExplorer(params) {
+  const fen = params.get("fen") || START_FEN;
+  const { moves } = await api("/api/explorer?fen=" + encodeURIComponent(fen));
+
+  const pct = (n, total) => Math.round(100 * n / total) + "%";
+  const table = el("table", {},
+    el("tr", {}, ...["Move", "Games", "White", "Draw", "Black"].map((h) => el("th", {}, h))));
+  for (const m of moves) {
+    const row = el("tr", {},
+      el("td", {}, m.san || m.uci), el("td", {}, String(m.games)),
+      el("td", {}, pct(m.white_wins, m.games)), el("td", {}, pct(m.draws, m.games)),
+      el("td", {}, pct(m.black_wins, m.games)));
+    if (m.fen) {
+      row.classList.add("link");
+      row.addEventListener("click", () => {
+        location.hash = "#/explorer?" + new URLSearchParams({ fen: m.fen });
+      });
+    }
+    table.append(row);
+  }
+
+  app.replaceChildren(
+    el("div", { class: "viewer" },
+      el("div", {},
+        el("img", { class: "board", alt: "board", src: diagramURL(fen) }),
+        el("div", { class: "controls" },
+          el("button", { onclick: () => history.back() }, "◀ Back"), " ",
+          el("a", { href: "#/explorer" }, "Start position")),
+        el("div", { class: "status" }, fen)),
+      moves.length ? table : el("p", {}, "No games reached this position.")));
+}
+
+// Routing
+
+function route() {
+  document.onkeydown = null;
+  const [path, query] = location.hash.slice(1).split("?");
+  const params = new URLSearchParams(query || "");
+  const game = path.match(/^\/game\/(\d+)$/);
+  let view;
+  if (game) {
+    view = showGame(game[1]);
+  } else if (path === "/explorer") {
+    view = showExplorer(params);
+  } else {
+    view = showGames(params);
+  }
+  view.catch(showError);
+}
+
+window.addEventListener("hashchange", route);
+route();
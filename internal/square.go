@@ -0,0 +1,87 @@
+package internal
+
+import "iter"
+
+// Squares returns an iterator over the squares of the board, from a1 to h8
+// rank by rank.
+func Squares() iter.Seq[Sq] {
+	return func(yield func(Sq) bool) {
+		for sq := A1; sq <= H8; sq++ {
+			if !yield(sq) {
+				return
+			}
+		}
+	}
+}
+
+// All returns an iterator over the squares in the set, in ascending order.
+func (bb Bitboard) All() iter.Seq[Sq] {
+	return func(yield func(Sq) bool) {
+		for bb != 0 {
+			if !yield(bb.Pop()) {
+				return
+			}
+		}
+	}
+}
+
+// Up returns the square one rank further up the board as seen by color (a
+// rank higher for White, lower for Black), or NoSquare at the edge.
+func (sq Sq) Up(color int) Sq {
+	return sq.towards(0, []int{1, -1}[color])
+}
+
+// Down returns the square one rank back towards color's side of the board,
+// or NoSquare at the edge.
+func (sq Sq) Down(color int) Sq {
+	return sq.towards(0, []int{-1, 1}[color])
+}
+
+// Left returns the square one file to the left as seen by color (towards
+// the a-file for White, the h-file for Black), or NoSquare at the edge.
+func (sq Sq) Left(color int) Sq {
+	return sq.towards([]int{-1, 1}[color], 0)
+}
+
+// Right returns the square one file to the right as seen by color, or
+// NoSquare at the edge.
+func (sq Sq) Right(color int) Sq {
+	return sq.towards([]int{1, -1}[color], 0)
+}
+
+// towards returns the square df files and dr ranks away, or NoSquare if
+// that is off the board.
+func (sq Sq) towards(df, dr int) Sq {
+	if sq == NoSquare {
+		return NoSquare
+	}
+	return Square(sq.File()+df, sq.Rank()+dr)
+}
+
+// FileDistance returns the number of files between a and b.
+func FileDistance(a, b Sq) int {
+	return abs(a.File() - b.File())
+}
+
+// RankDistance returns the number of ranks between a and b.
+func RankDistance(a, b Sq) int {
+	return abs(a.Rank() - b.Rank())
+}
+
+// ChebyshevDistance returns the larger of the file and rank distances
+// between a and b.
+func ChebyshevDistance(a, b Sq) int {
+	return max(FileDistance(a, b), RankDistance(a, b))
+}
+
+// ManhattanDistance returns the sum of the file and rank distances between
+// a and b: the number of rook steps of one square from one to the other.
+func ManhattanDistance(a, b Sq) int {
+	return FileDistance(a, b) + RankDistance(a, b)
+}
+
+// KingDistance returns the number of moves a king needs to go from a to b on
+// an empty board, which is their Chebyshev distance.
+func KingDistance(a, b Sq) int {
+	return ChebyshevDistance(a, b)
+}
@@ -0,0 +1,76 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSquares(t *testing.T) {
+	var squares []Sq
+	for sq := range Squares() {
+		squares = append(squares, sq)
+	}
+	assert.Len(t, squares, 64)
+	assert.Equal(t, A1, squares[0])
+	assert.Equal(t, H1, squares[7])
+	assert.Equal(t, H8, squares[63])
+
+	// Stopping early
+	n := 0
+	for range Squares() {
+		if n++; n == 3 {
+			break
+		}
+	}
+	assert.Equal(t, 3, n)
+
+	squares = nil
+	for sq := range BitboardOf(C3, A1, H8).All() {
+		squares = append(squares, sq)
+	}
+	assert.Equal(t, []Sq{A1, C3, H8}, squares)
+}
+
+func TestSquareDirections(t *testing.T) {
+	tests := []struct {
+		sq                    Sq
+		color                 int
+		up, down, left, right Sq
+	}{
+		{E4, White, E5, E3, D4, F4},
+		{E4, Black, E3, E5, F4, D4},
+		{A1, White, A2, NoSquare, NoSquare, B1},
+		{A1, Black, NoSquare, A2, B1, NoSquare},
+		{H8, White, NoSquare, H7, G8, NoSquare},
+		{NoSquare, White, NoSquare, NoSquare, NoSquare, NoSquare},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.up, tt.sq.Up(tt.color), "%s.Up(%d)", tt.sq, tt.color)
+		assert.Equal(t, tt.down, tt.sq.Down(tt.color), "%s.Down(%d)", tt.sq, tt.color)
+		assert.Equal(t, tt.left, tt.sq.Left(tt.color), "%s.Left(%d)", tt.sq, tt.color)
+		assert.Equal(t, tt.right, tt.sq.Right(tt.color), "%s.Right(%d)", tt.sq, tt.color)
+	}
+}
+
+func TestDistances(t *testing.T) {
+	tests := []struct {
+		a, b                 Sq
+		file, rank           int
+		chebyshev, manhattan int
+	}{
+		{A1, A1, 0, 0, 0, 0},
+		{A1, H8, 7, 7, 7, 14},
+		{E1, E8, 0, 7, 7, 7},
+		{B1, C3, 1, 2, 2, 3},
+		{G7, B2, 5, 5, 5, 10},
+		{H1, A2, 7, 1, 7, 8},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.file, FileDistance(tt.a, tt.b), "FileDistance(%s, %s)", tt.a, tt.b)
+		assert.Equal(t, tt.rank, RankDistance(tt.a, tt.b), "RankDistance(%s, %s)", tt.a, tt.b)
+		assert.Equal(t, tt.chebyshev, ChebyshevDistance(tt.a, tt.b), "ChebyshevDistance(%s, %s)", tt.a, tt.b)
+		assert.Equal(t, tt.chebyshev, KingDistance(tt.b, tt.a), "KingDistance(%s, %s)", tt.b, tt.a)
+		assert.Equal(t, tt.manhattan, ManhattanDistance(tt.a, tt.b), "ManhattanDistance(%s, %s)", tt.a, tt.b)
+	}
+}
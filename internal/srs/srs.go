@@ -0,0 +1,79 @@
+// Package srs implements SM-2 spaced repetition: each item reviewed is
+// given an ease and an interval that grow while it is recalled well and
+// start over when it is forgotten, so that items are shown again just
+// before they would be forgotten.
+package srs
+
+import (
+	"math"
+	"time"
+)
+
+// Quality grades a review, from 0 (not recalled at all) to 5 (recalled
+// at once). Grades below Pass mean the item was forgotten.
+type Quality int
+
+// Review grades, as in SM-2.
+const (
+	Blackout Quality = 0 // not recalled at all
+	Wrong    Quality = 1 // wrong, though the answer looked familiar
+	Hard     Quality = 2 // wrong, but almost recalled
+	Pass     Quality = 3 // recalled, with serious difficulty
+	Good     Quality = 4 // recalled after some hesitation
+	Perfect  Quality = 5 // recalled at once
+)
+
+// Ease bounds: a new item starts at DefaultEase, and no item gets easier to
+// forget than MinEase.
+const (
+	DefaultEase = 2.5
+	MinEase     = 1.3
+)
+
+// Day is the unit of review intervals.
+const Day = 24 * time.Hour
+
+// Card is the scheduling state of an item.
+type Card struct {
+	Ease        float64   `json:"ease"`        // growth factor of the interval
+	Interval    int       `json:"interval"`    // days until the next review
+	Repetitions int       `json:"repetitions"` // reviews passed in a row
+	Due         time.Time `json:"due"`         // when the item is next shown
+}
+
+// New returns the card of a new item, due at once.
+func New(now time.Time) Card {
+	return Card{Ease: DefaultEase, Due: now}
+}
+
+// Review returns the card after a review graded q at time now. A passed
+// review schedules the item 1 day later, then 6, then at intervals growing
+// by the ease; a failed one starts over at 1 day. The ease changes with the
+// grade either way.
+func (c Card) Review(q Quality, now time.Time) Card {
+	q = max(Blackout, min(Perfect, q))
+	if q >= Pass {
+		switch c.Repetitions {
+		case 0:
+			c.Interval = 1
+		case 1:
+			c.Interval = 6
+		default:
+			c.Interval = int(math.Round(float64(c.Interval) * c.Ease))
+		}
+		c.Repetitions++
+	} else {
+		c.Repetitions = 0
+		c.Interval = 1
+	}
+
+	miss := float64(Perfect - q)
+	c.Ease = math.Max(MinEase, c.Ease+0.1-miss*(0.08+miss*0.02))
+	c.Due = now.Add(time.Duration(c.Interval) * Day)
+	return c
+}
+
+// IsDue reports whether the card is due for review by time t.
+func (c Card) IsDue(t time.Time) bool {
+	return !c.Due.After(t)
+}
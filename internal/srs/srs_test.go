@@ -0,0 +1,92 @@
+package srs
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+var start = time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+
+func TestNew(t *testing.T) {
+	c := New(start)
+	if c.Ease != DefaultEase || c.Interval != 0 || c.Repetitions != 0 {
+		t.Errorf("unexpected new card %+v", c)
+	}
+	if !c.IsDue(start) {
+		t.Error("expected a new card to be due at once")
+	}
+}
+
+func TestReview_Intervals(t *testing.T) {
+	// Passed reviews are scheduled after 1 day, 6 days, then by the ease
+	c := New(start)
+	now := start
+	var intervals []int
+	for i := 0; i < 4; i++ {
+		c = c.Review(Good, now)
+		intervals = append(intervals, c.Interval)
+		now = c.Due
+	}
+	expected := []int{1, 6, 15, 38}
+	for i := range expected {
+		if intervals[i] != expected[i] {
+			t.Fatalf("intervals = %v, expected %v", intervals, expected)
+		}
+	}
+	if c.Ease != DefaultEase {
+		t.Errorf("expected good reviews to keep the ease at %v, got %v", DefaultEase, c.Ease)
+	}
+	if !c.Due.Equal(now) || c.IsDue(now.Add(-time.Hour)) {
+		t.Errorf("expected the card to be due at %v, got %v", now, c.Due)
+	}
+}
+
+func TestReview_Ease(t *testing.T) {
+	tests := []struct {
+		q    Quality
+		ease float64
+	}{
+		{Perfect, 2.6},
+		{Good, 2.5},
+		{Pass, 2.36},
+		{Hard, 2.18},
+		{Wrong, 1.96},
+		{Blackout, 1.7},
+	}
+	for _, tt := range tests {
+		c := New(start).Review(tt.q, start)
+		if math.Abs(c.Ease-tt.ease) > 1e-9 {
+			t.Errorf("Review(%d): ease = %v, expected %v", tt.q, c.Ease, tt.ease)
+		}
+	}
+
+	// The ease never drops below MinEase
+	c := New(start)
+	for i := 0; i < 10; i++ {
+		c = c.Review(Blackout, start)
+	}
+	if c.Ease != MinEase {
+		t.Errorf("expected the ease to stop at %v, got %v", MinEase, c.Ease)
+	}
+}
+
+func TestReview_Forgotten(t *testing.T) {
+	c := New(start)
+	for i := 0; i < 3; i++ {
+		c = c.Review(Perfect, c.Due)
+	}
+	if c.Repetitions != 3 || c.Interval <= 6 {
+		t.Fatalf("unexpected card after three perfect reviews: %+v", c)
+	}
+
+	// A failed review starts the item over
+	now := c.Due
+	c = c.Review(Wrong, now)
+	if c.Repetitions != 0 || c.Interval != 1 {
+		t.Errorf("expected a forgotten card to start over, got %+v", c)
+	}
+	if !c.Due.Equal(now.Add(Day)) {
+		t.Errorf("expected the card to be due a day later, got %v", c.Due)
+	}
+}
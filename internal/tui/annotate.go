@@ -0,0 +1,229 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/kyleboon/gochess/internal"
+	"github.com/kyleboon/gochess/internal/pgn"
+)
+
+// SaveGameFunc persists the annotated PGN text of the game with the given ID.
+type SaveGameFunc func(gameID int, pgnText string) error
+
+// maxVariationRows is the number of continuations listed under the move list.
+const maxVariationRows = 4
+
+// Annotations cycled through with the n and N keys.
+var (
+	moveNags     = []pgn.Nag{1, 2, 3, 4, 5, 6}               // ! ? !! ?? !? ?!
+	positionNags = []pgn.Nag{10, 13, 14, 15, 16, 17, 18, 19} // = ∞ ⩲ ⩱ ± ∓ +- -+
+)
+
+// cycleNag replaces the NAG of n that is in set with the next one in set,
+// ending with none.
+func cycleNag(n *pgn.Node, set []pgn.Nag) {
+	next := 0
+	for i, nag := range set {
+		for _, x := range n.Nags {
+			if x == nag {
+				n.DropNag(nag)
+				next = i + 1
+			}
+		}
+	}
+	if next < len(set) {
+		n.AddNag(set[next])
+	}
+}
+
+// setComment replaces the comment text of n, keeping any embedded commands
+// such as clock times and evaluations.
+func setComment(n *pgn.Node, text string) {
+	var parts []string
+	for _, c := range n.Comment {
+		parts = append(parts, commandPattern.FindAllString(c, -1)...)
+	}
+	if text = strings.TrimSpace(text); text != "" {
+		parts = append(parts, text)
+	}
+	n.Comment = nil
+	if len(parts) > 0 {
+		n.Comment = []string{strings.Join(parts, " ")}
+	}
+}
+
+// SetGameSaver sets the function that persists annotations. Without one,
+// edits are kept for the session only.
+func (m *GameViewModel) SetGameSaver(save SaveGameFunc) {
+	m.saveGame = save
+}
+
+// capturesKeys reports whether a settings screen, comment editor, export
+// form or the help overlay is open, which handles its own enter, escape and
+// quit keys.
+func (m GameViewModel) capturesKeys() bool {
+	return m.settings != nil || m.editor != nil || m.export != nil || m.showHelp
+}
+
+// editComment opens the comment editor on the current move.
+func (m *GameViewModel) editComment() tea.Cmd {
+	editor := textinput.New()
+	editor.Prompt = "Comment: "
+	editor.Placeholder = "empty to remove"
+	editor.SetValue(commentText(m.current))
+	editor.Width = max(m.width, MinWidth) - len(editor.Prompt) - 2
+	cmd := editor.Focus()
+	m.editor = &editor
+	return cmd
+}
+
+// updateEditor forwards a message to the comment editor, storing the comment
+// on enter and discarding it on escape.
+func (m *GameViewModel) updateEditor(msg tea.Msg) tea.Cmd {
+	if key, ok := msg.(tea.KeyMsg); ok {
+		switch key.String() {
+		case "enter":
+			setComment(m.current, m.editor.Value())
+			m.editor = nil
+			return m.saveAnnotations()
+		case "esc":
+			m.editor = nil
+			return nil
+		}
+	}
+	editor, cmd := m.editor.Update(msg)
+	m.editor = &editor
+	return cmd
+}
+
+// saveAnnotations returns a command that writes the game, with its comments,
+// NAGs and variations, back through the game saver in the background.
+func (m *GameViewModel) saveAnnotations() tea.Cmd {
+	text := m.game.String()
+	m.info.PGNText = text
+	if m.saveGame == nil {
+		return nil
+	}
+	save, id := m.saveGame, m.info.ID
+	return notifyResult(func() error { return save(id, text) }, "Game saved", "Failed to save game")
+}
+
+// continuations returns the moves that can follow the current position: the
+// next move of the current line, then its alternatives.
+func (m GameViewModel) continuations() []*pgn.Node {
+	next := m.current.Next
+	if next == nil {
+		return nil
+	}
+	nodes := []*pgn.Node{next}
+	for _, v := range next.Variations() {
+		nodes = append(nodes, v.Next)
+	}
+	return nodes
+}
+
+// inVariation reports whether the current move is off the main line.
+func (m GameViewModel) inVariation() bool {
+	return m.current.Parent != nil && m.plyOf(m.current) == 0
+}
+
+// variationRows returns the number of continuations listed under the move
+// list, which is zero unless there is a choice of moves.
+func (m GameViewModel) variationRows() int {
+	n := len(m.continuations())
+	if n < 2 {
+		return 0
+	}
+	return min(n, maxVariationRows)
+}
+
+// variationsHeight returns the height of the variations block, including
+// its blank line and heading, or zero if it is not shown.
+func (m GameViewModel) variationsHeight() int {
+	rows := m.variationRows()
+	if m.inVariation() {
+		rows++
+	}
+	if rows == 0 {
+		return 0
+	}
+	return rows + 2
+}
+
+// lineText formats the moves starting at n, as in "3. Bb5 a6 4. Ba4", cut
+// to width.
+func lineText(n *pgn.Node, width int) string {
+	text := moveLabel(n)
+	for n = n.Next; n != nil; n = n.Next {
+		san := moveSan(n)
+		if n.Parent.Board.SideToMove == internal.White {
+			san = moveLabel(n)
+		}
+		if lipgloss.Width(text)+1+lipgloss.Width(san) > width {
+			break
+		}
+		text += " " + san
+	}
+	return text
+}
+
+// variationStart returns the first move of the variation containing n.
+func variationStart(n *pgn.Node) *pgn.Node {
+	for !n.Parent.IsRoot() {
+		n = n.Parent
+	}
+	return n
+}
+
+// renderVariations renders the variation tree at the current position: the
+// line being followed when off the main line, and the continuations to choose
+// from with tab.
+func (m GameViewModel) renderVariations() string {
+	if m.variationsHeight() == 0 {
+		return ""
+	}
+	width := max(moveListWidth, m.width-m.moveListX()-1)
+	muted := lipgloss.NewStyle().Foreground(ColorTextMuted)
+	chosen := lipgloss.NewStyle().Foreground(ColorAccent).Bold(true)
+
+	lines := []string{"", SubtitleStyle.UnsetMargins().Render("Variations")}
+	if m.inVariation() {
+		// Show the end of the variation up to the current move
+		var sans []string
+		start := variationStart(m.current)
+		for n := start; ; n = n.Next {
+			if n == start || n.Parent.Board.SideToMove == internal.White {
+				sans = append(sans, moveLabel(n))
+			} else {
+				sans = append(sans, moveSan(n))
+			}
+			if n == m.current {
+				break
+			}
+		}
+		line := strings.Join(sans, " ")
+		for lipgloss.Width(line) > width-8 && len(sans) > 1 {
+			sans = sans[1:]
+			line = "… " + strings.Join(sans, " ")
+		}
+		lines = append(lines, muted.Render("line: ")+line)
+	}
+
+	conts := m.continuations()
+	if rows := m.variationRows(); rows > 0 {
+		// Scroll so the chosen continuation is listed
+		first := max(0, m.choice-rows+1)
+		for i := first; i < first+rows; i++ {
+			text := lineText(conts[i], width-2)
+			if i == m.choice {
+				lines = append(lines, chosen.Render("> "+text))
+			} else {
+				lines = append(lines, muted.Render("  "+text))
+			}
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,177 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/kyleboon/gochess/internal"
+)
+
+// boardSquareWidth is the width of a rendered square in cells.
+const boardSquareWidth = 3
+
+// HighlightStyle selects how the squares of the last move are marked.
+type HighlightStyle int
+
+const (
+	HighlightSquares HighlightStyle = iota // color the squares
+	HighlightMarkers                       // bracket the squares: [♞]
+	HighlightNone                          // no highlighting
+)
+
+// String returns the name of the highlight style.
+func (h HighlightStyle) String() string {
+	switch h {
+	case HighlightMarkers:
+		return "markers"
+	case HighlightNone:
+		return "none"
+	}
+	return "squares"
+}
+
+// ParseHighlightStyle parses a highlight style name. The empty string selects
+// the default square highlighting.
+func ParseHighlightStyle(s string) (HighlightStyle, error) {
+	for h := HighlightSquares; h <= HighlightNone; h++ {
+		if strings.EqualFold(s, h.String()) {
+			return h, nil
+		}
+	}
+	if s == "" {
+		return HighlightSquares, nil
+	}
+	return HighlightSquares, fmt.Errorf("unknown highlight style %q (expected squares, markers or none)", s)
+}
+
+// Next returns the highlight style that follows h, cycling through all styles.
+func (h HighlightStyle) Next() HighlightStyle {
+	return (h + 1) % (HighlightNone + 1)
+}
+
+// BoardOptions controls how a board is rendered.
+type BoardOptions struct {
+	Flipped         bool // Black at the bottom
+	HideCoordinates bool // omit the rank and file labels
+	Highlight       HighlightStyle
+	Theme           Theme // zero value selects the default theme
+	Pieces          PieceSet
+}
+
+// Width returns the width of a board rendered with these options.
+func (o BoardOptions) Width() int {
+	if o.HideCoordinates {
+		return 8 * boardSquareWidth
+	}
+	return 2 + 8*boardSquareWidth
+}
+
+// Height returns the height of a board rendered with these options.
+func (o BoardOptions) Height() int {
+	if o.HideCoordinates {
+		return 8
+	}
+	return 9
+}
+
+// SquareAt returns the square drawn at (x, y), relative to the top left of a
+// board rendered with these options, or NoSquare if (x, y) is off the board.
+func (o BoardOptions) SquareAt(x, y int) internal.Sq {
+	if !o.HideCoordinates {
+		x -= 2 // rank labels
+	}
+	if x < 0 || y < 0 || x >= 8*boardSquareWidth || y >= 8 {
+		return internal.NoSquare
+	}
+	file, rank := x/boardSquareWidth, 7-y
+	if o.Flipped {
+		file, rank = 7-file, y
+	}
+	return internal.Square(file, rank)
+}
+
+// BoardMarks are the squares marked on a rendered board.
+type BoardMarks struct {
+	Last     internal.Move // last move, or internal.NullMove for none
+	Selected internal.Sq   // selected square, or internal.NoSquare for none
+	Targets  []internal.Sq // legal destinations of the selected piece
+}
+
+// LastMoveMarks returns marks that highlight only the last move.
+func LastMoveMarks(last internal.Move) BoardMarks {
+	return BoardMarks{Last: last, Selected: internal.NoSquare}
+}
+
+// RenderBoard renders a board with the given squares marked.
+func RenderBoard(b *internal.Board, marks BoardMarks, opts BoardOptions) string {
+	labelStyle := lipgloss.NewStyle().Foreground(ColorTextMuted)
+	theme := opts.Theme
+	if theme.Name == "" {
+		theme = DefaultTheme()
+	}
+
+	// ranks top to bottom and files left to right, as seen by the viewer
+	ranks := []int{7, 6, 5, 4, 3, 2, 1, 0}
+	files := []int{0, 1, 2, 3, 4, 5, 6, 7}
+	if opts.Flipped {
+		ranks, files = files, ranks
+	}
+	last := marks.Last
+	targets := make(map[internal.Sq]bool, len(marks.Targets))
+	for _, sq := range marks.Targets {
+		targets[sq] = true
+	}
+
+	var sb strings.Builder
+	for _, rank := range ranks {
+		if !opts.HideCoordinates {
+			sb.WriteString(labelStyle.Render(string(rune('1'+rank)) + " "))
+		}
+		for _, file := range files {
+			sq := internal.Square(file, rank)
+			highlighted := last != internal.NullMove && (sq == last.From || sq == last.To)
+
+			bg := theme.LightSquare
+			if sq.Color() == internal.Black {
+				bg = theme.DarkSquare
+			}
+			if highlighted && opts.Highlight == HighlightSquares {
+				bg = theme.Highlight
+			}
+			if sq == marks.Selected || (targets[sq] && b.Piece[sq] != internal.NoPiece) {
+				bg = theme.Target
+			}
+			style := lipgloss.NewStyle().Background(bg)
+
+			glyph := " "
+			if targets[sq] {
+				// empty destinations get a dot
+				glyph = "•"
+				style = style.Foreground(theme.Target)
+			}
+			if p := b.Piece[sq]; p != internal.NoPiece {
+				fg := theme.WhitePiece
+				if p.Color() == internal.Black {
+					fg = theme.BlackPiece
+				}
+				style = style.Foreground(fg)
+				glyph = opts.Pieces.glyph(p)
+			}
+			cell := " " + glyph + " "
+			if highlighted && opts.Highlight == HighlightMarkers {
+				cell = "[" + glyph + "]"
+			}
+			sb.WriteString(style.Render(cell))
+		}
+		sb.WriteString("\n")
+	}
+
+	if !opts.HideCoordinates {
+		sb.WriteString("  ")
+		for _, file := range files {
+			sb.WriteString(labelStyle.Render(" " + string(rune('a'+file)) + " "))
+		}
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
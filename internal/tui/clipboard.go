@@ -0,0 +1,73 @@
+package tui
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// clipboardCommands are the programs tried, in order, to copy text to the
+// system clipboard.
+var clipboardCommands = [][]string{
+	{"pbcopy"},
+	{"wl-copy"},
+	{"xclip", "-selection", "clipboard"},
+	{"xsel", "--clipboard", "--input"},
+	{"clip.exe"},
+}
+
+// copyToClipboard copies text to the system clipboard. Without a clipboard
+// program it asks the terminal to do so with an OSC 52 escape sequence, which
+// most terminals, including over SSH, support. It returns where the text went.
+func copyToClipboard(text string) (string, error) {
+	for _, args := range clipboardCommands {
+		if _, err := exec.LookPath(args[0]); err != nil {
+			continue
+		}
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		if err := cmd.Run(); err != nil {
+			return "", fmt.Errorf("%s: %w", args[0], err)
+		}
+		return "clipboard", nil
+	}
+	seq := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\a"
+	if _, err := os.Stderr.WriteString(seq); err != nil {
+		return "", err
+	}
+	return "terminal clipboard", nil
+}
+
+// pasteCommands are the programs tried, in order, to read the system
+// clipboard.
+var pasteCommands = [][]string{
+	{"pbpaste"},
+	{"wl-paste", "--no-newline"},
+	{"xclip", "-selection", "clipboard", "-out"},
+	{"xsel", "--clipboard", "--output"},
+	{"powershell.exe", "-NoProfile", "-Command", "Get-Clipboard"},
+}
+
+// pasteFromClipboard returns the text on the system clipboard. Unlike
+// copying, reading needs a clipboard program: terminals do not let programs
+// read their clipboard.
+func pasteFromClipboard() (string, error) {
+	for _, args := range pasteCommands {
+		if _, err := exec.LookPath(args[0]); err != nil {
+			continue
+		}
+		out, err := exec.Command(args[0], args[1:]...).Output()
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", args[0], err)
+		}
+		text := strings.TrimSpace(strings.ReplaceAll(string(out), "\r\n", "\n"))
+		if text == "" {
+			return "", errors.New("the clipboard is empty")
+		}
+		return text, nil
+	}
+	return "", errors.New("no clipboard program found (install xclip, xsel or wl-clipboard)")
+}
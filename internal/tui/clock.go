@@ -0,0 +1,172 @@
+package tui
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/kyleboon/gochess/internal"
+	"github.com/kyleboon/gochess/internal/pgn"
+)
+
+// clockTickInterval is how often live clocks are redrawn.
+const clockTickInterval = 100 * time.Millisecond
+
+// clockTickMsg redraws the live clocks and checks for a flag fall.
+type clockTickMsg time.Time
+
+// tickClock schedules the next clock tick.
+func tickClock() tea.Cmd {
+	return tea.Tick(clockTickInterval, func(t time.Time) tea.Msg {
+		return clockTickMsg(t)
+	})
+}
+
+// ChessClock is a two-sided game clock with a Fischer increment. Neither
+// clock runs until the first move has been made, after which Black's starts.
+type ChessClock struct {
+	Increment time.Duration
+	remaining [2]time.Duration // by color, as of since
+	running   int              // color whose clock runs, or -1 if stopped
+	since     time.Time        // when the running clock was started
+}
+
+// NewChessClock creates a stopped clock with base time on both sides.
+func NewChessClock(base, increment time.Duration) ChessClock {
+	return ChessClock{
+		Increment: increment,
+		remaining: [2]time.Duration{base, base},
+		running:   -1,
+	}
+}
+
+// Remaining returns the time left for color at now, which is never negative.
+func (c ChessClock) Remaining(color int, now time.Time) time.Duration {
+	d := c.remaining[color]
+	if c.running == color {
+		d -= now.Sub(c.since)
+	}
+	return max(0, d)
+}
+
+// Running returns the color whose clock runs, or -1 if the clock is stopped.
+func (c ChessClock) Running() int {
+	return c.running
+}
+
+// Press ends the move of color at now: its clock stops, gains the increment
+// and the opponent's clock starts. It returns the time left for color.
+func (c *ChessClock) Press(color int, now time.Time) time.Duration {
+	if c.running == color {
+		c.remaining[color] = c.Remaining(color, now) + c.Increment
+	}
+	c.running = color ^ 1
+	c.since = now
+	return c.remaining[color]
+}
+
+// Stop stops the clock at now.
+func (c *ChessClock) Stop(now time.Time) {
+	if c.running >= 0 {
+		c.remaining[c.running] = c.Remaining(c.running, now)
+	}
+	c.running = -1
+}
+
+// Flagged reports whether the running clock has run out at now, and whose.
+func (c ChessClock) Flagged(now time.Time) (color int, flagged bool) {
+	if c.running < 0 || c.Remaining(c.running, now) > 0 {
+		return 0, false
+	}
+	return c.running, true
+}
+
+// FormatClock formats a clock time as "1:02:03" or "4:05", with tenths of a
+// second in the last ten seconds ("0:09.4").
+func FormatClock(d time.Duration) string {
+	d = max(0, d)
+	if d < 10*time.Second {
+		tenths := d / (100 * time.Millisecond)
+		return fmt.Sprintf("0:%02d.%d", tenths/10, tenths%10)
+	}
+	secs := int(d / time.Second)
+	if secs >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", secs/3600, secs/60%60, secs%60)
+	}
+	return fmt.Sprintf("%d:%02d", secs/60, secs%60)
+}
+
+// clockComment formats a clock time as a PGN "[%clk H:MM:SS]" command, with
+// tenths of seconds when they are not zero.
+func clockComment(d time.Duration) string {
+	tenths := max(0, d) / (100 * time.Millisecond)
+	secs := int(tenths / 10)
+	clk := fmt.Sprintf("%d:%02d:%02d", secs/3600, secs/60%60, secs%60)
+	if tenths%10 != 0 {
+		clk += fmt.Sprintf(".%d", tenths%10)
+	}
+	return "[%clk " + clk + "]"
+}
+
+// recordedClocks returns the clock times recorded in the %clk comments of
+// the line leading to n, for White and Black, and whether any were found.
+func recordedClocks(n *pgn.Node) (clocks [2]time.Duration, found [2]bool) {
+	for ; n.Parent != nil; n = n.Parent {
+		if n.IsRoot() {
+			continue
+		}
+		color := n.Parent.Board.SideToMove
+		if found[color] {
+			continue
+		}
+		if d, ok := n.Clock(); ok {
+			clocks[color], found[color] = d, true
+		}
+		if found[internal.White] && found[internal.Black] {
+			break
+		}
+	}
+	return clocks, found
+}
+
+// clockLine renders the clocks of both players, the side to move in bold
+// and a fallen flag in red. It is empty when no clock times are known.
+func (m GameViewModel) clockLine() string {
+	var clocks [2]time.Duration
+	var found [2]bool
+	running := -1
+	if m.play != nil {
+		now := m.play.now()
+		for color := range clocks {
+			clocks[color], found[color] = m.play.clock.Remaining(color, now), true
+		}
+		running = m.play.clock.Running()
+	} else {
+		clocks, found = recordedClocks(m.current)
+		if found[internal.White] || found[internal.Black] {
+			running = m.current.Board.SideToMove
+		}
+	}
+	if !found[internal.White] && !found[internal.Black] {
+		return ""
+	}
+
+	label := [2]string{"White", "Black"}
+	var parts []string
+	for color := range clocks {
+		text := label[color] + " --:--"
+		if found[color] {
+			text = label[color] + " " + FormatClock(clocks[color])
+		}
+		style := lipgloss.NewStyle().Foreground(ColorTextMuted)
+		switch {
+		case found[color] && clocks[color] == 0:
+			style = lipgloss.NewStyle().Foreground(ColorError).Bold(true)
+		case color == running:
+			style = lipgloss.NewStyle().Foreground(ColorText).Bold(true)
+		}
+		parts = append(parts, style.Render(text))
+	}
+	return "⏱ " + parts[0] + "  " + parts[1]
+}
@@ -0,0 +1,108 @@
+package tui
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/kyleboon/gochess/internal/analysis"
+	"github.com/kyleboon/gochess/internal/pgn"
+)
+
+// Eval colors
+var (
+	ColorEvalWhite = lipgloss.Color("#F0F0F0")
+	ColorEvalBlack = lipgloss.Color("#303030")
+)
+
+// evalRange is the evaluation, in pawns, at which the eval bar and graph are
+// full. Larger advantages are clamped.
+const evalRange = 5.0
+
+// EvalBarWidth is the width of a rendered eval bar in cells.
+const EvalBarWidth = 2
+
+// blocks are the eighth-height block characters, from empty to full.
+var blocks = []rune(" ▁▂▃▄▅▆▇█")
+
+// evalShare maps an evaluation in pawns to White's share of the bar, from 0
+// to 1.
+func evalShare(pawns float64) float64 {
+	return (math.Max(-evalRange, math.Min(evalRange, pawns)) + evalRange) / (2 * evalRange)
+}
+
+// RenderEvalBar renders a vertical eval bar of the given height, filled with
+// White according to the evaluation (pawns, from White's perspective). White
+// fills from the bottom, or from the top when the board is flipped.
+func RenderEvalBar(pawns float64, height int, flipped bool) string {
+	// Work in eighths of a row so the bar moves smoothly
+	eighths := int(math.Round(evalShare(pawns) * float64(height*8)))
+
+	// A flipped bar fills Black from the bottom instead
+	fg, bg := ColorEvalWhite, ColorEvalBlack
+	if flipped {
+		eighths = height*8 - eighths
+		fg, bg = bg, fg
+	}
+	style := lipgloss.NewStyle().Foreground(fg).Background(bg)
+	lines := make([]string, height)
+	for row := 0; row < height; row++ {
+		// row 0 is the top; fill counts from the bottom
+		fill := eighths - (height-1-row)*8
+		fill = max(0, min(8, fill))
+		lines[row] = style.Render(strings.Repeat(string(blocks[fill]), EvalBarWidth))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// FormatEval formats an evaluation in pawns as "+0.35", "-1.20" or "#" for a
+// forced mate.
+func FormatEval(pawns float64) string {
+	switch {
+	case pawns >= pgn.MateEval:
+		return "+#"
+	case pawns <= -pgn.MateEval:
+		return "-#"
+	}
+	return fmt.Sprintf("%+.2f", pawns)
+}
+
+// RenderEvalGraph renders a one-line sparkline of the evaluations of a game,
+// keyed by ply, from ply 1 to plies, as White's win probability: a momentum
+// graph, where a pawn matters more in a level position than when a side is
+// already winning. Each column covers one ply, or several
+// plies when the game is longer than width. The column containing the
+// current ply is highlighted. Plies without an evaluation repeat the previous
+// one.
+func RenderEvalGraph(evals map[int]float64, plies, current, width int) string {
+	if plies <= 0 || width <= 0 {
+		return ""
+	}
+	perColumn := (plies + width - 1) / width
+	columns := (plies + perColumn - 1) / perColumn
+
+	style := lipgloss.NewStyle().Foreground(ColorAccent)
+	currentStyle := lipgloss.NewStyle().Foreground(ColorBg).Background(ColorSecondary)
+
+	var sb strings.Builder
+	last := 0.0
+	for col := 0; col < columns; col++ {
+		// Each column shows the last evaluation of its plies
+		first := col*perColumn + 1
+		for ply := first; ply < first+perColumn && ply <= plies; ply++ {
+			if v, ok := evals[ply]; ok {
+				last = v
+			}
+		}
+		win := analysis.WinProbability(int(math.Round(last*100)), 0)
+		level := 1 + int(math.Round(win*float64(len(blocks)-2)))
+		ch := string(blocks[level])
+		if current >= first && current < first+perColumn {
+			sb.WriteString(currentStyle.Render(ch))
+		} else {
+			sb.WriteString(style.Render(ch))
+		}
+	}
+	return sb.String()
+}
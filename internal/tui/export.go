@@ -0,0 +1,215 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/kyleboon/gochess/internal/pgn"
+)
+
+// AddGameFunc inserts a game, given as PGN text, into the database.
+type AddGameFunc func(pgnText string) error
+
+// exportTarget is where the export form writes the game.
+type exportTarget int
+
+const (
+	exportFile     exportTarget = iota // append to a PGN file
+	exportDatabase                     // insert into the database
+)
+
+// exportTags are the tags filled in on the export form.
+var exportTags = []string{"Event", "White", "Black"}
+
+// Keys of the export form, which are not remappable.
+var (
+	exportNextKey = key.NewBinding(key.WithKeys("tab", "down"), key.WithHelp("tab", "next field"))
+	exportPrevKey = key.NewBinding(key.WithKeys("shift+tab", "up"), key.WithHelp("shift+tab", "previous field"))
+	exportSaveKey = key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "save"))
+	exportQuitKey = key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "cancel"))
+)
+
+// exportForm asks for the tags of a game, and for a file name when exporting
+// to a file, before the game is written.
+type exportForm struct {
+	target exportTarget
+	labels []string
+	inputs []textinput.Model
+	focus  int
+}
+
+// newExportForm creates an export form filled in from the tags of game.
+func newExportForm(target exportTarget, game *pgn.Game, width int) exportForm {
+	f := exportForm{target: target}
+	add := func(label, value string) {
+		input := textinput.New()
+		input.Prompt = ""
+		input.SetValue(value)
+		input.Width = max(width, MinWidth) - 16
+		f.labels = append(f.labels, label)
+		f.inputs = append(f.inputs, input)
+	}
+	for _, tag := range exportTags {
+		value := game.Tags[tag]
+		if value == "?" {
+			value = ""
+		}
+		add(tag, value)
+	}
+	if target == exportFile {
+		add("File", defaultExportPath(game.Tags))
+	}
+	return f
+}
+
+// defaultExportPath suggests a file name for a game, as in
+// "2024.01.31-Carlsen-Nakamura.pgn".
+func defaultExportPath(tags map[string]string) string {
+	var parts []string
+	for _, tag := range []string{"Date", "White", "Black"} {
+		if v := tags[tag]; v != "" && !strings.Contains(v, "?") {
+			parts = append(parts, v)
+		}
+	}
+	if len(parts) == 0 {
+		return "game.pgn"
+	}
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		}
+		return '_'
+	}, strings.Join(parts, "-"))
+	return name + ".pgn"
+}
+
+// value returns the text entered for label.
+func (f exportForm) value(label string) string {
+	for i, l := range f.labels {
+		if l == label {
+			return strings.TrimSpace(f.inputs[i].Value())
+		}
+	}
+	return ""
+}
+
+// focusField moves the cursor to field i.
+func (f *exportForm) focusField(i int) tea.Cmd {
+	f.inputs[f.focus].Blur()
+	f.focus = (i + len(f.inputs)) % len(f.inputs)
+	return f.inputs[f.focus].Focus()
+}
+
+// SetGameAdder sets the function that inserts games into the database.
+// Without one, games can only be exported to a file.
+func (m *GameViewModel) SetGameAdder(add AddGameFunc) {
+	m.addGame = add
+}
+
+// openExport opens the export form for target.
+func (m *GameViewModel) openExport(target exportTarget) tea.Cmd {
+	if target == exportDatabase && m.addGame == nil {
+		return m.notes.notify(NotifyError, "No database to add the game to")
+	}
+	form := newExportForm(target, m.game, m.width)
+	cmd := form.inputs[0].Focus()
+	m.export = &form
+	return cmd
+}
+
+// updateExport forwards a message to the export form, writing the game on
+// enter and discarding the form on escape.
+func (m *GameViewModel) updateExport(msg tea.Msg) tea.Cmd {
+	form := m.export
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case key.Matches(msg, exportQuitKey):
+			m.export = nil
+			return nil
+		case key.Matches(msg, exportSaveKey):
+			m.export = nil
+			return m.exportGame(*form)
+		case key.Matches(msg, exportNextKey):
+			return form.focusField(form.focus + 1)
+		case key.Matches(msg, exportPrevKey):
+			return form.focusField(form.focus - 1)
+		}
+	}
+	var cmd tea.Cmd
+	form.inputs[form.focus], cmd = form.inputs[form.focus].Update(msg)
+	return cmd
+}
+
+// exportGame stores the tags entered on form and returns a command that
+// writes the game, with any moves played and annotations made, to the form's
+// target in the background.
+func (m *GameViewModel) exportGame(form exportForm) tea.Cmd {
+	for _, tag := range exportTags {
+		value := form.value(tag)
+		if value == "" {
+			value = "?"
+		}
+		m.game.Tags[tag] = value
+	}
+	m.info.Event = m.game.Tags["Event"]
+	m.info.White = m.game.Tags["White"]
+	m.info.Black = m.game.Tags["Black"]
+	text := m.game.String()
+
+	if form.target == exportDatabase {
+		add := m.addGame
+		return notifyResult(func() error { return add(text) }, "Game added to the database", "Failed to add game")
+	}
+	path := form.value("File")
+	return notifyResult(func() error { return appendPGN(path, text) }, "Game written to "+path, "Failed to export game")
+}
+
+// appendPGN appends a game to the PGN file at path, creating it if needed.
+func appendPGN(path, text string) error {
+	if path == "" {
+		return fmt.Errorf("no file name given")
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	// Games in a PGN file are separated by a blank line
+	if info, err := f.Stat(); err == nil && info.Size() > 0 {
+		text = "\n" + text
+	}
+	if _, err := f.WriteString(text); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// renderExport renders the export form.
+func (m GameViewModel) renderExport() string {
+	form := m.export
+	title := "💾 Export to PGN file"
+	if form.target == exportDatabase {
+		title = "💾 Add to database"
+	}
+
+	var b strings.Builder
+	b.WriteString(TitleStyle.Render(title))
+	b.WriteString("\n")
+	for i, input := range form.inputs {
+		line := StatLabelStyle.Render(form.labels[i]) + input.View()
+		if i == form.focus {
+			line = lipgloss.NewStyle().Foreground(ColorPrimary).Bold(true).Render("> ") + line
+		} else {
+			line = "  " + line
+		}
+		b.WriteString(line + "\n")
+	}
+	b.WriteString(HelpStyle.Render(helpLine(exportNextKey, exportPrevKey, exportSaveKey, exportQuitKey)))
+	return b.String()
+}
@@ -5,8 +5,12 @@ import (
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/kyleboon/gochess/internal"
+	"github.com/kyleboon/gochess/internal/eco"
+	"github.com/kyleboon/gochess/internal/pgn"
 )
 
 // Game represents a chess game for display purposes
@@ -59,6 +63,17 @@ type GameListModel struct {
 	list     list.Model
 	games    []Game
 	selected *Game
+	viewer   *GameViewModel // board view of the selected game, if its moves parse
+	evals    EvalLoader
+	notes    NoteLoader
+	pgn      PGNLoader
+	players  []string // the user's own accounts, used to orient the board
+	board    BoardOptions
+	save     SaveSettingsFunc
+	saveGame SaveGameFunc
+	addGame  AddGameFunc
+	openings *eco.Database
+	keys     KeyMap
 	quitting bool
 	width    int
 	height   int
@@ -90,13 +105,96 @@ func NewGameListModel(games []Game) GameListModel {
 	l.Styles.Title = TitleStyle
 	l.Styles.PaginationStyle = lipgloss.NewStyle().Foreground(ColorTextMuted)
 	l.Styles.HelpStyle = HelpStyle
+	l.StatusMessageLifetime = notificationTimeout
 
-	return GameListModel{
+	m := GameListModel{
 		list:   l,
 		games:  games,
 		width:  defaultWidth,
 		height: defaultHeight,
 	}
+	return m.WithKeys(DefaultKeyMap())
+}
+
+// EvalLoader returns the stored evaluations of a game, in pawns from White's
+// perspective, keyed by ply.
+type EvalLoader func(gameID int) (map[int]float64, error)
+
+// WithEvaluations sets the loader used to show the evaluations of analyzed
+// games when they are opened.
+func (m GameListModel) WithEvaluations(loader EvalLoader) GameListModel {
+	m.evals = loader
+	return m
+}
+
+// NoteLoader returns the user's own note on a game, or "" if it has none.
+type NoteLoader func(gameID int) (string, error)
+
+// WithNotes sets the loader used to show the user's notes on games when they
+// are opened.
+func (m GameListModel) WithNotes(loader NoteLoader) GameListModel {
+	m.notes = loader
+	return m
+}
+
+// PGNLoader returns the PGN of a game.
+type PGNLoader func(gameID int) (string, error)
+
+// WithPGN sets the loader used to read the moves of games listed without
+// their PGN when they are opened.
+func (m GameListModel) WithPGN(loader PGNLoader) GameListModel {
+	m.pgn = loader
+	return m
+}
+
+// WithPlayers sets the user's own player names. Games in which one of them
+// played Black open with the board flipped.
+func (m GameListModel) WithPlayers(players []string) GameListModel {
+	m.players = players
+	return m
+}
+
+// WithBoardOptions sets how boards are drawn, and the function that persists
+// changes made on the settings screen (nil to keep them for the session only).
+func (m GameListModel) WithBoardOptions(opts BoardOptions, save SaveSettingsFunc) GameListModel {
+	m.board = opts
+	m.save = save
+	return m
+}
+
+// WithGameSaver sets the function that persists comments, NAGs and variations
+// edited in the game view.
+func (m GameListModel) WithGameSaver(save SaveGameFunc) GameListModel {
+	m.saveGame = save
+	return m
+}
+
+// WithGameAdder sets the function that inserts games exported from the game
+// view into the database.
+func (m GameListModel) WithGameAdder(add AddGameFunc) GameListModel {
+	m.addGame = add
+	return m
+}
+
+// WithOpenings sets the ECO database used to name the opening of the
+// position shown in the game view.
+func (m GameListModel) WithOpenings(openings *eco.Database) GameListModel {
+	m.openings = openings
+	return m
+}
+
+// WithKeys sets the key bindings of the list and of the game view.
+func (m GameListModel) WithKeys(keys KeyMap) GameListModel {
+	m.keys = keys
+	// The list shows our keys in its own help, toggled with ?
+	m.list.KeyMap.Quit = keys.List.Quit
+	m.list.AdditionalShortHelpKeys = func() []key.Binding {
+		return []key.Binding{keys.List.Open, keys.List.Paste}
+	}
+	m.list.AdditionalFullHelpKeys = func() []key.Binding {
+		return []key.Binding{keys.List.Open, keys.List.Paste}
+	}
+	return m
 }
 
 // Init initializes the model
@@ -115,19 +213,57 @@ func (m GameListModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "ctrl+c", "q":
+		switch {
+		case msg.String() == "ctrl+c":
 			m.quitting = true
 			return m, tea.Quit
 
-		case "enter":
-			// Select the current game
-			i, ok := m.list.SelectedItem().(gameItem)
-			if ok {
-				m.selected = &i.game
-			}
+		case m.viewer != nil && m.viewer.capturesKeys():
+			// The settings screen, comment editor and help overlay handle
+			// their own keys
+
+		case m.selected != nil && key.Matches(msg, m.keys.Game.Back):
+			// Go back from the game view to the list
+			m.selected = nil
+			m.viewer = nil
 			return m, nil
+
+		case m.selected == nil && key.Matches(msg, m.keys.List.Quit) &&
+			m.list.FilterState() != list.Filtering && !key.Matches(msg, m.list.KeyMap.ClearFilter):
+			m.quitting = true
+			return m, tea.Quit
+
+		case m.selected == nil && key.Matches(msg, m.keys.List.Open):
+			// Select the current game
+			return m, m.openSelected()
+
+		case m.selected == nil && key.Matches(msg, m.keys.List.Paste) &&
+			m.list.FilterState() != list.Filtering:
+			return m, m.paste()
+		}
+	}
+
+	// Notifications that arrive once the game view is closed, such as the
+	// result of saving the game, go to the list's status bar
+	if msg, ok := msg.(NotifyMsg); ok && m.viewer == nil {
+		return m, m.list.NewStatusMessage(renderNotification(msg))
+	}
+	if m.viewer != nil {
+		model, cmd := m.viewer.Update(msg)
+		viewer := model.(GameViewModel)
+		m.viewer = &viewer
+		// Keep settings changes for the next game, but not the orientation
+		flipped := m.board.Flipped
+		m.board = viewer.BoardOptions()
+		m.board.Flipped = flipped
+		// Reopening an annotated game shows its edits
+		if viewer.info.PGNText != m.selected.PGNText {
+			m.updateGame(viewer.info)
 		}
+		return m, cmd
+	}
+	if m.selected != nil {
+		return m, nil
 	}
 
 	var cmd tea.Cmd
@@ -135,13 +271,120 @@ func (m GameListModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+// openSelected opens the game selected in the list in the game view.
+func (m *GameListModel) openSelected() tea.Cmd {
+	var cmd tea.Cmd
+	i, ok := m.list.SelectedItem().(gameItem)
+	if !ok {
+		return nil
+	}
+	m.selected = &i.game
+	if i.game.PGNText == "" && m.pgn != nil {
+		// Games listed without their moves keep them once loaded
+		pgnText, err := m.pgn(i.game.ID)
+		if err != nil {
+			cmd = m.list.NewStatusMessage(renderNotification(NotifyMsg{Level: NotifyError, Text: "Failed to load the game: " + err.Error()}))
+		}
+		i.game.PGNText = pgnText
+		m.updateGame(i.game)
+	}
+	m.viewer = newViewer(i.game, m.width, m.height)
+	if m.viewer != nil && m.evals != nil {
+		evals, err := m.evals(i.game.ID)
+		if err != nil {
+			cmd = m.viewer.notes.notify(NotifyError, "Failed to load evaluations: "+err.Error())
+		}
+		m.viewer.SetEvaluations(evals)
+	}
+	if m.viewer != nil && m.notes != nil {
+		note, err := m.notes(i.game.ID)
+		if err != nil {
+			cmd = m.viewer.notes.notify(NotifyError, "Failed to load note: "+err.Error())
+		}
+		m.viewer.SetNote(note)
+	}
+	if m.viewer != nil {
+		m.viewer.SetBoardOptions(m.board)
+		m.viewer.SetSettingsSaver(m.save)
+		m.viewer.SetGameSaver(m.saveGame)
+		m.viewer.SetGameAdder(m.addGame)
+		m.viewer.SetOpenings(m.openings)
+		m.viewer.SetKeys(m.keys)
+		for _, p := range m.players {
+			if strings.EqualFold(p, i.game.Black) {
+				m.viewer.board.Flipped = true
+			}
+		}
+	}
+	return cmd
+}
+
+// updateGame replaces the listed copy of game.
+func (m *GameListModel) updateGame(game Game) {
+	*m.selected = game
+	for i, g := range m.games {
+		if g.ID == game.ID {
+			m.games[i] = game
+		}
+	}
+	for i, item := range m.list.Items() {
+		if it, ok := item.(gameItem); ok && it.game.ID == game.ID {
+			m.list.SetItem(i, gameItem{game: game})
+		}
+	}
+}
+
+// paste imports the PGN on the clipboard into the database, or opens the
+// position of a FEN on the clipboard in the game view, where it can be
+// explored and added to the database.
+func (m *GameListModel) paste() tea.Cmd {
+	text, err := pasteFromClipboard()
+	if err != nil {
+		return m.list.NewStatusMessage(renderNotification(NotifyMsg{Level: NotifyError, Text: "Failed to paste: " + err.Error()}))
+	}
+
+	if _, err := internal.ParseFen(text); err == nil {
+		// The PGN parser ignores FEN tags, so the game is set up directly
+		tags := map[string]string{"Event": "Position from the clipboard", "Result": "*", "SetUp": "1", "FEN": text}
+		position, err := pgn.NewGame(tags)
+		if err != nil {
+			return m.list.NewStatusMessage(renderNotification(NotifyMsg{Level: NotifyError, Text: "Failed to set up the pasted position: " + err.Error()}))
+		}
+		game := Game{Event: tags["Event"], Result: "*", PGNText: position.String()}
+		viewer := NewGameViewModel(game, position)
+		viewer.width, viewer.height = m.width, m.height
+		m.selected, m.viewer = &game, &viewer
+		m.viewer.SetBoardOptions(m.board)
+		m.viewer.SetSettingsSaver(m.save)
+		m.viewer.SetGameAdder(m.addGame)
+		m.viewer.SetOpenings(m.openings)
+		m.viewer.SetKeys(m.keys)
+		return m.viewer.notes.notify(NotifySuccess, "Position pasted from the clipboard")
+	}
+
+	if m.addGame == nil {
+		return m.list.NewStatusMessage(renderNotification(NotifyMsg{Level: NotifyError, Text: "Games cannot be imported here"}))
+	}
+	pgnDB := &pgn.DB{}
+	if errs := pgnDB.Parse(text); len(errs) > 0 || len(pgnDB.Games) == 0 {
+		return m.list.NewStatusMessage(renderNotification(NotifyMsg{Level: NotifyError, Text: "The clipboard holds no PGN or FEN"}))
+	}
+	add := m.addGame
+	done := fmt.Sprintf("Imported %d game(s) from the clipboard; reopen the list to see them", len(pgnDB.Games))
+	return notifyResult(func() error { return add(text) }, done, "Failed to import from the clipboard")
+}
+
 // View renders the model
 func (m GameListModel) View() string {
 	if m.quitting {
 		return "Thanks for using GoChess!\n"
 	}
 
-	// If a game is selected, show its details
+	// If a game is selected, show it on the board, or just its details if
+	// its moves could not be parsed
+	if m.viewer != nil {
+		return m.viewer.View()
+	}
 	if m.selected != nil {
 		return m.renderGameDetails()
 	}
@@ -178,12 +421,12 @@ func (m GameListModel) renderGameDetails() string {
 
 	// Result with color
 	resultStyle := lipgloss.NewStyle()
-	switch game.Result {
-	case "1-0":
+	switch pgn.ParseResult(game.Result) {
+	case pgn.WhiteWin:
 		resultStyle = WinStyle
-	case "0-1":
+	case pgn.BlackWin:
 		resultStyle = LossStyle
-	case "1/2-1/2":
+	case pgn.Draw:
 		resultStyle = DrawStyle
 	}
 	fmt.Fprintf(&b, "  Result: %s\n", resultStyle.Render(game.Result))
@@ -228,11 +471,56 @@ func (m GameListModel) renderGameDetails() string {
 
 	// Help
 	b.WriteString("\n")
-	b.WriteString(HelpStyle.Render("Press 'q' to go back, 'ctrl+c' to quit"))
+	b.WriteString(HelpStyle.Render(fmt.Sprintf("Press '%s' to go back, 'ctrl+c' to quit", m.keys.Game.Back.Help().Key)))
 
 	return BorderStyle.Render(b.String())
 }
 
+// newViewer creates a game view for game, or returns nil if its PGN cannot
+// be parsed.
+func newViewer(game Game, width, height int) *GameViewModel {
+	if game.PGNText == "" {
+		return nil
+	}
+	db := &pgn.DB{}
+	if errs := db.Parse(game.PGNText); len(errs) > 0 || len(db.Games) == 0 {
+		return nil
+	}
+	if err := db.ParseMoves(db.Games[0]); err != nil {
+		return nil
+	}
+	viewer := NewGameViewModel(withTags(game, db.Games[0].Tags), db.Games[0])
+	viewer.width = width
+	viewer.height = height
+	return &viewer
+}
+
+// withTags fills the details of game that were not stored with it from the
+// tags of its own PGN, so the board view shows the game as it was recorded.
+func withTags(game Game, tags map[string]string) Game {
+	fields := []struct {
+		value *string
+		tag   string
+	}{
+		{&game.Event, "Event"},
+		{&game.Site, "Site"},
+		{&game.Date, "Date"},
+		{&game.White, "White"},
+		{&game.Black, "Black"},
+		{&game.Result, "Result"},
+		{&game.TimeControl, "TimeControl"},
+		{&game.ECOCode, "ECO"},
+		{&game.OpeningName, "Opening"},
+		{&game.OpeningVariation, "Variation"},
+	}
+	for _, f := range fields {
+		if *f.value == "" {
+			*f.value = tags[f.tag]
+		}
+	}
+	return game
+}
+
 // GetSelectedGame returns the currently selected game
 func (m GameListModel) GetSelectedGame() *Game {
 	return m.selected
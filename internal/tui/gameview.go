@@ -0,0 +1,818 @@
+package tui
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/kyleboon/gochess/internal"
+	"github.com/kyleboon/gochess/internal/eco"
+	"github.com/kyleboon/gochess/internal/pgn"
+)
+
+// Layout of the game view, used to map mouse clicks onto the move list.
+const (
+	gameViewHeaderHeight = 3                                 // title, game info, blank line, without a note
+	moveListGap          = 4                                 // space between the board and the move list
+	moveNumberWidth      = 5                                 // "123. "
+	moveCellWidth        = 9                                 // SAN padded to 8, plus a space
+	moveListMinRows      = 8                                 // rows shown on small terminals
+	gameViewFooterHeight = 4                                 // blank, status, comment, help
+	evalGraphHeight      = 2                                 // blank, graph
+	moveListWidth        = moveNumberWidth + 2*moveCellWidth // width of a move row
+	maxNoteLines         = 3                                 // lines of the game's note shown in the header
+)
+
+// GameViewModel shows a game on a board, with its move list and variations.
+// The position follows the current move, which is moved with the arrow keys
+// or by clicking a move in the list. Comments and NAGs can be edited and are
+// saved back as annotated PGN.
+type GameViewModel struct {
+	info     Game
+	game     *pgn.Game
+	mainline []*pgn.Node     // nodes of the main line; mainline[0] is the root
+	current  *pgn.Node       // node whose position is shown
+	scroll   int             // first move-list row shown
+	evals    map[int]float64 // evaluations in pawns from White's perspective, by ply
+	note     string          // the user's own note on the game, kept apart from its PGN
+	board    BoardOptions
+	settings *SettingsModel   // open settings screen, if any
+	save     SaveSettingsFunc // persists accepted settings; may be nil
+	notes    notifier         // transient status bar notifications
+	selected internal.Sq      // square of the piece selected with the mouse, or NoSquare
+	choice   int              // continuation followed by Next, 0 being the current line
+	editor   *textinput.Model // open comment editor, if any
+	saveGame SaveGameFunc     // persists annotations; may be nil
+	play     *playState       // clock and state of a game being played, if any
+	export   *exportForm      // open export form, if any
+	addGame  AddGameFunc      // inserts games into the database; may be nil
+	openings *eco.Database    // names the opening in the status line; may be nil
+	keys     KeyMap
+	showHelp bool // the key help overlay is open
+	width    int
+	height   int
+}
+
+// NewGameViewModel creates a game view. The moves of game must already have
+// been parsed.
+func NewGameViewModel(info Game, game *pgn.Game) GameViewModel {
+	m := GameViewModel{
+		info:     info,
+		game:     game,
+		current:  game.Root,
+		selected: internal.NoSquare,
+		keys:     DefaultKeyMap(),
+		width:    MinWidth,
+		height:   MinHeight,
+	}
+	for n := game.Root; n != nil; n = n.Next {
+		m.mainline = append(m.mainline, n)
+	}
+
+	// Lichess exports carry evaluations in the move comments
+	m.evals = make(map[int]float64)
+	for ply, n := range m.mainline {
+		if v, ok := n.Eval(); ok {
+			m.evals[ply] = v
+		}
+	}
+	return m
+}
+
+// SetEvaluations adds evaluations, in pawns from White's perspective, keyed
+// by ply (0 being the starting position). They take precedence over any
+// evaluations found in the game's comments.
+func (m *GameViewModel) SetEvaluations(evals map[int]float64) {
+	for ply, v := range evals {
+		m.evals[ply] = v
+	}
+}
+
+// SetNote sets the user's own note on the game, shown under the header.
+func (m *GameViewModel) SetNote(note string) {
+	m.note = strings.TrimSpace(note)
+}
+
+// hasEvals reports whether the game has been analyzed, which enables the
+// eval bar and graph.
+func (m GameViewModel) hasEvals() bool {
+	return len(m.evals) > 0
+}
+
+// currentEval returns the evaluation of the shown position, falling back to
+// the last evaluated position before it.
+func (m GameViewModel) currentEval() (float64, bool) {
+	// Evaluations are only known for the main line
+	if m.inVariation() {
+		return 0, false
+	}
+	for ply := m.plyOf(m.current); ply >= 0; ply-- {
+		if v, ok := m.evals[ply]; ok {
+			return v, true
+		}
+	}
+	return 0, false
+}
+
+// SetBoardOptions sets how the board is drawn.
+func (m *GameViewModel) SetBoardOptions(opts BoardOptions) {
+	m.board = opts
+}
+
+// BoardOptions returns how the board is drawn.
+func (m GameViewModel) BoardOptions() BoardOptions {
+	return m.board
+}
+
+// SetSettingsSaver sets the function that persists the settings chosen on
+// the settings screen.
+func (m *GameViewModel) SetSettingsSaver(save SaveSettingsFunc) {
+	m.save = save
+}
+
+// SetKeys sets the key bindings of the game view and its settings screen.
+func (m *GameViewModel) SetKeys(keys KeyMap) {
+	m.keys = keys
+}
+
+// boardX returns the left edge of the board.
+func (m GameViewModel) boardX() int {
+	if m.hasEvals() {
+		return EvalBarWidth + 1
+	}
+	return 0
+}
+
+// moveListX returns the left edge of the move list.
+func (m GameViewModel) moveListX() int {
+	return m.boardX() + m.board.Width() + moveListGap
+}
+
+// Init initializes the model
+func (m GameViewModel) Init() tea.Cmd {
+	if m.playing() {
+		return tickClock()
+	}
+	return nil
+}
+
+// Update handles messages
+func (m GameViewModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if cmd, ok := m.notes.update(msg); ok {
+		return m, cmd
+	}
+	if m.editor != nil {
+		switch msg.(type) {
+		case tea.WindowSizeMsg, clockTickMsg:
+		default:
+			cmd := m.updateEditor(msg)
+			return m, cmd
+		}
+	}
+	if m.export != nil {
+		switch msg.(type) {
+		case tea.WindowSizeMsg, clockTickMsg:
+		default:
+			cmd := m.updateExport(msg)
+			return m, cmd
+		}
+	}
+	if m.showHelp {
+		// Any key closes the help overlay
+		if _, ok := msg.(tea.KeyMsg); ok {
+			m.showHelp = false
+			return m, nil
+		}
+	}
+	if m.settings != nil {
+		if _, ok := msg.(tea.KeyMsg); ok {
+			return m, m.updateSettings(msg)
+		}
+	}
+	current := m.current
+
+	var cmd tea.Cmd
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+
+	case clockTickMsg:
+		if m.playing() {
+			cmd = m.checkFlag()
+		}
+		if m.playing() {
+			cmd = tickClock()
+		}
+
+	case tea.KeyMsg:
+		m.selected = internal.NoSquare
+		keys := m.keys.Game
+		switch {
+		case key.Matches(msg, keys.Prev):
+			m.Prev()
+		case key.Matches(msg, keys.Next):
+			m.Next()
+		case key.Matches(msg, keys.First):
+			m.First()
+		case key.Matches(msg, keys.Last):
+			m.Last()
+		case key.Matches(msg, keys.NextVariation):
+			if n := len(m.continuations()); n > 0 {
+				m.choice = (m.choice + 1) % n
+			}
+		case key.Matches(msg, keys.PrevVariation):
+			if n := len(m.continuations()); n > 0 {
+				m.choice = (m.choice + n - 1) % n
+			}
+		case key.Matches(msg, keys.Comment):
+			cmd = m.editComment()
+		case key.Matches(msg, keys.MoveNag, keys.PositionNag):
+			if m.current.Parent != nil {
+				nags := moveNags
+				if key.Matches(msg, keys.PositionNag) {
+					nags = positionNags
+				}
+				cycleNag(m.current, nags)
+				cmd = m.saveAnnotations()
+			}
+		case key.Matches(msg, keys.Flip):
+			m.board.Flipped = !m.board.Flipped
+		case key.Matches(msg, keys.Coordinates):
+			m.board.HideCoordinates = !m.board.HideCoordinates
+		case key.Matches(msg, keys.Highlight):
+			m.board.Highlight = m.board.Highlight.Next()
+		case key.Matches(msg, keys.Settings):
+			settings := NewSettingsModel(m.board, m.keys.Settings)
+			m.settings = &settings
+		case key.Matches(msg, keys.Export):
+			cmd = m.openExport(exportFile)
+		case key.Matches(msg, keys.AddToDatabase):
+			cmd = m.openExport(exportDatabase)
+		case key.Matches(msg, keys.CopyFEN):
+			cmd = m.copy("FEN", m.current.Board.Fen())
+		case key.Matches(msg, keys.CopyPGN):
+			cmd = m.copy("PGN", m.game.String())
+		case key.Matches(msg, keys.Help):
+			m.showHelp = true
+		}
+
+	case tea.MouseMsg:
+		if msg.Action == tea.MouseActionPress && msg.Button == tea.MouseButtonLeft {
+			if n := m.nodeAt(msg.X, msg.Y); n != nil {
+				m.current = n
+				m.selected = internal.NoSquare
+			} else if sq := m.squareAt(msg.X, msg.Y); sq != internal.NoSquare {
+				cmd = m.clickSquare(sq)
+			}
+		}
+		if msg.Button == tea.MouseButtonWheelUp {
+			m.Prev()
+		}
+		if msg.Button == tea.MouseButtonWheelDown {
+			m.Next()
+		}
+	}
+
+	// A new position starts again from its own line
+	if m.current != current {
+		m.choice = 0
+	}
+	m.scrollToCurrent()
+	return m, cmd
+}
+
+// updateSettings forwards a message to the settings screen, applying and
+// saving its settings once accepted.
+func (m *GameViewModel) updateSettings(msg tea.Msg) tea.Cmd {
+	model, _ := m.settings.Update(msg)
+	settings := model.(SettingsModel)
+	m.settings = &settings
+
+	done, accepted := settings.Done()
+	if !done {
+		return nil
+	}
+	m.settings = nil
+	if !accepted {
+		return nil
+	}
+	m.board = settings.Options()
+	if m.save == nil {
+		return nil
+	}
+	if err := m.save(m.board); err != nil {
+		return m.notes.notify(NotifyError, "Failed to save settings: "+err.Error())
+	}
+	return m.notes.notify(NotifySuccess, "Settings saved")
+}
+
+// Prev steps back one move.
+func (m *GameViewModel) Prev() {
+	if m.current.Parent != nil {
+		m.current = m.current.Parent
+	}
+	// Skip the empty root node that starts a variation
+	if m.current.IsRoot() && m.current.Parent != nil {
+		m.current = m.current.Parent
+	}
+}
+
+// Next steps forward one move, into the continuation chosen with tab.
+func (m *GameViewModel) Next() {
+	if conts := m.continuations(); m.choice < len(conts) {
+		m.current = conts[m.choice]
+	}
+}
+
+// First goes to the starting position.
+func (m *GameViewModel) First() {
+	m.current = m.game.Root
+}
+
+// Last goes to the final position of the main line.
+func (m *GameViewModel) Last() {
+	m.current = m.mainline[len(m.mainline)-1]
+}
+
+// squareAt returns the board square shown at screen position (x, y), or
+// NoSquare if (x, y) is not on the board.
+func (m GameViewModel) squareAt(x, y int) internal.Sq {
+	return m.board.SquareAt(x-m.boardX(), y-m.headerHeight())
+}
+
+// targets returns the legal moves of the selected piece.
+func (m GameViewModel) targets() []internal.Move {
+	if m.selected == internal.NoSquare {
+		return nil
+	}
+	var moves []internal.Move
+	for _, mv := range m.current.Board.LegalMoves() {
+		if mv.From == m.selected {
+			moves = append(moves, mv)
+		}
+	}
+	return moves
+}
+
+// clickSquare selects the piece on sq, or plays the selected piece to sq if
+// that is a legal move.
+func (m *GameViewModel) clickSquare(sq internal.Sq) tea.Cmd {
+	if m.play != nil {
+		if m.play.over {
+			return m.notes.notify(NotifyInfo, "The game is over")
+		}
+		// Play continues from the last move
+		if m.current != m.tip() {
+			m.current = m.tip()
+			m.selected = internal.NoSquare
+		}
+	}
+	board := m.current.Board
+	for _, mv := range m.targets() {
+		// Promotions always queen; castling also accepts the rook's square
+		if (mv.To == sq || moveTarget(board, mv) == sq) &&
+			(mv.Promotion == internal.NoPiece || mv.Promotion.Type() == internal.Queen) {
+			m.playMove(mv)
+			m.selected = internal.NoSquare
+			if m.play != nil {
+				return m.pressClock()
+			}
+			return nil
+		}
+	}
+	if p := board.Piece[sq]; p != internal.NoPiece && p.Color() == board.SideToMove && sq != m.selected {
+		m.selected = sq
+		return nil
+	}
+	m.selected = internal.NoSquare
+	return nil
+}
+
+// moveTarget returns the square a move is shown to go to. Castling moves,
+// which are encoded as the king capturing its own rook, go to the king's
+// destination square.
+func moveTarget(board *internal.Board, mv internal.Move) internal.Sq {
+	p, q := board.Piece[mv.From], board.Piece[mv.To]
+	if p.Type() == internal.King && q != internal.NoPiece && q.Color() == p.Color() {
+		file := internal.FileG
+		if mv.To.File() < mv.From.File() {
+			file = internal.FileC
+		}
+		return internal.Square(file, mv.From.Rank())
+	}
+	return mv.To
+}
+
+// playMove makes move mv from the current position. A move already in the game
+// tree is followed; otherwise the game is extended, or a new variation is
+// started if the current position already has a continuation.
+func (m *GameViewModel) playMove(mv internal.Move) {
+	if next := m.current.Next; next != nil {
+		if next.Move == mv {
+			m.current = next
+			return
+		}
+		for _, v := range next.Variations() {
+			if v.Next.Move == mv {
+				m.current = v.Next
+				return
+			}
+		}
+		m.current = next.NewVariation().Insert(mv)
+		return
+	}
+	m.current = m.current.Insert(mv)
+
+	// Moves added at the end of the main line extend it
+	m.mainline = m.mainline[:0]
+	for n := m.game.Root; n != nil; n = n.Next {
+		m.mainline = append(m.mainline, n)
+	}
+}
+
+// Current returns the node whose position is shown.
+func (m GameViewModel) Current() *pgn.Node {
+	return m.current
+}
+
+// firstBlack reports whether the game starts with a Black move, which leaves
+// the White cell of the first move row empty.
+func (m GameViewModel) firstBlack() bool {
+	return m.game.Root.Board.SideToMove == internal.Black
+}
+
+// rowOf returns the move-list row of the ply-th move of the main line (1-based).
+func (m GameViewModel) rowOf(ply int) int {
+	if m.firstBlack() {
+		ply++
+	}
+	return (ply - 1) / 2
+}
+
+// rowCount returns the number of rows in the move list.
+func (m GameViewModel) rowCount() int {
+	plies := len(m.mainline) - 1
+	if plies == 0 {
+		return 0
+	}
+	return m.rowOf(plies) + 1
+}
+
+// visibleRows returns how many move-list rows fit on the screen.
+func (m GameViewModel) visibleRows() int {
+	rows := m.height - m.moveListY() - gameViewFooterHeight - m.variationsHeight()
+	if m.hasEvals() {
+		rows -= evalGraphHeight
+	}
+	return max(moveListMinRows, rows)
+}
+
+// scrollToCurrent adjusts the move-list scroll so the current move is visible.
+func (m *GameViewModel) scrollToCurrent() {
+	ply := m.branchPly()
+	if ply == 0 {
+		m.scroll = 0
+		return
+	}
+	row := m.rowOf(ply)
+	rows := m.visibleRows()
+	if row < m.scroll {
+		m.scroll = row
+	}
+	if row >= m.scroll+rows {
+		m.scroll = row - rows + 1
+	}
+}
+
+// plyOf returns the main-line ply of node n, or 0 for the root and for nodes
+// that are not on the main line.
+func (m GameViewModel) plyOf(n *pgn.Node) int {
+	for i, node := range m.mainline {
+		if node == n {
+			return i
+		}
+	}
+	return 0
+}
+
+// branchPly returns the main-line ply of the current move or, in a
+// variation, of the main-line move it is an alternative to.
+func (m GameViewModel) branchPly() int {
+	ply := m.plyOf(m.current)
+	// The outermost variation branches off the main line
+	for n := m.current; n.Parent != nil; n = n.Parent {
+		if n.IsRoot() {
+			ply = m.plyOf(n.Parent) + 1
+		}
+	}
+	return ply
+}
+
+// nodeAt returns the main-line node of the move shown at screen position
+// (x, y), or nil if there is no move there.
+func (m GameViewModel) nodeAt(x, y int) *pgn.Node {
+	row := y - m.moveListY()
+	col := x - m.moveListX() - moveNumberWidth
+	if row < 0 || row >= m.visibleRows() || col < 0 || col >= 2*moveCellWidth {
+		return nil
+	}
+	ply := 2*(row+m.scroll) + col/moveCellWidth + 1
+	if m.firstBlack() {
+		ply--
+	}
+	if ply < 1 || ply >= len(m.mainline) {
+		return nil
+	}
+	return m.mainline[ply]
+}
+
+// View renders the model
+func (m GameViewModel) View() string {
+	if m.export != nil {
+		return m.renderExport()
+	}
+	if m.showHelp {
+		return m.renderHelp()
+	}
+	if m.settings != nil {
+		return m.settings.View()
+	}
+
+	var b strings.Builder
+
+	// Header
+	b.WriteString(TitleStyle.UnsetMarginBottom().Render(
+		fmt.Sprintf("♔ %s vs %s", m.info.White, m.info.Black)))
+	b.WriteString("\n")
+	b.WriteString(lipgloss.NewStyle().Foreground(ColorTextMuted).Render(m.infoLine()))
+	b.WriteString("\n")
+	for _, line := range m.noteLines() {
+		b.WriteString(lipgloss.NewStyle().Foreground(ColorAccent).Italic(true).Render(line))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	// Eval bar, board and move list side by side; analyzed games also get
+	// an eval graph under the move list
+	marks := LastMoveMarks(m.current.Move)
+	marks.Selected = m.selected
+	for _, mv := range m.targets() {
+		marks.Targets = append(marks.Targets, moveTarget(m.current.Board, mv))
+	}
+	board := RenderBoard(m.current.Board, marks, m.board) + "\n\n" + RenderMaterial(m.current.Board, m.board)
+	moves := SubtitleStyle.UnsetMargins().Render("Moves") + "\n" + m.renderMoveList()
+	if variations := m.renderVariations(); variations != "" {
+		moves += "\n" + variations
+	}
+	var panels []string
+	if m.hasEvals() {
+		eval, _ := m.currentEval()
+		panels = append(panels, RenderEvalBar(eval, 8, m.board.Flipped), " ")
+		graphWidth := max(moveListWidth, m.width-m.moveListX()-1)
+		moves += "\n\n" + RenderEvalGraph(m.evals, len(m.mainline)-1, m.branchPly(), graphWidth)
+	}
+	panels = append(panels, board, strings.Repeat(" ", moveListGap), moves)
+	b.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, panels...))
+	b.WriteString("\n\n")
+
+	// Status: current move and its comment
+	b.WriteString(m.statusLine())
+	b.WriteString("\n")
+	if m.editor != nil {
+		b.WriteString(m.editor.View())
+	} else if comment := commentText(m.current); comment != "" {
+		b.WriteString(lipgloss.NewStyle().
+			Foreground(ColorTextMuted).
+			Italic(true).
+			MaxWidth(max(m.width, MinWidth)).
+			Render(comment))
+	}
+	b.WriteString("\n")
+
+	b.WriteString(HelpStyle.UnsetMarginTop().Render(m.helpText()))
+
+	return b.String()
+}
+
+// copy copies text, the FEN or PGN named what, to the clipboard.
+func (m *GameViewModel) copy(what, text string) tea.Cmd {
+	where, err := copyToClipboard(text)
+	if err != nil {
+		return m.notes.notify(NotifyError, "Failed to copy "+what+": "+err.Error())
+	}
+	return m.notes.notify(NotifySuccess, what+" copied to the "+where)
+}
+
+// helpText returns the key help for the footer. The full list of keys is
+// shown by the help overlay.
+func (m GameViewModel) helpText() string {
+	if m.editor != nil {
+		return "enter save comment • esc cancel"
+	}
+	keys := m.keys.Game
+	return helpLine(keys.Prev, keys.Next, keys.NextVariation, keys.Comment, keys.Flip, keys.Settings, keys.Help, m.backKey())
+}
+
+// backKey returns the binding that leaves the game view, which quits in play
+// mode.
+func (m GameViewModel) backKey() key.Binding {
+	back := m.keys.Game.Back
+	if m.play != nil {
+		back.SetHelp(back.Help().Key, "quit")
+	}
+	return back
+}
+
+// renderHelp renders the help overlay, listing the keys of the game view and
+// of the screens opened from it.
+func (m GameViewModel) renderHelp() string {
+	keys := m.keys.Game
+	highlight := keys.Highlight
+	highlight.SetHelp(highlight.Help().Key, fmt.Sprintf("%s (%s)", highlight.Help().Desc, m.board.Highlight))
+	settings := m.keys.Settings
+	editor := []key.Binding{
+		key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "save comment")),
+		key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "cancel")),
+	}
+
+	var b strings.Builder
+	b.WriteString(TitleStyle.Render("⌨ Keys"))
+	b.WriteString("\n")
+	b.WriteString(renderKeyHelp(
+		keyGroup{"Game view", []key.Binding{
+			keys.Prev, keys.Next, keys.First, keys.Last, keys.NextVariation, keys.PrevVariation,
+			keys.Comment, keys.MoveNag, keys.PositionNag, keys.Flip, keys.Coordinates, highlight,
+			keys.Settings, keys.Export, keys.AddToDatabase, keys.CopyFEN, keys.CopyPGN, keys.Help, m.backKey(),
+		}},
+		keyGroup{"Comment editor", editor},
+		keyGroup{"Export form", []key.Binding{exportNextKey, exportPrevKey, exportSaveKey, exportQuitKey}},
+		keyGroup{"Board settings", []key.Binding{
+			settings.Up, settings.Down, settings.PrevValue, settings.NextValue, settings.Save, settings.Cancel,
+		}},
+	))
+	b.WriteString("\n")
+	b.WriteString(HelpStyle.Render("Click a move to jump to it, or a piece and then a square to move it • press any key to close"))
+	return b.String()
+}
+
+// infoLine summarizes the game for the header.
+func (m GameViewModel) infoLine() string {
+	parts := []string{}
+	for _, s := range []string{m.info.Event, m.info.Date, m.info.Result} {
+		if s != "" {
+			parts = append(parts, s)
+		}
+	}
+	if m.info.ECOCode != "" {
+		parts = append(parts, strings.TrimSpace(m.info.ECOCode+" "+m.info.OpeningName))
+	}
+	return strings.Join(parts, " • ")
+}
+
+// noteLines wraps the game's note to the screen width for the header, cut
+// short after maxNoteLines lines.
+func (m GameViewModel) noteLines() []string {
+	if m.note == "" {
+		return nil
+	}
+	wrapped := lipgloss.NewStyle().Width(max(m.width, MinWidth) - 1).Render("✎ " + m.note)
+	lines := strings.Split(wrapped, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	if len(lines) > maxNoteLines {
+		lines = lines[:maxNoteLines]
+		lines[maxNoteLines-1] += " …"
+	}
+	return lines
+}
+
+// headerHeight returns the height of the header, which grows with the note.
+func (m GameViewModel) headerHeight() int {
+	return gameViewHeaderHeight + len(m.noteLines())
+}
+
+// moveListY returns the screen row of the first move row, below the "Moves"
+// heading.
+func (m GameViewModel) moveListY() int {
+	return m.headerHeight() + 1
+}
+
+// statusLine describes the current move.
+func (m GameViewModel) statusLine() string {
+	plies := len(m.mainline) - 1
+	status := fmt.Sprintf("Starting position (%d plies)", plies)
+	switch {
+	case m.current.Parent == nil:
+	case m.plyOf(m.current) == 0:
+		status = fmt.Sprintf("%s (variation)", StatValueStyle.Render(moveLabel(m.current)))
+	default:
+		status = fmt.Sprintf("%s (ply %d/%d)", StatValueStyle.Render(moveLabel(m.current)), m.plyOf(m.current), plies)
+	}
+	if eval, ok := m.currentEval(); ok {
+		status += "  eval " + FormatEval(eval)
+	}
+	if opening := m.openingName(); opening != "" {
+		status += "  " + lipgloss.NewStyle().Foreground(ColorTextMuted).Render(opening)
+	}
+	if clocks := m.clockLine(); clocks != "" {
+		status += "  " + clocks
+	}
+	if note := m.notes.View(); note != "" {
+		status += "  " + note
+	}
+	return status
+}
+
+// moveLabel formats the move of n with its move number, as in "12. Nf3" or
+// "12... Nf6".
+func moveLabel(n *pgn.Node) string {
+	before := n.Parent.Board
+	dots := "."
+	if before.SideToMove == internal.Black {
+		dots = "..."
+	}
+	return fmt.Sprintf("%d%s %s", before.MoveNr, dots, moveSan(n))
+}
+
+// commandPattern matches embedded commands such as "[%clk 0:05:00]".
+var commandPattern = regexp.MustCompile(`\[%[^\]]*\]`)
+
+// commentText returns the comments of n without embedded commands.
+func commentText(n *pgn.Node) string {
+	var parts []string
+	for _, c := range n.Comment {
+		if c = strings.TrimSpace(commandPattern.ReplaceAllString(c, "")); c != "" {
+			parts = append(parts, c)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// moveSan returns the SAN of the move of n followed by its NAGs.
+func moveSan(n *pgn.Node) string {
+	san := n.Move.San(n.Parent.Board)
+	for _, nag := range n.Nags {
+		if s := nag.String(); !strings.HasPrefix(s, "$") {
+			san += s
+		}
+	}
+	return san
+}
+
+// renderMoveList renders the visible rows of the move list, highlighting the
+// current move, or the move a variation being shown branches from. Moves with
+// alternatives are underlined.
+func (m GameViewModel) renderMoveList() string {
+	rows := m.rowCount()
+	if rows == 0 {
+		return lipgloss.NewStyle().Foreground(ColorTextMuted).Render("(no moves)")
+	}
+
+	numberStyle := lipgloss.NewStyle().Foreground(ColorTextMuted)
+	currentStyle := lipgloss.NewStyle().Foreground(ColorBg).Background(ColorAccent).Bold(true)
+	branchStyle := lipgloss.NewStyle().Background(ColorBgLight)
+	branch := 0
+	if m.inVariation() {
+		branch = m.branchPly()
+	}
+
+	cell := func(ply int) string {
+		if ply < 1 || ply >= len(m.mainline) {
+			return strings.Repeat(" ", moveCellWidth)
+		}
+		n := m.mainline[ply]
+		san := moveSan(n)
+		if len(n.Variations()) > 0 {
+			san = lipgloss.NewStyle().Underline(true).Render(san)
+		}
+		// Pad by display width, as NAG glyphs take several bytes
+		text := san + strings.Repeat(" ", max(0, moveCellWidth-1-lipgloss.Width(san)))
+		switch {
+		case n == m.current:
+			text = currentStyle.Render(text)
+		case ply == branch:
+			text = branchStyle.Render(text)
+		}
+		return text + " "
+	}
+
+	offset := 0
+	if m.firstBlack() {
+		offset = 1
+	}
+	moveNr := m.game.Root.Board.MoveNr
+
+	var lines []string
+	last := min(rows, m.scroll+m.visibleRows())
+	for row := m.scroll; row < last; row++ {
+		white := 2*row + 1 - offset
+		lines = append(lines, numberStyle.Render(fmt.Sprintf("%3d. ", moveNr+row))+cell(white)+cell(white+1))
+	}
+	return lipgloss.NewStyle().Width(moveListWidth).Render(strings.Join(lines, "\n"))
+}
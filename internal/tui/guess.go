@@ -0,0 +1,356 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/kyleboon/gochess/internal"
+	"github.com/kyleboon/gochess/internal/pgn"
+)
+
+// EvalFunc evaluates the position fen, in pawns from White's point of view.
+type EvalFunc func(fen string) (float64, error)
+
+// ScheduleReviewFunc schedules a position for spaced repetition, with the
+// move to find in it in SAN.
+type ScheduleReviewFunc func(fen, answer string) error
+
+// Points of a guess: the game move scores most, and a move the engine
+// rates within guessTolerance pawns of it scores some.
+const (
+	guessMatchPoints       = 2
+	guessAlternativePoints = 1
+	guessTolerance         = 0.3
+)
+
+// guessEvalMsg carries the evaluations of the positions after a guess that
+// was not the game move and after the game move.
+type guessEvalMsg struct {
+	guess, actual string // the moves, in SAN
+	guessEval     float64
+	actualEval    float64
+	err           error
+}
+
+// GuessModel replays a game, stopping before each move of one side for the
+// move to be guessed. Guessing the game move scores guessMatchPoints; another
+// move scores guessAlternativePoints when the engine rates it about as good.
+type GuessModel struct {
+	title    string
+	nodes    []*pgn.Node // the mainline, from the starting position
+	ply      int         // index in nodes of the position shown
+	side     int         // the color whose moves are guessed
+	fromMove int         // the first move number guessed
+	points   int
+	guesses  int
+	matches  int
+	started  time.Time
+	finished bool
+	checking bool   // waiting for the evaluation of a guess
+	feedback string // rendered result of the last guess
+	input    textinput.Model
+	board    BoardOptions
+	save     SaveTrainingFunc
+	eval     EvalFunc
+	review   ScheduleReviewFunc
+	notes    notifier
+	quitting bool
+	width    int
+	height   int
+}
+
+// NewGuessModel creates a session guessing the moves side plays in game,
+// from move number fromMove on.
+func NewGuessModel(game *pgn.Game, side, fromMove int) (GuessModel, error) {
+	var nodes []*pgn.Node
+	for n := game.Root; n != nil; n = n.Next {
+		nodes = append(nodes, n)
+	}
+	input := textinput.New()
+	input.Prompt = "> "
+	input.Width = MinWidth - 4
+	input.Focus()
+	m := GuessModel{
+		title:    fmt.Sprintf("%s - %s", game.Tags["White"], game.Tags["Black"]),
+		nodes:    nodes,
+		side:     side,
+		fromMove: max(fromMove, 1),
+		input:    input,
+		board:    BoardOptions{Theme: DefaultTheme()},
+		width:    MinWidth,
+		height:   MinHeight,
+	}
+	m.start()
+	if m.finished {
+		return GuessModel{}, fmt.Errorf("the game has no %s moves from move %d to guess", [2]string{"White", "Black"}[side], m.fromMove)
+	}
+	return m, nil
+}
+
+// WithBoardOptions sets how the board is drawn. The board is flipped when
+// Black's moves are guessed.
+func (m GuessModel) WithBoardOptions(opts BoardOptions) GuessModel {
+	m.board = opts
+	m.board.Flipped = m.side == internal.Black
+	return m
+}
+
+// WithScoreSaver sets the function that stores the score of each finished
+// session.
+func (m GuessModel) WithScoreSaver(save SaveTrainingFunc) GuessModel {
+	m.save = save
+	return m
+}
+
+// WithEvaluator sets the function that evaluates guesses that are not the
+// game move. Without one they score nothing.
+func (m GuessModel) WithEvaluator(eval EvalFunc) GuessModel {
+	m.eval = eval
+	return m
+}
+
+// WithReviewScheduler sets the function that schedules the positions whose
+// game move was missed for spaced repetition.
+func (m GuessModel) WithReviewScheduler(review ScheduleReviewFunc) GuessModel {
+	m.review = review
+	return m
+}
+
+// start begins a new session, at the first move to guess.
+func (m *GuessModel) start() {
+	m.points, m.guesses, m.matches = 0, 0, 0
+	m.started = time.Now()
+	m.finished = false
+	m.checking = false
+	m.feedback = ""
+	m.input.SetValue("")
+	m.ply = 0
+	m.skip()
+}
+
+// skip plays the game on up to the next move to guess, ending the session
+// when there is none.
+func (m *GuessModel) skip() {
+	for m.ply < len(m.nodes)-1 {
+		b := m.nodes[m.ply].Board
+		if b.SideToMove == m.side && b.MoveNr >= m.fromMove {
+			return
+		}
+		m.ply++
+	}
+	m.finished = true
+}
+
+// Init initializes the model
+func (m GuessModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+// Update handles messages
+func (m GuessModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if cmd, ok := m.notes.update(msg); ok {
+		return m, cmd
+	}
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.input.Width = max(m.width, MinWidth) - 4
+		return m, nil
+
+	case guessEvalMsg:
+		m.checking = false
+		if msg.err != nil {
+			m.feedback = wrongStyle.Render(fmt.Sprintf("✗ The game move was %s", msg.actual))
+			return m, tea.Batch(m.notes.notify(NotifyError, "Failed to evaluate the guess: "+msg.err.Error()), m.miss(), m.advance())
+		}
+		loss := msg.actualEval - msg.guessEval
+		if m.side == internal.Black {
+			loss = -loss
+		}
+		if loss <= guessTolerance {
+			m.points += guessAlternativePoints
+			m.feedback = alternativeStyle.Render(fmt.Sprintf("≈ %s (%s) is about as good as the game move %s (%s)",
+				msg.guess, FormatEval(msg.guessEval), msg.actual, FormatEval(msg.actualEval)))
+		} else {
+			m.feedback = wrongStyle.Render(fmt.Sprintf("✗ The game move was %s (%s); %s gives %s",
+				msg.actual, FormatEval(msg.actualEval), msg.guess, FormatEval(msg.guessEval)))
+			return m, tea.Batch(m.miss(), m.advance())
+		}
+		return m, m.advance()
+
+	case tea.KeyMsg:
+		switch {
+		case msg.String() == "ctrl+c":
+			m.quitting = true
+			return m, tea.Quit
+		case key.Matches(msg, trainQuitKey):
+			// The moves guessed so far count when leaving early
+			var cmd tea.Cmd
+			if !m.finished && m.guesses > 0 {
+				cmd = m.finish()
+			}
+			m.quitting = true
+			return m, tea.Sequence(cmd, tea.Quit)
+		case m.finished:
+			if key.Matches(msg, trainAgainKey) {
+				m.start()
+			}
+			return m, nil
+		case key.Matches(msg, trainEnterKey):
+			answer := strings.TrimSpace(m.input.Value())
+			if answer == "" {
+				return m, nil
+			}
+			if m.checking {
+				return m, m.notes.notify(NotifyInfo, "Wait for the evaluation of the last guess")
+			}
+			return m, m.guess(answer)
+		}
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+// guess scores a guessed move, if it is legal in the position shown.
+func (m *GuessModel) guess(answer string) tea.Cmd {
+	board := m.nodes[m.ply].Board
+	mv, err := board.ParseMove(answer)
+	if err != nil {
+		return m.notes.notify(NotifyError, fmt.Sprintf("%s: %v", answer, err))
+	}
+	m.input.SetValue("")
+	m.guesses++
+	actual := m.nodes[m.ply+1]
+	if mv == actual.Move {
+		m.points += guessMatchPoints
+		m.matches++
+		m.feedback = correctStyle.Render(fmt.Sprintf("✓ %s is the game move", moveSan(actual)))
+		return m.advance()
+	}
+	if m.eval == nil {
+		m.feedback = wrongStyle.Render(fmt.Sprintf("✗ The game move was %s", moveSan(actual)))
+		return tea.Batch(m.miss(), m.advance())
+	}
+
+	m.checking = true
+	eval := m.eval
+	msg := guessEvalMsg{guess: mv.San(board), actual: moveSan(actual)}
+	guessFEN, actualFEN := board.MakeMove(mv).Fen(), actual.Board.Fen()
+	return func() tea.Msg {
+		if msg.guessEval, msg.err = eval(guessFEN); msg.err == nil {
+			msg.actualEval, msg.err = eval(actualFEN)
+		}
+		return msg
+	}
+}
+
+// miss schedules the position shown, whose game move was missed, for
+// review. It is called before the game move is played.
+func (m *GuessModel) miss() tea.Cmd {
+	if m.review == nil {
+		return nil
+	}
+	board := m.nodes[m.ply].Board
+	review, fen, answer := m.review, board.Fen(), m.nodes[m.ply+1].Move.San(board)
+	return notifyFailure(func() error { return review(fen, answer) }, "Failed to schedule the position for review")
+}
+
+// advance plays the game move, then the game on up to the next move to
+// guess, or ends the session after the last one.
+func (m *GuessModel) advance() tea.Cmd {
+	m.ply++
+	m.skip()
+	if m.finished {
+		return m.finish()
+	}
+	return nil
+}
+
+// finish ends the session and stores its score.
+func (m *GuessModel) finish() tea.Cmd {
+	m.finished = true
+	if m.save == nil || m.guesses == 0 {
+		return nil
+	}
+	save, res := m.save, TrainingResult{
+		Mode:     TrainingGuessMove,
+		Score:    m.points,
+		Total:    m.guesses * guessMatchPoints,
+		Duration: time.Since(m.started),
+	}
+	return notifyResult(func() error { return save(res) }, "Score saved", "Failed to save the score")
+}
+
+// View renders the model
+func (m GuessModel) View() string {
+	if m.quitting {
+		return "Thanks for using GoChess!\n"
+	}
+	var s strings.Builder
+	s.WriteString(TitleStyle.UnsetMarginBottom().Render("🎯 " + trainingTitles[TrainingGuessMove] + ": " + m.title))
+	s.WriteString("\n")
+	s.WriteString(lipgloss.NewStyle().Foreground(ColorTextMuted).Render(m.status()))
+	s.WriteString("\n\n")
+
+	current := m.nodes[m.ply]
+	marks := BoardMarks{Last: internal.NullMove, Selected: internal.NoSquare}
+	if current.Parent != nil {
+		marks.Last = current.Move
+	}
+	s.WriteString(RenderBoard(current.Board, marks, m.board))
+	s.WriteString("\n\n")
+	if current.Parent != nil {
+		s.WriteString("Last move: " + moveLabel(current) + "\n")
+	}
+	if !m.finished {
+		if m.checking {
+			s.WriteString("Evaluating your guess...")
+		} else {
+			color := [2]string{"White", "Black"}[m.side]
+			s.WriteString(fmt.Sprintf("Guess %s's move %d, in SAN or UCI:\n", color, current.Board.MoveNr))
+			s.WriteString(m.input.View())
+		}
+	}
+	s.WriteString("\n\n")
+	if m.feedback != "" {
+		s.WriteString(m.feedback + "\n")
+	}
+	if m.finished {
+		s.WriteString(m.summary() + "\n")
+	}
+	if note := m.notes.View(); note != "" {
+		s.WriteString(note + "\n")
+	}
+
+	help := []key.Binding{trainEnterKey}
+	if m.finished {
+		help = []key.Binding{trainAgainKey}
+	}
+	help = append(help, trainQuitKey)
+	s.WriteString(HelpStyle.UnsetMarginTop().Render(helpLine(help...)))
+	return s.String()
+}
+
+// status describes the progress of the session.
+func (m GuessModel) status() string {
+	return fmt.Sprintf("%d of %d moves guessed • %d points of %d",
+		m.matches, m.guesses, m.points, m.guesses*guessMatchPoints)
+}
+
+// summary renders the score of a finished session.
+func (m GuessModel) summary() string {
+	elapsed := time.Since(m.started).Round(time.Second)
+	text := fmt.Sprintf("Game over • %d of %d game moves found • score %d/%d in %s",
+		m.matches, m.guesses, m.points, m.guesses*guessMatchPoints, elapsed)
+	return SubtitleStyle.UnsetMargins().Render(text)
+}
+
+var alternativeStyle = lipgloss.NewStyle().Foreground(ColorWarning)
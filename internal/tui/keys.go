@@ -0,0 +1,326 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ListKeys are the key bindings of the game list, in addition to the list's
+// own navigation and filtering keys.
+type ListKeys struct {
+	Open  key.Binding
+	Paste key.Binding
+	Quit  key.Binding
+}
+
+// GameKeys are the key bindings of the game view and play mode.
+type GameKeys struct {
+	Prev          key.Binding
+	Next          key.Binding
+	First         key.Binding
+	Last          key.Binding
+	NextVariation key.Binding
+	PrevVariation key.Binding
+	Comment       key.Binding
+	MoveNag       key.Binding
+	PositionNag   key.Binding
+	Flip          key.Binding
+	Coordinates   key.Binding
+	Highlight     key.Binding
+	Settings      key.Binding
+	Export        key.Binding
+	AddToDatabase key.Binding
+	CopyFEN       key.Binding
+	CopyPGN       key.Binding
+	Help          key.Binding
+	Back          key.Binding
+}
+
+// SettingsKeys are the key bindings of the settings screen.
+type SettingsKeys struct {
+	Up        key.Binding
+	Down      key.Binding
+	PrevValue key.Binding
+	NextValue key.Binding
+	Save      key.Binding
+	Cancel    key.Binding
+}
+
+// SetupKeys are the key bindings of the board editor. Pieces are placed by
+// typing their FEN letters (P, n, ...), which cannot be remapped.
+type SetupKeys struct {
+	Up            key.Binding
+	Down          key.Binding
+	Left          key.Binding
+	Right         key.Binding
+	Clear         key.Binding
+	SideToMove    key.Binding
+	WhiteOO       key.Binding
+	WhiteOOO      key.Binding
+	BlackOO       key.Binding
+	BlackOOO      key.Binding
+	EnPassant     key.Binding
+	StartPosition key.Binding
+	EmptyBoard    key.Binding
+	Flip          key.Binding
+	Analyse       key.Binding
+	Play          key.Binding
+	CopyFEN       key.Binding
+	PasteFEN      key.Binding
+	Help          key.Binding
+	Back          key.Binding
+}
+
+// TreeKeys are the key bindings of the opening tree.
+type TreeKeys struct {
+	Up       key.Binding
+	Down     key.Binding
+	Expand   key.Binding
+	Collapse key.Binding
+	Quit     key.Binding
+}
+
+// KeyMap holds the key bindings of all screens. Ctrl+C always quits and
+// cannot be remapped.
+type KeyMap struct {
+	List     ListKeys
+	Game     GameKeys
+	Settings SettingsKeys
+	Setup    SetupKeys
+	Tree     TreeKeys
+}
+
+// DefaultKeyMap returns the default key bindings.
+func DefaultKeyMap() KeyMap {
+	bind := func(help, desc string, keys ...string) key.Binding {
+		return key.NewBinding(key.WithKeys(keys...), key.WithHelp(help, desc))
+	}
+	return KeyMap{
+		List: ListKeys{
+			Open:  bind("enter", "open game", "enter"),
+			Paste: bind("p", "paste PGN or FEN", "p", "ctrl+v"),
+			Quit:  bind("q", "quit", "q", "esc"),
+		},
+		Game: GameKeys{
+			Prev:          bind("←/h", "previous move", "left", "h"),
+			Next:          bind("→/l", "next move", "right", "l"),
+			First:         bind("↑/home", "starting position", "home", "up", "k"),
+			Last:          bind("↓/end", "last move", "end", "down", "j"),
+			NextVariation: bind("tab", "next variation", "tab"),
+			PrevVariation: bind("shift+tab", "previous variation", "shift+tab"),
+			Comment:       bind("e", "edit comment", "e"),
+			MoveNag:       bind("n", "cycle move annotation", "n"),
+			PositionNag:   bind("N", "cycle position annotation", "N"),
+			Flip:          bind("f", "flip board", "f"),
+			Coordinates:   bind("c", "toggle coordinates", "c"),
+			Highlight:     bind("s", "cycle highlight style", "s"),
+			Settings:      bind("t", "board settings", "t"),
+			Export:        bind("w", "write to PGN file", "w"),
+			AddToDatabase: bind("a", "add to database", "a"),
+			CopyFEN:       bind("y", "copy FEN", "y"),
+			CopyPGN:       bind("Y", "copy PGN", "Y"),
+			Help:          bind("?", "show keys", "?"),
+			Back:          bind("q", "back", "q", "esc"),
+		},
+		Settings: SettingsKeys{
+			Up:        bind("↑", "previous setting", "up", "k"),
+			Down:      bind("↓", "next setting", "down", "j", "tab"),
+			PrevValue: bind("←", "previous value", "left", "h"),
+			NextValue: bind("→", "next value", "right", "l", " "),
+			Save:      bind("enter", "save", "enter"),
+			Cancel:    bind("esc", "cancel", "esc", "q"),
+		},
+		Setup: SetupKeys{
+			Up:            bind("↑", "cursor up", "up"),
+			Down:          bind("↓", "cursor down", "down"),
+			Left:          bind("←", "cursor left", "left"),
+			Right:         bind("→", "cursor right", "right"),
+			Clear:         bind("x", "clear square", "x", "delete", "backspace"),
+			SideToMove:    bind("tab", "toggle side to move", "tab"),
+			WhiteOO:       bind("1", "White castles short", "1"),
+			WhiteOOO:      bind("2", "White castles long", "2"),
+			BlackOO:       bind("3", "Black castles short", "3"),
+			BlackOOO:      bind("4", "Black castles long", "4"),
+			EnPassant:     bind("e", "en passant square", "e"),
+			StartPosition: bind("s", "starting position", "s"),
+			EmptyBoard:    bind("0", "empty board", "0"),
+			Flip:          bind("f", "flip board", "f"),
+			Analyse:       bind("enter", "analyse position", "enter"),
+			Play:          bind("g", "play with clock", "g"),
+			CopyFEN:       bind("y", "copy FEN", "y"),
+			PasteFEN:      bind("ctrl+v", "paste FEN", "ctrl+v"),
+			Help:          bind("?", "show keys", "?"),
+			Back:          bind("esc", "quit", "esc"),
+		},
+		Tree: TreeKeys{
+			Up:       bind("↑", "previous move", "up", "k"),
+			Down:     bind("↓", "next move", "down", "j"),
+			Expand:   bind("→/enter", "expand", "right", "l", "enter"),
+			Collapse: bind("←", "collapse", "left", "h"),
+			Quit:     bind("q", "quit", "q", "esc"),
+		},
+	}
+}
+
+// namedBinding is a binding with the name it is configured by, such as
+// "game.flip".
+type namedBinding struct {
+	name    string
+	binding *key.Binding
+}
+
+// named returns the bindings of k by configuration name, grouped by screen.
+func (k *KeyMap) named() []namedBinding {
+	return []namedBinding{
+		{"list.open", &k.List.Open},
+		{"list.paste", &k.List.Paste},
+		{"list.quit", &k.List.Quit},
+		{"game.prev", &k.Game.Prev},
+		{"game.next", &k.Game.Next},
+		{"game.first", &k.Game.First},
+		{"game.last", &k.Game.Last},
+		{"game.next_variation", &k.Game.NextVariation},
+		{"game.prev_variation", &k.Game.PrevVariation},
+		{"game.comment", &k.Game.Comment},
+		{"game.move_nag", &k.Game.MoveNag},
+		{"game.position_nag", &k.Game.PositionNag},
+		{"game.flip", &k.Game.Flip},
+		{"game.coordinates", &k.Game.Coordinates},
+		{"game.highlight", &k.Game.Highlight},
+		{"game.settings", &k.Game.Settings},
+		{"game.export", &k.Game.Export},
+		{"game.add", &k.Game.AddToDatabase},
+		{"game.copy_fen", &k.Game.CopyFEN},
+		{"game.copy_pgn", &k.Game.CopyPGN},
+		{"game.help", &k.Game.Help},
+		{"game.back", &k.Game.Back},
+		{"settings.up", &k.Settings.Up},
+		{"settings.down", &k.Settings.Down},
+		{"settings.prev_value", &k.Settings.PrevValue},
+		{"settings.next_value", &k.Settings.NextValue},
+		{"settings.save", &k.Settings.Save},
+		{"settings.cancel", &k.Settings.Cancel},
+		{"setup.up", &k.Setup.Up},
+		{"setup.down", &k.Setup.Down},
+		{"setup.left", &k.Setup.Left},
+		{"setup.right", &k.Setup.Right},
+		{"setup.clear", &k.Setup.Clear},
+		{"setup.side_to_move", &k.Setup.SideToMove},
+		{"setup.white_oo", &k.Setup.WhiteOO},
+		{"setup.white_ooo", &k.Setup.WhiteOOO},
+		{"setup.black_oo", &k.Setup.BlackOO},
+		{"setup.black_ooo", &k.Setup.BlackOOO},
+		{"setup.en_passant", &k.Setup.EnPassant},
+		{"setup.start_position", &k.Setup.StartPosition},
+		{"setup.empty_board", &k.Setup.EmptyBoard},
+		{"setup.flip", &k.Setup.Flip},
+		{"setup.analyse", &k.Setup.Analyse},
+		{"setup.play", &k.Setup.Play},
+		{"setup.copy_fen", &k.Setup.CopyFEN},
+		{"setup.paste_fen", &k.Setup.PasteFEN},
+		{"setup.help", &k.Setup.Help},
+		{"setup.back", &k.Setup.Back},
+		{"tree.up", &k.Tree.Up},
+		{"tree.down", &k.Tree.Down},
+		{"tree.expand", &k.Tree.Expand},
+		{"tree.collapse", &k.Tree.Collapse},
+		{"tree.quit", &k.Tree.Quit},
+	}
+}
+
+// Remap replaces the keys of the actions in keys, which maps action names
+// such as "game.flip" to the keys that trigger them ("f", "ctrl+f", "left").
+// It fails on unknown actions, when two actions of the same screen would
+// share a key, and when a board editor key would shadow a piece letter.
+func (k *KeyMap) Remap(keys map[string][]string) error {
+	bindings := k.named()
+	names := make([]string, 0, len(keys))
+	for name := range keys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		found := false
+		for _, b := range bindings {
+			if b.name != name {
+				continue
+			}
+			if len(keys[name]) == 0 {
+				return fmt.Errorf("no keys given for %s", name)
+			}
+			b.binding.SetKeys(keys[name]...)
+			b.binding.SetHelp(strings.Join(keys[name], "/"), b.binding.Help().Desc)
+			found = true
+		}
+		if !found {
+			return fmt.Errorf("unknown key action %q", name)
+		}
+	}
+
+	// Each key may only do one thing per screen
+	used := make(map[string]string)
+	for _, b := range bindings {
+		screen, _, _ := strings.Cut(b.name, ".")
+		for _, k := range b.binding.Keys() {
+			if screen == "setup" && len(k) == 1 && strings.Contains(pieceLetters, k) {
+				return fmt.Errorf("key %q of %s places a piece in the board editor", k, b.name)
+			}
+			if other, ok := used[screen+" "+k]; ok {
+				return fmt.Errorf("key %q is bound to both %s and %s", k, other, b.name)
+			}
+			used[screen+" "+k] = b.name
+		}
+	}
+	return nil
+}
+
+// helpLine renders bindings as a one-line key summary, "key desc • key desc".
+func helpLine(bindings ...key.Binding) string {
+	var parts []string
+	for _, b := range bindings {
+		if b.Enabled() {
+			parts = append(parts, b.Help().Key+" "+b.Help().Desc)
+		}
+	}
+	return strings.Join(parts, " • ")
+}
+
+// keyGroup is a titled list of bindings shown in the help overlay.
+type keyGroup struct {
+	title    string
+	bindings []key.Binding
+}
+
+// renderKeyHelp renders the help overlay: every binding of each group, one
+// per line, with the keys in a column.
+func renderKeyHelp(groups ...keyGroup) string {
+	keyStyle := lipgloss.NewStyle().Foreground(ColorAccent).Bold(true)
+	descStyle := lipgloss.NewStyle().Foreground(ColorText)
+
+	width := 0
+	for _, g := range groups {
+		for _, b := range g.bindings {
+			width = max(width, lipgloss.Width(b.Help().Key))
+		}
+	}
+
+	var sections []string
+	for _, g := range groups {
+		lines := []string{SubtitleStyle.UnsetMargins().Render(g.title)}
+		for _, b := range g.bindings {
+			if !b.Enabled() {
+				continue
+			}
+			keys := b.Help().Key
+			keys += strings.Repeat(" ", width-lipgloss.Width(keys))
+			lines = append(lines, "  "+keyStyle.Render(keys)+"  "+descStyle.Render(b.Help().Desc))
+		}
+		sections = append(sections, strings.Join(lines, "\n"))
+	}
+	return BorderStyle.Render(strings.Join(sections, "\n\n"))
+}
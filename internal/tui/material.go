@@ -0,0 +1,47 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/kyleboon/gochess/internal"
+)
+
+// materialOrder lists the piece types in the order they are shown.
+var materialOrder = []int{internal.Queen, internal.Rook, internal.Bishop, internal.Knight, internal.Pawn}
+
+// RenderMaterial renders the material imbalance of a position, one line per
+// side: the pieces each side has more of than the other, and the point
+// advantage of the side that is ahead, as "White ♝♟♟ +2" and "Black ♞". A
+// bishop against a knight thus shows as a bishop for one side and a knight
+// for the other.
+func RenderMaterial(b *internal.Board, opts BoardOptions) string {
+	label := lipgloss.NewStyle().Foreground(ColorTextMuted)
+	pieces := lipgloss.NewStyle().Foreground(ColorText)
+	points := lipgloss.NewStyle().Foreground(ColorSuccess).Bold(true)
+
+	diff := (b.Material(internal.White) - b.Material(internal.Black)) / internal.PieceValue(internal.Pawn)
+	var lines []string
+	for _, color := range []int{internal.White, internal.Black} {
+		var extra strings.Builder
+		for _, typ := range materialOrder {
+			n := b.Count(internal.Piece(color|typ)) - b.Count(internal.Piece(color^1|typ))
+			for ; n > 0; n-- {
+				extra.WriteString(opts.Pieces.glyph(internal.Piece(color | typ)))
+			}
+		}
+		line := label.Render([2]string{"White", "Black"}[color])
+		if extra.Len() > 0 {
+			line += " " + pieces.Render(extra.String())
+		}
+		if color == internal.Black {
+			diff = -diff
+		}
+		if diff > 0 {
+			line += " " + points.Render(fmt.Sprintf("+%d", diff))
+		}
+		lines = append(lines, lipgloss.NewStyle().MaxWidth(opts.Width()).Render(line))
+	}
+	return strings.Join(lines, "\n")
+}
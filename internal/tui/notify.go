@@ -0,0 +1,119 @@
+package tui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// notificationTimeout is how long a notification stays in the status bar.
+const notificationTimeout = 5 * time.Second
+
+// NotificationLevel is how a notification is styled.
+type NotificationLevel int
+
+// Notification levels
+const (
+	NotifyInfo NotificationLevel = iota
+	NotifySuccess
+	NotifyError
+)
+
+// NotifyMsg shows a transient notification in the status bar. Commands that
+// run in the background return one to report how they went.
+type NotifyMsg struct {
+	Level NotificationLevel
+	Text  string
+}
+
+// Notify returns a command that shows a notification.
+func Notify(level NotificationLevel, text string) tea.Cmd {
+	return func() tea.Msg {
+		return NotifyMsg{Level: level, Text: text}
+	}
+}
+
+// notifyResult returns a command that runs action in the background and
+// notifies its outcome: done on success, or failed followed by the error.
+func notifyResult(action func() error, done, failed string) tea.Cmd {
+	return func() tea.Msg {
+		if err := action(); err != nil {
+			return NotifyMsg{Level: NotifyError, Text: failed + ": " + err.Error()}
+		}
+		return NotifyMsg{Level: NotifySuccess, Text: done}
+	}
+}
+
+// notifyFailure returns a command that runs action in the background and
+// notifies only when it fails: failed followed by the error.
+func notifyFailure(action func() error, failed string) tea.Cmd {
+	return func() tea.Msg {
+		if err := action(); err != nil {
+			return NotifyMsg{Level: NotifyError, Text: failed + ": " + err.Error()}
+		}
+		return nil
+	}
+}
+
+// notificationExpiredMsg hides notifications that were due to expire by the
+// time it was sent.
+type notificationExpiredMsg time.Time
+
+// notifier holds the notification shown in a status bar. Each notification
+// replaces the previous one and disappears after notificationTimeout.
+type notifier struct {
+	current NotifyMsg
+	until   time.Time // when the current notification expires
+	visible bool
+}
+
+// show displays msg and returns the command that hides it again.
+func (n *notifier) show(msg NotifyMsg) tea.Cmd {
+	n.current = msg
+	n.until = time.Now().Add(notificationTimeout)
+	n.visible = true
+	return tea.Tick(notificationTimeout, func(t time.Time) tea.Msg {
+		return notificationExpiredMsg(t)
+	})
+}
+
+// notify displays a notification of level and returns the command that hides
+// it again.
+func (n *notifier) notify(level NotificationLevel, text string) tea.Cmd {
+	return n.show(NotifyMsg{Level: level, Text: text})
+}
+
+// update handles the notification messages, reporting whether msg was one.
+func (n *notifier) update(msg tea.Msg) (tea.Cmd, bool) {
+	switch msg := msg.(type) {
+	case NotifyMsg:
+		return n.show(msg), true
+	case notificationExpiredMsg:
+		// A later notification outlives the timers of earlier ones
+		if !time.Time(msg).Before(n.until) {
+			n.visible = false
+		}
+		return nil, true
+	}
+	return nil, false
+}
+
+// View renders the current notification, or nothing once it has expired.
+func (n notifier) View() string {
+	if !n.visible {
+		return ""
+	}
+	return renderNotification(n.current)
+}
+
+// renderNotification styles a notification by its level.
+func renderNotification(msg NotifyMsg) string {
+	switch msg.Level {
+	case NotifySuccess:
+		return lipgloss.NewStyle().Foreground(ColorSuccess).Render("✓ " + msg.Text)
+	case NotifyError:
+		return lipgloss.NewStyle().Foreground(ColorError).Bold(true).Render("✗ " + msg.Text)
+	}
+	return lipgloss.NewStyle().Foreground(ColorInfo).Render(msg.Text)
+}
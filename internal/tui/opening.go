@@ -0,0 +1,45 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/kyleboon/gochess/internal/eco"
+	"github.com/kyleboon/gochess/internal/pgn"
+)
+
+// SetOpenings sets the ECO database used to name the opening of the shown
+// position in the status line.
+func (m *GameViewModel) SetOpenings(openings *eco.Database) {
+	m.openings = openings
+}
+
+// openingName returns the ECO code and name of the opening reached at the
+// current move, as in "C65 Ruy Lopez: Berlin Defense". Once the game leaves
+// known theory the last opening it was in is kept. Games from a set-up
+// position have no opening.
+func (m GameViewModel) openingName() string {
+	if m.openings == nil || m.game.Root.Board.Fen() != startFEN {
+		return ""
+	}
+	code, name, ok := m.openings.Classify(sanLine(m.current))
+	if !ok {
+		return ""
+	}
+	return strings.TrimSpace(code + " " + name)
+}
+
+// sanLine returns the moves leading to n in SAN, following variations back
+// to the main line.
+func sanLine(n *pgn.Node) []string {
+	var moves []string
+	for ; n.Parent != nil; n = n.Parent {
+		// The root node of a variation holds no move
+		if !n.IsRoot() {
+			moves = append(moves, n.Move.San(n.Parent.Board))
+		}
+	}
+	for i, j := 0, len(moves)-1; i < j; i, j = i+1, j-1 {
+		moves[i], moves[j] = moves[j], moves[i]
+	}
+	return moves
+}
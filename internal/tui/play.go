@@ -0,0 +1,278 @@
+package tui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/kyleboon/gochess/internal"
+	"github.com/kyleboon/gochess/internal/eco"
+	"github.com/kyleboon/gochess/internal/pgn"
+)
+
+// startFEN is the standard starting position.
+const startFEN = "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
+
+// playState is the state of a game being played in the game view.
+type playState struct {
+	clock ChessClock
+	state *internal.GameState // repetitions and the move counters
+	over  bool                // the game has ended
+	now   func() time.Time    // clock source, replaced in tests
+
+	onChange func(pgnText string) // told of each move and the result, if set
+}
+
+// newPlayView creates a game view for a new game between two players at the
+// same terminal from the position fen, with base time and increment on the
+// clock.
+func newPlayView(fen string, base, increment time.Duration) GameViewModel {
+	info := Game{
+		Event:       "Casual game",
+		Site:        "gochess",
+		Date:        time.Now().Format("2006.01.02"),
+		White:       "White",
+		Black:       "Black",
+		Result:      "*",
+		TimeControl: fmt.Sprintf("%d+%d", int(base.Seconds()), int(increment.Seconds())),
+	}
+	tags := map[string]string{
+		"Event":       info.Event,
+		"Site":        info.Site,
+		"Date":        info.Date,
+		"Round":       "-",
+		"White":       info.White,
+		"Black":       info.Black,
+		"Result":      info.Result,
+		"TimeControl": info.TimeControl,
+		"FEN":         fen,
+	}
+	if fen != startFEN {
+		tags["SetUp"] = "1"
+	}
+	game, _ := pgn.NewGame(tags)
+	m := NewGameViewModel(info, game)
+	m.play = &playState{
+		clock: NewChessClock(base, increment),
+		state: internal.NewGameState(game.Root.Board),
+		now:   time.Now,
+	}
+	return m
+}
+
+// playing reports whether moves are being played against the clock.
+func (m GameViewModel) playing() bool {
+	return m.play != nil && !m.play.over
+}
+
+// tip returns the last move of the main line, where play continues.
+func (m GameViewModel) tip() *pgn.Node {
+	return m.mainline[len(m.mainline)-1]
+}
+
+// pressClock records the move just played in play mode: the mover's clock is
+// stopped and its time written to the move, and the game ends on mate,
+// stalemate, insufficient material, fivefold repetition or the 75-move rule.
+// The players are told when a draw can be claimed.
+func (m *GameViewModel) pressClock() tea.Cmd {
+	now := m.play.now()
+	color := m.current.Parent.Board.SideToMove
+	left := m.play.clock.Press(color, now)
+	m.current.Comment = []string{clockComment(left)}
+
+	if err := m.play.state.Push(m.current.Move); err != nil {
+		return m.notes.notify(NotifyError, err.Error())
+	}
+	if result, reason := m.play.state.Termination(); reason != "" {
+		return m.endGame(result, reason)
+	}
+	m.gameChanged()
+	if claim := m.play.state.ClaimableDraw(); claim != "" {
+		return m.notes.notify(NotifyInfo, "Draw claimable: "+claim)
+	}
+	return nil
+}
+
+// checkFlag ends the game if the clock of the side to move has run out. A
+// player who runs out of time loses, unless the opponent has only the king
+// left.
+func (m *GameViewModel) checkFlag() tea.Cmd {
+	color, flagged := m.play.clock.Flagged(m.play.now())
+	if !flagged {
+		return nil
+	}
+	name := [2]string{"White", "Black"}[color]
+	if len(m.tip().Board.GetPieceTypes(color^1)) == 1 {
+		return m.endGame(pgn.Draw.String(), name+" ran out of time, opponent has insufficient material")
+	}
+	return m.endGame(winner(color^1), name+" lost on time")
+}
+
+// endGame stops the clock and records the result and reason the game ended.
+func (m *GameViewModel) endGame(result, termination string) tea.Cmd {
+	m.play.clock.Stop(m.play.now())
+	m.play.over = true
+	m.game.Tags["Result"] = result
+	m.game.Tags["Termination"] = termination
+	m.info.Result = result
+	m.gameChanged()
+	return m.notes.notify(NotifyInfo, fmt.Sprintf("Game over: %s (%s)", termination, result))
+}
+
+// gameChanged passes the game as PGN to the play listener, if there is one.
+func (m *GameViewModel) gameChanged() {
+	if m.play.onChange != nil {
+		m.play.onChange(m.game.String())
+	}
+}
+
+// playFedMove plays a move made on a board connected to the game, in SAN,
+// at the end of the main line.
+func (m *GameViewModel) playFedMove(san string) tea.Cmd {
+	if !m.playing() {
+		return m.notes.notify(NotifyError, "Ignored "+san+" played on the board: the game is over")
+	}
+	m.current = m.tip()
+	m.selected = internal.NoSquare
+	mv, err := m.current.Board.ParseMove(san)
+	if err != nil {
+		return m.notes.notify(NotifyError, fmt.Sprintf("Ignored %s played on the board: %v", san, err))
+	}
+	m.playMove(mv)
+	return m.pressClock()
+}
+
+// fedMoveMsg is a move played on a board connected to the game, in SAN.
+type fedMoveMsg struct {
+	san string
+}
+
+// feedClosedMsg tells that the board connected to the game is gone.
+type feedClosedMsg struct{}
+
+// waitFedMove waits for the next move played on the connected board.
+func waitFedMove(feed <-chan string) tea.Cmd {
+	return func() tea.Msg {
+		san, ok := <-feed
+		if !ok {
+			return feedClosedMsg{}
+		}
+		return fedMoveMsg{san: san}
+	}
+}
+
+// winner returns the result of a game won by color.
+func winner(color int) string {
+	if color == internal.White {
+		return pgn.WhiteWin.String()
+	}
+	return pgn.BlackWin.String()
+}
+
+// PlayModel is a game between two players sharing the terminal, moving
+// pieces with the mouse against a chess clock.
+type PlayModel struct {
+	view     GameViewModel
+	feed     <-chan string // moves played on a connected board, if any
+	quitting bool
+}
+
+// NewPlayModel creates a new game with base time and increment on the clock.
+func NewPlayModel(base, increment time.Duration) PlayModel {
+	return PlayModel{view: newPlayView(startFEN, base, increment)}
+}
+
+// WithBoardOptions sets how the board is drawn, and the function that
+// persists changes made on the settings screen.
+func (m PlayModel) WithBoardOptions(opts BoardOptions, save SaveSettingsFunc) PlayModel {
+	m.view.SetBoardOptions(opts)
+	m.view.SetSettingsSaver(save)
+	return m
+}
+
+// WithGameAdder sets the function that inserts the game into the database
+// when it is saved.
+func (m PlayModel) WithGameAdder(add AddGameFunc) PlayModel {
+	m.view.SetGameAdder(add)
+	return m
+}
+
+// WithOpenings sets the ECO database used to name the opening being played.
+func (m PlayModel) WithOpenings(openings *eco.Database) PlayModel {
+	m.view.SetOpenings(openings)
+	return m
+}
+
+// WithKeys sets the key bindings. The game view's back key ends the session.
+func (m PlayModel) WithKeys(keys KeyMap) PlayModel {
+	m.view.SetKeys(keys)
+	return m
+}
+
+// WithTags sets tags of the game, such as the names of the players and the
+// event, over those of a casual game.
+func (m PlayModel) WithTags(tags map[string]string) PlayModel {
+	for name, value := range tags {
+		m.view.game.Tags[name] = value
+	}
+	m.view.info.Event = m.view.game.Tags["Event"]
+	m.view.info.White = m.view.game.Tags["White"]
+	m.view.info.Black = m.view.game.Tags["Black"]
+	return m
+}
+
+// WithGameListener sets a function passed the game as PGN when it starts,
+// after each move and when it ends, as for relaying it live.
+func (m PlayModel) WithGameListener(fn func(pgnText string)) PlayModel {
+	m.view.play.onChange = fn
+	if fn != nil {
+		fn(m.view.game.String())
+	}
+	return m
+}
+
+// WithMoveFeed sets a channel of the moves played on an electronic board, in
+// SAN, which are played in the game as they come. Closing it tells the
+// players the board is disconnected.
+func (m PlayModel) WithMoveFeed(feed <-chan string) PlayModel {
+	m.feed = feed
+	return m
+}
+
+// Init initializes the model
+func (m PlayModel) Init() tea.Cmd {
+	if m.feed != nil {
+		return tea.Batch(m.view.Init(), waitFedMove(m.feed))
+	}
+	return m.view.Init()
+}
+
+// Update handles messages
+func (m PlayModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		if msg.String() == "ctrl+c" ||
+			(key.Matches(msg, m.view.keys.Game.Back) && !m.view.capturesKeys()) {
+			m.quitting = true
+			return m, tea.Quit
+		}
+	}
+	switch msg := msg.(type) {
+	case fedMoveMsg:
+		cmd := m.view.playFedMove(msg.san)
+		return m, tea.Batch(cmd, waitFedMove(m.feed))
+	case feedClosedMsg:
+		return m, m.view.notes.notify(NotifyError, "The board was disconnected")
+	}
+	model, cmd := m.view.Update(msg)
+	m.view = model.(GameViewModel)
+	return m, cmd
+}
+
+// View renders the model
+func (m PlayModel) View() string {
+	if m.quitting {
+		return "Thanks for using GoChess!\n"
+	}
+	return m.view.View()
+}
@@ -0,0 +1,257 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/kyleboon/gochess/internal"
+	"github.com/kyleboon/gochess/internal/srs"
+)
+
+// ReviewCard is a position due for review: the side to move has to find
+// one of the answers.
+type ReviewCard struct {
+	ID      int
+	Kind    string   // the trainer that scheduled it
+	Source  string   // where the position was found
+	FEN     string   // the position
+	Answers []string // the moves accepted, in SAN
+}
+
+// GradeFunc records the grade of a review of the card with the given ID.
+type GradeFunc func(id int, q srs.Quality) error
+
+// Answers found within these times are graded perfect and good; slower
+// ones pass.
+const (
+	reviewPerfectTime = 10 * time.Second
+	reviewGoodTime    = 30 * time.Second
+)
+
+var reviewNextKey = key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "next"))
+
+// ReviewModel drills the positions due for spaced repetition, one at a time.
+// Each is graded by whether, and how fast, the move was found.
+type ReviewModel struct {
+	cards    []ReviewCard
+	current  int
+	board    *internal.Board
+	answers  []internal.Move
+	shown    time.Time // when the current position was shown
+	answered bool      // whether the current position was answered
+	correct  int
+	finished bool
+	feedback string // rendered result of the last answer
+	input    textinput.Model
+	opts     BoardOptions
+	grade    GradeFunc
+	notes    notifier
+	quitting bool
+	width    int
+	height   int
+}
+
+// NewReviewModel creates a review of the cards, in order.
+func NewReviewModel(cards []ReviewCard) (ReviewModel, error) {
+	if len(cards) == 0 {
+		return ReviewModel{}, fmt.Errorf("nothing to review")
+	}
+	input := textinput.New()
+	input.Prompt = "> "
+	input.Width = MinWidth - 4
+	input.Focus()
+	m := ReviewModel{
+		cards:  cards,
+		input:  input,
+		opts:   BoardOptions{Theme: DefaultTheme()},
+		width:  MinWidth,
+		height: MinHeight,
+	}
+	if err := m.show(0); err != nil {
+		return ReviewModel{}, err
+	}
+	return m, nil
+}
+
+// WithBoardOptions sets how the board is drawn. It is flipped for positions
+// with Black to move.
+func (m ReviewModel) WithBoardOptions(opts BoardOptions) ReviewModel {
+	m.opts = opts
+	return m
+}
+
+// WithGrader sets the function that records the grade of each review.
+func (m ReviewModel) WithGrader(grade GradeFunc) ReviewModel {
+	m.grade = grade
+	return m
+}
+
+// show shows the card at index i.
+func (m *ReviewModel) show(i int) error {
+	card := m.cards[i]
+	board, err := internal.ParseFen(card.FEN)
+	if err != nil {
+		return fmt.Errorf("invalid position of review item %d: %w", card.ID, err)
+	}
+	var answers []internal.Move
+	for _, san := range card.Answers {
+		if mv, err := board.ParseMove(san); err == nil {
+			answers = append(answers, mv)
+		}
+	}
+	if len(answers) == 0 {
+		return fmt.Errorf("review item %d has no legal answer", card.ID)
+	}
+	m.current, m.board, m.answers = i, board, answers
+	m.shown = time.Now()
+	m.answered = false
+	m.input.SetValue("")
+	return nil
+}
+
+// Init initializes the model
+func (m ReviewModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+// Update handles messages
+func (m ReviewModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if cmd, ok := m.notes.update(msg); ok {
+		return m, cmd
+	}
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.input.Width = max(m.width, MinWidth) - 4
+		return m, nil
+
+	case tea.KeyMsg:
+		switch {
+		case msg.String() == "ctrl+c":
+			m.quitting = true
+			return m, tea.Quit
+		case key.Matches(msg, trainQuitKey):
+			m.quitting = true
+			return m, tea.Quit
+		case m.finished:
+			return m, nil
+		case m.answered && key.Matches(msg, reviewNextKey):
+			return m, m.next()
+		case m.answered:
+			return m, nil
+		case key.Matches(msg, trainEnterKey):
+			answer := strings.TrimSpace(m.input.Value())
+			if answer == "" {
+				return m, nil
+			}
+			return m, m.answer(answer)
+		}
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+// answer grades a move typed for the current position, if it is legal.
+func (m *ReviewModel) answer(answer string) tea.Cmd {
+	mv, err := m.board.ParseMove(answer)
+	if err != nil {
+		return m.notes.notify(NotifyError, fmt.Sprintf("%s: %v", answer, err))
+	}
+	m.answered = true
+	card := m.cards[m.current]
+
+	q := srs.Wrong
+	if slices.Contains(m.answers, mv) {
+		m.correct++
+		switch elapsed := time.Since(m.shown); {
+		case elapsed < reviewPerfectTime:
+			q = srs.Perfect
+		case elapsed < reviewGoodTime:
+			q = srs.Good
+		default:
+			q = srs.Pass
+		}
+		m.feedback = correctStyle.Render(fmt.Sprintf("✓ %s", mv.San(m.board)))
+	} else {
+		m.feedback = wrongStyle.Render(fmt.Sprintf("✗ %s; the answer is %s", mv.San(m.board), strings.Join(card.Answers, " or ")))
+	}
+
+	if m.grade == nil {
+		return nil
+	}
+	grade, id := m.grade, card.ID
+	return notifyFailure(func() error { return grade(id, q) }, "Failed to save the review")
+}
+
+// next shows the next card that can be shown, or ends the review.
+func (m *ReviewModel) next() tea.Cmd {
+	m.feedback = ""
+	var cmd tea.Cmd
+	for i := m.current + 1; i < len(m.cards); i++ {
+		err := m.show(i)
+		if err == nil {
+			return cmd
+		}
+		cmd = m.notes.notify(NotifyError, err.Error())
+	}
+	m.finished = true
+	return cmd
+}
+
+// View renders the model
+func (m ReviewModel) View() string {
+	if m.quitting {
+		return "Thanks for using GoChess!\n"
+	}
+	card := m.cards[m.current]
+	var s strings.Builder
+	s.WriteString(TitleStyle.UnsetMarginBottom().Render("🎯 Review"))
+	s.WriteString("\n")
+	status := fmt.Sprintf("Position %d of %d • %d correct", m.current+1, len(m.cards), m.correct)
+	s.WriteString(lipgloss.NewStyle().Foreground(ColorTextMuted).Render(status))
+	s.WriteString("\n\n")
+
+	if m.finished {
+		s.WriteString(SubtitleStyle.UnsetMargins().Render(fmt.Sprintf("Review done • %d of %d found", m.correct, len(m.cards))))
+		s.WriteString("\n\n")
+		s.WriteString(HelpStyle.UnsetMarginTop().Render(helpLine(trainQuitKey)))
+		return s.String()
+	}
+
+	opts := m.opts
+	opts.Flipped = m.board.SideToMove == internal.Black
+	s.WriteString(RenderBoard(m.board, BoardMarks{Last: internal.NullMove, Selected: internal.NoSquare}, opts))
+	s.WriteString("\n\n")
+	from := card.Kind
+	if card.Source != "" {
+		from += ", " + card.Source
+	}
+	s.WriteString(lipgloss.NewStyle().Foreground(ColorTextMuted).Render("From "+from) + "\n")
+	color := [2]string{"White", "Black"}[m.board.SideToMove]
+	s.WriteString(fmt.Sprintf("Find the move for %s, in SAN or UCI:\n", color))
+	s.WriteString(m.input.View())
+	s.WriteString("\n\n")
+	if m.feedback != "" {
+		s.WriteString(m.feedback + "\n")
+	}
+	if note := m.notes.View(); note != "" {
+		s.WriteString(note + "\n")
+	}
+
+	help := []key.Binding{trainEnterKey}
+	if m.answered {
+		help = []key.Binding{reviewNextKey}
+	}
+	help = append(help, trainQuitKey)
+	s.WriteString(HelpStyle.UnsetMarginTop().Render(helpLine(help...)))
+	return s.String()
+}
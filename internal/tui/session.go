@@ -0,0 +1,91 @@
+package tui
+
+import "time"
+
+// Screens of the game browser saved in a session.
+const (
+	ScreenList = "list"
+	ScreenGame = "game"
+)
+
+// Session is the state of the game browser when it was left, so that a
+// review can go on where it stopped: the screen shown, the game open or
+// selected and the scroll positions.
+type Session struct {
+	Screen     string    `json:"screen"`
+	GameID     int       `json:"game_id,omitempty"` // the game open, or selected in the list
+	Title      string    `json:"title,omitempty"`   // the players of the game, to offer restoring it by
+	ListIndex  int       `json:"list_index"`        // the row selected in the list
+	Ply        int       `json:"ply,omitempty"`     // the main-line move shown in the game view
+	MoveScroll int       `json:"move_scroll,omitempty"`
+	SavedAt    time.Time `json:"saved_at"`
+}
+
+// Session returns the state of the browser, to restore it later with
+// WithSession.
+func (m GameListModel) Session() Session {
+	s := Session{Screen: ScreenList, ListIndex: m.list.Index(), SavedAt: time.Now()}
+	game := m.selected
+	if game == nil {
+		if i, ok := m.list.SelectedItem().(gameItem); ok {
+			game = &i.game
+		}
+	}
+	if game != nil {
+		s.GameID = game.ID
+		s.Title = game.White + " - " + game.Black
+	}
+	if m.selected != nil && m.selected.ID != 0 {
+		s.Screen = ScreenGame
+		if m.viewer != nil {
+			s.Ply = m.viewer.Ply()
+			s.MoveScroll = m.viewer.scroll
+		}
+	}
+	return s
+}
+
+// WithSession restores the state of a session: the game it had selected,
+// found by its ID, is selected again and reopened at the move shown. It
+// reports whether the game is listed; if not, the list starts at the row
+// the session had selected.
+func (m GameListModel) WithSession(s Session) (GameListModel, bool) {
+	index := -1
+	for i, item := range m.list.Items() {
+		if g, ok := item.(gameItem); ok && g.game.ID == s.GameID && s.GameID != 0 {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		if s.ListIndex < len(m.list.Items()) {
+			m.list.Select(s.ListIndex)
+		}
+		return m, false
+	}
+	m.list.Select(index)
+	if s.Screen == ScreenGame {
+		m.openSelected()
+		if m.viewer != nil {
+			m.viewer.GoToPly(s.Ply)
+			m.viewer.scroll = s.MoveScroll
+		}
+	}
+	return m, true
+}
+
+// Ply returns the main-line ply of the position shown, 0 being the starting
+// position. In a variation it is the ply the variation branches from.
+func (m GameViewModel) Ply() int {
+	if ply := m.plyOf(m.current); ply > 0 || m.current == m.game.Root {
+		return ply
+	}
+	return m.branchPly() - 1
+}
+
+// GoToPly shows the position after the ply-th move of the main line, or the
+// final position if the game is shorter.
+func (m *GameViewModel) GoToPly(ply int) {
+	m.current = m.mainline[max(0, min(ply, len(m.mainline)-1))]
+	m.scrollToCurrent()
+}
@@ -0,0 +1,144 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/kyleboon/gochess/internal"
+)
+
+// SaveSettingsFunc persists the board settings chosen on the settings screen.
+type SaveSettingsFunc func(opts BoardOptions) error
+
+// settingsField is a row of the settings screen.
+type settingsField int
+
+const (
+	settingTheme settingsField = iota
+	settingPieces
+	settingHighlight
+	settingCoordinates
+	settingCount
+)
+
+// SettingsModel is a screen for choosing the board theme, piece set and
+// highlight style, with a preview board.
+type SettingsModel struct {
+	opts     BoardOptions
+	keys     SettingsKeys
+	field    settingsField
+	done     bool // the screen was closed
+	accepted bool // the settings were accepted rather than canceled
+}
+
+// NewSettingsModel creates a settings screen starting from opts, operated
+// with keys.
+func NewSettingsModel(opts BoardOptions, keys SettingsKeys) SettingsModel {
+	if opts.Theme.Name == "" {
+		opts.Theme = DefaultTheme()
+	}
+	return SettingsModel{opts: opts, keys: keys}
+}
+
+// Init initializes the model
+func (m SettingsModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles messages
+func (m SettingsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	switch {
+	case key.Matches(keyMsg, m.keys.Up):
+		m.field = (m.field + settingCount - 1) % settingCount
+	case key.Matches(keyMsg, m.keys.Down):
+		m.field = (m.field + 1) % settingCount
+	case key.Matches(keyMsg, m.keys.PrevValue):
+		m.change(-1)
+	case key.Matches(keyMsg, m.keys.NextValue):
+		m.change(1)
+	case key.Matches(keyMsg, m.keys.Save):
+		m.done, m.accepted = true, true
+	case key.Matches(keyMsg, m.keys.Cancel):
+		m.done = true
+	}
+	return m, nil
+}
+
+// change steps the value of the selected field forwards or backwards.
+func (m *SettingsModel) change(step int) {
+	switch m.field {
+	case settingTheme:
+		i := 0
+		for j, t := range Themes {
+			if t.Name == m.opts.Theme.Name {
+				i = j
+			}
+		}
+		m.opts.Theme = Themes[(i+step+len(Themes))%len(Themes)]
+	case settingPieces:
+		m.opts.Pieces = (m.opts.Pieces + 1) % (PiecesLetters + 1)
+	case settingHighlight:
+		m.opts.Highlight = (m.opts.Highlight + HighlightStyle(step) + HighlightNone + 1) % (HighlightNone + 1)
+	case settingCoordinates:
+		m.opts.HideCoordinates = !m.opts.HideCoordinates
+	}
+}
+
+// Done reports whether the screen was closed, and whether its settings were
+// accepted.
+func (m SettingsModel) Done() (done, accepted bool) {
+	return m.done, m.accepted
+}
+
+// Options returns the chosen board options.
+func (m SettingsModel) Options() BoardOptions {
+	return m.opts
+}
+
+// previewMove is the last move shown on the preview board.
+var previewMove = internal.Move{From: internal.G1, To: internal.F3}
+
+// View renders the model
+func (m SettingsModel) View() string {
+	coordinates := "shown"
+	if m.opts.HideCoordinates {
+		coordinates = "hidden"
+	}
+	rows := []struct {
+		label, value string
+	}{
+		{"Theme", m.opts.Theme.Name},
+		{"Pieces", m.opts.Pieces.String()},
+		{"Highlight", m.opts.Highlight.String()},
+		{"Coordinates", coordinates},
+	}
+
+	var b strings.Builder
+	b.WriteString(TitleStyle.Render("⚙ Board Settings"))
+	b.WriteString("\n")
+	for i, row := range rows {
+		value := fmt.Sprintf("‹ %s ›", row.value)
+		line := StatLabelStyle.Render(row.label) + value
+		if settingsField(i) == m.field {
+			line = lipgloss.NewStyle().Foreground(ColorPrimary).Bold(true).Render("> ") + line
+		} else {
+			line = "  " + line
+		}
+		b.WriteString(line + "\n")
+	}
+	b.WriteString("\n")
+
+	board, _ := internal.ParseFen("rnbqkbnr/pppppppp/8/8/8/5N2/PPPPPPPP/RNBQKB1R b KQkq - 1 1")
+	b.WriteString(RenderBoard(board, LastMoveMarks(previewMove), m.opts))
+	b.WriteString("\n")
+
+	b.WriteString(HelpStyle.Render(helpLine(m.keys.Up, m.keys.Down, m.keys.PrevValue, m.keys.NextValue, m.keys.Save, m.keys.Cancel)))
+	return b.String()
+}
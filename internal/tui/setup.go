@@ -0,0 +1,464 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/kyleboon/gochess/internal"
+	"github.com/kyleboon/gochess/internal/eco"
+	"github.com/kyleboon/gochess/internal/pgn"
+)
+
+// pieceLetters are the keys that place pieces in the board editor.
+const pieceLetters = "PNBRQKpnbrqk"
+
+// emptyFEN is the board with no pieces on it.
+const emptyFEN = "8/8/8/8/8/8/8/8 w - - 0 1"
+
+// castleRooks are the squares of the rooks that can castle, indexed like
+// Board.CastleSq.
+var castleRooks = [4]internal.Sq{internal.A1, internal.A8, internal.H1, internal.H8}
+
+// SetupModel is a board editor. Pieces are placed with the keyboard or the
+// mouse, and the side to move, castling rights and en passant square are set
+// with their own keys. A legal position can be analysed on the board, played
+// from with a clock, or copied as FEN.
+type SetupModel struct {
+	pieces    [64]internal.Piece
+	side      int
+	castle    [4]bool // castling rights, indexed like Board.CastleSq
+	ep        internal.Sq
+	cursor    internal.Sq
+	brush     internal.Piece // piece placed by clicking a square
+	brushSet  bool           // a piece (or NoPiece, to clear) was chosen for clicking
+	board     BoardOptions
+	save      SaveSettingsFunc
+	addGame   AddGameFunc
+	openings  *eco.Database
+	keys      KeyMap
+	base      time.Duration // clock for games played from the position
+	increment time.Duration
+	view      *GameViewModel // analysis or game started from the position
+	showHelp  bool
+	notes     notifier
+	quitting  bool
+	width     int
+	height    int
+}
+
+// NewSetupModel creates a board editor starting from fen, or from the
+// standard starting position if fen is empty. The FEN only has to be well
+// formed, not legal.
+func NewSetupModel(fen string) (SetupModel, error) {
+	m := SetupModel{
+		cursor: internal.E4,
+		keys:   DefaultKeyMap(),
+		board:  BoardOptions{Theme: DefaultTheme()},
+		width:  MinWidth,
+		height: MinHeight,
+	}
+	if fen == "" {
+		fen = startFEN
+	}
+	if err := m.load(fen); err != nil {
+		return m, err
+	}
+	return m, nil
+}
+
+// load replaces the position with fen.
+func (m *SetupModel) load(fen string) error {
+	b, err := internal.ParseFen(fen)
+	if err != nil {
+		return err
+	}
+	m.pieces = b.Piece
+	m.side = b.SideToMove
+	for i, sq := range b.CastleSq {
+		m.castle[i] = sq != internal.NoSquare
+	}
+	m.ep = b.EpSquare
+	return nil
+}
+
+// WithBoardOptions sets how the board is drawn, and the function that
+// persists changes made on the settings screen.
+func (m SetupModel) WithBoardOptions(opts BoardOptions, save SaveSettingsFunc) SetupModel {
+	m.board = opts
+	m.save = save
+	return m
+}
+
+// WithKeys sets the key bindings.
+func (m SetupModel) WithKeys(keys KeyMap) SetupModel {
+	m.keys = keys
+	return m
+}
+
+// WithGameAdder sets the function that inserts games analysed or played from
+// the position into the database.
+func (m SetupModel) WithGameAdder(add AddGameFunc) SetupModel {
+	m.addGame = add
+	return m
+}
+
+// WithOpenings sets the ECO database used to name the opening of games
+// analysed or played from the starting position.
+func (m SetupModel) WithOpenings(openings *eco.Database) SetupModel {
+	m.openings = openings
+	return m
+}
+
+// WithTimeControl sets the clock of games played from the position.
+func (m SetupModel) WithTimeControl(base, increment time.Duration) SetupModel {
+	m.base = base
+	m.increment = increment
+	return m
+}
+
+// position returns the board being set up.
+func (m SetupModel) position() *internal.Board {
+	b := internal.Board{
+		Piece:      m.pieces,
+		SideToMove: m.side,
+		EpSquare:   m.ep,
+		MoveNr:     1,
+	}
+	for i, ok := range m.castle {
+		b.CastleSq[i] = internal.NoSquare
+		if ok {
+			b.CastleSq[i] = castleRooks[i]
+		}
+	}
+	// Parse the FEN back to count the pieces
+	board, _ := internal.ParseFen(b.Fen())
+	return board
+}
+
+// FEN returns the FEN of the position being set up.
+func (m SetupModel) FEN() string {
+	return m.position().Fen()
+}
+
+// Init initializes the model
+func (m SetupModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles messages
+func (m SetupModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok && msg.String() == "ctrl+c" {
+		m.quitting = true
+		return m, tea.Quit
+	}
+	// The editor's notifications expire while the position is analysed or
+	// played, as well as the view's own
+	if _, ok := msg.(notificationExpiredMsg); ok {
+		m.notes.update(msg)
+	}
+	if m.view != nil {
+		return m.updateView(msg)
+	}
+	if cmd, ok := m.notes.update(msg); ok {
+		return m, cmd
+	}
+
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+
+	case tea.KeyMsg:
+		if m.showHelp {
+			m.showHelp = false
+			return m, nil
+		}
+		return m.updateKey(msg)
+
+	case tea.MouseMsg:
+		if msg.Action == tea.MouseActionPress && msg.Button == tea.MouseButtonLeft {
+			if sq := m.board.SquareAt(msg.X, msg.Y-gameViewHeaderHeight); sq != internal.NoSquare {
+				m.cursor = sq
+				if m.brushSet {
+					// Clicking a square with the piece already on it clears it
+					if m.pieces[sq] == m.brush {
+						m.pieces[sq] = internal.NoPiece
+					} else {
+						m.pieces[sq] = m.brush
+					}
+				}
+			}
+		}
+	}
+	return m, nil
+}
+
+// updateKey handles a key press in the editor.
+func (m SetupModel) updateKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	keys := m.keys.Setup
+	step := func(df, dr int) {
+		file, rank := m.cursor.File()+df, m.cursor.Rank()+dr
+		if m.board.Flipped {
+			file, rank = m.cursor.File()-df, m.cursor.Rank()-dr
+		}
+		if file >= 0 && file < 8 && rank >= 0 && rank < 8 {
+			m.cursor = internal.Square(file, rank)
+		}
+	}
+
+	switch {
+	case key.Matches(msg, keys.Back):
+		m.quitting = true
+		return m, tea.Quit
+	case key.Matches(msg, keys.Up):
+		step(0, 1)
+	case key.Matches(msg, keys.Down):
+		step(0, -1)
+	case key.Matches(msg, keys.Left):
+		step(-1, 0)
+	case key.Matches(msg, keys.Right):
+		step(1, 0)
+	case key.Matches(msg, keys.Clear):
+		m.pieces[m.cursor] = internal.NoPiece
+		m.brush, m.brushSet = internal.NoPiece, true
+	case key.Matches(msg, keys.SideToMove):
+		m.side ^= 1
+		m.ep = internal.NoSquare
+	case key.Matches(msg, keys.WhiteOO):
+		m.castle[internal.WhiteOO] = !m.castle[internal.WhiteOO]
+	case key.Matches(msg, keys.WhiteOOO):
+		m.castle[internal.WhiteOOO] = !m.castle[internal.WhiteOOO]
+	case key.Matches(msg, keys.BlackOO):
+		m.castle[internal.BlackOO] = !m.castle[internal.BlackOO]
+	case key.Matches(msg, keys.BlackOOO):
+		m.castle[internal.BlackOOO] = !m.castle[internal.BlackOOO]
+	case key.Matches(msg, keys.EnPassant):
+		if m.ep == m.cursor {
+			m.ep = internal.NoSquare
+		} else {
+			m.ep = m.cursor
+		}
+	case key.Matches(msg, keys.StartPosition):
+		_ = m.load(startFEN)
+	case key.Matches(msg, keys.EmptyBoard):
+		_ = m.load(emptyFEN)
+	case key.Matches(msg, keys.Flip):
+		m.board.Flipped = !m.board.Flipped
+	case key.Matches(msg, keys.Analyse):
+		return m.start(false)
+	case key.Matches(msg, keys.Play):
+		return m.start(true)
+	case key.Matches(msg, keys.CopyFEN):
+		where, err := copyToClipboard(m.FEN())
+		if err != nil {
+			return m, m.notes.notify(NotifyError, "Failed to copy FEN: "+err.Error())
+		}
+		return m, m.notes.notify(NotifySuccess, "FEN copied to the "+where)
+	case key.Matches(msg, keys.PasteFEN):
+		text, err := pasteFromClipboard()
+		if err != nil {
+			return m, m.notes.notify(NotifyError, "Failed to paste FEN: "+err.Error())
+		}
+		if err := m.load(text); err != nil {
+			return m, m.notes.notify(NotifyError, "The clipboard holds no FEN: "+err.Error())
+		}
+		return m, m.notes.notify(NotifySuccess, "Position pasted from the clipboard")
+	case key.Matches(msg, keys.Help):
+		m.showHelp = true
+	default:
+		// Piece letters place the piece on the cursor
+		if s := msg.String(); len(s) == 1 && strings.Contains(pieceLetters, s) {
+			for p := internal.Piece(internal.WP); int(p) < len(internal.PieceRunes); p++ {
+				if internal.PieceRunes[p] == rune(s[0]) {
+					m.pieces[m.cursor] = p
+					m.brush, m.brushSet = p, true
+				}
+			}
+		}
+	}
+	return m, nil
+}
+
+// start opens the position on the board for analysis, or starts a game from
+// it with a clock. The position must be legal.
+func (m SetupModel) start(play bool) (tea.Model, tea.Cmd) {
+	b := m.position()
+	if err := b.Validate(); err != nil {
+		return m, m.notes.notify(NotifyError, "Illegal position: "+err.Error())
+	}
+	fen := b.Fen()
+
+	var view GameViewModel
+	if play {
+		if len(b.LegalMoves()) == 0 {
+			return m, m.notes.notify(NotifyError, "There are no legal moves in this position")
+		}
+		view = newPlayView(fen, m.base, m.increment)
+	} else {
+		info := Game{Event: "Analysis", White: "White", Black: "Black", Result: "*"}
+		tags := map[string]string{
+			"Event":  info.Event,
+			"Site":   "gochess",
+			"Date":   time.Now().Format("2006.01.02"),
+			"Round":  "-",
+			"White":  info.White,
+			"Black":  info.Black,
+			"Result": info.Result,
+			"FEN":    fen,
+		}
+		if fen != startFEN {
+			tags["SetUp"] = "1"
+		}
+		game, err := pgn.NewGame(tags)
+		if err != nil {
+			return m, m.notes.notify(NotifyError, err.Error())
+		}
+		view = NewGameViewModel(info, game)
+	}
+	view.SetBoardOptions(m.board)
+	view.SetSettingsSaver(m.save)
+	view.SetGameAdder(m.addGame)
+	view.SetOpenings(m.openings)
+	view.SetKeys(m.keys)
+	view.width = m.width
+	view.height = m.height
+	m.view = &view
+	return m, view.Init()
+}
+
+// updateView forwards a message to the analysis board or game started from
+// the position. Its back key returns to the editor.
+func (m SetupModel) updateView(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+	case tea.KeyMsg:
+		if key.Matches(msg, m.keys.Game.Back) && !m.view.capturesKeys() {
+			m.board = m.view.BoardOptions()
+			m.view = nil
+			return m, nil
+		}
+	}
+	model, cmd := m.view.Update(msg)
+	view := model.(GameViewModel)
+	m.view = &view
+	return m, cmd
+}
+
+// View renders the model
+func (m SetupModel) View() string {
+	if m.quitting {
+		return "Thanks for using GoChess!\n"
+	}
+	if m.view != nil {
+		return m.view.View()
+	}
+	if m.showHelp {
+		return m.renderHelp()
+	}
+
+	b := m.position()
+	var s strings.Builder
+	s.WriteString(TitleStyle.UnsetMarginBottom().Render("♜ Board Editor"))
+	s.WriteString("\n")
+	s.WriteString(lipgloss.NewStyle().Foreground(ColorTextMuted).Render(b.Fen()))
+	s.WriteString("\n\n")
+
+	marks := BoardMarks{Last: internal.NullMove, Selected: m.cursor}
+	if m.ep != internal.NoSquare {
+		marks.Targets = []internal.Sq{m.ep}
+	}
+	panel := m.renderPanel()
+	s.WriteString(lipgloss.JoinHorizontal(lipgloss.Top,
+		RenderBoard(b, marks, m.board), strings.Repeat(" ", moveListGap), panel))
+	s.WriteString("\n\n")
+
+	if err := b.Validate(); err != nil {
+		s.WriteString(lipgloss.NewStyle().Foreground(ColorError).Render("✗ " + err.Error()))
+	} else {
+		s.WriteString(lipgloss.NewStyle().Foreground(ColorSuccess).Render("✓ Legal position"))
+	}
+	if note := m.notes.View(); note != "" {
+		s.WriteString("  " + note)
+	}
+	s.WriteString("\n")
+
+	keys := m.keys.Setup
+	s.WriteString(HelpStyle.UnsetMarginTop().Render(helpLine(keys.Clear, keys.SideToMove, keys.Analyse, keys.Play, keys.CopyFEN, keys.Help, keys.Back)))
+	return s.String()
+}
+
+// renderPanel renders the settings of the position next to the board.
+func (m SetupModel) renderPanel() string {
+	side := "White"
+	if m.side == internal.Black {
+		side = "Black"
+	}
+	castling := ""
+	for _, right := range []struct {
+		index  int
+		letter string
+	}{{internal.WhiteOO, "K"}, {internal.WhiteOOO, "Q"}, {internal.BlackOO, "k"}, {internal.BlackOOO, "q"}} {
+		if m.castle[right.index] {
+			castling += right.letter
+		}
+	}
+	if castling == "" {
+		castling = "-"
+	}
+	ep := "-"
+	if m.ep != internal.NoSquare {
+		ep = m.ep.String()
+	}
+	brush := "none"
+	switch {
+	case m.brushSet && m.brush == internal.NoPiece:
+		brush = "clear"
+	case m.brushSet:
+		brush = m.board.Pieces.glyph(m.brush)
+	}
+
+	rows := []struct {
+		label, value string
+	}{
+		{"To move", side},
+		{"Castling", castling},
+		{"En passant", ep},
+		{"Cursor", m.cursor.String()},
+		{"Click places", brush},
+	}
+	var lines []string
+	for _, row := range rows {
+		lines = append(lines, StatLabelStyle.Render(row.label)+StatValueStyle.Render(row.value))
+	}
+	muted := lipgloss.NewStyle().Foreground(ColorTextMuted)
+	lines = append(lines, "",
+		muted.Render("Type PNBRQK to place a White"),
+		muted.Render("piece, pnbrqk for Black."))
+	return strings.Join(lines, "\n")
+}
+
+// renderHelp renders the help overlay of the editor.
+func (m SetupModel) renderHelp() string {
+	keys := m.keys.Setup
+	pieces := key.NewBinding(key.WithKeys(strings.Split(pieceLetters, "")...), key.WithHelp("PNBRQK pnbrqk", "place a piece"))
+
+	var b strings.Builder
+	b.WriteString(TitleStyle.Render("⌨ Keys"))
+	b.WriteString("\n")
+	b.WriteString(renderKeyHelp(keyGroup{"Board editor", []key.Binding{
+		pieces, keys.Clear, keys.Up, keys.Down, keys.Left, keys.Right,
+		keys.SideToMove, keys.WhiteOO, keys.WhiteOOO, keys.BlackOO, keys.BlackOOO, keys.EnPassant,
+		keys.StartPosition, keys.EmptyBoard, keys.Flip, keys.Analyse, keys.Play, keys.CopyFEN, keys.PasteFEN, keys.Help, keys.Back,
+	}}))
+	b.WriteString("\n")
+	b.WriteString(HelpStyle.Render(fmt.Sprintf(
+		"Click a square to move the cursor there and place the last piece typed • %s returns from analysis or play • press any key to close",
+		m.keys.Game.Back.Help().Key)))
+	return b.String()
+}
@@ -0,0 +1,133 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/kyleboon/gochess/internal"
+)
+
+// Theme is a board color palette.
+type Theme struct {
+	Name        string
+	LightSquare lipgloss.Color
+	DarkSquare  lipgloss.Color
+	Highlight   lipgloss.Color // squares of the last move
+	Target      lipgloss.Color // selected piece and its legal destinations
+	WhitePiece  lipgloss.Color
+	BlackPiece  lipgloss.Color
+}
+
+// Themes are the built-in board themes. The first one is the default.
+var Themes = []Theme{
+	{
+		Name:        "classic",
+		LightSquare: lipgloss.Color("#D7C5A3"),
+		DarkSquare:  lipgloss.Color("#9C7653"),
+		Highlight:   lipgloss.Color("#C9B03A"),
+		Target:      lipgloss.Color("#6F9E4F"),
+		WhitePiece:  lipgloss.Color("#FFFFFF"),
+		BlackPiece:  lipgloss.Color("#000000"),
+	},
+	{
+		Name:        "blue",
+		LightSquare: lipgloss.Color("#DEE3E6"),
+		DarkSquare:  lipgloss.Color("#8CA2AD"),
+		Highlight:   lipgloss.Color("#9BC7E8"),
+		Target:      lipgloss.Color("#3E7CB1"),
+		WhitePiece:  lipgloss.Color("#FFFFFF"),
+		BlackPiece:  lipgloss.Color("#000000"),
+	},
+	{
+		Name:        "green",
+		LightSquare: lipgloss.Color("#EEEED2"),
+		DarkSquare:  lipgloss.Color("#769656"),
+		Highlight:   lipgloss.Color("#BACA44"),
+		Target:      lipgloss.Color("#F6F669"),
+		WhitePiece:  lipgloss.Color("#FFFFFF"),
+		BlackPiece:  lipgloss.Color("#000000"),
+	},
+	{
+		// Dark squares on dark terminals, with bright pieces
+		Name:        "dark",
+		LightSquare: lipgloss.Color("#5A5F73"),
+		DarkSquare:  lipgloss.Color("#373A47"),
+		Highlight:   lipgloss.Color("#7B61FF"),
+		Target:      lipgloss.Color("#00D9FF"),
+		WhitePiece:  lipgloss.Color("#F8F8F2"),
+		BlackPiece:  lipgloss.Color("#FF6B9D"),
+	},
+	{
+		// ANSI colors only, for terminals without true color support
+		Name:        "mono",
+		LightSquare: lipgloss.Color("7"),
+		DarkSquare:  lipgloss.Color("8"),
+		Highlight:   lipgloss.Color("3"),
+		Target:      lipgloss.Color("2"),
+		WhitePiece:  lipgloss.Color("15"),
+		BlackPiece:  lipgloss.Color("0"),
+	},
+}
+
+// DefaultTheme returns the default board theme.
+func DefaultTheme() Theme {
+	return Themes[0]
+}
+
+// ThemeByName looks up a built-in theme by name, ignoring case.
+func ThemeByName(name string) (Theme, bool) {
+	for _, t := range Themes {
+		if strings.EqualFold(t.Name, name) {
+			return t, true
+		}
+	}
+	return Theme{}, false
+}
+
+// ThemeNames returns the names of the built-in themes.
+func ThemeNames() []string {
+	names := make([]string, len(Themes))
+	for i, t := range Themes {
+		names[i] = t.Name
+	}
+	return names
+}
+
+// PieceSet selects how pieces are drawn.
+type PieceSet int
+
+const (
+	PiecesUnicode PieceSet = iota // chess figurines: ♞
+	PiecesLetters                 // letters, uppercase for White: N, n
+)
+
+// String returns the name of the piece set.
+func (p PieceSet) String() string {
+	if p == PiecesLetters {
+		return "letters"
+	}
+	return "unicode"
+}
+
+// ParsePieceSet parses a piece set name. The empty string selects the
+// default Unicode set.
+func ParsePieceSet(s string) (PieceSet, error) {
+	switch strings.ToLower(s) {
+	case "", "unicode":
+		return PiecesUnicode, nil
+	case "letters", "ascii":
+		return PiecesLetters, nil
+	}
+	return PiecesUnicode, fmt.Errorf("unknown piece set %q (expected unicode or letters)", s)
+}
+
+// glyph returns the character used for piece.
+func (p PieceSet) glyph(piece internal.Piece) string {
+	if p == PiecesLetters {
+		return string(internal.PieceRunes[piece])
+	}
+	// Filled glyphs for both sides read better on colored squares; the
+	// side is told apart by the foreground.
+	return string(internal.Glyphs[piece.Type()|internal.Black])
+}
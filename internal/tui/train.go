@@ -0,0 +1,537 @@
+package tui
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/kyleboon/gochess/internal"
+)
+
+// TrainingMode names a training game.
+type TrainingMode string
+
+// The training games: playing a game without seeing the pieces, telling the
+// color of squares named at random, finding the shortest knight paths, and
+// guessing the moves of a game (see GuessModel).
+const (
+	TrainingBlindfold    TrainingMode = "blindfold"
+	TrainingSquareColors TrainingMode = "square-colors"
+	TrainingKnightPaths  TrainingMode = "knight-paths"
+	TrainingGuessMove    TrainingMode = "guess-the-move"
+)
+
+// TrainingModes lists the training games.
+var TrainingModes = []TrainingMode{TrainingBlindfold, TrainingSquareColors, TrainingKnightPaths, TrainingGuessMove}
+
+// trainingTitles are the names the training games are shown by.
+var trainingTitles = map[TrainingMode]string{
+	TrainingBlindfold:    "Blindfold",
+	TrainingSquareColors: "Square colors",
+	TrainingKnightPaths:  "Knight paths",
+	TrainingGuessMove:    "Guess the move",
+}
+
+// TrainingResult is the score of a finished training session: the correct
+// answers of the questions asked, or in blindfold play the legal moves of
+// the moves tried.
+type TrainingResult struct {
+	Mode     TrainingMode
+	Score    int
+	Total    int
+	Duration time.Duration
+}
+
+// SaveTrainingFunc stores the score of a finished training session.
+type SaveTrainingFunc func(TrainingResult) error
+
+// OpponentFunc returns the move to play in the position fen, in UCI or SAN.
+type OpponentFunc func(fen string) (string, error)
+
+var (
+	trainLightKey = key.NewBinding(key.WithKeys("l", "w"), key.WithHelp("l", "light"))
+	trainDarkKey  = key.NewBinding(key.WithKeys("d", "b"), key.WithHelp("d", "dark"))
+	trainEnterKey = key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "answer"))
+	trainAgainKey = key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "play again"))
+	trainQuitKey  = key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "quit"))
+)
+
+// opponentMoveMsg carries the opponent's move in blindfold play.
+type opponentMoveMsg struct {
+	move string
+	err  error
+}
+
+// TrainModel is a session of one of the training games. Square colors and
+// knight paths ask a number of questions; blindfold play lasts a game
+// against the opponent, typed in SAN on a board that is not shown.
+type TrainModel struct {
+	mode     TrainingMode
+	rounds   int // questions per session
+	round    int // questions asked, the current one included
+	score    int
+	total    int
+	started  time.Time
+	finished bool
+	feedback string // rendered result of the last answer
+	input    textinput.Model
+	board    BoardOptions
+	save     SaveTrainingFunc
+	opponent OpponentFunc
+	notes    notifier
+	quitting bool
+	width    int
+	height   int
+
+	square internal.Sq // square colors: the square asked about
+
+	from, to internal.Sq // knight paths: the knight's square and its target
+
+	game   *internal.GameState // blindfold: the game played
+	player int                 // the color played
+	moves  []string            // the moves played, in SAN
+	result string              // how the game ended
+}
+
+// NewTrainModel creates a session of the training game mode, asking rounds
+// questions (square colors and knight paths).
+func NewTrainModel(mode TrainingMode, rounds int) (TrainModel, error) {
+	if _, ok := trainingTitles[mode]; !ok {
+		return TrainModel{}, fmt.Errorf("unknown training mode %q", mode)
+	}
+	if mode == TrainingGuessMove {
+		return TrainModel{}, fmt.Errorf("guessing the moves of a game needs the game: use NewGuessModel")
+	}
+	if rounds <= 0 {
+		return TrainModel{}, fmt.Errorf("the number of rounds must be positive")
+	}
+	input := textinput.New()
+	input.Prompt = "> "
+	input.Width = MinWidth - 4
+	input.Focus()
+	m := TrainModel{
+		mode:   mode,
+		rounds: rounds,
+		input:  input,
+		board:  BoardOptions{Theme: DefaultTheme()},
+		width:  MinWidth,
+		height: MinHeight,
+	}
+	m.start()
+	return m, nil
+}
+
+// WithBoardOptions sets how the board is drawn.
+func (m TrainModel) WithBoardOptions(opts BoardOptions) TrainModel {
+	m.board = opts
+	return m
+}
+
+// WithScoreSaver sets the function that stores the score of each finished
+// session.
+func (m TrainModel) WithScoreSaver(save SaveTrainingFunc) TrainModel {
+	m.save = save
+	return m
+}
+
+// WithOpponent sets the opponent of blindfold play, and the color played
+// against it.
+func (m TrainModel) WithOpponent(opponent OpponentFunc, color int) TrainModel {
+	m.opponent = opponent
+	m.player = color
+	return m
+}
+
+// start begins a new session.
+func (m *TrainModel) start() {
+	m.round, m.score, m.total = 0, 0, 0
+	m.started = time.Now()
+	m.finished = false
+	m.feedback = ""
+	m.input.SetValue("")
+	switch m.mode {
+	case TrainingBlindfold:
+		board, _ := internal.ParseFen(startFEN)
+		m.game = internal.NewGameState(board)
+		m.moves = nil
+		m.result = ""
+	default:
+		m.next()
+	}
+}
+
+// next asks the next question.
+func (m *TrainModel) next() {
+	m.round++
+	switch m.mode {
+	case TrainingSquareColors:
+		m.square = internal.Sq(rand.IntN(64))
+	case TrainingKnightPaths:
+		for {
+			m.from, m.to = internal.Sq(rand.IntN(64)), internal.Sq(rand.IntN(64))
+			if knightDistance(m.from, m.to) >= 2 {
+				break
+			}
+		}
+	}
+}
+
+// Init initializes the model
+func (m TrainModel) Init() tea.Cmd {
+	if m.mode == TrainingBlindfold {
+		return tea.Batch(textinput.Blink, m.opponentTurn())
+	}
+	return textinput.Blink
+}
+
+// Update handles messages
+func (m TrainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if cmd, ok := m.notes.update(msg); ok {
+		return m, cmd
+	}
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.input.Width = max(m.width, MinWidth) - 4
+		return m, nil
+
+	case opponentMoveMsg:
+		if msg.err != nil {
+			return m, m.notes.notify(NotifyError, "The opponent failed to move: "+msg.err.Error())
+		}
+		mv, err := m.game.Board().ParseMove(msg.move)
+		if err != nil {
+			return m, m.notes.notify(NotifyError, fmt.Sprintf("The opponent played an illegal move %s: %v", msg.move, err))
+		}
+		return m, m.playBlindfold(mv)
+
+	case tea.KeyMsg:
+		switch {
+		case msg.String() == "ctrl+c":
+			m.quitting = true
+			return m, tea.Quit
+		case key.Matches(msg, trainQuitKey):
+			// Leaving a blindfold game ends it, and its score counts
+			var cmd tea.Cmd
+			if m.mode == TrainingBlindfold && !m.finished && m.total > 0 {
+				cmd = m.finish("Stopped")
+			}
+			m.quitting = true
+			return m, tea.Sequence(cmd, tea.Quit)
+		case m.finished:
+			if key.Matches(msg, trainAgainKey) {
+				m.start()
+				return m, m.opponentTurn()
+			}
+			return m, nil
+		case m.mode == TrainingSquareColors:
+			switch {
+			case key.Matches(msg, trainLightKey):
+				return m, m.answerColor(internal.White)
+			case key.Matches(msg, trainDarkKey):
+				return m, m.answerColor(internal.Black)
+			}
+			return m, nil
+		case key.Matches(msg, trainEnterKey):
+			answer := strings.TrimSpace(m.input.Value())
+			if answer == "" {
+				return m, nil
+			}
+			m.input.SetValue("")
+			if m.mode == TrainingKnightPaths {
+				return m, m.answerPath(answer)
+			}
+			return m, m.answerMove(answer)
+		}
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+// answerColor scores the color given for the square asked about.
+func (m *TrainModel) answerColor(color int) tea.Cmd {
+	names := [2]string{"light", "dark"}
+	m.total++
+	if m.square.Color() == color {
+		m.score++
+		m.feedback = correctStyle.Render(fmt.Sprintf("✓ %s is %s", m.square, names[color]))
+	} else {
+		m.feedback = wrongStyle.Render(fmt.Sprintf("✗ %s is %s", m.square, names[m.square.Color()]))
+	}
+	return m.advance()
+}
+
+// answerPath scores a knight path, given as the squares the knight visits.
+// It must reach the target in the fewest moves.
+func (m *TrainModel) answerPath(answer string) tea.Cmd {
+	var path []internal.Sq
+	for _, field := range strings.Fields(strings.ReplaceAll(answer, ",", " ")) {
+		sq, ok := parseSquare(field)
+		if !ok {
+			return m.notes.notify(NotifyError, fmt.Sprintf("%q is not a square", field))
+		}
+		path = append(path, sq)
+	}
+	if len(path) > 0 && path[0] == m.from {
+		path = path[1:]
+	}
+
+	m.total++
+	shortest := knightPath(m.from, m.to)
+	valid := len(path) == len(shortest)
+	for i, at := 0, m.from; valid && i < len(path); i++ {
+		valid = isKnightMove(at, path[i])
+		at = path[i]
+	}
+	valid = valid && path[len(path)-1] == m.to
+	if valid {
+		m.score++
+		m.feedback = correctStyle.Render(fmt.Sprintf("✓ %s to %s in %d moves", m.from, m.to, len(shortest)))
+	} else {
+		m.feedback = wrongStyle.Render(fmt.Sprintf("✗ %s to %s takes %d moves, as in %s", m.from, m.to, len(shortest), joinSquares(shortest)))
+	}
+	return m.advance()
+}
+
+// advance asks the next question, or ends the session after the last one.
+func (m *TrainModel) advance() tea.Cmd {
+	if m.round >= m.rounds {
+		return m.finish("")
+	}
+	m.next()
+	return nil
+}
+
+// answerMove plays a move typed in blindfold play, if it is legal.
+func (m *TrainModel) answerMove(answer string) tea.Cmd {
+	if m.game.Board().SideToMove != m.player {
+		return m.notes.notify(NotifyInfo, "Wait for the opponent's move")
+	}
+	m.total++
+	mv, err := m.game.Board().ParseMove(answer)
+	if err != nil {
+		m.feedback = wrongStyle.Render(fmt.Sprintf("✗ %s: %v", answer, err))
+		return nil
+	}
+	m.score++
+	m.feedback = ""
+	return m.playBlindfold(mv)
+}
+
+// playBlindfold plays a move in the blindfold game, then lets the opponent
+// answer unless the game is over.
+func (m *TrainModel) playBlindfold(mv internal.Move) tea.Cmd {
+	m.moves = append(m.moves, mv.San(m.game.Board()))
+	if err := m.game.Push(mv); err != nil {
+		return m.notes.notify(NotifyError, err.Error())
+	}
+	if result, reason := m.game.Termination(); reason != "" {
+		return m.finish(fmt.Sprintf("%s (%s)", reason, result))
+	}
+	return m.opponentTurn()
+}
+
+// opponentTurn asks the opponent for its move in blindfold play, when it is
+// its turn.
+func (m *TrainModel) opponentTurn() tea.Cmd {
+	if m.mode != TrainingBlindfold || m.opponent == nil || m.finished ||
+		m.game.Board().SideToMove == m.player {
+		return nil
+	}
+	opponent, fen := m.opponent, m.game.Board().Fen()
+	return func() tea.Msg {
+		move, err := opponent(fen)
+		return opponentMoveMsg{move: move, err: err}
+	}
+}
+
+// finish ends the session, with how a blindfold game ended, and stores its
+// score.
+func (m *TrainModel) finish(result string) tea.Cmd {
+	m.finished = true
+	m.result = result
+	if m.save == nil || m.total == 0 {
+		return nil
+	}
+	save, res := m.save, TrainingResult{Mode: m.mode, Score: m.score, Total: m.total, Duration: time.Since(m.started)}
+	return notifyResult(func() error { return save(res) }, "Score saved", "Failed to save the score")
+}
+
+// View renders the model
+func (m TrainModel) View() string {
+	if m.quitting {
+		return "Thanks for using GoChess!\n"
+	}
+	var s strings.Builder
+	s.WriteString(TitleStyle.UnsetMarginBottom().Render("🎯 " + trainingTitles[m.mode]))
+	s.WriteString("\n")
+	s.WriteString(lipgloss.NewStyle().Foreground(ColorTextMuted).Render(m.status()))
+	s.WriteString("\n\n")
+
+	switch m.mode {
+	case TrainingSquareColors:
+		if !m.finished {
+			s.WriteString("Is " + TitleStyle.UnsetMargins().Render(m.square.String()) + " a light or a dark square?")
+		}
+	case TrainingKnightPaths:
+		if !m.finished {
+			knight := &internal.Board{}
+			knight.Piece[m.from] = internal.WN
+			marks := BoardMarks{Last: internal.NullMove, Selected: m.to}
+			s.WriteString(RenderBoard(knight, marks, m.board))
+			s.WriteString("\n\n")
+			s.WriteString(fmt.Sprintf("Type the squares the knight on %s visits to reach %s in the fewest moves:\n", m.from, m.to))
+			s.WriteString(m.input.View())
+		}
+	case TrainingBlindfold:
+		if m.finished {
+			s.WriteString(RenderBoard(m.game.Board(), BoardMarks{Last: internal.NullMove, Selected: internal.NoSquare}, m.board))
+			s.WriteString("\n\n")
+		}
+		s.WriteString(m.moveList())
+		s.WriteString("\n\n")
+		if !m.finished {
+			if m.game.Board().SideToMove != m.player {
+				s.WriteString("The opponent is thinking...")
+			} else {
+				s.WriteString("Your move, in SAN:\n")
+				s.WriteString(m.input.View())
+			}
+		}
+	}
+	s.WriteString("\n\n")
+	if m.feedback != "" {
+		s.WriteString(m.feedback + "\n")
+	}
+	if m.finished {
+		s.WriteString(m.summary() + "\n")
+	}
+	if note := m.notes.View(); note != "" {
+		s.WriteString(note + "\n")
+	}
+
+	var help []key.Binding
+	switch {
+	case m.finished:
+		help = []key.Binding{trainAgainKey}
+	case m.mode == TrainingSquareColors:
+		help = []key.Binding{trainLightKey, trainDarkKey}
+	default:
+		help = []key.Binding{trainEnterKey}
+	}
+	help = append(help, trainQuitKey)
+	s.WriteString(HelpStyle.UnsetMarginTop().Render(helpLine(help...)))
+	return s.String()
+}
+
+// status describes the progress of the session.
+func (m TrainModel) status() string {
+	if m.mode == TrainingBlindfold {
+		color := [2]string{"White", "Black"}[m.player]
+		return fmt.Sprintf("Playing %s • %d legal of %d moves tried", color, m.score, m.total)
+	}
+	round := min(m.round, m.rounds)
+	return fmt.Sprintf("Question %d of %d • score %d", round, m.rounds, m.score)
+}
+
+// summary renders the score of a finished session.
+func (m TrainModel) summary() string {
+	elapsed := time.Since(m.started).Round(time.Second)
+	text := fmt.Sprintf("Score %d/%d in %s", m.score, m.total, elapsed)
+	if m.result != "" {
+		text = m.result + " • " + text
+	}
+	return SubtitleStyle.UnsetMargins().Render(text)
+}
+
+// moveList renders the moves of the blindfold game, numbered.
+func (m TrainModel) moveList() string {
+	if len(m.moves) == 0 {
+		return lipgloss.NewStyle().Foreground(ColorTextMuted).Render("No moves yet; the pieces stay hidden until the game ends")
+	}
+	var lines []string
+	for i := 0; i < len(m.moves); i += 2 {
+		line := fmt.Sprintf("%d. %s", i/2+1, m.moves[i])
+		if i+1 < len(m.moves) {
+			line += " " + m.moves[i+1]
+		}
+		lines = append(lines, line)
+	}
+	// The latest moves fit on the screen
+	if limit := max(m.height-14, 4); len(lines) > limit {
+		lines = lines[len(lines)-limit:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+var (
+	correctStyle = lipgloss.NewStyle().Foreground(ColorSuccess)
+	wrongStyle   = lipgloss.NewStyle().Foreground(ColorError).Bold(true)
+)
+
+// knightJumps are the file and rank steps of a knight's moves.
+var knightJumps = [8][2]int{{1, 2}, {2, 1}, {2, -1}, {1, -2}, {-1, -2}, {-2, -1}, {-2, 1}, {-1, 2}}
+
+// isKnightMove reports whether a knight can jump from one square to the
+// other.
+func isKnightMove(from, to internal.Sq) bool {
+	df, dr := to.File()-from.File(), to.Rank()-from.Rank()
+	return df*df+dr*dr == 5
+}
+
+// knightPath returns a shortest path of a knight from one square to
+// another on an empty board, as the squares it visits after from.
+func knightPath(from, to internal.Sq) []internal.Sq {
+	prev := make(map[internal.Sq]internal.Sq, 64)
+	prev[from] = internal.NoSquare
+	queue := []internal.Sq{from}
+	for len(queue) > 0 && queue[0] != to {
+		at := queue[0]
+		queue = queue[1:]
+		for _, j := range knightJumps {
+			next := internal.Square(at.File()+j[0], at.Rank()+j[1])
+			if _, seen := prev[next]; next == internal.NoSquare || seen {
+				continue
+			}
+			prev[next] = at
+			queue = append(queue, next)
+		}
+	}
+	var path []internal.Sq
+	for at := to; at != from; at = prev[at] {
+		path = append([]internal.Sq{at}, path...)
+	}
+	return path
+}
+
+// knightDistance returns the fewest moves a knight needs between two
+// squares.
+func knightDistance(from, to internal.Sq) int {
+	return len(knightPath(from, to))
+}
+
+// parseSquare reads a square name, as in "e4".
+func parseSquare(s string) (internal.Sq, bool) {
+	s = strings.ToLower(s)
+	if len(s) != 2 || s[0] < 'a' || s[0] > 'h' || s[1] < '1' || s[1] > '8' {
+		return internal.NoSquare, false
+	}
+	return internal.Square(int(s[0]-'a'), int(s[1]-'1')), true
+}
+
+// joinSquares writes squares separated by spaces.
+func joinSquares(squares []internal.Sq) string {
+	names := make([]string, len(squares))
+	for i, sq := range squares {
+		names[i] = sq.String()
+	}
+	return strings.Join(names, " ")
+}
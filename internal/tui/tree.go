@@ -0,0 +1,183 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/kyleboon/gochess/internal/db"
+)
+
+// treeRow is a visible line of the opening tree: a node and its depth.
+type treeRow struct {
+	node  *db.TreeNode
+	depth int
+}
+
+// OpeningTreeModel is a screen for drilling down an opening tree, expanding
+// and collapsing its moves, with the games and score of each.
+type OpeningTreeModel struct {
+	title    string
+	root     *db.TreeNode
+	keys     TreeKeys
+	expanded map[*db.TreeNode]bool
+	cursor   int
+	offset   int // first visible row
+	height   int
+}
+
+// NewOpeningTreeModel creates a screen for the tree of root, showing the
+// first moves, operated with keys.
+func NewOpeningTreeModel(title string, root *db.TreeNode, keys TreeKeys) OpeningTreeModel {
+	return OpeningTreeModel{
+		title:    title,
+		root:     root,
+		keys:     keys,
+		expanded: map[*db.TreeNode]bool{root: true},
+	}
+}
+
+// Init initializes the model
+func (m OpeningTreeModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles messages
+func (m OpeningTreeModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.height = msg.Height
+	case tea.KeyMsg:
+		rows := m.rows()
+		switch {
+		case msg.String() == "ctrl+c", key.Matches(msg, m.keys.Quit):
+			return m, tea.Quit
+		case key.Matches(msg, m.keys.Up):
+			m.cursor = max(0, m.cursor-1)
+		case key.Matches(msg, m.keys.Down):
+			m.cursor = min(len(rows)-1, m.cursor+1)
+		case key.Matches(msg, m.keys.Expand):
+			if len(rows) > 0 {
+				m.expanded[rows[m.cursor].node] = true
+			}
+		case key.Matches(msg, m.keys.Collapse):
+			if len(rows) == 0 {
+				break
+			}
+			row := rows[m.cursor]
+			if m.expanded[row.node] && len(row.node.Children) > 0 {
+				delete(m.expanded, row.node)
+				break
+			}
+			// Go up to the parent move
+			for i := m.cursor - 1; i >= 0; i-- {
+				if rows[i].depth < row.depth {
+					m.cursor = i
+					break
+				}
+			}
+		}
+	}
+	m.scroll()
+	return m, nil
+}
+
+// rows returns the visible lines of the tree: the children of every
+// expanded node, depth first.
+func (m OpeningTreeModel) rows() []treeRow {
+	var rows []treeRow
+	var walk func(n *db.TreeNode, depth int)
+	walk = func(n *db.TreeNode, depth int) {
+		for _, c := range n.Children {
+			rows = append(rows, treeRow{node: c, depth: depth})
+			if m.expanded[c] {
+				walk(c, depth+1)
+			}
+		}
+	}
+	walk(m.root, 0)
+	return rows
+}
+
+// visibleRows returns the number of tree lines that fit on the screen.
+func (m OpeningTreeModel) visibleRows() int {
+	if m.height == 0 {
+		return 20
+	}
+	return max(1, m.height-6) // title, summary and help
+}
+
+// scroll keeps the cursor on the screen.
+func (m *OpeningTreeModel) scroll() {
+	visible := m.visibleRows()
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+visible {
+		m.offset = m.cursor - visible + 1
+	}
+}
+
+// View renders the model
+func (m OpeningTreeModel) View() string {
+	var b strings.Builder
+	b.WriteString(TitleStyle.Render("♘ " + m.title))
+	b.WriteString("\n")
+	b.WriteString(StatLabelStyle.Render("Games:") + " " + StatValueStyle.Render(strings.TrimSpace(treeStats(m.root))))
+	b.WriteString("\n\n")
+
+	rows := m.rows()
+	cursorStyle := lipgloss.NewStyle().Foreground(ColorPrimary).Bold(true)
+	end := min(len(rows), m.offset+m.visibleRows())
+	for i := m.offset; i < end; i++ {
+		row := rows[i]
+		marker := "  "
+		switch {
+		case len(row.node.Children) == 0:
+		case m.expanded[row.node]:
+			marker = "▾ "
+		default:
+			marker = "▸ "
+		}
+		line := strings.Repeat("  ", row.depth) + marker + TreeMove(row.node)
+		line = fmt.Sprintf("%-30s", line) + " " + treeStats(row.node)
+		if i == m.cursor {
+			b.WriteString(cursorStyle.Render("> ") + line + "\n")
+		} else {
+			b.WriteString("  " + line + "\n")
+		}
+	}
+	if len(rows) == 0 {
+		b.WriteString(StatLabelStyle.Render("No games") + "\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(HelpStyle.Render(helpLine(m.keys.Up, m.keys.Down, m.keys.Expand, m.keys.Collapse, m.keys.Quit)))
+	return b.String()
+}
+
+// treeStats renders the games of n, the wins, draws and losses in them and
+// the score, colored by how well it went.
+func treeStats(n *db.TreeNode) string {
+	score := n.Score() * 100
+	style := lipgloss.NewStyle().Foreground(ColorTextBright)
+	switch {
+	case score >= 60:
+		style = WinStyle
+	case score <= 40:
+		style = LossStyle
+	}
+	return fmt.Sprintf("%4d  +%d =%d -%d  %s", n.Games, n.Wins, n.Draws, n.Losses,
+		style.Render(fmt.Sprintf("%.0f%%", score)))
+}
+
+// TreeMove returns the move of n with its move number, as in "1. e4" or
+// "1... e5".
+func TreeMove(n *db.TreeNode) string {
+	if n.Ply%2 == 1 {
+		return fmt.Sprintf("%d. %s", (n.Ply+1)/2, n.Move)
+	}
+	return fmt.Sprintf("%d... %s", n.Ply/2, n.Move)
+}
@@ -0,0 +1,72 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Validate checks that the position could arise in a game: each side has one
+// king and at most 16 pieces, no pawns stand on the first or last rank, the
+// side that just moved is not in check, and the castling rights and en
+// passant square agree with the pieces on the board.
+func (b *Board) Validate() error {
+	names := [2]string{"White", "Black"}
+	for color := White; color <= Black; color++ {
+		switch n := b.Count(Piece(color | King)); {
+		case n == 0:
+			return fmt.Errorf("%s has no king", names[color])
+		case n > 1:
+			return fmt.Errorf("%s has %d kings", names[color], n)
+		}
+		total := 0
+		for typ := Pawn; typ <= King; typ += Pawn {
+			total += b.Count(Piece(color | typ))
+		}
+		if total > 16 {
+			return fmt.Errorf("%s has %d pieces", names[color], total)
+		}
+		if n := b.Count(Piece(color | Pawn)); n > 8 {
+			return fmt.Errorf("%s has %d pawns", names[color], n)
+		}
+	}
+
+	for file := FileA; file <= FileH; file++ {
+		for _, sq := range []Sq{Square(file, Rank1), Square(file, Rank8)} {
+			if b.Piece[sq].Type() == Pawn {
+				return fmt.Errorf("pawn on %s", sq)
+			}
+		}
+	}
+
+	if _, illegal := b.pseudoLegalMoves(); illegal {
+		return fmt.Errorf("%s is in check but it is %s's move", names[b.SideToMove^1], names[b.SideToMove])
+	}
+
+	// Castling needs the king and rook on their starting squares
+	for right, rook := range []Sq{A1, A8, H1, H8} {
+		if b.CastleSq[right] == NoSquare {
+			continue
+		}
+		color := right & 1
+		king := [2]Sq{E1, E8}[color]
+		if b.Piece[king] != Piece(color|King) || b.Piece[rook] != Piece(color|Rook) {
+			return fmt.Errorf("%s cannot castle with the rook on %s", names[color], rook)
+		}
+	}
+
+	// The en passant square is behind a pawn that has just moved two squares
+	if ep := b.EpSquare; ep != NoSquare {
+		mover := b.SideToMove ^ 1
+		if ep.RelativeRank(mover) != Rank3 {
+			return errors.New("en passant square is not on the third rank of the side that moved")
+		}
+		dir := 8
+		if mover == Black {
+			dir = -8
+		}
+		if b.Piece[ep] != NoPiece || b.Piece[ep-Sq(dir)] != NoPiece || b.Piece[ep+Sq(dir)] != Piece(mover|Pawn) {
+			return fmt.Errorf("no pawn can have moved two squares past %s", ep)
+		}
+	}
+	return nil
+}
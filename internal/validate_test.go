@@ -0,0 +1,43 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		fen  string
+		err  string
+	}{
+		{"starting position", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", ""},
+		{"en passant", "rnbqkbnr/ppp1pppp/8/8/3pP3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 3", ""},
+		{"bare kings", "8/8/4k3/8/8/4K3/8/8 w - - 0 1", ""},
+		{"no white king", "4k3/8/8/8/8/8/8/8 w - - 0 1", "White has no king"},
+		{"two black kings", "4k2k/8/8/8/8/8/8/4K3 w - - 0 1", "Black has 2 kings"},
+		{"nine pawns", "4k3/8/8/8/8/P7/PPPPPPPP/4K3 w - - 0 1", "White has 9 pawns"},
+		{"pawn on last rank", "P3k3/8/8/8/8/8/8/4K3 w - - 0 1", "pawn on a8"},
+		{"opponent in check", "k7/8/8/8/8/8/8/R3K3 w - - 0 1", "Black is in check but it is White's move"},
+		{"queenside castling", "r3k3/8/8/8/8/8/8/4K3 w q - 0 1", ""},
+		{"castling rights", "4k3/8/8/8/8/8/8/4K3 w K - 0 1", "White cannot castle with the rook on h1"},
+		{"king moved", "r3k2r/8/8/8/8/8/8/3K3R w k - 0 1", ""},
+		{"black castling", "r3k3/8/8/8/8/8/8/3K3R w k - 0 1", "Black cannot castle with the rook on h8"},
+		{"en passant rank", "4k3/8/8/8/4P3/8/8/4K3 b - e4 0 1", "en passant square is not on the third rank of the side that moved"},
+		{"en passant pawn", "4k3/8/8/8/8/8/8/4K3 b - e3 0 1", "no pawn can have moved two squares past e3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := ParseFen(tt.fen)
+			require.NoError(t, err)
+			err = b.Validate()
+			if tt.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.err)
+			}
+		})
+	}
+}